@@ -77,6 +77,8 @@ func (src *AzureCluster) ConvertTo(dstRaw conversion.Hub) error {
 	dst.Spec.NetworkSpec.APIServerLB.IdleTimeoutInMinutes = restored.Spec.NetworkSpec.APIServerLB.IdleTimeoutInMinutes
 	dst.Spec.CloudProviderConfigOverrides = restored.Spec.CloudProviderConfigOverrides
 	dst.Spec.BastionSpec = restored.Spec.BastionSpec
+	dst.Spec.CapacityReservationGroups = restored.Spec.CapacityReservationGroups
+	dst.Spec.Hibernate = restored.Spec.Hibernate
 
 	// Here we manually restore outbound security rules. Since v1alpha3 only supports ingress ("Inbound") rules, all v1alpha4/v1beta1 outbound rules are dropped when an AzureCluster
 	// is converted to v1alpha3. We loop through all security group rules. For all previously existing outbound rules we restore the full rule.
@@ -99,6 +101,7 @@ func (src *AzureCluster) ConvertTo(dstRaw conversion.Hub) error {
 	}
 
 	dst.Status.LongRunningOperationStates = restored.Status.LongRunningOperationStates
+	dst.Status.CapacityReservationGroups = restored.Status.CapacityReservationGroups
 
 	// Restore list of virtual network peerings
 	dst.Spec.NetworkSpec.Vnet.Peerings = restored.Spec.NetworkSpec.Vnet.Peerings
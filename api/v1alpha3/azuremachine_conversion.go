@@ -51,6 +51,18 @@ func (src *AzureMachine) ConvertTo(dstRaw conversion.Hub) error {
 	}
 
 	dst.Spec.SubnetName = restored.Spec.SubnetName
+	dst.Spec.CapacityReservationGroupID = restored.Spec.CapacityReservationGroupID
+	dst.Spec.EnableInPlaceResize = restored.Spec.EnableInPlaceResize
+	dst.Spec.DataDiskRemovalPolicy = restored.Spec.DataDiskRemovalPolicy
+	dst.Spec.EnableUltraSSD = restored.Spec.EnableUltraSSD
+	dst.Spec.Diagnostics = restored.Spec.Diagnostics
+	dst.Spec.BootstrapDataDelivery = restored.Spec.BootstrapDataDelivery
+	dst.Spec.BootstrapDataStorage = restored.Spec.BootstrapDataStorage
+	restoreDataDisks(dst.Spec.DataDisks, restored.Spec.DataDisks)
+	restoreOSDisk(&dst.Spec.OSDisk, &restored.Spec.OSDisk)
+	restoreImage(dst.Spec.Image, restored.Spec.Image)
+	dst.Status.Image = restored.Status.Image
+	dst.Status.RemovedDataDisks = restored.Status.RemovedDataDisks
 
 	dst.Status.LongRunningOperationStates = restored.Status.LongRunningOperationStates
 
@@ -105,7 +117,12 @@ func Convert_v1alpha3_OSDisk_To_v1beta1_OSDisk(in *OSDisk, out *v1beta1.OSDisk,
 	if in.DiskSizeGB != 0 {
 		out.DiskSizeGB = &in.DiskSizeGB
 	}
-	out.DiffDiskSettings = (*v1beta1.DiffDiskSettings)(in.DiffDiskSettings)
+	if in.DiffDiskSettings != nil {
+		out.DiffDiskSettings = &v1beta1.DiffDiskSettings{}
+		if err := Convert_v1alpha3_DiffDiskSettings_To_v1beta1_DiffDiskSettings(in.DiffDiskSettings, out.DiffDiskSettings, s); err != nil {
+			return err
+		}
+	}
 	out.CachingType = in.CachingType
 	out.ManagedDisk = &v1beta1.ManagedDiskParameters{}
 
@@ -118,7 +135,12 @@ func Convert_v1beta1_OSDisk_To_v1alpha3_OSDisk(in *v1beta1.OSDisk, out *OSDisk,
 	if in.DiskSizeGB != nil {
 		out.DiskSizeGB = *in.DiskSizeGB
 	}
-	out.DiffDiskSettings = (*DiffDiskSettings)(in.DiffDiskSettings)
+	if in.DiffDiskSettings != nil {
+		out.DiffDiskSettings = &DiffDiskSettings{}
+		if err := Convert_v1beta1_DiffDiskSettings_To_v1alpha3_DiffDiskSettings(in.DiffDiskSettings, out.DiffDiskSettings, s); err != nil {
+			return err
+		}
+	}
 	out.CachingType = in.CachingType
 
 	if in.ManagedDisk != nil {
@@ -144,3 +166,47 @@ func Convert_v1beta1_ManagedDiskParameters_To_v1alpha3_ManagedDisk(in *v1beta1.M
 	out.DiskEncryptionSet = (*DiskEncryptionSetParameters)(in.DiskEncryptionSet)
 	return nil
 }
+
+// Convert_v1beta1_DataDisk_To_v1alpha3_DataDisk converts from the Hub version (v1beta1) of the DataDisk to this version.
+func Convert_v1beta1_DataDisk_To_v1alpha3_DataDisk(in *v1beta1.DataDisk, out *DataDisk, s apiconversion.Scope) error {
+	return autoConvert_v1beta1_DataDisk_To_v1alpha3_DataDisk(in, out, s)
+}
+
+// Convert_v1beta1_DiffDiskSettings_To_v1alpha3_DiffDiskSettings converts from the Hub version (v1beta1) of the DiffDiskSettings to this version.
+func Convert_v1beta1_DiffDiskSettings_To_v1alpha3_DiffDiskSettings(in *v1beta1.DiffDiskSettings, out *DiffDiskSettings, s apiconversion.Scope) error {
+	return autoConvert_v1beta1_DiffDiskSettings_To_v1alpha3_DiffDiskSettings(in, out, s)
+}
+
+// restoreOSDisk restores the fields of the OS disk that don't exist in this version.
+func restoreOSDisk(dst, restored *v1beta1.OSDisk) {
+	if dst.DiffDiskSettings != nil && restored.DiffDiskSettings != nil {
+		dst.DiffDiskSettings.Placement = restored.DiffDiskSettings.Placement
+	}
+}
+
+// restoreDataDisks restores the fields of the data disks that don't exist in this version.
+func restoreDataDisks(dst, restored []v1beta1.DataDisk) {
+	if len(dst) != len(restored) {
+		return
+	}
+	for i := range dst {
+		dst[i].SourceDiskID = restored[i].SourceDiskID
+		dst[i].DiskIOPSReadWrite = restored[i].DiskIOPSReadWrite
+		dst[i].DiskMBpsReadWrite = restored[i].DiskMBpsReadWrite
+		dst[i].MaxShares = restored[i].MaxShares
+	}
+}
+
+// restoreImage restores the fields of the image that don't exist in this version.
+func restoreImage(dst, restored *v1beta1.Image) {
+	if dst == nil || restored == nil {
+		return
+	}
+	dst.CommunityGallery = restored.CommunityGallery
+	dst.DirectSharedGallery = restored.DirectSharedGallery
+}
+
+// Convert_v1beta1_Image_To_v1alpha3_Image converts from the Hub version (v1beta1) of the Image to this version.
+func Convert_v1beta1_Image_To_v1alpha3_Image(in *v1beta1.Image, out *Image, s apiconversion.Scope) error {
+	return autoConvert_v1beta1_Image_To_v1alpha3_Image(in, out, s)
+}
@@ -126,11 +126,6 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1beta1.AzureMachineTemplate)(nil), (*AzureMachineTemplate)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1beta1_AzureMachineTemplate_To_v1alpha3_AzureMachineTemplate(a.(*v1beta1.AzureMachineTemplate), b.(*AzureMachineTemplate), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*AzureMachineTemplateList)(nil), (*v1beta1.AzureMachineTemplateList)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha3_AzureMachineTemplateList_To_v1beta1_AzureMachineTemplateList(a.(*AzureMachineTemplateList), b.(*v1beta1.AzureMachineTemplateList), scope)
 	}); err != nil {
@@ -186,21 +181,11 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1beta1.DataDisk)(nil), (*DataDisk)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1beta1_DataDisk_To_v1alpha3_DataDisk(a.(*v1beta1.DataDisk), b.(*DataDisk), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*DiffDiskSettings)(nil), (*v1beta1.DiffDiskSettings)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha3_DiffDiskSettings_To_v1beta1_DiffDiskSettings(a.(*DiffDiskSettings), b.(*v1beta1.DiffDiskSettings), scope)
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1beta1.DiffDiskSettings)(nil), (*DiffDiskSettings)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1beta1_DiffDiskSettings_To_v1alpha3_DiffDiskSettings(a.(*v1beta1.DiffDiskSettings), b.(*DiffDiskSettings), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*DiskEncryptionSetParameters)(nil), (*v1beta1.DiskEncryptionSetParameters)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha3_DiskEncryptionSetParameters_To_v1beta1_DiskEncryptionSetParameters(a.(*DiskEncryptionSetParameters), b.(*v1beta1.DiskEncryptionSetParameters), scope)
 	}); err != nil {
@@ -216,11 +201,6 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1beta1.Image)(nil), (*Image)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1beta1_Image_To_v1alpha3_Image(a.(*v1beta1.Image), b.(*Image), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*PublicIPSpec)(nil), (*v1beta1.PublicIPSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha3_PublicIPSpec_To_v1beta1_PublicIPSpec(a.(*PublicIPSpec), b.(*v1beta1.PublicIPSpec), scope)
 	}); err != nil {
@@ -371,6 +351,11 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddConversionFunc((*v1beta1.AzureMachineTemplate)(nil), (*AzureMachineTemplate)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_AzureMachineTemplate_To_v1alpha3_AzureMachineTemplate(a.(*v1beta1.AzureMachineTemplate), b.(*AzureMachineTemplate), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddConversionFunc((*v1beta1.AzureMachineTemplateResource)(nil), (*AzureMachineTemplateResource)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta1_AzureMachineTemplateResource_To_v1alpha3_AzureMachineTemplateResource(a.(*v1beta1.AzureMachineTemplateResource), b.(*AzureMachineTemplateResource), scope)
 	}); err != nil {
@@ -381,6 +366,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddConversionFunc((*v1beta1.DataDisk)(nil), (*DataDisk)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_DataDisk_To_v1alpha3_DataDisk(a.(*v1beta1.DataDisk), b.(*DataDisk), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddConversionFunc((*v1beta1.DiffDiskSettings)(nil), (*DiffDiskSettings)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_DiffDiskSettings_To_v1alpha3_DiffDiskSettings(a.(*v1beta1.DiffDiskSettings), b.(*DiffDiskSettings), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddConversionFunc((*v1beta1.FrontendIP)(nil), (*FrontendIP)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta1_FrontendIP_To_v1alpha3_FrontendIP(a.(*v1beta1.FrontendIP), b.(*FrontendIP), scope)
 	}); err != nil {
@@ -391,6 +386,11 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddConversionFunc((*v1beta1.Image)(nil), (*Image)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_Image_To_v1alpha3_Image(a.(*v1beta1.Image), b.(*Image), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddConversionFunc((*v1beta1.LoadBalancerSpec)(nil), (*LoadBalancerSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta1_LoadBalancerSpec_To_v1alpha3_LoadBalancerSpec(a.(*v1beta1.LoadBalancerSpec), b.(*LoadBalancerSpec), scope)
 	}); err != nil {
@@ -689,6 +689,8 @@ func autoConvert_v1beta1_AzureClusterSpec_To_v1alpha3_AzureClusterSpec(in *v1bet
 	if err := apiv1alpha3.Convert_v1beta1_APIEndpoint_To_v1alpha3_APIEndpoint(&in.ControlPlaneEndpoint, &out.ControlPlaneEndpoint, s); err != nil {
 		return err
 	}
+	// WARNING: in.CapacityReservationGroups requires manual conversion: does not exist in peer-type
+	// WARNING: in.Hibernate requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -748,6 +750,7 @@ func autoConvert_v1beta1_AzureClusterStatus_To_v1alpha3_AzureClusterStatus(in *v
 		out.Conditions = nil
 	}
 	// WARNING: in.LongRunningOperationStates requires manual conversion: does not exist in peer-type
+	// WARNING: in.CapacityReservationGroups requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -870,6 +873,7 @@ func autoConvert_v1alpha3_AzureMachineSpec_To_v1beta1_AzureMachineSpec(in *Azure
 func autoConvert_v1beta1_AzureMachineSpec_To_v1alpha3_AzureMachineSpec(in *v1beta1.AzureMachineSpec, out *AzureMachineSpec, s conversion.Scope) error {
 	out.ProviderID = (*string)(unsafe.Pointer(in.ProviderID))
 	out.VMSize = in.VMSize
+	// WARNING: in.EnableInPlaceResize requires manual conversion: does not exist in peer-type
 	out.FailureDomain = (*string)(unsafe.Pointer(in.FailureDomain))
 	if in.Image != nil {
 		in, out := &in.Image, &out.Image
@@ -897,6 +901,8 @@ func autoConvert_v1beta1_AzureMachineSpec_To_v1alpha3_AzureMachineSpec(in *v1bet
 	} else {
 		out.DataDisks = nil
 	}
+	// WARNING: in.DataDiskRemovalPolicy requires manual conversion: does not exist in peer-type
+	// WARNING: in.EnableUltraSSD requires manual conversion: does not exist in peer-type
 	out.SSHPublicKey = in.SSHPublicKey
 	out.AdditionalTags = *(*Tags)(unsafe.Pointer(&in.AdditionalTags))
 	out.AllocatePublicIP = in.AllocatePublicIP
@@ -904,7 +910,11 @@ func autoConvert_v1beta1_AzureMachineSpec_To_v1alpha3_AzureMachineSpec(in *v1bet
 	out.AcceleratedNetworking = (*bool)(unsafe.Pointer(in.AcceleratedNetworking))
 	out.SpotVMOptions = (*SpotVMOptions)(unsafe.Pointer(in.SpotVMOptions))
 	out.SecurityProfile = (*SecurityProfile)(unsafe.Pointer(in.SecurityProfile))
+	// WARNING: in.Diagnostics requires manual conversion: does not exist in peer-type
+	// WARNING: in.BootstrapDataDelivery requires manual conversion: does not exist in peer-type
+	// WARNING: in.BootstrapDataStorage requires manual conversion: does not exist in peer-type
 	// WARNING: in.SubnetName requires manual conversion: does not exist in peer-type
+	// WARNING: in.CapacityReservationGroupID requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -946,6 +956,8 @@ func autoConvert_v1beta1_AzureMachineStatus_To_v1alpha3_AzureMachineStatus(in *v
 		out.Conditions = nil
 	}
 	// WARNING: in.LongRunningOperationStates requires manual conversion: does not exist in peer-type
+	// WARNING: in.Image requires manual conversion: does not exist in peer-type
+	// WARNING: in.RemovedDataDisks requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -967,14 +979,10 @@ func autoConvert_v1beta1_AzureMachineTemplate_To_v1alpha3_AzureMachineTemplate(i
 	if err := Convert_v1beta1_AzureMachineTemplateSpec_To_v1alpha3_AzureMachineTemplateSpec(&in.Spec, &out.Spec, s); err != nil {
 		return err
 	}
+	// WARNING: in.Status requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1beta1_AzureMachineTemplate_To_v1alpha3_AzureMachineTemplate is an autogenerated conversion function.
-func Convert_v1beta1_AzureMachineTemplate_To_v1alpha3_AzureMachineTemplate(in *v1beta1.AzureMachineTemplate, out *AzureMachineTemplate, s conversion.Scope) error {
-	return autoConvert_v1beta1_AzureMachineTemplate_To_v1alpha3_AzureMachineTemplate(in, out, s)
-}
-
 func autoConvert_v1alpha3_AzureMachineTemplateList_To_v1beta1_AzureMachineTemplateList(in *AzureMachineTemplateList, out *v1beta1.AzureMachineTemplateList, s conversion.Scope) error {
 	out.ListMeta = in.ListMeta
 	if in.Items != nil {
@@ -1170,6 +1178,10 @@ func Convert_v1alpha3_DataDisk_To_v1beta1_DataDisk(in *DataDisk, out *v1beta1.Da
 func autoConvert_v1beta1_DataDisk_To_v1alpha3_DataDisk(in *v1beta1.DataDisk, out *DataDisk, s conversion.Scope) error {
 	out.NameSuffix = in.NameSuffix
 	out.DiskSizeGB = in.DiskSizeGB
+	// WARNING: in.SourceDiskID requires manual conversion: does not exist in peer-type
+	// WARNING: in.DiskIOPSReadWrite requires manual conversion: does not exist in peer-type
+	// WARNING: in.DiskMBpsReadWrite requires manual conversion: does not exist in peer-type
+	// WARNING: in.MaxShares requires manual conversion: does not exist in peer-type
 	if in.ManagedDisk != nil {
 		in, out := &in.ManagedDisk, &out.ManagedDisk
 		*out = new(ManagedDisk)
@@ -1184,11 +1196,6 @@ func autoConvert_v1beta1_DataDisk_To_v1alpha3_DataDisk(in *v1beta1.DataDisk, out
 	return nil
 }
 
-// Convert_v1beta1_DataDisk_To_v1alpha3_DataDisk is an autogenerated conversion function.
-func Convert_v1beta1_DataDisk_To_v1alpha3_DataDisk(in *v1beta1.DataDisk, out *DataDisk, s conversion.Scope) error {
-	return autoConvert_v1beta1_DataDisk_To_v1alpha3_DataDisk(in, out, s)
-}
-
 func autoConvert_v1alpha3_DiffDiskSettings_To_v1beta1_DiffDiskSettings(in *DiffDiskSettings, out *v1beta1.DiffDiskSettings, s conversion.Scope) error {
 	out.Option = in.Option
 	return nil
@@ -1201,14 +1208,10 @@ func Convert_v1alpha3_DiffDiskSettings_To_v1beta1_DiffDiskSettings(in *DiffDiskS
 
 func autoConvert_v1beta1_DiffDiskSettings_To_v1alpha3_DiffDiskSettings(in *v1beta1.DiffDiskSettings, out *DiffDiskSettings, s conversion.Scope) error {
 	out.Option = in.Option
+	// WARNING: in.Placement requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1beta1_DiffDiskSettings_To_v1alpha3_DiffDiskSettings is an autogenerated conversion function.
-func Convert_v1beta1_DiffDiskSettings_To_v1alpha3_DiffDiskSettings(in *v1beta1.DiffDiskSettings, out *DiffDiskSettings, s conversion.Scope) error {
-	return autoConvert_v1beta1_DiffDiskSettings_To_v1alpha3_DiffDiskSettings(in, out, s)
-}
-
 func autoConvert_v1alpha3_DiskEncryptionSetParameters_To_v1beta1_DiskEncryptionSetParameters(in *DiskEncryptionSetParameters, out *v1beta1.DiskEncryptionSetParameters, s conversion.Scope) error {
 	out.ID = in.ID
 	return nil
@@ -1292,14 +1295,11 @@ func autoConvert_v1beta1_Image_To_v1alpha3_Image(in *v1beta1.Image, out *Image,
 		out.SharedGallery = nil
 	}
 	out.Marketplace = (*AzureMarketplaceImage)(unsafe.Pointer(in.Marketplace))
+	// WARNING: in.CommunityGallery requires manual conversion: does not exist in peer-type
+	// WARNING: in.DirectSharedGallery requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1beta1_Image_To_v1alpha3_Image is an autogenerated conversion function.
-func Convert_v1beta1_Image_To_v1alpha3_Image(in *v1beta1.Image, out *Image, s conversion.Scope) error {
-	return autoConvert_v1beta1_Image_To_v1alpha3_Image(in, out, s)
-}
-
 func autoConvert_v1alpha3_LoadBalancerSpec_To_v1beta1_LoadBalancerSpec(in *LoadBalancerSpec, out *v1beta1.LoadBalancerSpec, s conversion.Scope) error {
 	out.ID = in.ID
 	out.Name = in.Name
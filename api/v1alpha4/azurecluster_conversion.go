@@ -42,6 +42,13 @@ func (src *AzureCluster) ConvertTo(dstRaw conversion.Hub) error {
 	// Restore list of virtual network peerings
 	dst.Spec.NetworkSpec.Vnet.Peerings = restored.Spec.NetworkSpec.Vnet.Peerings
 
+	// Restore capacity reservation groups
+	dst.Spec.CapacityReservationGroups = restored.Spec.CapacityReservationGroups
+	dst.Status.CapacityReservationGroups = restored.Status.CapacityReservationGroups
+
+	// Restore hibernation
+	dst.Spec.Hibernate = restored.Spec.Hibernate
+
 	return nil
 }
 
@@ -128,6 +135,11 @@ func Convert_v1beta1_AzureClusterSpec_To_v1alpha4_AzureClusterSpec(in *infrav1be
 	return nil
 }
 
+// Convert_v1beta1_AzureClusterStatus_To_v1alpha4_AzureClusterStatus converts from the Hub version (v1beta1) of the AzureClusterStatus to this version.
+func Convert_v1beta1_AzureClusterStatus_To_v1alpha4_AzureClusterStatus(in *infrav1beta1.AzureClusterStatus, out *AzureClusterStatus, s apiconversion.Scope) error {
+	return autoConvert_v1beta1_AzureClusterStatus_To_v1alpha4_AzureClusterStatus(in, out, s)
+}
+
 // Convert_v1alpha4_FrontendIP_To_v1beta1_FrontendIP is an autogenerated conversion function.
 func Convert_v1alpha4_FrontendIP_To_v1beta1_FrontendIP(in *FrontendIP, out *infrav1beta1.FrontendIP, s apiconversion.Scope) error { //nolint
 	if err := autoConvert_v1alpha4_FrontendIP_To_v1beta1_FrontendIP(in, out, s); err != nil {
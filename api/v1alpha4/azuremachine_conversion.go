@@ -1,36 +1,50 @@
-/*
-Copyright 2021 The Kubernetes Authors.
-
-Licensed under the Apache License, Version 2.0 (the "License");
-you may not use this file except in compliance with the License.
-You may obtain a copy of the License at
-
-    http://www.apache.org/licenses/LICENSE-2.0
-
-Unless required by applicable law or agreed to in writing, software
-distributed under the License is distributed on an "AS IS" BASIS,
-WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
-See the License for the specific language governing permissions and
-limitations under the License.
-*/
-
 package v1alpha4
 
 import (
+	apiconversion "k8s.io/apimachinery/pkg/conversion"
 	"sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	utilconversion "sigs.k8s.io/cluster-api/util/conversion"
 	"sigs.k8s.io/controller-runtime/pkg/conversion"
 )
 
 // ConvertTo converts this AzureMachine to the Hub version (v1beta1).
 func (src *AzureMachine) ConvertTo(dstRaw conversion.Hub) error {
 	dst := dstRaw.(*v1beta1.AzureMachine)
-	return Convert_v1alpha4_AzureMachine_To_v1beta1_AzureMachine(src, dst, nil)
+	if err := Convert_v1alpha4_AzureMachine_To_v1beta1_AzureMachine(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Restore missing fields from annotations
+	restored := &v1beta1.AzureMachine{}
+	if ok, err := utilconversion.UnmarshalData(src, restored); err != nil || !ok {
+		return err
+	}
+
+	dst.Spec.CapacityReservationGroupID = restored.Spec.CapacityReservationGroupID
+	dst.Spec.EnableInPlaceResize = restored.Spec.EnableInPlaceResize
+	dst.Spec.DataDiskRemovalPolicy = restored.Spec.DataDiskRemovalPolicy
+	dst.Spec.EnableUltraSSD = restored.Spec.EnableUltraSSD
+	dst.Spec.Diagnostics = restored.Spec.Diagnostics
+	dst.Spec.BootstrapDataDelivery = restored.Spec.BootstrapDataDelivery
+	dst.Spec.BootstrapDataStorage = restored.Spec.BootstrapDataStorage
+	restoreDataDisks(dst.Spec.DataDisks, restored.Spec.DataDisks)
+	restoreOSDisk(&dst.Spec.OSDisk, &restored.Spec.OSDisk)
+	restoreImage(dst.Spec.Image, restored.Spec.Image)
+	dst.Status.Image = restored.Status.Image
+	dst.Status.RemovedDataDisks = restored.Status.RemovedDataDisks
+
+	return nil
 }
 
 // ConvertFrom converts from the Hub version (v1beta1) to this version.
 func (dst *AzureMachine) ConvertFrom(srcRaw conversion.Hub) error {
 	src := srcRaw.(*v1beta1.AzureMachine)
-	return Convert_v1beta1_AzureMachine_To_v1alpha4_AzureMachine(src, dst, nil)
+	if err := Convert_v1beta1_AzureMachine_To_v1alpha4_AzureMachine(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Preserve Hub data on down-conversion.
+	return utilconversion.MarshalData(src, dst)
 }
 
 // ConvertTo converts this AzureMachineList to the Hub version (v1beta1).
@@ -44,3 +58,57 @@ func (dst *AzureMachineList) ConvertFrom(srcRaw conversion.Hub) error {
 	src := srcRaw.(*v1beta1.AzureMachineList)
 	return Convert_v1beta1_AzureMachineList_To_v1alpha4_AzureMachineList(src, dst, nil)
 }
+
+// Convert_v1beta1_AzureMachineSpec_To_v1alpha4_AzureMachineSpec converts from the Hub version (v1beta1) of the AzureMachineSpec to this version.
+func Convert_v1beta1_AzureMachineSpec_To_v1alpha4_AzureMachineSpec(in *v1beta1.AzureMachineSpec, out *AzureMachineSpec, s apiconversion.Scope) error {
+	return autoConvert_v1beta1_AzureMachineSpec_To_v1alpha4_AzureMachineSpec(in, out, s)
+}
+
+// Convert_v1beta1_AzureMachineStatus_To_v1alpha4_AzureMachineStatus converts from the Hub version (v1beta1) of the AzureMachineStatus to this version.
+func Convert_v1beta1_AzureMachineStatus_To_v1alpha4_AzureMachineStatus(in *v1beta1.AzureMachineStatus, out *AzureMachineStatus, s apiconversion.Scope) error {
+	return autoConvert_v1beta1_AzureMachineStatus_To_v1alpha4_AzureMachineStatus(in, out, s)
+}
+
+// Convert_v1beta1_DataDisk_To_v1alpha4_DataDisk converts from the Hub version (v1beta1) of the DataDisk to this version.
+func Convert_v1beta1_DataDisk_To_v1alpha4_DataDisk(in *v1beta1.DataDisk, out *DataDisk, s apiconversion.Scope) error {
+	return autoConvert_v1beta1_DataDisk_To_v1alpha4_DataDisk(in, out, s)
+}
+
+// Convert_v1beta1_DiffDiskSettings_To_v1alpha4_DiffDiskSettings converts from the Hub version (v1beta1) of the DiffDiskSettings to this version.
+func Convert_v1beta1_DiffDiskSettings_To_v1alpha4_DiffDiskSettings(in *v1beta1.DiffDiskSettings, out *DiffDiskSettings, s apiconversion.Scope) error {
+	return autoConvert_v1beta1_DiffDiskSettings_To_v1alpha4_DiffDiskSettings(in, out, s)
+}
+
+// restoreOSDisk restores the fields of the OS disk that don't exist in this version.
+func restoreOSDisk(dst, restored *v1beta1.OSDisk) {
+	if dst.DiffDiskSettings != nil && restored.DiffDiskSettings != nil {
+		dst.DiffDiskSettings.Placement = restored.DiffDiskSettings.Placement
+	}
+}
+
+// restoreDataDisks restores the fields of the data disks that don't exist in this version.
+func restoreDataDisks(dst, restored []v1beta1.DataDisk) {
+	if len(dst) != len(restored) {
+		return
+	}
+	for i := range dst {
+		dst[i].SourceDiskID = restored[i].SourceDiskID
+		dst[i].DiskIOPSReadWrite = restored[i].DiskIOPSReadWrite
+		dst[i].DiskMBpsReadWrite = restored[i].DiskMBpsReadWrite
+		dst[i].MaxShares = restored[i].MaxShares
+	}
+}
+
+// restoreImage restores the fields of the image that don't exist in this version.
+func restoreImage(dst, restored *v1beta1.Image) {
+	if dst == nil || restored == nil {
+		return
+	}
+	dst.CommunityGallery = restored.CommunityGallery
+	dst.DirectSharedGallery = restored.DirectSharedGallery
+}
+
+// Convert_v1beta1_Image_To_v1alpha4_Image converts from the Hub version (v1beta1) of the Image to this version.
+func Convert_v1beta1_Image_To_v1alpha4_Image(in *v1beta1.Image, out *Image, s apiconversion.Scope) error {
+	return autoConvert_v1beta1_Image_To_v1alpha4_Image(in, out, s)
+}
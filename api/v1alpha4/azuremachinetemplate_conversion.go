@@ -37,6 +37,17 @@ func (src *AzureMachineTemplate) ConvertTo(dstRaw conversion.Hub) error {
 	}
 
 	dst.Spec.Template.ObjectMeta = restored.Spec.Template.ObjectMeta
+	dst.Spec.Template.Spec.CapacityReservationGroupID = restored.Spec.Template.Spec.CapacityReservationGroupID
+	dst.Spec.Template.Spec.EnableInPlaceResize = restored.Spec.Template.Spec.EnableInPlaceResize
+	dst.Spec.Template.Spec.DataDiskRemovalPolicy = restored.Spec.Template.Spec.DataDiskRemovalPolicy
+	dst.Spec.Template.Spec.EnableUltraSSD = restored.Spec.Template.Spec.EnableUltraSSD
+	dst.Spec.Template.Spec.Diagnostics = restored.Spec.Template.Spec.Diagnostics
+	dst.Spec.Template.Spec.BootstrapDataDelivery = restored.Spec.Template.Spec.BootstrapDataDelivery
+	dst.Spec.Template.Spec.BootstrapDataStorage = restored.Spec.Template.Spec.BootstrapDataStorage
+	restoreDataDisks(dst.Spec.Template.Spec.DataDisks, restored.Spec.Template.Spec.DataDisks)
+	restoreOSDisk(&dst.Spec.Template.Spec.OSDisk, &restored.Spec.Template.Spec.OSDisk)
+	restoreImage(dst.Spec.Template.Spec.Image, restored.Spec.Template.Spec.Image)
+	dst.Status = restored.Status
 
 	return nil
 }
@@ -67,3 +78,8 @@ func (dst *AzureMachineTemplateList) ConvertFrom(srcRaw conversion.Hub) error {
 func Convert_v1beta1_AzureMachineTemplateResource_To_v1alpha4_AzureMachineTemplateResource(in *infrav1beta1.AzureMachineTemplateResource, out *AzureMachineTemplateResource, s apimachineryconversion.Scope) error {
 	return autoConvert_v1beta1_AzureMachineTemplateResource_To_v1alpha4_AzureMachineTemplateResource(in, out, s)
 }
+
+// Convert_v1beta1_AzureMachineTemplate_To_v1alpha4_AzureMachineTemplate converts from the Hub version (v1beta1) of the AzureMachineTemplate to this version.
+func Convert_v1beta1_AzureMachineTemplate_To_v1alpha4_AzureMachineTemplate(in *infrav1beta1.AzureMachineTemplate, out *AzureMachineTemplate, s apimachineryconversion.Scope) error {
+	return autoConvert_v1beta1_AzureMachineTemplate_To_v1alpha4_AzureMachineTemplate(in, out, s)
+}
@@ -137,11 +137,6 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1beta1.AzureClusterStatus)(nil), (*AzureClusterStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1beta1_AzureClusterStatus_To_v1alpha4_AzureClusterStatus(a.(*v1beta1.AzureClusterStatus), b.(*AzureClusterStatus), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*AzureMachine)(nil), (*v1beta1.AzureMachine)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha4_AzureMachine_To_v1beta1_AzureMachine(a.(*AzureMachine), b.(*v1beta1.AzureMachine), scope)
 	}); err != nil {
@@ -167,31 +162,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1beta1.AzureMachineSpec)(nil), (*AzureMachineSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1beta1_AzureMachineSpec_To_v1alpha4_AzureMachineSpec(a.(*v1beta1.AzureMachineSpec), b.(*AzureMachineSpec), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*AzureMachineStatus)(nil), (*v1beta1.AzureMachineStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha4_AzureMachineStatus_To_v1beta1_AzureMachineStatus(a.(*AzureMachineStatus), b.(*v1beta1.AzureMachineStatus), scope)
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1beta1.AzureMachineStatus)(nil), (*AzureMachineStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1beta1_AzureMachineStatus_To_v1alpha4_AzureMachineStatus(a.(*v1beta1.AzureMachineStatus), b.(*AzureMachineStatus), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*AzureMachineTemplate)(nil), (*v1beta1.AzureMachineTemplate)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha4_AzureMachineTemplate_To_v1beta1_AzureMachineTemplate(a.(*AzureMachineTemplate), b.(*v1beta1.AzureMachineTemplate), scope)
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1beta1.AzureMachineTemplate)(nil), (*AzureMachineTemplate)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1beta1_AzureMachineTemplate_To_v1alpha4_AzureMachineTemplate(a.(*v1beta1.AzureMachineTemplate), b.(*AzureMachineTemplate), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*AzureMachineTemplateList)(nil), (*v1beta1.AzureMachineTemplateList)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha4_AzureMachineTemplateList_To_v1beta1_AzureMachineTemplateList(a.(*AzureMachineTemplateList), b.(*v1beta1.AzureMachineTemplateList), scope)
 	}); err != nil {
@@ -282,21 +262,11 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1beta1.DataDisk)(nil), (*DataDisk)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1beta1_DataDisk_To_v1alpha4_DataDisk(a.(*v1beta1.DataDisk), b.(*DataDisk), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*DiffDiskSettings)(nil), (*v1beta1.DiffDiskSettings)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha4_DiffDiskSettings_To_v1beta1_DiffDiskSettings(a.(*DiffDiskSettings), b.(*v1beta1.DiffDiskSettings), scope)
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1beta1.DiffDiskSettings)(nil), (*DiffDiskSettings)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1beta1_DiffDiskSettings_To_v1alpha4_DiffDiskSettings(a.(*v1beta1.DiffDiskSettings), b.(*DiffDiskSettings), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*DiskEncryptionSetParameters)(nil), (*v1beta1.DiskEncryptionSetParameters)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha4_DiskEncryptionSetParameters_To_v1beta1_DiskEncryptionSetParameters(a.(*DiskEncryptionSetParameters), b.(*v1beta1.DiskEncryptionSetParameters), scope)
 	}); err != nil {
@@ -322,11 +292,6 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1beta1.Image)(nil), (*Image)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1beta1_Image_To_v1alpha4_Image(a.(*v1beta1.Image), b.(*Image), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*ManagedDiskParameters)(nil), (*v1beta1.ManagedDiskParameters)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha4_ManagedDiskParameters_To_v1beta1_ManagedDiskParameters(a.(*ManagedDiskParameters), b.(*v1beta1.ManagedDiskParameters), scope)
 	}); err != nil {
@@ -472,16 +437,51 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddConversionFunc((*v1beta1.AzureClusterStatus)(nil), (*AzureClusterStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_AzureClusterStatus_To_v1alpha4_AzureClusterStatus(a.(*v1beta1.AzureClusterStatus), b.(*AzureClusterStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddConversionFunc((*v1beta1.AzureMachineSpec)(nil), (*AzureMachineSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_AzureMachineSpec_To_v1alpha4_AzureMachineSpec(a.(*v1beta1.AzureMachineSpec), b.(*AzureMachineSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddConversionFunc((*v1beta1.AzureMachineStatus)(nil), (*AzureMachineStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_AzureMachineStatus_To_v1alpha4_AzureMachineStatus(a.(*v1beta1.AzureMachineStatus), b.(*AzureMachineStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddConversionFunc((*v1beta1.AzureMachineTemplate)(nil), (*AzureMachineTemplate)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_AzureMachineTemplate_To_v1alpha4_AzureMachineTemplate(a.(*v1beta1.AzureMachineTemplate), b.(*AzureMachineTemplate), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddConversionFunc((*v1beta1.AzureMachineTemplateResource)(nil), (*AzureMachineTemplateResource)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta1_AzureMachineTemplateResource_To_v1alpha4_AzureMachineTemplateResource(a.(*v1beta1.AzureMachineTemplateResource), b.(*AzureMachineTemplateResource), scope)
 	}); err != nil {
 		return err
 	}
+	if err := s.AddConversionFunc((*v1beta1.DataDisk)(nil), (*DataDisk)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_DataDisk_To_v1alpha4_DataDisk(a.(*v1beta1.DataDisk), b.(*DataDisk), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddConversionFunc((*v1beta1.DiffDiskSettings)(nil), (*DiffDiskSettings)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_DiffDiskSettings_To_v1alpha4_DiffDiskSettings(a.(*v1beta1.DiffDiskSettings), b.(*DiffDiskSettings), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddConversionFunc((*v1beta1.FrontendIP)(nil), (*FrontendIP)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta1_FrontendIP_To_v1alpha4_FrontendIP(a.(*v1beta1.FrontendIP), b.(*FrontendIP), scope)
 	}); err != nil {
 		return err
 	}
+	if err := s.AddConversionFunc((*v1beta1.Image)(nil), (*Image)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_Image_To_v1alpha4_Image(a.(*v1beta1.Image), b.(*Image), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddConversionFunc((*v1beta1.LoadBalancerSpec)(nil), (*LoadBalancerSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta1_LoadBalancerSpec_To_v1alpha4_LoadBalancerSpec(a.(*v1beta1.LoadBalancerSpec), b.(*LoadBalancerSpec), scope)
 	}); err != nil {
@@ -841,6 +841,8 @@ func autoConvert_v1beta1_AzureClusterSpec_To_v1alpha4_AzureClusterSpec(in *v1bet
 	if err := apiv1alpha4.Convert_v1beta1_APIEndpoint_To_v1alpha4_APIEndpoint(&in.ControlPlaneEndpoint, &out.ControlPlaneEndpoint, s); err != nil {
 		return err
 	}
+	// WARNING: in.CapacityReservationGroups requires manual conversion: does not exist in peer-type
+	// WARNING: in.Hibernate requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -906,14 +908,10 @@ func autoConvert_v1beta1_AzureClusterStatus_To_v1alpha4_AzureClusterStatus(in *v
 		out.Conditions = nil
 	}
 	out.LongRunningOperationStates = *(*Futures)(unsafe.Pointer(&in.LongRunningOperationStates))
+	// WARNING: in.CapacityReservationGroups requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1beta1_AzureClusterStatus_To_v1alpha4_AzureClusterStatus is an autogenerated conversion function.
-func Convert_v1beta1_AzureClusterStatus_To_v1alpha4_AzureClusterStatus(in *v1beta1.AzureClusterStatus, out *AzureClusterStatus, s conversion.Scope) error {
-	return autoConvert_v1beta1_AzureClusterStatus_To_v1alpha4_AzureClusterStatus(in, out, s)
-}
-
 func autoConvert_v1alpha4_AzureMachine_To_v1beta1_AzureMachine(in *AzureMachine, out *v1beta1.AzureMachine, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
 	if err := Convert_v1alpha4_AzureMachineSpec_To_v1beta1_AzureMachineSpec(&in.Spec, &out.Spec, s); err != nil {
@@ -992,14 +990,32 @@ func autoConvert_v1alpha4_AzureMachineSpec_To_v1beta1_AzureMachineSpec(in *Azure
 	out.ProviderID = (*string)(unsafe.Pointer(in.ProviderID))
 	out.VMSize = in.VMSize
 	out.FailureDomain = (*string)(unsafe.Pointer(in.FailureDomain))
-	out.Image = (*v1beta1.Image)(unsafe.Pointer(in.Image))
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(v1beta1.Image)
+		if err := Convert_v1alpha4_Image_To_v1beta1_Image(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Image = nil
+	}
 	out.Identity = v1beta1.VMIdentity(in.Identity)
 	out.UserAssignedIdentities = *(*[]v1beta1.UserAssignedIdentity)(unsafe.Pointer(&in.UserAssignedIdentities))
 	out.RoleAssignmentName = in.RoleAssignmentName
 	if err := Convert_v1alpha4_OSDisk_To_v1beta1_OSDisk(&in.OSDisk, &out.OSDisk, s); err != nil {
 		return err
 	}
-	out.DataDisks = *(*[]v1beta1.DataDisk)(unsafe.Pointer(&in.DataDisks))
+	if in.DataDisks != nil {
+		in, out := &in.DataDisks, &out.DataDisks
+		*out = make([]v1beta1.DataDisk, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha4_DataDisk_To_v1beta1_DataDisk(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.DataDisks = nil
+	}
 	out.SSHPublicKey = in.SSHPublicKey
 	out.AdditionalTags = *(*v1beta1.Tags)(unsafe.Pointer(&in.AdditionalTags))
 	out.AllocatePublicIP = in.AllocatePublicIP
@@ -1019,15 +1035,36 @@ func Convert_v1alpha4_AzureMachineSpec_To_v1beta1_AzureMachineSpec(in *AzureMach
 func autoConvert_v1beta1_AzureMachineSpec_To_v1alpha4_AzureMachineSpec(in *v1beta1.AzureMachineSpec, out *AzureMachineSpec, s conversion.Scope) error {
 	out.ProviderID = (*string)(unsafe.Pointer(in.ProviderID))
 	out.VMSize = in.VMSize
+	// WARNING: in.EnableInPlaceResize requires manual conversion: does not exist in peer-type
 	out.FailureDomain = (*string)(unsafe.Pointer(in.FailureDomain))
-	out.Image = (*Image)(unsafe.Pointer(in.Image))
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(Image)
+		if err := Convert_v1beta1_Image_To_v1alpha4_Image(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Image = nil
+	}
 	out.Identity = VMIdentity(in.Identity)
 	out.UserAssignedIdentities = *(*[]UserAssignedIdentity)(unsafe.Pointer(&in.UserAssignedIdentities))
 	out.RoleAssignmentName = in.RoleAssignmentName
 	if err := Convert_v1beta1_OSDisk_To_v1alpha4_OSDisk(&in.OSDisk, &out.OSDisk, s); err != nil {
 		return err
 	}
-	out.DataDisks = *(*[]DataDisk)(unsafe.Pointer(&in.DataDisks))
+	if in.DataDisks != nil {
+		in, out := &in.DataDisks, &out.DataDisks
+		*out = make([]DataDisk, len(*in))
+		for i := range *in {
+			if err := Convert_v1beta1_DataDisk_To_v1alpha4_DataDisk(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.DataDisks = nil
+	}
+	// WARNING: in.DataDiskRemovalPolicy requires manual conversion: does not exist in peer-type
+	// WARNING: in.EnableUltraSSD requires manual conversion: does not exist in peer-type
 	out.SSHPublicKey = in.SSHPublicKey
 	out.AdditionalTags = *(*Tags)(unsafe.Pointer(&in.AdditionalTags))
 	out.AllocatePublicIP = in.AllocatePublicIP
@@ -1035,15 +1072,14 @@ func autoConvert_v1beta1_AzureMachineSpec_To_v1alpha4_AzureMachineSpec(in *v1bet
 	out.AcceleratedNetworking = (*bool)(unsafe.Pointer(in.AcceleratedNetworking))
 	out.SpotVMOptions = (*SpotVMOptions)(unsafe.Pointer(in.SpotVMOptions))
 	out.SecurityProfile = (*SecurityProfile)(unsafe.Pointer(in.SecurityProfile))
+	// WARNING: in.Diagnostics requires manual conversion: does not exist in peer-type
+	// WARNING: in.BootstrapDataDelivery requires manual conversion: does not exist in peer-type
+	// WARNING: in.BootstrapDataStorage requires manual conversion: does not exist in peer-type
 	out.SubnetName = in.SubnetName
+	// WARNING: in.CapacityReservationGroupID requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1beta1_AzureMachineSpec_To_v1alpha4_AzureMachineSpec is an autogenerated conversion function.
-func Convert_v1beta1_AzureMachineSpec_To_v1alpha4_AzureMachineSpec(in *v1beta1.AzureMachineSpec, out *AzureMachineSpec, s conversion.Scope) error {
-	return autoConvert_v1beta1_AzureMachineSpec_To_v1alpha4_AzureMachineSpec(in, out, s)
-}
-
 func autoConvert_v1alpha4_AzureMachineStatus_To_v1beta1_AzureMachineStatus(in *AzureMachineStatus, out *v1beta1.AzureMachineStatus, s conversion.Scope) error {
 	out.Ready = in.Ready
 	out.Addresses = *(*[]corev1.NodeAddress)(unsafe.Pointer(&in.Addresses))
@@ -1088,14 +1124,11 @@ func autoConvert_v1beta1_AzureMachineStatus_To_v1alpha4_AzureMachineStatus(in *v
 		out.Conditions = nil
 	}
 	out.LongRunningOperationStates = *(*Futures)(unsafe.Pointer(&in.LongRunningOperationStates))
+	// WARNING: in.Image requires manual conversion: does not exist in peer-type
+	// WARNING: in.RemovedDataDisks requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1beta1_AzureMachineStatus_To_v1alpha4_AzureMachineStatus is an autogenerated conversion function.
-func Convert_v1beta1_AzureMachineStatus_To_v1alpha4_AzureMachineStatus(in *v1beta1.AzureMachineStatus, out *AzureMachineStatus, s conversion.Scope) error {
-	return autoConvert_v1beta1_AzureMachineStatus_To_v1alpha4_AzureMachineStatus(in, out, s)
-}
-
 func autoConvert_v1alpha4_AzureMachineTemplate_To_v1beta1_AzureMachineTemplate(in *AzureMachineTemplate, out *v1beta1.AzureMachineTemplate, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
 	if err := Convert_v1alpha4_AzureMachineTemplateSpec_To_v1beta1_AzureMachineTemplateSpec(&in.Spec, &out.Spec, s); err != nil {
@@ -1114,14 +1147,10 @@ func autoConvert_v1beta1_AzureMachineTemplate_To_v1alpha4_AzureMachineTemplate(i
 	if err := Convert_v1beta1_AzureMachineTemplateSpec_To_v1alpha4_AzureMachineTemplateSpec(&in.Spec, &out.Spec, s); err != nil {
 		return err
 	}
+	// WARNING: in.Status requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1beta1_AzureMachineTemplate_To_v1alpha4_AzureMachineTemplate is an autogenerated conversion function.
-func Convert_v1beta1_AzureMachineTemplate_To_v1alpha4_AzureMachineTemplate(in *v1beta1.AzureMachineTemplate, out *AzureMachineTemplate, s conversion.Scope) error {
-	return autoConvert_v1beta1_AzureMachineTemplate_To_v1alpha4_AzureMachineTemplate(in, out, s)
-}
-
 func autoConvert_v1alpha4_AzureMachineTemplateList_To_v1beta1_AzureMachineTemplateList(in *AzureMachineTemplateList, out *v1beta1.AzureMachineTemplateList, s conversion.Scope) error {
 	out.ListMeta = in.ListMeta
 	if in.Items != nil {
@@ -1407,17 +1436,16 @@ func Convert_v1alpha4_DataDisk_To_v1beta1_DataDisk(in *DataDisk, out *v1beta1.Da
 func autoConvert_v1beta1_DataDisk_To_v1alpha4_DataDisk(in *v1beta1.DataDisk, out *DataDisk, s conversion.Scope) error {
 	out.NameSuffix = in.NameSuffix
 	out.DiskSizeGB = in.DiskSizeGB
+	// WARNING: in.SourceDiskID requires manual conversion: does not exist in peer-type
+	// WARNING: in.DiskIOPSReadWrite requires manual conversion: does not exist in peer-type
+	// WARNING: in.DiskMBpsReadWrite requires manual conversion: does not exist in peer-type
+	// WARNING: in.MaxShares requires manual conversion: does not exist in peer-type
 	out.ManagedDisk = (*ManagedDiskParameters)(unsafe.Pointer(in.ManagedDisk))
 	out.Lun = (*int32)(unsafe.Pointer(in.Lun))
 	out.CachingType = in.CachingType
 	return nil
 }
 
-// Convert_v1beta1_DataDisk_To_v1alpha4_DataDisk is an autogenerated conversion function.
-func Convert_v1beta1_DataDisk_To_v1alpha4_DataDisk(in *v1beta1.DataDisk, out *DataDisk, s conversion.Scope) error {
-	return autoConvert_v1beta1_DataDisk_To_v1alpha4_DataDisk(in, out, s)
-}
-
 func autoConvert_v1alpha4_DiffDiskSettings_To_v1beta1_DiffDiskSettings(in *DiffDiskSettings, out *v1beta1.DiffDiskSettings, s conversion.Scope) error {
 	out.Option = in.Option
 	return nil
@@ -1430,14 +1458,10 @@ func Convert_v1alpha4_DiffDiskSettings_To_v1beta1_DiffDiskSettings(in *DiffDiskS
 
 func autoConvert_v1beta1_DiffDiskSettings_To_v1alpha4_DiffDiskSettings(in *v1beta1.DiffDiskSettings, out *DiffDiskSettings, s conversion.Scope) error {
 	out.Option = in.Option
+	// WARNING: in.Placement requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1beta1_DiffDiskSettings_To_v1alpha4_DiffDiskSettings is an autogenerated conversion function.
-func Convert_v1beta1_DiffDiskSettings_To_v1alpha4_DiffDiskSettings(in *v1beta1.DiffDiskSettings, out *DiffDiskSettings, s conversion.Scope) error {
-	return autoConvert_v1beta1_DiffDiskSettings_To_v1alpha4_DiffDiskSettings(in, out, s)
-}
-
 func autoConvert_v1alpha4_DiskEncryptionSetParameters_To_v1beta1_DiskEncryptionSetParameters(in *DiskEncryptionSetParameters, out *v1beta1.DiskEncryptionSetParameters, s conversion.Scope) error {
 	out.ID = in.ID
 	return nil
@@ -1516,14 +1540,11 @@ func autoConvert_v1beta1_Image_To_v1alpha4_Image(in *v1beta1.Image, out *Image,
 	out.ID = (*string)(unsafe.Pointer(in.ID))
 	out.SharedGallery = (*AzureSharedGalleryImage)(unsafe.Pointer(in.SharedGallery))
 	out.Marketplace = (*AzureMarketplaceImage)(unsafe.Pointer(in.Marketplace))
+	// WARNING: in.CommunityGallery requires manual conversion: does not exist in peer-type
+	// WARNING: in.DirectSharedGallery requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1beta1_Image_To_v1alpha4_Image is an autogenerated conversion function.
-func Convert_v1beta1_Image_To_v1alpha4_Image(in *v1beta1.Image, out *Image, s conversion.Scope) error {
-	return autoConvert_v1beta1_Image_To_v1alpha4_Image(in, out, s)
-}
-
 func autoConvert_v1alpha4_LoadBalancerSpec_To_v1beta1_LoadBalancerSpec(in *LoadBalancerSpec, out *v1beta1.LoadBalancerSpec, s conversion.Scope) error {
 	out.ID = in.ID
 	out.Name = in.Name
@@ -1688,7 +1709,15 @@ func autoConvert_v1alpha4_OSDisk_To_v1beta1_OSDisk(in *OSDisk, out *v1beta1.OSDi
 	out.OSType = in.OSType
 	out.DiskSizeGB = (*int32)(unsafe.Pointer(in.DiskSizeGB))
 	out.ManagedDisk = (*v1beta1.ManagedDiskParameters)(unsafe.Pointer(in.ManagedDisk))
-	out.DiffDiskSettings = (*v1beta1.DiffDiskSettings)(unsafe.Pointer(in.DiffDiskSettings))
+	if in.DiffDiskSettings != nil {
+		in, out := &in.DiffDiskSettings, &out.DiffDiskSettings
+		*out = new(v1beta1.DiffDiskSettings)
+		if err := Convert_v1alpha4_DiffDiskSettings_To_v1beta1_DiffDiskSettings(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.DiffDiskSettings = nil
+	}
 	out.CachingType = in.CachingType
 	return nil
 }
@@ -1702,7 +1731,15 @@ func autoConvert_v1beta1_OSDisk_To_v1alpha4_OSDisk(in *v1beta1.OSDisk, out *OSDi
 	out.OSType = in.OSType
 	out.DiskSizeGB = (*int32)(unsafe.Pointer(in.DiskSizeGB))
 	out.ManagedDisk = (*ManagedDiskParameters)(unsafe.Pointer(in.ManagedDisk))
-	out.DiffDiskSettings = (*DiffDiskSettings)(unsafe.Pointer(in.DiffDiskSettings))
+	if in.DiffDiskSettings != nil {
+		in, out := &in.DiffDiskSettings, &out.DiffDiskSettings
+		*out = new(DiffDiskSettings)
+		if err := Convert_v1beta1_DiffDiskSettings_To_v1alpha4_DiffDiskSettings(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.DiffDiskSettings = nil
+	}
 	out.CachingType = in.CachingType
 	return nil
 }
@@ -49,6 +49,17 @@ type AzureClusterSpec struct {
 	// this when creating an AzureCluster as CAPZ will set this for you. However, if it is set, CAPZ will not change it.
 	// +optional
 	ControlPlaneEndpoint clusterv1.APIEndpoint `json:"controlPlaneEndpoint,omitempty"`
+
+	// CapacityReservationGroups is a list of on-demand capacity reservation groups to create in the cluster's resource group.
+	// Machines can be bound to one of these groups by setting their CapacityReservationGroupID.
+	// +optional
+	CapacityReservationGroups []CapacityReservationGroup `json:"capacityReservationGroups,omitempty"`
+
+	// Hibernate deallocates the VMs of all the AzureMachines of the cluster and scales its AzureMachinePools to zero,
+	// keeping their disks and networking, to save costs while the cluster is not in use. Setting it back to false
+	// resumes the cluster: the control plane machines are started first, followed by the worker machines.
+	// +optional
+	Hibernate bool `json:"hibernate,omitempty"`
 }
 
 // AzureClusterStatus defines the observed state of AzureCluster.
@@ -74,6 +85,10 @@ type AzureClusterStatus struct {
 	// next reconciliation loop.
 	// +optional
 	LongRunningOperationStates Futures `json:"longRunningOperationStates,omitempty"`
+
+	// CapacityReservationGroups reports the reserved and used capacity of the cluster's capacity reservation groups.
+	// +optional
+	CapacityReservationGroups []CapacityReservationGroupStatus `json:"capacityReservationGroups,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -41,6 +41,10 @@ const (
 	// described in https://docs.microsoft.com/en-us/azure/azure-resource-manager/management/resource-name-rules.
 	subnetRegex       = `^[-\w\._]+$`
 	loadBalancerRegex = `^[-\w\._]+$`
+	// described in https://docs.microsoft.com/en-us/azure/azure-resource-manager/management/resource-name-rules#microsoftcompute.
+	capacityReservationRegex = `^[-\w\._]+$`
+	// described in https://docs.microsoft.com/en-us/rest/api/storageservices/naming-and-referencing-containers--blobs--and-metadata#container-names.
+	blobContainerNameRegex = `^[a-z0-9][a-z0-9-]{1,61}[a-z0-9]$`
 	// MaxLoadBalancerOutboundIPs is the maximum number of outbound IPs in a Standard LoadBalancer frontend configuration.
 	MaxLoadBalancerOutboundIPs = 16
 	// MinLBIdleTimeoutInMinutes is the minimum number of minutes for the LB idle timeout.
@@ -83,6 +87,13 @@ func (c *AzureCluster) validateClusterSpec(old *AzureCluster) field.ErrorList {
 	allErrs = append(allErrs, validateCloudProviderConfigOverrides(c.Spec.CloudProviderConfigOverrides, oldCloudProviderConfigOverrides,
 		field.NewPath("spec").Child("cloudProviderConfigOverrides"))...)
 
+	var oldCapacityReservationGroups []CapacityReservationGroup
+	if old != nil {
+		oldCapacityReservationGroups = old.Spec.CapacityReservationGroups
+	}
+	allErrs = append(allErrs, validateCapacityReservationGroups(c.Spec.CapacityReservationGroups, oldCapacityReservationGroups,
+		field.NewPath("spec").Child("capacityReservationGroups"))...)
+
 	return allErrs
 }
 
@@ -456,6 +467,111 @@ func validateCloudProviderConfigOverrides(oldConfig, newConfig *CloudProviderCon
 	return allErrs
 }
 
+// validateCapacityReservationGroups validates the capacity reservation groups of a cluster and the updates made to them.
+func validateCapacityReservationGroups(groups, old []CapacityReservationGroup, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	oldGroups := make(map[string]CapacityReservationGroup, len(old))
+	for _, group := range old {
+		oldGroups[group.Name] = group
+	}
+
+	// Reservation names must be unique across groups since they identify the reservation's long-running operations.
+	groupNames := make(map[string]struct{}, len(groups))
+	reservationNames := make(map[string]struct{})
+	for i, group := range groups {
+		groupPath := fldPath.Index(i)
+		if err := validateCapacityReservationName(group.Name, groupPath.Child("name")); err != nil {
+			allErrs = append(allErrs, err)
+		}
+		if _, ok := groupNames[group.Name]; ok {
+			allErrs = append(allErrs, field.Duplicate(groupPath.Child("name"), group.Name))
+		}
+		groupNames[group.Name] = struct{}{}
+
+		oldGroup, exists := oldGroups[group.Name]
+		if exists && !reflect.DeepEqual(group.Zones, oldGroup.Zones) {
+			allErrs = append(allErrs, field.Invalid(groupPath.Child("zones"), group.Zones, "field is immutable"))
+		}
+
+		allErrs = append(allErrs, validateCapacityReservations(group, oldGroup.Reservations, reservationNames, groupPath.Child("reservations"))...)
+	}
+
+	return allErrs
+}
+
+// validateCapacityReservations validates the capacity reservations of a capacity reservation group.
+func validateCapacityReservations(group CapacityReservationGroup, old []CapacityReservation, names map[string]struct{}, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	oldReservations := make(map[string]CapacityReservation, len(old))
+	for _, reservation := range old {
+		oldReservations[reservation.Name] = reservation
+	}
+
+	zones := make(map[string]struct{}, len(group.Zones))
+	for _, zone := range group.Zones {
+		zones[zone] = struct{}{}
+	}
+
+	placements := make(map[string]struct{}, len(group.Reservations))
+	for i, reservation := range group.Reservations {
+		reservationPath := fldPath.Index(i)
+		if err := validateCapacityReservationName(reservation.Name, reservationPath.Child("name")); err != nil {
+			allErrs = append(allErrs, err)
+		}
+		if _, ok := names[reservation.Name]; ok {
+			allErrs = append(allErrs, field.Duplicate(reservationPath.Child("name"), reservation.Name))
+		}
+		names[reservation.Name] = struct{}{}
+
+		if reservation.VMSize == "" {
+			allErrs = append(allErrs, field.Required(reservationPath.Child("vmSize"), "the VM size of a capacity reservation cannot be empty"))
+		}
+
+		if reservation.Capacity < 0 {
+			allErrs = append(allErrs, field.Invalid(reservationPath.Child("capacity"), reservation.Capacity, "capacity must be greater than or equal to 0"))
+		}
+
+		switch {
+		case len(zones) == 0 && reservation.Zone != "":
+			allErrs = append(allErrs, field.Forbidden(reservationPath.Child("zone"), "zone cannot be set when the capacity reservation group has no zones"))
+		case len(zones) > 0 && reservation.Zone == "":
+			allErrs = append(allErrs, field.Required(reservationPath.Child("zone"), "zone is required when the capacity reservation group has zones"))
+		case len(zones) > 0:
+			if _, ok := zones[reservation.Zone]; !ok {
+				allErrs = append(allErrs, field.NotSupported(reservationPath.Child("zone"), reservation.Zone, group.Zones))
+			}
+		}
+
+		placement := reservation.VMSize + "/" + reservation.Zone
+		if _, ok := placements[placement]; ok {
+			allErrs = append(allErrs, field.Duplicate(reservationPath, fmt.Sprintf("vmSize %q in zone %q", reservation.VMSize, reservation.Zone)))
+		}
+		placements[placement] = struct{}{}
+
+		if oldReservation, ok := oldReservations[reservation.Name]; ok {
+			if reservation.VMSize != oldReservation.VMSize {
+				allErrs = append(allErrs, field.Invalid(reservationPath.Child("vmSize"), reservation.VMSize, "field is immutable"))
+			}
+			if reservation.Zone != oldReservation.Zone {
+				allErrs = append(allErrs, field.Invalid(reservationPath.Child("zone"), reservation.Zone, "field is immutable"))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+// validateCapacityReservationName validates the name of a capacity reservation group or capacity reservation.
+func validateCapacityReservationName(name string, fldPath *field.Path) *field.Error {
+	if success, _ := regexp.MatchString(capacityReservationRegex, name); !success {
+		return field.Invalid(fldPath, name,
+			fmt.Sprintf("name doesn't match regex %s", capacityReservationRegex))
+	}
+	return nil
+}
+
 func validateClassSpecForAPIServerLB(lb LoadBalancerClassSpec, old *LoadBalancerClassSpec, apiServerLBPath *field.Path) field.ErrorList {
 	var allErrs field.ErrorList
 
@@ -1286,6 +1286,155 @@ func TestValidateCloudProviderConfigOverrides(t *testing.T) {
 	}
 }
 
+func TestValidateCapacityReservationGroups(t *testing.T) {
+	g := NewWithT(t)
+
+	validGroup := func() CapacityReservationGroup {
+		return CapacityReservationGroup{
+			Name:  "my-crg",
+			Zones: []string{"1", "2"},
+			Reservations: []CapacityReservation{
+				{Name: "res-1", VMSize: "Standard_D2s_v3", Zone: "1", Capacity: 2},
+				{Name: "res-2", VMSize: "Standard_D2s_v3", Zone: "2", Capacity: 2},
+			},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		groups      []CapacityReservationGroup
+		old         []CapacityReservationGroup
+		wantErr     bool
+		expectedErr field.Error
+	}{
+		{
+			name:    "no capacity reservation groups",
+			wantErr: false,
+		},
+		{
+			name:    "valid zonal capacity reservation group",
+			groups:  []CapacityReservationGroup{validGroup()},
+			wantErr: false,
+		},
+		{
+			name: "valid regional capacity reservation group",
+			groups: []CapacityReservationGroup{{
+				Name:         "my-crg",
+				Reservations: []CapacityReservation{{Name: "res-1", VMSize: "Standard_D2s_v3", Capacity: 0}},
+			}},
+			wantErr: false,
+		},
+		{
+			name: "invalid group name",
+			groups: []CapacityReservationGroup{func() CapacityReservationGroup {
+				g := validGroup()
+				g.Name = "invalid name"
+				return g
+			}()},
+			wantErr: true,
+			expectedErr: field.Error{
+				Type:     "FieldValueInvalid",
+				Field:    "spec.capacityReservationGroups[0].name",
+				BadValue: "invalid name",
+				Detail:   "name doesn't match regex " + capacityReservationRegex,
+			},
+		},
+		{
+			name: "duplicate reservation names across groups",
+			groups: []CapacityReservationGroup{validGroup(), {
+				Name:         "other-crg",
+				Reservations: []CapacityReservation{{Name: "res-1", VMSize: "Standard_D4s_v3"}},
+			}},
+			wantErr: true,
+			expectedErr: field.Error{
+				Type:     "FieldValueDuplicate",
+				Field:    "spec.capacityReservationGroups[1].reservations[0].name",
+				BadValue: "res-1",
+			},
+		},
+		{
+			name: "reservation zone not in group zones",
+			groups: []CapacityReservationGroup{func() CapacityReservationGroup {
+				g := validGroup()
+				g.Reservations[1].Zone = "3"
+				return g
+			}()},
+			wantErr: true,
+			expectedErr: field.Error{
+				Type:     "FieldValueNotSupported",
+				Field:    "spec.capacityReservationGroups[0].reservations[1].zone",
+				BadValue: "3",
+				Detail:   `supported values: "1", "2"`,
+			},
+		},
+		{
+			name: "zonal group requires reservation zone",
+			groups: []CapacityReservationGroup{func() CapacityReservationGroup {
+				g := validGroup()
+				g.Reservations[0].Zone = ""
+				return g
+			}()},
+			wantErr: true,
+			expectedErr: field.Error{
+				Type:   "FieldValueRequired",
+				Field:  "spec.capacityReservationGroups[0].reservations[0].zone",
+				Detail: "zone is required when the capacity reservation group has zones",
+			},
+		},
+		{
+			name: "negative capacity",
+			groups: []CapacityReservationGroup{func() CapacityReservationGroup {
+				g := validGroup()
+				g.Reservations[0].Capacity = -1
+				return g
+			}()},
+			wantErr: true,
+			expectedErr: field.Error{
+				Type:     "FieldValueInvalid",
+				Field:    "spec.capacityReservationGroups[0].reservations[0].capacity",
+				BadValue: int64(-1),
+				Detail:   "capacity must be greater than or equal to 0",
+			},
+		},
+		{
+			name:   "capacity can be updated",
+			groups: []CapacityReservationGroup{validGroup()},
+			old: []CapacityReservationGroup{func() CapacityReservationGroup {
+				g := validGroup()
+				g.Reservations[0].Capacity = 5
+				return g
+			}()},
+			wantErr: false,
+		},
+		{
+			name:   "group zones are immutable",
+			groups: []CapacityReservationGroup{validGroup()},
+			old: []CapacityReservationGroup{func() CapacityReservationGroup {
+				g := validGroup()
+				g.Zones = []string{"1", "2", "3"}
+				return g
+			}()},
+			wantErr: true,
+			expectedErr: field.Error{
+				Type:     "FieldValueInvalid",
+				Field:    "spec.capacityReservationGroups[0].zones",
+				BadValue: []string{"1", "2"},
+				Detail:   "field is immutable",
+			},
+		},
+	}
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := validateCapacityReservationGroups(testCase.groups, testCase.old, field.NewPath("spec", "capacityReservationGroups"))
+			if testCase.wantErr {
+				g.Expect(err).To(ContainElement(MatchError(testCase.expectedErr.Error())))
+			} else {
+				g.Expect(err).To(HaveLen(0))
+			}
+		})
+	}
+}
+
 func createValidCluster() *AzureCluster {
 	return &AzureCluster{
 		ObjectMeta: metav1.ObjectMeta{
@@ -0,0 +1,67 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultImageResolver resolves the image of machines which don't specify one.
+type DefaultImageResolver interface {
+	// GetDefaultImage returns the default image for a Kubernetes version, OS type and image flavor, or nil if there is
+	// none, in which case the reference images are used. An empty Kubernetes version only matches the images used for
+	// all Kubernetes versions, and an empty flavor matches the images of machines bootstrapped with cloud-init.
+	GetDefaultImage(k8sVersion, osType, flavor string) (*Image, error)
+}
+
+var (
+	defaultImageResolverMu sync.RWMutex
+	defaultImageResolver   DefaultImageResolver
+)
+
+// SetDefaultImageResolver sets the resolver consulted for the image of machines which don't specify one, before
+// falling back to the reference images. A nil resolver restores the reference images.
+func SetDefaultImageResolver(resolver DefaultImageResolver) {
+	defaultImageResolverMu.Lock()
+	defer defaultImageResolverMu.Unlock()
+	defaultImageResolver = resolver
+}
+
+// GetDefaultImageResolver returns the resolver consulted for the image of machines which don't specify one, or nil if
+// the reference images are used.
+func GetDefaultImageResolver() DefaultImageResolver {
+	defaultImageResolverMu.RLock()
+	defer defaultImageResolverMu.RUnlock()
+	return defaultImageResolver
+}
+
+// GetDefaultImageForAllVersions returns the image the default image resolver has for all Kubernetes versions of an OS
+// type, or nil if there is none. The defaulting webhooks set it on the machines which don't specify an image, as the
+// Kubernetes version of a machine is only known once it is reconciled.
+func GetDefaultImageForAllVersions(osType string) (*Image, error) {
+	resolver := GetDefaultImageResolver()
+	if resolver == nil {
+		return nil, nil
+	}
+	image, err := resolver.GetDefaultImage("", osType, "")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to resolve default image")
+	}
+	return image, nil
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package v1beta1
 
 import (
+	"github.com/blang/semver"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 )
 
@@ -40,6 +41,12 @@ func ValidateImage(image *Image, fldPath *field.Path) field.ErrorList {
 	if image.ID != nil {
 		allErrs = append(allErrs, validateSpecifcImage(image, fldPath)...)
 	}
+	if image.CommunityGallery != nil {
+		allErrs = append(allErrs, validateCommunityGalleryImage(image, fldPath)...)
+	}
+	if image.DirectSharedGallery != nil {
+		allErrs = append(allErrs, validateDirectSharedGalleryImage(image, fldPath)...)
+	}
 
 	return allErrs
 }
@@ -68,8 +75,24 @@ func validateSingleDetailsOnly(image *Image, fldPath *field.Path) field.ErrorLis
 		}
 	}
 
+	if image.CommunityGallery != nil {
+		if imageDetailsFound {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("CommunityGallery"), "CommunityGallery cannot be used as an image ID, Marketplace or SharedGallery images has been specified"))
+		} else {
+			imageDetailsFound = true
+		}
+	}
+
+	if image.DirectSharedGallery != nil {
+		if imageDetailsFound {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("DirectSharedGallery"), "DirectSharedGallery cannot be used as an image ID, Marketplace, SharedGallery or CommunityGallery images has been specified"))
+		} else {
+			imageDetailsFound = true
+		}
+	}
+
 	if !imageDetailsFound {
-		allErrs = append(allErrs, field.Required(fldPath, "You must supply a ID, Marketplace or SharedGallery image details"))
+		allErrs = append(allErrs, field.Required(fldPath, "You must supply a ID, Marketplace, SharedGallery, CommunityGallery or DirectSharedGallery image details"))
 	}
 
 	return allErrs
@@ -92,6 +115,40 @@ func validateSharedGalleryImage(image *Image, fldPath *field.Path) field.ErrorLi
 	}
 	if image.SharedGallery.Version == "" {
 		allErrs = append(allErrs, field.Invalid(fldPath.Child("Version"), "", "Version cannot be empty when specifying an AzureSharedGalleryImage"))
+	} else {
+		allErrs = append(allErrs, validateImageVersion(image.SharedGallery.Version, fldPath.Child("Version"))...)
+	}
+
+	return allErrs
+}
+
+func validateCommunityGalleryImage(image *Image, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if image.CommunityGallery.Gallery == "" {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("Gallery"), "", "Gallery cannot be empty when specifying an AzureCommunityGalleryImage"))
+	}
+	if image.CommunityGallery.Name == "" {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("Name"), "", "Name cannot be empty when specifying an AzureCommunityGalleryImage"))
+	}
+	if image.CommunityGallery.Version == "" {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("Version"), "", "Version cannot be empty when specifying an AzureCommunityGalleryImage"))
+	}
+
+	return allErrs
+}
+
+func validateDirectSharedGalleryImage(image *Image, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if image.DirectSharedGallery.Gallery == "" {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("Gallery"), "", "Gallery cannot be empty when specifying an AzureDirectSharedGalleryImage"))
+	}
+	if image.DirectSharedGallery.Name == "" {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("Name"), "", "Name cannot be empty when specifying an AzureDirectSharedGalleryImage"))
+	}
+	if image.DirectSharedGallery.Version == "" {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("Version"), "", "Version cannot be empty when specifying an AzureDirectSharedGalleryImage"))
 	}
 
 	return allErrs
@@ -111,7 +168,23 @@ func validateMarketplaceImage(image *Image, fldPath *field.Path) field.ErrorList
 	}
 	if image.Marketplace.Version == "" {
 		allErrs = append(allErrs, field.Invalid(fldPath.Child("Version"), "", "Version cannot be empty when specifying an AzureMarketplaceImage"))
+	} else {
+		allErrs = append(allErrs, validateImageVersion(image.Marketplace.Version, fldPath.Child("Version"))...)
+	}
+	return allErrs
+}
+
+// validateImageVersion validates that an image version is either exact, 'latest' or a semantic version range.
+func validateImageVersion(version string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if version == LatestImageVersion || IsExactImageVersion(version) {
+		return allErrs
 	}
+	if _, err := semver.ParseRange(version); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath, version, "Version must be Major.Minor.Build, 'latest' or a semantic version range"))
+	}
+
 	return allErrs
 }
 
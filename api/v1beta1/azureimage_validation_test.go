@@ -44,14 +44,14 @@ func TestImageTooManyDetails(t *testing.T) {
 			Offer:     "OFFER",
 			Publisher: "PUBLISHER",
 			SKU:       "SKU",
-			Version:   "1.0.0.",
+			Version:   "1.0.0",
 		},
 		SharedGallery: &AzureSharedGalleryImage{
 			Gallery:        "GALLERY",
 			Name:           "GALLERY1",
 			ResourceGroup:  "RG1",
 			SubscriptionID: "SUB12",
-			Version:        "1.0.0.",
+			Version:        "1.0.0",
 		},
 	}
 
@@ -123,6 +123,22 @@ func TestMarketPlaceImageValid(t *testing.T) {
 			expectedErrors: 1,
 			image:          createTestMarketPlaceImage("PUB1234", "OFFER1234", "SKU1234", ""),
 		},
+		"AzureMarketplaceImage - latest version": {
+			expectedErrors: 0,
+			image:          createTestMarketPlaceImage("PUB1234", "OFFER1234", "SKU1234", "latest"),
+		},
+		"AzureMarketplaceImage - version range": {
+			expectedErrors: 0,
+			image:          createTestMarketPlaceImage("PUB1234", "OFFER1234", "SKU1234", ">=1.0.0 <2.0.0"),
+		},
+		"AzureMarketplaceImage - wildcard version": {
+			expectedErrors: 0,
+			image:          createTestMarketPlaceImage("PUB1234", "OFFER1234", "SKU1234", "1.2.x"),
+		},
+		"AzureMarketplaceImage - invalid version": {
+			expectedErrors: 1,
+			image:          createTestMarketPlaceImage("PUB1234", "OFFER1234", "SKU1234", "newest"),
+		},
 	}
 
 	for _, tc := range testCases {
@@ -152,6 +168,77 @@ func TestImageByIDValid(t *testing.T) {
 	}
 }
 
+func TestCommunityGalleryImageValid(t *testing.T) {
+	g := NewWithT(t)
+
+	testCases := map[string]struct {
+		image          *Image
+		expectedErrors int
+	}{
+		"AzureCommunityGalleryImage - fully specified": {
+			expectedErrors: 0,
+			image:          createTestCommunityGalleryImage("GALLERY-1234", "IMAGENAME", "1.0.0"),
+		},
+		"AzureCommunityGalleryImage - latest version": {
+			expectedErrors: 0,
+			image:          createTestCommunityGalleryImage("GALLERY-1234", "IMAGENAME", "latest"),
+		},
+		"AzureCommunityGalleryImage - missing gallery": {
+			expectedErrors: 1,
+			image:          createTestCommunityGalleryImage("", "IMAGENAME", "1.0.0"),
+		},
+		"AzureCommunityGalleryImage - missing image name": {
+			expectedErrors: 1,
+			image:          createTestCommunityGalleryImage("GALLERY-1234", "", "1.0.0"),
+		},
+		"AzureCommunityGalleryImage - missing version": {
+			expectedErrors: 1,
+			image:          createTestCommunityGalleryImage("GALLERY-1234", "IMAGENAME", ""),
+		},
+	}
+
+	for _, tc := range testCases {
+		g.Expect(ValidateImage(tc.image, field.NewPath("image"))).To(HaveLen(tc.expectedErrors))
+	}
+}
+
+func TestDirectSharedGalleryImageValid(t *testing.T) {
+	g := NewWithT(t)
+
+	testCases := map[string]struct {
+		image          *Image
+		expectedErrors int
+	}{
+		"AzureDirectSharedGalleryImage - fully specified": {
+			expectedErrors: 0,
+			image:          createTestDirectSharedGalleryImage("GALLERY-1234", "IMAGENAME", "1.0.0"),
+		},
+		"AzureDirectSharedGalleryImage - missing gallery": {
+			expectedErrors: 1,
+			image:          createTestDirectSharedGalleryImage("", "IMAGENAME", "1.0.0"),
+		},
+		"AzureDirectSharedGalleryImage - missing image name": {
+			expectedErrors: 1,
+			image:          createTestDirectSharedGalleryImage("GALLERY-1234", "", "1.0.0"),
+		},
+		"AzureDirectSharedGalleryImage - missing version": {
+			expectedErrors: 1,
+			image:          createTestDirectSharedGalleryImage("GALLERY-1234", "IMAGENAME", ""),
+		},
+		"AzureDirectSharedGalleryImage - with community gallery": {
+			expectedErrors: 1,
+			image: &Image{
+				CommunityGallery:    createTestCommunityGalleryImage("GALLERY-1234", "IMAGENAME", "1.0.0").CommunityGallery,
+				DirectSharedGallery: createTestDirectSharedGalleryImage("GALLERY-5678", "IMAGENAME", "1.0.0").DirectSharedGallery,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		g.Expect(ValidateImage(tc.image, field.NewPath("image"))).To(HaveLen(tc.expectedErrors))
+	}
+}
+
 func createTestSharedImage(subscriptionID, resourceGroup, name, gallery, version string) *Image {
 	return &Image{
 		SharedGallery: &AzureSharedGalleryImage{
@@ -180,3 +267,23 @@ func createTestImageByID(imageID string) *Image {
 		ID: &imageID,
 	}
 }
+
+func createTestCommunityGalleryImage(gallery, name, version string) *Image {
+	return &Image{
+		CommunityGallery: &AzureCommunityGalleryImage{
+			Gallery: gallery,
+			Name:    name,
+			Version: version,
+		},
+	}
+}
+
+func createTestDirectSharedGalleryImage(gallery, name, version string) *Image {
+	return &Image{
+		DirectSharedGallery: &AzureDirectSharedGalleryImage{
+			Gallery: gallery,
+			Name:    name,
+			Version: version,
+		},
+	}
+}
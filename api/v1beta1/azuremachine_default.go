@@ -81,6 +81,20 @@ func (s *AzureMachineSpec) SetIdentityDefaults() {
 	}
 }
 
+// SetDefaultImage sets the image of an AzureMachine which doesn't specify one to the default image for all Kubernetes
+// versions of its OS type, if the default image resolver has one.
+func (s *AzureMachineSpec) SetDefaultImage() error {
+	if s.Image != nil {
+		return nil
+	}
+	image, err := GetDefaultImageForAllVersions(s.OSDisk.OSType)
+	if err != nil {
+		return err
+	}
+	s.Image = image
+	return nil
+}
+
 // SetDefaults sets to the defaults for the AzureMachineSpec.
 func (s *AzureMachineSpec) SetDefaults() {
 	if err := s.SetDefaultSSHPublicKey(); err != nil {
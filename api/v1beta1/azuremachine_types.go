@@ -38,6 +38,12 @@ type AzureMachineSpec struct {
 
 	VMSize string `json:"vmSize"`
 
+	// EnableInPlaceResize allows VMSize to be changed after the machine is created. The virtual machine is then
+	// resized in place, and deallocated during the resize if the new size is not available on the hardware
+	// cluster currently hosting it.
+	// +optional
+	EnableInPlaceResize bool `json:"enableInPlaceResize,omitempty"`
+
 	// FailureDomain is the failure domain unique identifier this Machine should be attached to,
 	// as defined in Cluster API. This relates to an Azure Availability Zone
 	// +optional
@@ -74,10 +80,22 @@ type AzureMachineSpec struct {
 	// OSDisk specifies the parameters for the operating system disk of the machine
 	OSDisk OSDisk `json:"osDisk"`
 
-	// DataDisk specifies the parameters that are used to add one or more data disks to the machine
+	// DataDisk specifies the parameters that are used to add one or more data disks to the machine.
+	// Data disks can be added and grown after the machine is created.
 	// +optional
 	DataDisks []DataDisk `json:"dataDisks,omitempty"`
 
+	// DataDiskRemovalPolicy allows data disks to be removed from DataDisks after the machine is created, and specifies
+	// whether the managed disks detached from the virtual machine are retained or deleted.
+	// If omitted, data disks cannot be removed.
+	// +optional
+	DataDiskRemovalPolicy DataDiskRemovalPolicy `json:"dataDiskRemovalPolicy,omitempty"`
+
+	// EnableUltraSSD enables the storage type UltraSSD_LRS for the data disks of the machine.
+	// If omitted, it is enabled only if one of the data disks is an UltraSSD_LRS disk.
+	// +optional
+	EnableUltraSSD *bool `json:"enableUltraSSD,omitempty"`
+
 	SSHPublicKey string `json:"sshPublicKey"`
 
 	// AdditionalTags is an optional set of tags to add to an instance, in addition to the ones added by default by the
@@ -111,9 +129,30 @@ type AzureMachineSpec struct {
 	// +optional
 	SecurityProfile *SecurityProfile `json:"securityProfile,omitempty"`
 
+	// Diagnostics specifies the diagnostic settings for the virtual machine.
+	// If omitted, boot diagnostics are enabled with a storage account managed by Azure.
+	// +optional
+	Diagnostics *Diagnostics `json:"diagnostics,omitempty"`
+
+	// BootstrapDataDelivery specifies whether the bootstrap data is passed to the virtual machine as custom data, as
+	// user data, or from blob storage. Ignition bootstrap data can be passed as user data, while cloud-init bootstrap
+	// data must be passed as custom data or from blob storage. Defaults to CustomData.
+	// +optional
+	BootstrapDataDelivery BootstrapDataDelivery `json:"bootstrapDataDelivery,omitempty"`
+
+	// BootstrapDataStorage specifies the blob storage the bootstrap data is uploaded to when BootstrapDataDelivery
+	// is Blob. The blob is deleted once the machine has joined the cluster.
+	// +optional
+	BootstrapDataStorage *BootstrapDataStorage `json:"bootstrapDataStorage,omitempty"`
+
 	// SubnetName selects the Subnet where the VM will be placed
 	// +optional
 	SubnetName string `json:"subnetName,omitempty"`
+
+	// CapacityReservationGroupID is the Azure resource ID of an on-demand capacity reservation group to allocate
+	// the Virtual Machine from. The group must contain a capacity reservation for the VM size and zone of the machine.
+	// +optional
+	CapacityReservationGroupID *string `json:"capacityReservationGroupID,omitempty"`
 }
 
 // SpotVMOptions defines the options relevant to running the Machine on Spot VMs.
@@ -137,6 +176,16 @@ type AzureMachineStatus struct {
 	// +optional
 	VMState *ProvisioningState `json:"vmState,omitempty"`
 
+	// Image is the image the virtual machine is created from. When the version of the spec image is 'latest' or a
+	// version range, it is resolved to the exact version of the image when the virtual machine is created.
+	// +optional
+	Image *Image `json:"image,omitempty"`
+
+	// RemovedDataDisks are the names of the data disks that were detached from the virtual machine after being removed
+	// from the spec with the Delete data disk removal policy. They are deleted once they are detached.
+	// +optional
+	RemovedDataDisks []string `json:"removedDataDisks,omitempty"`
+
 	// ErrorReason will be set in the event that there is a terminal problem
 	// reconciling the Machine and will contain a succinct value suitable
 	// for machine interpretation.
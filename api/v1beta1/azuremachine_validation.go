@@ -19,8 +19,13 @@ package v1beta1
 import (
 	"encoding/base64"
 	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
 
-	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
+	azureautorest "github.com/Azure/go-autorest/autorest/azure"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/ssh"
 	"k8s.io/apimachinery/pkg/util/validation/field"
@@ -54,6 +59,26 @@ func ValidateAzureMachineSpec(spec AzureMachineSpec) field.ErrorList {
 		allErrs = append(allErrs, errs...)
 	}
 
+	if errs := ValidateEnableUltraSSD(spec.EnableUltraSSD, spec.DataDisks, field.NewPath("enableUltraSSD")); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if errs := ValidateCapacityReservationGroupID(spec.CapacityReservationGroupID, field.NewPath("capacityReservationGroupID")); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if errs := ValidateDiagnostics(spec.Diagnostics, field.NewPath("diagnostics")); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if errs := ValidateBootstrapDataDelivery(spec.BootstrapDataDelivery, spec.OSDisk.OSType, field.NewPath("bootstrapDataDelivery")); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if errs := ValidateBootstrapDataStorage(spec.BootstrapDataStorage, spec.BootstrapDataDelivery, spec.Identity, field.NewPath("bootstrapDataStorage")); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
 	return allErrs
 }
 
@@ -103,14 +128,172 @@ func ValidateUserAssignedIdentity(identityType VMIdentity, userAssignedIdentetie
 	return allErrs
 }
 
+// ValidateCapacityReservationGroupID validates that the capacity reservation group ID, if set, is the resource ID
+// of a capacity reservation group.
+func ValidateCapacityReservationGroupID(id *string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if id == nil {
+		return allErrs
+	}
+
+	resource, err := azureautorest.ParseResourceID(*id)
+	if err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath, *id, "the capacity reservation group ID must be a valid Azure resource ID"))
+		return allErrs
+	}
+
+	if !strings.EqualFold(resource.Provider, "Microsoft.Compute") || !strings.EqualFold(resource.ResourceType, "capacityReservationGroups") {
+		allErrs = append(allErrs, field.Invalid(fldPath, *id, "the resource ID must reference a Microsoft.Compute/capacityReservationGroups resource"))
+	}
+
+	return allErrs
+}
+
+// ValidateCapacityReservation validates that, when the capacity reservation group ID references one of the capacity
+// reservation groups of the AzureCluster, the group reserves capacity for the VM size, and in the zone if it is known.
+// Capacity reservation groups which aren't managed by the AzureCluster are only validated at reconcile time.
+func ValidateCapacityReservation(id *string, cluster *AzureCluster, vmSize string, zone *string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if id == nil || cluster == nil {
+		return allErrs
+	}
+
+	resource, err := azureautorest.ParseResourceID(*id)
+	if err != nil {
+		return allErrs
+	}
+
+	if !strings.EqualFold(resource.ResourceGroup, cluster.Spec.ResourceGroup) ||
+		(cluster.Spec.SubscriptionID != "" && !strings.EqualFold(resource.SubscriptionID, cluster.Spec.SubscriptionID)) {
+		return allErrs
+	}
+
+	for _, group := range cluster.Spec.CapacityReservationGroups {
+		if !strings.EqualFold(group.Name, resource.ResourceName) {
+			continue
+		}
+		for _, reservation := range group.Reservations {
+			if strings.EqualFold(reservation.VMSize, vmSize) && (zone == nil || reservation.Zone == *zone) {
+				return allErrs
+			}
+		}
+		if zone == nil {
+			allErrs = append(allErrs, field.Invalid(fldPath, *id, fmt.Sprintf("capacity reservation group %s has no capacity reservation for VM size %s", group.Name, vmSize)))
+		} else if *zone == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath, *id, fmt.Sprintf("capacity reservation group %s has no regional capacity reservation for VM size %s", group.Name, vmSize)))
+		} else {
+			allErrs = append(allErrs, field.Invalid(fldPath, *id, fmt.Sprintf("capacity reservation group %s has no capacity reservation for VM size %s in zone %s", group.Name, vmSize, *zone)))
+		}
+		return allErrs
+	}
+
+	return allErrs
+}
+
+// ValidateDiagnostics validates the diagnostic settings of a virtual machine.
+func ValidateDiagnostics(diagnostics *Diagnostics, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if diagnostics == nil || diagnostics.Boot == nil {
+		return allErrs
+	}
+
+	bootPath := fldPath.Child("boot")
+	boot := diagnostics.Boot
+	if boot.StorageAccountType != UserManagedDiagnosticsStorage {
+		if boot.UserManaged != nil {
+			allErrs = append(allErrs, field.Forbidden(bootPath.Child("userManaged"), fmt.Sprintf("userManaged must not be set when storageAccountType is %s", boot.StorageAccountType)))
+		}
+		return allErrs
+	}
+
+	if boot.UserManaged == nil {
+		allErrs = append(allErrs, field.Required(bootPath.Child("userManaged"), "userManaged must be set when storageAccountType is UserManaged"))
+		return allErrs
+	}
+
+	uriPath := bootPath.Child("userManaged", "storageAccountURI")
+	uri, err := url.Parse(boot.UserManaged.StorageAccountURI)
+	if err != nil || uri.Scheme != "https" || uri.Host == "" {
+		allErrs = append(allErrs, field.Invalid(uriPath, boot.UserManaged.StorageAccountURI, "the storage account URI must be a valid https URL"))
+	}
+
+	return allErrs
+}
+
+// ValidateBootstrapDataDelivery validates how the bootstrap data is passed to a virtual machine.
+func ValidateBootstrapDataDelivery(delivery BootstrapDataDelivery, osType string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	switch delivery {
+	case "", BootstrapDataDeliveryCustomData:
+	case BootstrapDataDeliveryUserData, BootstrapDataDeliveryBlob:
+		// cloudbase-init only reads the bootstrap data from custom data, and doesn't run the blob loader script.
+		if osType == string(compute.OperatingSystemTypesWindows) {
+			allErrs = append(allErrs, field.Forbidden(fldPath, "bootstrap data can only be passed as custom data to Windows virtual machines"))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath, delivery, []string{string(BootstrapDataDeliveryCustomData), string(BootstrapDataDeliveryUserData), string(BootstrapDataDeliveryBlob)}))
+	}
+
+	return allErrs
+}
+
+// ValidateBootstrapDataStorage validates the blob storage the bootstrap data of a virtual machine is uploaded to.
+func ValidateBootstrapDataStorage(storage *BootstrapDataStorage, delivery BootstrapDataDelivery, identity VMIdentity, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if storage == nil {
+		return allErrs
+	}
+
+	if delivery != BootstrapDataDeliveryBlob {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "bootstrapDataStorage can only be set when bootstrapDataDelivery is Blob"))
+		return allErrs
+	}
+
+	if storage.StorageAccountID != nil {
+		idPath := fldPath.Child("storageAccountID")
+		resource, err := azureautorest.ParseResourceID(*storage.StorageAccountID)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(idPath, *storage.StorageAccountID, "the storage account ID must be a valid Azure resource ID"))
+		} else if !strings.EqualFold(resource.Provider, "Microsoft.Storage") || !strings.EqualFold(resource.ResourceType, "storageAccounts") {
+			allErrs = append(allErrs, field.Invalid(idPath, *storage.StorageAccountID, "the resource ID must reference a Microsoft.Storage/storageAccounts resource"))
+		}
+	}
+
+	if storage.ContainerName != "" {
+		if success, _ := regexp.MatchString(blobContainerNameRegex, storage.ContainerName); !success || strings.Contains(storage.ContainerName, "--") {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("containerName"), storage.ContainerName,
+				"the container name must be 3 to 63 lowercase letters, numbers and single hyphens, and start and end with a letter or number"))
+		}
+	}
+
+	switch storage.Access {
+	case "", BootstrapDataBlobAccessSAS:
+	case BootstrapDataBlobAccessManagedIdentity:
+		// The loader script authenticates with the first user-assigned identity, which can be granted access to the
+		// container before the virtual machine exists, unlike a system-assigned identity.
+		if identity != VMIdentityUserAssigned {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("access"), "ManagedIdentity access requires the UserAssigned identity type"))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("access"), storage.Access, []string{string(BootstrapDataBlobAccessSAS), string(BootstrapDataBlobAccessManagedIdentity)}))
+	}
+
+	return allErrs
+}
+
 // ValidateDataDisks validates a list of data disks.
 func ValidateDataDisks(dataDisks []DataDisk, fieldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 	lunSet := make(map[int32]struct{})
 	nameSet := make(map[string]struct{})
 	for _, disk := range dataDisks {
-		// validate that the disk size is between 4 and 32767.
-		if disk.DiskSizeGB < 4 || disk.DiskSizeGB > 32767 {
+		// validate that the disk size is between 4 and 32767, unless an existing disk is attached.
+		if disk.SourceDiskID == nil && (disk.DiskSizeGB < 4 || disk.DiskSizeGB > 32767) {
 			allErrs = append(allErrs, field.Invalid(fieldPath.Child("DiskSizeGB"), "", "the disk size should be a value between 4 and 32767"))
 		}
 
@@ -144,7 +327,105 @@ func ValidateDataDisks(dataDisks []DataDisk, fieldPath *field.Path) field.ErrorL
 
 		// validate cachingType
 		allErrs = append(allErrs, validateCachingType(disk.CachingType, fieldPath)...)
+
+		allErrs = append(allErrs, validateSourceDisk(disk, fieldPath)...)
+		allErrs = append(allErrs, validateDiskPerformance(disk, fieldPath)...)
+		allErrs = append(allErrs, validateMaxShares(disk, fieldPath)...)
+	}
+	return allErrs
+}
+
+// validateSourceDisk validates that the source disk ID, if set, is the resource ID of a managed disk, and that
+// none of the properties of the existing disk are set on the data disk.
+func validateSourceDisk(disk DataDisk, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if disk.SourceDiskID == nil {
+		return allErrs
+	}
+
+	resource, err := azureautorest.ParseResourceID(*disk.SourceDiskID)
+	if err != nil || !strings.EqualFold(resource.Provider, "Microsoft.Compute") || !strings.EqualFold(resource.ResourceType, "disks") {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("sourceDiskID"), *disk.SourceDiskID, "the source disk ID must be the resource ID of a Microsoft.Compute/disks resource"))
+	}
+
+	if disk.ManagedDisk != nil {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("managedDisk"), "the managed disk options cannot be set when attaching an existing disk"))
+	}
+	if disk.DiskIOPSReadWrite != nil || disk.DiskMBpsReadWrite != nil || disk.MaxShares != nil {
+		allErrs = append(allErrs, field.Forbidden(fieldPath, "diskIOPSReadWrite, diskMBpsReadWrite and maxShares cannot be set when attaching an existing disk"))
+	}
+
+	return allErrs
+}
+
+// validateDiskPerformance validates that the IOPS and bandwidth of a data disk are only set for storage account
+// types supporting them.
+func validateDiskPerformance(disk DataDisk, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if disk.DiskIOPSReadWrite == nil && disk.DiskMBpsReadWrite == nil {
+		return allErrs
+	}
+
+	if disk.DiskIOPSReadWrite != nil && *disk.DiskIOPSReadWrite <= 0 {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("diskIOPSReadWrite"), *disk.DiskIOPSReadWrite, "the disk IOPS must be greater than 0"))
+	}
+	if disk.DiskMBpsReadWrite != nil && *disk.DiskMBpsReadWrite <= 0 {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("diskMBpsReadWrite"), *disk.DiskMBpsReadWrite, "the disk bandwidth must be greater than 0"))
+	}
+
+	if disk.ManagedDisk == nil || (disk.ManagedDisk.StorageAccountType != string(compute.StorageAccountTypesUltraSSDLRS) && disk.ManagedDisk.StorageAccountType != StorageAccountTypePremiumV2LRS) {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("managedDisk", "storageAccountType"), disk.ManagedDisk, "diskIOPSReadWrite and diskMBpsReadWrite can only be set for UltraSSD_LRS and PremiumV2_LRS disks"))
+	}
+
+	return allErrs
+}
+
+// validateMaxShares validates that a shared data disk uses a storage account type supporting shared disks and
+// doesn't use host caching.
+func validateMaxShares(disk DataDisk, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if disk.MaxShares == nil {
+		return allErrs
+	}
+
+	if *disk.MaxShares < 1 {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("maxShares"), *disk.MaxShares, "maxShares must be at least 1"))
+		return allErrs
+	}
+
+	if *disk.MaxShares == 1 {
+		return allErrs
+	}
+
+	if disk.CachingType != string(compute.CachingTypesNone) {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("cachingType"), disk.CachingType, "the caching type of a shared disk must be None"))
+	}
+
+	if disk.ManagedDisk == nil || disk.ManagedDisk.StorageAccountType == string(compute.StorageAccountTypesStandardLRS) {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("managedDisk", "storageAccountType"), disk.ManagedDisk, "shared disks require a premium, standard SSD or ultra storage account type"))
+	}
+
+	return allErrs
+}
+
+// ValidateEnableUltraSSD validates that UltraSSD_LRS data disks are not used when ultra SSD support is disabled.
+func ValidateEnableUltraSSD(enableUltraSSD *bool, dataDisks []DataDisk, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if enableUltraSSD == nil || *enableUltraSSD {
+		return allErrs
 	}
+
+	for _, disk := range dataDisks {
+		if disk.ManagedDisk != nil && disk.ManagedDisk.StorageAccountType == string(compute.StorageAccountTypesUltraSSDLRS) {
+			allErrs = append(allErrs, field.Invalid(fieldPath, *enableUltraSSD, "enableUltraSSD cannot be false when a data disk uses UltraSSD_LRS"))
+			break
+		}
+	}
+
 	return allErrs
 }
 
@@ -192,16 +473,27 @@ func validateManagedDisk(m *ManagedDiskParameters, fieldPath *field.Path, isOSDi
 	return allErrs
 }
 
-// ValidateDataDisksUpdate validates updates to Data disks.
-func ValidateDataDisksUpdate(oldDataDisks, newDataDisks []DataDisk, fieldPath *field.Path) field.ErrorList {
+// ValidateDataDisksUpdate validates updates to Data disks. Data disks can be added and grown after machine creation,
+// and can only be removed when a data disk removal policy is set.
+func ValidateDataDisksUpdate(oldDataDisks, newDataDisks []DataDisk, removalPolicy DataDiskRemovalPolicy, fieldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 
-	diskErrMsg := "adding/removing data disks after machine creation is not allowed"
+	diskErrMsg := "removing data disks after machine creation is not allowed unless dataDiskRemovalPolicy is set"
 	fieldErrMsg := "modifying data disk's fields after machine creation is not allowed"
+	sizeErrMsg := "data disks can only be grown after machine creation"
 
-	if len(oldDataDisks) != len(newDataDisks) {
-		allErrs = append(allErrs, field.Invalid(fieldPath, newDataDisks, diskErrMsg))
-		return allErrs
+	newDisks := make(map[string]struct{})
+	for _, disk := range newDataDisks {
+		newDisks[disk.NameSuffix] = struct{}{}
+	}
+
+	if removalPolicy == "" {
+		for _, disk := range oldDataDisks {
+			if _, ok := newDisks[disk.NameSuffix]; !ok {
+				allErrs = append(allErrs, field.Invalid(fieldPath, newDataDisks, diskErrMsg))
+				break
+			}
+		}
 	}
 
 	oldDisks := make(map[string]DataDisk)
@@ -211,24 +503,34 @@ func ValidateDataDisksUpdate(oldDataDisks, newDataDisks []DataDisk, fieldPath *f
 	}
 
 	for i, newDisk := range newDataDisks {
-		if oldDisk, ok := oldDisks[newDisk.NameSuffix]; ok {
-			if newDisk.DiskSizeGB != oldDisk.DiskSizeGB {
-				allErrs = append(allErrs, field.Invalid(fieldPath.Index(i).Child("diskSizeGB"), newDataDisks, fieldErrMsg))
-			}
+		oldDisk, ok := oldDisks[newDisk.NameSuffix]
+		if !ok {
+			// The data disk was added after machine creation.
+			continue
+		}
 
-			allErrs = append(allErrs, validateManagedDisksUpdate(oldDisk.ManagedDisk, newDisk.ManagedDisk, fieldPath.Index(i).Child("managedDisk"))...)
+		if newDisk.DiskSizeGB < oldDisk.DiskSizeGB {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Index(i).Child("diskSizeGB"), newDataDisks, sizeErrMsg))
+		}
 
-			if (newDisk.Lun != nil && oldDisk.Lun != nil) && (*newDisk.Lun != *oldDisk.Lun) {
-				allErrs = append(allErrs, field.Invalid(fieldPath.Index(i).Child("lun"), newDataDisks, fieldErrMsg))
-			} else if (newDisk.Lun != nil && oldDisk.Lun == nil) || (newDisk.Lun == nil && oldDisk.Lun != nil) {
-				allErrs = append(allErrs, field.Invalid(fieldPath.Index(i).Child("lun"), newDataDisks, fieldErrMsg))
-			}
+		allErrs = append(allErrs, validateManagedDisksUpdate(oldDisk.ManagedDisk, newDisk.ManagedDisk, fieldPath.Index(i).Child("managedDisk"))...)
 
-			if newDisk.CachingType != oldDisk.CachingType {
-				allErrs = append(allErrs, field.Invalid(fieldPath.Index(i).Child("cachingType"), newDataDisks, fieldErrMsg))
-			}
-		} else {
-			allErrs = append(allErrs, field.Invalid(fieldPath.Index(i).Child("nameSuffix"), newDataDisks, diskErrMsg))
+		if (newDisk.Lun != nil && oldDisk.Lun != nil) && (*newDisk.Lun != *oldDisk.Lun) {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Index(i).Child("lun"), newDataDisks, fieldErrMsg))
+		} else if (newDisk.Lun != nil && oldDisk.Lun == nil) || (newDisk.Lun == nil && oldDisk.Lun != nil) {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Index(i).Child("lun"), newDataDisks, fieldErrMsg))
+		}
+
+		if newDisk.CachingType != oldDisk.CachingType {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Index(i).Child("cachingType"), newDataDisks, fieldErrMsg))
+		}
+
+		if !reflect.DeepEqual(newDisk.SourceDiskID, oldDisk.SourceDiskID) {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Index(i).Child("sourceDiskID"), newDataDisks, fieldErrMsg))
+		}
+
+		if !reflect.DeepEqual(newDisk.MaxShares, oldDisk.MaxShares) {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Index(i).Child("maxShares"), newDataDisks, fieldErrMsg))
 		}
 	}
 
@@ -264,17 +566,25 @@ func validateStorageAccountType(storageAccountType string, fieldPath *field.Path
 		allErrs = append(allErrs, field.Invalid(fieldPath.Child("managedDisks").Child("storageAccountType"), storageAccountType, "UltraSSD_LRS can only be used with data disks, it cannot be used with OS Disks"))
 	}
 
+	if isOSDisk && storageAccountType == StorageAccountTypePremiumV2LRS {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("managedDisks").Child("storageAccountType"), storageAccountType, "PremiumV2_LRS can only be used with data disks, it cannot be used with OS Disks"))
+	}
+
 	if storageAccountType == "" {
 		allErrs = append(allErrs, field.Required(fieldPath, "the Storage Account Type for Managed Disk cannot be empty"))
 		return allErrs
 	}
 
+	if storageAccountType == StorageAccountTypePremiumV2LRS {
+		return allErrs
+	}
+
 	for _, possibleStorageAccountType := range compute.PossibleDiskStorageAccountTypesValues() {
 		if string(possibleStorageAccountType) == storageAccountType {
 			return allErrs
 		}
 	}
-	allErrs = append(allErrs, field.Invalid(fieldPath, "", fmt.Sprintf("allowed values are %v", compute.PossibleDiskStorageAccountTypesValues())))
+	allErrs = append(allErrs, field.Invalid(fieldPath, "", fmt.Sprintf("allowed values are %v", append(compute.PossibleDiskStorageAccountTypesValues(), StorageAccountTypePremiumV2LRS))))
 	return allErrs
 }
 
@@ -23,7 +23,7 @@ import (
 	"fmt"
 	"testing"
 
-	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/google/uuid"
 	. "github.com/onsi/gomega"
@@ -68,6 +68,390 @@ func TestAzureMachine_ValidateSSHKey(t *testing.T) {
 	}
 }
 
+func TestAzureMachine_ValidateDiagnostics(t *testing.T) {
+	g := NewWithT(t)
+
+	tests := []struct {
+		name        string
+		diagnostics *Diagnostics
+		wantErr     bool
+	}{
+		{
+			name:        "nil diagnostics",
+			diagnostics: nil,
+			wantErr:     false,
+		},
+		{
+			name:        "managed boot diagnostics",
+			diagnostics: &Diagnostics{Boot: &BootDiagnostics{StorageAccountType: ManagedDiagnosticsStorage}},
+			wantErr:     false,
+		},
+		{
+			name:        "disabled boot diagnostics",
+			diagnostics: &Diagnostics{Boot: &BootDiagnostics{StorageAccountType: DisabledDiagnosticsStorage}},
+			wantErr:     false,
+		},
+		{
+			name: "user-managed boot diagnostics",
+			diagnostics: &Diagnostics{Boot: &BootDiagnostics{
+				StorageAccountType: UserManagedDiagnosticsStorage,
+				UserManaged:        &UserManagedBootDiagnostics{StorageAccountURI: "https://mystorageaccount.blob.core.windows.net/"},
+			}},
+			wantErr: false,
+		},
+		{
+			name:        "user-managed boot diagnostics without a storage account",
+			diagnostics: &Diagnostics{Boot: &BootDiagnostics{StorageAccountType: UserManagedDiagnosticsStorage}},
+			wantErr:     true,
+		},
+		{
+			name: "user-managed boot diagnostics with an invalid storage account URI",
+			diagnostics: &Diagnostics{Boot: &BootDiagnostics{
+				StorageAccountType: UserManagedDiagnosticsStorage,
+				UserManaged:        &UserManagedBootDiagnostics{StorageAccountURI: "mystorageaccount"},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "managed boot diagnostics with a storage account",
+			diagnostics: &Diagnostics{Boot: &BootDiagnostics{
+				StorageAccountType: ManagedDiagnosticsStorage,
+				UserManaged:        &UserManagedBootDiagnostics{StorageAccountURI: "https://mystorageaccount.blob.core.windows.net/"},
+			}},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateDiagnostics(tc.diagnostics, field.NewPath("diagnostics"))
+			if tc.wantErr {
+				g.Expect(err).ToNot(HaveLen(0))
+			} else {
+				g.Expect(err).To(HaveLen(0))
+			}
+		})
+	}
+}
+
+func TestAzureMachine_ValidateBootstrapDataDelivery(t *testing.T) {
+	g := NewWithT(t)
+
+	tests := []struct {
+		name     string
+		delivery BootstrapDataDelivery
+		osType   string
+		wantErr  bool
+	}{
+		{
+			name:     "default delivery",
+			delivery: "",
+			osType:   "Linux",
+			wantErr:  false,
+		},
+		{
+			name:     "custom data for Windows",
+			delivery: BootstrapDataDeliveryCustomData,
+			osType:   "Windows",
+			wantErr:  false,
+		},
+		{
+			name:     "user data for Linux",
+			delivery: BootstrapDataDeliveryUserData,
+			osType:   "Linux",
+			wantErr:  false,
+		},
+		{
+			name:     "user data for Windows",
+			delivery: BootstrapDataDeliveryUserData,
+			osType:   "Windows",
+			wantErr:  true,
+		},
+		{
+			name:     "blob for Linux",
+			delivery: BootstrapDataDeliveryBlob,
+			osType:   "Linux",
+			wantErr:  false,
+		},
+		{
+			name:     "blob for Windows",
+			delivery: BootstrapDataDeliveryBlob,
+			osType:   "Windows",
+			wantErr:  true,
+		},
+		{
+			name:     "unknown delivery",
+			delivery: "Ignition",
+			osType:   "Linux",
+			wantErr:  true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateBootstrapDataDelivery(tc.delivery, tc.osType, field.NewPath("bootstrapDataDelivery"))
+			if tc.wantErr {
+				g.Expect(err).ToNot(HaveLen(0))
+			} else {
+				g.Expect(err).To(HaveLen(0))
+			}
+		})
+	}
+}
+
+func TestAzureMachine_ValidateBootstrapDataStorage(t *testing.T) {
+	g := NewWithT(t)
+
+	tests := []struct {
+		name     string
+		storage  *BootstrapDataStorage
+		delivery BootstrapDataDelivery
+		identity VMIdentity
+		wantErr  bool
+	}{
+		{
+			name:     "no storage",
+			storage:  nil,
+			delivery: BootstrapDataDeliveryBlob,
+			wantErr:  false,
+		},
+		{
+			name:     "default storage",
+			storage:  &BootstrapDataStorage{},
+			delivery: BootstrapDataDeliveryBlob,
+			wantErr:  false,
+		},
+		{
+			name:     "storage without blob delivery",
+			storage:  &BootstrapDataStorage{},
+			delivery: BootstrapDataDeliveryCustomData,
+			wantErr:  true,
+		},
+		{
+			name: "existing storage account",
+			storage: &BootstrapDataStorage{
+				StorageAccountID: to.StringPtr("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Storage/storageAccounts/mystorage"),
+				ContainerName:    "my-bootstrap-data",
+			},
+			delivery: BootstrapDataDeliveryBlob,
+			wantErr:  false,
+		},
+		{
+			name: "storage account ID of another resource type",
+			storage: &BootstrapDataStorage{
+				StorageAccountID: to.StringPtr("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/disks/my-disk"),
+			},
+			delivery: BootstrapDataDeliveryBlob,
+			wantErr:  true,
+		},
+		{
+			name:     "invalid storage account ID",
+			storage:  &BootstrapDataStorage{StorageAccountID: to.StringPtr("mystorage")},
+			delivery: BootstrapDataDeliveryBlob,
+			wantErr:  true,
+		},
+		{
+			name:     "invalid container name",
+			storage:  &BootstrapDataStorage{ContainerName: "Bootstrap_Data"},
+			delivery: BootstrapDataDeliveryBlob,
+			wantErr:  true,
+		},
+		{
+			name:     "container name with consecutive hyphens",
+			storage:  &BootstrapDataStorage{ContainerName: "bootstrap--data"},
+			delivery: BootstrapDataDeliveryBlob,
+			wantErr:  true,
+		},
+		{
+			name:     "managed identity access with a user-assigned identity",
+			storage:  &BootstrapDataStorage{Access: BootstrapDataBlobAccessManagedIdentity},
+			delivery: BootstrapDataDeliveryBlob,
+			identity: VMIdentityUserAssigned,
+			wantErr:  false,
+		},
+		{
+			name:     "managed identity access with a system-assigned identity",
+			storage:  &BootstrapDataStorage{Access: BootstrapDataBlobAccessManagedIdentity},
+			delivery: BootstrapDataDeliveryBlob,
+			identity: VMIdentitySystemAssigned,
+			wantErr:  true,
+		},
+		{
+			name:     "unknown access",
+			storage:  &BootstrapDataStorage{Access: "AccountKey"},
+			delivery: BootstrapDataDeliveryBlob,
+			wantErr:  true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateBootstrapDataStorage(tc.storage, tc.delivery, tc.identity, field.NewPath("bootstrapDataStorage"))
+			if tc.wantErr {
+				g.Expect(err).ToNot(HaveLen(0))
+			} else {
+				g.Expect(err).To(HaveLen(0))
+			}
+		})
+	}
+}
+
+func TestAzureMachine_ValidateCapacityReservationGroupID(t *testing.T) {
+	g := NewWithT(t)
+
+	tests := []struct {
+		name    string
+		id      *string
+		wantErr bool
+	}{
+		{
+			name:    "nil capacity reservation group ID",
+			id:      nil,
+			wantErr: false,
+		},
+		{
+			name:    "valid capacity reservation group ID",
+			id:      to.StringPtr("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/capacityReservationGroups/my-crg"),
+			wantErr: false,
+		},
+		{
+			name:    "malformed resource ID",
+			id:      to.StringPtr("my-crg"),
+			wantErr: true,
+		},
+		{
+			name:    "resource ID of another resource type",
+			id:      to.StringPtr("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/availabilitySets/my-as"),
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateCapacityReservationGroupID(tc.id, field.NewPath("capacityReservationGroupID"))
+			if tc.wantErr {
+				g.Expect(err).ToNot(HaveLen(0))
+			} else {
+				g.Expect(err).To(HaveLen(0))
+			}
+		})
+	}
+}
+
+func TestAzureMachine_ValidateCapacityReservation(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := &AzureCluster{
+		Spec: AzureClusterSpec{
+			AzureClusterClassSpec: AzureClusterClassSpec{
+				SubscriptionID: "123",
+			},
+			ResourceGroup: "my-rg",
+			CapacityReservationGroups: []CapacityReservationGroup{
+				{
+					Name:  "my-crg",
+					Zones: []string{"1", "2"},
+					Reservations: []CapacityReservation{
+						{Name: "d2-1", VMSize: "Standard_D2s_v3", Zone: "1", Capacity: 2},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		id      *string
+		cluster *AzureCluster
+		vmSize  string
+		zone    *string
+		wantErr bool
+	}{
+		{
+			name:    "nil capacity reservation group ID",
+			cluster: cluster,
+			vmSize:  "Standard_D2s_v3",
+			wantErr: false,
+		},
+		{
+			name:    "no AzureCluster",
+			id:      to.StringPtr("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/capacityReservationGroups/my-crg"),
+			vmSize:  "Standard_D4s_v3",
+			wantErr: false,
+		},
+		{
+			name:    "group of the AzureCluster reserving the VM size",
+			id:      to.StringPtr("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/capacityReservationGroups/my-crg"),
+			cluster: cluster,
+			vmSize:  "standard_d2s_v3",
+			wantErr: false,
+		},
+		{
+			name:    "group of the AzureCluster reserving the VM size in the zone",
+			id:      to.StringPtr("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/capacityReservationGroups/my-crg"),
+			cluster: cluster,
+			vmSize:  "Standard_D2s_v3",
+			zone:    to.StringPtr("1"),
+			wantErr: false,
+		},
+		{
+			name:    "group of the AzureCluster not reserving the VM size",
+			id:      to.StringPtr("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/capacityReservationGroups/my-crg"),
+			cluster: cluster,
+			vmSize:  "Standard_D4s_v3",
+			wantErr: true,
+		},
+		{
+			name:    "group of the AzureCluster not reserving the VM size in the zone",
+			id:      to.StringPtr("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/capacityReservationGroups/my-crg"),
+			cluster: cluster,
+			vmSize:  "Standard_D2s_v3",
+			zone:    to.StringPtr("2"),
+			wantErr: true,
+		},
+		{
+			name:    "group of the AzureCluster without a regional reservation",
+			id:      to.StringPtr("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/capacityReservationGroups/my-crg"),
+			cluster: cluster,
+			vmSize:  "Standard_D2s_v3",
+			zone:    to.StringPtr(""),
+			wantErr: true,
+		},
+		{
+			name:    "group in another resource group",
+			id:      to.StringPtr("/subscriptions/123/resourceGroups/other-rg/providers/Microsoft.Compute/capacityReservationGroups/my-crg"),
+			cluster: cluster,
+			vmSize:  "Standard_D4s_v3",
+			wantErr: false,
+		},
+		{
+			name:    "group in another subscription",
+			id:      to.StringPtr("/subscriptions/456/resourceGroups/my-rg/providers/Microsoft.Compute/capacityReservationGroups/my-crg"),
+			cluster: cluster,
+			vmSize:  "Standard_D4s_v3",
+			wantErr: false,
+		},
+		{
+			name:    "group which isn't managed by the AzureCluster",
+			id:      to.StringPtr("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/capacityReservationGroups/other-crg"),
+			cluster: cluster,
+			vmSize:  "Standard_D4s_v3",
+			wantErr: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateCapacityReservation(tc.id, tc.cluster, tc.vmSize, tc.zone, field.NewPath("capacityReservationGroupID"))
+			if tc.wantErr {
+				g.Expect(err).ToNot(HaveLen(0))
+			} else {
+				g.Expect(err).To(HaveLen(0))
+			}
+		})
+	}
+}
+
 func generateSSHPublicKey(b64Enconded bool) string {
 	privateKey, _ := rsa.GenerateKey(rand.Reader, 2048)
 	publicRsaKey, _ := ssh.NewPublicKey(&privateKey.PublicKey)
@@ -105,7 +489,7 @@ func TestAzureMachine_ValidateOSDisk(t *testing.T) {
 				CachingType: "None",
 				OSType:      "blah",
 				DiffDiskSettings: &DiffDiskSettings{
-					Option: string(compute.DiffDiskOptionsLocal),
+					Option: string(compute.Local),
 				},
 				ManagedDisk: &ManagedDiskParameters{
 					StorageAccountType: "Standard_LRS",
@@ -120,7 +504,7 @@ func TestAzureMachine_ValidateOSDisk(t *testing.T) {
 				CachingType: "None",
 				OSType:      "blah",
 				DiffDiskSettings: &DiffDiskSettings{
-					Option: string(compute.DiffDiskOptionsLocal),
+					Option: string(compute.Local),
 				},
 				ManagedDisk: &ManagedDiskParameters{
 					StorageAccountType: "Standard_LRS",
@@ -193,7 +577,7 @@ func generateNegativeTestCases() []osDiskTestInput {
 				StorageAccountType: "Premium_LRS",
 			},
 			DiffDiskSettings: &DiffDiskSettings{
-				Option: string(compute.DiffDiskOptionsLocal),
+				Option: string(compute.Local),
 			},
 		},
 	}
@@ -385,6 +769,142 @@ func TestAzureMachine_ValidateDataDisks(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid existing disk",
+			disks: []DataDisk{
+				{
+					NameSuffix:   "my_disk_1",
+					SourceDiskID: to.StringPtr("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/disks/my-disk"),
+					Lun:          to.Int32Ptr(0),
+					CachingType:  string(compute.CachingTypesNone),
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid existing disk ID",
+			disks: []DataDisk{
+				{
+					NameSuffix:   "my_disk_1",
+					SourceDiskID: to.StringPtr("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/snapshots/my-snapshot"),
+					Lun:          to.Int32Ptr(0),
+					CachingType:  string(compute.CachingTypesNone),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "existing disk with managed disk options",
+			disks: []DataDisk{
+				{
+					NameSuffix:   "my_disk_1",
+					SourceDiskID: to.StringPtr("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/disks/my-disk"),
+					ManagedDisk: &ManagedDiskParameters{
+						StorageAccountType: "Premium_LRS",
+					},
+					Lun:         to.Int32Ptr(0),
+					CachingType: string(compute.CachingTypesNone),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid ultra disk with IOPS and bandwidth",
+			disks: []DataDisk{
+				{
+					NameSuffix: "my_disk_1",
+					DiskSizeGB: 64,
+					ManagedDisk: &ManagedDiskParameters{
+						StorageAccountType: "UltraSSD_LRS",
+					},
+					DiskIOPSReadWrite: to.Int64Ptr(4000),
+					DiskMBpsReadWrite: to.Int64Ptr(200),
+					Lun:               to.Int32Ptr(0),
+					CachingType:       string(compute.CachingTypesNone),
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid premium v2 disk with IOPS",
+			disks: []DataDisk{
+				{
+					NameSuffix: "my_disk_1",
+					DiskSizeGB: 64,
+					ManagedDisk: &ManagedDiskParameters{
+						StorageAccountType: StorageAccountTypePremiumV2LRS,
+					},
+					DiskIOPSReadWrite: to.Int64Ptr(4000),
+					Lun:               to.Int32Ptr(0),
+					CachingType:       string(compute.CachingTypesNone),
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "IOPS on a premium disk",
+			disks: []DataDisk{
+				{
+					NameSuffix: "my_disk_1",
+					DiskSizeGB: 64,
+					ManagedDisk: &ManagedDiskParameters{
+						StorageAccountType: "Premium_LRS",
+					},
+					DiskIOPSReadWrite: to.Int64Ptr(4000),
+					Lun:               to.Int32Ptr(0),
+					CachingType:       string(compute.CachingTypesNone),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid shared disk",
+			disks: []DataDisk{
+				{
+					NameSuffix: "my_disk_1",
+					DiskSizeGB: 256,
+					ManagedDisk: &ManagedDiskParameters{
+						StorageAccountType: "Premium_LRS",
+					},
+					MaxShares:   to.Int32Ptr(2),
+					Lun:         to.Int32Ptr(0),
+					CachingType: string(compute.CachingTypesNone),
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "shared disk with host caching",
+			disks: []DataDisk{
+				{
+					NameSuffix: "my_disk_1",
+					DiskSizeGB: 256,
+					ManagedDisk: &ManagedDiskParameters{
+						StorageAccountType: "Premium_LRS",
+					},
+					MaxShares:   to.Int32Ptr(2),
+					Lun:         to.Int32Ptr(0),
+					CachingType: string(compute.CachingTypesReadWrite),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "shared standard HDD disk",
+			disks: []DataDisk{
+				{
+					NameSuffix: "my_disk_1",
+					DiskSizeGB: 256,
+					ManagedDisk: &ManagedDiskParameters{
+						StorageAccountType: "Standard_LRS",
+					},
+					MaxShares:   to.Int32Ptr(2),
+					Lun:         to.Int32Ptr(0),
+					CachingType: string(compute.CachingTypesNone),
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, test := range testcases {
@@ -399,6 +919,63 @@ func TestAzureMachine_ValidateDataDisks(t *testing.T) {
 	}
 }
 
+func TestAzureMachine_ValidateEnableUltraSSD(t *testing.T) {
+	g := NewWithT(t)
+
+	ultraDisks := []DataDisk{
+		{
+			NameSuffix: "my_disk_1",
+			DiskSizeGB: 64,
+			ManagedDisk: &ManagedDiskParameters{
+				StorageAccountType: "UltraSSD_LRS",
+			},
+			Lun: to.Int32Ptr(0),
+		},
+	}
+
+	tests := []struct {
+		name           string
+		enableUltraSSD *bool
+		disks          []DataDisk
+		wantErr        bool
+	}{
+		{
+			name:           "ultra disks with ultra SSD support unset",
+			enableUltraSSD: nil,
+			disks:          ultraDisks,
+			wantErr:        false,
+		},
+		{
+			name:           "ultra disks with ultra SSD support enabled",
+			enableUltraSSD: to.BoolPtr(true),
+			disks:          ultraDisks,
+			wantErr:        false,
+		},
+		{
+			name:           "no ultra disks with ultra SSD support disabled",
+			enableUltraSSD: to.BoolPtr(false),
+			disks:          nil,
+			wantErr:        false,
+		},
+		{
+			name:           "ultra disks with ultra SSD support disabled",
+			enableUltraSSD: to.BoolPtr(false),
+			disks:          ultraDisks,
+			wantErr:        true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateEnableUltraSSD(tc.enableUltraSSD, tc.disks, field.NewPath("enableUltraSSD"))
+			if tc.wantErr {
+				g.Expect(err).NotTo(HaveLen(0))
+			} else {
+				g.Expect(err).To(HaveLen(0))
+			}
+		})
+	}
+}
+
 func TestAzureMachine_ValidateSystemAssignedIdentity(t *testing.T) {
 	g := NewWithT(t)
 
@@ -458,10 +1035,11 @@ func TestAzureMachine_ValidateDataDisksUpdate(t *testing.T) {
 	g := NewWithT(t)
 
 	tests := []struct {
-		name     string
-		disks    []DataDisk
-		oldDisks []DataDisk
-		wantErr  bool
+		name          string
+		disks         []DataDisk
+		oldDisks      []DataDisk
+		removalPolicy DataDiskRemovalPolicy
+		wantErr       bool
 	}{
 		{
 			name:     "valid nil data disks",
@@ -567,7 +1145,7 @@ func TestAzureMachine_ValidateDataDisksUpdate(t *testing.T) {
 			wantErr: true,
 		},
 		{
-			name: "data disks cannot be added after machine creation",
+			name: "data disks cannot be removed after machine creation without a removal policy",
 			disks: []DataDisk{
 				{
 					NameSuffix: "my_disk_1",
@@ -602,7 +1180,7 @@ func TestAzureMachine_ValidateDataDisksUpdate(t *testing.T) {
 			wantErr: true,
 		},
 		{
-			name: "data disks cannot be removed after machine creation",
+			name: "data disks can be added after machine creation",
 			disks: []DataDisk{
 				{
 					NameSuffix: "my_disk_1",
@@ -634,13 +1212,131 @@ func TestAzureMachine_ValidateDataDisksUpdate(t *testing.T) {
 					CachingType: string(compute.PossibleCachingTypesValues()[0]),
 				},
 			},
+			wantErr: false,
+		},
+		{
+			name: "data disks can be removed after machine creation with a removal policy",
+			disks: []DataDisk{
+				{
+					NameSuffix: "my_disk_1",
+					DiskSizeGB: 64,
+					Lun:        to.Int32Ptr(0),
+				},
+			},
+			oldDisks: []DataDisk{
+				{
+					NameSuffix: "my_disk_1",
+					DiskSizeGB: 64,
+					Lun:        to.Int32Ptr(0),
+				},
+				{
+					NameSuffix: "my_disk_2",
+					DiskSizeGB: 64,
+					Lun:        to.Int32Ptr(1),
+				},
+			},
+			removalPolicy: DataDiskRemovalPolicyRetain,
+			wantErr:       false,
+		},
+		{
+			name: "data disks can be grown after machine creation",
+			disks: []DataDisk{
+				{
+					NameSuffix: "my_disk_1",
+					DiskSizeGB: 128,
+					Lun:        to.Int32Ptr(0),
+				},
+			},
+			oldDisks: []DataDisk{
+				{
+					NameSuffix: "my_disk_1",
+					DiskSizeGB: 64,
+					Lun:        to.Int32Ptr(0),
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "data disks cannot be shrunk after machine creation",
+			disks: []DataDisk{
+				{
+					NameSuffix: "my_disk_1",
+					DiskSizeGB: 32,
+					Lun:        to.Int32Ptr(0),
+				},
+			},
+			oldDisks: []DataDisk{
+				{
+					NameSuffix: "my_disk_1",
+					DiskSizeGB: 64,
+					Lun:        to.Int32Ptr(0),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "data disks can change their IOPS after machine creation",
+			disks: []DataDisk{
+				{
+					NameSuffix:        "my_disk_1",
+					DiskSizeGB:        64,
+					DiskIOPSReadWrite: to.Int64Ptr(8000),
+					Lun:               to.Int32Ptr(0),
+				},
+			},
+			oldDisks: []DataDisk{
+				{
+					NameSuffix:        "my_disk_1",
+					DiskSizeGB:        64,
+					DiskIOPSReadWrite: to.Int64Ptr(4000),
+					Lun:               to.Int32Ptr(0),
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "data disks cannot change their max shares after machine creation",
+			disks: []DataDisk{
+				{
+					NameSuffix: "my_disk_1",
+					DiskSizeGB: 64,
+					MaxShares:  to.Int32Ptr(3),
+					Lun:        to.Int32Ptr(0),
+				},
+			},
+			oldDisks: []DataDisk{
+				{
+					NameSuffix: "my_disk_1",
+					DiskSizeGB: 64,
+					MaxShares:  to.Int32Ptr(2),
+					Lun:        to.Int32Ptr(0),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "data disks cannot change their source disk after machine creation",
+			disks: []DataDisk{
+				{
+					NameSuffix:   "my_disk_1",
+					SourceDiskID: to.StringPtr("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/disks/other-disk"),
+					Lun:          to.Int32Ptr(0),
+				},
+			},
+			oldDisks: []DataDisk{
+				{
+					NameSuffix:   "my_disk_1",
+					SourceDiskID: to.StringPtr("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/disks/my-disk"),
+					Lun:          to.Int32Ptr(0),
+				},
+			},
 			wantErr: true,
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			err := ValidateDataDisksUpdate(test.oldDisks, test.disks, field.NewPath("dataDisks"))
+			err := ValidateDataDisksUpdate(test.oldDisks, test.disks, test.removalPolicy, field.NewPath("dataDisks"))
 			if test.wantErr {
 				g.Expect(err).NotTo(HaveLen(0))
 			} else {
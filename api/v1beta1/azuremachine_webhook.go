@@ -17,17 +17,26 @@ limitations under the License.
 package v1beta1
 
 import (
+	"context"
 	"reflect"
 
+	"github.com/pkg/errors"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	webhookutils "sigs.k8s.io/cluster-api-provider-azure/util/webhook"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // SetupWebhookWithManager sets up and registers the webhook with the manager.
 func (m *AzureMachine) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	// the validating webhook reads the AzureCluster of the machine, so it is registered with a client
+	mgr.GetWebhookServer().Register("/validate-infrastructure-cluster-x-k8s-io-v1beta1-azuremachine",
+		webhookutils.NewValidatingWebhook(m, mgr.GetClient()))
+
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(m).
 		Complete()
@@ -36,22 +45,35 @@ func (m *AzureMachine) SetupWebhookWithManager(mgr ctrl.Manager) error {
 // +kubebuilder:webhook:verbs=create;update,path=/validate-infrastructure-cluster-x-k8s-io-v1beta1-azuremachine,mutating=false,failurePolicy=fail,matchPolicy=Equivalent,groups=infrastructure.cluster.x-k8s.io,resources=azuremachines,versions=v1beta1,name=validation.azuremachine.infrastructure.cluster.x-k8s.io,sideEffects=None,admissionReviewVersions=v1;v1beta1
 // +kubebuilder:webhook:verbs=create;update,path=/mutate-infrastructure-cluster-x-k8s-io-v1beta1-azuremachine,mutating=true,failurePolicy=fail,matchPolicy=Equivalent,groups=infrastructure.cluster.x-k8s.io,resources=azuremachines,versions=v1beta1,name=default.azuremachine.infrastructure.cluster.x-k8s.io,sideEffects=None,admissionReviewVersions=v1;v1beta1
 
-var _ webhook.Validator = &AzureMachine{}
+var _ webhookutils.Validator = &AzureMachine{}
 
-// ValidateCreate implements webhook.Validator so a webhook will be registered for the type.
-func (m *AzureMachine) ValidateCreate() error {
-	if allErrs := ValidateAzureMachineSpec(m.Spec); len(allErrs) > 0 {
+// ValidateCreate implements webhookutils.Validator so a webhook will be registered for the type.
+func (m *AzureMachine) ValidateCreate(cli client.Client) error {
+	allErrs := ValidateAzureMachineSpec(m.Spec)
+	allErrs = append(allErrs, m.validateCapacityReservation(cli)...)
+	if len(allErrs) > 0 {
 		return apierrors.NewInvalid(GroupVersion.WithKind("AzureMachine").GroupKind(), m.Name, allErrs)
 	}
 
 	return nil
 }
 
-// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
-func (m *AzureMachine) ValidateUpdate(oldRaw runtime.Object) error {
+// ValidateUpdate implements webhookutils.Validator so a webhook will be registered for the type.
+func (m *AzureMachine) ValidateUpdate(oldRaw runtime.Object, cli client.Client) error {
 	var allErrs field.ErrorList
 	old := oldRaw.(*AzureMachine)
 
+	if m.Spec.VMSize != old.Spec.VMSize {
+		if !m.Spec.EnableInPlaceResize {
+			allErrs = append(allErrs,
+				field.Invalid(field.NewPath("spec", "vmSize"),
+					m.Spec.VMSize, "field is immutable unless enableInPlaceResize is set"),
+			)
+		} else {
+			allErrs = append(allErrs, m.validateCapacityReservation(cli)...)
+		}
+	}
+
 	if !reflect.DeepEqual(m.Spec.Image, old.Spec.Image) {
 		allErrs = append(allErrs,
 			field.Invalid(field.NewPath("spec", "image"),
@@ -88,9 +110,15 @@ func (m *AzureMachine) ValidateUpdate(oldRaw runtime.Object) error {
 	}
 
 	if !reflect.DeepEqual(m.Spec.DataDisks, old.Spec.DataDisks) {
+		allErrs = append(allErrs, ValidateDataDisksUpdate(old.Spec.DataDisks, m.Spec.DataDisks, m.Spec.DataDiskRemovalPolicy, field.NewPath("spec", "dataDisks"))...)
+		allErrs = append(allErrs, ValidateDataDisks(m.Spec.DataDisks, field.NewPath("spec", "dataDisks"))...)
+		allErrs = append(allErrs, ValidateEnableUltraSSD(m.Spec.EnableUltraSSD, m.Spec.DataDisks, field.NewPath("spec", "enableUltraSSD"))...)
+	}
+
+	if !reflect.DeepEqual(m.Spec.EnableUltraSSD, old.Spec.EnableUltraSSD) {
 		allErrs = append(allErrs,
-			field.Invalid(field.NewPath("spec", "dataDisks"),
-				m.Spec.DataDisks, "field is immutable"),
+			field.Invalid(field.NewPath("spec", "enableUltraSSD"),
+				m.Spec.EnableUltraSSD, "field is immutable"),
 		)
 	}
 
@@ -136,18 +164,109 @@ func (m *AzureMachine) ValidateUpdate(oldRaw runtime.Object) error {
 		)
 	}
 
+	if !reflect.DeepEqual(m.Spec.Diagnostics, old.Spec.Diagnostics) {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec", "diagnostics"),
+				m.Spec.Diagnostics, "field is immutable"),
+		)
+	}
+
+	if m.Spec.BootstrapDataDelivery != old.Spec.BootstrapDataDelivery {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec", "bootstrapDataDelivery"),
+				m.Spec.BootstrapDataDelivery, "field is immutable"),
+		)
+	}
+
+	if !reflect.DeepEqual(m.Spec.BootstrapDataStorage, old.Spec.BootstrapDataStorage) {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec", "bootstrapDataStorage"),
+				m.Spec.BootstrapDataStorage, "field is immutable"),
+		)
+	}
+
+	if !reflect.DeepEqual(m.Spec.CapacityReservationGroupID, old.Spec.CapacityReservationGroupID) {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec", "capacityReservationGroupID"),
+				m.Spec.CapacityReservationGroupID, "field is immutable"),
+		)
+	}
+
 	if len(allErrs) == 0 {
 		return nil
 	}
 	return apierrors.NewInvalid(GroupVersion.WithKind("AzureMachine").GroupKind(), m.Name, allErrs)
 }
 
-// ValidateDelete implements webhook.Validator so a webhook will be registered for the type.
-func (m *AzureMachine) ValidateDelete() error {
+// ValidateDelete implements webhookutils.Validator so a webhook will be registered for the type.
+func (m *AzureMachine) ValidateDelete(cli client.Client) error {
 	return nil
 }
 
+// validateCapacityReservation validates that the capacity reservation group of the AzureMachine reserves capacity for
+// its VM size and failure domain, when the group is one of the capacity reservation groups of its AzureCluster.
+func (m *AzureMachine) validateCapacityReservation(cli client.Client) field.ErrorList {
+	fldPath := field.NewPath("spec", "capacityReservationGroupID")
+	if m.Spec.CapacityReservationGroupID == nil {
+		return nil
+	}
+
+	cluster, err := GetOwnerAzureCluster(context.Background(), cli, m)
+	if err != nil {
+		return field.ErrorList{field.InternalError(fldPath, err)}
+	}
+
+	return ValidateCapacityReservation(m.Spec.CapacityReservationGroupID, cluster, m.Spec.VMSize, m.Spec.FailureDomain, fldPath)
+}
+
+// GetOwnerAzureCluster returns the AzureCluster of the Cluster an object belongs to, as given by its cluster name label.
+// It returns nil if the object doesn't belong to a Cluster, or if the infrastructure of the Cluster isn't an AzureCluster.
+func GetOwnerAzureCluster(ctx context.Context, cli client.Client, obj metav1.Object) (*AzureCluster, error) {
+	clusterName, ok := obj.GetLabels()[clusterv1.ClusterLabelName]
+	if !ok || cli == nil {
+		return nil, nil
+	}
+
+	cluster := &clusterv1.Cluster{}
+	key := client.ObjectKey{
+		Namespace: obj.GetNamespace(),
+		Name:      clusterName,
+	}
+	if err := cli.Get(ctx, key, cluster); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to get Cluster %s", key)
+	}
+
+	ref := cluster.Spec.InfrastructureRef
+	if ref == nil || ref.Kind != "AzureCluster" {
+		return nil, nil
+	}
+
+	azureCluster := &AzureCluster{}
+	key = client.ObjectKey{
+		Namespace: cluster.Namespace,
+		Name:      ref.Name,
+	}
+	if err := cli.Get(ctx, key, azureCluster); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to get AzureCluster %s", key)
+	}
+
+	return azureCluster, nil
+}
+
 // Default implements webhookutil.defaulter so a webhook will be registered for the type.
 func (m *AzureMachine) Default() {
 	m.Spec.SetDefaults()
+	// The image is immutable, so it is only defaulted when the AzureMachine is created, i.e. before the API server
+	// sets its creation timestamp.
+	if m.CreationTimestamp.IsZero() {
+		if err := m.Spec.SetDefaultImage(); err != nil {
+			ctrl.Log.WithName("SetDefault").Error(err, "SetDefaultImage failed")
+		}
+	}
 }
@@ -21,8 +21,13 @@ import (
 
 	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-12-01/compute"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 var (
@@ -106,7 +111,83 @@ func TestAzureMachine_ValidateCreate(t *testing.T) {
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			err := tc.machine.ValidateCreate()
+			err := tc.machine.ValidateCreate(nil)
+			if tc.wantErr {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestAzureMachine_ValidateCreateCapacityReservation(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(AddToScheme(scheme)).To(Succeed())
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-cluster", Namespace: "default"},
+			Spec: clusterv1.ClusterSpec{
+				InfrastructureRef: &corev1.ObjectReference{Kind: "AzureCluster", Name: "my-azure-cluster"},
+			},
+		},
+		&AzureCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-azure-cluster", Namespace: "default"},
+			Spec: AzureClusterSpec{
+				ResourceGroup: "my-rg",
+				CapacityReservationGroups: []CapacityReservationGroup{
+					{
+						Name: "my-crg",
+						Reservations: []CapacityReservation{
+							{Name: "d2", VMSize: "Standard_D2s_v3", Capacity: 2},
+						},
+					},
+				},
+			},
+		},
+	).Build()
+
+	tests := []struct {
+		name    string
+		labels  map[string]string
+		vmSize  string
+		wantErr bool
+	}{
+		{
+			name:    "azuremachine with a VM size reserved by the capacity reservation group of its AzureCluster",
+			labels:  map[string]string{clusterv1.ClusterLabelName: "my-cluster"},
+			vmSize:  "Standard_D2s_v3",
+			wantErr: false,
+		},
+		{
+			name:    "azuremachine with a VM size not reserved by the capacity reservation group of its AzureCluster",
+			labels:  map[string]string{clusterv1.ClusterLabelName: "my-cluster"},
+			vmSize:  "Standard_D4s_v3",
+			wantErr: true,
+		},
+		{
+			name:    "azuremachine of an unknown cluster",
+			labels:  map[string]string{clusterv1.ClusterLabelName: "other-cluster"},
+			vmSize:  "Standard_D4s_v3",
+			wantErr: false,
+		},
+		{
+			name:    "azuremachine without a cluster",
+			vmSize:  "Standard_D4s_v3",
+			wantErr: false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			machine := createMachineWithSSHPublicKey(validSSHPublicKey)
+			machine.Namespace = "default"
+			machine.Labels = tc.labels
+			machine.Spec.VMSize = tc.vmSize
+			machine.Spec.CapacityReservationGroupID = pointer.String("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/capacityReservationGroups/my-crg")
+			err := machine.ValidateCreate(cli)
 			if tc.wantErr {
 				g.Expect(err).To(HaveOccurred())
 			} else {
@@ -125,6 +206,35 @@ func TestAzureMachine_ValidateUpdate(t *testing.T) {
 		newMachine *AzureMachine
 		wantErr    bool
 	}{
+		{
+			name: "invalidTest: azuremachine.spec.vmSize is immutable without in-place resize",
+			oldMachine: &AzureMachine{
+				Spec: AzureMachineSpec{
+					VMSize: "Standard_D2s_v3",
+				},
+			},
+			newMachine: &AzureMachine{
+				Spec: AzureMachineSpec{
+					VMSize: "Standard_D4s_v3",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "validTest: azuremachine.spec.vmSize can be changed with in-place resize",
+			oldMachine: &AzureMachine{
+				Spec: AzureMachineSpec{
+					VMSize: "Standard_D2s_v3",
+				},
+			},
+			newMachine: &AzureMachine{
+				Spec: AzureMachineSpec{
+					VMSize:              "Standard_D4s_v3",
+					EnableInPlaceResize: true,
+				},
+			},
+			wantErr: false,
+		},
 		{
 			name: "invalidTest: azuremachine.spec.image is immutable",
 			oldMachine: &AzureMachine{
@@ -333,6 +443,94 @@ func TestAzureMachine_ValidateUpdate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "validTest: azuremachine.spec.DataDisks can be added and grown",
+			oldMachine: &AzureMachine{
+				Spec: AzureMachineSpec{
+					DataDisks: []DataDisk{
+						{
+							NameSuffix:  "disk1",
+							DiskSizeGB:  128,
+							Lun:         pointer.Int32(0),
+							CachingType: "ReadWrite",
+						},
+					},
+				},
+			},
+			newMachine: &AzureMachine{
+				Spec: AzureMachineSpec{
+					DataDisks: []DataDisk{
+						{
+							NameSuffix:  "disk1",
+							DiskSizeGB:  256,
+							Lun:         pointer.Int32(0),
+							CachingType: "ReadWrite",
+						},
+						{
+							NameSuffix:  "disk2",
+							DiskSizeGB:  64,
+							Lun:         pointer.Int32(1),
+							CachingType: "ReadWrite",
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalidTest: azuremachine.spec.DataDisks cannot be removed without a removal policy",
+			oldMachine: &AzureMachine{
+				Spec: AzureMachineSpec{
+					DataDisks: []DataDisk{
+						{
+							NameSuffix:  "disk1",
+							DiskSizeGB:  128,
+							Lun:         pointer.Int32(0),
+							CachingType: "ReadWrite",
+						},
+					},
+				},
+			},
+			newMachine: &AzureMachine{
+				Spec: AzureMachineSpec{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "validTest: azuremachine.spec.DataDisks can be removed with a removal policy",
+			oldMachine: &AzureMachine{
+				Spec: AzureMachineSpec{
+					DataDisks: []DataDisk{
+						{
+							NameSuffix:  "disk1",
+							DiskSizeGB:  128,
+							Lun:         pointer.Int32(0),
+							CachingType: "ReadWrite",
+						},
+					},
+				},
+			},
+			newMachine: &AzureMachine{
+				Spec: AzureMachineSpec{
+					DataDiskRemovalPolicy: DataDiskRemovalPolicyDelete,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalidTest: azuremachine.spec.EnableUltraSSD is immutable",
+			oldMachine: &AzureMachine{
+				Spec: AzureMachineSpec{
+					EnableUltraSSD: pointer.Bool(true),
+				},
+			},
+			newMachine: &AzureMachine{
+				Spec: AzureMachineSpec{
+					EnableUltraSSD: pointer.Bool(false),
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalidTest: azuremachine.spec.SSHPublicKey is immutable",
 			oldMachine: &AzureMachine{
@@ -509,10 +707,95 @@ func TestAzureMachine_ValidateUpdate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "invalidTest: azuremachine.spec.Diagnostics is immutable",
+			oldMachine: &AzureMachine{
+				Spec: AzureMachineSpec{
+					Diagnostics: &Diagnostics{Boot: &BootDiagnostics{StorageAccountType: ManagedDiagnosticsStorage}},
+				},
+			},
+			newMachine: &AzureMachine{
+				Spec: AzureMachineSpec{
+					Diagnostics: &Diagnostics{Boot: &BootDiagnostics{StorageAccountType: DisabledDiagnosticsStorage}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "validTest: azuremachine.spec.Diagnostics is immutable",
+			oldMachine: &AzureMachine{
+				Spec: AzureMachineSpec{
+					Diagnostics: &Diagnostics{Boot: &BootDiagnostics{StorageAccountType: ManagedDiagnosticsStorage}},
+				},
+			},
+			newMachine: &AzureMachine{
+				Spec: AzureMachineSpec{
+					Diagnostics: &Diagnostics{Boot: &BootDiagnostics{StorageAccountType: ManagedDiagnosticsStorage}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalidTest: azuremachine.spec.BootstrapDataDelivery is immutable",
+			oldMachine: &AzureMachine{
+				Spec: AzureMachineSpec{
+					BootstrapDataDelivery: BootstrapDataDeliveryCustomData,
+				},
+			},
+			newMachine: &AzureMachine{
+				Spec: AzureMachineSpec{
+					BootstrapDataDelivery: BootstrapDataDeliveryUserData,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalidTest: azuremachine.spec.BootstrapDataStorage is immutable",
+			oldMachine: &AzureMachine{
+				Spec: AzureMachineSpec{
+					BootstrapDataDelivery: BootstrapDataDeliveryBlob,
+				},
+			},
+			newMachine: &AzureMachine{
+				Spec: AzureMachineSpec{
+					BootstrapDataDelivery: BootstrapDataDeliveryBlob,
+					BootstrapDataStorage:  &BootstrapDataStorage{ContainerName: "other"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalidTest: azuremachine.spec.CapacityReservationGroupID is immutable",
+			oldMachine: &AzureMachine{
+				Spec: AzureMachineSpec{
+					CapacityReservationGroupID: pointer.String("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/capacityReservationGroups/crg-1"),
+				},
+			},
+			newMachine: &AzureMachine{
+				Spec: AzureMachineSpec{
+					CapacityReservationGroupID: pointer.String("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/capacityReservationGroups/crg-2"),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "validTest: azuremachine.spec.CapacityReservationGroupID is immutable",
+			oldMachine: &AzureMachine{
+				Spec: AzureMachineSpec{
+					CapacityReservationGroupID: pointer.String("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/capacityReservationGroups/crg-1"),
+				},
+			},
+			newMachine: &AzureMachine{
+				Spec: AzureMachineSpec{
+					CapacityReservationGroupID: pointer.String("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/capacityReservationGroups/crg-1"),
+				},
+			},
+			wantErr: false,
+		},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			err := tc.newMachine.ValidateUpdate(tc.oldMachine)
+			err := tc.newMachine.ValidateUpdate(tc.oldMachine, nil)
 			if tc.wantErr {
 				g.Expect(err).To(HaveOccurred())
 			} else {
@@ -550,6 +833,78 @@ func TestAzureMachine_Default(t *testing.T) {
 	}
 }
 
+type fakeDefaultImageResolver map[string]*Image
+
+func (r fakeDefaultImageResolver) GetDefaultImage(k8sVersion, osType, flavor string) (*Image, error) {
+	if k8sVersion != "" || flavor != "" {
+		return nil, nil
+	}
+	if osType == "" {
+		osType = "Linux"
+	}
+	return r[osType].DeepCopy(), nil
+}
+
+func TestAzureMachine_DefaultImage(t *testing.T) {
+	linuxImage := &Image{ID: pointer.StringPtr("/subscriptions/123/resourceGroups/images/providers/Microsoft.Compute/images/linux")}
+	windowsImage := &Image{ID: pointer.StringPtr("/subscriptions/123/resourceGroups/images/providers/Microsoft.Compute/images/windows")}
+	customImage := &Image{ID: pointer.StringPtr("/subscriptions/123/resourceGroups/images/providers/Microsoft.Compute/images/custom")}
+
+	tests := []struct {
+		name     string
+		resolver DefaultImageResolver
+		machine  *AzureMachine
+		want     *Image
+	}{
+		{
+			name:    "no default image resolver",
+			machine: &AzureMachine{},
+			want:    nil,
+		},
+		{
+			name:     "new machine without image",
+			resolver: fakeDefaultImageResolver{"Linux": linuxImage, "Windows": windowsImage},
+			machine:  &AzureMachine{},
+			want:     linuxImage,
+		},
+		{
+			name:     "new Windows machine without image",
+			resolver: fakeDefaultImageResolver{"Linux": linuxImage, "Windows": windowsImage},
+			machine:  &AzureMachine{Spec: AzureMachineSpec{OSDisk: OSDisk{OSType: "Windows"}}},
+			want:     windowsImage,
+		},
+		{
+			name:     "new machine with image",
+			resolver: fakeDefaultImageResolver{"Linux": linuxImage},
+			machine:  &AzureMachine{Spec: AzureMachineSpec{Image: customImage}},
+			want:     customImage,
+		},
+		{
+			name:     "existing machine without image",
+			resolver: fakeDefaultImageResolver{"Linux": linuxImage},
+			machine:  &AzureMachine{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.Now()}},
+			want:     nil,
+		},
+		{
+			name:     "no default image for all Kubernetes versions",
+			resolver: fakeDefaultImageResolver{"Windows": windowsImage},
+			machine:  &AzureMachine{},
+			want:     nil,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			SetDefaultImageResolver(tc.resolver)
+			defer SetDefaultImageResolver(nil)
+
+			tc.machine.Default()
+			g.Expect(tc.machine.Spec.Image).To(Equal(tc.want))
+		})
+	}
+}
+
 func createMachineWithSharedImage(subscriptionID, resourceGroup, name, gallery, version string) *AzureMachine {
 	image := &Image{
 		SharedGallery: &AzureSharedGalleryImage{
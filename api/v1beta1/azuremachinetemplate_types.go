@@ -17,6 +17,7 @@ limitations under the License.
 package v1beta1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 )
@@ -26,16 +27,26 @@ type AzureMachineTemplateSpec struct {
 	Template AzureMachineTemplateResource `json:"template"`
 }
 
+// AzureMachineTemplateStatus defines the observed state of AzureMachineTemplate.
+type AzureMachineTemplateStatus struct {
+	// Capacity is the CPU, memory and GPU capacity of the VM size of the template, and the default maximum number of
+	// pods of its nodes. It is used by the cluster-autoscaler to scale node groups from zero.
+	// +optional
+	Capacity corev1.ResourceList `json:"capacity,omitempty"`
+}
+
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:path=azuremachinetemplates,scope=Namespaced,categories=cluster-api
 // +kubebuilder:storageversion
+// +kubebuilder:subresource:status
 
 // AzureMachineTemplate is the Schema for the azuremachinetemplates API.
 type AzureMachineTemplate struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec AzureMachineTemplateSpec `json:"spec,omitempty"`
+	Spec   AzureMachineTemplateSpec   `json:"spec,omitempty"`
+	Status AzureMachineTemplateStatus `json:"status,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -38,6 +38,18 @@ const (
 	VMDeletingReason = "VMDeleting"
 	// VMProvisionFailedReason used for failures during vm provisioning.
 	VMProvisionFailedReason = "VMProvisionFailed"
+	// VMResizedCondition reports on the progress of an in-place resize of the Azure VM.
+	VMResizedCondition clusterv1.ConditionType = "VMResized"
+	// VMActionCondition reports on the progress of the last action requested on the Azure VM with the
+	// azure.cluster.x-k8s.io/vm-action annotation.
+	VMActionCondition clusterv1.ConditionType = "VMAction"
+	// HibernatedCondition reports on the hibernation of an AzureCluster and of its machines. It is only set while the
+	// cluster is hibernating, hibernated or resuming.
+	HibernatedCondition clusterv1.ConditionType = "Hibernated"
+	// HibernatingReason is used while the VMs are being deallocated or scaled down to hibernate the cluster.
+	HibernatingReason = "Hibernating"
+	// ResumingReason is used while the VMs are being started or scaled up again to resume the cluster.
+	ResumingReason = "Resuming"
 	// WaitingForClusterInfrastructureReason used when machine is waiting for cluster infrastructure to be ready before proceeding.
 	WaitingForClusterInfrastructureReason = "WaitingForClusterInfrastructure"
 	// WaitingForBootstrapDataReason used when machine is waiting for bootstrap data to be ready before proceeding.
@@ -74,6 +86,34 @@ const (
 	ScaleSetModelUpdatedCondition clusterv1.ConditionType = "ScaleSetModelUpdated"
 	// ScaleSetModelOutOfDateReason describes the machine pool model being out of date.
 	ScaleSetModelOutOfDateReason = "ScaleSetModelOutOfDate"
+
+	// ScaleSetDeploymentCondition reports on the blue/green deployment of a new scale set of the machine pool. It is
+	// only set while a deployment is in progress or after it was rolled back.
+	ScaleSetDeploymentCondition clusterv1.ConditionType = "ScaleSetDeployment"
+	// ScaleSetDeployingReason describes the machines of a new scale set becoming ready.
+	ScaleSetDeployingReason = "ScaleSetDeploying"
+	// ScaleSetRetiringReason describes the machines of the previous scale set being drained and deleted once the new
+	// scale set is ready.
+	ScaleSetRetiringReason = "ScaleSetRetiring"
+	// ScaleSetRolledBackReason describes a new scale set being deleted because its machines failed to become ready.
+	ScaleSetRolledBackReason = "ScaleSetRolledBack"
+
+	// RolloutPausedCondition reports the rolling update of the machine pool being paused because it disrupts the
+	// workloads of the cluster. It is removed once the rolling update resumes.
+	RolloutPausedCondition clusterv1.ConditionType = "RolloutPaused"
+	// DrainBlockedReason describes the drain of the node of a machine being deleted not succeeding in time.
+	DrainBlockedReason = "DrainBlocked"
+	// NodeNotReadyReason describes the node of a machine with the latest model not becoming ready in time.
+	NodeNotReadyReason = "NodeNotReady"
+)
+
+// AzureMachinePoolMachine Conditions and Reasons.
+const (
+	// ScheduledEventApprovedCondition reports on the handling of a pending Terminate or Preempt scheduled event of the
+	// Azure scale set VM. It is only set while such an event is pending.
+	ScheduledEventApprovedCondition clusterv1.ConditionType = "ScheduledEventApproved"
+	// ScheduledEventDrainingReason describes the node being drained before the scheduled event is approved.
+	ScheduledEventDrainingReason = "ScheduledEventDraining"
 )
 
 // AzureManagedCluster Conditions and Reasons.
@@ -100,6 +140,8 @@ const (
 	PublicIPsReadyCondition clusterv1.ConditionType = "PublicIPsReady"
 	// NATGatewaysReadyCondition means the NAT gateways exist and are ready to be used.
 	NATGatewaysReadyCondition clusterv1.ConditionType = "NATGatewaysReady"
+	// CapacityReservationGroupsReadyCondition means the capacity reservation groups exist and are ready to be used.
+	CapacityReservationGroupsReadyCondition clusterv1.ConditionType = "CapacityReservationGroupsReady"
 	// SubnetsReadyCondition means the subnets exist and are ready to be used.
 	SubnetsReadyCondition clusterv1.ConditionType = "SubnetsReady"
 	// LoadBalancersReadyCondition means the load balancers exist and are ready to be used.
@@ -122,6 +164,9 @@ const (
 	DisksReadyCondition clusterv1.ConditionType = "DisksReady"
 	// NetworkInterfaceReadyCondition means the network interfaces exist and are ready to be used.
 	NetworkInterfaceReadyCondition clusterv1.ConditionType = "NetworkInterfacesReady"
+	// BootstrapDataReadyCondition means the bootstrap data was uploaded to blob storage and is ready to be downloaded.
+	// The condition is removed once the bootstrap data is deleted from blob storage.
+	BootstrapDataReadyCondition clusterv1.ConditionType = "BootstrapDataReady"
 
 	// CreatingReason means the resource is being created.
 	CreatingReason = "Creating"
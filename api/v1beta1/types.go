@@ -17,6 +17,8 @@ limitations under the License.
 package v1beta1
 
 import (
+	"regexp"
+
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 )
@@ -40,6 +42,8 @@ const (
 	PutFuture string = "PUT"
 	// DeleteFuture is a future that was derived from a DELETE request.
 	DeleteFuture string = "DELETE"
+	// PostFuture is a future that was derived from a POST request, such as a virtual machine power operation.
+	PostFuture string = "POST"
 )
 
 // Future contains the data needed for an Azure long-running operation to continue across reconcile loops.
@@ -310,8 +314,9 @@ const (
 )
 
 // Image defines information about the image to use for VM creation.
-// There are three ways to specify an image: by ID, Marketplace Image or SharedImageGallery
-// One of ID, SharedImage or Marketplace should be set.
+// There are five ways to specify an image: by ID, Marketplace Image, SharedImageGallery, community gallery or
+// direct shared gallery.
+// One of ID, SharedGallery, Marketplace, CommunityGallery or DirectSharedGallery should be set.
 type Image struct {
 	// ID specifies an image to use by ID
 	// +optional
@@ -324,6 +329,27 @@ type Image struct {
 	// Marketplace specifies an image to use from the Azure Marketplace
 	// +optional
 	Marketplace *AzureMarketplaceImage `json:"marketplace,omitempty"`
+
+	// CommunityGallery specifies an image to use from an Azure Compute Gallery shared publicly with the community
+	// +optional
+	CommunityGallery *AzureCommunityGalleryImage `json:"communityGallery,omitempty"`
+
+	// DirectSharedGallery specifies an image to use from an Azure Compute Gallery shared directly with the
+	// subscription or tenant
+	// +optional
+	DirectSharedGallery *AzureDirectSharedGalleryImage `json:"directSharedGallery,omitempty"`
+}
+
+// LatestImageVersion is the image version resolving to the latest version of an image.
+const LatestImageVersion = "latest"
+
+// exactImageVersionRegex matches the Major.Minor.Build image versions.
+var exactImageVersionRegex = regexp.MustCompile(`^[0-9]+\.[0-9]+\.[0-9]+$`)
+
+// IsExactImageVersion returns true if an image version is a Major.Minor.Build version, and not 'latest' or a
+// version range to resolve.
+func IsExactImageVersion(version string) bool {
+	return exactImageVersionRegex.MatchString(version)
 }
 
 // AzureMarketplaceImage defines an image in the Azure Marketplace to use for VM creation.
@@ -340,10 +366,12 @@ type AzureMarketplaceImage struct {
 	// +kubebuilder:validation:MinLength=1
 	SKU string `json:"sku"`
 	// Version specifies the version of an image sku. The allowed formats
-	// are Major.Minor.Build or 'latest'. Major, Minor, and Build are decimal numbers.
-	// Specify 'latest' to use the latest version of an image available at deploy time.
-	// Even if you use 'latest', the VM image will not automatically update after deploy
-	// time even if a new version becomes available.
+	// are Major.Minor.Build, 'latest' or a semantic version range such as '1.22.x' or '>=1.22.0 <1.23.0'.
+	// Major, Minor, and Build are decimal numbers.
+	// Specify 'latest' or a range to use the latest matching version of an image available when the VM or
+	// Virtual Machine Scale Set is created. The resolved version is recorded in the status of the AzureMachine
+	// or AzureMachinePool. An AzureMachine keeps that version, and an AzureMachinePool updates to newer versions
+	// as they become available only if its ImageVersionUpdatePolicy is Rolling.
 	// +kubebuilder:validation:MinLength=1
 	Version string `json:"version"`
 	// ThirdPartyImage indicates the image is published by a third party publisher and a Plan
@@ -353,6 +381,42 @@ type AzureMarketplaceImage struct {
 	ThirdPartyImage bool `json:"thirdPartyImage"`
 }
 
+// AzureCommunityGalleryImage defines an image in an Azure Compute Gallery shared with the community to use for VM
+// creation.
+type AzureCommunityGalleryImage struct {
+	// Gallery specifies the public name of the community gallery that contains the image
+	// +kubebuilder:validation:MinLength=1
+	Gallery string `json:"gallery"`
+	// Name is the name of the image
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Version specifies the version of the image. The allowed formats
+	// are Major.Minor.Build or 'latest'. Major, Minor, and Build are decimal numbers.
+	// Specify 'latest' to use the latest version of an image available at deploy time.
+	// Even if you use 'latest', the VM image will not automatically update after deploy
+	// time even if a new version becomes available.
+	// +kubebuilder:validation:MinLength=1
+	Version string `json:"version"`
+}
+
+// AzureDirectSharedGalleryImage defines an image in an Azure Compute Gallery shared directly with the subscription or
+// tenant to use for VM creation.
+type AzureDirectSharedGalleryImage struct {
+	// Gallery specifies the unique name of the directly shared gallery that contains the image
+	// +kubebuilder:validation:MinLength=1
+	Gallery string `json:"gallery"`
+	// Name is the name of the image
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Version specifies the version of the image. The allowed formats
+	// are Major.Minor.Build or 'latest'. Major, Minor, and Build are decimal numbers.
+	// Specify 'latest' to use the latest version of an image available at deploy time.
+	// Even if you use 'latest', the VM image will not automatically update after deploy
+	// time even if a new version becomes available.
+	// +kubebuilder:validation:MinLength=1
+	Version string `json:"version"`
+}
+
 // AzureSharedGalleryImage defines an image in a Shared Image Gallery to use for VM creation.
 type AzureSharedGalleryImage struct {
 	// SubscriptionID is the identifier of the subscription that contains the shared image gallery
@@ -368,10 +432,13 @@ type AzureSharedGalleryImage struct {
 	// +kubebuilder:validation:MinLength=1
 	Name string `json:"name"`
 	// Version specifies the version of the marketplace image. The allowed formats
-	// are Major.Minor.Build or 'latest'. Major, Minor, and Build are decimal numbers.
-	// Specify 'latest' to use the latest version of an image available at deploy time.
-	// Even if you use 'latest', the VM image will not automatically update after deploy
-	// time even if a new version becomes available.
+	// are Major.Minor.Build, 'latest' or a semantic version range such as '1.22.x' or '>=1.22.0 <1.23.0'.
+	// Major, Minor, and Build are decimal numbers.
+	// Specify 'latest' or a range to use the latest matching version of an image available when the VM or
+	// Virtual Machine Scale Set is created. Versions excluded from latest are ignored. The resolved version is
+	// recorded in the status of the AzureMachine or AzureMachinePool. An AzureMachine keeps that version, and an
+	// AzureMachinePool updates to newer versions as they become available only if its ImageVersionUpdatePolicy
+	// is Rolling.
 	// +kubebuilder:validation:MinLength=1
 	Version string `json:"version"`
 	// Publisher is the name of the organization that created the image.
@@ -463,8 +530,25 @@ type DataDisk struct {
 	// NameSuffix is the suffix to be appended to the machine name to generate the disk name.
 	// Each disk name will be in format <machineName>_<nameSuffix>.
 	NameSuffix string `json:"nameSuffix"`
-	// DiskSizeGB is the size in GB to assign to the data disk.
+	// DiskSizeGB is the size in GB to assign to the data disk. It is ignored if SourceDiskID is set.
 	DiskSizeGB int32 `json:"diskSizeGB"`
+	// SourceDiskID is the resource ID of an existing managed disk to attach to the machine instead of creating
+	// a new empty disk. The existing disk keeps its name and properties, and is never deleted by CAPZ.
+	// +optional
+	SourceDiskID *string `json:"sourceDiskID,omitempty"`
+	// DiskIOPSReadWrite is the number of IOPS allowed for the data disk.
+	// Only UltraSSD_LRS and PremiumV2_LRS disks support it.
+	// +optional
+	DiskIOPSReadWrite *int64 `json:"diskIOPSReadWrite,omitempty"`
+	// DiskMBpsReadWrite is the bandwidth in MB per second allowed for the data disk.
+	// Only UltraSSD_LRS and PremiumV2_LRS disks support it.
+	// +optional
+	DiskMBpsReadWrite *int64 `json:"diskMBpsReadWrite,omitempty"`
+	// MaxShares is the maximum number of VMs that can attach the data disk at the same time.
+	// A value greater than one makes the disk a shared disk, which requires the caching type to be None.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxShares *int32 `json:"maxShares,omitempty"`
 	// ManagedDisk specifies the Managed Disk parameters for the data disk.
 	// +optional
 	ManagedDisk *ManagedDiskParameters `json:"managedDisk,omitempty"`
@@ -478,6 +562,20 @@ type DataDisk struct {
 	CachingType string `json:"cachingType,omitempty"`
 }
 
+// DataDiskRemovalPolicy describes what happens to a data disk that is removed from a machine after creation.
+// +kubebuilder:validation:Enum=Retain;Delete
+type DataDiskRemovalPolicy string
+
+const (
+	// DataDiskRemovalPolicyRetain detaches a removed data disk from the virtual machine and keeps the managed disk.
+	DataDiskRemovalPolicyRetain DataDiskRemovalPolicy = "Retain"
+	// DataDiskRemovalPolicyDelete detaches a removed data disk from the virtual machine and deletes the managed disk.
+	DataDiskRemovalPolicyDelete DataDiskRemovalPolicy = "Delete"
+)
+
+// StorageAccountTypePremiumV2LRS is the storage account type of Premium SSD v2 managed disks.
+const StorageAccountTypePremiumV2LRS = "PremiumV2_LRS"
+
 // ManagedDiskParameters defines the parameters of a managed disk.
 type ManagedDiskParameters struct {
 	// +optional
@@ -499,8 +597,26 @@ type DiffDiskSettings struct {
 	// See https://docs.microsoft.com/en-us/azure/virtual-machines/ephemeral-os-disks for full details
 	// +kubebuilder:validation:Enum=Local
 	Option string `json:"option"`
+
+	// Placement specifies where the ephemeral OS disk is placed on the VM: the cache disk or the resource (temp)
+	// disk. The VM size must have enough space on the chosen disk for the OS disk.
+	// If not specified, Azure places the OS disk on the cache disk.
+	// See https://docs.microsoft.com/en-us/azure/virtual-machines/ephemeral-os-disks#placement-options-for-ephemeral-os-disks
+	// +optional
+	Placement *DiffDiskPlacement `json:"placement,omitempty"`
 }
 
+// DiffDiskPlacement defines where an ephemeral OS disk is placed on the VM.
+// +kubebuilder:validation:Enum=CacheDisk;ResourceDisk
+type DiffDiskPlacement string
+
+const (
+	// DiffDiskPlacementCacheDisk places the ephemeral OS disk on the cache disk of the VM.
+	DiffDiskPlacementCacheDisk DiffDiskPlacement = "CacheDisk"
+	// DiffDiskPlacementResourceDisk places the ephemeral OS disk on the resource (temp) disk of the VM.
+	DiffDiskPlacementResourceDisk DiffDiskPlacement = "ResourceDisk"
+)
+
 // SubnetRole defines the unique role of a subnet.
 type SubnetRole string
 
@@ -583,6 +699,94 @@ type SecurityProfile struct {
 	EncryptionAtHost *bool `json:"encryptionAtHost,omitempty"`
 }
 
+// Diagnostics specifies the diagnostic settings for a virtual machine or virtual machine scale set.
+type Diagnostics struct {
+	// Boot configures the boot diagnostics of the virtual machine, which capture its serial console log and a
+	// screenshot of its console. If omitted, boot diagnostics are stored in a storage account managed by Azure.
+	// +optional
+	Boot *BootDiagnostics `json:"boot,omitempty"`
+}
+
+// BootDiagnostics specifies the boot diagnostics settings for a virtual machine or virtual machine scale set.
+type BootDiagnostics struct {
+	// StorageAccountType determines where the boot diagnostics data is stored: in a storage account managed
+	// by Azure (Managed), in a storage account provided by the user (UserManaged), or not at all (Disabled).
+	StorageAccountType BootDiagnosticsStorageAccountType `json:"storageAccountType"`
+
+	// UserManaged references the storage account to store the boot diagnostics data in when StorageAccountType
+	// is UserManaged.
+	// +optional
+	UserManaged *UserManagedBootDiagnostics `json:"userManaged,omitempty"`
+}
+
+// BootDiagnosticsStorageAccountType defines where the boot diagnostics data is stored.
+// +kubebuilder:validation:Enum=Managed;UserManaged;Disabled
+type BootDiagnosticsStorageAccountType string
+
+const (
+	// ManagedDiagnosticsStorage stores the boot diagnostics data in a storage account managed by Azure.
+	ManagedDiagnosticsStorage BootDiagnosticsStorageAccountType = "Managed"
+	// UserManagedDiagnosticsStorage stores the boot diagnostics data in a storage account provided by the user.
+	UserManagedDiagnosticsStorage BootDiagnosticsStorageAccountType = "UserManaged"
+	// DisabledDiagnosticsStorage disables boot diagnostics.
+	DisabledDiagnosticsStorage BootDiagnosticsStorageAccountType = "Disabled"
+)
+
+// UserManagedBootDiagnostics references a storage account provided by the user to store boot diagnostics data in.
+type UserManagedBootDiagnostics struct {
+	// StorageAccountURI is the blob endpoint of the storage account, e.g. https://mystorageaccount.blob.core.windows.net/.
+	// +kubebuilder:validation:MaxLength=1024
+	StorageAccountURI string `json:"storageAccountURI"`
+}
+
+// BootstrapDataDelivery defines how the bootstrap data is passed to a virtual machine.
+// +kubebuilder:validation:Enum=CustomData;UserData;Blob
+type BootstrapDataDelivery string
+
+const (
+	// BootstrapDataDeliveryCustomData passes the bootstrap data as custom data, which is only made available to the
+	// virtual machine while it is provisioned.
+	BootstrapDataDeliveryCustomData BootstrapDataDelivery = "CustomData"
+	// BootstrapDataDeliveryUserData passes the bootstrap data as user data, which remains available from the Azure
+	// Instance Metadata Service for the lifetime of the virtual machine.
+	BootstrapDataDeliveryUserData BootstrapDataDelivery = "UserData"
+	// BootstrapDataDeliveryBlob uploads the bootstrap data to blob storage and passes a small loader that downloads
+	// it as custom data, which lifts the size limit of custom data.
+	BootstrapDataDeliveryBlob BootstrapDataDelivery = "Blob"
+)
+
+// BootstrapDataBlobAccess defines how a virtual machine authenticates to download its bootstrap data from blob storage.
+// +kubebuilder:validation:Enum=SAS;ManagedIdentity
+type BootstrapDataBlobAccess string
+
+const (
+	// BootstrapDataBlobAccessSAS downloads the bootstrap data with a read-only shared access signature that expires
+	// a few hours after the virtual machine is created.
+	BootstrapDataBlobAccessSAS BootstrapDataBlobAccess = "SAS"
+	// BootstrapDataBlobAccessManagedIdentity downloads the bootstrap data with a token of the first user-assigned
+	// identity of the virtual machine, which must be granted read access to the blob container.
+	BootstrapDataBlobAccessManagedIdentity BootstrapDataBlobAccess = "ManagedIdentity"
+)
+
+// BootstrapDataStorage defines the blob storage the bootstrap data of a virtual machine is uploaded to when it is
+// delivered from blob storage.
+type BootstrapDataStorage struct {
+	// StorageAccountID is the resource ID of an existing storage account to upload the bootstrap data to.
+	// If not specified, the bootstrap data is uploaded to a storage account owned by the cluster, which is created in
+	// the resource group of the cluster and deleted with it.
+	// +optional
+	StorageAccountID *string `json:"storageAccountID,omitempty"`
+
+	// ContainerName is the name of the blob container the bootstrap data is uploaded to. The container is created if
+	// it does not exist. Defaults to bootstrap-data.
+	// +optional
+	ContainerName string `json:"containerName,omitempty"`
+
+	// Access specifies how the virtual machine authenticates to download the bootstrap data. Defaults to SAS.
+	// +optional
+	Access BootstrapDataBlobAccess `json:"access,omitempty"`
+}
+
 // AddressRecord specifies a DNS record mapping a hostname to an IPV4 or IPv6 address.
 type AddressRecord struct {
 	Hostname string
@@ -688,6 +892,85 @@ type AzureBastion struct {
 	PublicIP PublicIPSpec `json:"publicIP,omitempty"`
 }
 
+// CapacityReservationGroup defines an on-demand capacity reservation group and the capacity reservations it contains.
+type CapacityReservationGroup struct {
+	// Name is the name of the capacity reservation group.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Zones are the availability zones the capacity reservation group can reserve capacity in.
+	// If empty, the group is regional and its reservations must not specify a zone.
+	// +optional
+	Zones []string `json:"zones,omitempty"`
+
+	// Reservations are the capacity reservations of the group, one per VM size and zone.
+	// +optional
+	Reservations []CapacityReservation `json:"reservations,omitempty"`
+}
+
+// CapacityReservation defines an amount of capacity reserved for a VM size in a zone.
+type CapacityReservation struct {
+	// Name is the name of the capacity reservation.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// VMSize is the size of the Virtual Machines the capacity is reserved for.
+	VMSize string `json:"vmSize"`
+
+	// Zone is the availability zone the capacity is reserved in. It must be one of the zones of the
+	// capacity reservation group.
+	// +optional
+	Zone string `json:"zone,omitempty"`
+
+	// Capacity is the number of Virtual Machines to reserve capacity for.
+	// +kubebuilder:validation:Minimum=0
+	Capacity int64 `json:"capacity"`
+}
+
+// CapacityReservationGroupStatus reports the observed state of a capacity reservation group.
+type CapacityReservationGroupStatus struct {
+	// Name is the name of the capacity reservation group.
+	Name string `json:"name"`
+
+	// ID is the Azure resource ID of the capacity reservation group.
+	// +optional
+	ID string `json:"id,omitempty"`
+
+	// Reservations reports the reserved and used capacity of each capacity reservation in the group.
+	// +optional
+	Reservations []CapacityReservationStatus `json:"reservations,omitempty"`
+}
+
+// CapacityReservationStatus reports the reserved and used capacity of a capacity reservation.
+type CapacityReservationStatus struct {
+	// Name is the name of the capacity reservation.
+	Name string `json:"name"`
+
+	// VMSize is the size of the Virtual Machines the capacity is reserved for.
+	// +optional
+	VMSize string `json:"vmSize,omitempty"`
+
+	// Zone is the availability zone the capacity is reserved in.
+	// +optional
+	Zone string `json:"zone,omitempty"`
+
+	// Reserved is the number of Virtual Machines capacity is reserved for.
+	Reserved int64 `json:"reserved"`
+
+	// Used is the number of Virtual Machines currently allocated against the reservation.
+	Used int64 `json:"used"`
+}
+
+// OrchestrationModeType is the orchestration mode of a Virtual Machine Scale Set.
+type OrchestrationModeType string
+
+const (
+	// FlexibleOrchestrationMode manages the instances of the scale set as standard virtual machines.
+	FlexibleOrchestrationMode OrchestrationModeType = "Flexible"
+	// UniformOrchestrationMode manages the instances of the scale set as identical scale set VMs.
+	UniformOrchestrationMode OrchestrationModeType = "Uniform"
+)
+
 // IsTerminalProvisioningState returns true if the ProvisioningState is a terminal state for an Azure resource.
 func IsTerminalProvisioningState(state ProvisioningState) bool {
 	return state == Failed || state == Succeeded
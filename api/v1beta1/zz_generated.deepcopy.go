@@ -302,6 +302,13 @@ func (in *AzureClusterSpec) DeepCopyInto(out *AzureClusterSpec) {
 	in.NetworkSpec.DeepCopyInto(&out.NetworkSpec)
 	in.BastionSpec.DeepCopyInto(&out.BastionSpec)
 	out.ControlPlaneEndpoint = in.ControlPlaneEndpoint
+	if in.CapacityReservationGroups != nil {
+		in, out := &in.CapacityReservationGroups, &out.CapacityReservationGroups
+		*out = make([]CapacityReservationGroup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureClusterSpec.
@@ -336,6 +343,13 @@ func (in *AzureClusterStatus) DeepCopyInto(out *AzureClusterStatus) {
 		*out = make(Futures, len(*in))
 		copy(*out, *in)
 	}
+	if in.CapacityReservationGroups != nil {
+		in, out := &in.CapacityReservationGroups, &out.CapacityReservationGroups
+		*out = make([]CapacityReservationGroupStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureClusterStatus.
@@ -456,6 +470,36 @@ func (in *AzureClusterTemplateSpec) DeepCopy() *AzureClusterTemplateSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AzureCommunityGalleryImage) DeepCopyInto(out *AzureCommunityGalleryImage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureCommunityGalleryImage.
+func (in *AzureCommunityGalleryImage) DeepCopy() *AzureCommunityGalleryImage {
+	if in == nil {
+		return nil
+	}
+	out := new(AzureCommunityGalleryImage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AzureDirectSharedGalleryImage) DeepCopyInto(out *AzureDirectSharedGalleryImage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureDirectSharedGalleryImage.
+func (in *AzureDirectSharedGalleryImage) DeepCopy() *AzureDirectSharedGalleryImage {
+	if in == nil {
+		return nil
+	}
+	out := new(AzureDirectSharedGalleryImage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AzureMachine) DeepCopyInto(out *AzureMachine) {
 	*out = *in
@@ -546,6 +590,11 @@ func (in *AzureMachineSpec) DeepCopyInto(out *AzureMachineSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.EnableUltraSSD != nil {
+		in, out := &in.EnableUltraSSD, &out.EnableUltraSSD
+		*out = new(bool)
+		**out = **in
+	}
 	if in.AdditionalTags != nil {
 		in, out := &in.AdditionalTags, &out.AdditionalTags
 		*out = make(Tags, len(*in))
@@ -568,6 +617,21 @@ func (in *AzureMachineSpec) DeepCopyInto(out *AzureMachineSpec) {
 		*out = new(SecurityProfile)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Diagnostics != nil {
+		in, out := &in.Diagnostics, &out.Diagnostics
+		*out = new(Diagnostics)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BootstrapDataStorage != nil {
+		in, out := &in.BootstrapDataStorage, &out.BootstrapDataStorage
+		*out = new(BootstrapDataStorage)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CapacityReservationGroupID != nil {
+		in, out := &in.CapacityReservationGroupID, &out.CapacityReservationGroupID
+		*out = new(string)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureMachineSpec.
@@ -593,6 +657,16 @@ func (in *AzureMachineStatus) DeepCopyInto(out *AzureMachineStatus) {
 		*out = new(ProvisioningState)
 		**out = **in
 	}
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(Image)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RemovedDataDisks != nil {
+		in, out := &in.RemovedDataDisks, &out.RemovedDataDisks
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.FailureReason != nil {
 		in, out := &in.FailureReason, &out.FailureReason
 		*out = new(errors.MachineStatusError)
@@ -633,6 +707,7 @@ func (in *AzureMachineTemplate) DeepCopyInto(out *AzureMachineTemplate) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureMachineTemplate.
@@ -718,6 +793,28 @@ func (in *AzureMachineTemplateSpec) DeepCopy() *AzureMachineTemplateSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AzureMachineTemplateStatus) DeepCopyInto(out *AzureMachineTemplateStatus) {
+	*out = *in
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureMachineTemplateStatus.
+func (in *AzureMachineTemplateStatus) DeepCopy() *AzureMachineTemplateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AzureMachineTemplateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AzureMarketplaceImage) DeepCopyInto(out *AzureMarketplaceImage) {
 	*out = *in
@@ -828,6 +925,46 @@ func (in *BastionTemplateSpec) DeepCopy() *BastionTemplateSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BootDiagnostics) DeepCopyInto(out *BootDiagnostics) {
+	*out = *in
+	if in.UserManaged != nil {
+		in, out := &in.UserManaged, &out.UserManaged
+		*out = new(UserManagedBootDiagnostics)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BootDiagnostics.
+func (in *BootDiagnostics) DeepCopy() *BootDiagnostics {
+	if in == nil {
+		return nil
+	}
+	out := new(BootDiagnostics)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BootstrapDataStorage) DeepCopyInto(out *BootstrapDataStorage) {
+	*out = *in
+	if in.StorageAccountID != nil {
+		in, out := &in.StorageAccountID, &out.StorageAccountID
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BootstrapDataStorage.
+func (in *BootstrapDataStorage) DeepCopy() *BootstrapDataStorage {
+	if in == nil {
+		return nil
+	}
+	out := new(BootstrapDataStorage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BuildParams) DeepCopyInto(out *BuildParams) {
 	*out = *in
@@ -860,6 +997,81 @@ func (in *BuildParams) DeepCopy() *BuildParams {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapacityReservation) DeepCopyInto(out *CapacityReservation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityReservation.
+func (in *CapacityReservation) DeepCopy() *CapacityReservation {
+	if in == nil {
+		return nil
+	}
+	out := new(CapacityReservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapacityReservationGroup) DeepCopyInto(out *CapacityReservationGroup) {
+	*out = *in
+	if in.Zones != nil {
+		in, out := &in.Zones, &out.Zones
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Reservations != nil {
+		in, out := &in.Reservations, &out.Reservations
+		*out = make([]CapacityReservation, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityReservationGroup.
+func (in *CapacityReservationGroup) DeepCopy() *CapacityReservationGroup {
+	if in == nil {
+		return nil
+	}
+	out := new(CapacityReservationGroup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapacityReservationGroupStatus) DeepCopyInto(out *CapacityReservationGroupStatus) {
+	*out = *in
+	if in.Reservations != nil {
+		in, out := &in.Reservations, &out.Reservations
+		*out = make([]CapacityReservationStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityReservationGroupStatus.
+func (in *CapacityReservationGroupStatus) DeepCopy() *CapacityReservationGroupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CapacityReservationGroupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapacityReservationStatus) DeepCopyInto(out *CapacityReservationStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityReservationStatus.
+func (in *CapacityReservationStatus) DeepCopy() *CapacityReservationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CapacityReservationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CloudProviderConfigOverrides) DeepCopyInto(out *CloudProviderConfigOverrides) {
 	*out = *in
@@ -886,6 +1098,26 @@ func (in *CloudProviderConfigOverrides) DeepCopy() *CloudProviderConfigOverrides
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DataDisk) DeepCopyInto(out *DataDisk) {
 	*out = *in
+	if in.SourceDiskID != nil {
+		in, out := &in.SourceDiskID, &out.SourceDiskID
+		*out = new(string)
+		**out = **in
+	}
+	if in.DiskIOPSReadWrite != nil {
+		in, out := &in.DiskIOPSReadWrite, &out.DiskIOPSReadWrite
+		*out = new(int64)
+		**out = **in
+	}
+	if in.DiskMBpsReadWrite != nil {
+		in, out := &in.DiskMBpsReadWrite, &out.DiskMBpsReadWrite
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MaxShares != nil {
+		in, out := &in.MaxShares, &out.MaxShares
+		*out = new(int32)
+		**out = **in
+	}
 	if in.ManagedDisk != nil {
 		in, out := &in.ManagedDisk, &out.ManagedDisk
 		*out = new(ManagedDiskParameters)
@@ -908,9 +1140,34 @@ func (in *DataDisk) DeepCopy() *DataDisk {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Diagnostics) DeepCopyInto(out *Diagnostics) {
+	*out = *in
+	if in.Boot != nil {
+		in, out := &in.Boot, &out.Boot
+		*out = new(BootDiagnostics)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Diagnostics.
+func (in *Diagnostics) DeepCopy() *Diagnostics {
+	if in == nil {
+		return nil
+	}
+	out := new(Diagnostics)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DiffDiskSettings) DeepCopyInto(out *DiffDiskSettings) {
 	*out = *in
+	if in.Placement != nil {
+		in, out := &in.Placement, &out.Placement
+		*out = new(DiffDiskPlacement)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiffDiskSettings.
@@ -1026,6 +1283,16 @@ func (in *Image) DeepCopyInto(out *Image) {
 		*out = new(AzureMarketplaceImage)
 		**out = **in
 	}
+	if in.CommunityGallery != nil {
+		in, out := &in.CommunityGallery, &out.CommunityGallery
+		*out = new(AzureCommunityGalleryImage)
+		**out = **in
+	}
+	if in.DirectSharedGallery != nil {
+		in, out := &in.DirectSharedGallery, &out.DirectSharedGallery
+		*out = new(AzureDirectSharedGalleryImage)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Image.
@@ -1239,7 +1506,7 @@ func (in *OSDisk) DeepCopyInto(out *OSDisk) {
 	if in.DiffDiskSettings != nil {
 		in, out := &in.DiffDiskSettings, &out.DiffDiskSettings
 		*out = new(DiffDiskSettings)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -1600,6 +1867,21 @@ func (in *UserAssignedIdentity) DeepCopy() *UserAssignedIdentity {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserManagedBootDiagnostics) DeepCopyInto(out *UserManagedBootDiagnostics) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserManagedBootDiagnostics.
+func (in *UserManagedBootDiagnostics) DeepCopy() *UserManagedBootDiagnostics {
+	if in == nil {
+		return nil
+	}
+	out := new(UserManagedBootDiagnostics)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VnetClassSpec) DeepCopyInto(out *VnetClassSpec) {
 	*out = *in
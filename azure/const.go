@@ -28,4 +28,76 @@ const (
 	// See https://kubernetes.io/docs/concepts/overview/working-with-objects/annotations/
 	// for annotation formatting rules.
 	RGTagsLastAppliedAnnotation = "sigs.k8s.io/cluster-api-provider-azure-last-applied-tags-rg"
+
+	// VMActionAnnotation is the key for the AzureMachine and AzureMachinePoolMachine annotation which requests a
+	// one-shot action on the backing Azure VM. The annotation is removed once the action has completed or failed.
+	VMActionAnnotation = "azure.cluster.x-k8s.io/vm-action"
+
+	// HibernatedReplicasAnnotation is the key for the AzureMachinePool annotation which remembers the capacity of the
+	// scale set before it was scaled to zero to hibernate the cluster, so that it can be restored when the cluster is
+	// resumed.
+	HibernatedReplicasAnnotation = "azure.cluster.x-k8s.io/hibernated-replicas"
+
+	// InstanceProtectionAnnotation is the key for the AzureMachinePoolMachine annotation which protects the instance
+	// from the scale-in of the machine pool, and from the scale set actions with the InstanceProtectionScaleSetActions
+	// value.
+	InstanceProtectionAnnotation = "azure.cluster.x-k8s.io/instance-protection"
+)
+
+const (
+	// InstanceProtectionScaleIn protects the instance from being deleted when the machine pool is scaled in.
+	InstanceProtectionScaleIn = "scale-in"
+	// InstanceProtectionScaleSetActions protects the instance from being deleted when the machine pool is scaled in,
+	// and from the actions of the scale set such as upgrades to the latest model.
+	InstanceProtectionScaleSetActions = "scale-set-actions"
+)
+
+const (
+	// VMEventScheduledNodeCondition is the type of the node condition reported by the node-problem-detector while a
+	// scheduled event is pending for the VM of the node. The reason or message of the condition names the type of the
+	// pending events.
+	VMEventScheduledNodeCondition = "VMEventScheduled"
+	// TerminateScheduledEvent is the type of the scheduled event notifying that the VM is about to be deleted.
+	TerminateScheduledEvent = "Terminate"
+	// PreemptScheduledEvent is the type of the scheduled event notifying that the Spot VM is about to be evicted.
+	PreemptScheduledEvent = "Preempt"
 )
+
+const (
+	// AutoscalerCPUCapacityAnnotation is the key for the AzureMachineTemplate and AzureMachinePool annotation which
+	// tells the cluster-autoscaler the number of CPUs of a node when scaling a node group from zero.
+	AutoscalerCPUCapacityAnnotation = "capacity.cluster-autoscaler.kubernetes.io/cpu"
+	// AutoscalerMemoryCapacityAnnotation is the key for the annotation which tells the cluster-autoscaler the memory
+	// of a node when scaling a node group from zero.
+	AutoscalerMemoryCapacityAnnotation = "capacity.cluster-autoscaler.kubernetes.io/memory"
+	// AutoscalerGPUCountCapacityAnnotation is the key for the annotation which tells the cluster-autoscaler the number
+	// of GPUs of a node when scaling a node group from zero.
+	AutoscalerGPUCountCapacityAnnotation = "capacity.cluster-autoscaler.kubernetes.io/gpu-count"
+	// AutoscalerGPUTypeCapacityAnnotation is the key for the annotation which tells the cluster-autoscaler the
+	// resource name of the GPUs of a node when scaling a node group from zero.
+	AutoscalerGPUTypeCapacityAnnotation = "capacity.cluster-autoscaler.kubernetes.io/gpu-type"
+	// AutoscalerMaxPodsCapacityAnnotation is the key for the annotation which tells the cluster-autoscaler the
+	// maximum number of pods of a node when scaling a node group from zero. It is only set when missing, so that it
+	// can be changed to match the kubelet configuration of the nodes.
+	AutoscalerMaxPodsCapacityAnnotation = "capacity.cluster-autoscaler.kubernetes.io/maxPods"
+
+	// DefaultMaxPods is the default maximum number of pods of a kubelet.
+	DefaultMaxPods = 110
+)
+
+const (
+	// VMActionRestart restarts the VM.
+	VMActionRestart = "restart"
+	// VMActionRedeploy moves the VM to a new host and powers it on again.
+	VMActionRedeploy = "redeploy"
+	// VMActionReimage restores the OS disk of the VM to its initial state. It is only supported for VMs with an
+	// ephemeral OS disk.
+	VMActionReimage = "reimage"
+	// VMActionDeallocate shuts down the VM and releases its compute resources.
+	VMActionDeallocate = "deallocate"
+	// VMActionStart starts a stopped or deallocated VM.
+	VMActionStart = "start"
+)
+
+// VMActions are the actions that can be requested with the VMActionAnnotation.
+var VMActions = []string{VMActionRestart, VMActionRedeploy, VMActionReimage, VMActionDeallocate, VMActionStart}
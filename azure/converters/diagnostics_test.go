@@ -0,0 +1,69 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package converters
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/gomega"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+)
+
+func TestGetDiagnosticsProfile(t *testing.T) {
+	cases := []struct {
+		name        string
+		diagnostics *infrav1.Diagnostics
+		want        *compute.BootDiagnostics
+	}{
+		{
+			name:        "enables managed boot diagnostics by default",
+			diagnostics: nil,
+			want:        &compute.BootDiagnostics{Enabled: to.BoolPtr(true)},
+		},
+		{
+			name:        "enables managed boot diagnostics",
+			diagnostics: &infrav1.Diagnostics{Boot: &infrav1.BootDiagnostics{StorageAccountType: infrav1.ManagedDiagnosticsStorage}},
+			want:        &compute.BootDiagnostics{Enabled: to.BoolPtr(true)},
+		},
+		{
+			name:        "disables boot diagnostics",
+			diagnostics: &infrav1.Diagnostics{Boot: &infrav1.BootDiagnostics{StorageAccountType: infrav1.DisabledDiagnosticsStorage}},
+			want:        &compute.BootDiagnostics{Enabled: to.BoolPtr(false)},
+		},
+		{
+			name: "enables user-managed boot diagnostics",
+			diagnostics: &infrav1.Diagnostics{Boot: &infrav1.BootDiagnostics{
+				StorageAccountType: infrav1.UserManagedDiagnosticsStorage,
+				UserManaged:        &infrav1.UserManagedBootDiagnostics{StorageAccountURI: "https://mystorageaccount.blob.core.windows.net/"},
+			}},
+			want: &compute.BootDiagnostics{
+				Enabled:    to.BoolPtr(true),
+				StorageURI: to.StringPtr("https://mystorageaccount.blob.core.windows.net/"),
+			},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(GetDiagnosticsProfile(c.diagnostics)).To(Equal(&compute.DiagnosticsProfile{BootDiagnostics: c.want}))
+		})
+	}
+}
@@ -19,7 +19,7 @@ package converters
 import (
 	"strings"
 
-	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
 	"github.com/pkg/errors"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
@@ -54,14 +54,14 @@ func VMIdentityToVMSDK(identity infrav1.VMIdentity, uami []infrav1.UserAssignedI
 // UserAssignedIdentitiesToVMSDK converts CAPZ user assigned identities associated with the Virtual Machine to Azure SDK identities
 // The user identity dictionary key references will be ARM resource ids in the form:
 // '/subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}/providers/Microsoft.ManagedIdentity/userAssignedIdentities/{identityName}'.
-func UserAssignedIdentitiesToVMSDK(identities []infrav1.UserAssignedIdentity) (map[string]*compute.VirtualMachineIdentityUserAssignedIdentitiesValue, error) {
+func UserAssignedIdentitiesToVMSDK(identities []infrav1.UserAssignedIdentity) (map[string]*compute.UserAssignedIdentitiesValue, error) {
 	if len(identities) == 0 {
 		return nil, ErrUserAssignedIdentitiesNotFound
 	}
-	userIdentitiesMap := make(map[string]*compute.VirtualMachineIdentityUserAssignedIdentitiesValue, len(identities))
+	userIdentitiesMap := make(map[string]*compute.UserAssignedIdentitiesValue, len(identities))
 	for _, id := range identities {
 		key := sanitized(id.ProviderID)
-		userIdentitiesMap[key] = &compute.VirtualMachineIdentityUserAssignedIdentitiesValue{}
+		userIdentitiesMap[key] = &compute.UserAssignedIdentitiesValue{}
 	}
 
 	return userIdentitiesMap, nil
@@ -69,14 +69,14 @@ func UserAssignedIdentitiesToVMSDK(identities []infrav1.UserAssignedIdentity) (m
 
 // UserAssignedIdentitiesToVMSSSDK converts CAPZ user assigned identities associated with the Virtual Machine Scale Set to Azure SDK identities
 // Similar to UserAssignedIdentitiesToVMSDK.
-func UserAssignedIdentitiesToVMSSSDK(identities []infrav1.UserAssignedIdentity) (map[string]*compute.VirtualMachineScaleSetIdentityUserAssignedIdentitiesValue, error) {
+func UserAssignedIdentitiesToVMSSSDK(identities []infrav1.UserAssignedIdentity) (map[string]*compute.UserAssignedIdentitiesValue, error) {
 	if len(identities) == 0 {
 		return nil, ErrUserAssignedIdentitiesNotFound
 	}
-	userIdentitiesMap := make(map[string]*compute.VirtualMachineScaleSetIdentityUserAssignedIdentitiesValue, len(identities))
+	userIdentitiesMap := make(map[string]*compute.UserAssignedIdentitiesValue, len(identities))
 	for _, id := range identities {
 		key := sanitized(id.ProviderID)
-		userIdentitiesMap[key] = &compute.VirtualMachineScaleSetIdentityUserAssignedIdentitiesValue{}
+		userIdentitiesMap[key] = &compute.UserAssignedIdentitiesValue{}
 	}
 
 	return userIdentitiesMap, nil
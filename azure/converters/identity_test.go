@@ -19,7 +19,7 @@ package converters
 import (
 	"testing"
 
-	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
 	. "github.com/onsi/gomega"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 )
@@ -36,13 +36,13 @@ var sampleSubjectFactory = []infrav1.UserAssignedIdentity{
 	},
 }
 
-var expectedVMSDKObject = map[string]*compute.VirtualMachineIdentityUserAssignedIdentitiesValue{
+var expectedVMSDKObject = map[string]*compute.UserAssignedIdentitiesValue{
 	"/foo":            {},
 	"/bar":            {},
 	"/without/prefix": {},
 }
 
-var expectedVMSSSDKObject = map[string]*compute.VirtualMachineScaleSetIdentityUserAssignedIdentitiesValue{
+var expectedVMSSSDKObject = map[string]*compute.UserAssignedIdentitiesValue{
 	"/foo":            {},
 	"/bar":            {},
 	"/without/prefix": {},
@@ -73,7 +73,7 @@ func Test_VMIdentityToVMSDK(t *testing.T) {
 				g.Expect(err).Should(BeNil())
 				g.Expect(m).Should(Equal(&compute.VirtualMachineIdentity{
 					Type: compute.ResourceIdentityTypeUserAssigned,
-					UserAssignedIdentities: map[string]*compute.VirtualMachineIdentityUserAssignedIdentitiesValue{
+					UserAssignedIdentities: map[string]*compute.UserAssignedIdentitiesValue{
 						"my-uami-1": {},
 						"my-uami-2": {},
 					},
@@ -113,12 +113,12 @@ func Test_UserAssignedIdentitiesToVMSDK(t *testing.T) {
 	cases := []struct {
 		Name           string
 		SubjectFactory []infrav1.UserAssignedIdentity
-		Expect         func(*GomegaWithT, map[string]*compute.VirtualMachineIdentityUserAssignedIdentitiesValue, error)
+		Expect         func(*GomegaWithT, map[string]*compute.UserAssignedIdentitiesValue, error)
 	}{
 		{
 			Name:           "ShouldPopulateWithData",
 			SubjectFactory: sampleSubjectFactory,
-			Expect: func(g *GomegaWithT, m map[string]*compute.VirtualMachineIdentityUserAssignedIdentitiesValue, err error) {
+			Expect: func(g *GomegaWithT, m map[string]*compute.UserAssignedIdentitiesValue, err error) {
 				g.Expect(err).Should(BeNil())
 				g.Expect(m).Should(Equal(expectedVMSDKObject))
 			},
@@ -127,7 +127,7 @@ func Test_UserAssignedIdentitiesToVMSDK(t *testing.T) {
 		{
 			Name:           "ShouldFailWithError",
 			SubjectFactory: []infrav1.UserAssignedIdentity{},
-			Expect: func(g *GomegaWithT, m map[string]*compute.VirtualMachineIdentityUserAssignedIdentitiesValue, err error) {
+			Expect: func(g *GomegaWithT, m map[string]*compute.UserAssignedIdentitiesValue, err error) {
 				g.Expect(err).Should(Equal(ErrUserAssignedIdentitiesNotFound))
 			},
 		},
@@ -148,12 +148,12 @@ func Test_UserAssignedIdentitiesToVMSSSDK(t *testing.T) {
 	cases := []struct {
 		Name           string
 		SubjectFactory []infrav1.UserAssignedIdentity
-		Expect         func(*GomegaWithT, map[string]*compute.VirtualMachineScaleSetIdentityUserAssignedIdentitiesValue, error)
+		Expect         func(*GomegaWithT, map[string]*compute.UserAssignedIdentitiesValue, error)
 	}{
 		{
 			Name:           "ShouldPopulateWithData",
 			SubjectFactory: sampleSubjectFactory,
-			Expect: func(g *GomegaWithT, m map[string]*compute.VirtualMachineScaleSetIdentityUserAssignedIdentitiesValue, err error) {
+			Expect: func(g *GomegaWithT, m map[string]*compute.UserAssignedIdentitiesValue, err error) {
 				g.Expect(err).Should(BeNil())
 				g.Expect(m).Should(Equal(expectedVMSSSDKObject))
 			},
@@ -162,7 +162,7 @@ func Test_UserAssignedIdentitiesToVMSSSDK(t *testing.T) {
 		{
 			Name:           "ShouldFailWithError",
 			SubjectFactory: []infrav1.UserAssignedIdentity{},
-			Expect: func(g *GomegaWithT, m map[string]*compute.VirtualMachineScaleSetIdentityUserAssignedIdentitiesValue, err error) {
+			Expect: func(g *GomegaWithT, m map[string]*compute.UserAssignedIdentitiesValue, err error) {
 				g.Expect(err).Should(Equal(ErrUserAssignedIdentitiesNotFound))
 			},
 		},
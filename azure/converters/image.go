@@ -19,7 +19,7 @@ package converters
 import (
 	"fmt"
 
-	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/pkg/errors"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
@@ -36,6 +36,12 @@ func ImageToSDK(image *infrav1.Image) (*compute.ImageReference, error) {
 	if image.SharedGallery != nil {
 		return sigImageToSDK(image)
 	}
+	if image.CommunityGallery != nil {
+		return communityGalleryImageToSDK(image)
+	}
+	if image.DirectSharedGallery != nil {
+		return directSharedGalleryImageToSDK(image)
+	}
 
 	return nil, errors.New("unable to convert image as no options set")
 }
@@ -62,6 +68,28 @@ func sigImageToSDK(image *infrav1.Image) (*compute.ImageReference, error) {
 	}, nil
 }
 
+func communityGalleryImageToSDK(image *infrav1.Image) (*compute.ImageReference, error) {
+	imageID := fmt.Sprintf("/CommunityGalleries/%s/Images/%s/Versions/%s",
+		image.CommunityGallery.Gallery,
+		image.CommunityGallery.Name,
+		image.CommunityGallery.Version)
+
+	return &compute.ImageReference{
+		CommunityGalleryImageID: &imageID,
+	}, nil
+}
+
+func directSharedGalleryImageToSDK(image *infrav1.Image) (*compute.ImageReference, error) {
+	imageID := fmt.Sprintf("/SharedGalleries/%s/Images/%s/Versions/%s",
+		image.DirectSharedGallery.Gallery,
+		image.DirectSharedGallery.Name,
+		image.DirectSharedGallery.Version)
+
+	return &compute.ImageReference{
+		SharedGalleryImageID: &imageID,
+	}, nil
+}
+
 func specificImageToSDK(image *infrav1.Image) (*compute.ImageReference, error) {
 	return &compute.ImageReference{
 		ID: image.ID,
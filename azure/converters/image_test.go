@@ -19,7 +19,7 @@ package converters
 import (
 	"testing"
 
-	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
 	"github.com/Azure/go-autorest/autorest/to"
 	. "github.com/onsi/gomega"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
@@ -123,3 +123,79 @@ func Test_ImageToPlan(t *testing.T) {
 		})
 	}
 }
+
+func Test_ImageToSDK(t *testing.T) {
+	cases := []struct {
+		name   string
+		image  *infrav1.Image
+		expect func(*GomegaWithT, *compute.ImageReference, error)
+	}{
+		{
+			name: "Should return a community gallery image ID for a community gallery image",
+			image: &infrav1.Image{
+				CommunityGallery: &infrav1.AzureCommunityGalleryImage{
+					Gallery: "fake-gallery-1234",
+					Name:    "fake-image-name",
+					Version: "1.0.0",
+				},
+			},
+			expect: func(g *GomegaWithT, result *compute.ImageReference, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(result).To(Equal(&compute.ImageReference{
+					CommunityGalleryImageID: to.StringPtr("/CommunityGalleries/fake-gallery-1234/Images/fake-image-name/Versions/1.0.0"),
+				}))
+			},
+		},
+		{
+			name: "Should return a shared gallery image ID for a direct shared gallery image",
+			image: &infrav1.Image{
+				DirectSharedGallery: &infrav1.AzureDirectSharedGalleryImage{
+					Gallery: "fake-gallery-1234",
+					Name:    "fake-image-name",
+					Version: "latest",
+				},
+			},
+			expect: func(g *GomegaWithT, result *compute.ImageReference, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(result).To(Equal(&compute.ImageReference{
+					SharedGalleryImageID: to.StringPtr("/SharedGalleries/fake-gallery-1234/Images/fake-image-name/Versions/latest"),
+				}))
+			},
+		},
+		{
+			name: "Should return an image ID for a SIG image",
+			image: &infrav1.Image{
+				SharedGallery: &infrav1.AzureSharedGalleryImage{
+					SubscriptionID: "fake-sub-id",
+					ResourceGroup:  "fake-rg",
+					Gallery:        "fake-gallery-name",
+					Name:           "fake-image-name",
+					Version:        "v1.0.0",
+				},
+			},
+			expect: func(g *GomegaWithT, result *compute.ImageReference, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(result).To(Equal(&compute.ImageReference{
+					ID: to.StringPtr("/subscriptions/fake-sub-id/resourceGroups/fake-rg/providers/Microsoft.Compute/galleries/fake-gallery-name/images/fake-image-name/versions/v1.0.0"),
+				}))
+			},
+		},
+		{
+			name:  "Should return an error for an image without options",
+			image: &infrav1.Image{},
+			expect: func(g *GomegaWithT, result *compute.ImageReference, err error) {
+				g.Expect(err).To(HaveOccurred())
+			},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			g := NewGomegaWithT(t)
+			result, err := ImageToSDK(c.image)
+			c.expect(g, result, err)
+		})
+	}
+}
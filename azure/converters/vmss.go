@@ -17,7 +17,9 @@ limitations under the License.
 package converters
 
 import (
-	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
 	"github.com/Azure/go-autorest/autorest/to"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
@@ -45,9 +47,19 @@ func SDKToVMSS(sdkvmss compute.VirtualMachineScaleSet, sdkinstances []compute.Vi
 	}
 
 	if len(sdkinstances) > 0 {
+		// the instances of a Uniform scale set all have the priority of its model
+		priority := compute.Regular
+		if sdkvmss.VirtualMachineScaleSetProperties != nil && sdkvmss.VirtualMachineProfile != nil && sdkvmss.VirtualMachineProfile.Priority != "" {
+			priority = sdkvmss.VirtualMachineProfile.Priority
+		}
+
 		vmss.Instances = make([]azure.VMSSVM, len(sdkinstances))
 		for i, vm := range sdkinstances {
 			vmss.Instances[i] = *SDKToVMSSVM(vm)
+			vmss.Instances[i].Priority = string(priority)
+			if vm.VirtualMachineScaleSetVMProperties != nil && vm.InstanceView != nil {
+				vmss.Instances[i].Evicted = isEvicted(priority, vm.InstanceView.Statuses)
+			}
 		}
 	}
 
@@ -58,9 +70,135 @@ func SDKToVMSS(sdkvmss compute.VirtualMachineScaleSet, sdkinstances []compute.Vi
 		vmss.Image = SDKImageToImage(imageRef, sdkvmss.Plan != nil)
 	}
 
+	if sdkvmss.VirtualMachineProfile != nil && sdkvmss.VirtualMachineProfile.StorageProfile != nil {
+		vmss.OSDisk, vmss.DataDisks = sdkToModelDisks(*sdkvmss.VirtualMachineProfile.StorageProfile)
+	}
+
+	if sdkvmss.VirtualMachineProfile != nil &&
+		sdkvmss.VirtualMachineProfile.ExtensionProfile != nil &&
+		sdkvmss.VirtualMachineProfile.ExtensionProfile.Extensions != nil {
+		for _, extension := range *sdkvmss.VirtualMachineProfile.ExtensionProfile.Extensions {
+			vmss.Extensions = append(vmss.Extensions, to.String(extension.Name))
+		}
+	}
+
+	if sdkvmss.VirtualMachineScaleSetProperties != nil &&
+		sdkvmss.AutomaticRepairsPolicy != nil &&
+		to.Bool(sdkvmss.AutomaticRepairsPolicy.Enabled) {
+		vmss.AutomaticRepairs = &azure.AutomaticRepairsSpec{
+			GracePeriod:  to.String(sdkvmss.AutomaticRepairsPolicy.GracePeriod),
+			RepairAction: string(sdkvmss.AutomaticRepairsPolicy.RepairAction),
+		}
+	}
+
+	if sdkvmss.VirtualMachineScaleSetProperties != nil && sdkvmss.UpgradePolicy != nil {
+		vmss.UpgradePolicy = SDKToUpgradePolicy(*sdkvmss.UpgradePolicy)
+	}
+
 	return vmss
 }
 
+// sdkToModelDisks converts the disks of the storage profile of an Azure SDK VirtualMachineScaleSet model into
+// azure.VMSSDisks.
+func sdkToModelDisks(storageProfile compute.VirtualMachineScaleSetStorageProfile) (azure.VMSSDisk, []azure.VMSSDisk) {
+	var osDisk azure.VMSSDisk
+	if sdkDisk := storageProfile.OsDisk; sdkDisk != nil {
+		osDisk = azure.VMSSDisk{
+			Name:       to.String(sdkDisk.Name),
+			DiskSizeGB: to.Int32(sdkDisk.DiskSizeGB),
+			Ephemeral:  sdkDisk.DiffDiskSettings != nil,
+		}
+		if sdkDisk.ManagedDisk != nil {
+			osDisk.StorageAccountType = string(sdkDisk.ManagedDisk.StorageAccountType)
+			osDisk.DiskEncryptionSetID = sdkToDiskEncryptionSetID(sdkDisk.ManagedDisk.DiskEncryptionSet)
+		}
+	}
+
+	var dataDisks []azure.VMSSDisk
+	if storageProfile.DataDisks != nil {
+		for _, sdkDisk := range *storageProfile.DataDisks {
+			disk := azure.VMSSDisk{
+				Name:       to.String(sdkDisk.Name),
+				Lun:        sdkDisk.Lun,
+				DiskSizeGB: to.Int32(sdkDisk.DiskSizeGB),
+			}
+			if sdkDisk.ManagedDisk != nil {
+				disk.StorageAccountType = string(sdkDisk.ManagedDisk.StorageAccountType)
+				disk.DiskEncryptionSetID = sdkToDiskEncryptionSetID(sdkDisk.ManagedDisk.DiskEncryptionSet)
+			}
+			dataDisks = append(dataDisks, disk)
+		}
+	}
+
+	return osDisk, dataDisks
+}
+
+// sdkToInstanceDisks converts the disks of the storage profile of an Azure SDK VirtualMachineScaleSetVM or
+// VirtualMachine into azure.VMSSDisks.
+func sdkToInstanceDisks(storageProfile compute.StorageProfile) (azure.VMSSDisk, []azure.VMSSDisk) {
+	var osDisk azure.VMSSDisk
+	if sdkDisk := storageProfile.OsDisk; sdkDisk != nil {
+		osDisk = azure.VMSSDisk{
+			Name:       to.String(sdkDisk.Name),
+			DiskSizeGB: to.Int32(sdkDisk.DiskSizeGB),
+			Ephemeral:  sdkDisk.DiffDiskSettings != nil,
+		}
+		if sdkDisk.ManagedDisk != nil {
+			osDisk.StorageAccountType = string(sdkDisk.ManagedDisk.StorageAccountType)
+			osDisk.DiskEncryptionSetID = sdkToDiskEncryptionSetID(sdkDisk.ManagedDisk.DiskEncryptionSet)
+		}
+	}
+
+	var dataDisks []azure.VMSSDisk
+	if storageProfile.DataDisks != nil {
+		for _, sdkDisk := range *storageProfile.DataDisks {
+			disk := azure.VMSSDisk{
+				Name:       to.String(sdkDisk.Name),
+				Lun:        sdkDisk.Lun,
+				DiskSizeGB: to.Int32(sdkDisk.DiskSizeGB),
+				Attached:   sdkDisk.CreateOption == compute.DiskCreateOptionTypesAttach,
+			}
+			if sdkDisk.ManagedDisk != nil {
+				disk.StorageAccountType = string(sdkDisk.ManagedDisk.StorageAccountType)
+				disk.DiskEncryptionSetID = sdkToDiskEncryptionSetID(sdkDisk.ManagedDisk.DiskEncryptionSet)
+			}
+			dataDisks = append(dataDisks, disk)
+		}
+	}
+
+	return osDisk, dataDisks
+}
+
+func sdkToDiskEncryptionSetID(diskEncryptionSet *compute.DiskEncryptionSetParameters) string {
+	if diskEncryptionSet == nil {
+		return ""
+	}
+	return to.String(diskEncryptionSet.ID)
+}
+
+// SDKToUpgradePolicy converts an Azure SDK UpgradePolicy into an azure.UpgradePolicySpec, or nil if the instances
+// aren't upgraded by the platform.
+func SDKToUpgradePolicy(sdkPolicy compute.UpgradePolicy) *azure.UpgradePolicySpec {
+	automaticOSUpgrade := sdkPolicy.AutomaticOSUpgradePolicy != nil && to.Bool(sdkPolicy.AutomaticOSUpgradePolicy.EnableAutomaticOSUpgrade)
+	if (sdkPolicy.Mode == "" || sdkPolicy.Mode == compute.UpgradeModeManual) && !automaticOSUpgrade {
+		return nil
+	}
+
+	policy := &azure.UpgradePolicySpec{
+		Mode:               string(sdkPolicy.Mode),
+		AutomaticOSUpgrade: automaticOSUpgrade,
+	}
+	if rolling := sdkPolicy.RollingUpgradePolicy; rolling != nil {
+		policy.RollingUpgrade = &azure.RollingUpgradePolicySpec{
+			MaxBatchInstancePercent:             rolling.MaxBatchInstancePercent,
+			MaxUnhealthyInstancePercent:         rolling.MaxUnhealthyInstancePercent,
+			MaxUnhealthyUpgradedInstancePercent: rolling.MaxUnhealthyUpgradedInstancePercent,
+			PauseTimeBetweenBatches:             to.String(rolling.PauseTimeBetweenBatches),
+		}
+	}
+	return policy
+}
+
 // SDKToVMSSVM converts an Azure SDK VirtualMachineScaleSetVM into an infrav1exp.VMSSVM.
 func SDKToVMSSVM(sdkInstance compute.VirtualMachineScaleSetVM) *azure.VMSSVM {
 	instance := azure.VMSSVM{
@@ -86,16 +224,135 @@ func SDKToVMSSVM(sdkInstance compute.VirtualMachineScaleSetVM) *azure.VMSSVM {
 		instance.Image = SDKImageToImage(imageRef, sdkInstance.Plan != nil)
 	}
 
+	if sdkInstance.StorageProfile != nil {
+		instance.OSDisk, instance.DataDisks = sdkToInstanceDisks(*sdkInstance.StorageProfile)
+	}
+
+	if sdkInstance.SecurityProfile != nil {
+		instance.EncryptionAtHost = to.Bool(sdkInstance.SecurityProfile.EncryptionAtHost)
+	}
+
 	if sdkInstance.Zones != nil && len(*sdkInstance.Zones) > 0 {
 		// an instance should only have 1 zone, so we select the first item of the slice
 		instance.AvailabilityZone = to.StringSlice(sdkInstance.Zones)[0]
 	}
 
+	if sdkInstance.InstanceView != nil &&
+		sdkInstance.InstanceView.VMHealth != nil &&
+		sdkInstance.InstanceView.VMHealth.Status != nil {
+		// the health state is reported by the Application Health extension as a code like HealthState/healthy
+		instance.HealthState = strings.TrimPrefix(to.String(sdkInstance.InstanceView.VMHealth.Status.Code), "HealthState/")
+	}
+
+	if sdkInstance.Sku != nil {
+		instance.Sku = to.String(sdkInstance.Sku.Name)
+	}
+
+	// the platform reports whether the latest model, including the changes applied in place, is applied to the instance
+	instance.LatestModelApplied = to.Bool(sdkInstance.LatestModelApplied)
+
+	if sdkInstance.ProtectionPolicy != nil {
+		instance.ProtectFromScaleIn = to.Bool(sdkInstance.ProtectionPolicy.ProtectFromScaleIn)
+		instance.ProtectFromScaleSetActions = to.Bool(sdkInstance.ProtectionPolicy.ProtectFromScaleSetActions)
+	}
+
+	return &instance
+}
+
+// SDKVMToVMSSVM converts an Azure SDK VirtualMachine of a Flexible scale set into an azure.VMSSVM. The instances of
+// Flexible scale sets don't have an instance ID, so they are identified by their name.
+func SDKVMToVMSSVM(sdkVM compute.VirtualMachine) *azure.VMSSVM {
+	instance := azure.VMSSVM{
+		ID:         to.String(sdkVM.ID),
+		InstanceID: to.String(sdkVM.Name),
+	}
+
+	if sdkVM.VirtualMachineProperties == nil {
+		return &instance
+	}
+
+	instance.State = infrav1.Creating
+	if sdkVM.ProvisioningState != nil {
+		instance.State = infrav1.ProvisioningState(to.String(sdkVM.ProvisioningState))
+	}
+
+	if sdkVM.OsProfile != nil && sdkVM.OsProfile.ComputerName != nil {
+		instance.Name = *sdkVM.OsProfile.ComputerName
+	}
+
+	if sdkVM.StorageProfile != nil && sdkVM.StorageProfile.ImageReference != nil {
+		imageRef := sdkVM.StorageProfile.ImageReference
+		instance.Image = SDKImageToImage(imageRef, sdkVM.Plan != nil)
+	}
+
+	if sdkVM.StorageProfile != nil {
+		instance.OSDisk, instance.DataDisks = sdkToInstanceDisks(*sdkVM.StorageProfile)
+	}
+
+	if sdkVM.SecurityProfile != nil {
+		instance.EncryptionAtHost = to.Bool(sdkVM.SecurityProfile.EncryptionAtHost)
+	}
+
+	if sdkVM.Zones != nil && len(*sdkVM.Zones) > 0 {
+		instance.AvailabilityZone = to.StringSlice(sdkVM.Zones)[0]
+	}
+
+	if sdkVM.InstanceView != nil &&
+		sdkVM.InstanceView.VMHealth != nil &&
+		sdkVM.InstanceView.VMHealth.Status != nil {
+		instance.HealthState = strings.TrimPrefix(to.String(sdkVM.InstanceView.VMHealth.Status.Code), "HealthState/")
+	}
+
+	instance.Priority = string(compute.Regular)
+	if sdkVM.Priority != "" {
+		instance.Priority = string(sdkVM.Priority)
+	}
+	if sdkVM.InstanceView != nil {
+		instance.Evicted = isEvicted(sdkVM.Priority, sdkVM.InstanceView.Statuses)
+	}
+
 	return &instance
 }
 
+// isEvicted returns true if a Spot instance is deallocated, which is how the instances evicted with the Deallocate
+// eviction policy are left.
+func isEvicted(priority compute.VirtualMachinePriorityTypes, statuses *[]compute.InstanceViewStatus) bool {
+	if priority != compute.Spot || statuses == nil {
+		return false
+	}
+	for _, status := range *statuses {
+		if strings.EqualFold(to.String(status.Code), "PowerState/deallocated") {
+			return true
+		}
+	}
+	return false
+}
+
 // SDKImageToImage converts a SDK image reference to infrav1.Image.
 func SDKImageToImage(sdkImageRef *compute.ImageReference, isThirdPartyImage bool) infrav1.Image {
+	if sdkImageRef.CommunityGalleryImageID != nil {
+		if gallery, name, version, ok := parseGalleryImageID(*sdkImageRef.CommunityGalleryImageID, "CommunityGalleries"); ok {
+			return infrav1.Image{
+				CommunityGallery: &infrav1.AzureCommunityGalleryImage{
+					Gallery: gallery,
+					Name:    name,
+					Version: version,
+				},
+			}
+		}
+	}
+	if sdkImageRef.SharedGalleryImageID != nil {
+		if gallery, name, version, ok := parseGalleryImageID(*sdkImageRef.SharedGalleryImageID, "SharedGalleries"); ok {
+			return infrav1.Image{
+				DirectSharedGallery: &infrav1.AzureDirectSharedGalleryImage{
+					Gallery: gallery,
+					Name:    name,
+					Version: version,
+				},
+			}
+		}
+	}
+
 	return infrav1.Image{
 		ID: sdkImageRef.ID,
 		Marketplace: &infrav1.AzureMarketplaceImage{
@@ -107,3 +364,16 @@ func SDKImageToImage(sdkImageRef *compute.ImageReference, isThirdPartyImage bool
 		},
 	}
 }
+
+// parseGalleryImageID parses the ID of a community or direct shared gallery image version, in the form
+// /<galleries>/<gallery>/Images/<name>/Versions/<version>.
+func parseGalleryImageID(id, galleries string) (gallery, name, version string, ok bool) {
+	parts := strings.Split(strings.TrimPrefix(id, "/"), "/")
+	if len(parts) != 6 ||
+		!strings.EqualFold(parts[0], galleries) ||
+		!strings.EqualFold(parts[2], "Images") ||
+		!strings.EqualFold(parts[4], "Versions") {
+		return "", "", "", false
+	}
+	return parts[1], parts[3], parts[5], true
+}
@@ -20,9 +20,10 @@ import (
 	"fmt"
 	"testing"
 
-	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/onsi/gomega"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/converters"
 )
@@ -53,7 +54,7 @@ func Test_SDKToVMSS(t *testing.T) {
 						Tags:     tags,
 						VirtualMachineScaleSetProperties: &compute.VirtualMachineScaleSetProperties{
 							SinglePlacementGroup: to.BoolPtr(false),
-							ProvisioningState:    to.StringPtr(string(compute.ProvisioningState1Succeeded)),
+							ProvisioningState:    to.StringPtr(string(infrav1.Succeeded)),
 						},
 					},
 					[]compute.VirtualMachineScaleSetVM{
@@ -63,7 +64,7 @@ func Test_SDKToVMSS(t *testing.T) {
 							Name:       to.StringPtr("vm0"),
 							Zones:      to.StringSlicePtr([]string{"zone0"}),
 							VirtualMachineScaleSetVMProperties: &compute.VirtualMachineScaleSetVMProperties{
-								ProvisioningState: to.StringPtr(string(compute.ProvisioningState1Succeeded)),
+								ProvisioningState: to.StringPtr(string(infrav1.Succeeded)),
 								OsProfile: &compute.OSProfile{
 									ComputerName: to.StringPtr("instance-000000"),
 								},
@@ -75,7 +76,7 @@ func Test_SDKToVMSS(t *testing.T) {
 							Name:       to.StringPtr("vm1"),
 							Zones:      to.StringSlicePtr([]string{"zone1"}),
 							VirtualMachineScaleSetVMProperties: &compute.VirtualMachineScaleSetVMProperties{
-								ProvisioningState: to.StringPtr(string(compute.ProvisioningState1Succeeded)),
+								ProvisioningState: to.StringPtr(string(infrav1.Succeeded)),
 								OsProfile: &compute.OSProfile{
 									ComputerName: to.StringPtr("instance-000001"),
 								},
@@ -104,11 +105,182 @@ func Test_SDKToVMSS(t *testing.T) {
 						Name:             fmt.Sprintf("instance-00000%d", i),
 						AvailabilityZone: fmt.Sprintf("zone%d", i),
 						State:            "Succeeded",
+						Priority:         "Regular",
 					}
 				}
 				g.Expect(actual).To(gomega.Equal(&expected))
 			},
 		},
+		{
+			Name: "ShouldPopulateExtensionsRepairsAndHealthState",
+			SubjectFactory: func(g *gomega.GomegaWithT) (compute.VirtualMachineScaleSet, []compute.VirtualMachineScaleSetVM) {
+				return compute.VirtualMachineScaleSet{
+						ID:   to.StringPtr("vmssID"),
+						Name: to.StringPtr("vmssName"),
+						VirtualMachineScaleSetProperties: &compute.VirtualMachineScaleSetProperties{
+							ProvisioningState: to.StringPtr(string(infrav1.Succeeded)),
+							AutomaticRepairsPolicy: &compute.AutomaticRepairsPolicy{
+								Enabled:      to.BoolPtr(true),
+								GracePeriod:  to.StringPtr("PT30M"),
+								RepairAction: compute.Replace,
+							},
+							VirtualMachineProfile: &compute.VirtualMachineScaleSetVMProfile{
+								ExtensionProfile: &compute.VirtualMachineScaleSetExtensionProfile{
+									Extensions: &[]compute.VirtualMachineScaleSetExtension{
+										{Name: to.StringPtr("CAPZ.Linux.Bootstrapping")},
+										{Name: to.StringPtr("ApplicationHealthLinux")},
+									},
+								},
+							},
+						},
+					},
+					[]compute.VirtualMachineScaleSetVM{
+						{
+							InstanceID: to.StringPtr("0"),
+							ID:         to.StringPtr("vm/0"),
+							VirtualMachineScaleSetVMProperties: &compute.VirtualMachineScaleSetVMProperties{
+								ProvisioningState: to.StringPtr(string(infrav1.Succeeded)),
+								InstanceView: &compute.VirtualMachineScaleSetVMInstanceView{
+									VMHealth: &compute.VirtualMachineHealthStatus{
+										Status: &compute.InstanceViewStatus{
+											Code: to.StringPtr("HealthState/unhealthy"),
+										},
+									},
+								},
+							},
+						},
+					}
+			},
+			Expect: func(g *gomega.GomegaWithT, actual *azure.VMSS) {
+				g.Expect(actual.Extensions).To(gomega.Equal([]string{"CAPZ.Linux.Bootstrapping", "ApplicationHealthLinux"}))
+				g.Expect(actual.AutomaticRepairs).To(gomega.Equal(&azure.AutomaticRepairsSpec{
+					GracePeriod:  "PT30M",
+					RepairAction: "Replace",
+				}))
+				g.Expect(actual.Instances).To(gomega.HaveLen(1))
+				g.Expect(actual.Instances[0].HealthState).To(gomega.Equal("unhealthy"))
+			},
+		},
+		{
+			Name: "ShouldPopulateSpotPriorityAndEvictions",
+			SubjectFactory: func(g *gomega.GomegaWithT) (compute.VirtualMachineScaleSet, []compute.VirtualMachineScaleSetVM) {
+				instance := func(id string, powerState string) compute.VirtualMachineScaleSetVM {
+					return compute.VirtualMachineScaleSetVM{
+						InstanceID: to.StringPtr(id),
+						ID:         to.StringPtr("vm/" + id),
+						VirtualMachineScaleSetVMProperties: &compute.VirtualMachineScaleSetVMProperties{
+							ProvisioningState: to.StringPtr(string(infrav1.Succeeded)),
+							InstanceView: &compute.VirtualMachineScaleSetVMInstanceView{
+								Statuses: &[]compute.InstanceViewStatus{
+									{Code: to.StringPtr("ProvisioningState/succeeded")},
+									{Code: to.StringPtr(powerState)},
+								},
+							},
+						},
+					}
+				}
+				return compute.VirtualMachineScaleSet{
+						ID:   to.StringPtr("vmssID"),
+						Name: to.StringPtr("vmssName"),
+						VirtualMachineScaleSetProperties: &compute.VirtualMachineScaleSetProperties{
+							ProvisioningState: to.StringPtr(string(infrav1.Succeeded)),
+							VirtualMachineProfile: &compute.VirtualMachineScaleSetVMProfile{
+								Priority:       compute.Spot,
+								EvictionPolicy: compute.VirtualMachineEvictionPolicyTypesDeallocate,
+							},
+						},
+					},
+					[]compute.VirtualMachineScaleSetVM{
+						instance("0", "PowerState/running"),
+						instance("1", "PowerState/deallocated"),
+					}
+			},
+			Expect: func(g *gomega.GomegaWithT, actual *azure.VMSS) {
+				g.Expect(actual.Instances).To(gomega.HaveLen(2))
+				g.Expect(actual.Instances[0].Priority).To(gomega.Equal("Spot"))
+				g.Expect(actual.Instances[0].Evicted).To(gomega.BeFalse())
+				g.Expect(actual.Instances[1].Priority).To(gomega.Equal("Spot"))
+				g.Expect(actual.Instances[1].Evicted).To(gomega.BeTrue())
+			},
+		}, {
+			Name: "ShouldPopulateModelAndInstanceDisks",
+			SubjectFactory: func(g *gomega.GomegaWithT) (compute.VirtualMachineScaleSet, []compute.VirtualMachineScaleSetVM) {
+				return compute.VirtualMachineScaleSet{
+						ID:   to.StringPtr("vmssID"),
+						Name: to.StringPtr("vmssName"),
+						VirtualMachineScaleSetProperties: &compute.VirtualMachineScaleSetProperties{
+							VirtualMachineProfile: &compute.VirtualMachineScaleSetVMProfile{
+								StorageProfile: &compute.VirtualMachineScaleSetStorageProfile{
+									OsDisk: &compute.VirtualMachineScaleSetOSDisk{
+										DiffDiskSettings: &compute.DiffDiskSettings{Option: compute.Local},
+									},
+									DataDisks: &[]compute.VirtualMachineScaleSetDataDisk{
+										{
+											Name:       to.StringPtr("vmssName_etcd"),
+											Lun:        to.Int32Ptr(0),
+											DiskSizeGB: to.Int32Ptr(256),
+											ManagedDisk: &compute.VirtualMachineScaleSetManagedDiskParameters{
+												StorageAccountType: compute.StorageAccountTypesPremiumLRS,
+												DiskEncryptionSet:  &compute.DiskEncryptionSetParameters{ID: to.StringPtr("des")},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+					[]compute.VirtualMachineScaleSetVM{
+						{
+							InstanceID: to.StringPtr("0"),
+							ID:         to.StringPtr("vm/0"),
+							VirtualMachineScaleSetVMProperties: &compute.VirtualMachineScaleSetVMProperties{
+								StorageProfile: &compute.StorageProfile{
+									OsDisk: &compute.OSDisk{
+										Name:             to.StringPtr("vm0_os"),
+										DiskSizeGB:       to.Int32Ptr(30),
+										DiffDiskSettings: &compute.DiffDiskSettings{Option: compute.Local},
+										ManagedDisk:      &compute.ManagedDiskParameters{StorageAccountType: compute.StorageAccountTypesStandardLRS},
+									},
+									DataDisks: &[]compute.DataDisk{
+										{
+											Name:         to.StringPtr("vm0_etcd"),
+											Lun:          to.Int32Ptr(0),
+											DiskSizeGB:   to.Int32Ptr(256),
+											CreateOption: compute.DiskCreateOptionTypesEmpty,
+											ManagedDisk: &compute.ManagedDiskParameters{
+												StorageAccountType: compute.StorageAccountTypesPremiumLRS,
+												DiskEncryptionSet:  &compute.DiskEncryptionSetParameters{ID: to.StringPtr("des")},
+											},
+										},
+										{
+											Name:         to.StringPtr("pvc-0"),
+											Lun:          to.Int32Ptr(1),
+											DiskSizeGB:   to.Int32Ptr(10),
+											CreateOption: compute.DiskCreateOptionTypesAttach,
+										},
+									},
+								},
+								SecurityProfile: &compute.SecurityProfile{EncryptionAtHost: to.BoolPtr(true)},
+							},
+						},
+					}
+			},
+			Expect: func(g *gomega.GomegaWithT, actual *azure.VMSS) {
+				g.Expect(actual.OSDisk).To(gomega.Equal(azure.VMSSDisk{Ephemeral: true}))
+				g.Expect(actual.DataDisks).To(gomega.Equal([]azure.VMSSDisk{
+					{Name: "vmssName_etcd", Lun: to.Int32Ptr(0), DiskSizeGB: 256, StorageAccountType: "Premium_LRS", DiskEncryptionSetID: "des"},
+				}))
+				g.Expect(actual.Instances).To(gomega.HaveLen(1))
+				g.Expect(actual.Instances[0].OSDisk).To(gomega.Equal(azure.VMSSDisk{Name: "vm0_os", DiskSizeGB: 30, StorageAccountType: "Standard_LRS", Ephemeral: true}))
+				g.Expect(actual.Instances[0].DataDisks).To(gomega.Equal([]azure.VMSSDisk{
+					{Name: "vm0_etcd", Lun: to.Int32Ptr(0), DiskSizeGB: 256, StorageAccountType: "Premium_LRS", DiskEncryptionSetID: "des"},
+					{Name: "pvc-0", Lun: to.Int32Ptr(1), DiskSizeGB: 10, Attached: true},
+				}))
+				g.Expect(actual.Instances[0].EncryptionAtHost).To(gomega.BeTrue())
+				g.Expect(actual.HasLatestModelApplied(actual.Instances[0])).To(gomega.BeTrue())
+				g.Expect(azure.HasDiskLayout(actual.Instances[0].OSDisk, actual.Instances[0].DataDisks, actual.OSDisk, actual.DataDisks)).To(gomega.BeTrue())
+			},
+		},
 	}
 
 	for _, c := range cases {
@@ -122,3 +294,131 @@ func Test_SDKToVMSS(t *testing.T) {
 		})
 	}
 }
+
+func Test_SDKImageToImage(t *testing.T) {
+	cases := []struct {
+		Name     string
+		ImageRef *compute.ImageReference
+		Expect   infrav1.Image
+	}{
+		{
+			Name: "ShouldConvertCommunityGalleryImage",
+			ImageRef: &compute.ImageReference{
+				CommunityGalleryImageID: to.StringPtr("/CommunityGalleries/gallery-1234/Images/image/Versions/1.0.0"),
+			},
+			Expect: infrav1.Image{
+				CommunityGallery: &infrav1.AzureCommunityGalleryImage{
+					Gallery: "gallery-1234",
+					Name:    "image",
+					Version: "1.0.0",
+				},
+			},
+		},
+		{
+			Name: "ShouldConvertDirectSharedGalleryImage",
+			ImageRef: &compute.ImageReference{
+				SharedGalleryImageID: to.StringPtr("/SharedGalleries/gallery-1234/Images/image/Versions/latest"),
+			},
+			Expect: infrav1.Image{
+				DirectSharedGallery: &infrav1.AzureDirectSharedGalleryImage{
+					Gallery: "gallery-1234",
+					Name:    "image",
+					Version: "latest",
+				},
+			},
+		},
+		{
+			Name: "ShouldConvertMarketplaceImage",
+			ImageRef: &compute.ImageReference{
+				Publisher: to.StringPtr("publisher"),
+				Offer:     to.StringPtr("offer"),
+				Sku:       to.StringPtr("sku"),
+				Version:   to.StringPtr("1.0.0"),
+			},
+			Expect: infrav1.Image{
+				Marketplace: &infrav1.AzureMarketplaceImage{
+					Publisher: "publisher",
+					Offer:     "offer",
+					SKU:       "sku",
+					Version:   "1.0.0",
+				},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			t.Parallel()
+			g := gomega.NewGomegaWithT(t)
+			g.Expect(converters.SDKImageToImage(c.ImageRef, false)).To(gomega.Equal(c.Expect))
+		})
+	}
+}
+
+func Test_SDKToUpgradePolicy(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Policy compute.UpgradePolicy
+		Expect *azure.UpgradePolicySpec
+	}{
+		{
+			Name:   "ShouldIgnoreManualUpgrades",
+			Policy: compute.UpgradePolicy{Mode: compute.UpgradeModeManual},
+			Expect: nil,
+		},
+		{
+			Name: "ShouldIgnoreDisabledAutomaticOSUpgrades",
+			Policy: compute.UpgradePolicy{
+				Mode: compute.UpgradeModeManual,
+				AutomaticOSUpgradePolicy: &compute.AutomaticOSUpgradePolicy{
+					EnableAutomaticOSUpgrade: to.BoolPtr(false),
+				},
+			},
+			Expect: nil,
+		},
+		{
+			Name: "ShouldConvertRollingUpgrades",
+			Policy: compute.UpgradePolicy{
+				Mode: compute.UpgradeModeRolling,
+				RollingUpgradePolicy: &compute.RollingUpgradePolicy{
+					MaxBatchInstancePercent:             to.Int32Ptr(20),
+					MaxUnhealthyInstancePercent:         to.Int32Ptr(20),
+					MaxUnhealthyUpgradedInstancePercent: to.Int32Ptr(20),
+					PauseTimeBetweenBatches:             to.StringPtr("PT0S"),
+				},
+			},
+			Expect: &azure.UpgradePolicySpec{
+				Mode: "Rolling",
+				RollingUpgrade: &azure.RollingUpgradePolicySpec{
+					MaxBatchInstancePercent:             to.Int32Ptr(20),
+					MaxUnhealthyInstancePercent:         to.Int32Ptr(20),
+					MaxUnhealthyUpgradedInstancePercent: to.Int32Ptr(20),
+					PauseTimeBetweenBatches:             "PT0S",
+				},
+			},
+		},
+		{
+			Name: "ShouldConvertAutomaticOSUpgrades",
+			Policy: compute.UpgradePolicy{
+				Mode: compute.UpgradeModeManual,
+				AutomaticOSUpgradePolicy: &compute.AutomaticOSUpgradePolicy{
+					EnableAutomaticOSUpgrade: to.BoolPtr(true),
+				},
+			},
+			Expect: &azure.UpgradePolicySpec{
+				Mode:               "Manual",
+				AutomaticOSUpgrade: true,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			t.Parallel()
+			g := gomega.NewGomegaWithT(t)
+			g.Expect(converters.SDKToUpgradePolicy(c.Policy)).To(gomega.Equal(c.Expect))
+		})
+	}
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"os"
+
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/yaml"
+)
+
+// GetDefaultImage returns the image of a machine which doesn't specify one. The default image resolver is consulted
+// first and, if it has no image for the Kubernetes version, OS type and image flavor, the reference image is returned.
+func GetDefaultImage(k8sVersion, osType, flavor, runtime, windowsServerVersion string) (*infrav1.Image, error) {
+	if resolver := infrav1.GetDefaultImageResolver(); resolver != nil {
+		image, err := resolver.GetDefaultImage(k8sVersion, osType, flavor)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to resolve default image")
+		}
+		if image != nil {
+			return image, nil
+		}
+	}
+
+	switch {
+	case osType == WindowsOS:
+		return GetDefaultWindowsImage(k8sVersion, runtime, windowsServerVersion)
+	case flavor == FlatcarFlavor:
+		return GetDefaultFlatcarImage(), nil
+	default:
+		return GetDefaultUbuntuImage(k8sVersion)
+	}
+}
+
+// DefaultImagesConfig maps Kubernetes versions, OS types and image flavors to the images used by default.
+type DefaultImagesConfig struct {
+	// Images are the default images. The first image matching the Kubernetes version, OS type and image flavor of a
+	// machine is used.
+	Images []DefaultImage `json:"images"`
+}
+
+// DefaultImage is the default image for a range of Kubernetes versions, an OS type and an image flavor.
+type DefaultImage struct {
+	// OSType is the OS type of the machines to use the image for, either Linux or Windows. Defaults to Linux.
+	// +optional
+	OSType string `json:"osType,omitempty"`
+
+	// Flavor is the image flavor of the machines to use the image for. Flatcar selects the Linux machines bootstrapped
+	// with Ignition. Defaults to the machines bootstrapped with cloud-init.
+	// +optional
+	Flavor string `json:"flavor,omitempty"`
+
+	// KubernetesVersion is the semantic version range of the Kubernetes versions to use the image for, e.g.
+	// ">=1.22.0 <1.23.0" or "1.22.x". Defaults to all versions.
+	// +optional
+	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
+
+	// Image is the image to use.
+	Image infrav1.Image `json:"image"`
+}
+
+// LoadDefaultImagesConfig reads and validates a YAML default images config file.
+func LoadDefaultImagesConfig(path string) (*DefaultImagesConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read default images config %s", path)
+	}
+	config := &DefaultImagesConfig{}
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse default images config %s", path)
+	}
+	if errs := config.Validate(); len(errs) > 0 {
+		return nil, errors.Wrapf(errs.ToAggregate(), "invalid default images config %s", path)
+	}
+	return config, nil
+}
+
+// Validate validates the default images.
+func (c *DefaultImagesConfig) Validate() field.ErrorList {
+	allErrs := field.ErrorList{}
+	for i := range c.Images {
+		image := &c.Images[i]
+		fldPath := field.NewPath("images").Index(i)
+		if image.OSType != "" && image.OSType != LinuxOS && image.OSType != WindowsOS {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("osType"), image.OSType, []string{LinuxOS, WindowsOS}))
+		}
+		if image.Flavor != "" {
+			if image.Flavor != FlatcarFlavor {
+				allErrs = append(allErrs, field.NotSupported(fldPath.Child("flavor"), image.Flavor, []string{FlatcarFlavor}))
+			} else if image.OSType == WindowsOS {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("flavor"), image.Flavor, "only Linux images can have the Flatcar flavor"))
+			}
+		}
+		if image.KubernetesVersion != "" {
+			if _, err := semver.ParseRange(image.KubernetesVersion); err != nil {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("kubernetesVersion"), image.KubernetesVersion, "must be a semantic version range"))
+			}
+		}
+		allErrs = append(allErrs, infrav1.ValidateImage(&image.Image, fldPath.Child("image"))...)
+	}
+	return allErrs
+}
+
+// GetDefaultImage returns a copy of the first image matching a Kubernetes version, OS type and image flavor, or nil if
+// none does. An empty Kubernetes version only matches the images without a Kubernetes version range.
+func (c *DefaultImagesConfig) GetDefaultImage(k8sVersion, osType, flavor string) (*infrav1.Image, error) {
+	if osType == "" {
+		osType = LinuxOS
+	}
+	var version semver.Version
+	if k8sVersion != "" {
+		var err error
+		version, err = semver.ParseTolerant(k8sVersion)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to parse Kubernetes version \"%s\"", k8sVersion)
+		}
+	}
+
+	for _, image := range c.Images {
+		imageOSType := image.OSType
+		if imageOSType == "" {
+			imageOSType = LinuxOS
+		}
+		if imageOSType != osType || image.Flavor != flavor {
+			continue
+		}
+		if image.KubernetesVersion != "" {
+			if k8sVersion == "" {
+				continue
+			}
+			versionRange, err := semver.ParseRange(image.KubernetesVersion)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid Kubernetes version range \"%s\"", image.KubernetesVersion)
+			}
+			if !versionRange(version) {
+				continue
+			}
+		}
+		return image.Image.DeepCopy(), nil
+	}
+	return nil, nil
+}
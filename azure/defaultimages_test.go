@@ -0,0 +1,259 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+)
+
+func hardenedImage(name string) infrav1.Image {
+	return infrav1.Image{
+		SharedGallery: &infrav1.AzureSharedGalleryImage{
+			SubscriptionID: "123",
+			ResourceGroup:  "images",
+			Gallery:        "hardened",
+			Name:           name,
+			Version:        "latest",
+		},
+	}
+}
+
+func TestDefaultImagesConfigGetDefaultImage(t *testing.T) {
+	config := &DefaultImagesConfig{
+		Images: []DefaultImage{
+			{
+				KubernetesVersion: "1.22.x",
+				Image:             hardenedImage("ubuntu-2004-k8s-1.22"),
+			},
+			{
+				OSType:            WindowsOS,
+				KubernetesVersion: ">=1.22.0",
+				Image:             hardenedImage("windows-2022"),
+			},
+			{
+				OSType: LinuxOS,
+				Image:  hardenedImage("ubuntu-2004"),
+			},
+			{
+				Flavor: FlatcarFlavor,
+				Image:  hardenedImage("flatcar"),
+			},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		k8sVersion string
+		osType     string
+		flavor     string
+		want       *infrav1.Image
+		wantErr    bool
+	}{
+		{
+			name:       "first matching image",
+			k8sVersion: "v1.22.6",
+			osType:     LinuxOS,
+			want:       &config.Images[0].Image,
+		},
+		{
+			name:       "image for all versions",
+			k8sVersion: "v1.23.3",
+			want:       &config.Images[2].Image,
+		},
+		{
+			name:       "image for os type",
+			k8sVersion: "1.23.3",
+			osType:     WindowsOS,
+			want:       &config.Images[1].Image,
+		},
+		{
+			name:       "no matching image",
+			k8sVersion: "1.21.2",
+			osType:     WindowsOS,
+			want:       nil,
+		},
+		{
+			name:   "no Kubernetes version only matches images for all versions",
+			osType: LinuxOS,
+			want:   &config.Images[2].Image,
+		},
+		{
+			name:   "no Kubernetes version and no image for all versions",
+			osType: WindowsOS,
+			want:   nil,
+		},
+		{
+			name:       "image for flavor",
+			k8sVersion: "1.22.6",
+			osType:     LinuxOS,
+			flavor:     FlatcarFlavor,
+			want:       &config.Images[3].Image,
+		},
+		{
+			name:       "no matching image for flavor",
+			k8sVersion: "1.22.6",
+			osType:     WindowsOS,
+			flavor:     FlatcarFlavor,
+			want:       nil,
+		},
+		{
+			name:       "invalid Kubernetes version",
+			k8sVersion: "invalid",
+			wantErr:    true,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			got, err := config.GetDefaultImage(tc.k8sVersion, tc.osType, tc.flavor)
+			if tc.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tc.want))
+		})
+	}
+}
+
+func TestGetDefaultImage(t *testing.T) {
+	g := NewWithT(t)
+	defer infrav1.SetDefaultImageResolver(nil)
+
+	reference, err := GetDefaultImage("1.22.6", LinuxOS, "", "", "")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(reference.Marketplace.Publisher).To(Equal(DefaultImagePublisherID))
+
+	infrav1.SetDefaultImageResolver(&DefaultImagesConfig{
+		Images: []DefaultImage{
+			{
+				KubernetesVersion: "1.22.x",
+				Image:             hardenedImage("ubuntu-2004"),
+			},
+		},
+	})
+
+	image, err := GetDefaultImage("1.22.6", LinuxOS, "", "", "")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(image.SharedGallery.Gallery).To(Equal("hardened"))
+
+	image, err = GetDefaultImage("1.23.3", LinuxOS, "", "", "")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(image).To(Equal(mustGetDefaultUbuntuImage(g, "1.23.3")))
+
+	image, err = GetDefaultImage("1.22.6", WindowsOS, "", "", "")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(image.Marketplace.Offer).To(Equal(DefaultWindowsImageOfferID))
+
+	image, err = GetDefaultImage("1.22.6", LinuxOS, FlatcarFlavor, "", "")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(image).To(Equal(GetDefaultFlatcarImage()))
+
+	infrav1.SetDefaultImageResolver(&DefaultImagesConfig{
+		Images: []DefaultImage{
+			{
+				Flavor: FlatcarFlavor,
+				Image:  hardenedImage("flatcar"),
+			},
+		},
+	})
+
+	image, err = GetDefaultImage("1.22.6", LinuxOS, FlatcarFlavor, "", "")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(image.SharedGallery.Name).To(Equal("flatcar"))
+}
+
+func mustGetDefaultUbuntuImage(g *WithT, k8sVersion string) *infrav1.Image {
+	image, err := GetDefaultUbuntuImage(k8sVersion)
+	g.Expect(err).NotTo(HaveOccurred())
+	return image
+}
+
+func TestLoadDefaultImagesConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name: "valid config",
+			content: `images:
+- kubernetesVersion: ">=1.22.0 <1.23.0"
+  image:
+    marketplace:
+      publisher: example
+      offer: hardened
+      sku: k8s-1dot22-ubuntu-2004
+      version: latest
+- osType: Windows
+  image:
+    id: /subscriptions/123/resourceGroups/images/providers/Microsoft.Compute/images/windows
+`,
+		},
+		{
+			name: "unknown field",
+			content: `images:
+- kubernetesVersions: "1.22.x"
+`,
+			wantErr: "failed to parse default images config",
+		},
+		{
+			name: "invalid config",
+			content: `images:
+- osType: Darwin
+  kubernetesVersion: "not a range"
+  image:
+    marketplace:
+      publisher: example
+`,
+			wantErr: "invalid default images config",
+		},
+		{
+			name: "Windows image with the Flatcar flavor",
+			content: `images:
+- osType: Windows
+  flavor: Flatcar
+  image:
+    id: /subscriptions/123/resourceGroups/images/providers/Microsoft.Compute/images/windows
+`,
+			wantErr: "only Linux images can have the Flatcar flavor",
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			path := filepath.Join(t.TempDir(), "default-images.yaml")
+			g.Expect(os.WriteFile(path, []byte(tc.content), 0600)).To(Succeed())
+
+			config, err := LoadDefaultImagesConfig(path)
+			if tc.wantErr != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring(tc.wantErr))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(config.Images).To(HaveLen(2))
+		})
+	}
+}
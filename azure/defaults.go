@@ -17,8 +17,10 @@ limitations under the License.
 package azure
 
 import (
+	"crypto/sha256"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/Azure/go-autorest/autorest"
 	"github.com/Azure/go-autorest/autorest/azure"
@@ -50,6 +52,37 @@ const (
 	LinuxOS = "Linux"
 	// WindowsOS is Windows OS value for OSDisk.OSType.
 	WindowsOS = "Windows"
+	// FlatcarFlavor is the default image flavor of Linux machines bootstrapped with Ignition, which use Flatcar
+	// Container Linux.
+	FlatcarFlavor = "Flatcar"
+)
+
+const (
+	// DefaultFlatcarImagePublisherID is the Azure Marketplace publisher ID of Flatcar Container Linux.
+	DefaultFlatcarImagePublisherID = "kinvolk"
+	// DefaultFlatcarImageOfferID is the Azure Marketplace offer ID of Flatcar Container Linux.
+	DefaultFlatcarImageOfferID = "flatcar-container-linux-corevm-amd64"
+	// DefaultFlatcarImageSKU is the Azure Marketplace SKU of the stable channel of Flatcar Container Linux.
+	DefaultFlatcarImageSKU = "stable-gen2"
+)
+
+const (
+	// CloudConfigBootstrapDataFormat is the format of cloud-init bootstrap data.
+	CloudConfigBootstrapDataFormat = "cloud-config"
+	// IgnitionBootstrapDataFormat is the format of Ignition bootstrap data.
+	IgnitionBootstrapDataFormat = "ignition"
+)
+
+const (
+	// MaxCustomDataSize is the maximum size in bytes of the custom data of a VM, before it is base64 encoded.
+	MaxCustomDataSize = 65535
+	// MaxUserDataSize is the maximum size in bytes of the user data of a VM, once it is base64 encoded.
+	MaxUserDataSize = 65536
+)
+
+const (
+	// DefaultBootstrapDataContainerName is the default name of the blob container bootstrap data is uploaded to.
+	DefaultBootstrapDataContainerName = "bootstrap-data"
 )
 
 const (
@@ -199,6 +232,19 @@ func GenerateVnetPeeringName(sourceVnetName string, remoteVnetName string) strin
 	return fmt.Sprintf("%s-To-%s", sourceVnetName, remoteVnetName)
 }
 
+// GenerateBootstrapDataStorageAccountName generates the name of the storage account owned by a cluster that bootstrap
+// data is uploaded to. Storage account names must be globally unique and at most 24 lowercase letters and numbers, so
+// the name is derived from a hash of the subscription, resource group and name of the cluster.
+func GenerateBootstrapDataStorageAccountName(subscriptionID, resourceGroup, clusterName string) string {
+	hash := sha256.Sum256([]byte(strings.ToLower(fmt.Sprintf("%s/%s/%s", subscriptionID, resourceGroup, clusterName))))
+	return fmt.Sprintf("capzbd%x", hash[:9])
+}
+
+// GenerateBootstrapDataBlobName generates the name of the blob the bootstrap data of a machine is uploaded to.
+func GenerateBootstrapDataBlobName(namespace, clusterName, machineName string) string {
+	return fmt.Sprintf("%s/%s/%s", namespace, clusterName, machineName)
+}
+
 // GenerateAvailabilitySetName generates the name of a availability set based on the cluster name and the node group.
 // node group identifies the set of nodes that belong to this availability set:
 // For control plane nodes, this will be `control-plane`.
@@ -282,6 +328,11 @@ func AvailabilitySetID(subscriptionID, resourceGroup, availabilitySetName string
 	return fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/availabilitySets/%s", subscriptionID, resourceGroup, availabilitySetName)
 }
 
+// DiskID returns the azure resource ID for a given managed disk.
+func DiskID(subscriptionID, resourceGroup, diskName string) string {
+	return fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/disks/%s", subscriptionID, resourceGroup, diskName)
+}
+
 // GetDefaultImageSKUID gets the SKU ID of the image to use for the provided version of Kubernetes.
 // note: osAndVersion is expected to be in the format of {os}-{version} (ex: unbuntu-2004 or windows-2022)
 func getDefaultImageSKUID(k8sVersion, osAndVersion string) (string, error) {
@@ -364,6 +415,45 @@ func GetDefaultWindowsImage(k8sVersion, runtime, osAndVersion string) (*infrav1.
 	return defaultImage, nil
 }
 
+// GetDefaultFlatcarImage returns the default image spec for Flatcar Container Linux, which is used for machines
+// bootstrapped with Ignition.
+func GetDefaultFlatcarImage() *infrav1.Image {
+	return &infrav1.Image{
+		Marketplace: &infrav1.AzureMarketplaceImage{
+			Publisher: DefaultFlatcarImagePublisherID,
+			Offer:     DefaultFlatcarImageOfferID,
+			SKU:       DefaultFlatcarImageSKU,
+			Version:   LatestVersion,
+		},
+	}
+}
+
+// ValidateBootstrapData checks that base64 encoded bootstrap data of the given format can be passed to a VM with the
+// given delivery.
+func ValidateBootstrapData(data, format string, delivery infrav1.BootstrapDataDelivery) error {
+	if delivery == infrav1.BootstrapDataDeliveryUserData {
+		if format != IgnitionBootstrapDataFormat {
+			return errors.Errorf("%s bootstrap data must be passed as custom data", format)
+		}
+		if len(data) > MaxUserDataSize {
+			return errors.Errorf("bootstrap data is %d bytes once base64 encoded, more than the %d bytes allowed in user data", len(data), MaxUserDataSize)
+		}
+		return nil
+	}
+
+	// The loader that downloads the bootstrap data from blob storage is passed as custom data once it is generated.
+	if delivery == infrav1.BootstrapDataDeliveryBlob && data == "" {
+		return errors.New("bootstrap data has not been uploaded to blob storage yet")
+	}
+
+	// Every 4 base64 characters encode 3 bytes, minus one byte for each padding character.
+	size := len(data)/4*3 - (len(data) - len(strings.TrimRight(data, "=")))
+	if size > MaxCustomDataSize {
+		return errors.Errorf("bootstrap data is %d bytes, more than the %d bytes allowed in custom data", size, MaxCustomDataSize)
+	}
+	return nil
+}
+
 // GetBootstrappingVMExtension returns the CAPZ Bootstrapping VM extension.
 // The CAPZ Bootstrapping extension is a simple clone of https://github.com/Azure/custom-script-extension-linux for Linux or
 // https://docs.microsoft.com/en-us/azure/virtual-machines/extensions/custom-script-windows for Windows.
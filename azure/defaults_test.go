@@ -18,6 +18,7 @@ package azure
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -26,6 +27,7 @@ import (
 
 	"github.com/Azure/go-autorest/autorest"
 	. "github.com/onsi/gomega"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
 )
 
@@ -367,3 +369,64 @@ func TestGetDefaultWindowsImage(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateBootstrapData(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		format   string
+		delivery infrav1.BootstrapDataDelivery
+		wantErr  string
+	}{
+		{
+			name:   "cloud-config passed as custom data",
+			data:   base64.StdEncoding.EncodeToString([]byte("#cloud-config")),
+			format: CloudConfigBootstrapDataFormat,
+		},
+		{
+			name:     "ignition passed as user data",
+			data:     base64.StdEncoding.EncodeToString([]byte(`{"ignition":{"version":"3.1.0"}}`)),
+			format:   IgnitionBootstrapDataFormat,
+			delivery: infrav1.BootstrapDataDeliveryUserData,
+		},
+		{
+			name:     "cloud-config passed as user data",
+			data:     base64.StdEncoding.EncodeToString([]byte("#cloud-config")),
+			format:   CloudConfigBootstrapDataFormat,
+			delivery: infrav1.BootstrapDataDeliveryUserData,
+			wantErr:  "cloud-config bootstrap data must be passed as custom data",
+		},
+		{
+			name:    "largest custom data",
+			data:    base64.StdEncoding.EncodeToString(make([]byte, MaxCustomDataSize)),
+			format:  IgnitionBootstrapDataFormat,
+			wantErr: "",
+		},
+		{
+			name:    "custom data too large",
+			data:    base64.StdEncoding.EncodeToString(make([]byte, MaxCustomDataSize+1)),
+			format:  IgnitionBootstrapDataFormat,
+			wantErr: "bootstrap data is 65536 bytes, more than the 65535 bytes allowed in custom data",
+		},
+		{
+			name:     "user data too large",
+			data:     base64.StdEncoding.EncodeToString(make([]byte, 49152+1)),
+			format:   IgnitionBootstrapDataFormat,
+			delivery: infrav1.BootstrapDataDeliveryUserData,
+			wantErr:  "bootstrap data is 65540 bytes once base64 encoded, more than the 65536 bytes allowed in user data",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			g := NewWithT(t)
+			err := ValidateBootstrapData(test.data, test.format, test.delivery)
+			if test.wantErr != "" {
+				g.Expect(err).To(MatchError(test.wantErr))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
@@ -31,6 +31,8 @@ import (
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/bastionhosts"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/bootstrapdata"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/capacityreservationgroups"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/groups"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/loadbalancers"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/natgateways"
@@ -278,6 +280,67 @@ func (s *ClusterScope) NatGatewaySpecs() []azure.ResourceSpecGetter {
 	return natGateways
 }
 
+// CapacityReservationGroupSpecs returns the capacity reservation group specs.
+func (s *ClusterScope) CapacityReservationGroupSpecs() []azure.ResourceSpecGetter {
+	specs := make([]azure.ResourceSpecGetter, len(s.AzureCluster.Spec.CapacityReservationGroups))
+	for i, group := range s.AzureCluster.Spec.CapacityReservationGroups {
+		specs[i] = &capacityreservationgroups.CapacityReservationGroupSpec{
+			Name:           group.Name,
+			ResourceGroup:  s.ResourceGroup(),
+			ClusterName:    s.ClusterName(),
+			Location:       s.Location(),
+			Zones:          group.Zones,
+			AdditionalTags: s.AdditionalTags(),
+		}
+	}
+
+	return specs
+}
+
+// BootstrapDataStorageAccountSpec returns the spec of the storage account owned by the cluster that the bootstrap data
+// of machines delivering it from blob storage is uploaded to.
+func (s *ClusterScope) BootstrapDataStorageAccountSpec() azure.ResourceSpecGetter {
+	return &bootstrapdata.StorageAccountSpec{
+		Name:           azure.GenerateBootstrapDataStorageAccountName(s.SubscriptionID(), s.ResourceGroup(), s.ClusterName()),
+		ResourceGroup:  s.ResourceGroup(),
+		Location:       s.Location(),
+		ClusterName:    s.ClusterName(),
+		AdditionalTags: s.AdditionalTags(),
+	}
+}
+
+// CapacityReservationSpecs returns the capacity reservation specs of all capacity reservation groups.
+func (s *ClusterScope) CapacityReservationSpecs() []azure.ResourceSpecGetter {
+	var specs []azure.ResourceSpecGetter
+	for _, group := range s.AzureCluster.Spec.CapacityReservationGroups {
+		for _, reservation := range group.Reservations {
+			specs = append(specs, &capacityreservationgroups.CapacityReservationSpec{
+				Name:           reservation.Name,
+				GroupName:      group.Name,
+				ResourceGroup:  s.ResourceGroup(),
+				ClusterName:    s.ClusterName(),
+				Location:       s.Location(),
+				VMSize:         reservation.VMSize,
+				Zone:           reservation.Zone,
+				Capacity:       reservation.Capacity,
+				AdditionalTags: s.AdditionalTags(),
+			})
+		}
+	}
+
+	return specs
+}
+
+// CapacityReservationGroupsStatus returns the observed state of the capacity reservation groups.
+func (s *ClusterScope) CapacityReservationGroupsStatus() []infrav1.CapacityReservationGroupStatus {
+	return s.AzureCluster.Status.CapacityReservationGroups
+}
+
+// SetCapacityReservationGroupsStatus sets the observed state of the capacity reservation groups.
+func (s *ClusterScope) SetCapacityReservationGroupsStatus(statuses []infrav1.CapacityReservationGroupStatus) {
+	s.AzureCluster.Status.CapacityReservationGroups = statuses
+}
+
 // NSGSpecs returns the security group specs.
 func (s *ClusterScope) NSGSpecs() []azure.ResourceSpecGetter {
 	nsgspecs := make([]azure.ResourceSpecGetter, len(s.AzureCluster.Spec.NetworkSpec.Subnets))
@@ -722,6 +785,7 @@ func (s *ClusterScope) PatchObject(ctx context.Context) error {
 			infrav1.PrivateDNSZoneReadyCondition,
 			infrav1.PrivateDNSLinkReadyCondition,
 			infrav1.PrivateDNSRecordReadyCondition,
+			infrav1.HibernatedCondition,
 		}})
 }
 
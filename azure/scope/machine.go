@@ -23,7 +23,8 @@ import (
 	"strings"
 	"time"
 
-	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
+	azureautorest "github.com/Azure/go-autorest/autorest/azure"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
@@ -31,11 +32,13 @@ import (
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/availabilitysets"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/bootstrapdata"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/disks"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/inboundnatrules"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/networkinterfaces"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/resourceskus"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/roleassignments"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/virtualmachineimages"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/virtualmachines"
 	"sigs.k8s.io/cluster-api-provider-azure/util/futures"
 	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
@@ -94,14 +97,17 @@ type MachineScope struct {
 	Machine      *clusterv1.Machine
 	AzureMachine *infrav1.AzureMachine
 	cache        *MachineCache
+	hibernate    bool
 }
 
 // MachineCache stores common machine information so we don't have to hit the API multiple times within the same reconcile loop.
 type MachineCache struct {
-	BootstrapData      string
-	VMImage            *infrav1.Image
-	VMSKU              resourceskus.SKU
-	availabilitySetSKU resourceskus.SKU
+	BootstrapData       string
+	BootstrapDataFormat string
+	BootstrapDataLoader string
+	VMImage             *infrav1.Image
+	VMSKU               resourceskus.SKU
+	availabilitySetSKU  resourceskus.SKU
 }
 
 // InitMachineCache sets cached information about the machine to be used in the scope.
@@ -118,6 +124,11 @@ func (m *MachineScope) InitMachineCache(ctx context.Context) error {
 			return err
 		}
 
+		m.cache.BootstrapDataFormat, err = m.GetBootstrapDataFormat(ctx)
+		if err != nil {
+			return err
+		}
+
 		m.cache.VMImage, err = m.GetVMImage(ctx)
 		if err != nil {
 			return err
@@ -133,9 +144,9 @@ func (m *MachineScope) InitMachineCache(ctx context.Context) error {
 			return errors.Wrapf(err, "failed to get VM SKU %s in compute api", m.AzureMachine.Spec.VMSize)
 		}
 
-		m.cache.availabilitySetSKU, err = skuCache.Get(ctx, string(compute.AvailabilitySetSkuTypesAligned), resourceskus.AvailabilitySets)
+		m.cache.availabilitySetSKU, err = skuCache.Get(ctx, string(compute.Aligned), resourceskus.AvailabilitySets)
 		if err != nil {
-			return errors.Wrapf(err, "failed to get availability set SKU %s in compute api", string(compute.AvailabilitySetSkuTypesAligned))
+			return errors.Wrapf(err, "failed to get availability set SKU %s in compute api", string(compute.Aligned))
 		}
 	}
 
@@ -153,25 +164,111 @@ func (m *MachineScope) VMSpec() azure.ResourceSpecGetter {
 		NICIDs:                 m.NICIDs(),
 		SSHKeyData:             m.AzureMachine.Spec.SSHPublicKey,
 		Size:                   m.AzureMachine.Spec.VMSize,
+		EnableInPlaceResize:    m.AzureMachine.Spec.EnableInPlaceResize,
 		OSDisk:                 m.AzureMachine.Spec.OSDisk,
 		DataDisks:              m.AzureMachine.Spec.DataDisks,
+		DataDiskRemovalPolicy:  m.AzureMachine.Spec.DataDiskRemovalPolicy,
+		EnableUltraSSD:         m.AzureMachine.Spec.EnableUltraSSD,
+		SubscriptionID:         m.SubscriptionID(),
 		AvailabilitySetID:      m.AvailabilitySetID(),
 		Zone:                   m.AvailabilityZone(),
 		Identity:               m.AzureMachine.Spec.Identity,
 		UserAssignedIdentities: m.AzureMachine.Spec.UserAssignedIdentities,
 		SpotVMOptions:          m.AzureMachine.Spec.SpotVMOptions,
 		SecurityProfile:        m.AzureMachine.Spec.SecurityProfile,
+		Diagnostics:            m.AzureMachine.Spec.Diagnostics,
+		BootstrapDataDelivery:  m.AzureMachine.Spec.BootstrapDataDelivery,
 		AdditionalTags:         m.AdditionalTags(),
 		ProviderID:             m.ProviderID(),
 	}
+	if m.AzureMachine.Spec.CapacityReservationGroupID != nil {
+		spec.CapacityReservationGroupID = *m.AzureMachine.Spec.CapacityReservationGroupID
+	}
 	if m.cache != nil {
 		spec.SKU = m.cache.VMSKU
 		spec.Image = m.cache.VMImage
 		spec.BootstrapData = m.cache.BootstrapData
+		spec.BootstrapDataFormat = m.cache.BootstrapDataFormat
+		if m.AzureMachine.Spec.BootstrapDataDelivery == infrav1.BootstrapDataDeliveryBlob {
+			spec.BootstrapData = m.cache.BootstrapDataLoader
+		}
 	}
 	return spec
 }
 
+// BootstrapDataBlobSpec returns the spec of the blob the bootstrap data is uploaded to, or nil if the bootstrap data
+// isn't delivered from blob storage.
+func (m *MachineScope) BootstrapDataBlobSpec() *azure.BootstrapDataBlobSpec {
+	if m.AzureMachine.Spec.BootstrapDataDelivery != infrav1.BootstrapDataDeliveryBlob {
+		return nil
+	}
+
+	storage := m.AzureMachine.Spec.BootstrapDataStorage
+	if storage == nil {
+		storage = &infrav1.BootstrapDataStorage{}
+	}
+
+	spec := &azure.BootstrapDataBlobSpec{
+		ContainerName: storage.ContainerName,
+		Name:          azure.GenerateBootstrapDataBlobName(m.Namespace(), m.ClusterName(), m.Name()),
+		Access:        storage.Access,
+	}
+	if spec.ContainerName == "" {
+		spec.ContainerName = azure.DefaultBootstrapDataContainerName
+	}
+	if spec.Access == infrav1.BootstrapDataBlobAccessManagedIdentity && len(m.AzureMachine.Spec.UserAssignedIdentities) > 0 {
+		spec.IdentityID = strings.TrimPrefix(m.AzureMachine.Spec.UserAssignedIdentities[0].ProviderID, azure.ProviderIDPrefix)
+	}
+
+	if storage.StorageAccountID != nil {
+		// The storage account ID is validated by the webhook.
+		if resource, err := azureautorest.ParseResourceID(*storage.StorageAccountID); err == nil {
+			spec.StorageAccountName = resource.ResourceName
+			spec.ResourceGroup = resource.ResourceGroup
+		}
+		return spec
+	}
+
+	spec.StorageAccountName = azure.GenerateBootstrapDataStorageAccountName(m.SubscriptionID(), m.ResourceGroup(), m.ClusterName())
+	spec.ResourceGroup = m.ResourceGroup()
+	spec.StorageAccount = &bootstrapdata.StorageAccountSpec{
+		Name:           spec.StorageAccountName,
+		ResourceGroup:  spec.ResourceGroup,
+		Location:       m.Location(),
+		ClusterName:    m.ClusterName(),
+		AdditionalTags: m.ClusterScoper.AdditionalTags(),
+	}
+	return spec
+}
+
+// SetBootstrapDataLoader sets the base64 encoded loader that downloads the bootstrap data from blob storage, which is
+// passed to the VM as custom data.
+func (m *MachineScope) SetBootstrapDataLoader(loader string) {
+	if m.cache != nil {
+		m.cache.BootstrapDataLoader = loader
+	}
+}
+
+// IsBootstrapDataUploaded returns true if the bootstrap data was uploaded to blob storage and not deleted since.
+func (m *MachineScope) IsBootstrapDataUploaded() bool {
+	return conditions.Has(m.AzureMachine, infrav1.BootstrapDataReadyCondition)
+}
+
+// IsBootstrapDataReady returns true if the bootstrap data was successfully uploaded to blob storage.
+func (m *MachineScope) IsBootstrapDataReady() bool {
+	return conditions.IsTrue(m.AzureMachine, infrav1.BootstrapDataReadyCondition)
+}
+
+// SetBootstrapDataDeleted records that the bootstrap data was deleted from blob storage.
+func (m *MachineScope) SetBootstrapDataDeleted() {
+	conditions.Delete(m.AzureMachine, infrav1.BootstrapDataReadyCondition)
+}
+
+// HasNodeRef returns true if the machine has joined the cluster as a node.
+func (m *MachineScope) HasNodeRef() bool {
+	return m.Machine.Status.NodeRef != nil
+}
+
 // TagsSpecs returns the tags for the AzureMachine.
 func (m *MachineScope) TagsSpecs() []azure.TagsSpec {
 	return []azure.TagsSpec{
@@ -271,23 +368,67 @@ func (m *MachineScope) NICIDs() []string {
 	return nicIDs
 }
 
-// DiskSpecs returns the disk specs.
+// DiskSpecs returns the disk specs. Existing disks attached to the machine are never deleted, so they are not included.
 func (m *MachineScope) DiskSpecs() []azure.ResourceSpecGetter {
-	diskSpecs := make([]azure.ResourceSpecGetter, 1+len(m.AzureMachine.Spec.DataDisks))
+	diskSpecs := make([]azure.ResourceSpecGetter, 1, 1+len(m.AzureMachine.Spec.DataDisks))
 	diskSpecs[0] = &disks.DiskSpec{
 		Name:          azure.GenerateOSDiskName(m.Name()),
 		ResourceGroup: m.ResourceGroup(),
 	}
 
-	for i, dd := range m.AzureMachine.Spec.DataDisks {
-		diskSpecs[i+1] = &disks.DiskSpec{
+	for _, dd := range m.AzureMachine.Spec.DataDisks {
+		if dd.SourceDiskID != nil {
+			continue
+		}
+		diskSpecs = append(diskSpecs, &disks.DiskSpec{
 			Name:          azure.GenerateDataDiskName(m.Name(), dd.NameSuffix),
 			ResourceGroup: m.ResourceGroup(),
+		})
+	}
+	return diskSpecs
+}
+
+// DataDiskSpecs returns the specs of the data disks created for the machine.
+func (m *MachineScope) DataDiskSpecs() []azure.ResourceSpecGetter {
+	diskSpecs := make([]azure.ResourceSpecGetter, 0, len(m.AzureMachine.Spec.DataDisks))
+	for _, dd := range m.AzureMachine.Spec.DataDisks {
+		if dd.SourceDiskID != nil {
+			continue
+		}
+		spec := &disks.DataDiskSpec{
+			Name:              azure.GenerateDataDiskName(m.Name(), dd.NameSuffix),
+			ResourceGroup:     m.ResourceGroup(),
+			Location:          m.Location(),
+			Zone:              m.AvailabilityZone(),
+			ClusterName:       m.ClusterName(),
+			AdditionalTags:    m.AdditionalTags(),
+			DiskSizeGB:        dd.DiskSizeGB,
+			DiskIOPSReadWrite: dd.DiskIOPSReadWrite,
+			DiskMBpsReadWrite: dd.DiskMBpsReadWrite,
+			MaxShares:         dd.MaxShares,
+			Standalone:        disks.IsStandaloneDataDisk(dd),
+		}
+		if dd.ManagedDisk != nil {
+			spec.StorageAccountType = dd.ManagedDisk.StorageAccountType
+			if dd.ManagedDisk.DiskEncryptionSet != nil {
+				spec.DiskEncryptionSetID = dd.ManagedDisk.DiskEncryptionSet.ID
+			}
 		}
+		diskSpecs = append(diskSpecs, spec)
 	}
 	return diskSpecs
 }
 
+// RemovedDataDisks returns the names of the removed data disks that are deleted once they are detached.
+func (m *MachineScope) RemovedDataDisks() []string {
+	return m.AzureMachine.Status.RemovedDataDisks
+}
+
+// SetRemovedDataDisks sets the names of the removed data disks that are deleted once they are detached.
+func (m *MachineScope) SetRemovedDataDisks(names []string) {
+	m.AzureMachine.Status.RemovedDataDisks = names
+}
+
 // RoleAssignmentSpecs returns the role assignment specs.
 func (m *MachineScope) RoleAssignmentSpecs(principalID *string) []azure.ResourceSpecGetter {
 	roles := make([]azure.ResourceSpecGetter, 1)
@@ -379,6 +520,40 @@ func (m *MachineScope) IsControlPlane() bool {
 	return util.IsControlPlaneMachine(m.Machine)
 }
 
+// SetHibernate sets whether the VM should be deallocated because its cluster is hibernated.
+func (m *MachineScope) SetHibernate(hibernate bool) {
+	m.hibernate = hibernate
+}
+
+// ShouldHibernate returns true if the VM should be deallocated because its cluster is hibernated.
+func (m *MachineScope) ShouldHibernate() bool {
+	return m.hibernate
+}
+
+// IsHibernated returns true if the VM has been deallocated to hibernate its cluster and has not been resumed yet.
+func (m *MachineScope) IsHibernated() bool {
+	return conditions.Has(m.AzureMachine, infrav1.HibernatedCondition)
+}
+
+// UpdateHibernationStatus updates the Hibernated condition of the AzureMachine after the VM has been deallocated or
+// started to hibernate or resume its cluster. The condition is removed once the VM has been resumed.
+func (m *MachineScope) UpdateHibernationStatus(err error) {
+	reason := infrav1.ResumingReason
+	if m.hibernate {
+		reason = infrav1.HibernatingReason
+	}
+	switch {
+	case err == nil && m.hibernate:
+		conditions.MarkTrue(m.AzureMachine, infrav1.HibernatedCondition)
+	case err == nil:
+		conditions.Delete(m.AzureMachine, infrav1.HibernatedCondition)
+	case azure.IsOperationNotDoneError(err):
+		conditions.MarkFalse(m.AzureMachine, infrav1.HibernatedCondition, reason, clusterv1.ConditionSeverityInfo, "")
+	default:
+		conditions.MarkFalse(m.AzureMachine, infrav1.HibernatedCondition, reason, clusterv1.ConditionSeverityError, "%s", err.Error())
+	}
+}
+
 // Role returns the machine role from the labels.
 func (m *MachineScope) Role() string {
 	if util.IsControlPlaneMachine(m.Machine) {
@@ -521,6 +696,12 @@ func (m *MachineScope) SetBootstrapConditions(ctx context.Context, provisioningS
 	}
 }
 
+// SetBootstrapFailureLog adds the tail of the serial console log of the VM to the message of the failed
+// BootstrapSucceeded condition.
+func (m *MachineScope) SetBootstrapFailureLog(serialConsoleLog string) {
+	conditions.MarkFalse(m.AzureMachine, infrav1.BootstrapSucceededCondition, infrav1.BootstrapFailedReason, clusterv1.ConditionSeverityError, "serial console log tail:\n%s", serialConsoleLog)
+}
+
 // SetAnnotation sets a key value annotation on the AzureMachine.
 func (m *MachineScope) SetAnnotation(key, value string) {
 	if m.AzureMachine.Annotations == nil {
@@ -529,6 +710,16 @@ func (m *MachineScope) SetAnnotation(key, value string) {
 	m.AzureMachine.Annotations[key] = value
 }
 
+// Annotation returns the value of an annotation on the AzureMachine.
+func (m *MachineScope) Annotation(key string) string {
+	return m.AzureMachine.GetAnnotations()[key]
+}
+
+// RemoveAnnotation removes an annotation from the AzureMachine.
+func (m *MachineScope) RemoveAnnotation(key string) {
+	delete(m.AzureMachine.Annotations, key)
+}
+
 // AnnotationJSON returns a map[string]interface from a JSON annotation.
 func (m *MachineScope) AnnotationJSON(annotation string) (map[string]interface{}, error) {
 	out := map[string]interface{}{}
@@ -571,8 +762,12 @@ func (m *MachineScope) PatchObject(ctx context.Context) error {
 		patch.WithOwnedConditions{Conditions: []clusterv1.ConditionType{
 			clusterv1.ReadyCondition,
 			infrav1.VMRunningCondition,
+			infrav1.VMResizedCondition,
+			infrav1.VMActionCondition,
+			infrav1.HibernatedCondition,
 			infrav1.AvailabilitySetReadyCondition,
 			infrav1.NetworkInterfaceReadyCondition,
+			infrav1.BootstrapDataReadyCondition,
 		}})
 }
 
@@ -600,13 +795,9 @@ func (m *MachineScope) GetBootstrapData(ctx context.Context) (string, error) {
 	ctx, _, done := tele.StartSpanWithLogger(ctx, "scope.MachineScope.GetBootstrapData")
 	defer done()
 
-	if m.Machine.Spec.Bootstrap.DataSecretName == nil {
-		return "", errors.New("error retrieving bootstrap data: linked Machine's bootstrap.dataSecretName is nil")
-	}
-	secret := &corev1.Secret{}
-	key := types.NamespacedName{Namespace: m.Namespace(), Name: *m.Machine.Spec.Bootstrap.DataSecretName}
-	if err := m.client.Get(ctx, key, secret); err != nil {
-		return "", errors.Wrapf(err, "failed to retrieve bootstrap data secret for AzureMachine %s/%s", m.Namespace(), m.Name())
+	secret, err := m.getBootstrapDataSecret(ctx)
+	if err != nil {
+		return "", err
 	}
 
 	value, ok := secret.Data["value"]
@@ -616,6 +807,37 @@ func (m *MachineScope) GetBootstrapData(ctx context.Context) (string, error) {
 	return base64.StdEncoding.EncodeToString(value), nil
 }
 
+// GetBootstrapDataFormat returns the format of the bootstrap data from the secret in the Machine's
+// bootstrap.dataSecretName, which is cloud-config unless the bootstrap provider set it to ignition.
+func (m *MachineScope) GetBootstrapDataFormat(ctx context.Context) (string, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "scope.MachineScope.GetBootstrapDataFormat")
+	defer done()
+
+	secret, err := m.getBootstrapDataSecret(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	format, ok := secret.Data["format"]
+	if !ok || len(format) == 0 {
+		return azure.CloudConfigBootstrapDataFormat, nil
+	}
+	return string(format), nil
+}
+
+// getBootstrapDataSecret returns the secret in the Machine's bootstrap.dataSecretName.
+func (m *MachineScope) getBootstrapDataSecret(ctx context.Context) (*corev1.Secret, error) {
+	if m.Machine.Spec.Bootstrap.DataSecretName == nil {
+		return nil, errors.New("error retrieving bootstrap data: linked Machine's bootstrap.dataSecretName is nil")
+	}
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Namespace: m.Namespace(), Name: *m.Machine.Spec.Bootstrap.DataSecretName}
+	if err := m.client.Get(ctx, key, secret); err != nil {
+		return nil, errors.Wrapf(err, "failed to retrieve bootstrap data secret for AzureMachine %s/%s", m.Namespace(), m.Name())
+	}
+	return secret, nil
+}
+
 // GetVMImage returns the image from the machine configuration, or a default one.
 func (m *MachineScope) GetVMImage(ctx context.Context) (*infrav1.Image, error) {
 	_, log, done := tele.StartSpanWithLogger(ctx, "scope.MachineScope.GetVMImage")
@@ -623,18 +845,62 @@ func (m *MachineScope) GetVMImage(ctx context.Context) (*infrav1.Image, error) {
 
 	// Use custom Marketplace image, Image ID or a Shared Image Gallery image if provided
 	if m.AzureMachine.Spec.Image != nil {
-		return m.AzureMachine.Spec.Image, nil
+		return m.resolveVMImage(ctx, m.AzureMachine.Spec.Image)
 	}
 
 	if m.AzureMachine.Spec.OSDisk.OSType == azure.WindowsOS {
 		runtime := m.AzureMachine.Annotations["runtime"]
 		windowsServerVersion := m.AzureMachine.Annotations["windowsServerVersion"]
 		log.Info("No image specified for machine, using default Windows Image", "machine", m.AzureMachine.GetName(), "runtime", runtime, "windowsServerVersion", windowsServerVersion)
-		return azure.GetDefaultWindowsImage(to.String(m.Machine.Spec.Version), runtime, windowsServerVersion)
+		return m.getDefaultVMImage(ctx, azure.WindowsOS, "", runtime, windowsServerVersion)
+	}
+
+	if m.cache != nil && m.cache.BootstrapDataFormat == azure.IgnitionBootstrapDataFormat {
+		log.Info("No image specified for machine bootstrapped with Ignition, using default Flatcar Image", "machine", m.AzureMachine.GetName())
+		return m.getDefaultVMImage(ctx, azure.LinuxOS, azure.FlatcarFlavor, "", "")
 	}
 
 	log.Info("No image specified for machine, using default Linux Image", "machine", m.AzureMachine.GetName())
-	return azure.GetDefaultUbuntuImage(to.String(m.Machine.Spec.Version))
+	return m.getDefaultVMImage(ctx, azure.LinuxOS, "", "", "")
+}
+
+// getDefaultVMImage returns the default image for the Kubernetes version of the machine. A version range, which the
+// default image resolver may return, is resolved like the image of the AzureMachine spec.
+func (m *MachineScope) getDefaultVMImage(ctx context.Context, osType, flavor, runtime, windowsServerVersion string) (*infrav1.Image, error) {
+	image, err := azure.GetDefaultImage(to.String(m.Machine.Spec.Version), osType, flavor, runtime, windowsServerVersion)
+	if err != nil {
+		return nil, err
+	}
+	if !virtualmachineimages.IsVersionRange(image) {
+		return image, nil
+	}
+	return m.resolveVMImage(ctx, image)
+}
+
+// resolveVMImage resolves the 'latest' or version range of an image to the exact version of the latest matching
+// image, and records it in the AzureMachine status so that the same version is used until the VM is created.
+func (m *MachineScope) resolveVMImage(ctx context.Context, image *infrav1.Image) (*infrav1.Image, error) {
+	if !virtualmachineimages.NeedsResolution(image) {
+		return image, nil
+	}
+	if virtualmachineimages.IsResolvedFrom(m.AzureMachine.Status.Image, image) {
+		return m.AzureMachine.Status.Image, nil
+	}
+	// The VM was created before its image was recorded, and its image can't change anymore.
+	if m.ProviderID() != "" {
+		return image, nil
+	}
+
+	resolver, err := virtualmachineimages.GetResolver(m, m.Location())
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := resolver.Resolve(ctx, image)
+	if err != nil {
+		return nil, err
+	}
+	m.AzureMachine.Status.Image = resolved
+	return resolved, nil
 }
 
 // SetSubnetName defaults the AzureMachine subnet name to the name of one the subnets with the machine role when there is only one of them.
@@ -27,16 +27,20 @@ import (
 	"github.com/Azure/go-autorest/autorest/azure/auth"
 	"github.com/Azure/go-autorest/autorest/to"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/bootstrapdata"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/disks"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/inboundnatrules"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/networkinterfaces"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/resourceskus"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/roleassignments"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/virtualmachines"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
 )
 
 func specArrayToString(specs []azure.ResourceSpecGetter) string {
@@ -1166,6 +1170,75 @@ func TestMachineScope_GetVMImage(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "returns the AzureMachine image pinned in the status if the latest image version is specified",
+			machineScope: MachineScope{
+				AzureMachine: &infrav1.AzureMachine{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "machine-name",
+					},
+					Spec: infrav1.AzureMachineSpec{
+						Image: &infrav1.Image{
+							Marketplace: &infrav1.AzureMarketplaceImage{
+								Publisher: "cncf-upstream",
+								Offer:     "capi",
+								SKU:       "k8s-1dot22dot6-ubuntu-2004",
+								Version:   "latest",
+							},
+						},
+					},
+					Status: infrav1.AzureMachineStatus{
+						Image: &infrav1.Image{
+							Marketplace: &infrav1.AzureMarketplaceImage{
+								Publisher: "cncf-upstream",
+								Offer:     "capi",
+								SKU:       "k8s-1dot22dot6-ubuntu-2004",
+								Version:   "2022.01.05",
+							},
+						},
+					},
+				},
+			},
+			want: &infrav1.Image{
+				Marketplace: &infrav1.AzureMarketplaceImage{
+					Publisher: "cncf-upstream",
+					Offer:     "capi",
+					SKU:       "k8s-1dot22dot6-ubuntu-2004",
+					Version:   "2022.01.05",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "returns the AzureMachine image as is if the VM was created before its image version was pinned",
+			machineScope: MachineScope{
+				AzureMachine: &infrav1.AzureMachine{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "machine-name",
+					},
+					Spec: infrav1.AzureMachineSpec{
+						ProviderID: pointer.StringPtr("azure:///subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/virtualMachines/machine-name"),
+						Image: &infrav1.Image{
+							Marketplace: &infrav1.AzureMarketplaceImage{
+								Publisher: "cncf-upstream",
+								Offer:     "capi",
+								SKU:       "k8s-1dot22dot6-ubuntu-2004",
+								Version:   "latest",
+							},
+						},
+					},
+				},
+			},
+			want: &infrav1.Image{
+				Marketplace: &infrav1.AzureMarketplaceImage{
+					Publisher: "cncf-upstream",
+					Offer:     "capi",
+					SKU:       "k8s-1dot22dot6-ubuntu-2004",
+					Version:   "latest",
+				},
+			},
+			wantErr: false,
+		},
 		{
 			name: "if no image is specified and os specified is windows with version below 1.22, returns windows dockershim image",
 			machineScope: MachineScope{
@@ -1397,6 +1470,29 @@ func TestMachineScope_GetVMImage(t *testing.T) {
 			}(),
 			wantErr: false,
 		},
+		{
+			name: "if no image is specified and the bootstrap data is ignition, returns flatcar image",
+			machineScope: MachineScope{
+				Machine: &clusterv1.Machine{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "machine-name",
+					},
+					Spec: clusterv1.MachineSpec{
+						Version: pointer.String("1.22.1"),
+					},
+				},
+				AzureMachine: &infrav1.AzureMachine{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "machine-name",
+					},
+				},
+				cache: &MachineCache{
+					BootstrapDataFormat: azure.IgnitionBootstrapDataFormat,
+				},
+			},
+			want:    azure.GetDefaultFlatcarImage(),
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1416,6 +1512,47 @@ func TestMachineScope_GetVMImage(t *testing.T) {
 	}
 }
 
+func TestMachineScope_GetVMImageWithDefaultImagesConfig(t *testing.T) {
+	g := NewWithT(t)
+	flatcarImage := infrav1.Image{ID: pointer.String("/subscriptions/123/resourceGroups/images/providers/Microsoft.Compute/images/flatcar")}
+	infrav1.SetDefaultImageResolver(&azure.DefaultImagesConfig{
+		Images: []azure.DefaultImage{
+			{
+				Flavor: azure.FlatcarFlavor,
+				Image:  flatcarImage,
+			},
+		},
+	})
+	defer infrav1.SetDefaultImageResolver(nil)
+
+	machineScope := MachineScope{
+		Machine: &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "machine-name",
+			},
+			Spec: clusterv1.MachineSpec{
+				Version: pointer.String("1.22.1"),
+			},
+		},
+		AzureMachine: &infrav1.AzureMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "machine-name",
+			},
+		},
+		cache: &MachineCache{
+			BootstrapDataFormat: azure.IgnitionBootstrapDataFormat,
+		},
+	}
+	image, err := machineScope.GetVMImage(context.TODO())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(image).To(Equal(&flatcarImage))
+
+	machineScope.cache.BootstrapDataFormat = azure.CloudConfigBootstrapDataFormat
+	image, err = machineScope.GetVMImage(context.TODO())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(image.Marketplace.Publisher).To(Equal(azure.DefaultImagePublisherID))
+}
+
 func TestMachineScope_NICSpecs(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -2180,6 +2317,60 @@ func TestDiskSpecs(t *testing.T) {
 					ResourceGroup: "my-rg",
 				},
 			},
+		}, {
+			name: "os and existing data disks",
+			machineScope: MachineScope{
+				ClusterScoper: &ClusterScope{
+					Cluster: &clusterv1.Cluster{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "cluster",
+						},
+					},
+					AzureCluster: &infrav1.AzureCluster{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "cluster",
+						},
+						Spec: infrav1.AzureClusterSpec{
+							ResourceGroup: "my-rg",
+						},
+					},
+				},
+				AzureMachine: &infrav1.AzureMachine{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "my-azure-machine",
+					},
+					Spec: infrav1.AzureMachineSpec{
+						OSDisk: infrav1.OSDisk{
+							DiskSizeGB: to.Int32Ptr(30),
+							OSType:     "Linux",
+						},
+						DataDisks: []infrav1.DataDisk{
+							{
+								NameSuffix: "etcddisk",
+							},
+							{
+								NameSuffix:   "existingdisk",
+								SourceDiskID: to.StringPtr("/subscriptions/123/resourceGroups/other-rg/providers/Microsoft.Compute/disks/my-existing-disk"),
+							},
+						},
+					},
+				},
+				Machine: &clusterv1.Machine{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "machine",
+					},
+				},
+			},
+			want: []azure.ResourceSpecGetter{
+				&disks.DiskSpec{
+					Name:          "my-azure-machine_OSDisk",
+					ResourceGroup: "my-rg",
+				},
+				&disks.DiskSpec{
+					Name:          "my-azure-machine_etcddisk",
+					ResourceGroup: "my-rg",
+				},
+			},
 		},
 	}
 
@@ -2194,3 +2385,187 @@ func TestDiskSpecs(t *testing.T) {
 		})
 	}
 }
+
+func TestMachineScope_UpdateHibernationStatus(t *testing.T) {
+	tests := []struct {
+		name         string
+		hibernate    bool
+		err          error
+		wantStatus   corev1.ConditionStatus
+		wantReason   string
+		wantSeverity clusterv1.ConditionSeverity
+	}{
+		{
+			name:       "VM is deallocated",
+			hibernate:  true,
+			wantStatus: corev1.ConditionTrue,
+		},
+		{
+			name: "VM is started",
+		},
+		{
+			name:         "VM is being deallocated",
+			hibernate:    true,
+			err:          azure.NewOperationNotDoneError(&infrav1.Future{}),
+			wantStatus:   corev1.ConditionFalse,
+			wantReason:   infrav1.HibernatingReason,
+			wantSeverity: clusterv1.ConditionSeverityInfo,
+		},
+		{
+			name:         "VM failed to start",
+			err:          fmt.Errorf("boom"),
+			wantStatus:   corev1.ConditionFalse,
+			wantReason:   infrav1.ResumingReason,
+			wantSeverity: clusterv1.ConditionSeverityError,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			m := &MachineScope{
+				AzureMachine: &infrav1.AzureMachine{
+					Status: infrav1.AzureMachineStatus{
+						Conditions: clusterv1.Conditions{*conditions.FalseCondition(infrav1.HibernatedCondition, infrav1.ResumingReason, clusterv1.ConditionSeverityInfo, "")},
+					},
+				},
+			}
+			m.SetHibernate(tt.hibernate)
+			m.UpdateHibernationStatus(tt.err)
+
+			condition := conditions.Get(m.AzureMachine, infrav1.HibernatedCondition)
+			if tt.wantStatus == "" {
+				g.Expect(condition).To(BeNil())
+				g.Expect(m.IsHibernated()).To(BeFalse())
+				return
+			}
+			g.Expect(condition).NotTo(BeNil())
+			g.Expect(condition.Status).To(Equal(tt.wantStatus))
+			g.Expect(condition.Reason).To(Equal(tt.wantReason))
+			g.Expect(condition.Severity).To(Equal(tt.wantSeverity))
+			g.Expect(m.IsHibernated()).To(BeTrue())
+		})
+	}
+}
+
+func TestMachineScope_BootstrapDataBlobSpec(t *testing.T) {
+	clusterScope := &ClusterScope{
+		Cluster: &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "cluster",
+			},
+		},
+		AzureCluster: &infrav1.AzureCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "cluster",
+			},
+			Spec: infrav1.AzureClusterSpec{
+				ResourceGroup: "my-rg",
+				AzureClusterClassSpec: infrav1.AzureClusterClassSpec{
+					Location: "westus",
+				},
+			},
+		},
+	}
+	storageAccountName := azure.GenerateBootstrapDataStorageAccountName("", "my-rg", "cluster")
+
+	tests := []struct {
+		name string
+		spec infrav1.AzureMachineSpec
+		want *azure.BootstrapDataBlobSpec
+	}{
+		{
+			name: "bootstrap data passed as custom data",
+			spec: infrav1.AzureMachineSpec{},
+			want: nil,
+		},
+		{
+			name: "bootstrap data uploaded to the storage account of the cluster",
+			spec: infrav1.AzureMachineSpec{
+				BootstrapDataDelivery: infrav1.BootstrapDataDeliveryBlob,
+			},
+			want: &azure.BootstrapDataBlobSpec{
+				StorageAccount: &bootstrapdata.StorageAccountSpec{
+					Name:           storageAccountName,
+					ResourceGroup:  "my-rg",
+					Location:       "westus",
+					ClusterName:    "cluster",
+					AdditionalTags: infrav1.Tags{},
+				},
+				StorageAccountName: storageAccountName,
+				ResourceGroup:      "my-rg",
+				ContainerName:      "bootstrap-data",
+				Name:               "default/cluster/my-azure-machine",
+			},
+		},
+		{
+			name: "bootstrap data uploaded to an existing storage account with a managed identity",
+			spec: infrav1.AzureMachineSpec{
+				BootstrapDataDelivery: infrav1.BootstrapDataDeliveryBlob,
+				BootstrapDataStorage: &infrav1.BootstrapDataStorage{
+					StorageAccountID: to.StringPtr("/subscriptions/123/resourceGroups/storage-rg/providers/Microsoft.Storage/storageAccounts/mystorage"),
+					ContainerName:    "my-container",
+					Access:           infrav1.BootstrapDataBlobAccessManagedIdentity,
+				},
+				Identity: infrav1.VMIdentityUserAssigned,
+				UserAssignedIdentities: []infrav1.UserAssignedIdentity{
+					{ProviderID: "azure:///subscriptions/123/resourceGroups/my-rg/providers/Microsoft.ManagedIdentity/userAssignedIdentities/my-identity"},
+				},
+			},
+			want: &azure.BootstrapDataBlobSpec{
+				StorageAccountName: "mystorage",
+				ResourceGroup:      "storage-rg",
+				ContainerName:      "my-container",
+				Name:               "default/cluster/my-azure-machine",
+				Access:             infrav1.BootstrapDataBlobAccessManagedIdentity,
+				IdentityID:         "/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.ManagedIdentity/userAssignedIdentities/my-identity",
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			m := &MachineScope{
+				ClusterScoper: clusterScope,
+				AzureMachine: &infrav1.AzureMachine{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "my-azure-machine",
+						Namespace: "default",
+					},
+					Spec: tt.spec,
+				},
+			}
+			g.Expect(m.BootstrapDataBlobSpec()).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestMachineScope_BootstrapDataLoader(t *testing.T) {
+	g := NewWithT(t)
+	m := &MachineScope{
+		ClusterScoper: &ClusterScope{
+			Cluster:      &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}},
+			AzureCluster: &infrav1.AzureCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}},
+		},
+		Machine: &clusterv1.Machine{},
+		AzureMachine: &infrav1.AzureMachine{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-azure-machine"},
+			Spec: infrav1.AzureMachineSpec{
+				BootstrapDataDelivery: infrav1.BootstrapDataDeliveryBlob,
+			},
+		},
+		cache: &MachineCache{
+			BootstrapData: "large-bootstrap-data",
+		},
+	}
+
+	m.SetBootstrapDataLoader("loader")
+	g.Expect(m.VMSpec().(*virtualmachines.VMSpec).BootstrapData).To(Equal("loader"))
+
+	g.Expect(m.IsBootstrapDataUploaded()).To(BeFalse())
+	m.UpdatePutStatus(infrav1.BootstrapDataReadyCondition, "bootstrapdata", nil)
+	g.Expect(m.IsBootstrapDataUploaded()).To(BeTrue())
+	m.SetBootstrapDataDeleted()
+	g.Expect(m.IsBootstrapDataUploaded()).To(BeFalse())
+}
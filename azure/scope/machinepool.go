@@ -19,6 +19,8 @@ package scope
 import (
 	"context"
 	"encoding/base64"
+	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -32,6 +34,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
 	machinepool "sigs.k8s.io/cluster-api-provider-azure/azure/scope/strategies/machinepool_deployments"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/roleassignments"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/virtualmachineimages"
 	infrav1exp "sigs.k8s.io/cluster-api-provider-azure/exp/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/util/futures"
 	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
@@ -39,6 +42,7 @@ import (
 	"sigs.k8s.io/cluster-api/controllers/noderefutil"
 	capierrors "sigs.k8s.io/cluster-api/errors"
 	capiv1exp "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/annotations"
 	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -50,6 +54,10 @@ import (
 // added here to avoid a circular dependency.
 const ScalesetsServiceName = "scalesets"
 
+// greenScaleSetSuffix is the suffix of the name of the scale set alternating with the default scale set of a machine
+// pool in blue/green deployments.
+const greenScaleSetSuffix = "green"
+
 type (
 	// MachinePoolScopeParams defines the input parameters used to create a new MachinePoolScope.
 	MachinePoolScopeParams struct {
@@ -67,6 +75,8 @@ type (
 		client           client.Client
 		patchHelper      *patch.Helper
 		vmssState        *azure.VMSS
+		deploymentStates map[string]*azure.VMSS
+		hibernate        bool
 	}
 
 	// NodeStatus represents the status of a Kubernetes node.
@@ -110,7 +120,7 @@ func (m *MachinePoolScope) ScaleSetSpec() azure.ScaleSetSpec {
 	return azure.ScaleSetSpec{
 		Name:                         m.Name(),
 		Size:                         m.AzureMachinePool.Spec.Template.VMSize,
-		Capacity:                     int64(to.Int32(m.MachinePool.Spec.Replicas)),
+		Capacity:                     int64(m.DesiredReplicas()),
 		SSHKeyData:                   m.AzureMachinePool.Spec.Template.SSHPublicKey,
 		OSDisk:                       m.AzureMachinePool.Spec.Template.OSDisk,
 		DataDisks:                    m.AzureMachinePool.Spec.Template.DataDisks,
@@ -123,14 +133,107 @@ func (m *MachinePoolScope) ScaleSetSpec() azure.ScaleSetSpec {
 		Identity:                     m.AzureMachinePool.Spec.Identity,
 		UserAssignedIdentities:       m.AzureMachinePool.Spec.UserAssignedIdentities,
 		SecurityProfile:              m.AzureMachinePool.Spec.Template.SecurityProfile,
+		Diagnostics:                  m.AzureMachinePool.Spec.Template.Diagnostics,
+		BootstrapDataDelivery:        m.AzureMachinePool.Spec.Template.BootstrapDataDelivery,
 		SpotVMOptions:                m.AzureMachinePool.Spec.Template.SpotVMOptions,
 		FailureDomains:               m.MachinePool.Spec.FailureDomains,
 		TerminateNotificationTimeout: m.AzureMachinePool.Spec.Template.TerminateNotificationTimeout,
+		CapacityReservationGroupID:   to.String(m.AzureMachinePool.Spec.Template.CapacityReservationGroupID),
+		ApplicationHealth:            m.applicationHealthSpec(),
+		AutomaticRepairs:             m.automaticRepairsSpec(),
+		OrchestrationMode:            m.AzureMachinePool.GetOrchestrationMode(),
+		UpgradePolicy:                m.upgradePolicySpec(),
+		PriorityMixPolicy:            m.priorityMixPolicySpec(),
+	}
+}
+
+// applicationHealthSpec returns the spec of the endpoint probed by the Application Health extension, or nil if the
+// application health of the instances isn't monitored.
+func (m *MachinePoolScope) applicationHealthSpec() *azure.ApplicationHealthSpec {
+	health := m.AzureMachinePool.Spec.ApplicationHealth
+	if health == nil {
+		return nil
+	}
+	return &azure.ApplicationHealthSpec{
+		Protocol:    string(health.Protocol),
+		Port:        health.Port,
+		RequestPath: health.RequestPath,
+	}
+}
+
+// automaticRepairsSpec returns the spec of the automatic repairs policy, or nil if unhealthy instances aren't
+// repaired.
+func (m *MachinePoolScope) automaticRepairsSpec() *azure.AutomaticRepairsSpec {
+	health := m.AzureMachinePool.Spec.ApplicationHealth
+	if health == nil || health.AutomaticRepairs == nil {
+		return nil
+	}
+	repairs := &azure.AutomaticRepairsSpec{
+		RepairAction: string(health.AutomaticRepairs.RepairAction),
+	}
+	if health.AutomaticRepairs.GracePeriod != nil {
+		repairs.GracePeriod = fmt.Sprintf("PT%dM", int(health.AutomaticRepairs.GracePeriod.Minutes()))
+	}
+	return repairs
+}
+
+// upgradePolicySpec returns the spec of the upgrade policy, or nil if the instances aren't upgraded by the platform.
+func (m *MachinePoolScope) upgradePolicySpec() *azure.UpgradePolicySpec {
+	if !m.AzureMachinePool.HasPlatformManagedUpgrades() {
+		return nil
+	}
+	policy := m.AzureMachinePool.Spec.UpgradePolicy
+	spec := &azure.UpgradePolicySpec{
+		Mode:               string(policy.Mode),
+		AutomaticOSUpgrade: policy.AutomaticOSUpgrade,
+	}
+	if spec.Mode == "" {
+		spec.Mode = string(infrav1exp.ManualUpgradeMode)
+	}
+	if rolling := policy.RollingUpgrade; rolling != nil {
+		spec.RollingUpgrade = &azure.RollingUpgradePolicySpec{
+			MaxBatchInstancePercent:             rolling.MaxBatchInstancePercent,
+			MaxUnhealthyInstancePercent:         rolling.MaxUnhealthyInstancePercent,
+			MaxUnhealthyUpgradedInstancePercent: rolling.MaxUnhealthyUpgradedInstancePercent,
+		}
+		if rolling.PauseTimeBetweenBatches != nil {
+			spec.RollingUpgrade.PauseTimeBetweenBatches = fmt.Sprintf("PT%dS", int(rolling.PauseTimeBetweenBatches.Seconds()))
+		}
 	}
+	return spec
 }
 
-// Name returns the Azure Machine Pool Name.
+// priorityMixPolicySpec returns the spec of the mix of regular and Spot instances, or nil if all the instances have
+// the same priority.
+func (m *MachinePoolScope) priorityMixPolicySpec() *azure.PriorityMixPolicySpec {
+	mix := m.AzureMachinePool.Spec.PriorityMixPolicy
+	if mix == nil {
+		return nil
+	}
+	spec := &azure.PriorityMixPolicySpec{
+		BaseRegularPriorityCount: mix.BaseRegularPriorityCount,
+		EvictionPolicy:           string(mix.EvictionPolicy),
+	}
+	if mix.SpotPercentageAboveBase != nil {
+		// the platform takes the percentage of regular instances rather than Spot instances
+		spec.RegularPriorityPercentageAboveBase = to.Int32Ptr(100 - *mix.SpotPercentageAboveBase)
+	}
+	if spec.EvictionPolicy == "" {
+		spec.EvictionPolicy = string(infrav1exp.DeallocateSpotEvictionPolicy)
+	}
+	return spec
+}
+
+// Name returns the name of the active scale set of the Azure Machine Pool.
 func (m *MachinePoolScope) Name() string {
+	if status := m.AzureMachinePool.Status.BlueGreen; status != nil && status.ActiveScaleSetName != "" {
+		return status.ActiveScaleSetName
+	}
+	return m.defaultScaleSetName()
+}
+
+// defaultScaleSetName returns the name of the scale set of the Azure Machine Pool before any blue/green deployment.
+func (m *MachinePoolScope) defaultScaleSetName() string {
 	// Windows Machine pools names cannot be longer than 9 chars
 	if m.AzureMachinePool.Spec.Template.OSDisk.OSType == azure.WindowsOS && len(m.AzureMachinePool.Name) > 9 {
 		return "win-" + m.AzureMachinePool.Name[len(m.AzureMachinePool.Name)-5:]
@@ -138,6 +241,81 @@ func (m *MachinePoolScope) Name() string {
 	return m.AzureMachinePool.Name
 }
 
+// alternateScaleSetName returns the name of the scale set replacing the scale set named name in a blue/green
+// deployment. Deployments alternate between the default scale set name and a "green" one.
+func (m *MachinePoolScope) alternateScaleSetName(name string) string {
+	if name != m.defaultScaleSetName() {
+		return m.defaultScaleSetName()
+	}
+	// Windows Machine pools names cannot be longer than 9 chars
+	if m.AzureMachinePool.Spec.Template.OSDisk.OSType == azure.WindowsOS {
+		suffix := m.AzureMachinePool.Name
+		if len(suffix) > 6 {
+			suffix = suffix[len(suffix)-6:]
+		}
+		return "wg-" + suffix
+	}
+	return m.AzureMachinePool.Name + "-" + greenScaleSetSuffix
+}
+
+// NewScaleSetSpec returns the spec of the scale set being deployed with the latest model, or nil if no blue/green
+// deployment is in progress.
+func (m *MachinePoolScope) NewScaleSetSpec() *azure.ScaleSetSpec {
+	status := m.AzureMachinePool.Status.BlueGreen
+	if status == nil || status.NewScaleSetName == "" {
+		return nil
+	}
+	spec := m.ScaleSetSpec()
+	spec.Name = status.NewScaleSetName
+	return &spec
+}
+
+// RetiredScaleSetName returns the name of the scale set to delete once its instances are drained and deleted, or an
+// empty string if there is none.
+func (m *MachinePoolScope) RetiredScaleSetName() string {
+	if status := m.AzureMachinePool.Status.BlueGreen; status != nil {
+		return status.RetiredScaleSetName
+	}
+	return ""
+}
+
+// SetRetiredScaleSetDeleted records that the retired scale set was deleted, which completes the blue/green deployment.
+func (m *MachinePoolScope) SetRetiredScaleSetDeleted() {
+	if status := m.AzureMachinePool.Status.BlueGreen; status != nil {
+		status.RetiredScaleSetName = ""
+		status.StartTime = nil
+	}
+	if conditions.GetReason(m.AzureMachinePool, infrav1.ScaleSetDeploymentCondition) != infrav1.ScaleSetRolledBackReason {
+		conditions.Delete(m.AzureMachinePool, infrav1.ScaleSetDeploymentCondition)
+	}
+}
+
+// StartScaleSetDeployment starts the blue/green deployment of a new scale set with the latest model of the machine pool,
+// and returns false if its deployment strategy updates the model of the active scale set instead. No deployment is
+// started while another one is in progress, or if the deployment of the current generation of the machine pool was
+// rolled back.
+func (m *MachinePoolScope) StartScaleSetDeployment() bool {
+	if _, ok := m.getDeploymentStrategy().(machinepool.ScaleSetDeployer); !ok {
+		return false
+	}
+
+	status := m.AzureMachinePool.Status.BlueGreen
+	if status == nil {
+		status = &infrav1exp.BlueGreenDeploymentStatus{}
+		m.AzureMachinePool.Status.BlueGreen = status
+	}
+	if status.NewScaleSetName != "" || status.RetiredScaleSetName != "" || status.RolledBackGeneration == m.AzureMachinePool.Generation {
+		return true
+	}
+
+	now := metav1.Now()
+	status.ActiveScaleSetName = m.Name()
+	status.NewScaleSetName = m.alternateScaleSetName(m.Name())
+	status.StartTime = &now
+	conditions.MarkFalse(m.AzureMachinePool, infrav1.ScaleSetDeploymentCondition, infrav1.ScaleSetDeployingReason, clusterv1.ConditionSeverityInfo, "deploying scale set %s", status.NewScaleSetName)
+	return true
+}
+
 // ProviderID returns the AzureMachinePool ID by parsing Spec.FakeProviderID.
 func (m *MachinePoolScope) ProviderID() string {
 	parsed, err := noderefutil.NewProviderID(m.AzureMachinePool.Spec.ProviderID)
@@ -152,6 +330,12 @@ func (m *MachinePoolScope) SetProviderID(v string) {
 	m.AzureMachinePool.Spec.ProviderID = v
 }
 
+// SetCapacity sets the AzureMachinePool status capacity, which is used by the cluster-autoscaler to scale the pool
+// from zero.
+func (m *MachinePoolScope) SetCapacity(capacity corev1.ResourceList) {
+	m.AzureMachinePool.Status.Capacity = capacity
+}
+
 // ProvisioningState returns the AzureMachinePool provisioning state.
 func (m *MachinePoolScope) ProvisioningState() infrav1.ProvisioningState {
 	if m.AzureMachinePool.Status.ProvisioningState != nil {
@@ -165,6 +349,15 @@ func (m *MachinePoolScope) SetVMSSState(vmssState *azure.VMSS) {
 	m.vmssState = vmssState
 }
 
+// SetDeploymentVMSSState updates the machine pool scope with the current state of the new or retired VMSS of a
+// blue/green deployment.
+func (m *MachinePoolScope) SetDeploymentVMSSState(name string, vmssState *azure.VMSS) {
+	if m.deploymentStates == nil {
+		m.deploymentStates = map[string]*azure.VMSS{}
+	}
+	m.deploymentStates[name] = vmssState
+}
+
 // NeedsRequeue return true if any machines are not on the latest model or the VMSS is not in a terminal provisioning
 // state.
 func (m *MachinePoolScope) NeedsRequeue() bool {
@@ -177,15 +370,76 @@ func (m *MachinePoolScope) NeedsRequeue() bool {
 		return true
 	}
 
+	if status := m.AzureMachinePool.Status.BlueGreen; status != nil && (status.NewScaleSetName != "" || status.RetiredScaleSetName != "") {
+		return true
+	}
+
+	if conditions.Has(m.AzureMachinePool, infrav1.RolloutPausedCondition) {
+		return true
+	}
+
 	desiredMatchesActual := len(m.vmssState.Instances) == int(m.DesiredReplicas())
 	return !(state != nil && infrav1.IsTerminalProvisioningState(*state) && desiredMatchesActual)
 }
 
-// DesiredReplicas returns the replica count on machine pool or 0 if machine pool replicas is nil.
+// DesiredReplicas returns the replica count on machine pool or 0 if machine pool replicas is nil. While the cluster is
+// hibernated the desired replica count is 0, and while it is resumed it is the capacity the scale set had before it
+// was hibernated.
 func (m MachinePoolScope) DesiredReplicas() int32 {
+	if m.hibernate {
+		return 0
+	}
+	if replicas, ok := m.hibernatedReplicas(); ok {
+		return replicas
+	}
 	return to.Int32(m.MachinePool.Spec.Replicas)
 }
 
+// hibernatedReplicas returns the capacity the scale set had before it was hibernated, if it has not been resumed yet.
+func (m MachinePoolScope) hibernatedReplicas() (int32, bool) {
+	value, ok := m.AzureMachinePool.Annotations[azure.HibernatedReplicasAnnotation]
+	if !ok {
+		return 0, false
+	}
+	replicas, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return int32(replicas), true
+}
+
+// SetHibernate sets whether the scale set should be scaled to zero because its cluster is hibernated.
+func (m *MachinePoolScope) SetHibernate(hibernate bool) {
+	m.hibernate = hibernate
+}
+
+// updateHibernationStatus remembers the capacity of the scale set when it starts hibernating, and reports the progress
+// of the hibernation in the Hibernated condition. The condition and the remembered capacity are removed once the scale
+// set is back to its capacity and all of its instances are ready.
+func (m *MachinePoolScope) updateHibernationStatus() {
+	replicas, remembered := m.hibernatedReplicas()
+	switch {
+	case m.hibernate:
+		if !remembered {
+			annotations.AddAnnotations(m.AzureMachinePool, map[string]string{
+				azure.HibernatedReplicasAnnotation: strconv.FormatInt(m.vmssState.Capacity, 10),
+			})
+		}
+		if len(m.vmssState.Instances) == 0 {
+			conditions.MarkTrue(m.AzureMachinePool, infrav1.HibernatedCondition)
+		} else {
+			conditions.MarkFalse(m.AzureMachinePool, infrav1.HibernatedCondition, infrav1.HibernatingReason, clusterv1.ConditionSeverityInfo, "")
+		}
+	case remembered:
+		if len(m.vmssState.Instances) == int(replicas) && m.AzureMachinePool.Status.Replicas == replicas {
+			delete(m.AzureMachinePool.Annotations, azure.HibernatedReplicasAnnotation)
+			conditions.Delete(m.AzureMachinePool, infrav1.HibernatedCondition)
+		} else {
+			conditions.MarkFalse(m.AzureMachinePool, infrav1.HibernatedCondition, infrav1.ResumingReason, clusterv1.ConditionSeverityInfo, "")
+		}
+	}
+}
+
 // MaxSurge returns the number of machines to surge, or 0 if the deployment strategy does not support surge.
 func (m MachinePoolScope) MaxSurge() (int, error) {
 	if surger, ok := m.getDeploymentStrategy().(machinepool.Surger); ok {
@@ -201,7 +455,7 @@ func (m MachinePoolScope) MaxSurge() (int, error) {
 }
 
 // updateReplicasAndProviderIDs ties the Azure VMSS instance data and the Node status data together to build and update
-// the AzureMachinePool replica count and providerIDList.
+// the AzureMachinePool replica count, providerIDList and instance statuses.
 func (m *MachinePoolScope) updateReplicasAndProviderIDs(ctx context.Context) error {
 	ctx, _, done := tele.StartSpanWithLogger(ctx, "scope.MachinePoolScope.UpdateInstanceStatuses")
 	defer done()
@@ -211,17 +465,47 @@ func (m *MachinePoolScope) updateReplicasAndProviderIDs(ctx context.Context) err
 		return errors.Wrap(err, "failed to get machine pool machines")
 	}
 
+	// the priority, eviction and health of the instances are only known from the scale sets
+	vmssInstances := make(map[string]azure.VMSSVM)
+	vmssStates := []*azure.VMSS{m.vmssState}
+	for _, vmssState := range m.deploymentStates {
+		vmssStates = append(vmssStates, vmssState)
+	}
+	for _, vmssState := range vmssStates {
+		if vmssState == nil {
+			continue
+		}
+		for providerID, instance := range vmssState.InstancesByProviderID() {
+			vmssInstances[providerID] = instance
+		}
+	}
+
 	var readyReplicas int32
 	providerIDs := make([]string, len(machines))
+	instances := make([]*infrav1exp.AzureMachinePoolInstanceStatus, len(machines))
 	for i, machine := range machines {
 		if machine.Status.Ready {
 			readyReplicas++
 		}
 		providerIDs[i] = machine.Spec.ProviderID
+		instances[i] = &infrav1exp.AzureMachinePoolInstanceStatus{
+			Version:            machine.Status.Version,
+			ProvisioningState:  machine.Status.ProvisioningState,
+			ProviderID:         machine.Spec.ProviderID,
+			InstanceID:         machine.Spec.InstanceID,
+			InstanceName:       machine.Status.InstanceName,
+			LatestModelApplied: machine.Status.LatestModelApplied,
+		}
+		if instance, ok := vmssInstances[machine.Spec.ProviderID]; ok {
+			instances[i].Priority = infrav1exp.InstancePriority(instance.Priority)
+			instances[i].Evicted = instance.Evicted
+			instances[i].HealthState = toInstanceHealthState(instance.HealthState)
+		}
 	}
 
 	m.AzureMachinePool.Status.Replicas = readyReplicas
 	m.AzureMachinePool.Spec.ProviderIDList = providerIDs
+	m.AzureMachinePool.Status.Instances = instances
 	return nil
 }
 
@@ -264,15 +548,25 @@ func (m *MachinePoolScope) applyAzureMachinePoolMachines(ctx context.Context) er
 		existingMachinesByProviderID[machine.Spec.ProviderID] = machine
 	}
 
+	// the new and retired scale sets of a blue/green deployment are only known if their state could be fetched
+	vmssStates := map[string]*azure.VMSS{m.Name(): m.vmssState}
+	for name, vmssState := range m.deploymentStates {
+		if vmssState != nil {
+			vmssStates[name] = vmssState
+		}
+	}
+
 	// determine which machines need to be created to reflect the current state in Azure
-	azureMachinesByProviderID := m.vmssState.InstancesByProviderID()
-	for key, val := range azureMachinesByProviderID {
-		if _, ok := existingMachinesByProviderID[key]; !ok {
-			log.V(4).Info("creating AzureMachinePoolMachine", "providerID", key)
-			if err := m.createMachine(ctx, val); err != nil {
-				return errors.Wrap(err, "failed creating AzureMachinePoolMachine")
+	azureMachinesByProviderID := make(map[string]azure.VMSSVM)
+	for name, vmssState := range vmssStates {
+		for key, val := range vmssState.InstancesByProviderID() {
+			azureMachinesByProviderID[key] = val
+			if _, ok := existingMachinesByProviderID[key]; !ok {
+				log.V(4).Info("creating AzureMachinePoolMachine", "providerID", key, "scale set", name)
+				if err := m.createMachine(ctx, name, val); err != nil {
+					return errors.Wrap(err, "failed creating AzureMachinePoolMachine")
+				}
 			}
-			continue
 		}
 	}
 
@@ -280,6 +574,10 @@ func (m *MachinePoolScope) applyAzureMachinePoolMachines(ctx context.Context) er
 	// delete machines that no longer exist in Azure
 	for key, machine := range existingMachinesByProviderID {
 		machine := machine
+		if _, ok := vmssStates[m.scaleSetNameOf(machine)]; !ok && m.isDeploymentScaleSet(m.scaleSetNameOf(machine)) {
+			// the state of the scale set of the machine is unknown
+			continue
+		}
 		if _, ok := azureMachinesByProviderID[key]; !ok {
 			deleted = true
 			log.V(4).Info("deleting AzureMachinePoolMachine because it no longer exists in the VMSS", "providerID", key)
@@ -290,6 +588,14 @@ func (m *MachinePoolScope) applyAzureMachinePoolMachines(ctx context.Context) er
 		}
 	}
 
+	// the availability zone of machines whose status hasn't been updated yet is known from the VMSS instances
+	for key, machine := range existingMachinesByProviderID {
+		if instance, ok := azureMachinesByProviderID[key]; ok && machine.Status.AvailabilityZone == "" {
+			machine.Status.AvailabilityZone = instance.AvailabilityZone
+			existingMachinesByProviderID[key] = machine
+		}
+	}
+
 	if deleted {
 		log.V(4).Info("exiting early due to finding AzureMachinePoolMachine(s) that were deleted because they no longer exist in the VMSS")
 		// exit early to be less greedy about delete
@@ -302,18 +608,37 @@ func (m *MachinePoolScope) applyAzureMachinePoolMachines(ctx context.Context) er
 		return nil
 	}
 
+	if err := m.reconcileScaleSetDeployment(ctx, existingMachinesByProviderID); err != nil {
+		return errors.Wrap(err, "failed reconciling scale set deployment")
+	}
+
 	deleteSelector := m.getDeploymentStrategy()
 	if deleteSelector == nil {
 		log.V(4).Info("can not select AzureMachinePoolMachines to delete because no deployment strategy is specified")
 		return nil
 	}
 
-	// select machines to delete to lower the replica count
-	toDelete, err := deleteSelector.SelectMachinesToDelete(ctx, m.DesiredReplicas(), existingMachinesByProviderID)
+	activeMachinesByProviderID := m.machinesOfScaleSet(existingMachinesByProviderID, m.Name())
+	paused := m.reconcileRolloutPause(ctx, deleteSelector, activeMachinesByProviderID)
+
+	// select machines of the active scale set to delete to lower the replica count
+	toDelete, err := m.selectMachinesToDelete(ctx, deleteSelector, activeMachinesByProviderID)
 	if err != nil {
 		return errors.Wrap(err, "failed selecting AzureMachinePoolMachine(s) to delete")
 	}
 
+	if paused {
+		// while the rollout is paused, only the machines whose VMs failed or are being deleted are deleted
+		var failedOrDeleting []infrav1exp.AzureMachinePoolMachine
+		for _, machine := range toDelete {
+			if state := machine.Status.ProvisioningState; state != nil && (*state == infrav1.Failed || *state == infrav1.Deleting) {
+				failedOrDeleting = append(failedOrDeleting, machine)
+			}
+		}
+		log.V(4).Info("rollout is paused, only deleting failed or deleting AzureMachinePoolMachine(s)", "selected", len(toDelete), "failedOrDeleting", len(failedOrDeleting))
+		toDelete = failedOrDeleting
+	}
+
 	for _, machine := range toDelete {
 		machine := machine
 		log.Info("deleting selected AzureMachinePoolMachine", "providerID", machine.Spec.ProviderID)
@@ -326,7 +651,174 @@ func (m *MachinePoolScope) applyAzureMachinePoolMachines(ctx context.Context) er
 	return nil
 }
 
-func (m *MachinePoolScope) createMachine(ctx context.Context, machine azure.VMSSVM) error {
+// selectMachinesToDelete selects the machines to delete with the deployment strategy. While the cluster is hibernated,
+// the machines protected from scale-in are deleted as well, as neither the platform nor the deployment strategy delete
+// them when the scale set is scaled to zero.
+func (m *MachinePoolScope) selectMachinesToDelete(ctx context.Context, deleteSelector machinepool.DeleteSelector, machinesByProviderID map[string]infrav1exp.AzureMachinePoolMachine) ([]infrav1exp.AzureMachinePoolMachine, error) {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "scope.MachinePoolScope.selectMachinesToDelete")
+	defer done()
+
+	toDelete, err := deleteSelector.SelectMachinesToDelete(ctx, m.DesiredReplicas(), machinesByProviderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !m.hibernate {
+		return toDelete, nil
+	}
+
+	selected := make(map[string]bool, len(toDelete))
+	for _, machine := range toDelete {
+		selected[machine.Spec.ProviderID] = true
+	}
+	for _, machine := range machinepool.GetProtectedMachines(machinesByProviderID) {
+		if !selected[machine.Spec.ProviderID] {
+			log.V(4).Info("deleting protected AzureMachinePoolMachine while the cluster is hibernated", "providerID", machine.Spec.ProviderID)
+			toDelete = append(toDelete, machine)
+		}
+	}
+
+	return toDelete, nil
+}
+
+// reconcileRolloutPause pauses the rollout of the machine pool while it disrupts the workloads of the cluster, if the
+// deployment strategy supports it, and reports it with the RolloutPaused condition. A paused rollout resumes by itself
+// once the machines which paused it recover, or when the AzureMachinePool has the resume rollout annotation.
+func (m *MachinePoolScope) reconcileRolloutPause(ctx context.Context, strategy machinepool.TypedDeleteSelector, machinesByProviderID map[string]infrav1exp.AzureMachinePoolMachine) bool {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "scope.MachinePoolScope.reconcileRolloutPause")
+	defer done()
+
+	pauser, ok := strategy.(machinepool.RolloutPauser)
+	if !ok {
+		conditions.Delete(m.AzureMachinePool, infrav1.RolloutPausedCondition)
+		return false
+	}
+
+	if _, ok := m.AzureMachinePool.Annotations[infrav1exp.ResumeRolloutAnnotation]; ok {
+		log.Info("resuming rollout", "annotation", infrav1exp.ResumeRolloutAnnotation)
+		now := metav1.Now()
+		m.AzureMachinePool.Status.RolloutResumeTime = &now
+		delete(m.AzureMachinePool.Annotations, infrav1exp.ResumeRolloutAnnotation)
+	}
+
+	var since time.Time
+	if resumeTime := m.AzureMachinePool.Status.RolloutResumeTime; resumeTime != nil {
+		since = resumeTime.Time
+	}
+
+	reason, message := pauser.PauseRollout(ctx, since, machinesByProviderID)
+	if reason == "" {
+		conditions.Delete(m.AzureMachinePool, infrav1.RolloutPausedCondition)
+		return false
+	}
+
+	log.Info("rollout is paused", "reason", reason, "message", message)
+	conditions.Set(m.AzureMachinePool, &clusterv1.Condition{
+		Type:    infrav1.RolloutPausedCondition,
+		Status:  corev1.ConditionTrue,
+		Reason:  reason,
+		Message: message,
+	})
+	return true
+}
+
+// reconcileScaleSetDeployment advances the blue/green deployment of a new scale set. Once all the machines of the new
+// scale set are ready, it becomes the active scale set and the machines of the previous one are deleted, which drains
+// their nodes. If the deployment fails, the machines of the new scale set are deleted instead. The retired scale set
+// itself is deleted by the scalesets service once it has no instances left.
+func (m *MachinePoolScope) reconcileScaleSetDeployment(ctx context.Context, machinesByProviderID map[string]infrav1exp.AzureMachinePoolMachine) error {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "scope.MachinePoolScope.reconcileScaleSetDeployment")
+	defer done()
+
+	status := m.AzureMachinePool.Status.BlueGreen
+	if status == nil {
+		return nil
+	}
+
+	if status.NewScaleSetName != "" {
+		newState := m.deploymentStates[status.NewScaleSetName]
+		if newState == nil {
+			log.V(4).Info("waiting for the new scale set to be created", "scale set", status.NewScaleSetName)
+			return nil
+		}
+
+		var (
+			ready bool
+			err   error
+		)
+		if deployer, ok := m.getDeploymentStrategy().(machinepool.ScaleSetDeployer); ok {
+			startTime := time.Now()
+			if status.StartTime != nil {
+				startTime = status.StartTime.Time
+			}
+			ready, err = deployer.CheckDeployment(ctx, m.DesiredReplicas(), startTime, m.machinesOfScaleSet(machinesByProviderID, status.NewScaleSetName))
+		} else {
+			err = errors.New("the deployment strategy no longer deploys new scale sets")
+		}
+
+		switch {
+		case err != nil:
+			log.Info("rolling back the deployment of the new scale set", "scale set", status.NewScaleSetName, "reason", err.Error())
+			status.RetiredScaleSetName = status.NewScaleSetName
+			status.NewScaleSetName = ""
+			status.RolledBackGeneration = m.AzureMachinePool.Generation
+			conditions.MarkFalse(m.AzureMachinePool, infrav1.ScaleSetDeploymentCondition, infrav1.ScaleSetRolledBackReason, clusterv1.ConditionSeverityWarning, "rolled back the deployment of scale set %s: %s", status.RetiredScaleSetName, err.Error())
+		case ready && len(newState.Instances) == int(m.DesiredReplicas()):
+			log.Info("the new scale set is ready, retiring the previous one", "scale set", status.NewScaleSetName, "previous scale set", m.Name())
+			status.RetiredScaleSetName = m.Name()
+			status.ActiveScaleSetName = status.NewScaleSetName
+			status.NewScaleSetName = ""
+			conditions.MarkFalse(m.AzureMachinePool, infrav1.ScaleSetDeploymentCondition, infrav1.ScaleSetRetiringReason, clusterv1.ConditionSeverityInfo, "draining scale set %s", status.RetiredScaleSetName)
+		default:
+			log.V(4).Info("waiting for the machines of the new scale set to be ready", "scale set", status.NewScaleSetName)
+			return nil
+		}
+	}
+
+	if status.RetiredScaleSetName == "" {
+		return nil
+	}
+
+	for _, machine := range m.machinesOfScaleSet(machinesByProviderID, status.RetiredScaleSetName) {
+		machine := machine
+		if !machine.DeletionTimestamp.IsZero() {
+			continue
+		}
+		log.Info("deleting AzureMachinePoolMachine of the retired scale set", "providerID", machine.Spec.ProviderID, "scale set", status.RetiredScaleSetName)
+		if err := m.client.Delete(ctx, &machine); err != nil {
+			return errors.Wrap(err, "failed deleting AzureMachinePoolMachine of the retired scale set")
+		}
+	}
+
+	return nil
+}
+
+// isDeploymentScaleSet returns true if name is the name of the new or retired scale set of a blue/green deployment.
+func (m *MachinePoolScope) isDeploymentScaleSet(name string) bool {
+	status := m.AzureMachinePool.Status.BlueGreen
+	return status != nil && name != "" && (name == status.NewScaleSetName || name == status.RetiredScaleSetName)
+}
+
+// scaleSetNameOf returns the name of the scale set of an AzureMachinePoolMachine.
+func (m *MachinePoolScope) scaleSetNameOf(machine infrav1exp.AzureMachinePoolMachine) string {
+	if name, ok := machine.Labels[infrav1exp.ScaleSetNameLabel]; ok {
+		return name
+	}
+	return m.defaultScaleSetName()
+}
+
+// machinesOfScaleSet returns the AzureMachinePoolMachines of the scale set named name.
+func (m *MachinePoolScope) machinesOfScaleSet(machinesByProviderID map[string]infrav1exp.AzureMachinePoolMachine, name string) map[string]infrav1exp.AzureMachinePoolMachine {
+	machines := make(map[string]infrav1exp.AzureMachinePoolMachine, len(machinesByProviderID))
+	for key, machine := range machinesByProviderID {
+		if m.scaleSetNameOf(machine) == name {
+			machines[key] = machine
+		}
+	}
+	return machines
+}
+
+func (m *MachinePoolScope) createMachine(ctx context.Context, scaleSetName string, machine azure.VMSSVM) error {
 	if machine.InstanceID == "" {
 		return errors.New("machine.InstanceID must not be empty")
 	}
@@ -337,7 +829,7 @@ func (m *MachinePoolScope) createMachine(ctx context.Context, machine azure.VMSS
 
 	ampm := infrav1exp.AzureMachinePoolMachine{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      strings.Join([]string{m.AzureMachinePool.Name, machine.InstanceID}, "-"),
+			Name:      m.azureMachinePoolMachineName(scaleSetName, machine),
 			Namespace: m.AzureMachinePool.Namespace,
 			OwnerReferences: []metav1.OwnerReference{
 				{
@@ -360,6 +852,10 @@ func (m *MachinePoolScope) createMachine(ctx context.Context, machine azure.VMSS
 		},
 	}
 
+	if scaleSetName != m.defaultScaleSetName() {
+		ampm.Labels[infrav1exp.ScaleSetNameLabel] = scaleSetName
+	}
+
 	controllerutil.AddFinalizer(&ampm, infrav1exp.AzureMachinePoolMachineFinalizer)
 	conditions.MarkFalse(&ampm, infrav1.VMRunningCondition, string(infrav1.Creating), clusterv1.ConditionSeverityInfo, "")
 	if err := m.client.Create(ctx, &ampm); err != nil {
@@ -369,6 +865,21 @@ func (m *MachinePoolScope) createMachine(ctx context.Context, machine azure.VMSS
 	return nil
 }
 
+// azureMachinePoolMachineName returns the name of the AzureMachinePoolMachine of an instance of the scale set named
+// scaleSetName. The instances of Flexible scale sets are identified by their VM name, e.g. pool_1a2b3c4d, which is
+// already prefixed with the scale set name but isn't a valid object name. The instance IDs of Uniform scale sets are
+// only unique within the scale set, so the names of the AzureMachinePoolMachines of the green scale set of a
+// blue/green deployment are prefixed with it.
+func (m *MachinePoolScope) azureMachinePoolMachineName(scaleSetName string, machine azure.VMSSVM) string {
+	if m.AzureMachinePool.GetOrchestrationMode() == infrav1.FlexibleOrchestrationMode {
+		return strings.ToLower(strings.ReplaceAll(machine.InstanceID, "_", "-"))
+	}
+	if scaleSetName != m.defaultScaleSetName() {
+		return strings.Join([]string{m.AzureMachinePool.Name, greenScaleSetSuffix, machine.InstanceID}, "-")
+	}
+	return strings.Join([]string{m.AzureMachinePool.Name, machine.InstanceID}, "-")
+}
+
 // SetLongRunningOperationState will set the future on the AzureMachinePool status to allow the resource to continue
 // in the next reconciliation.
 func (m *MachinePoolScope) SetLongRunningOperationState(future *infrav1.Future) {
@@ -389,17 +900,17 @@ func (m *MachinePoolScope) DeleteLongRunningOperationState(name, service string)
 func (m *MachinePoolScope) setProvisioningStateAndConditions(v infrav1.ProvisioningState) {
 	m.AzureMachinePool.Status.ProvisioningState = &v
 	switch {
-	case v == infrav1.Succeeded && *m.MachinePool.Spec.Replicas == m.AzureMachinePool.Status.Replicas:
+	case v == infrav1.Succeeded && m.DesiredReplicas() == m.AzureMachinePool.Status.Replicas:
 		// vmss is provisioned with enough ready replicas
 		conditions.MarkTrue(m.AzureMachinePool, infrav1.ScaleSetRunningCondition)
 		conditions.MarkTrue(m.AzureMachinePool, infrav1.ScaleSetModelUpdatedCondition)
 		conditions.MarkTrue(m.AzureMachinePool, infrav1.ScaleSetDesiredReplicasCondition)
 		m.SetReady()
-	case v == infrav1.Succeeded && *m.MachinePool.Spec.Replicas != m.AzureMachinePool.Status.Replicas:
+	case v == infrav1.Succeeded && m.DesiredReplicas() != m.AzureMachinePool.Status.Replicas:
 		// not enough ready or too many ready replicas we must still be scaling up or down
 		updatingState := infrav1.Updating
 		m.AzureMachinePool.Status.ProvisioningState = &updatingState
-		if *m.MachinePool.Spec.Replicas > m.AzureMachinePool.Status.Replicas {
+		if m.DesiredReplicas() > m.AzureMachinePool.Status.Replicas {
 			conditions.MarkFalse(m.AzureMachinePool, infrav1.ScaleSetDesiredReplicasCondition, infrav1.ScaleSetScaleUpReason, clusterv1.ConditionSeverityInfo, "")
 		} else {
 			conditions.MarkFalse(m.AzureMachinePool, infrav1.ScaleSetDesiredReplicasCondition, infrav1.ScaleSetScaleDownReason, clusterv1.ConditionSeverityInfo, "")
@@ -508,6 +1019,7 @@ func (m *MachinePoolScope) Close(ctx context.Context) error {
 		if err := m.updateReplicasAndProviderIDs(ctx); err != nil {
 			return errors.Wrap(err, "failed to update replicas and providerIDs")
 		}
+		m.updateHibernationStatus()
 	}
 
 	return m.patchHelper.Patch(ctx, m.AzureMachinePool)
@@ -518,31 +1030,71 @@ func (m *MachinePoolScope) GetBootstrapData(ctx context.Context) (string, error)
 	ctx, _, done := tele.StartSpanWithLogger(ctx, "scope.MachinePoolScope.GetBootstrapData")
 	defer done()
 
+	secret, err := m.getBootstrapDataSecret(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := secret.Data["value"]
+	if !ok {
+		return "", errors.New("error retrieving bootstrap data: secret value key is missing")
+	}
+	return base64.StdEncoding.EncodeToString(value), nil
+}
+
+// GetBootstrapDataFormat returns the format of the bootstrap data from the secret in the Machine's
+// bootstrap.dataSecretName, which is cloud-config unless the bootstrap provider set it to ignition.
+func (m *MachinePoolScope) GetBootstrapDataFormat(ctx context.Context) (string, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "scope.MachinePoolScope.GetBootstrapDataFormat")
+	defer done()
+
+	secret, err := m.getBootstrapDataSecret(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	format, ok := secret.Data["format"]
+	if !ok || len(format) == 0 {
+		return azure.CloudConfigBootstrapDataFormat, nil
+	}
+	return string(format), nil
+}
+
+// getBootstrapDataSecret returns the secret in the Machine's bootstrap.dataSecretName.
+func (m *MachinePoolScope) getBootstrapDataSecret(ctx context.Context) (*corev1.Secret, error) {
 	dataSecretName := m.MachinePool.Spec.Template.Spec.Bootstrap.DataSecretName
 	if dataSecretName == nil {
-		return "", errors.New("error retrieving bootstrap data: linked Machine Spec's bootstrap.dataSecretName is nil")
+		return nil, errors.New("error retrieving bootstrap data: linked Machine Spec's bootstrap.dataSecretName is nil")
 	}
 	secret := &corev1.Secret{}
 	key := types.NamespacedName{Namespace: m.AzureMachinePool.Namespace, Name: *dataSecretName}
 	if err := m.client.Get(ctx, key, secret); err != nil {
-		return "", errors.Wrapf(err, "failed to retrieve bootstrap data secret for AzureMachinePool %s/%s", m.AzureMachinePool.Namespace, m.Name())
+		return nil, errors.Wrapf(err, "failed to retrieve bootstrap data secret for AzureMachinePool %s/%s", m.AzureMachinePool.Namespace, m.Name())
 	}
+	return secret, nil
+}
 
-	value, ok := secret.Data["value"]
-	if !ok {
-		return "", errors.New("error retrieving bootstrap data: secret value key is missing")
+// isIgnition returns true if the machine pool is bootstrapped with Ignition. The format of the bootstrap data is only
+// known once the bootstrap provider has created the bootstrap data secret.
+func (m *MachinePoolScope) isIgnition(ctx context.Context) (bool, error) {
+	if m.MachinePool.Spec.Template.Spec.Bootstrap.DataSecretName == nil {
+		return false, nil
 	}
-	return base64.StdEncoding.EncodeToString(value), nil
+	format, err := m.GetBootstrapDataFormat(ctx)
+	if err != nil {
+		return false, err
+	}
+	return format == azure.IgnitionBootstrapDataFormat, nil
 }
 
 // GetVMImage picks an image from the machine configuration, or uses a default one.
 func (m *MachinePoolScope) GetVMImage(ctx context.Context) (*infrav1.Image, error) {
-	_, log, done := tele.StartSpanWithLogger(ctx, "scope.MachinePoolScope.GetVMImage")
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "scope.MachinePoolScope.GetVMImage")
 	defer done()
 
 	// Use custom Marketplace image, Image ID or a Shared Image Gallery image if provided
 	if m.AzureMachinePool.Spec.Template.Image != nil {
-		return m.AzureMachinePool.Spec.Template.Image, nil
+		return m.resolveVMImage(ctx, m.AzureMachinePool.Spec.Template.Image)
 	}
 
 	var (
@@ -553,9 +1105,19 @@ func (m *MachinePoolScope) GetVMImage(ctx context.Context) (*infrav1.Image, erro
 		runtime := m.AzureMachinePool.Annotations["runtime"]
 		windowsServerVersion := m.AzureMachinePool.Annotations["windowsServerVersion"]
 		log.V(4).Info("No image specified for machine, using default Windows Image", "machine", m.MachinePool.GetName(), "runtime", runtime, "windowsServerVersion", windowsServerVersion)
-		defaultImage, err = azure.GetDefaultWindowsImage(to.String(m.MachinePool.Spec.Template.Spec.Version), runtime, windowsServerVersion)
+		defaultImage, err = m.getDefaultVMImage(ctx, azure.WindowsOS, "", runtime, windowsServerVersion)
 	} else {
-		defaultImage, err = azure.GetDefaultUbuntuImage(to.String(m.MachinePool.Spec.Template.Spec.Version))
+		var ignition bool
+		ignition, err = m.isIgnition(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get bootstrap data format")
+		}
+		if ignition {
+			log.V(4).Info("No image specified for machine bootstrapped with Ignition, using default Flatcar Image", "machine", m.MachinePool.GetName())
+			defaultImage, err = m.getDefaultVMImage(ctx, azure.LinuxOS, azure.FlatcarFlavor, "", "")
+		} else {
+			defaultImage, err = m.getDefaultVMImage(ctx, azure.LinuxOS, "", "", "")
+		}
 	}
 
 	if err != nil {
@@ -565,6 +1127,57 @@ func (m *MachinePoolScope) GetVMImage(ctx context.Context) (*infrav1.Image, erro
 	return defaultImage, nil
 }
 
+// getDefaultVMImage returns the default image for the Kubernetes version of the machine pool. A version range, which
+// the default image resolver may return, is resolved like the image of the AzureMachinePool spec.
+func (m *MachinePoolScope) getDefaultVMImage(ctx context.Context, osType, flavor, runtime, windowsServerVersion string) (*infrav1.Image, error) {
+	image, err := azure.GetDefaultImage(to.String(m.MachinePool.Spec.Template.Spec.Version), osType, flavor, runtime, windowsServerVersion)
+	if err != nil {
+		return nil, err
+	}
+	if !virtualmachineimages.IsVersionRange(image) {
+		return image, nil
+	}
+	return m.resolveVMImage(ctx, image)
+}
+
+// resolveVMImage resolves the 'latest' or version range of an image to the exact version of the latest matching
+// image. The image resolved previously, which is saved to the AzureMachinePool status, is kept unless the image
+// changed, or a newer version is published and the image version update policy is Rolling.
+// The image isn't resolved when the platform upgrades the OS of the instances, which requires the 'latest' version.
+func (m *MachinePoolScope) resolveVMImage(ctx context.Context, image *infrav1.Image) (*infrav1.Image, error) {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "scope.MachinePoolScope.resolveVMImage")
+	defer done()
+
+	if !virtualmachineimages.NeedsResolution(image) {
+		return image, nil
+	}
+	if policy := m.AzureMachinePool.Spec.UpgradePolicy; policy != nil && policy.AutomaticOSUpgrade {
+		return image, nil
+	}
+
+	current := m.AzureMachinePool.Status.Image
+	isCurrent := virtualmachineimages.IsResolvedFrom(current, image)
+	if isCurrent && m.AzureMachinePool.Spec.ImageVersionUpdatePolicy != infrav1exp.RollingImageVersionUpdatePolicy {
+		return current, nil
+	}
+
+	resolver, err := virtualmachineimages.GetResolver(m, m.Location())
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := resolver.Resolve(ctx, image)
+	if err != nil {
+		return nil, err
+	}
+	if isCurrent && !virtualmachineimages.IsNewer(resolved, current) {
+		return current, nil
+	}
+	if isCurrent {
+		log.Info("Rolling out newer image version", "image", resolved)
+	}
+	return resolved, nil
+}
+
 // SaveVMImageToStatus persists the AzureMachinePool image to the status.
 func (m *MachinePoolScope) SaveVMImageToStatus(image *infrav1.Image) {
 	m.AzureMachinePool.Status.Image = image
@@ -614,6 +1227,11 @@ func (m *MachinePoolScope) getDeploymentStrategy() machinepool.TypedDeleteSelect
 		return nil
 	}
 
+	if m.AzureMachinePool.HasPlatformManagedUpgrades() {
+		// the platform upgrades the instances, so the strategy only scales the machine pool down
+		return machinepool.NewPlatformUpgradeStrategy(m.AzureMachinePool.Spec.Strategy)
+	}
+
 	return machinepool.NewMachinePoolDeploymentStrategy(m.AzureMachinePool.Spec.Strategy)
 }
 
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	autorestazure "github.com/Azure/go-autorest/autorest/azure"
 	"github.com/Azure/go-autorest/autorest/azure/auth"
@@ -96,6 +97,54 @@ func TestMachinePoolScope_Name(t *testing.T) {
 	}
 }
 
+func TestMachinePoolScope_azureMachinePoolMachineName(t *testing.T) {
+	tests := []struct {
+		name              string
+		orchestrationMode infrav1.OrchestrationModeType
+		scaleSetName      string
+		instanceID        string
+		want              string
+	}{
+		{
+			name:         "uniform instance is named after its instance ID",
+			scaleSetName: "pool-0",
+			instanceID:   "3",
+			want:         "pool-0-3",
+		},
+		{
+			name:         "uniform instance of the green scale set is prefixed with it",
+			scaleSetName: "pool-0-green",
+			instanceID:   "3",
+			want:         "pool-0-green-3",
+		},
+		{
+			name:              "flexible instance is named after its VM name",
+			orchestrationMode: infrav1.FlexibleOrchestrationMode,
+			scaleSetName:      "pool-0",
+			instanceID:        "pool-0_1A2b3c4d",
+			want:              "pool-0-1a2b3c4d",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			machinePoolScope := MachinePoolScope{
+				AzureMachinePool: &infrav1exp.AzureMachinePool{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "pool-0",
+					},
+					Spec: infrav1exp.AzureMachinePoolSpec{
+						OrchestrationMode: tt.orchestrationMode,
+					},
+				},
+			}
+			g.Expect(machinePoolScope.azureMachinePoolMachineName(tt.scaleSetName, azure.VMSSVM{InstanceID: tt.instanceID})).To(Equal(tt.want))
+		})
+	}
+}
+
 func TestMachinePoolScope_SetBootstrapConditions(t *testing.T) {
 	cases := []struct {
 		Name   string
@@ -331,7 +380,7 @@ func TestMachinePoolScope_GetVMImage(t *testing.T) {
 						Publisher:       "cncf-upstream",
 						Offer:           "capi",
 						SKU:             "k8s-1dot19dot19-ubuntu-1804",
-						Version:         "latest",
+						Version:         "1.19.20210818",
 						ThirdPartyImage: false,
 					},
 				}
@@ -343,7 +392,7 @@ func TestMachinePoolScope_GetVMImage(t *testing.T) {
 						Publisher:       "cncf-upstream",
 						Offer:           "capi",
 						SKU:             "k8s-1dot19dot19-ubuntu-1804",
-						Version:         "latest",
+						Version:         "1.19.20210818",
 						ThirdPartyImage: false,
 					},
 				}
@@ -351,6 +400,62 @@ func TestMachinePoolScope_GetVMImage(t *testing.T) {
 				g.Expect(amp.Spec.Template.Image).To(Equal(image))
 			},
 		},
+		{
+			Name: "should use the image version pinned in the status if the latest image version is specified",
+			Setup: func(mp *clusterv1exp.MachinePool, amp *infrav1exp.AzureMachinePool) {
+				amp.Spec.Template.Image = &infrav1.Image{
+					SharedGallery: &infrav1.AzureSharedGalleryImage{
+						SubscriptionID: "123",
+						ResourceGroup:  "my-rg",
+						Gallery:        "my-gallery",
+						Name:           "my-image",
+						Version:        "latest",
+					},
+				}
+				amp.Status.Image = &infrav1.Image{
+					SharedGallery: &infrav1.AzureSharedGalleryImage{
+						SubscriptionID: "123",
+						ResourceGroup:  "my-rg",
+						Gallery:        "my-gallery",
+						Name:           "my-image",
+						Version:        "1.2.3",
+					},
+				}
+			},
+			Verify: func(g *WithT, amp *infrav1exp.AzureMachinePool, vmImage *infrav1.Image, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(vmImage).To(Equal(amp.Status.Image))
+				g.Expect(amp.Spec.Template.Image.SharedGallery.Version).To(Equal("latest"))
+			},
+		},
+		{
+			Name: "should not resolve the latest image version if the platform upgrades the OS of the instances",
+			Setup: func(mp *clusterv1exp.MachinePool, amp *infrav1exp.AzureMachinePool) {
+				amp.Spec.UpgradePolicy = &infrav1exp.UpgradePolicy{AutomaticOSUpgrade: true}
+				amp.Spec.Template.Image = &infrav1.Image{
+					SharedGallery: &infrav1.AzureSharedGalleryImage{
+						SubscriptionID: "123",
+						ResourceGroup:  "my-rg",
+						Gallery:        "my-gallery",
+						Name:           "my-image",
+						Version:        "latest",
+					},
+				}
+				amp.Status.Image = &infrav1.Image{
+					SharedGallery: &infrav1.AzureSharedGalleryImage{
+						SubscriptionID: "123",
+						ResourceGroup:  "my-rg",
+						Gallery:        "my-gallery",
+						Name:           "my-image",
+						Version:        "1.2.3",
+					},
+				}
+			},
+			Verify: func(g *WithT, amp *infrav1exp.AzureMachinePool, vmImage *infrav1.Image, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(vmImage).To(Equal(amp.Spec.Template.Image))
+			},
+		},
 	}
 
 	for _, c := range cases {
@@ -470,6 +575,24 @@ func TestMachinePoolScope_NeedsRequeue(t *testing.T) {
 				g.Expect(requeue).To(BeTrue())
 			},
 		},
+		{
+			Name: "should requeue if an instance disk layout does not match the disks of the VMSS",
+			Setup: func(mp *clusterv1exp.MachinePool, amp *infrav1exp.AzureMachinePool, vmss *azure.VMSS) {
+				succeeded := infrav1.Succeeded
+				mp.Spec.Replicas = to.Int32Ptr(1)
+				amp.Status.ProvisioningState = &succeeded
+				vmss.DataDisks = []azure.VMSSDisk{{Lun: to.Int32Ptr(0), DiskSizeGB: 128}}
+				vmss.Instances = []azure.VMSSVM{
+					{
+						Name:      "instance1",
+						DataDisks: []azure.VMSSDisk{{Lun: to.Int32Ptr(0), DiskSizeGB: 64}},
+					},
+				}
+			},
+			Verify: func(g *WithT, requeue bool) {
+				g.Expect(requeue).To(BeTrue())
+			},
+		},
 	}
 
 	for _, c := range cases {
@@ -520,9 +643,10 @@ func TestMachinePoolScope_updateReplicasAndProviderIDs(t *testing.T) {
 	_ = infrav1exp.AddToScheme(scheme)
 
 	cases := []struct {
-		Name   string
-		Setup  func(cb *fake.ClientBuilder)
-		Verify func(g *WithT, amp *infrav1exp.AzureMachinePool, err error)
+		Name      string
+		Setup     func(cb *fake.ClientBuilder)
+		VMSSState *azure.VMSS
+		Verify    func(g *WithT, amp *infrav1exp.AzureMachinePool, err error)
 	}{
 		{
 			Name: "if there are three ready machines with matching labels, then should count them",
@@ -568,6 +692,36 @@ func TestMachinePoolScope_updateReplicasAndProviderIDs(t *testing.T) {
 				g.Expect(amp.Status.Replicas).To(BeEquivalentTo(2))
 			},
 		},
+		{
+			Name: "should report the priority and health of the instances and whether they were evicted",
+			Setup: func(cb *fake.ClientBuilder) {
+				machines := getReadyAzureMachinePoolMachines(3)
+				for i := range machines {
+					machines[i].Spec.ProviderID = fmt.Sprintf("azure:///foo/ampm%d", i)
+				}
+				machines[2].Status.Ready = false
+				for _, machine := range machines {
+					obj := machine
+					cb.WithObjects(&obj)
+				}
+			},
+			VMSSState: &azure.VMSS{
+				Instances: []azure.VMSSVM{
+					{ID: "/foo/ampm0", Priority: "Regular", HealthState: "healthy"},
+					{ID: "/foo/ampm1", Priority: "Spot", HealthState: "unhealthy"},
+					{ID: "/foo/ampm2", Priority: "Spot", Evicted: true},
+				},
+			},
+			Verify: func(g *WithT, amp *infrav1exp.AzureMachinePool, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(amp.Status.Replicas).To(BeEquivalentTo(2))
+				g.Expect(amp.Status.Instances).To(ConsistOf(
+					&infrav1exp.AzureMachinePoolInstanceStatus{ProviderID: "azure:///foo/ampm0", Priority: infrav1exp.RegularInstancePriority, HealthState: infrav1exp.HealthyInstanceHealthState},
+					&infrav1exp.AzureMachinePoolInstanceStatus{ProviderID: "azure:///foo/ampm1", Priority: infrav1exp.SpotInstancePriority, HealthState: infrav1exp.UnhealthyInstanceHealthState},
+					&infrav1exp.AzureMachinePoolInstanceStatus{ProviderID: "azure:///foo/ampm2", Priority: infrav1exp.SpotInstancePriority, Evicted: true},
+				))
+			},
+		},
 	}
 
 	for _, c := range cases {
@@ -613,6 +767,7 @@ func TestMachinePoolScope_updateReplicasAndProviderIDs(t *testing.T) {
 					Cluster: cluster,
 				},
 				AzureMachinePool: amp,
+				vmssState:        c.VMSSState,
 			}
 			err := s.updateReplicasAndProviderIDs(context.TODO())
 			c.Verify(g, s.AzureMachinePool, err)
@@ -856,3 +1011,409 @@ func getReadyAzureMachinePoolMachines(count int32) []infrav1exp.AzureMachinePool
 
 	return machines
 }
+
+func TestMachinePoolScope_DesiredReplicas(t *testing.T) {
+	tests := []struct {
+		name        string
+		hibernate   bool
+		annotations map[string]string
+		want        int32
+	}{
+		{
+			name: "uses the machine pool replicas",
+			want: 3,
+		},
+		{
+			name:      "scales to zero while hibernating",
+			hibernate: true,
+			want:      0,
+		},
+		{
+			name:        "restores the remembered capacity while resuming",
+			annotations: map[string]string{azure.HibernatedReplicasAnnotation: "5"},
+			want:        5,
+		},
+		{
+			name:        "ignores an invalid remembered capacity",
+			annotations: map[string]string{azure.HibernatedReplicasAnnotation: "foo"},
+			want:        3,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			s := &MachinePoolScope{
+				MachinePool: &clusterv1exp.MachinePool{
+					Spec: clusterv1exp.MachinePoolSpec{
+						Replicas: to.Int32Ptr(3),
+					},
+				},
+				AzureMachinePool: &infrav1exp.AzureMachinePool{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: tt.annotations,
+					},
+				},
+				hibernate: tt.hibernate,
+			}
+			g.Expect(s.DesiredReplicas()).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestMachinePoolScope_priorityMixPolicySpec(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy *infrav1exp.PriorityMixPolicy
+		want   *azure.PriorityMixPolicySpec
+	}{
+		{
+			name: "no priority mix policy",
+		},
+		{
+			name:   "defaults the eviction policy",
+			policy: &infrav1exp.PriorityMixPolicy{},
+			want: &azure.PriorityMixPolicySpec{
+				EvictionPolicy: "Deallocate",
+			},
+		},
+		{
+			name: "converts the percentage of spot instances to the percentage of regular instances",
+			policy: &infrav1exp.PriorityMixPolicy{
+				BaseRegularPriorityCount: to.Int32Ptr(2),
+				SpotPercentageAboveBase:  to.Int32Ptr(80),
+				EvictionPolicy:           infrav1exp.DeleteSpotEvictionPolicy,
+			},
+			want: &azure.PriorityMixPolicySpec{
+				BaseRegularPriorityCount:           to.Int32Ptr(2),
+				RegularPriorityPercentageAboveBase: to.Int32Ptr(20),
+				EvictionPolicy:                     "Delete",
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			s := &MachinePoolScope{
+				AzureMachinePool: &infrav1exp.AzureMachinePool{
+					Spec: infrav1exp.AzureMachinePoolSpec{
+						PriorityMixPolicy: tt.policy,
+					},
+				},
+			}
+			g.Expect(s.priorityMixPolicySpec()).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestMachinePoolScope_updateHibernationStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		hibernate   bool
+		annotations map[string]string
+		replicas    int32
+		instances   int
+		verify      func(g *WithT, amp *infrav1exp.AzureMachinePool)
+	}{
+		{
+			name:      "remembers the capacity when hibernation starts",
+			hibernate: true,
+			instances: 2,
+			verify: func(g *WithT, amp *infrav1exp.AzureMachinePool) {
+				g.Expect(amp.Annotations).To(HaveKeyWithValue(azure.HibernatedReplicasAnnotation, "2"))
+				g.Expect(conditions.GetReason(amp, infrav1.HibernatedCondition)).To(Equal(infrav1.HibernatingReason))
+			},
+		},
+		{
+			name:        "is hibernated once there are no instances",
+			hibernate:   true,
+			annotations: map[string]string{azure.HibernatedReplicasAnnotation: "2"},
+			verify: func(g *WithT, amp *infrav1exp.AzureMachinePool) {
+				g.Expect(amp.Annotations).To(HaveKeyWithValue(azure.HibernatedReplicasAnnotation, "2"))
+				g.Expect(conditions.IsTrue(amp, infrav1.HibernatedCondition)).To(BeTrue())
+			},
+		},
+		{
+			name:        "is resuming until all instances are ready",
+			annotations: map[string]string{azure.HibernatedReplicasAnnotation: "2"},
+			replicas:    1,
+			instances:   2,
+			verify: func(g *WithT, amp *infrav1exp.AzureMachinePool) {
+				g.Expect(amp.Annotations).To(HaveKey(azure.HibernatedReplicasAnnotation))
+				g.Expect(conditions.GetReason(amp, infrav1.HibernatedCondition)).To(Equal(infrav1.ResumingReason))
+			},
+		},
+		{
+			name:        "is resumed once all instances are ready",
+			annotations: map[string]string{azure.HibernatedReplicasAnnotation: "2"},
+			replicas:    2,
+			instances:   2,
+			verify: func(g *WithT, amp *infrav1exp.AzureMachinePool) {
+				g.Expect(amp.Annotations).NotTo(HaveKey(azure.HibernatedReplicasAnnotation))
+				g.Expect(conditions.Has(amp, infrav1.HibernatedCondition)).To(BeFalse())
+			},
+		},
+		{
+			name:      "does nothing when the cluster was never hibernated",
+			instances: 2,
+			verify: func(g *WithT, amp *infrav1exp.AzureMachinePool) {
+				g.Expect(amp.Annotations).NotTo(HaveKey(azure.HibernatedReplicasAnnotation))
+				g.Expect(conditions.Has(amp, infrav1.HibernatedCondition)).To(BeFalse())
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			s := &MachinePoolScope{
+				AzureMachinePool: &infrav1exp.AzureMachinePool{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: tt.annotations,
+					},
+					Status: infrav1exp.AzureMachinePoolStatus{
+						Replicas: tt.replicas,
+					},
+				},
+				vmssState: &azure.VMSS{
+					Capacity:  int64(tt.instances),
+					Instances: make([]azure.VMSSVM, tt.instances),
+				},
+				hibernate: tt.hibernate,
+			}
+			s.updateHibernationStatus()
+			tt.verify(g, s.AzureMachinePool)
+		})
+	}
+}
+
+func TestMachinePoolScope_reconcileRolloutPause(t *testing.T) {
+	var (
+		succeeded  = infrav1.Succeeded
+		tenMinutes = &metav1.Duration{Duration: 10 * time.Minute}
+		notReady   = infrav1exp.AzureMachinePoolMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "notready",
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-1 * time.Hour)),
+			},
+			Status: infrav1exp.AzureMachinePoolMachineStatus{
+				LatestModelApplied: true,
+				ProvisioningState:  &succeeded,
+			},
+		}
+	)
+
+	tests := []struct {
+		name        string
+		strategy    infrav1exp.AzureMachinePoolDeploymentStrategy
+		annotations map[string]string
+		machines    map[string]infrav1exp.AzureMachinePoolMachine
+		want        bool
+		verify      func(g *WithT, amp *infrav1exp.AzureMachinePool)
+	}{
+		{
+			name: "pauses the rollout when a node isn't ready in time",
+			strategy: infrav1exp.AzureMachinePoolDeploymentStrategy{
+				Type:          infrav1exp.RollingUpdateAzureMachinePoolDeploymentStrategyType,
+				RollingUpdate: &infrav1exp.MachineRollingUpdateDeployment{NodeReadyTimeout: tenMinutes},
+			},
+			machines: map[string]infrav1exp.AzureMachinePoolMachine{"notready": notReady},
+			want:     true,
+			verify: func(g *WithT, amp *infrav1exp.AzureMachinePool) {
+				g.Expect(conditions.IsTrue(amp, infrav1.RolloutPausedCondition)).To(BeTrue())
+				g.Expect(conditions.GetReason(amp, infrav1.RolloutPausedCondition)).To(Equal(infrav1.NodeNotReadyReason))
+			},
+		},
+		{
+			name: "resumes the rollout with the resume rollout annotation",
+			strategy: infrav1exp.AzureMachinePoolDeploymentStrategy{
+				Type:          infrav1exp.RollingUpdateAzureMachinePoolDeploymentStrategyType,
+				RollingUpdate: &infrav1exp.MachineRollingUpdateDeployment{NodeReadyTimeout: tenMinutes},
+			},
+			annotations: map[string]string{infrav1exp.ResumeRolloutAnnotation: ""},
+			machines:    map[string]infrav1exp.AzureMachinePoolMachine{"notready": notReady},
+			want:        false,
+			verify: func(g *WithT, amp *infrav1exp.AzureMachinePool) {
+				g.Expect(conditions.Has(amp, infrav1.RolloutPausedCondition)).To(BeFalse())
+				g.Expect(amp.Annotations).NotTo(HaveKey(infrav1exp.ResumeRolloutAnnotation))
+				g.Expect(amp.Status.RolloutResumeTime).NotTo(BeNil())
+			},
+		},
+		{
+			name: "does not pause a blue/green deployment",
+			strategy: infrav1exp.AzureMachinePoolDeploymentStrategy{
+				Type: infrav1exp.BlueGreenAzureMachinePoolDeploymentStrategyType,
+			},
+			machines: map[string]infrav1exp.AzureMachinePoolMachine{"notready": notReady},
+			want:     false,
+			verify: func(g *WithT, amp *infrav1exp.AzureMachinePool) {
+				g.Expect(conditions.Has(amp, infrav1.RolloutPausedCondition)).To(BeFalse())
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			s := &MachinePoolScope{
+				AzureMachinePool: &infrav1exp.AzureMachinePool{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: tt.annotations,
+					},
+					Spec: infrav1exp.AzureMachinePoolSpec{
+						Strategy: tt.strategy,
+					},
+				},
+			}
+			got := s.reconcileRolloutPause(context.Background(), s.getDeploymentStrategy(), tt.machines)
+			g.Expect(got).To(Equal(tt.want))
+			tt.verify(g, s.AzureMachinePool)
+		})
+	}
+}
+
+func TestMachinePoolScope_selectMachinesToDelete(t *testing.T) {
+	var (
+		succeeded = infrav1.Succeeded
+		created   = metav1.NewTime(time.Now().Add(-1 * time.Hour))
+		makeAMPM  = func(providerID string, annotations map[string]string) infrav1exp.AzureMachinePoolMachine {
+			return infrav1exp.AzureMachinePoolMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              providerID,
+					Annotations:       annotations,
+					CreationTimestamp: created,
+				},
+				Spec: infrav1exp.AzureMachinePoolMachineSpec{
+					ProviderID: providerID,
+				},
+				Status: infrav1exp.AzureMachinePoolMachineStatus{
+					Ready:              true,
+					LatestModelApplied: true,
+					ProvisioningState:  &succeeded,
+				},
+			}
+		}
+		unprotected = makeAMPM("unprotected", nil)
+		protected   = makeAMPM("protected", map[string]string{azure.InstanceProtectionAnnotation: azure.InstanceProtectionScaleIn})
+	)
+
+	tests := []struct {
+		name      string
+		hibernate bool
+		want      []infrav1exp.AzureMachinePoolMachine
+	}{
+		{
+			name: "spares protected machines when scaling in",
+			want: []infrav1exp.AzureMachinePoolMachine{unprotected},
+		},
+		{
+			name:      "deletes protected machines while hibernating",
+			hibernate: true,
+			want:      []infrav1exp.AzureMachinePoolMachine{unprotected, protected},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			s := &MachinePoolScope{
+				MachinePool: &clusterv1exp.MachinePool{
+					Spec: clusterv1exp.MachinePoolSpec{
+						Replicas: to.Int32Ptr(0),
+					},
+				},
+				AzureMachinePool: &infrav1exp.AzureMachinePool{
+					Spec: infrav1exp.AzureMachinePoolSpec{
+						Strategy: infrav1exp.AzureMachinePoolDeploymentStrategy{
+							Type:          infrav1exp.RollingUpdateAzureMachinePoolDeploymentStrategyType,
+							RollingUpdate: &infrav1exp.MachineRollingUpdateDeployment{DeletePolicy: infrav1exp.OldestDeletePolicyType},
+						},
+					},
+				},
+				hibernate: tt.hibernate,
+			}
+			got, err := s.selectMachinesToDelete(context.Background(), s.getDeploymentStrategy(), map[string]infrav1exp.AzureMachinePoolMachine{
+				unprotected.Spec.ProviderID: unprotected,
+				protected.Spec.ProviderID:   protected,
+			})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestMachinePoolScope_GetBootstrapDataFormat(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	tests := []struct {
+		name       string
+		secretData map[string][]byte
+		wantFormat string
+		wantImage  *infrav1.Image
+	}{
+		{
+			name:       "defaults to cloud-config",
+			secretData: map[string][]byte{"value": []byte("#cloud-config")},
+			wantFormat: azure.CloudConfigBootstrapDataFormat,
+			wantImage: &infrav1.Image{
+				Marketplace: &infrav1.AzureMarketplaceImage{
+					Publisher: "cncf-upstream",
+					Offer:     "capi",
+					SKU:       "k8s-1dot22dot1-ubuntu-2004",
+					Version:   "latest",
+				},
+			},
+		},
+		{
+			name:       "uses the format of the bootstrap data secret",
+			secretData: map[string][]byte{"value": []byte(`{"ignition":{}}`), "format": []byte("ignition")},
+			wantFormat: azure.IgnitionBootstrapDataFormat,
+			wantImage: &infrav1.Image{
+				Marketplace: &infrav1.AzureMarketplaceImage{
+					Publisher: "kinvolk",
+					Offer:     "flatcar-container-linux-corevm-amd64",
+					SKU:       "stable-gen2",
+					Version:   "latest",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "mp1-bootstrap", Namespace: "default"},
+				Data:       tt.secretData,
+			}
+			s := &MachinePoolScope{
+				client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build(),
+				MachinePool: &clusterv1exp.MachinePool{
+					Spec: clusterv1exp.MachinePoolSpec{
+						Template: clusterv1.MachineTemplateSpec{
+							Spec: clusterv1.MachineSpec{
+								Version: to.StringPtr("v1.22.1"),
+								Bootstrap: clusterv1.Bootstrap{
+									DataSecretName: to.StringPtr("mp1-bootstrap"),
+								},
+							},
+						},
+					},
+				},
+				AzureMachinePool: &infrav1exp.AzureMachinePool{
+					ObjectMeta: metav1.ObjectMeta{Name: "amp1", Namespace: "default"},
+				},
+			}
+
+			format, err := s.GetBootstrapDataFormat(context.TODO())
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(format).To(Equal(tt.wantFormat))
+
+			image, err := s.GetVMImage(context.TODO())
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(image).To(Equal(tt.wantImage))
+		})
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -82,6 +83,7 @@ type (
 		client                  client.Client
 		patchHelper             *patch.Helper
 		instance                *azure.VMSSVM
+		terminateEventScheduled bool
 
 		// workloadNodeGetter is only used for testing purposes and provides a way for mocking requests to the workload cluster
 		workloadNodeGetter nodeGetter
@@ -158,9 +160,32 @@ func (s *MachinePoolMachineScope) InstanceID() string {
 	return s.AzureMachinePoolMachine.Spec.InstanceID
 }
 
-// ScaleSetName is the name of the VMSS.
+// ScaleSetName is the name of the VMSS the machine belongs to.
 func (s *MachinePoolMachineScope) ScaleSetName() string {
-	return s.MachinePoolScope.Name()
+	return s.MachinePoolScope.scaleSetNameOf(*s.AzureMachinePoolMachine)
+}
+
+// OrchestrationMode is the orchestration mode of the VMSS.
+func (s *MachinePoolMachineScope) OrchestrationMode() infrav1.OrchestrationModeType {
+	return s.AzureMachinePool.GetOrchestrationMode()
+}
+
+// OSType is the operating system of the instances of the VMSS.
+func (s *MachinePoolMachineScope) OSType() string {
+	return s.AzureMachinePool.Spec.Template.OSDisk.OSType
+}
+
+// ReceivesTerminateEvents indicates the instances of the VMSS are notified of their deletion or eviction with Terminate
+// or Preempt scheduled events.
+func (s *MachinePoolMachineScope) ReceivesTerminateEvents() bool {
+	template := s.AzureMachinePool.Spec.Template
+	return template.TerminateNotificationTimeout != nil || template.SpotVMOptions != nil || s.AzureMachinePool.Spec.PriorityMixPolicy != nil
+}
+
+// TerminateEventScheduled indicates the node of the machine reports a pending Terminate or Preempt scheduled event. It
+// is only known after UpdateStatus.
+func (s *MachinePoolMachineScope) TerminateEventScheduled() bool {
+	return s.terminateEventScheduled
 }
 
 // SetLongRunningOperationState will set the future on the AzureMachinePoolMachine status to allow the resource to continue
@@ -220,6 +245,16 @@ func (s *MachinePoolMachineScope) SetVMSSVM(instance *azure.VMSSVM) {
 	s.instance = instance
 }
 
+// Annotation returns the value of an annotation on the AzureMachinePoolMachine.
+func (s *MachinePoolMachineScope) Annotation(key string) string {
+	return s.AzureMachinePoolMachine.GetAnnotations()[key]
+}
+
+// RemoveAnnotation removes an annotation from the AzureMachinePoolMachine.
+func (s *MachinePoolMachineScope) RemoveAnnotation(key string) {
+	delete(s.AzureMachinePoolMachine.Annotations, key)
+}
+
 // ProvisioningState returns the AzureMachinePoolMachine provisioning state.
 func (s *MachinePoolMachineScope) ProvisioningState() infrav1.ProvisioningState {
 	if s.AzureMachinePoolMachine.Status.ProvisioningState != nil {
@@ -295,6 +330,7 @@ func (s *MachinePoolMachineScope) UpdateStatus(ctx context.Context) error {
 
 		s.AzureMachinePoolMachine.Status.Ready = noderefutil.IsNodeReady(node)
 		s.AzureMachinePoolMachine.Status.Version = node.Status.NodeInfo.KubeletVersion
+		s.terminateEventScheduled = hasTerminateEventScheduled(node)
 	}
 
 	if s.instance != nil {
@@ -305,11 +341,79 @@ func (s *MachinePoolMachineScope) UpdateStatus(ctx context.Context) error {
 
 		s.AzureMachinePoolMachine.Status.LatestModelApplied = hasLatestModel
 		s.AzureMachinePoolMachine.Status.ProvisioningState = &s.instance.State
+		s.AzureMachinePoolMachine.Status.HealthState = toInstanceHealthState(s.instance.HealthState)
+		s.AzureMachinePoolMachine.Status.AvailabilityZone = s.instance.AvailabilityZone
+		s.AzureMachinePoolMachine.Status.ProtectionPolicy = nil
+		if s.instance.ProtectFromScaleIn || s.instance.ProtectFromScaleSetActions {
+			s.AzureMachinePoolMachine.Status.ProtectionPolicy = &infrav1exp.InstanceProtectionPolicy{
+				ProtectFromScaleIn:         s.instance.ProtectFromScaleIn,
+				ProtectFromScaleSetActions: s.instance.ProtectFromScaleSetActions,
+			}
+		}
+		s.AzureMachinePoolMachine.Status.Disks = toInstanceDisks(*s.instance)
 	}
 
 	return nil
 }
 
+// toInstanceDisks converts the OS disk and the data disks of a VMSS instance, if it reports them.
+func toInstanceDisks(instance azure.VMSSVM) []infrav1exp.InstanceDisk {
+	if instance.OSDisk.Name == "" && len(instance.DataDisks) == 0 {
+		return nil
+	}
+
+	convert := func(disk azure.VMSSDisk) infrav1exp.InstanceDisk {
+		return infrav1exp.InstanceDisk{
+			Name:                disk.Name,
+			Lun:                 disk.Lun,
+			DiskSizeGB:          disk.DiskSizeGB,
+			StorageAccountType:  disk.StorageAccountType,
+			Ephemeral:           disk.Ephemeral,
+			Attached:            disk.Attached,
+			DiskEncryptionSetID: disk.DiskEncryptionSetID,
+			EncryptionAtHost:    instance.EncryptionAtHost,
+		}
+	}
+
+	disks := []infrav1exp.InstanceDisk{convert(instance.OSDisk)}
+	for _, disk := range instance.DataDisks {
+		disks = append(disks, convert(disk))
+	}
+	return disks
+}
+
+// toInstanceHealthState converts the health state reported by the Application Health extension of a VMSS instance.
+func toInstanceHealthState(healthState string) infrav1exp.InstanceHealthState {
+	switch strings.ToLower(healthState) {
+	case "":
+		return ""
+	case "healthy":
+		return infrav1exp.HealthyInstanceHealthState
+	case "unhealthy":
+		return infrav1exp.UnhealthyInstanceHealthState
+	case "initializing":
+		return infrav1exp.InitializingInstanceHealthState
+	default:
+		return infrav1exp.UnknownInstanceHealthState
+	}
+}
+
+// hasTerminateEventScheduled checks the node for a pending Terminate or Preempt scheduled event reported by the
+// node-problem-detector.
+func hasTerminateEventScheduled(node *corev1.Node) bool {
+	for _, c := range node.Status.Conditions {
+		if c.Type != azure.VMEventScheduledNodeCondition || c.Status != corev1.ConditionTrue {
+			continue
+		}
+		for _, eventType := range []string{azure.TerminateScheduledEvent, azure.PreemptScheduledEvent} {
+			if strings.Contains(c.Reason, eventType) || strings.Contains(c.Message, eventType) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // CordonAndDrain will cordon and drain the Kubernetes node associated with this AzureMachinePoolMachine.
 func (s *MachinePoolMachineScope) CordonAndDrain(ctx context.Context) error {
 	ctx, log, done := tele.StartSpanWithLogger(
@@ -384,17 +488,7 @@ func (s *MachinePoolMachineScope) drainNode(ctx context.Context, node *corev1.No
 	)
 	defer done()
 
-	restConfig, err := remote.RESTConfig(ctx, MachinePoolMachineScopeName, s.client, client.ObjectKey{
-		Name:      s.ClusterName(),
-		Namespace: s.AzureMachinePoolMachine.Namespace,
-	})
-
-	if err != nil {
-		log.Error(err, "Error creating a remote client while deleting Machine, won't retry")
-		return nil
-	}
-
-	kubeClient, err := kubernetes.NewForConfig(restConfig)
+	kubeClient, err := s.workloadKubeClient(ctx)
 	if err != nil {
 		log.Error(err, "Error creating a remote client while deleting Machine, won't retry")
 		return nil
@@ -441,6 +535,63 @@ func (s *MachinePoolMachineScope) drainNode(ctx context.Context, node *corev1.No
 	return nil
 }
 
+// Uncordon makes the node of the machine schedulable again once the scheduled event it was drained for is no longer
+// pending, e.g. when an evicted Spot VM with the Deallocate eviction policy is started again.
+func (s *MachinePoolMachineScope) Uncordon(ctx context.Context) error {
+	ctx, _, done := tele.StartSpanWithLogger(
+		ctx,
+		"scope.MachinePoolMachineScope.Uncordon",
+	)
+	defer done()
+
+	nodeRef := s.AzureMachinePoolMachine.Status.NodeRef
+	if nodeRef == nil || nodeRef.Name == "" {
+		return nil
+	}
+
+	node, err := s.workloadNodeGetter.GetNodeByObjectReference(ctx, *nodeRef)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrap(err, "failed to find node")
+	}
+
+	if !node.Spec.Unschedulable {
+		return nil
+	}
+
+	kubeClient, err := s.workloadKubeClient(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to create the workload cluster client")
+	}
+
+	drainer := &kubedrain.Helper{
+		Client: kubeClient,
+		Ctx:    ctx,
+		Out:    writer{klog.Info},
+		ErrOut: writer{klog.Error},
+	}
+	if err := kubedrain.RunCordonOrUncordon(drainer, node, false); err != nil {
+		return errors.Wrapf(err, "unable to uncordon node %s", node.Name)
+	}
+
+	return nil
+}
+
+// workloadKubeClient creates a clientset for the workload cluster of the machine.
+func (s *MachinePoolMachineScope) workloadKubeClient(ctx context.Context) (kubernetes.Interface, error) {
+	restConfig, err := remote.RESTConfig(ctx, MachinePoolMachineScopeName, s.client, client.ObjectKey{
+		Name:      s.ClusterName(),
+		Namespace: s.AzureMachinePoolMachine.Namespace,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return kubernetes.NewForConfig(restConfig)
+}
+
 // isNodeDrainAllowed checks to see the node is excluded from draining or if the NodeDrainTimeout has expired.
 func (s *MachinePoolMachineScope) isNodeDrainAllowed() bool {
 	if _, exists := s.AzureMachinePoolMachine.ObjectMeta.Annotations[clusterv1.ExcludeNodeDrainingAnnotation]; exists {
@@ -495,8 +646,14 @@ func (s *MachinePoolMachineScope) hasLatestModelApplied(ctx context.Context) (bo
 		return false, errors.New("machinepoolscope image must not be nil")
 	}
 
-	// if the images match, then the VM is of the same model
-	return reflect.DeepEqual(s.instance.Image, *image), nil
+	// if the images match, then the VM is of the same model, unless its disk layout drifted from the disks of the pool
+	if !reflect.DeepEqual(s.instance.Image, *image) {
+		return false, nil
+	}
+
+	template := s.AzureMachinePool.Spec.Template
+	osDisk, dataDisks := azure.DiskLayoutFromSpec(template.OSDisk, template.DataDisks)
+	return azure.HasDiskLayout(s.instance.OSDisk, s.instance.DataDisks, osDisk, dataDisks), nil
 }
 
 func newWorkloadClusterProxy(c client.Client, cluster client.ObjectKey) *workloadClusterProxy {
@@ -240,6 +240,23 @@ func TestMachineScope_UpdateStatus(t *testing.T) {
 				}))
 			},
 		},
+		{
+			Name: "should report a pending terminate scheduled event of the node",
+			Setup: func(mockNodeGetter *mock_scope.MocknodeGetter, ampm *infrav1.AzureMachinePoolMachine) (*azure.VMSSVM, *infrav1.AzureMachinePoolMachine) {
+				node := getReadyNode()
+				node.Status.Conditions = append(node.Status.Conditions, corev1.NodeCondition{
+					Type:    azure.VMEventScheduledNodeCondition,
+					Status:  corev1.ConditionTrue,
+					Reason:  "VMEventScheduled",
+					Message: "Terminate scheduled for VM",
+				})
+				mockNodeGetter.EXPECT().GetNodeByProviderID(gomock2.AContext(), FakeProviderID).Return(node, nil)
+				return nil, ampm
+			},
+			Verify: func(g *WithT, scope *MachinePoolMachineScope) {
+				g.Expect(scope.TerminateEventScheduled()).To(BeTrue())
+			},
+		},
 		{
 			Name: "instance information with latest model populates the AMPM status",
 			Setup: func(mockNodeGetter *mock_scope.MocknodeGetter, ampm *infrav1.AzureMachinePoolMachine) (*azure.VMSSVM, *infrav1.AzureMachinePoolMachine) {
@@ -264,6 +281,118 @@ func TestMachineScope_UpdateStatus(t *testing.T) {
 				}))
 			},
 		},
+		{
+			Name: "instance information with an availability zone populates the AMPM status",
+			Setup: func(mockNodeGetter *mock_scope.MocknodeGetter, ampm *infrav1.AzureMachinePoolMachine) (*azure.VMSSVM, *infrav1.AzureMachinePoolMachine) {
+				mockNodeGetter.EXPECT().GetNodeByProviderID(gomock2.AContext(), FakeProviderID).Return(nil, nil)
+				return &azure.VMSSVM{
+					State:            v1beta1.Succeeded,
+					AvailabilityZone: "2",
+					Image: v1beta1.Image{
+						Marketplace: &v1beta1.AzureMarketplaceImage{
+							Publisher: "cncf-upstream",
+							Offer:     "capi",
+							SKU:       "k8s-1dot19dot11-ubuntu-1804",
+							Version:   "latest",
+						},
+					},
+				}, ampm
+			},
+			Verify: func(g *WithT, scope *MachinePoolMachineScope) {
+				succeeded := v1beta1.Succeeded
+				g.Expect(scope.AzureMachinePoolMachine.Status).To(Equal(infrav1.AzureMachinePoolMachineStatus{
+					ProvisioningState:  &succeeded,
+					LatestModelApplied: true,
+					AvailabilityZone:   "2",
+				}))
+			},
+		},
+		{
+			Name: "instance disks populate the AMPM status and attached disks do not count as drift",
+			Setup: func(mockNodeGetter *mock_scope.MocknodeGetter, ampm *infrav1.AzureMachinePoolMachine) (*azure.VMSSVM, *infrav1.AzureMachinePoolMachine) {
+				mockNodeGetter.EXPECT().GetNodeByProviderID(gomock2.AContext(), FakeProviderID).Return(nil, nil)
+				return &azure.VMSSVM{
+					State:            v1beta1.Succeeded,
+					EncryptionAtHost: true,
+					Image: v1beta1.Image{
+						Marketplace: &v1beta1.AzureMarketplaceImage{
+							Publisher: "cncf-upstream",
+							Offer:     "capi",
+							SKU:       "k8s-1dot19dot11-ubuntu-1804",
+							Version:   "latest",
+						},
+					},
+					OSDisk: azure.VMSSDisk{
+						Name:               "osdisk",
+						DiskSizeGB:         30,
+						StorageAccountType: "Premium_LRS",
+					},
+					DataDisks: []azure.VMSSDisk{
+						{
+							Name:               "pvc-disk",
+							Lun:                to.Int32Ptr(5),
+							DiskSizeGB:         64,
+							StorageAccountType: "Premium_LRS",
+							Attached:           true,
+						},
+					},
+				}, ampm
+			},
+			Verify: func(g *WithT, scope *MachinePoolMachineScope) {
+				succeeded := v1beta1.Succeeded
+				g.Expect(scope.AzureMachinePoolMachine.Status).To(Equal(infrav1.AzureMachinePoolMachineStatus{
+					ProvisioningState:  &succeeded,
+					LatestModelApplied: true,
+					Disks: []infrav1.InstanceDisk{
+						{
+							Name:               "osdisk",
+							DiskSizeGB:         30,
+							StorageAccountType: "Premium_LRS",
+							EncryptionAtHost:   true,
+						},
+						{
+							Name:               "pvc-disk",
+							Lun:                to.Int32Ptr(5),
+							DiskSizeGB:         64,
+							StorageAccountType: "Premium_LRS",
+							Attached:           true,
+							EncryptionAtHost:   true,
+						},
+					},
+				}))
+			},
+		},
+		{
+			Name: "instance with a data disk not in the AzureMachinePool template is not on the latest model",
+			Setup: func(mockNodeGetter *mock_scope.MocknodeGetter, ampm *infrav1.AzureMachinePoolMachine) (*azure.VMSSVM, *infrav1.AzureMachinePoolMachine) {
+				mockNodeGetter.EXPECT().GetNodeByProviderID(gomock2.AContext(), FakeProviderID).Return(nil, nil)
+				return &azure.VMSSVM{
+					State: v1beta1.Succeeded,
+					Image: v1beta1.Image{
+						Marketplace: &v1beta1.AzureMarketplaceImage{
+							Publisher: "cncf-upstream",
+							Offer:     "capi",
+							SKU:       "k8s-1dot19dot11-ubuntu-1804",
+							Version:   "latest",
+						},
+					},
+					OSDisk: azure.VMSSDisk{
+						Name: "osdisk",
+					},
+					DataDisks: []azure.VMSSDisk{
+						{
+							Name:       "etcddisk",
+							Lun:        to.Int32Ptr(0),
+							DiskSizeGB: 256,
+						},
+					},
+				}, ampm
+			},
+			Verify: func(g *WithT, scope *MachinePoolMachineScope) {
+				g.Expect(scope.AzureMachinePoolMachine.Status.LatestModelApplied).To(BeFalse())
+				g.Expect(scope.AzureMachinePoolMachine.Status.Disks).To(HaveLen(2))
+			},
+		},
 	}
 
 	for _, c := range cases {
@@ -446,3 +575,73 @@ func getNotReadyNode() *corev1.Node {
 		},
 	}
 }
+
+func TestHasTerminateEventScheduled(t *testing.T) {
+	cases := []struct {
+		Name      string
+		Condition corev1.NodeCondition
+		Expected  bool
+	}{
+		{
+			Name: "terminate event",
+			Condition: corev1.NodeCondition{
+				Type:    azure.VMEventScheduledNodeCondition,
+				Status:  corev1.ConditionTrue,
+				Message: "Terminate scheduled for VM",
+			},
+			Expected: true,
+		},
+		{
+			Name: "preempt event",
+			Condition: corev1.NodeCondition{
+				Type:   azure.VMEventScheduledNodeCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "PreemptScheduled",
+			},
+			Expected: true,
+		},
+		{
+			Name: "reboot event",
+			Condition: corev1.NodeCondition{
+				Type:    azure.VMEventScheduledNodeCondition,
+				Status:  corev1.ConditionTrue,
+				Message: "Reboot scheduled for VM",
+			},
+		},
+		{
+			Name: "no event",
+			Condition: corev1.NodeCondition{
+				Type:    azure.VMEventScheduledNodeCondition,
+				Status:  corev1.ConditionFalse,
+				Message: "Terminate scheduled for VM",
+			},
+		},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			g := NewWithT(t)
+			node := getReadyNode()
+			node.Status.Conditions = append(node.Status.Conditions, c.Condition)
+			g.Expect(hasTerminateEventScheduled(node)).To(Equal(c.Expected))
+		})
+	}
+}
+
+func TestToInstanceHealthState(t *testing.T) {
+	cases := map[string]infrav1.InstanceHealthState{
+		"":             "",
+		"healthy":      infrav1.HealthyInstanceHealthState,
+		"Unhealthy":    infrav1.UnhealthyInstanceHealthState,
+		"initializing": infrav1.InitializingInstanceHealthState,
+		"unknown":      infrav1.UnknownInstanceHealthState,
+		"unexpected":   infrav1.UnknownInstanceHealthState,
+	}
+	for healthState, want := range cases {
+		healthState, want := healthState, want
+		t.Run(healthState, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(toInstanceHealthState(healthState)).To(Equal(want))
+		})
+	}
+}
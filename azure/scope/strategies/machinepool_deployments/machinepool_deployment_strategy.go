@@ -18,6 +18,7 @@ package machinepool
 
 import (
 	"context"
+	"fmt"
 	"math/rand"
 	"sort"
 	"time"
@@ -25,8 +26,11 @@ import (
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
 	infrav1exp "sigs.k8s.io/cluster-api-provider-azure/exp/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
@@ -48,8 +52,31 @@ type (
 		Type() infrav1exp.AzureMachinePoolDeploymentStrategyType
 	}
 
+	// ScaleSetDeployer is the ability to deploy a new scale set with the latest model instead of updating the model of
+	// the existing one, and to check the progress of the deployment.
+	ScaleSetDeployer interface {
+		// CheckDeployment returns whether all the machines of a new scale set are ready, or an error if the deployment
+		// failed and should be rolled back.
+		CheckDeployment(ctx context.Context, desiredReplicas int32, startTime time.Time, machinesByProviderID map[string]infrav1exp.AzureMachinePoolMachine) (bool, error)
+	}
+
+	// RolloutPauser is the ability to pause a rollout while it disrupts the workloads running on the machine pool.
+	RolloutPauser interface {
+		// PauseRollout returns the reason and message describing why the rollout should be paused, or an empty reason
+		// if it can proceed. Machines are only considered for the time elapsed since the given time.
+		PauseRollout(ctx context.Context, since time.Time, machinesByProviderID map[string]infrav1exp.AzureMachinePoolMachine) (string, string)
+	}
+
 	rollingUpdateStrategy struct {
 		infrav1exp.MachineRollingUpdateDeployment
+
+		// platformUpgrade is true when the platform upgrades the instances to the latest model itself, per the upgrade
+		// policy of the scale set, in which case machines are only deleted to lower the replica count.
+		platformUpgrade bool
+	}
+
+	blueGreenStrategy struct {
+		infrav1exp.MachineBlueGreenDeployment
 	}
 )
 
@@ -66,6 +93,15 @@ func NewMachinePoolDeploymentStrategy(strategy infrav1exp.AzureMachinePoolDeploy
 		return &rollingUpdateStrategy{
 			MachineRollingUpdateDeployment: *rollingUpdate,
 		}
+	case infrav1exp.BlueGreenAzureMachinePoolDeploymentStrategyType:
+		blueGreen := strategy.BlueGreen
+		if blueGreen == nil {
+			blueGreen = &infrav1exp.MachineBlueGreenDeployment{}
+		}
+
+		return &blueGreenStrategy{
+			MachineBlueGreenDeployment: *blueGreen,
+		}
 	default:
 		// default to a rolling update strategy if unknown type
 		return &rollingUpdateStrategy{
@@ -74,6 +110,21 @@ func NewMachinePoolDeploymentStrategy(strategy infrav1exp.AzureMachinePoolDeploy
 	}
 }
 
+// NewPlatformUpgradeStrategy constructs a rolling update strategy for a machine pool whose instances are upgraded by
+// the platform. It neither surges nor replaces machines without the latest model, and only deletes machines which
+// failed, are being deleted, or exceed the desired replica count, following the DeletePolicy.
+func NewPlatformUpgradeStrategy(strategy infrav1exp.AzureMachinePoolDeploymentStrategy) TypedDeleteSelector {
+	rollingUpdate := strategy.RollingUpdate
+	if rollingUpdate == nil || strategy.Type == infrav1exp.BlueGreenAzureMachinePoolDeploymentStrategyType {
+		rollingUpdate = &infrav1exp.MachineRollingUpdateDeployment{}
+	}
+
+	return &rollingUpdateStrategy{
+		MachineRollingUpdateDeployment: *rollingUpdate,
+		platformUpgrade:                true,
+	}
+}
+
 // Type is the AzureMachinePoolDeploymentStrategyType for the strategy.
 func (rollingUpdateStrategy *rollingUpdateStrategy) Type() infrav1exp.AzureMachinePoolDeploymentStrategyType {
 	return infrav1exp.RollingUpdateAzureMachinePoolDeploymentStrategyType
@@ -81,6 +132,10 @@ func (rollingUpdateStrategy *rollingUpdateStrategy) Type() infrav1exp.AzureMachi
 
 // Surge calculates the number of replicas that can be added during an upgrade operation.
 func (rollingUpdateStrategy *rollingUpdateStrategy) Surge(desiredReplicaCount int) (int, error) {
+	if rollingUpdateStrategy.platformUpgrade {
+		return 0, nil
+	}
+
 	if rollingUpdateStrategy.MaxSurge == nil {
 		return 1, nil
 	}
@@ -103,7 +158,8 @@ func (rollingUpdateStrategy *rollingUpdateStrategy) maxUnavailable(desiredReplic
 }
 
 // SelectMachinesToDelete selects the machines to delete based on the machine state, desired replica count, and
-// the DeletePolicy.
+// the DeletePolicy. Ready machines protected with the azure.InstanceProtectionAnnotation are not selected, while the
+// protected machines which failed are still replaced.
 func (rollingUpdateStrategy rollingUpdateStrategy) SelectMachinesToDelete(ctx context.Context, desiredReplicaCount int32, machinesByProviderID map[string]infrav1exp.AzureMachinePoolMachine) ([]infrav1exp.AzureMachinePoolMachine, error) {
 	ctx, _, done := tele.StartSpanWithLogger(
 		ctx,
@@ -118,20 +174,28 @@ func (rollingUpdateStrategy rollingUpdateStrategy) SelectMachinesToDelete(ctx co
 
 	var (
 		order = func() func(machines []infrav1exp.AzureMachinePoolMachine) []infrav1exp.AzureMachinePoolMachine {
-			switch rollingUpdateStrategy.DeletePolicy {
-			case infrav1exp.OldestDeletePolicyType:
-				return orderByOldest
-			case infrav1exp.NewestDeletePolicyType:
-				return orderByNewest
-			default:
-				return orderRandom
+			policyOrder := func() func(machines []infrav1exp.AzureMachinePoolMachine) []infrav1exp.AzureMachinePoolMachine {
+				switch rollingUpdateStrategy.DeletePolicy {
+				case infrav1exp.OldestDeletePolicyType:
+					return orderByOldest
+				case infrav1exp.NewestDeletePolicyType:
+					return orderByNewest
+				default:
+					return orderRandom
+				}
+			}()
+
+			if rollingUpdateStrategy.BalanceZones {
+				return orderByZoneBalance(machinesByProviderID, policyOrder)
 			}
+
+			return policyOrder
 		}()
 		log                        = ctrl.LoggerFrom(ctx).V(4)
 		failedMachines             = order(getFailedMachines(machinesByProviderID))
 		deletingMachines           = order(getDeletingMachines(machinesByProviderID))
 		readyMachines              = order(getReadyMachines(machinesByProviderID))
-		machinesWithoutLatestModel = order(getMachinesWithoutLatestModel(machinesByProviderID))
+		machinesWithoutLatestModel = order(withoutProtectedMachines(getMachinesWithoutLatestModel(machinesByProviderID)))
 		overProvisionCount         = len(readyMachines) - int(desiredReplicaCount)
 		disruptionBudget           = func() int {
 			if maxUnavailable > int(desiredReplicaCount) {
@@ -185,7 +249,7 @@ func (rollingUpdateStrategy rollingUpdateStrategy) SelectMachinesToDelete(ctx co
 
 		log.Info("over-provisioned ready", "desiredReplicaCount", desiredReplicaCount, "overProvisionCount", overProvisionCount, "readyMachines", getProviderIDs(readyMachines))
 		// remove ready machines
-		for _, v := range readyMachines {
+		for _, v := range withoutProtectedMachines(readyMachines) {
 			if len(toDelete) >= overProvisionCount {
 				return toDelete, nil
 			}
@@ -196,6 +260,11 @@ func (rollingUpdateStrategy rollingUpdateStrategy) SelectMachinesToDelete(ctx co
 		return toDelete, nil
 	}
 
+	if rollingUpdateStrategy.platformUpgrade {
+		log.Info("nothing more to do since the AzureMachinePoolMachine(s) are upgraded by the platform and not over-provisioned")
+		return []infrav1exp.AzureMachinePoolMachine{}, nil
+	}
+
 	if len(machinesWithoutLatestModel) == 0 {
 		log.Info("nothing more to do since all the AzureMachinePoolMachine(s) are the latest model and not over-provisioned")
 		return []infrav1exp.AzureMachinePoolMachine{}, nil
@@ -208,20 +277,252 @@ func (rollingUpdateStrategy rollingUpdateStrategy) SelectMachinesToDelete(ctx co
 
 	var toDelete []infrav1exp.AzureMachinePoolMachine
 	log.Info("removing ready machines within disruption budget", "desiredReplicaCount", desiredReplicaCount, "maxUnavailable", maxUnavailable, "readyMachines", getProviderIDs(readyMachines), "readyMachinesCount", len(readyMachines))
-	for _, v := range readyMachines {
+	var readyMachinesWithoutLatestModel []infrav1exp.AzureMachinePoolMachine
+	for _, v := range withoutProtectedMachines(readyMachines) {
+		if !v.Status.LatestModelApplied {
+			readyMachinesWithoutLatestModel = append(readyMachinesWithoutLatestModel, v)
+		}
+	}
+
+	for _, v := range order(readyMachinesWithoutLatestModel) {
 		if len(toDelete) >= disruptionBudget {
 			return toDelete, nil
 		}
 
-		if !v.Status.LatestModelApplied {
-			toDelete = append(toDelete, v)
-		}
+		toDelete = append(toDelete, v)
 	}
 
 	log.Info("completed without filling toDelete", "toDelete", getProviderIDs(toDelete), "numToDelete", len(toDelete))
 	return toDelete, nil
 }
 
+// PauseRollout pauses the rolling update when the drain of a machine being deleted is blocked for longer than the
+// DrainBlockedTimeout, or when a machine with the latest model isn't ready within the NodeReadyTimeout.
+func (rollingUpdateStrategy *rollingUpdateStrategy) PauseRollout(ctx context.Context, since time.Time, machinesByProviderID map[string]infrav1exp.AzureMachinePoolMachine) (string, string) {
+	ctx, _, done := tele.StartSpanWithLogger(
+		ctx,
+		"strategies.rollingUpdateStrategy.PauseRollout",
+	)
+	defer done()
+
+	if rollingUpdateStrategy.platformUpgrade {
+		// the rollout is driven by the platform
+		return "", ""
+	}
+
+	var (
+		log = ctrl.LoggerFrom(ctx).V(4)
+		now = time.Now()
+		// elapsed is the time elapsed since the start time, or since the rollout was last resumed if later
+		elapsed = func(start time.Time) time.Duration {
+			if start.Before(since) {
+				start = since
+			}
+
+			return now.Sub(start)
+		}
+	)
+
+	if timeout := rollingUpdateStrategy.DrainBlockedTimeout; timeout != nil {
+		for _, v := range orderByOldest(getDrainingMachines(machinesByProviderID)) {
+			v := v
+			drainStart := conditions.GetLastTransitionTime(&v, clusterv1.DrainingSucceededCondition)
+			if drainStart != nil && elapsed(drainStart.Time) > timeout.Duration {
+				log.Info("pausing rollout due to a blocked drain", "providerID", v.Spec.ProviderID, "drainStart", drainStart)
+				return infrav1.DrainBlockedReason, fmt.Sprintf("draining the node of machine %s has been blocked for more than %s", v.Name, timeout.Duration)
+			}
+		}
+	}
+
+	if timeout := rollingUpdateStrategy.NodeReadyTimeout; timeout != nil {
+		for _, v := range orderByOldest(getNotReadyMachinesWithLatestModel(machinesByProviderID)) {
+			if elapsed(v.CreationTimestamp.Time) > timeout.Duration {
+				log.Info("pausing rollout due to a node which isn't ready", "providerID", v.Spec.ProviderID, "creationTimestamp", v.CreationTimestamp)
+				return infrav1.NodeNotReadyReason, fmt.Sprintf("the node of machine %s did not become ready within %s", v.Name, timeout.Duration)
+			}
+		}
+	}
+
+	return "", ""
+}
+
+// Type is the AzureMachinePoolDeploymentStrategyType for the strategy.
+func (blueGreenStrategy *blueGreenStrategy) Type() infrav1exp.AzureMachinePoolDeploymentStrategyType {
+	return infrav1exp.BlueGreenAzureMachinePoolDeploymentStrategyType
+}
+
+// readyTimeout is the time the machines of a new scale set have to become ready.
+func (blueGreenStrategy *blueGreenStrategy) readyTimeout() time.Duration {
+	if blueGreenStrategy.ReadyTimeout == nil {
+		return 30 * time.Minute
+	}
+
+	return blueGreenStrategy.ReadyTimeout.Duration
+}
+
+// CheckDeployment returns whether all the machines of a new scale set are ready. The deployment fails if any of them
+// failed to provision or is reported unhealthy, or if they are not all ready within the ready timeout.
+func (blueGreenStrategy *blueGreenStrategy) CheckDeployment(ctx context.Context, desiredReplicaCount int32, startTime time.Time, machinesByProviderID map[string]infrav1exp.AzureMachinePoolMachine) (bool, error) {
+	ctx, _, done := tele.StartSpanWithLogger(
+		ctx,
+		"strategies.blueGreenStrategy.CheckDeployment",
+	)
+	defer done()
+
+	var (
+		log            = ctrl.LoggerFrom(ctx).V(4)
+		failedMachines = getFailedMachines(machinesByProviderID)
+		readyMachines  = getReadyMachines(machinesByProviderID)
+	)
+
+	for _, v := range machinesByProviderID {
+		if v.Status.HealthState == infrav1exp.UnhealthyInstanceHealthState {
+			failedMachines = append(failedMachines, v)
+		}
+	}
+
+	log.Info("checking deployment",
+		"readyMachines", len(readyMachines),
+		"desiredReplicaCount", desiredReplicaCount,
+		"failedMachines", len(failedMachines),
+		"startTime", startTime,
+	)
+
+	if len(failedMachines) > 0 {
+		return false, errors.Errorf("machines %v of the new scale set failed", getProviderIDs(failedMachines))
+	}
+
+	if len(readyMachines) >= int(desiredReplicaCount) {
+		return true, nil
+	}
+
+	if time.Since(startTime) > blueGreenStrategy.readyTimeout() {
+		return false, errors.Errorf("only %d of %d machines of the new scale set became ready within %s", len(readyMachines), desiredReplicaCount, blueGreenStrategy.readyTimeout())
+	}
+
+	return false, nil
+}
+
+// SelectMachinesToDelete selects the failed and deleting machines, and the oldest ready machines above the desired
+// replica count. Machines without the latest model are not selected, as they are replaced by deploying a new scale set,
+// nor are the ready machines protected with the azure.InstanceProtectionAnnotation.
+func (blueGreenStrategy *blueGreenStrategy) SelectMachinesToDelete(ctx context.Context, desiredReplicaCount int32, machinesByProviderID map[string]infrav1exp.AzureMachinePoolMachine) ([]infrav1exp.AzureMachinePoolMachine, error) {
+	ctx, _, done := tele.StartSpanWithLogger(
+		ctx,
+		"strategies.blueGreenStrategy.SelectMachinesToDelete",
+	)
+	defer done()
+
+	var (
+		log                = ctrl.LoggerFrom(ctx).V(4)
+		failedMachines     = orderByOldest(getFailedMachines(machinesByProviderID))
+		deletingMachines   = orderByOldest(getDeletingMachines(machinesByProviderID))
+		readyMachines      = orderByOldest(getReadyMachines(machinesByProviderID))
+		overProvisionCount = len(readyMachines) - int(desiredReplicaCount)
+		deletableMachines  = withoutProtectedMachines(readyMachines)
+	)
+
+	if len(failedMachines) > 0 || len(deletingMachines) > 0 {
+		log.Info("failed or deleting machines", "desiredReplicaCount", desiredReplicaCount, "failedMachines", getProviderIDs(failedMachines), "deletingMachines", getProviderIDs(deletingMachines))
+		return append(failedMachines, deletingMachines...), nil
+	}
+
+	if overProvisionCount > 0 {
+		log.Info("over-provisioned ready", "desiredReplicaCount", desiredReplicaCount, "overProvisionCount", overProvisionCount, "readyMachines", getProviderIDs(readyMachines))
+		if overProvisionCount > len(deletableMachines) {
+			overProvisionCount = len(deletableMachines)
+		}
+		return deletableMachines[:overProvisionCount], nil
+	}
+
+	return []infrav1exp.AzureMachinePoolMachine{}, nil
+}
+
+// orderByZoneBalance returns an order in which the machines are taken from the availability zone with the most
+// machines first, so that the remaining machines stay evenly spread across the zones. The machines of a zone are
+// ordered by policyOrder, and zones with the same number of machines are ordered by name.
+func orderByZoneBalance(machinesByProviderID map[string]infrav1exp.AzureMachinePoolMachine, policyOrder func(machines []infrav1exp.AzureMachinePoolMachine) []infrav1exp.AzureMachinePoolMachine) func(machines []infrav1exp.AzureMachinePoolMachine) []infrav1exp.AzureMachinePoolMachine {
+	return func(machines []infrav1exp.AzureMachinePoolMachine) []infrav1exp.AzureMachinePoolMachine {
+		// count the machines of each zone which are not already being deleted
+		machinesPerZone := make(map[string]int)
+		for _, v := range machinesByProviderID {
+			if v.DeletionTimestamp.IsZero() {
+				machinesPerZone[v.Status.AvailabilityZone]++
+			}
+		}
+
+		candidatesByZone := make(map[string][]infrav1exp.AzureMachinePoolMachine)
+		var zones []string
+		for _, v := range policyOrder(machines) {
+			zone := v.Status.AvailabilityZone
+			if _, ok := candidatesByZone[zone]; !ok {
+				zones = append(zones, zone)
+			}
+			candidatesByZone[zone] = append(candidatesByZone[zone], v)
+		}
+		sort.Strings(zones)
+
+		ordered := make([]infrav1exp.AzureMachinePoolMachine, 0, len(machines))
+		for len(ordered) < len(machines) {
+			var next string
+			found := false
+			for _, zone := range zones {
+				if len(candidatesByZone[zone]) == 0 {
+					continue
+				}
+				if !found || machinesPerZone[zone] > machinesPerZone[next] {
+					next = zone
+					found = true
+				}
+			}
+
+			ordered = append(ordered, candidatesByZone[next][0])
+			candidatesByZone[next] = candidatesByZone[next][1:]
+			machinesPerZone[next]--
+		}
+
+		return ordered
+	}
+}
+
+// withoutProtectedMachines returns the machines which are not protected from scale-in with the
+// azure.InstanceProtectionAnnotation, keeping their order. The protection only spares the ready machines, so that the
+// protected machines which failed, aren't healthy or aren't ready are still replaced.
+func withoutProtectedMachines(machines []infrav1exp.AzureMachinePoolMachine) []infrav1exp.AzureMachinePoolMachine {
+	var unprotected []infrav1exp.AzureMachinePoolMachine
+	for _, v := range machines {
+		if isProtected(v) && isReady(v) {
+			continue
+		}
+		unprotected = append(unprotected, v)
+	}
+
+	return unprotected
+}
+
+// GetProtectedMachines returns the machines protected from scale-in with the azure.InstanceProtectionAnnotation which
+// are not marked for deletion yet, ordered from the oldest.
+func GetProtectedMachines(machinesByProviderID map[string]infrav1exp.AzureMachinePoolMachine) []infrav1exp.AzureMachinePoolMachine {
+	var machines []infrav1exp.AzureMachinePoolMachine
+	for _, v := range machinesByProviderID {
+		if isProtected(v) && v.DeletionTimestamp.IsZero() {
+			machines = append(machines, v)
+		}
+	}
+
+	return orderByOldest(machines)
+}
+
+// isProtected returns true if the machine is protected from scale-in with the azure.InstanceProtectionAnnotation.
+func isProtected(machine infrav1exp.AzureMachinePoolMachine) bool {
+	switch machine.Annotations[azure.InstanceProtectionAnnotation] {
+	case azure.InstanceProtectionScaleIn, azure.InstanceProtectionScaleSetActions:
+		return true
+	default:
+		return false
+	}
+}
+
 func getFailedMachines(machinesByProviderID map[string]infrav1exp.AzureMachinePoolMachine) []infrav1exp.AzureMachinePoolMachine {
 	var machines []infrav1exp.AzureMachinePoolMachine
 	for _, v := range machinesByProviderID {
@@ -234,6 +535,35 @@ func getFailedMachines(machinesByProviderID map[string]infrav1exp.AzureMachinePo
 	return machines
 }
 
+// getDrainingMachines returns the machines marked for deletion whose node is being drained.
+func getDrainingMachines(machinesByProviderID map[string]infrav1exp.AzureMachinePoolMachine) []infrav1exp.AzureMachinePoolMachine {
+	var machines []infrav1exp.AzureMachinePoolMachine
+	for _, v := range machinesByProviderID {
+		v := v
+		if !v.DeletionTimestamp.IsZero() && conditions.IsFalse(&v, clusterv1.DrainingSucceededCondition) {
+			machines = append(machines, v)
+		}
+	}
+
+	return machines
+}
+
+// getNotReadyMachinesWithLatestModel returns the machines running the latest model whose node isn't ready, excluding
+// the machines whose VM failed to provision as they are replaced.
+func getNotReadyMachinesWithLatestModel(machinesByProviderID map[string]infrav1exp.AzureMachinePoolMachine) []infrav1exp.AzureMachinePoolMachine {
+	var machines []infrav1exp.AzureMachinePoolMachine
+	for _, v := range machinesByProviderID {
+		if v.Status.LatestModelApplied &&
+			!v.Status.Ready &&
+			v.DeletionTimestamp.IsZero() &&
+			(v.Status.ProvisioningState == nil || *v.Status.ProvisioningState != infrav1.Failed) {
+			machines = append(machines, v)
+		}
+	}
+
+	return machines
+}
+
 // getDeletingMachines is responsible for identifying machines whose VMs are in an active state of deletion
 // but whose corresponding AzureMachinePoolMachine resource has not yet been marked for deletion.
 func getDeletingMachines(machinesByProviderID map[string]infrav1exp.AzureMachinePoolMachine) []infrav1exp.AzureMachinePoolMachine {
@@ -254,13 +584,7 @@ func getDeletingMachines(machinesByProviderID map[string]infrav1exp.AzureMachine
 func getReadyMachines(machinesByProviderID map[string]infrav1exp.AzureMachinePoolMachine) []infrav1exp.AzureMachinePoolMachine {
 	var readyMachines []infrav1exp.AzureMachinePoolMachine
 	for _, v := range machinesByProviderID {
-		// ready status, with provisioning state Succeeded, and not marked for delete
-		if v.Status.Ready &&
-			(v.Status.ProvisioningState != nil && *v.Status.ProvisioningState == infrav1.Succeeded) &&
-			// Don't include machines that have already been marked for delete
-			v.DeletionTimestamp.IsZero() &&
-			// Don't include machines whose VMs are in an active state of deleting
-			*v.Status.ProvisioningState != infrav1.Deleting {
+		if isReady(v) {
 			readyMachines = append(readyMachines, v)
 		}
 	}
@@ -268,6 +592,19 @@ func getReadyMachines(machinesByProviderID map[string]infrav1exp.AzureMachinePoo
 	return readyMachines
 }
 
+// isReady returns true if the machine is ready, healthy and not being deleted.
+func isReady(v infrav1exp.AzureMachinePoolMachine) bool {
+	// ready status, with provisioning state Succeeded, and not marked for delete
+	return v.Status.Ready &&
+		(v.Status.ProvisioningState != nil && *v.Status.ProvisioningState == infrav1.Succeeded) &&
+		// Don't include machines that have already been marked for delete
+		v.DeletionTimestamp.IsZero() &&
+		// Don't include machines whose VMs are in an active state of deleting
+		*v.Status.ProvisioningState != infrav1.Deleting &&
+		// Don't include machines the Application Health extension doesn't report as healthy
+		(v.Status.HealthState == "" || v.Status.HealthState == infrav1exp.HealthyInstanceHealthState)
+}
+
 func getMachinesWithoutLatestModel(machinesByProviderID map[string]infrav1exp.AzureMachinePoolMachine) []infrav1exp.AzureMachinePoolMachine {
 	var machinesWithLatestModel []infrav1exp.AzureMachinePoolMachine
 	for _, v := range machinesByProviderID {
@@ -23,11 +23,14 @@ import (
 
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/types"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
 	infrav1exp "sigs.k8s.io/cluster-api-provider-azure/exp/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/internal/test/matchers/gomega"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 )
 
 func TestMachinePoolRollingUpdateStrategy_Type(t *testing.T) {
@@ -211,6 +214,17 @@ func TestMachinePoolRollingUpdateStrategy_SelectMachinesToDelete(t *testing.T) {
 				makeAMPM(ampmOptions{Ready: true, LatestModel: false, ProvisioningState: succeeded}),
 			}),
 		},
+		{
+			name:            "should not select machines to delete if healthy machines are less than desired replica count",
+			strategy:        makeRollingUpdateStrategy(infrav1exp.MachineRollingUpdateDeployment{}),
+			desiredReplicas: 2,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, HealthState: infrav1exp.HealthyInstanceHealthState}),
+				"bin": makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, HealthState: infrav1exp.InitializingInstanceHealthState}),
+				"baz": makeAMPM(ampmOptions{Ready: true, LatestModel: false, ProvisioningState: succeeded, HealthState: infrav1exp.UnhealthyInstanceHealthState}),
+			},
+			want: Equal([]infrav1exp.AzureMachinePoolMachine{}),
+		},
 		{
 			name:            "if over-provisioned, select a machine with an out-of-date model when using Random Delete Policy",
 			strategy:        makeRollingUpdateStrategy(infrav1exp.MachineRollingUpdateDeployment{DeletePolicy: infrav1exp.RandomDeletePolicyType}),
@@ -252,6 +266,32 @@ func TestMachinePoolRollingUpdateStrategy_SelectMachinesToDelete(t *testing.T) {
 				makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(2 * time.Hour))}),
 			}),
 		},
+		{
+			name:            "if over-provisioned, do not select protected machines",
+			strategy:        makeRollingUpdateStrategy(infrav1exp.MachineRollingUpdateDeployment{DeletePolicy: infrav1exp.OldestDeletePolicyType}),
+			desiredReplicas: 1,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(1 * time.Hour)), Protection: azure.InstanceProtectionScaleIn}),
+				"bin": makeAMPM(ampmOptions{Ready: true, LatestModel: false, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(2 * time.Hour)), Protection: azure.InstanceProtectionScaleSetActions}),
+				"baz": makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(3 * time.Hour))}),
+			},
+			want: gomega.DiffEq([]infrav1exp.AzureMachinePoolMachine{
+				makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(3 * time.Hour))}),
+			}),
+		},
+		{
+			name:            "select protected machines which failed",
+			strategy:        makeRollingUpdateStrategy(infrav1exp.MachineRollingUpdateDeployment{DeletePolicy: infrav1exp.OldestDeletePolicyType}),
+			desiredReplicas: 2,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(1 * time.Hour)), Protection: azure.InstanceProtectionScaleIn}),
+				"bin": makeAMPM(ampmOptions{LatestModel: true, ProvisioningState: infrav1.Failed, CreationTime: metav1.NewTime(baseTime.Add(2 * time.Hour)), Protection: azure.InstanceProtectionScaleSetActions}),
+				"baz": makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(3 * time.Hour))}),
+			},
+			want: gomega.DiffEq([]infrav1exp.AzureMachinePoolMachine{
+				makeAMPM(ampmOptions{LatestModel: true, ProvisioningState: infrav1.Failed, CreationTime: metav1.NewTime(baseTime.Add(2 * time.Hour)), Protection: azure.InstanceProtectionScaleSetActions}),
+			}),
+		},
 		{
 			name:            "if over-provisioned, select machines ordered by newest first",
 			strategy:        makeRollingUpdateStrategy(infrav1exp.MachineRollingUpdateDeployment{DeletePolicy: infrav1exp.NewestDeletePolicyType}),
@@ -369,6 +409,52 @@ func TestMachinePoolRollingUpdateStrategy_SelectMachinesToDelete(t *testing.T) {
 			},
 			want: HaveLen(1),
 		},
+		{
+			name:            "if over-provisioned with balanced zones, select the oldest machines of the zones with the most machines",
+			strategy:        makeRollingUpdateStrategy(infrav1exp.MachineRollingUpdateDeployment{DeletePolicy: infrav1exp.OldestDeletePolicyType, BalanceZones: true}),
+			desiredReplicas: 3,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo":  makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, AvailabilityZone: "1", CreationTime: metav1.NewTime(baseTime.Add(1 * time.Hour))}),
+				"bin":  makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, AvailabilityZone: "2", CreationTime: metav1.NewTime(baseTime.Add(2 * time.Hour))}),
+				"baz":  makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, AvailabilityZone: "2", CreationTime: metav1.NewTime(baseTime.Add(3 * time.Hour))}),
+				"bar":  makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, AvailabilityZone: "2", CreationTime: metav1.NewTime(baseTime.Add(4 * time.Hour))}),
+				"qux":  makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, AvailabilityZone: "3", CreationTime: metav1.NewTime(baseTime.Add(5 * time.Hour))}),
+				"quux": makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, AvailabilityZone: "3", CreationTime: metav1.NewTime(baseTime.Add(6 * time.Hour))}),
+			},
+			want: gomega.DiffEq([]infrav1exp.AzureMachinePoolMachine{
+				makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, AvailabilityZone: "2", CreationTime: metav1.NewTime(baseTime.Add(2 * time.Hour))}),
+				makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, AvailabilityZone: "2", CreationTime: metav1.NewTime(baseTime.Add(3 * time.Hour))}),
+				makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, AvailabilityZone: "3", CreationTime: metav1.NewTime(baseTime.Add(5 * time.Hour))}),
+			}),
+		},
+		{
+			name:            "if over-provisioned with balanced zones, break ties between zones by name and within a zone by the delete policy",
+			strategy:        makeRollingUpdateStrategy(infrav1exp.MachineRollingUpdateDeployment{DeletePolicy: infrav1exp.NewestDeletePolicyType, BalanceZones: true}),
+			desiredReplicas: 2,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, AvailabilityZone: "1", CreationTime: metav1.NewTime(baseTime.Add(1 * time.Hour))}),
+				"bin": makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, AvailabilityZone: "1", CreationTime: metav1.NewTime(baseTime.Add(2 * time.Hour))}),
+				"baz": makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, AvailabilityZone: "2", CreationTime: metav1.NewTime(baseTime.Add(3 * time.Hour))}),
+				"bar": makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, AvailabilityZone: "2", CreationTime: metav1.NewTime(baseTime.Add(4 * time.Hour))}),
+			},
+			want: gomega.DiffEq([]infrav1exp.AzureMachinePoolMachine{
+				makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, AvailabilityZone: "1", CreationTime: metav1.NewTime(baseTime.Add(2 * time.Hour))}),
+				makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, AvailabilityZone: "2", CreationTime: metav1.NewTime(baseTime.Add(4 * time.Hour))}),
+			}),
+		},
+		{
+			name:            "with balanced zones, select machines with an out-of-date model from the zone with the most machines",
+			strategy:        makeRollingUpdateStrategy(infrav1exp.MachineRollingUpdateDeployment{DeletePolicy: infrav1exp.OldestDeletePolicyType, MaxUnavailable: &one, BalanceZones: true}),
+			desiredReplicas: 3,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{Ready: true, LatestModel: false, ProvisioningState: succeeded, AvailabilityZone: "1", CreationTime: metav1.NewTime(baseTime.Add(1 * time.Hour))}),
+				"bin": makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, AvailabilityZone: "2", CreationTime: metav1.NewTime(baseTime.Add(2 * time.Hour))}),
+				"baz": makeAMPM(ampmOptions{Ready: true, LatestModel: false, ProvisioningState: succeeded, AvailabilityZone: "2", CreationTime: metav1.NewTime(baseTime.Add(3 * time.Hour))}),
+			},
+			want: gomega.DiffEq([]infrav1exp.AzureMachinePoolMachine{
+				makeAMPM(ampmOptions{Ready: true, LatestModel: false, ProvisioningState: succeeded, AvailabilityZone: "2", CreationTime: metav1.NewTime(baseTime.Add(3 * time.Hour))}),
+			}),
+		},
 		{
 			name:            "if maxUnavailable is 30%, and there are 2 with the latest model == false, delete 0.",
 			strategy:        makeRollingUpdateStrategy(infrav1exp.MachineRollingUpdateDeployment{MaxUnavailable: &thirtyPercent}),
@@ -396,30 +482,468 @@ func TestMachinePoolRollingUpdateStrategy_SelectMachinesToDelete(t *testing.T) {
 	}
 }
 
+func TestMachinePoolRollingUpdateStrategy_PauseRollout(t *testing.T) {
+	var (
+		succeeded    = infrav1.Succeeded
+		failed       = infrav1.Failed
+		now          = time.Now()
+		tenMinutes   = &metav1.Duration{Duration: 10 * time.Minute}
+		deleteTime   = metav1.NewTime(now)
+		elevenMinAgo = metav1.NewTime(now.Add(-11 * time.Minute))
+		fiveMinAgo   = metav1.NewTime(now.Add(-5 * time.Minute))
+	)
+
+	tests := []struct {
+		name       string
+		strategy   RolloutPauser
+		since      time.Time
+		input      map[string]infrav1exp.AzureMachinePoolMachine
+		wantReason string
+	}{
+		{
+			name:     "should not pause without timeouts",
+			strategy: makeRollingUpdateStrategy(infrav1exp.MachineRollingUpdateDeployment{}),
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": withDrainingCondition(makeAMPM(ampmOptions{Ready: true, ProvisioningState: succeeded, DeletionTime: &deleteTime}), elevenMinAgo),
+				"bar": makeAMPM(ampmOptions{LatestModel: true, ProvisioningState: succeeded, CreationTime: elevenMinAgo}),
+			},
+			wantReason: "",
+		},
+		{
+			name:     "should pause when a drain is blocked for longer than the timeout",
+			strategy: makeRollingUpdateStrategy(infrav1exp.MachineRollingUpdateDeployment{DrainBlockedTimeout: tenMinutes}),
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": withDrainingCondition(makeAMPM(ampmOptions{Ready: true, ProvisioningState: succeeded, DeletionTime: &deleteTime}), elevenMinAgo),
+			},
+			wantReason: infrav1.DrainBlockedReason,
+		},
+		{
+			name:     "should not pause while a drain is within the timeout",
+			strategy: makeRollingUpdateStrategy(infrav1exp.MachineRollingUpdateDeployment{DrainBlockedTimeout: tenMinutes}),
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": withDrainingCondition(makeAMPM(ampmOptions{Ready: true, ProvisioningState: succeeded, DeletionTime: &deleteTime}), fiveMinAgo),
+			},
+			wantReason: "",
+		},
+		{
+			name:     "should not pause for a blocked drain within the timeout since the rollout was resumed",
+			strategy: makeRollingUpdateStrategy(infrav1exp.MachineRollingUpdateDeployment{DrainBlockedTimeout: tenMinutes}),
+			since:    fiveMinAgo.Time,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": withDrainingCondition(makeAMPM(ampmOptions{Ready: true, ProvisioningState: succeeded, DeletionTime: &deleteTime}), elevenMinAgo),
+			},
+			wantReason: "",
+		},
+		{
+			name:     "should pause when a node with the latest model isn't ready within the timeout",
+			strategy: makeRollingUpdateStrategy(infrav1exp.MachineRollingUpdateDeployment{NodeReadyTimeout: tenMinutes}),
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{Ready: true, ProvisioningState: succeeded, CreationTime: elevenMinAgo}),
+				"bar": makeAMPM(ampmOptions{LatestModel: true, ProvisioningState: succeeded, CreationTime: elevenMinAgo}),
+			},
+			wantReason: infrav1.NodeNotReadyReason,
+		},
+		{
+			name:     "should not pause for nodes without the latest model, failed or within the timeout",
+			strategy: makeRollingUpdateStrategy(infrav1exp.MachineRollingUpdateDeployment{NodeReadyTimeout: tenMinutes}),
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{LatestModel: false, ProvisioningState: succeeded, CreationTime: elevenMinAgo}),
+				"bar": makeAMPM(ampmOptions{LatestModel: true, ProvisioningState: failed, CreationTime: elevenMinAgo}),
+				"baz": makeAMPM(ampmOptions{LatestModel: true, ProvisioningState: succeeded, CreationTime: fiveMinAgo}),
+				"qux": makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: elevenMinAgo}),
+			},
+			wantReason: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			reason, message := tt.strategy.PauseRollout(context.Background(), tt.since, tt.input)
+			g.Expect(reason).To(Equal(tt.wantReason))
+			if tt.wantReason == "" {
+				g.Expect(message).To(BeEmpty())
+			} else {
+				g.Expect(message).NotTo(BeEmpty())
+			}
+		})
+	}
+}
+
+func TestMachinePoolBlueGreenStrategy_Type(t *testing.T) {
+	g := NewWithT(t)
+	strategy := NewMachinePoolDeploymentStrategy(infrav1exp.AzureMachinePoolDeploymentStrategy{
+		Type: infrav1exp.BlueGreenAzureMachinePoolDeploymentStrategyType,
+	})
+	g.Expect(strategy.Type()).To(Equal(infrav1exp.BlueGreenAzureMachinePoolDeploymentStrategyType))
+	_, isSurger := strategy.(Surger)
+	g.Expect(isSurger).To(BeFalse())
+	_, isScaleSetDeployer := strategy.(ScaleSetDeployer)
+	g.Expect(isScaleSetDeployer).To(BeTrue())
+}
+
+func TestMachinePoolBlueGreenStrategy_CheckDeployment(t *testing.T) {
+	var (
+		succeeded = infrav1.Succeeded
+		failed    = infrav1.Failed
+		creating  = infrav1.Creating
+		now       = time.Now()
+	)
+
+	tests := []struct {
+		name            string
+		strategy        ScaleSetDeployer
+		startTime       time.Time
+		input           map[string]infrav1exp.AzureMachinePoolMachine
+		desiredReplicas int32
+		want            bool
+		errStr          string
+	}{
+		{
+			name:            "should be done when all the machines are ready",
+			strategy:        makeBlueGreenStrategy(infrav1exp.MachineBlueGreenDeployment{}),
+			startTime:       now,
+			desiredReplicas: 2,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{Ready: true, ProvisioningState: succeeded}),
+				"bar": makeAMPM(ampmOptions{Ready: true, ProvisioningState: succeeded, HealthState: infrav1exp.HealthyInstanceHealthState}),
+			},
+			want: true,
+		},
+		{
+			name:            "should not be done while machines are still provisioning",
+			strategy:        makeBlueGreenStrategy(infrav1exp.MachineBlueGreenDeployment{}),
+			startTime:       now,
+			desiredReplicas: 2,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{Ready: true, ProvisioningState: succeeded}),
+				"bar": makeAMPM(ampmOptions{ProvisioningState: creating}),
+			},
+			want: false,
+		},
+		{
+			name:            "should not be done while machines are initializing",
+			strategy:        makeBlueGreenStrategy(infrav1exp.MachineBlueGreenDeployment{}),
+			startTime:       now,
+			desiredReplicas: 2,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{Ready: true, ProvisioningState: succeeded}),
+				"bar": makeAMPM(ampmOptions{Ready: true, ProvisioningState: succeeded, HealthState: infrav1exp.InitializingInstanceHealthState}),
+			},
+			want: false,
+		},
+		{
+			name:            "should fail if a machine failed to provision",
+			strategy:        makeBlueGreenStrategy(infrav1exp.MachineBlueGreenDeployment{}),
+			startTime:       now,
+			desiredReplicas: 1,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{ProvisioningState: failed}),
+			},
+			errStr: "machines [] of the new scale set failed",
+		},
+		{
+			name:            "should fail if a machine is unhealthy",
+			strategy:        makeBlueGreenStrategy(infrav1exp.MachineBlueGreenDeployment{}),
+			startTime:       now,
+			desiredReplicas: 1,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{Ready: true, ProvisioningState: succeeded, HealthState: infrav1exp.UnhealthyInstanceHealthState}),
+			},
+			errStr: "machines [] of the new scale set failed",
+		},
+		{
+			name:            "should fail if the machines are not ready within the ready timeout",
+			strategy:        makeBlueGreenStrategy(infrav1exp.MachineBlueGreenDeployment{ReadyTimeout: &metav1.Duration{Duration: 10 * time.Minute}}),
+			startTime:       now.Add(-11 * time.Minute),
+			desiredReplicas: 2,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{Ready: true, ProvisioningState: succeeded}),
+				"bar": makeAMPM(ampmOptions{ProvisioningState: creating}),
+			},
+			errStr: "only 1 of 2 machines of the new scale set became ready within 10m0s",
+		},
+		{
+			name:            "should default the ready timeout to 30 minutes",
+			strategy:        makeBlueGreenStrategy(infrav1exp.MachineBlueGreenDeployment{}),
+			startTime:       now.Add(-20 * time.Minute),
+			desiredReplicas: 1,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{ProvisioningState: creating}),
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			got, err := tt.strategy.CheckDeployment(context.Background(), tt.desiredReplicas, tt.startTime, tt.input)
+			if tt.errStr == "" {
+				g.Expect(err).To(Succeed())
+				g.Expect(got).To(Equal(tt.want))
+			} else {
+				g.Expect(err).To(MatchError(tt.errStr))
+			}
+		})
+	}
+}
+
+func TestMachinePoolBlueGreenStrategy_SelectMachinesToDelete(t *testing.T) {
+	var (
+		succeeded = infrav1.Succeeded
+		failed    = infrav1.Failed
+		deleting  = infrav1.Deleting
+		baseTime  = time.Now().Add(-24 * time.Hour).Truncate(time.Microsecond)
+	)
+
+	tests := []struct {
+		name            string
+		strategy        DeleteSelector
+		input           map[string]infrav1exp.AzureMachinePoolMachine
+		desiredReplicas int32
+		want            types.GomegaMatcher
+	}{
+		{
+			name:            "should not select machines without the latest model",
+			strategy:        makeBlueGreenStrategy(infrav1exp.MachineBlueGreenDeployment{}),
+			desiredReplicas: 2,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{Ready: true, LatestModel: false, ProvisioningState: succeeded}),
+				"bar": makeAMPM(ampmOptions{Ready: true, LatestModel: false, ProvisioningState: succeeded}),
+			},
+			want: Equal([]infrav1exp.AzureMachinePoolMachine{}),
+		},
+		{
+			name:            "if over-provisioned, select the oldest machines",
+			strategy:        makeBlueGreenStrategy(infrav1exp.MachineBlueGreenDeployment{}),
+			desiredReplicas: 1,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{Ready: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(3 * time.Hour))}),
+				"bar": makeAMPM(ampmOptions{Ready: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(1 * time.Hour))}),
+				"baz": makeAMPM(ampmOptions{Ready: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(2 * time.Hour))}),
+			},
+			want: gomega.DiffEq([]infrav1exp.AzureMachinePoolMachine{
+				makeAMPM(ampmOptions{Ready: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(1 * time.Hour))}),
+				makeAMPM(ampmOptions{Ready: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(2 * time.Hour))}),
+			}),
+		},
+		{
+			name:            "if over-provisioned, do not select protected machines",
+			strategy:        makeBlueGreenStrategy(infrav1exp.MachineBlueGreenDeployment{}),
+			desiredReplicas: 1,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{Ready: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(3 * time.Hour))}),
+				"bar": makeAMPM(ampmOptions{Ready: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(1 * time.Hour)), Protection: azure.InstanceProtectionScaleIn}),
+				"baz": makeAMPM(ampmOptions{Ready: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(2 * time.Hour)), Protection: azure.InstanceProtectionScaleIn}),
+			},
+			want: gomega.DiffEq([]infrav1exp.AzureMachinePoolMachine{
+				makeAMPM(ampmOptions{Ready: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(3 * time.Hour))}),
+			}),
+		},
+		{
+			name:            "select failed and deleting machines",
+			strategy:        makeBlueGreenStrategy(infrav1exp.MachineBlueGreenDeployment{}),
+			desiredReplicas: 2,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{Ready: true, ProvisioningState: succeeded}),
+				"bar": makeAMPM(ampmOptions{ProvisioningState: failed}),
+				"baz": makeAMPM(ampmOptions{ProvisioningState: deleting}),
+			},
+			want: gomega.DiffEq([]infrav1exp.AzureMachinePoolMachine{
+				makeAMPM(ampmOptions{ProvisioningState: failed}),
+				makeAMPM(ampmOptions{ProvisioningState: deleting}),
+			}),
+		},
+		{
+			name:            "select protected machines which failed",
+			strategy:        makeBlueGreenStrategy(infrav1exp.MachineBlueGreenDeployment{}),
+			desiredReplicas: 1,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{Ready: true, ProvisioningState: succeeded, Protection: azure.InstanceProtectionScaleIn}),
+				"bar": makeAMPM(ampmOptions{ProvisioningState: failed, Protection: azure.InstanceProtectionScaleIn}),
+			},
+			want: gomega.DiffEq([]infrav1exp.AzureMachinePoolMachine{
+				makeAMPM(ampmOptions{ProvisioningState: failed, Protection: azure.InstanceProtectionScaleIn}),
+			}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			got, err := tt.strategy.SelectMachinesToDelete(context.Background(), tt.desiredReplicas, tt.input)
+			g.Expect(err).To(Succeed())
+			g.Expect(got).To(tt.want)
+		})
+	}
+}
+
+func TestGetProtectedMachines(t *testing.T) {
+	var (
+		g          = NewWithT(t)
+		succeeded  = infrav1.Succeeded
+		baseTime   = time.Now().Add(-24 * time.Hour).Truncate(time.Microsecond)
+		deleteTime = metav1.NewTime(time.Now())
+	)
+
+	got := GetProtectedMachines(map[string]infrav1exp.AzureMachinePoolMachine{
+		"foo": makeAMPM(ampmOptions{Ready: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(2 * time.Hour)), Protection: azure.InstanceProtectionScaleIn}),
+		"bar": makeAMPM(ampmOptions{ProvisioningState: infrav1.Creating, CreationTime: metav1.NewTime(baseTime.Add(1 * time.Hour)), Protection: azure.InstanceProtectionScaleSetActions}),
+		"baz": makeAMPM(ampmOptions{Ready: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(3 * time.Hour))}),
+		"bin": makeAMPM(ampmOptions{Ready: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(4 * time.Hour)), DeletionTime: &deleteTime, Protection: azure.InstanceProtectionScaleIn}),
+	})
+	g.Expect(got).To(gomega.DiffEq([]infrav1exp.AzureMachinePoolMachine{
+		makeAMPM(ampmOptions{ProvisioningState: infrav1.Creating, CreationTime: metav1.NewTime(baseTime.Add(1 * time.Hour)), Protection: azure.InstanceProtectionScaleSetActions}),
+		makeAMPM(ampmOptions{Ready: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(2 * time.Hour)), Protection: azure.InstanceProtectionScaleIn}),
+	}))
+}
+
+func TestMachinePoolPlatformUpgradeStrategy(t *testing.T) {
+	g := NewWithT(t)
+	maxSurge := intstr.FromInt(2)
+	maxUnavailable := intstr.FromInt(2)
+	strategy := NewPlatformUpgradeStrategy(infrav1exp.AzureMachinePoolDeploymentStrategy{
+		Type: infrav1exp.RollingUpdateAzureMachinePoolDeploymentStrategyType,
+		RollingUpdate: &infrav1exp.MachineRollingUpdateDeployment{
+			MaxSurge:            &maxSurge,
+			MaxUnavailable:      &maxUnavailable,
+			DeletePolicy:        infrav1exp.OldestDeletePolicyType,
+			NodeReadyTimeout:    &metav1.Duration{Duration: time.Minute},
+			DrainBlockedTimeout: &metav1.Duration{Duration: time.Minute},
+		},
+	})
+	g.Expect(strategy.Type()).To(Equal(infrav1exp.RollingUpdateAzureMachinePoolDeploymentStrategyType))
+
+	surger, ok := strategy.(Surger)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(surger.Surge(3)).To(Equal(0))
+
+	pauser, ok := strategy.(RolloutPauser)
+	g.Expect(ok).To(BeTrue())
+	reason, _ := pauser.PauseRollout(context.Background(), time.Time{}, map[string]infrav1exp.AzureMachinePoolMachine{
+		"foo": makeAMPM(ampmOptions{LatestModel: true, ProvisioningState: infrav1.Succeeded, CreationTime: metav1.NewTime(time.Now().Add(-time.Hour))}),
+	})
+	g.Expect(reason).To(BeEmpty())
+}
+
+func TestMachinePoolPlatformUpgradeStrategy_SelectMachinesToDelete(t *testing.T) {
+	var (
+		succeeded      = infrav1.Succeeded
+		failed         = infrav1.Failed
+		baseTime       = time.Now().Add(-24 * time.Hour).Truncate(time.Microsecond)
+		maxUnavailable = intstr.FromInt(2)
+		strategy       = NewPlatformUpgradeStrategy(infrav1exp.AzureMachinePoolDeploymentStrategy{
+			Type: infrav1exp.RollingUpdateAzureMachinePoolDeploymentStrategyType,
+			RollingUpdate: &infrav1exp.MachineRollingUpdateDeployment{
+				MaxUnavailable: &maxUnavailable,
+				DeletePolicy:   infrav1exp.OldestDeletePolicyType,
+			},
+		})
+	)
+
+	tests := []struct {
+		name            string
+		input           map[string]infrav1exp.AzureMachinePoolMachine
+		desiredReplicas int32
+		want            types.GomegaMatcher
+	}{
+		{
+			name:            "should not select machines without the latest model",
+			desiredReplicas: 2,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{Ready: true, LatestModel: false, ProvisioningState: succeeded}),
+				"bar": makeAMPM(ampmOptions{Ready: true, LatestModel: false, ProvisioningState: succeeded}),
+			},
+			want: Equal([]infrav1exp.AzureMachinePoolMachine{}),
+		},
+		{
+			name:            "if over-provisioned, select a machine with an out-of-date model",
+			desiredReplicas: 1,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime)}),
+				"bar": makeAMPM(ampmOptions{Ready: true, LatestModel: false, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(time.Hour))}),
+			},
+			want: gomega.DiffEq([]infrav1exp.AzureMachinePoolMachine{
+				makeAMPM(ampmOptions{Ready: true, LatestModel: false, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(time.Hour))}),
+			}),
+		},
+		{
+			name:            "select failed machines",
+			desiredReplicas: 2,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{Ready: true, LatestModel: false, ProvisioningState: succeeded}),
+				"bar": makeAMPM(ampmOptions{ProvisioningState: failed}),
+			},
+			want: gomega.DiffEq([]infrav1exp.AzureMachinePoolMachine{
+				makeAMPM(ampmOptions{ProvisioningState: failed}),
+			}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			got, err := strategy.SelectMachinesToDelete(context.Background(), tt.desiredReplicas, tt.input)
+			g.Expect(err).To(Succeed())
+			g.Expect(got).To(tt.want)
+		})
+	}
+}
+
+func makeBlueGreenStrategy(blueGreen infrav1exp.MachineBlueGreenDeployment) *blueGreenStrategy {
+	return &blueGreenStrategy{
+		MachineBlueGreenDeployment: blueGreen,
+	}
+}
+
 func makeRollingUpdateStrategy(rolling infrav1exp.MachineRollingUpdateDeployment) *rollingUpdateStrategy {
 	return &rollingUpdateStrategy{
 		MachineRollingUpdateDeployment: rolling,
 	}
 }
 
+func withDrainingCondition(ampm infrav1exp.AzureMachinePoolMachine, since metav1.Time) infrav1exp.AzureMachinePoolMachine {
+	ampm.Status.Conditions = clusterv1.Conditions{
+		{
+			Type:               clusterv1.DrainingSucceededCondition,
+			Status:             corev1.ConditionFalse,
+			Reason:             clusterv1.DrainingReason,
+			LastTransitionTime: since,
+		},
+	}
+	return ampm
+}
+
 type ampmOptions struct {
 	Ready             bool
 	LatestModel       bool
 	ProvisioningState infrav1.ProvisioningState
 	CreationTime      metav1.Time
 	DeletionTime      *metav1.Time
+	HealthState       infrav1exp.InstanceHealthState
+	AvailabilityZone  string
+	Protection        string
 }
 
 func makeAMPM(opts ampmOptions) infrav1exp.AzureMachinePoolMachine {
+	var annotations map[string]string
+	if opts.Protection != "" {
+		annotations = map[string]string{azure.InstanceProtectionAnnotation: opts.Protection}
+	}
+
 	return infrav1exp.AzureMachinePoolMachine{
 		ObjectMeta: metav1.ObjectMeta{
 			CreationTimestamp: opts.CreationTime,
 			DeletionTimestamp: opts.DeletionTime,
+			Annotations:       annotations,
 		},
 		Status: infrav1exp.AzureMachinePoolMachineStatus{
 			Ready:              opts.Ready,
 			LatestModelApplied: opts.LatestModel,
 			ProvisioningState:  &opts.ProvisioningState,
+			HealthState:        opts.HealthState,
+			AvailabilityZone:   opts.AvailabilityZone,
 		},
 	}
 }
@@ -0,0 +1,289 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrapdata
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2021-04-01/storage"
+	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async"
+	"sigs.k8s.io/cluster-api-provider-azure/util/cache/ttllru"
+	"sigs.k8s.io/cluster-api-provider-azure/util/reconciler"
+	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+)
+
+const serviceName = "bootstrapdata"
+
+const (
+	// uploadSASExpiry is how long the SAS token used to upload or delete the bootstrap data stays valid.
+	uploadSASExpiry = 15 * time.Minute
+	// downloadSASExpiry is how long the SAS token the virtual machine downloads the bootstrap data with stays valid.
+	// It must leave enough time to create and boot the virtual machine.
+	downloadSASExpiry = 4 * time.Hour
+	// downloadSASRenewal is how long before its expiry the SAS token to download the bootstrap data is renewed, so
+	// that a virtual machine created with it still has the time to boot.
+	downloadSASRenewal = 2 * time.Hour
+)
+
+var (
+	doOnce   sync.Once
+	sasCache ttllru.PeekingCacher
+)
+
+// cachedSAS is a SAS token to download the bootstrap data, and its expiry.
+type cachedSAS struct {
+	token  string
+	expiry time.Time
+}
+
+// BootstrapDataScope defines the scope interface for a bootstrap data service.
+type BootstrapDataScope interface {
+	azure.Authorizer
+	azure.AsyncStatusUpdater
+	BootstrapDataBlobSpec() *azure.BootstrapDataBlobSpec
+	GetBootstrapData(ctx context.Context) (string, error)
+	GetBootstrapDataFormat(ctx context.Context) (string, error)
+	SetBootstrapDataLoader(loader string)
+	ProviderID() string
+	HasNodeRef() bool
+	IsBootstrapDataUploaded() bool
+	IsBootstrapDataReady() bool
+	SetBootstrapDataDeleted()
+}
+
+// Service provides operations on the bootstrap data uploaded to blob storage.
+type Service struct {
+	Scope BootstrapDataScope
+	async.Reconciler
+	client client
+
+	// sasCache caches the SAS tokens to download the bootstrap data across reconciles. It is keyed by the blob URL.
+	sasCache ttllru.PeekingCacher
+}
+
+// New creates a new bootstrap data service.
+func New(scope BootstrapDataScope) (*Service, error) {
+	var err error
+	doOnce.Do(func() {
+		sasCache, err = ttllru.New(1024, downloadSASExpiry)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed creating LRU cache for bootstrap data SAS tokens")
+	}
+
+	client := newClient(scope)
+	return &Service{
+		Scope:      scope,
+		client:     client,
+		Reconciler: async.New(scope, client, client),
+		sasCache:   sasCache,
+	}, nil
+}
+
+// Name returns the service name.
+func (s *Service) Name() string {
+	return serviceName
+}
+
+// Reconcile uploads the bootstrap data of a machine that is delivered from blob storage before its virtual machine is
+// created, and passes the loader that downloads it to the virtual machine. The blob is deleted once the machine has
+// joined the cluster, as it contains credentials to join the cluster.
+func (s *Service) Reconcile(ctx context.Context) error {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "bootstrapdata.Service.Reconcile")
+	defer done()
+
+	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureServiceReconcileTimeout)
+	defer cancel()
+
+	spec := s.Scope.BootstrapDataBlobSpec()
+	if spec == nil {
+		return nil
+	}
+
+	if s.Scope.HasNodeRef() {
+		if !s.Scope.IsBootstrapDataUploaded() {
+			return nil
+		}
+		log.V(2).Info("deleting bootstrap data blob of joined machine", "blob", spec.Name)
+		if err := s.deleteBlob(ctx, spec); err != nil {
+			return errors.Wrap(err, "failed to delete bootstrap data blob")
+		}
+		s.Scope.SetBootstrapDataDeleted()
+		return nil
+	}
+
+	// The virtual machine was already created with the loader.
+	if s.Scope.ProviderID() != "" {
+		return nil
+	}
+
+	err := s.upload(ctx, spec)
+	s.Scope.UpdatePutStatus(infrav1.BootstrapDataReadyCondition, serviceName, err)
+	return err
+}
+
+// upload uploads the bootstrap data to its blob and sets the loader to pass to the virtual machine. Once the bootstrap
+// data is ready, it is only uploaded again if its blob no longer exists.
+func (s *Service) upload(ctx context.Context, spec *azure.BootstrapDataBlobSpec) error {
+	ready := s.Scope.IsBootstrapDataReady()
+	if !ready {
+		if spec.StorageAccount != nil {
+			if _, err := s.CreateResource(ctx, spec.StorageAccount, serviceName); err != nil {
+				return err
+			}
+		}
+
+		if err := s.client.CreateContainerIfNotExists(ctx, spec.ResourceGroup, spec.StorageAccountName, spec.ContainerName); err != nil {
+			return errors.Wrapf(err, "failed to create blob container %s in storage account %s", spec.ContainerName, spec.StorageAccountName)
+		}
+	}
+
+	encoded, err := s.Scope.GetBootstrapData(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get bootstrap data")
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return errors.Wrap(err, "failed to decode bootstrap data")
+	}
+	format, err := s.Scope.GetBootstrapDataFormat(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get bootstrap data format")
+	}
+
+	blobURL, err := s.blobURL(ctx, spec)
+	if err != nil {
+		return err
+	}
+
+	downloadSAS, err := s.downloadSAS(ctx, spec, blobURL)
+	if err != nil {
+		return err
+	}
+
+	exists := false
+	if ready {
+		if exists, err = s.client.BlobExists(ctx, blobURL+"?"+downloadSAS); err != nil {
+			// The cached SAS token is no longer valid if the keys of the storage account were rotated.
+			s.sasCache.Remove(blobURL)
+			return errors.Wrapf(err, "failed to check if blob %s exists", spec.Name)
+		}
+	}
+	if !exists {
+		uploadSAS, err := s.client.GetBlobSAS(ctx, spec.ResourceGroup, spec.StorageAccountName, spec.ContainerName, spec.Name, storage.PermissionsC+storage.PermissionsW, time.Now().Add(uploadSASExpiry))
+		if err != nil {
+			return errors.Wrap(err, "failed to get SAS token to upload bootstrap data")
+		}
+		if err := s.client.PutBlob(ctx, blobURL+"?"+uploadSAS, data); err != nil {
+			return errors.Wrapf(err, "failed to upload bootstrap data to blob %s", spec.Name)
+		}
+	}
+
+	// The SAS token is also used to check if the blob exists, but it is only passed to the virtual machine if it
+	// downloads the bootstrap data with it.
+	if spec.Access == infrav1.BootstrapDataBlobAccessManagedIdentity {
+		downloadSAS = ""
+	}
+
+	loader, err := newLoader(format, data, spec, blobURL, downloadSAS)
+	if err != nil {
+		return azure.WithTerminalError(errors.Wrap(err, "failed to generate bootstrap data loader"))
+	}
+	s.Scope.SetBootstrapDataLoader(base64.StdEncoding.EncodeToString(loader))
+	return nil
+}
+
+// downloadSAS returns the SAS token to download the bootstrap data from its blob. The token is reused across reconciles
+// until it is close to expiry.
+func (s *Service) downloadSAS(ctx context.Context, spec *azure.BootstrapDataBlobSpec, blobURL string) (string, error) {
+	if cached, _, ok := s.sasCache.Peek(blobURL); ok {
+		if sas, ok := cached.(cachedSAS); ok && time.Until(sas.expiry) > downloadSASRenewal {
+			return sas.token, nil
+		}
+	}
+
+	expiry := time.Now().Add(downloadSASExpiry)
+	token, err := s.client.GetBlobSAS(ctx, spec.ResourceGroup, spec.StorageAccountName, spec.ContainerName, spec.Name, storage.PermissionsR, expiry)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get SAS token to download bootstrap data")
+	}
+	s.sasCache.Add(blobURL, cachedSAS{token: token, expiry: expiry})
+	return token, nil
+}
+
+// deleteBlob deletes the blob of the bootstrap data. It is not an error if the blob or its storage account doesn't exist.
+func (s *Service) deleteBlob(ctx context.Context, spec *azure.BootstrapDataBlobSpec) error {
+	blobURL, err := s.blobURL(ctx, spec)
+	if azure.ResourceNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	sas, err := s.client.GetBlobSAS(ctx, spec.ResourceGroup, spec.StorageAccountName, spec.ContainerName, spec.Name, storage.PermissionsD, time.Now().Add(uploadSASExpiry))
+	if err != nil {
+		return errors.Wrap(err, "failed to get SAS token to delete bootstrap data")
+	}
+	if err := s.client.DeleteBlob(ctx, blobURL+"?"+sas); err != nil {
+		return err
+	}
+	s.sasCache.Remove(blobURL)
+	return nil
+}
+
+// blobURL returns the URL of the blob of the bootstrap data, without SAS token.
+func (s *Service) blobURL(ctx context.Context, spec *azure.BootstrapDataBlobSpec) (string, error) {
+	endpoint, err := s.client.GetBlobEndpoint(ctx, spec.ResourceGroup, spec.StorageAccountName)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(endpoint, "/"), spec.ContainerName, spec.Name), nil
+}
+
+// Delete deletes the blob of the bootstrap data when the machine is deleted before it joined the cluster.
+// The storage account owned by the cluster is deleted with the cluster.
+func (s *Service) Delete(ctx context.Context) error {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "bootstrapdata.Service.Delete")
+	defer done()
+
+	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureServiceReconcileTimeout)
+	defer cancel()
+
+	spec := s.Scope.BootstrapDataBlobSpec()
+	if spec == nil || !s.Scope.IsBootstrapDataUploaded() {
+		return nil
+	}
+
+	if err := s.deleteBlob(ctx, spec); err != nil {
+		return errors.Wrap(err, "failed to delete bootstrap data blob")
+	}
+	s.Scope.SetBootstrapDataDeleted()
+	return nil
+}
+
+// IsManaged always returns true as the bootstrap data blobs are always created by CAPZ.
+func (s *Service) IsManaged(ctx context.Context) (bool, error) {
+	return true, nil
+}
@@ -0,0 +1,356 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrapdata
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2021-04-01/storage"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async/mock_async"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/bootstrapdata/mock_bootstrapdata"
+	gomockinternal "sigs.k8s.io/cluster-api-provider-azure/internal/test/matchers/gomock"
+	"sigs.k8s.io/cluster-api-provider-azure/util/cache/ttllru"
+)
+
+var (
+	fakeStorageAccountSpec = StorageAccountSpec{
+		Name:          "capzbd0123456789abcdef01",
+		ResourceGroup: "my-rg",
+		Location:      "westus",
+		ClusterName:   "my-cluster",
+	}
+
+	fakeBlobSpec = azure.BootstrapDataBlobSpec{
+		StorageAccount:     &fakeStorageAccountSpec,
+		StorageAccountName: "capzbd0123456789abcdef01",
+		ResourceGroup:      "my-rg",
+		ContainerName:      "bootstrap-data",
+		Name:               "default/my-cluster/my-vm",
+	}
+
+	fakeBlobEndpoint  = "https://capzbd0123456789abcdef01.blob.core.windows.net/"
+	fakeBlobURL       = "https://capzbd0123456789abcdef01.blob.core.windows.net/bootstrap-data/default/my-cluster/my-vm"
+	fakeBootstrapData = base64.StdEncoding.EncodeToString([]byte("#cloud-config\nruncmd: []\n"))
+
+	notFoundError = autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: http.StatusNotFound}, "Not Found")
+	internalError = autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: http.StatusInternalServerError}, "Internal Server Error")
+)
+
+func TestReconcileBootstrapData(t *testing.T) {
+	testcases := []struct {
+		name          string
+		expectedError string
+		cachedSAS     *cachedSAS
+		expect        func(s *mock_bootstrapdata.MockBootstrapDataScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder, c *mock_bootstrapdata.MockclientMockRecorder)
+	}{
+		{
+			name:          "noop if the bootstrap data isn't delivered from blob storage",
+			expectedError: "",
+			expect: func(s *mock_bootstrapdata.MockBootstrapDataScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder, c *mock_bootstrapdata.MockclientMockRecorder) {
+				s.BootstrapDataBlobSpec().Return(nil)
+			},
+		},
+		{
+			name:          "noop if the VM was already created",
+			expectedError: "",
+			expect: func(s *mock_bootstrapdata.MockBootstrapDataScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder, c *mock_bootstrapdata.MockclientMockRecorder) {
+				s.BootstrapDataBlobSpec().Return(&fakeBlobSpec)
+				s.HasNodeRef().Return(false)
+				s.ProviderID().Return("azure:///subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/virtualMachines/my-vm")
+			},
+		},
+		{
+			name:          "upload the bootstrap data and set the loader",
+			expectedError: "",
+			expect: func(s *mock_bootstrapdata.MockBootstrapDataScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder, c *mock_bootstrapdata.MockclientMockRecorder) {
+				s.BootstrapDataBlobSpec().Return(&fakeBlobSpec)
+				s.HasNodeRef().Return(false)
+				s.ProviderID().Return("")
+				s.IsBootstrapDataReady().Return(false)
+				s.GetBootstrapData(gomockinternal.AContext()).Return(fakeBootstrapData, nil)
+				s.GetBootstrapDataFormat(gomockinternal.AContext()).Return(azure.CloudConfigBootstrapDataFormat, nil)
+				c.GetBlobEndpoint(gomockinternal.AContext(), "my-rg", "capzbd0123456789abcdef01").Return(fakeBlobEndpoint, nil)
+				gomock.InOrder(
+					r.CreateResource(gomockinternal.AContext(), &fakeStorageAccountSpec, serviceName).Return(storage.Account{}, nil),
+					c.CreateContainerIfNotExists(gomockinternal.AContext(), "my-rg", "capzbd0123456789abcdef01", "bootstrap-data").Return(nil),
+					c.GetBlobSAS(gomockinternal.AContext(), "my-rg", "capzbd0123456789abcdef01", "bootstrap-data", "default/my-cluster/my-vm", storage.PermissionsR, gomock.Any()).Return("sig=download", nil),
+					c.GetBlobSAS(gomockinternal.AContext(), "my-rg", "capzbd0123456789abcdef01", "bootstrap-data", "default/my-cluster/my-vm", storage.PermissionsC+storage.PermissionsW, gomock.Any()).Return("sig=upload", nil),
+					c.PutBlob(gomockinternal.AContext(), fakeBlobURL+"?sig=upload", []byte("#cloud-config\nruncmd: []\n")).Return(nil),
+					s.SetBootstrapDataLoader(gomock.Any()).Do(func(loader string) {
+						decoded, err := base64.StdEncoding.DecodeString(loader)
+						if err != nil || !strings.Contains(string(decoded), fakeBlobURL+"?sig=download") {
+							t.Errorf("unexpected loader %q", loader)
+						}
+					}),
+					s.UpdatePutStatus(infrav1.BootstrapDataReadyCondition, serviceName, nil),
+				)
+			},
+		},
+		{
+			name:          "reuse the uploaded blob and the cached SAS token once the bootstrap data is ready",
+			expectedError: "",
+			cachedSAS:     &cachedSAS{token: "sig=cached", expiry: time.Now().Add(3 * time.Hour)},
+			expect: func(s *mock_bootstrapdata.MockBootstrapDataScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder, c *mock_bootstrapdata.MockclientMockRecorder) {
+				s.BootstrapDataBlobSpec().Return(&fakeBlobSpec)
+				s.HasNodeRef().Return(false)
+				s.ProviderID().Return("")
+				s.IsBootstrapDataReady().Return(true)
+				s.GetBootstrapData(gomockinternal.AContext()).Return(fakeBootstrapData, nil)
+				s.GetBootstrapDataFormat(gomockinternal.AContext()).Return(azure.CloudConfigBootstrapDataFormat, nil)
+				gomock.InOrder(
+					c.GetBlobEndpoint(gomockinternal.AContext(), "my-rg", "capzbd0123456789abcdef01").Return(fakeBlobEndpoint, nil),
+					c.BlobExists(gomockinternal.AContext(), fakeBlobURL+"?sig=cached").Return(true, nil),
+					s.SetBootstrapDataLoader(gomock.Any()).Do(func(loader string) {
+						decoded, err := base64.StdEncoding.DecodeString(loader)
+						if err != nil || !strings.Contains(string(decoded), fakeBlobURL+"?sig=cached") {
+							t.Errorf("unexpected loader %q", loader)
+						}
+					}),
+					s.UpdatePutStatus(infrav1.BootstrapDataReadyCondition, serviceName, nil),
+				)
+			},
+		},
+		{
+			name:          "renew the SAS token close to expiry and upload the blob again if it no longer exists",
+			expectedError: "",
+			cachedSAS:     &cachedSAS{token: "sig=cached", expiry: time.Now().Add(time.Hour)},
+			expect: func(s *mock_bootstrapdata.MockBootstrapDataScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder, c *mock_bootstrapdata.MockclientMockRecorder) {
+				s.BootstrapDataBlobSpec().Return(&fakeBlobSpec)
+				s.HasNodeRef().Return(false)
+				s.ProviderID().Return("")
+				s.IsBootstrapDataReady().Return(true)
+				s.GetBootstrapData(gomockinternal.AContext()).Return(fakeBootstrapData, nil)
+				s.GetBootstrapDataFormat(gomockinternal.AContext()).Return(azure.CloudConfigBootstrapDataFormat, nil)
+				gomock.InOrder(
+					c.GetBlobEndpoint(gomockinternal.AContext(), "my-rg", "capzbd0123456789abcdef01").Return(fakeBlobEndpoint, nil),
+					c.GetBlobSAS(gomockinternal.AContext(), "my-rg", "capzbd0123456789abcdef01", "bootstrap-data", "default/my-cluster/my-vm", storage.PermissionsR, gomock.Any()).Return("sig=download", nil),
+					c.BlobExists(gomockinternal.AContext(), fakeBlobURL+"?sig=download").Return(false, nil),
+					c.GetBlobSAS(gomockinternal.AContext(), "my-rg", "capzbd0123456789abcdef01", "bootstrap-data", "default/my-cluster/my-vm", storage.PermissionsC+storage.PermissionsW, gomock.Any()).Return("sig=upload", nil),
+					c.PutBlob(gomockinternal.AContext(), fakeBlobURL+"?sig=upload", []byte("#cloud-config\nruncmd: []\n")).Return(nil),
+					s.SetBootstrapDataLoader(gomock.Any()).Do(func(loader string) {
+						decoded, err := base64.StdEncoding.DecodeString(loader)
+						if err != nil || !strings.Contains(string(decoded), fakeBlobURL+"?sig=download") {
+							t.Errorf("unexpected loader %q", loader)
+						}
+					}),
+					s.UpdatePutStatus(infrav1.BootstrapDataReadyCondition, serviceName, nil),
+				)
+			},
+		},
+		{
+			name:          "storage account creation in progress",
+			expectedError: "operation type  on Azure resource / is not done",
+			expect: func(s *mock_bootstrapdata.MockBootstrapDataScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder, c *mock_bootstrapdata.MockclientMockRecorder) {
+				notDoneError := azure.NewOperationNotDoneError(&infrav1.Future{})
+				s.BootstrapDataBlobSpec().Return(&fakeBlobSpec)
+				s.HasNodeRef().Return(false)
+				s.ProviderID().Return("")
+				s.IsBootstrapDataReady().Return(false)
+				gomock.InOrder(
+					r.CreateResource(gomockinternal.AContext(), &fakeStorageAccountSpec, serviceName).Return(nil, notDoneError),
+					s.UpdatePutStatus(infrav1.BootstrapDataReadyCondition, serviceName, notDoneError),
+				)
+			},
+		},
+		{
+			name:          "ignition bootstrap data can't be downloaded with a managed identity",
+			expectedError: "reconcile error that cannot be recovered occurred: failed to generate bootstrap data loader: ignition bootstrap data can only be downloaded from blob storage with a SAS. Object will not be requeued",
+			expect: func(s *mock_bootstrapdata.MockBootstrapDataScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder, c *mock_bootstrapdata.MockclientMockRecorder) {
+				spec := azure.BootstrapDataBlobSpec{
+					StorageAccountName: "existing",
+					ResourceGroup:      "other-rg",
+					ContainerName:      "bootstrap-data",
+					Name:               "default/my-cluster/my-vm",
+					Access:             infrav1.BootstrapDataBlobAccessManagedIdentity,
+				}
+				s.BootstrapDataBlobSpec().Return(&spec)
+				s.HasNodeRef().Return(false)
+				s.ProviderID().Return("")
+				s.IsBootstrapDataReady().Return(false)
+				s.GetBootstrapData(gomockinternal.AContext()).Return(fakeBootstrapData, nil)
+				s.GetBootstrapDataFormat(gomockinternal.AContext()).Return(azure.IgnitionBootstrapDataFormat, nil)
+				c.CreateContainerIfNotExists(gomockinternal.AContext(), "other-rg", "existing", "bootstrap-data").Return(nil)
+				c.GetBlobEndpoint(gomockinternal.AContext(), "other-rg", "existing").Return("https://existing.blob.core.windows.net/", nil)
+				c.GetBlobSAS(gomockinternal.AContext(), "other-rg", "existing", "bootstrap-data", "default/my-cluster/my-vm", storage.PermissionsR, gomock.Any()).Return("sig=download", nil)
+				c.GetBlobSAS(gomockinternal.AContext(), "other-rg", "existing", "bootstrap-data", "default/my-cluster/my-vm", storage.PermissionsC+storage.PermissionsW, gomock.Any()).Return("sig=upload", nil)
+				c.PutBlob(gomockinternal.AContext(), gomock.Any(), gomock.Any()).Return(nil)
+				s.UpdatePutStatus(infrav1.BootstrapDataReadyCondition, serviceName, gomock.Any())
+			},
+		},
+		{
+			name:          "delete the blob once the machine has joined the cluster",
+			expectedError: "",
+			expect: func(s *mock_bootstrapdata.MockBootstrapDataScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder, c *mock_bootstrapdata.MockclientMockRecorder) {
+				s.BootstrapDataBlobSpec().Return(&fakeBlobSpec)
+				s.HasNodeRef().Return(true)
+				s.IsBootstrapDataUploaded().Return(true)
+				gomock.InOrder(
+					c.GetBlobEndpoint(gomockinternal.AContext(), "my-rg", "capzbd0123456789abcdef01").Return(fakeBlobEndpoint, nil),
+					c.GetBlobSAS(gomockinternal.AContext(), "my-rg", "capzbd0123456789abcdef01", "bootstrap-data", "default/my-cluster/my-vm", storage.PermissionsD, gomock.Any()).Return("sig=delete", nil),
+					c.DeleteBlob(gomockinternal.AContext(), fakeBlobURL+"?sig=delete").Return(nil),
+					s.SetBootstrapDataDeleted(),
+				)
+			},
+		},
+		{
+			name:          "noop once the blob of a joined machine was deleted",
+			expectedError: "",
+			expect: func(s *mock_bootstrapdata.MockBootstrapDataScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder, c *mock_bootstrapdata.MockclientMockRecorder) {
+				s.BootstrapDataBlobSpec().Return(&fakeBlobSpec)
+				s.HasNodeRef().Return(true)
+				s.IsBootstrapDataUploaded().Return(false)
+			},
+		},
+		{
+			name:          "error deleting the blob of a joined machine",
+			expectedError: "failed to delete bootstrap data blob: #: Internal Server Error: StatusCode=500",
+			expect: func(s *mock_bootstrapdata.MockBootstrapDataScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder, c *mock_bootstrapdata.MockclientMockRecorder) {
+				s.BootstrapDataBlobSpec().Return(&fakeBlobSpec)
+				s.HasNodeRef().Return(true)
+				s.IsBootstrapDataUploaded().Return(true)
+				c.GetBlobEndpoint(gomockinternal.AContext(), "my-rg", "capzbd0123456789abcdef01").Return("", internalError)
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := mock_bootstrapdata.NewMockBootstrapDataScope(mockCtrl)
+			asyncMock := mock_async.NewMockReconciler(mockCtrl)
+			clientMock := mock_bootstrapdata.NewMockclient(mockCtrl)
+
+			tc.expect(scopeMock.EXPECT(), asyncMock.EXPECT(), clientMock.EXPECT())
+
+			cache, err := ttllru.New(1, downloadSASExpiry)
+			g.Expect(err).NotTo(HaveOccurred())
+			if tc.cachedSAS != nil {
+				cache.Add(fakeBlobURL, *tc.cachedSAS)
+			}
+
+			s := &Service{
+				Scope:      scopeMock,
+				Reconciler: asyncMock,
+				client:     clientMock,
+				sasCache:   cache,
+			}
+
+			err = s.Reconcile(context.TODO())
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestDeleteBootstrapData(t *testing.T) {
+	testcases := []struct {
+		name          string
+		expectedError string
+		expect        func(s *mock_bootstrapdata.MockBootstrapDataScopeMockRecorder, c *mock_bootstrapdata.MockclientMockRecorder)
+	}{
+		{
+			name:          "noop if the bootstrap data isn't delivered from blob storage",
+			expectedError: "",
+			expect: func(s *mock_bootstrapdata.MockBootstrapDataScopeMockRecorder, c *mock_bootstrapdata.MockclientMockRecorder) {
+				s.BootstrapDataBlobSpec().Return(nil)
+			},
+		},
+		{
+			name:          "noop if the blob was already deleted",
+			expectedError: "",
+			expect: func(s *mock_bootstrapdata.MockBootstrapDataScopeMockRecorder, c *mock_bootstrapdata.MockclientMockRecorder) {
+				s.BootstrapDataBlobSpec().Return(&fakeBlobSpec)
+				s.IsBootstrapDataUploaded().Return(false)
+			},
+		},
+		{
+			name:          "delete the blob",
+			expectedError: "",
+			expect: func(s *mock_bootstrapdata.MockBootstrapDataScopeMockRecorder, c *mock_bootstrapdata.MockclientMockRecorder) {
+				s.BootstrapDataBlobSpec().Return(&fakeBlobSpec)
+				s.IsBootstrapDataUploaded().Return(true)
+				gomock.InOrder(
+					c.GetBlobEndpoint(gomockinternal.AContext(), "my-rg", "capzbd0123456789abcdef01").Return(fakeBlobEndpoint, nil),
+					c.GetBlobSAS(gomockinternal.AContext(), "my-rg", "capzbd0123456789abcdef01", "bootstrap-data", "default/my-cluster/my-vm", storage.PermissionsD, gomock.Any()).Return("sig=delete", nil),
+					c.DeleteBlob(gomockinternal.AContext(), fakeBlobURL+"?sig=delete").Return(nil),
+					s.SetBootstrapDataDeleted(),
+				)
+			},
+		},
+		{
+			name:          "storage account already deleted",
+			expectedError: "",
+			expect: func(s *mock_bootstrapdata.MockBootstrapDataScopeMockRecorder, c *mock_bootstrapdata.MockclientMockRecorder) {
+				s.BootstrapDataBlobSpec().Return(&fakeBlobSpec)
+				s.IsBootstrapDataUploaded().Return(true)
+				gomock.InOrder(
+					c.GetBlobEndpoint(gomockinternal.AContext(), "my-rg", "capzbd0123456789abcdef01").Return("", notFoundError),
+					s.SetBootstrapDataDeleted(),
+				)
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := mock_bootstrapdata.NewMockBootstrapDataScope(mockCtrl)
+			clientMock := mock_bootstrapdata.NewMockclient(mockCtrl)
+
+			tc.expect(scopeMock.EXPECT(), clientMock.EXPECT())
+
+			cache, err := ttllru.New(1, downloadSASExpiry)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			s := &Service{
+				Scope:    scopeMock,
+				client:   clientMock,
+				sasCache: cache,
+			}
+
+			err = s.Delete(context.TODO())
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
@@ -0,0 +1,275 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrapdata
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2021-04-01/storage"
+	azureautorest "github.com/Azure/go-autorest/autorest/azure"
+	"github.com/Azure/go-autorest/autorest/date"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/util/reconciler"
+	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+)
+
+// blobServiceVersion is the version of the Blob service REST API used to upload and delete blobs.
+const blobServiceVersion = "2020-10-02"
+
+// client wraps go-sdk.
+type client interface {
+	GetBlobEndpoint(ctx context.Context, resourceGroupName, accountName string) (string, error)
+	CreateContainerIfNotExists(ctx context.Context, resourceGroupName, accountName, containerName string) error
+	GetBlobSAS(ctx context.Context, resourceGroupName, accountName, containerName, blobName string, permissions storage.Permissions, expiry time.Time) (string, error)
+	BlobExists(ctx context.Context, blobURL string) (bool, error)
+	PutBlob(ctx context.Context, blobURL string, data []byte) error
+	DeleteBlob(ctx context.Context, blobURL string) error
+}
+
+// azureClient contains the Azure go-sdk Client.
+type azureClient struct {
+	accounts   storage.AccountsClient
+	containers storage.BlobContainersClient
+}
+
+var _ client = (*azureClient)(nil)
+
+// newClient creates a new storage client from subscription ID.
+func newClient(auth azure.Authorizer) *azureClient {
+	accountsClient := storage.NewAccountsClientWithBaseURI(auth.BaseURI(), auth.SubscriptionID())
+	azure.SetAutoRestClientDefaults(&accountsClient.Client, auth.Authorizer())
+	containersClient := storage.NewBlobContainersClientWithBaseURI(auth.BaseURI(), auth.SubscriptionID())
+	azure.SetAutoRestClientDefaults(&containersClient.Client, auth.Authorizer())
+	return &azureClient{accounts: accountsClient, containers: containersClient}
+}
+
+// Get gets the specified storage account.
+func (ac *azureClient) Get(ctx context.Context, spec azure.ResourceSpecGetter) (result interface{}, err error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "bootstrapdata.azureClient.Get")
+	defer done()
+
+	return ac.accounts.GetProperties(ctx, spec.ResourceGroupName(), spec.ResourceName(), "")
+}
+
+// CreateOrUpdateAsync creates a storage account asynchronously.
+// It sends a PUT request to Azure and if accepted without error, the func will return a Future which can be used to track the ongoing
+// progress of the operation.
+func (ac *azureClient) CreateOrUpdateAsync(ctx context.Context, spec azure.ResourceSpecGetter, parameters interface{}) (result interface{}, future azureautorest.FutureAPI, err error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "bootstrapdata.azureClient.CreateOrUpdateAsync")
+	defer done()
+
+	account, ok := parameters.(storage.AccountCreateParameters)
+	if !ok {
+		return nil, nil, errors.Errorf("%T is not a storage.AccountCreateParameters", parameters)
+	}
+
+	createFuture, err := ac.accounts.Create(ctx, spec.ResourceGroupName(), spec.ResourceName(), account)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureCallTimeout)
+	defer cancel()
+
+	err = createFuture.WaitForCompletionRef(ctx, ac.accounts.Client)
+	if err != nil {
+		// if an error occurs, return the future.
+		// this means the long-running operation didn't finish in the specified timeout.
+		return nil, &createFuture, err
+	}
+	result, err = createFuture.Result(ac.accounts)
+	// if the operation completed, return a nil future
+	return result, nil, err
+}
+
+// DeleteAsync deletes a storage account. Storage accounts are deleted synchronously, so the returned future is always nil.
+func (ac *azureClient) DeleteAsync(ctx context.Context, spec azure.ResourceSpecGetter) (future azureautorest.FutureAPI, err error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "bootstrapdata.azureClient.DeleteAsync")
+	defer done()
+
+	_, err = ac.accounts.Delete(ctx, spec.ResourceGroupName(), spec.ResourceName())
+	return nil, err
+}
+
+// Result fetches the result of a long-running operation future.
+func (ac *azureClient) Result(ctx context.Context, future azureautorest.FutureAPI, futureType string) (result interface{}, err error) {
+	_, _, done := tele.StartSpanWithLogger(ctx, "bootstrapdata.azureClient.Result")
+	defer done()
+
+	if future == nil {
+		return nil, errors.Errorf("cannot get result from nil future")
+	}
+
+	switch futureType {
+	case infrav1.PutFuture:
+		// Marshal and Unmarshal the future to put it into the correct future type so we can access the Result function.
+		// Unfortunately the FutureAPI can't be casted directly to AccountsCreateFuture because it is a azureautorest.Future, which doesn't implement the Result function. See PR #1686 for discussion on alternatives.
+		var createFuture *storage.AccountsCreateFuture
+		jsonData, err := future.MarshalJSON()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to marshal future")
+		}
+		if err := json.Unmarshal(jsonData, &createFuture); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal future data")
+		}
+		return createFuture.Result(ac.accounts)
+
+	case infrav1.DeleteFuture:
+		// Delete does not return a result storage account.
+		return nil, nil
+
+	default:
+		return nil, errors.Errorf("unknown future type %q", futureType)
+	}
+}
+
+// IsDone returns true if the long-running operation has completed.
+func (ac *azureClient) IsDone(ctx context.Context, future azureautorest.FutureAPI) (isDone bool, err error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "bootstrapdata.azureClient.IsDone")
+	defer done()
+
+	isDone, err = future.DoneWithContext(ctx, ac.accounts)
+	if err != nil {
+		return false, errors.Wrap(err, "failed checking if the operation was complete")
+	}
+
+	return isDone, nil
+}
+
+// GetBlobEndpoint returns the blob service endpoint of a storage account.
+func (ac *azureClient) GetBlobEndpoint(ctx context.Context, resourceGroupName, accountName string) (string, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "bootstrapdata.azureClient.GetBlobEndpoint")
+	defer done()
+
+	account, err := ac.accounts.GetProperties(ctx, resourceGroupName, accountName, "")
+	if err != nil {
+		return "", err
+	}
+	if account.AccountProperties == nil || account.PrimaryEndpoints == nil || account.PrimaryEndpoints.Blob == nil {
+		return "", errors.Errorf("storage account %s has no blob endpoint", accountName)
+	}
+	return *account.PrimaryEndpoints.Blob, nil
+}
+
+// CreateContainerIfNotExists creates a private blob container in a storage account if it doesn't exist yet.
+func (ac *azureClient) CreateContainerIfNotExists(ctx context.Context, resourceGroupName, accountName, containerName string) error {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "bootstrapdata.azureClient.CreateContainerIfNotExists")
+	defer done()
+
+	if _, err := ac.containers.Get(ctx, resourceGroupName, accountName, containerName); err == nil || !azure.ResourceNotFound(err) {
+		return err
+	}
+
+	_, err := ac.containers.Create(ctx, resourceGroupName, accountName, containerName, storage.BlobContainer{
+		ContainerProperties: &storage.ContainerProperties{
+			PublicAccess: storage.PublicAccessNone,
+		},
+	})
+	return err
+}
+
+// GetBlobSAS returns a service SAS token granting the given permissions on a blob until the expiry time.
+func (ac *azureClient) GetBlobSAS(ctx context.Context, resourceGroupName, accountName, containerName, blobName string, permissions storage.Permissions, expiry time.Time) (string, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "bootstrapdata.azureClient.GetBlobSAS")
+	defer done()
+
+	result, err := ac.accounts.ListServiceSAS(ctx, resourceGroupName, accountName, storage.ServiceSasParameters{
+		CanonicalizedResource:  to.StringPtr(fmt.Sprintf("/blob/%s/%s/%s", accountName, containerName, blobName)),
+		Resource:               storage.SignedResourceB,
+		Permissions:            permissions,
+		Protocols:              storage.HTTPProtocolHTTPS,
+		SharedAccessExpiryTime: &date.Time{Time: expiry},
+	})
+	if err != nil {
+		return "", err
+	}
+	if result.ServiceSasToken == nil {
+		return "", errors.Errorf("no SAS token returned for blob %s/%s in storage account %s", containerName, blobName, accountName)
+	}
+	return strings.TrimPrefix(*result.ServiceSasToken, "?"), nil
+}
+
+// BlobExists returns true if a blob exists.
+func (ac *azureClient) BlobExists(ctx context.Context, blobURL string) (bool, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "bootstrapdata.azureClient.BlobExists")
+	defer done()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, blobURL, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("x-ms-version", blobServiceVersion)
+	status, err := doBlobRequest(req, http.StatusOK, http.StatusNotFound)
+	return status == http.StatusOK, err
+}
+
+// PutBlob uploads data to a block blob, overwriting the blob if it exists.
+func (ac *azureClient) PutBlob(ctx context.Context, blobURL string, data []byte) error {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "bootstrapdata.azureClient.PutBlob")
+	defer done()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, blobURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("x-ms-version", blobServiceVersion)
+	req.Header.Set("x-ms-blob-type", "BlockBlob")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	_, err = doBlobRequest(req, http.StatusCreated)
+	return err
+}
+
+// DeleteBlob deletes a blob. Deleting a blob that doesn't exist is not an error.
+func (ac *azureClient) DeleteBlob(ctx context.Context, blobURL string) error {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "bootstrapdata.azureClient.DeleteBlob")
+	defer done()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, blobURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("x-ms-version", blobServiceVersion)
+	_, err = doBlobRequest(req, http.StatusAccepted, http.StatusNotFound)
+	return err
+}
+
+// doBlobRequest sends a request to the Blob service and returns the status code of the response, and an error unless
+// it is one of the expected status codes.
+func doBlobRequest(req *http.Request, expected ...int) (int, error) {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	for _, code := range expected {
+		if resp.StatusCode == code {
+			return resp.StatusCode, nil
+		}
+	}
+	// Don't include the URL in the error, it contains the SAS token.
+	return resp.StatusCode, errors.Errorf("unexpected response from the Blob service to %s request: %s", req.Method, resp.Status)
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrapdata
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"text/template"
+
+	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+)
+
+// cloudConfigLoaderTemplate is the script passed as custom data to virtual machines bootstrapped with cloud-init.
+// cloud-init runs it once the virtual machine has booted, and it applies the modules used by the Cluster API kubeadm
+// bootstrap provider from the downloaded cloud-config, then runs its commands.
+var cloudConfigLoaderTemplate = template.Must(template.New("loader").Parse(`#!/bin/bash
+# Downloads the bootstrap data of this machine from blob storage and runs it with cloud-init.
+set -o errexit -o nounset -o pipefail
+
+BOOTSTRAP_DATA=/run/cluster-api/bootstrap-data.cfg
+mkdir -p "$(dirname "${BOOTSTRAP_DATA}")"
+
+retry() {
+  for i in $(seq 1 {{ .Retries }}); do
+    "$@" && return 0
+    sleep {{ .RetrySleep }}
+  done
+  return 1
+}
+
+download() {
+{{- if .IdentityID }}
+  TOKEN=$(curl --silent --show-error --fail -H Metadata:true "http://169.254.169.254/metadata/identity/oauth2/token?api-version=2018-02-01&resource=https%3A%2F%2Fstorage.azure.com%2F&msi_res_id={{ .IdentityID }}" | sed -E 's/.*"access_token":"([^"]+)".*/\1/')
+  curl --silent --show-error --fail -H "Authorization: Bearer ${TOKEN}" -H "x-ms-version: {{ .Version }}" -o "${BOOTSTRAP_DATA}" "{{ .URL }}"
+{{- else }}
+  curl --silent --show-error --fail -o "${BOOTSTRAP_DATA}" "{{ .URL }}"
+{{- end }}
+}
+
+retry download
+
+for module in disk_setup mounts write_files users-groups ntp runcmd; do
+  cloud-init --file "${BOOTSTRAP_DATA}" single --name "${module}" --frequency always
+done
+/bin/bash /var/lib/cloud/instance/scripts/runcmd
+`))
+
+const (
+	// loaderRetries is the number of times the loader tries to download the bootstrap data.
+	// NOTE: the overall timeout will be number of retries * retry sleep, in this case 60 * 10s = 600s, which leaves time
+	// for the role assignments of the identity to propagate.
+	loaderRetries = 60
+	// loaderRetrySleep is the duration in seconds the loader sleeps between tries.
+	loaderRetrySleep = 10
+)
+
+// ignitionLoader is the Ignition config passed as custom data to virtual machines bootstrapped with Ignition, which
+// replaces it with the config downloaded from its source. Its shape is the same in the 2.x and 3.x specs.
+type ignitionLoader struct {
+	Ignition struct {
+		Version string `json:"version"`
+		Config  struct {
+			Replace struct {
+				Source string `json:"source"`
+			} `json:"replace"`
+		} `json:"config"`
+	} `json:"ignition"`
+}
+
+// newLoader returns the loader that makes a virtual machine download bootstrapData, of the given format, from the blob
+// at blobURL. sas is the query string of the SAS token to download the blob with when access is SAS.
+func newLoader(format string, bootstrapData []byte, spec *azure.BootstrapDataBlobSpec, blobURL, sas string) ([]byte, error) {
+	if format == azure.IgnitionBootstrapDataFormat {
+		// Ignition can only download its config from a URL, without fetching a token first.
+		if spec.Access == infrav1.BootstrapDataBlobAccessManagedIdentity {
+			return nil, errors.New("ignition bootstrap data can only be downloaded from blob storage with a SAS")
+		}
+		// The loader is written in the spec version of the bootstrap data, so that it is understood by the version
+		// of Ignition the bootstrap data targets: the kubeadm bootstrap provider of Cluster API v1.1 generates spec
+		// 2.x configs, which the Ignition versions that only know the 3.x specs can't read, and vice versa.
+		version, err := ignitionVersion(bootstrapData)
+		if err != nil {
+			return nil, err
+		}
+		var loader ignitionLoader
+		loader.Ignition.Version = version
+		loader.Ignition.Config.Replace.Source = blobURL + "?" + sas
+		return json.Marshal(loader)
+	}
+
+	data := struct {
+		URL        string
+		IdentityID string
+		Version    string
+		Retries    int
+		RetrySleep int
+	}{
+		URL:        blobURL,
+		Version:    blobServiceVersion,
+		Retries:    loaderRetries,
+		RetrySleep: loaderRetrySleep,
+	}
+	if spec.Access == infrav1.BootstrapDataBlobAccessManagedIdentity {
+		data.IdentityID = url.QueryEscape(spec.IdentityID)
+	} else {
+		data.URL += "?" + sas
+	}
+
+	var buf bytes.Buffer
+	if err := cloudConfigLoaderTemplate.Execute(&buf, data); err != nil {
+		return nil, errors.Wrap(err, "failed to render the bootstrap data loader")
+	}
+	return buf.Bytes(), nil
+}
+
+// ignitionVersion returns the spec version of the Ignition config data.
+func ignitionVersion(data []byte) (string, error) {
+	var config struct {
+		Ignition struct {
+			Version string `json:"version"`
+		} `json:"ignition"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return "", errors.Wrap(err, "failed to parse ignition bootstrap data")
+	}
+	if config.Ignition.Version == "" {
+		return "", errors.New("ignition bootstrap data has no version")
+	}
+	return config.Ignition.Version, nil
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrapdata
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+)
+
+// capiIgnitionNode is the Ignition bootstrap data of a worker node generated by the kubeadm bootstrap provider of
+// Cluster API v1.1.1 with the containerLinuxConfig of the flatcar flavor.
+const capiIgnitionNode = `{"ignition":{"config":{},"security":{"tls":{}},"timeouts":{},"version":"2.3.0"},"networkd":{},"passwd":{},"storage":{"files":[{"filesystem":"root","path":"/etc/kubernetes/azure.json","contents":{"source":"data:,%7B%7D%0A","verification":{}},"mode":420},{"filesystem":"root","path":"/etc/kubeadm.sh","contents":{"source":"data:,%23!%2Fbin%2Fbash%0Aset%20-e%0A%0Ased%20-i%20%22s%2F%40%40HOSTNAME%40%40%2F%24(curl%20-s%20-H%20Metadata%3Atrue%20--noproxy%20'*'%20'http%3A%2F%2F169.254.169.254%2Fmetadata%2Finstance%2Fcompute%2Fname%3Fapi-version%3D2020-09-01%26format%3Dtext')%2Fg%22%20%2Fetc%2Fkubeadm.yml%0A%0Akubeadm%20join%20--config%20%2Fetc%2Fkubeadm.yml%20%0Amkdir%20-p%20%2Frun%2Fcluster-api%20%26%26%20echo%20success%20%3E%20%2Frun%2Fcluster-api%2Fbootstrap-success.complete%0Amv%20%2Fetc%2Fkubeadm.yml%20%2Ftmp%2F%0A","verification":{}},"mode":448},{"filesystem":"root","path":"/etc/kubeadm.yml","contents":{"source":"data:,---%0AapiVersion%3A%20kubeadm.k8s.io%2Fv1beta3%0Akind%3A%20JoinConfiguration%0AnodeRegistration%3A%0A%20%20name%3A%20'%40%40HOSTNAME%40%40'%0A","verification":{}},"mode":384}]},"systemd":{"units":[{"contents":"[Unit]\nDescription=kubeadm\n# Run only once. After successful run, this file is moved to /tmp/.\nConditionPathExists=/etc/kubeadm.yml\n[Service]\n# To not restart the unit when it exits, as it is expected.\nType=oneshot\nExecStart=/etc/kubeadm.sh\n[Install]\nWantedBy=multi-user.target\n","enabled":true,"name":"kubeadm.service"},{"dropins":[{"contents":"[Unit]\nRequires=containerd.service\nAfter=containerd.service\n","name":"10-flatcar.conf"}],"name":"kubeadm.service"}]}}`
+
+func TestNewLoader(t *testing.T) {
+	identityID := "/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.ManagedIdentity/userAssignedIdentities/my-identity"
+
+	t.Run("cloud-config downloaded with a SAS", func(t *testing.T) {
+		g := NewWithT(t)
+		loader, err := newLoader(azure.CloudConfigBootstrapDataFormat, []byte("#cloud-config"), &fakeBlobSpec, fakeBlobURL, "sv=2020-10-02&sig=abc")
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(string(loader)).To(HavePrefix("#!/bin/bash\n"))
+		g.Expect(string(loader)).To(ContainSubstring(`curl --silent --show-error --fail -o "${BOOTSTRAP_DATA}" "` + fakeBlobURL + `?sv=2020-10-02&sig=abc"`))
+		g.Expect(string(loader)).To(ContainSubstring(`cloud-init --file "${BOOTSTRAP_DATA}" single --name "${module}" --frequency always`))
+		g.Expect(string(loader)).NotTo(ContainSubstring("metadata/identity"))
+	})
+
+	t.Run("cloud-config downloaded with a managed identity", func(t *testing.T) {
+		g := NewWithT(t)
+		spec := fakeBlobSpec
+		spec.Access = infrav1.BootstrapDataBlobAccessManagedIdentity
+		spec.IdentityID = identityID
+		loader, err := newLoader(azure.CloudConfigBootstrapDataFormat, []byte("#cloud-config"), &spec, fakeBlobURL, "")
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(string(loader)).To(ContainSubstring("msi_res_id=%2Fsubscriptions%2F123%2FresourceGroups%2Fmy-rg%2Fproviders%2FMicrosoft.ManagedIdentity%2FuserAssignedIdentities%2Fmy-identity"))
+		g.Expect(string(loader)).To(ContainSubstring(`-H "Authorization: Bearer ${TOKEN}" -H "x-ms-version: 2020-10-02" -o "${BOOTSTRAP_DATA}" "` + fakeBlobURL + `"`))
+	})
+
+	t.Run("ignition generated by Cluster API downloaded with a SAS", func(t *testing.T) {
+		g := NewWithT(t)
+		loader, err := newLoader(azure.IgnitionBootstrapDataFormat, []byte(capiIgnitionNode), &fakeBlobSpec, fakeBlobURL, "sig=abc")
+		g.Expect(err).NotTo(HaveOccurred())
+		var config map[string]interface{}
+		g.Expect(json.Unmarshal(loader, &config)).To(Succeed())
+		g.Expect(config).To(Equal(map[string]interface{}{
+			"ignition": map[string]interface{}{
+				"version": "2.3.0",
+				"config": map[string]interface{}{
+					"replace": map[string]interface{}{
+						"source": fakeBlobURL + "?sig=abc",
+					},
+				},
+			},
+		}))
+	})
+
+	t.Run("ignition spec 3 downloaded with a SAS", func(t *testing.T) {
+		g := NewWithT(t)
+		loader, err := newLoader(azure.IgnitionBootstrapDataFormat, []byte(`{"ignition":{"version":"3.1.0"}}`), &fakeBlobSpec, fakeBlobURL, "sig=abc")
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(string(loader)).To(Equal(`{"ignition":{"version":"3.1.0","config":{"replace":{"source":"` + fakeBlobURL + `?sig=abc"}}}}`))
+	})
+
+	t.Run("ignition without a version", func(t *testing.T) {
+		g := NewWithT(t)
+		_, err := newLoader(azure.IgnitionBootstrapDataFormat, []byte(`{"ignition":{}}`), &fakeBlobSpec, fakeBlobURL, "sig=abc")
+		g.Expect(err).To(MatchError("ignition bootstrap data has no version"))
+	})
+
+	t.Run("ignition can't be downloaded with a managed identity", func(t *testing.T) {
+		g := NewWithT(t)
+		spec := fakeBlobSpec
+		spec.Access = infrav1.BootstrapDataBlobAccessManagedIdentity
+		spec.IdentityID = identityID
+		_, err := newLoader(azure.IgnitionBootstrapDataFormat, []byte(capiIgnitionNode), &spec, fakeBlobURL, "")
+		g.Expect(err).To(MatchError("ignition bootstrap data can only be downloaded from blob storage with a SAS"))
+	})
+}
@@ -0,0 +1,365 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ../bootstrapdata.go
+
+// Package mock_bootstrapdata is a generated GoMock package.
+package mock_bootstrapdata
+
+import (
+	context "context"
+	reflect "reflect"
+
+	autorest "github.com/Azure/go-autorest/autorest"
+	gomock "github.com/golang/mock/gomock"
+	v1beta1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	azure "sigs.k8s.io/cluster-api-provider-azure/azure"
+	v1beta10 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// MockBootstrapDataScope is a mock of BootstrapDataScope interface.
+type MockBootstrapDataScope struct {
+	ctrl     *gomock.Controller
+	recorder *MockBootstrapDataScopeMockRecorder
+}
+
+// MockBootstrapDataScopeMockRecorder is the mock recorder for MockBootstrapDataScope.
+type MockBootstrapDataScopeMockRecorder struct {
+	mock *MockBootstrapDataScope
+}
+
+// NewMockBootstrapDataScope creates a new mock instance.
+func NewMockBootstrapDataScope(ctrl *gomock.Controller) *MockBootstrapDataScope {
+	mock := &MockBootstrapDataScope{ctrl: ctrl}
+	mock.recorder = &MockBootstrapDataScopeMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBootstrapDataScope) EXPECT() *MockBootstrapDataScopeMockRecorder {
+	return m.recorder
+}
+
+// Authorizer mocks base method.
+func (m *MockBootstrapDataScope) Authorizer() autorest.Authorizer {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authorizer")
+	ret0, _ := ret[0].(autorest.Authorizer)
+	return ret0
+}
+
+// Authorizer indicates an expected call of Authorizer.
+func (mr *MockBootstrapDataScopeMockRecorder) Authorizer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorizer", reflect.TypeOf((*MockBootstrapDataScope)(nil).Authorizer))
+}
+
+// BaseURI mocks base method.
+func (m *MockBootstrapDataScope) BaseURI() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BaseURI")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// BaseURI indicates an expected call of BaseURI.
+func (mr *MockBootstrapDataScopeMockRecorder) BaseURI() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BaseURI", reflect.TypeOf((*MockBootstrapDataScope)(nil).BaseURI))
+}
+
+// BootstrapDataBlobSpec mocks base method.
+func (m *MockBootstrapDataScope) BootstrapDataBlobSpec() *azure.BootstrapDataBlobSpec {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BootstrapDataBlobSpec")
+	ret0, _ := ret[0].(*azure.BootstrapDataBlobSpec)
+	return ret0
+}
+
+// BootstrapDataBlobSpec indicates an expected call of BootstrapDataBlobSpec.
+func (mr *MockBootstrapDataScopeMockRecorder) BootstrapDataBlobSpec() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BootstrapDataBlobSpec", reflect.TypeOf((*MockBootstrapDataScope)(nil).BootstrapDataBlobSpec))
+}
+
+// ClientID mocks base method.
+func (m *MockBootstrapDataScope) ClientID() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClientID")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// ClientID indicates an expected call of ClientID.
+func (mr *MockBootstrapDataScopeMockRecorder) ClientID() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClientID", reflect.TypeOf((*MockBootstrapDataScope)(nil).ClientID))
+}
+
+// ClientSecret mocks base method.
+func (m *MockBootstrapDataScope) ClientSecret() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClientSecret")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// ClientSecret indicates an expected call of ClientSecret.
+func (mr *MockBootstrapDataScopeMockRecorder) ClientSecret() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClientSecret", reflect.TypeOf((*MockBootstrapDataScope)(nil).ClientSecret))
+}
+
+// CloudEnvironment mocks base method.
+func (m *MockBootstrapDataScope) CloudEnvironment() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloudEnvironment")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// CloudEnvironment indicates an expected call of CloudEnvironment.
+func (mr *MockBootstrapDataScopeMockRecorder) CloudEnvironment() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloudEnvironment", reflect.TypeOf((*MockBootstrapDataScope)(nil).CloudEnvironment))
+}
+
+// DeleteLongRunningOperationState mocks base method.
+func (m *MockBootstrapDataScope) DeleteLongRunningOperationState(arg0, arg1 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DeleteLongRunningOperationState", arg0, arg1)
+}
+
+// DeleteLongRunningOperationState indicates an expected call of DeleteLongRunningOperationState.
+func (mr *MockBootstrapDataScopeMockRecorder) DeleteLongRunningOperationState(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLongRunningOperationState", reflect.TypeOf((*MockBootstrapDataScope)(nil).DeleteLongRunningOperationState), arg0, arg1)
+}
+
+// GetBootstrapData mocks base method.
+func (m *MockBootstrapDataScope) GetBootstrapData(ctx context.Context) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBootstrapData", ctx)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBootstrapData indicates an expected call of GetBootstrapData.
+func (mr *MockBootstrapDataScopeMockRecorder) GetBootstrapData(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBootstrapData", reflect.TypeOf((*MockBootstrapDataScope)(nil).GetBootstrapData), ctx)
+}
+
+// GetBootstrapDataFormat mocks base method.
+func (m *MockBootstrapDataScope) GetBootstrapDataFormat(ctx context.Context) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBootstrapDataFormat", ctx)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBootstrapDataFormat indicates an expected call of GetBootstrapDataFormat.
+func (mr *MockBootstrapDataScopeMockRecorder) GetBootstrapDataFormat(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBootstrapDataFormat", reflect.TypeOf((*MockBootstrapDataScope)(nil).GetBootstrapDataFormat), ctx)
+}
+
+// GetLongRunningOperationState mocks base method.
+func (m *MockBootstrapDataScope) GetLongRunningOperationState(arg0, arg1 string) *v1beta1.Future {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLongRunningOperationState", arg0, arg1)
+	ret0, _ := ret[0].(*v1beta1.Future)
+	return ret0
+}
+
+// GetLongRunningOperationState indicates an expected call of GetLongRunningOperationState.
+func (mr *MockBootstrapDataScopeMockRecorder) GetLongRunningOperationState(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLongRunningOperationState", reflect.TypeOf((*MockBootstrapDataScope)(nil).GetLongRunningOperationState), arg0, arg1)
+}
+
+// HasNodeRef mocks base method.
+func (m *MockBootstrapDataScope) HasNodeRef() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasNodeRef")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// HasNodeRef indicates an expected call of HasNodeRef.
+func (mr *MockBootstrapDataScopeMockRecorder) HasNodeRef() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasNodeRef", reflect.TypeOf((*MockBootstrapDataScope)(nil).HasNodeRef))
+}
+
+// HashKey mocks base method.
+func (m *MockBootstrapDataScope) HashKey() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HashKey")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// HashKey indicates an expected call of HashKey.
+func (mr *MockBootstrapDataScopeMockRecorder) HashKey() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HashKey", reflect.TypeOf((*MockBootstrapDataScope)(nil).HashKey))
+}
+
+// IsBootstrapDataReady mocks base method.
+func (m *MockBootstrapDataScope) IsBootstrapDataReady() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsBootstrapDataReady")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsBootstrapDataReady indicates an expected call of IsBootstrapDataReady.
+func (mr *MockBootstrapDataScopeMockRecorder) IsBootstrapDataReady() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsBootstrapDataReady", reflect.TypeOf((*MockBootstrapDataScope)(nil).IsBootstrapDataReady))
+}
+
+// IsBootstrapDataUploaded mocks base method.
+func (m *MockBootstrapDataScope) IsBootstrapDataUploaded() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsBootstrapDataUploaded")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsBootstrapDataUploaded indicates an expected call of IsBootstrapDataUploaded.
+func (mr *MockBootstrapDataScopeMockRecorder) IsBootstrapDataUploaded() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsBootstrapDataUploaded", reflect.TypeOf((*MockBootstrapDataScope)(nil).IsBootstrapDataUploaded))
+}
+
+// ProviderID mocks base method.
+func (m *MockBootstrapDataScope) ProviderID() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProviderID")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// ProviderID indicates an expected call of ProviderID.
+func (mr *MockBootstrapDataScopeMockRecorder) ProviderID() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProviderID", reflect.TypeOf((*MockBootstrapDataScope)(nil).ProviderID))
+}
+
+// SetBootstrapDataDeleted mocks base method.
+func (m *MockBootstrapDataScope) SetBootstrapDataDeleted() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetBootstrapDataDeleted")
+}
+
+// SetBootstrapDataDeleted indicates an expected call of SetBootstrapDataDeleted.
+func (mr *MockBootstrapDataScopeMockRecorder) SetBootstrapDataDeleted() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBootstrapDataDeleted", reflect.TypeOf((*MockBootstrapDataScope)(nil).SetBootstrapDataDeleted))
+}
+
+// SetBootstrapDataLoader mocks base method.
+func (m *MockBootstrapDataScope) SetBootstrapDataLoader(loader string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetBootstrapDataLoader", loader)
+}
+
+// SetBootstrapDataLoader indicates an expected call of SetBootstrapDataLoader.
+func (mr *MockBootstrapDataScopeMockRecorder) SetBootstrapDataLoader(loader interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBootstrapDataLoader", reflect.TypeOf((*MockBootstrapDataScope)(nil).SetBootstrapDataLoader), loader)
+}
+
+// SetLongRunningOperationState mocks base method.
+func (m *MockBootstrapDataScope) SetLongRunningOperationState(arg0 *v1beta1.Future) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetLongRunningOperationState", arg0)
+}
+
+// SetLongRunningOperationState indicates an expected call of SetLongRunningOperationState.
+func (mr *MockBootstrapDataScopeMockRecorder) SetLongRunningOperationState(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLongRunningOperationState", reflect.TypeOf((*MockBootstrapDataScope)(nil).SetLongRunningOperationState), arg0)
+}
+
+// SubscriptionID mocks base method.
+func (m *MockBootstrapDataScope) SubscriptionID() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubscriptionID")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// SubscriptionID indicates an expected call of SubscriptionID.
+func (mr *MockBootstrapDataScopeMockRecorder) SubscriptionID() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscriptionID", reflect.TypeOf((*MockBootstrapDataScope)(nil).SubscriptionID))
+}
+
+// TenantID mocks base method.
+func (m *MockBootstrapDataScope) TenantID() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TenantID")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// TenantID indicates an expected call of TenantID.
+func (mr *MockBootstrapDataScopeMockRecorder) TenantID() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TenantID", reflect.TypeOf((*MockBootstrapDataScope)(nil).TenantID))
+}
+
+// UpdateDeleteStatus mocks base method.
+func (m *MockBootstrapDataScope) UpdateDeleteStatus(arg0 v1beta10.ConditionType, arg1 string, arg2 error) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdateDeleteStatus", arg0, arg1, arg2)
+}
+
+// UpdateDeleteStatus indicates an expected call of UpdateDeleteStatus.
+func (mr *MockBootstrapDataScopeMockRecorder) UpdateDeleteStatus(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDeleteStatus", reflect.TypeOf((*MockBootstrapDataScope)(nil).UpdateDeleteStatus), arg0, arg1, arg2)
+}
+
+// UpdatePatchStatus mocks base method.
+func (m *MockBootstrapDataScope) UpdatePatchStatus(arg0 v1beta10.ConditionType, arg1 string, arg2 error) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdatePatchStatus", arg0, arg1, arg2)
+}
+
+// UpdatePatchStatus indicates an expected call of UpdatePatchStatus.
+func (mr *MockBootstrapDataScopeMockRecorder) UpdatePatchStatus(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePatchStatus", reflect.TypeOf((*MockBootstrapDataScope)(nil).UpdatePatchStatus), arg0, arg1, arg2)
+}
+
+// UpdatePutStatus mocks base method.
+func (m *MockBootstrapDataScope) UpdatePutStatus(arg0 v1beta10.ConditionType, arg1 string, arg2 error) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdatePutStatus", arg0, arg1, arg2)
+}
+
+// UpdatePutStatus indicates an expected call of UpdatePutStatus.
+func (mr *MockBootstrapDataScopeMockRecorder) UpdatePutStatus(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePutStatus", reflect.TypeOf((*MockBootstrapDataScope)(nil).UpdatePutStatus), arg0, arg1, arg2)
+}
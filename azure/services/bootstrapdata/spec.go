@@ -0,0 +1,80 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrapdata
+
+import (
+	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2021-04-01/storage"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/converters"
+)
+
+// StorageAccountSpec defines the specification for the storage account owned by a cluster that bootstrap data is
+// uploaded to.
+type StorageAccountSpec struct {
+	Name           string
+	ResourceGroup  string
+	Location       string
+	ClusterName    string
+	AdditionalTags infrav1.Tags
+}
+
+// ResourceName returns the name of the storage account.
+func (s *StorageAccountSpec) ResourceName() string {
+	return s.Name
+}
+
+// ResourceGroupName returns the name of the resource group.
+func (s *StorageAccountSpec) ResourceGroupName() string {
+	return s.ResourceGroup
+}
+
+// OwnerResourceName is a no-op for storage accounts.
+func (s *StorageAccountSpec) OwnerResourceName() string {
+	return ""
+}
+
+// Parameters returns the parameters for the storage account.
+func (s *StorageAccountSpec) Parameters(existing interface{}) (params interface{}, err error) {
+	if existing != nil {
+		if _, ok := existing.(storage.Account); !ok {
+			return nil, errors.Errorf("%T is not a storage.Account", existing)
+		}
+		// storage account already exists
+		return nil, nil
+	}
+
+	return storage.AccountCreateParameters{
+		Sku: &storage.Sku{
+			Name: storage.SkuNameStandardLRS,
+		},
+		Kind:     storage.KindStorageV2,
+		Location: to.StringPtr(s.Location),
+		Tags: converters.TagsToMap(infrav1.Build(infrav1.BuildParams{
+			ClusterName: s.ClusterName,
+			Lifecycle:   infrav1.ResourceLifecycleOwned,
+			Name:        to.StringPtr(s.Name),
+			Additional:  s.AdditionalTags,
+		})),
+		AccountPropertiesCreateParameters: &storage.AccountPropertiesCreateParameters{
+			EnableHTTPSTrafficOnly: to.BoolPtr(true),
+			AllowBlobPublicAccess:  to.BoolPtr(false),
+			MinimumTLSVersion:      storage.MinimumTLSVersionTLS12,
+		},
+	}, nil
+}
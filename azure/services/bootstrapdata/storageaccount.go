@@ -0,0 +1,110 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrapdata
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2021-04-01/storage"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/converters"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async"
+	"sigs.k8s.io/cluster-api-provider-azure/util/reconciler"
+	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+)
+
+const storageAccountServiceName = "bootstrapdatastorage"
+
+// StorageAccountScope defines the scope interface for a bootstrap data storage account service.
+type StorageAccountScope interface {
+	azure.Authorizer
+	azure.AsyncStatusUpdater
+	ClusterName() string
+	BootstrapDataStorageAccountSpec() azure.ResourceSpecGetter
+}
+
+// StorageAccountService deletes the storage account owned by a cluster that the bootstrap data of its machines is
+// uploaded to. The storage account is created on demand by the machines delivering their bootstrap data from blob
+// storage, so there is nothing to reconcile.
+type StorageAccountService struct {
+	Scope StorageAccountScope
+	async.Reconciler
+	getter async.Getter
+}
+
+// NewStorageAccountService creates a new bootstrap data storage account service.
+func NewStorageAccountService(scope StorageAccountScope) *StorageAccountService {
+	client := newClient(scope)
+	return &StorageAccountService{
+		Scope:      scope,
+		getter:     client,
+		Reconciler: async.New(scope, client, client),
+	}
+}
+
+// Name returns the service name.
+func (s *StorageAccountService) Name() string {
+	return storageAccountServiceName
+}
+
+// Reconcile is a no-op as the storage account is created by the machines that need it.
+func (s *StorageAccountService) Reconcile(ctx context.Context) error {
+	return nil
+}
+
+// Delete deletes the storage account owned by the cluster, if it was created.
+func (s *StorageAccountService) Delete(ctx context.Context) error {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "bootstrapdata.StorageAccountService.Delete")
+	defer done()
+
+	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureServiceReconcileTimeout)
+	defer cancel()
+
+	spec := s.Scope.BootstrapDataStorageAccountSpec()
+	managed, err := s.isManaged(ctx, spec)
+	if azure.ResourceNotFound(err) {
+		// the storage account was never created or is already deleted
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "failed to determine if the bootstrap data storage account is managed")
+	}
+	if !managed {
+		log.V(2).Info("skipping deletion of unmanaged bootstrap data storage account", "storageAccount", spec.ResourceName())
+		return nil
+	}
+
+	return s.DeleteResource(ctx, spec, storageAccountServiceName)
+}
+
+// isManaged returns true if the storage account has an owned tag with the cluster name as value.
+func (s *StorageAccountService) isManaged(ctx context.Context, spec azure.ResourceSpecGetter) (bool, error) {
+	existing, err := s.getter.Get(ctx, spec)
+	if err != nil {
+		return false, err
+	}
+	account, ok := existing.(storage.Account)
+	if !ok {
+		return false, errors.Errorf("%T is not a storage.Account", existing)
+	}
+	return converters.MapToTags(account.Tags).HasOwned(s.Scope.ClusterName()), nil
+}
+
+// IsManaged always returns true as the storage account is only deleted if it is owned by the cluster.
+func (s *StorageAccountService) IsManaged(ctx context.Context) (bool, error) {
+	return true, nil
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrapdata
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2021-04-01/storage"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async/mock_async"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/bootstrapdata/mock_bootstrapdata"
+	gomockinternal "sigs.k8s.io/cluster-api-provider-azure/internal/test/matchers/gomock"
+)
+
+func TestDeleteStorageAccount(t *testing.T) {
+	testcases := []struct {
+		name          string
+		expectedError string
+		expect        func(s *mock_bootstrapdata.MockStorageAccountScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder, g *mock_async.MockGetterMockRecorder)
+	}{
+		{
+			name:          "noop if the storage account doesn't exist",
+			expectedError: "",
+			expect: func(s *mock_bootstrapdata.MockStorageAccountScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder, g *mock_async.MockGetterMockRecorder) {
+				s.BootstrapDataStorageAccountSpec().Return(&fakeStorageAccountSpec)
+				g.Get(gomockinternal.AContext(), &fakeStorageAccountSpec).Return(nil, notFoundError)
+			},
+		},
+		{
+			name:          "skip deleting a storage account that isn't owned by the cluster",
+			expectedError: "",
+			expect: func(s *mock_bootstrapdata.MockStorageAccountScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder, g *mock_async.MockGetterMockRecorder) {
+				s.BootstrapDataStorageAccountSpec().Return(&fakeStorageAccountSpec)
+				s.ClusterName().Return("my-cluster")
+				g.Get(gomockinternal.AContext(), &fakeStorageAccountSpec).Return(storage.Account{
+					Tags: map[string]*string{"sigs.k8s.io_cluster-api-provider-azure_cluster_other-cluster": to.StringPtr("owned")},
+				}, nil)
+			},
+		},
+		{
+			name:          "delete the storage account owned by the cluster",
+			expectedError: "",
+			expect: func(s *mock_bootstrapdata.MockStorageAccountScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder, g *mock_async.MockGetterMockRecorder) {
+				s.BootstrapDataStorageAccountSpec().Return(&fakeStorageAccountSpec)
+				s.ClusterName().Return("my-cluster")
+				gomock.InOrder(
+					g.Get(gomockinternal.AContext(), &fakeStorageAccountSpec).Return(storage.Account{
+						Tags: map[string]*string{"sigs.k8s.io_cluster-api-provider-azure_cluster_my-cluster": to.StringPtr("owned")},
+					}, nil),
+					r.DeleteResource(gomockinternal.AContext(), &fakeStorageAccountSpec, storageAccountServiceName).Return(nil),
+				)
+			},
+		},
+		{
+			name:          "error getting the storage account",
+			expectedError: "failed to determine if the bootstrap data storage account is managed: #: Internal Server Error: StatusCode=500",
+			expect: func(s *mock_bootstrapdata.MockStorageAccountScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder, g *mock_async.MockGetterMockRecorder) {
+				s.BootstrapDataStorageAccountSpec().Return(&fakeStorageAccountSpec)
+				g.Get(gomockinternal.AContext(), &fakeStorageAccountSpec).Return(nil, internalError)
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := mock_bootstrapdata.NewMockStorageAccountScope(mockCtrl)
+			asyncMock := mock_async.NewMockReconciler(mockCtrl)
+			getterMock := mock_async.NewMockGetter(mockCtrl)
+
+			tc.expect(scopeMock.EXPECT(), asyncMock.EXPECT(), getterMock.EXPECT())
+
+			s := &StorageAccountService{
+				Scope:      scopeMock,
+				Reconciler: asyncMock,
+				getter:     getterMock,
+			}
+
+			err := s.Delete(context.TODO())
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
@@ -0,0 +1,304 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacityreservationgroups
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async"
+	"sigs.k8s.io/cluster-api-provider-azure/util/reconciler"
+	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+)
+
+const (
+	serviceName             = "capacityreservationgroups"
+	reservationsServiceName = "capacityreservations"
+)
+
+// CapacityReservationGroupScope defines the scope interface for a capacity reservation groups service.
+type CapacityReservationGroupScope interface {
+	azure.ClusterScoper
+	azure.AsyncStatusUpdater
+	CapacityReservationGroupSpecs() []azure.ResourceSpecGetter
+	CapacityReservationSpecs() []azure.ResourceSpecGetter
+	CapacityReservationGroupsStatus() []infrav1.CapacityReservationGroupStatus
+	SetCapacityReservationGroupsStatus(statuses []infrav1.CapacityReservationGroupStatus)
+}
+
+// Service provides operations on Azure resources.
+type Service struct {
+	Scope        CapacityReservationGroupScope
+	groups       async.Reconciler
+	reservations async.Reconciler
+}
+
+// New creates a new service.
+func New(scope CapacityReservationGroupScope) *Service {
+	groupsClient := newGroupsClient(scope)
+	reservationsClient := newReservationsClient(scope)
+	return &Service{
+		Scope:        scope,
+		groups:       async.New(scope, groupsClient, groupsClient),
+		reservations: async.New(scope, reservationsClient, reservationsClient),
+	}
+}
+
+// Name returns the service name.
+func (s *Service) Name() string {
+	return serviceName
+}
+
+// Reconcile creates or updates the capacity reservation groups and their capacity reservations, reports
+// the reserved and used capacity of each reservation, and deletes the ones removed from the spec.
+func (s *Service) Reconcile(ctx context.Context) error {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "capacityreservationgroups.Service.Reconcile")
+	defer done()
+
+	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureServiceReconcileTimeout)
+	defer cancel()
+
+	groupSpecs := s.Scope.CapacityReservationGroupSpecs()
+	previousStatuses := s.Scope.CapacityReservationGroupsStatus()
+	if len(groupSpecs) == 0 && len(previousStatuses) == 0 {
+		return nil
+	}
+
+	// If multiple errors occur, we return the most pressing one.
+	//  Order of precedence (highest -> lowest) is: error that is not an operationNotDoneError (ie. error creating) -> operationNotDoneError (ie. creating in progress) -> no error (ie. created)
+	var resultingErr error
+	statuses := make([]infrav1.CapacityReservationGroupStatus, 0, len(groupSpecs))
+	statusIndex := make(map[string]int, len(groupSpecs))
+	for _, groupSpec := range groupSpecs {
+		status := infrav1.CapacityReservationGroupStatus{Name: groupSpec.ResourceName()}
+		result, err := s.groups.CreateResource(ctx, groupSpec, serviceName)
+		if err != nil {
+			if !azure.IsOperationNotDoneError(err) || resultingErr == nil {
+				resultingErr = err
+			}
+		} else if group, ok := result.(compute.CapacityReservationGroup); ok {
+			status.ID = to.String(group.ID)
+		}
+		statusIndex[status.Name] = len(statuses)
+		statuses = append(statuses, status)
+	}
+
+	// The capacity reservations can only be created once all their groups exist.
+	if resultingErr != nil {
+		s.Scope.UpdatePutStatus(infrav1.CapacityReservationGroupsReadyCondition, serviceName, resultingErr)
+		return resultingErr
+	}
+
+	reservationSpecs := s.Scope.CapacityReservationSpecs()
+	for _, reservationSpec := range reservationSpecs {
+		result, err := s.reservations.CreateResource(ctx, reservationSpec, reservationsServiceName)
+		if err != nil {
+			if !azure.IsOperationNotDoneError(err) || resultingErr == nil {
+				resultingErr = err
+			}
+			continue
+		}
+
+		reservation, ok := result.(compute.CapacityReservation)
+		if !ok {
+			// Return out of loop since this would be an unexpected fatal error
+			resultingErr = errors.Errorf("created resource %T is not a compute.CapacityReservation", result)
+			break
+		}
+
+		if i, ok := statusIndex[reservationSpec.OwnerResourceName()]; ok {
+			statuses[i].Reservations = append(statuses[i].Reservations, reservationStatus(reservationSpec.ResourceName(), reservation))
+		}
+	}
+
+	statuses, err := s.deleteRemoved(ctx, reservationSpecs, previousStatuses, statuses, statusIndex)
+	if err != nil && (!azure.IsOperationNotDoneError(err) || resultingErr == nil) {
+		resultingErr = err
+	}
+
+	s.Scope.SetCapacityReservationGroupsStatus(statuses)
+	s.Scope.UpdatePutStatus(infrav1.CapacityReservationGroupsReadyCondition, serviceName, resultingErr)
+	return resultingErr
+}
+
+// deleteRemoved deletes the capacity reservations and capacity reservation groups reported in the previous statuses
+// that are no longer part of the spec. A group is only deleted once all of its reservations are deleted. The ones
+// that are still being deleted are kept in the returned statuses so that their deletion is resumed on the next
+// reconciliation.
+func (s *Service) deleteRemoved(ctx context.Context, reservationSpecs []azure.ResourceSpecGetter, previousStatuses, statuses []infrav1.CapacityReservationGroupStatus, statusIndex map[string]int) ([]infrav1.CapacityReservationGroupStatus, error) {
+	wantedReservations := make(map[string]bool, len(reservationSpecs))
+	for _, reservationSpec := range reservationSpecs {
+		wantedReservations[reservationSpec.OwnerResourceName()+"/"+reservationSpec.ResourceName()] = true
+	}
+
+	var resultingErr error
+	for _, previous := range previousStatuses {
+		var remaining []infrav1.CapacityReservationStatus
+		for _, reservation := range previous.Reservations {
+			if wantedReservations[previous.Name+"/"+reservation.Name] {
+				continue
+			}
+			reservationSpec := &CapacityReservationSpec{
+				Name:          reservation.Name,
+				GroupName:     previous.Name,
+				ResourceGroup: s.Scope.ResourceGroup(),
+			}
+			if err := s.reservations.DeleteResource(ctx, reservationSpec, reservationsServiceName); err != nil {
+				if !azure.IsOperationNotDoneError(err) || resultingErr == nil {
+					resultingErr = err
+				}
+				remaining = append(remaining, reservation)
+			}
+		}
+
+		if i, ok := statusIndex[previous.Name]; ok {
+			statuses[i].Reservations = append(statuses[i].Reservations, remaining...)
+			continue
+		}
+
+		if len(remaining) == 0 {
+			groupSpec := &CapacityReservationGroupSpec{
+				Name:          previous.Name,
+				ResourceGroup: s.Scope.ResourceGroup(),
+			}
+			err := s.groups.DeleteResource(ctx, groupSpec, serviceName)
+			if err == nil {
+				continue
+			}
+			if !azure.IsOperationNotDoneError(err) || resultingErr == nil {
+				resultingErr = err
+			}
+		}
+		statuses = append(statuses, infrav1.CapacityReservationGroupStatus{
+			Name:         previous.Name,
+			ID:           previous.ID,
+			Reservations: remaining,
+		})
+	}
+
+	return statuses, resultingErr
+}
+
+// Delete deletes the capacity reservations and then the capacity reservation groups.
+func (s *Service) Delete(ctx context.Context) error {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "capacityreservationgroups.Service.Delete")
+	defer done()
+
+	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureServiceReconcileTimeout)
+	defer cancel()
+
+	groupSpecs := s.Scope.CapacityReservationGroupSpecs()
+	previousStatuses := s.Scope.CapacityReservationGroupsStatus()
+	if len(groupSpecs) == 0 && len(previousStatuses) == 0 {
+		return nil
+	}
+
+	// A capacity reservation group can only be deleted once all of its capacity reservations are deleted.
+	var resultingErr error
+	reservationSpecs := s.Scope.CapacityReservationSpecs()
+	for _, reservationSpec := range reservationSpecs {
+		if err := s.reservations.DeleteResource(ctx, reservationSpec, reservationsServiceName); err != nil {
+			if !azure.IsOperationNotDoneError(err) || resultingErr == nil {
+				resultingErr = err
+			}
+		}
+	}
+
+	// The capacity reservations and groups removed from the spec whose deletion is still in progress are deleted too.
+	specStatuses := make([]infrav1.CapacityReservationGroupStatus, len(groupSpecs))
+	specIndex := make(map[string]int, len(groupSpecs))
+	for i, groupSpec := range groupSpecs {
+		specStatuses[i].Name = groupSpec.ResourceName()
+		specIndex[groupSpec.ResourceName()] = i
+	}
+	if _, err := s.deleteRemoved(ctx, reservationSpecs, previousStatuses, specStatuses, specIndex); err != nil {
+		if !azure.IsOperationNotDoneError(err) || resultingErr == nil {
+			resultingErr = err
+		}
+	}
+
+	if resultingErr == nil {
+		for _, groupSpec := range groupSpecs {
+			if err := s.groups.DeleteResource(ctx, groupSpec, serviceName); err != nil {
+				if !azure.IsOperationNotDoneError(err) || resultingErr == nil {
+					resultingErr = err
+				}
+			}
+		}
+	}
+
+	if resultingErr == nil {
+		s.Scope.SetCapacityReservationGroupsStatus(nil)
+	}
+	s.Scope.UpdateDeleteStatus(infrav1.CapacityReservationGroupsReadyCondition, serviceName, resultingErr)
+	return resultingErr
+}
+
+// reservationStatus returns the reserved and used capacity of a capacity reservation.
+func reservationStatus(name string, reservation compute.CapacityReservation) infrav1.CapacityReservationStatus {
+	status := infrav1.CapacityReservationStatus{Name: name}
+	if reservation.Sku != nil {
+		status.VMSize = to.String(reservation.Sku.Name)
+		status.Reserved = to.Int64(reservation.Sku.Capacity)
+	}
+	if reservation.Zones != nil && len(*reservation.Zones) > 0 {
+		status.Zone = (*reservation.Zones)[0]
+	}
+	if props := reservation.CapacityReservationProperties; props != nil && props.InstanceView != nil &&
+		props.InstanceView.UtilizationInfo != nil && props.InstanceView.UtilizationInfo.VirtualMachinesAllocated != nil {
+		status.Used = int64(len(*props.InstanceView.UtilizationInfo.VirtualMachinesAllocated))
+	}
+	return status
+}
+
+// ValidateReservation returns a terminal error if none of the capacity reservations of a capacity reservation group
+// reserves capacity for the given VM size in the given zone. An empty zone only matches regional reservations.
+func ValidateReservation(reservations []compute.CapacityReservation, groupID, vmSize, zone string) error {
+	for _, reservation := range reservations {
+		if reservation.Sku == nil || !strings.EqualFold(to.String(reservation.Sku.Name), vmSize) {
+			continue
+		}
+		var zones []string
+		if reservation.Zones != nil {
+			zones = *reservation.Zones
+		}
+		if zone == "" && len(zones) == 0 {
+			return nil
+		}
+		for _, z := range zones {
+			if z == zone {
+				return nil
+			}
+		}
+	}
+
+	if zone == "" {
+		return azure.WithTerminalError(errors.Errorf("capacity reservation group %s has no regional capacity reservation for VM size %s", groupID, vmSize))
+	}
+	return azure.WithTerminalError(errors.Errorf("capacity reservation group %s has no capacity reservation for VM size %s in zone %s", groupID, vmSize, zone))
+}
+
+// IsManaged always returns true as the capacity reservation groups in the AzureCluster spec are always created by CAPZ.
+// Capacity reservation groups created outside of CAPZ can be referenced by ID from the machines instead.
+func (s *Service) IsManaged(ctx context.Context) (bool, error) {
+	return true, nil
+}
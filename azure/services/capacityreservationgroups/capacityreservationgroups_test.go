@@ -0,0 +1,416 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacityreservationgroups
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async/mock_async"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/capacityreservationgroups/mock_capacityreservationgroups"
+	gomockinternal "sigs.k8s.io/cluster-api-provider-azure/internal/test/matchers/gomock"
+)
+
+var (
+	groupSpec = CapacityReservationGroupSpec{
+		Name:          "my-crg",
+		ResourceGroup: "my-rg",
+		ClusterName:   "my-cluster",
+		Location:      "westus2",
+		Zones:         []string{"1", "2"},
+	}
+	reservationSpec1 = CapacityReservationSpec{
+		Name:          "my-reservation-1",
+		GroupName:     "my-crg",
+		ResourceGroup: "my-rg",
+		ClusterName:   "my-cluster",
+		Location:      "westus2",
+		VMSize:        "Standard_D2s_v3",
+		Zone:          "1",
+		Capacity:      3,
+	}
+	reservationSpec2 = CapacityReservationSpec{
+		Name:          "my-reservation-2",
+		GroupName:     "my-crg",
+		ResourceGroup: "my-rg",
+		ClusterName:   "my-cluster",
+		Location:      "westus2",
+		VMSize:        "Standard_D2s_v3",
+		Zone:          "2",
+		Capacity:      2,
+	}
+	group = compute.CapacityReservationGroup{
+		ID: to.StringPtr("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/capacityReservationGroups/my-crg"),
+	}
+	reservation1 = compute.CapacityReservation{
+		Sku:   &compute.Sku{Name: to.StringPtr("Standard_D2s_v3"), Capacity: to.Int64Ptr(3)},
+		Zones: &[]string{"1"},
+		CapacityReservationProperties: &compute.CapacityReservationProperties{
+			InstanceView: &compute.CapacityReservationInstanceView{
+				UtilizationInfo: &compute.CapacityReservationUtilization{
+					VirtualMachinesAllocated: &[]compute.SubResourceReadOnly{
+						{ID: to.StringPtr("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/virtualMachines/vm-1")},
+						{ID: to.StringPtr("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/virtualMachines/vm-2")},
+					},
+				},
+			},
+		},
+	}
+	reservation2 = compute.CapacityReservation{
+		Sku:   &compute.Sku{Name: to.StringPtr("Standard_D2s_v3"), Capacity: to.Int64Ptr(2)},
+		Zones: &[]string{"2"},
+	}
+	internalError = autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: http.StatusInternalServerError}, "Internal Server Error")
+)
+
+func TestReconcileCapacityReservationGroups(t *testing.T) {
+	testcases := []struct {
+		name          string
+		expectedError string
+		expect        func(s *mock_capacityreservationgroups.MockCapacityReservationGroupScopeMockRecorder, g, r *mock_async.MockReconcilerMockRecorder)
+	}{
+		{
+			name:          "noop if no capacity reservation group specs are found",
+			expectedError: "",
+			expect: func(s *mock_capacityreservationgroups.MockCapacityReservationGroupScopeMockRecorder, g, r *mock_async.MockReconcilerMockRecorder) {
+				s.CapacityReservationGroupSpecs().Return([]azure.ResourceSpecGetter{})
+				s.CapacityReservationGroupsStatus().Return(nil)
+			},
+		},
+		{
+			name:          "create capacity reservation group and reservations and report capacity",
+			expectedError: "",
+			expect: func(s *mock_capacityreservationgroups.MockCapacityReservationGroupScopeMockRecorder, g, r *mock_async.MockReconcilerMockRecorder) {
+				s.CapacityReservationGroupSpecs().Return([]azure.ResourceSpecGetter{&groupSpec})
+				s.CapacityReservationGroupsStatus().Return(nil)
+				g.CreateResource(gomockinternal.AContext(), &groupSpec, serviceName).Return(group, nil)
+				s.CapacityReservationSpecs().Return([]azure.ResourceSpecGetter{&reservationSpec1, &reservationSpec2})
+				r.CreateResource(gomockinternal.AContext(), &reservationSpec1, reservationsServiceName).Return(reservation1, nil)
+				r.CreateResource(gomockinternal.AContext(), &reservationSpec2, reservationsServiceName).Return(reservation2, nil)
+				s.SetCapacityReservationGroupsStatus([]infrav1.CapacityReservationGroupStatus{
+					{
+						Name: "my-crg",
+						ID:   "/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/capacityReservationGroups/my-crg",
+						Reservations: []infrav1.CapacityReservationStatus{
+							{Name: "my-reservation-1", VMSize: "Standard_D2s_v3", Zone: "1", Reserved: 3, Used: 2},
+							{Name: "my-reservation-2", VMSize: "Standard_D2s_v3", Zone: "2", Reserved: 2, Used: 0},
+						},
+					},
+				})
+				s.UpdatePutStatus(infrav1.CapacityReservationGroupsReadyCondition, serviceName, nil)
+			},
+		},
+		{
+			name:          "reservations are not created if the capacity reservation group fails to be created",
+			expectedError: internalError.Error(),
+			expect: func(s *mock_capacityreservationgroups.MockCapacityReservationGroupScopeMockRecorder, g, r *mock_async.MockReconcilerMockRecorder) {
+				s.CapacityReservationGroupSpecs().Return([]azure.ResourceSpecGetter{&groupSpec})
+				s.CapacityReservationGroupsStatus().Return(nil)
+				g.CreateResource(gomockinternal.AContext(), &groupSpec, serviceName).Return(nil, internalError)
+				s.UpdatePutStatus(infrav1.CapacityReservationGroupsReadyCondition, serviceName, internalError)
+			},
+		},
+		{
+			name:          "capacity reservation still being created is not reported",
+			expectedError: "operation type PUT on Azure resource my-rg/my-reservation-2 is not done",
+			expect: func(s *mock_capacityreservationgroups.MockCapacityReservationGroupScopeMockRecorder, g, r *mock_async.MockReconcilerMockRecorder) {
+				notDoneErr := azure.NewOperationNotDoneError(&infrav1.Future{Type: infrav1.PutFuture, ResourceGroup: "my-rg", Name: "my-reservation-2"})
+				s.CapacityReservationGroupSpecs().Return([]azure.ResourceSpecGetter{&groupSpec})
+				s.CapacityReservationGroupsStatus().Return(nil)
+				g.CreateResource(gomockinternal.AContext(), &groupSpec, serviceName).Return(group, nil)
+				s.CapacityReservationSpecs().Return([]azure.ResourceSpecGetter{&reservationSpec1, &reservationSpec2})
+				r.CreateResource(gomockinternal.AContext(), &reservationSpec1, reservationsServiceName).Return(reservation1, nil)
+				r.CreateResource(gomockinternal.AContext(), &reservationSpec2, reservationsServiceName).Return(nil, notDoneErr)
+				s.SetCapacityReservationGroupsStatus([]infrav1.CapacityReservationGroupStatus{
+					{
+						Name: "my-crg",
+						ID:   "/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/capacityReservationGroups/my-crg",
+						Reservations: []infrav1.CapacityReservationStatus{
+							{Name: "my-reservation-1", VMSize: "Standard_D2s_v3", Zone: "1", Reserved: 3, Used: 2},
+						},
+					},
+				})
+				s.UpdatePutStatus(infrav1.CapacityReservationGroupsReadyCondition, serviceName, notDoneErr)
+			},
+		},
+		{
+			name:          "delete capacity reservations and groups removed from the spec",
+			expectedError: "",
+			expect: func(s *mock_capacityreservationgroups.MockCapacityReservationGroupScopeMockRecorder, g, r *mock_async.MockReconcilerMockRecorder) {
+				s.CapacityReservationGroupSpecs().Return([]azure.ResourceSpecGetter{&groupSpec})
+				s.CapacityReservationGroupsStatus().Return([]infrav1.CapacityReservationGroupStatus{
+					{
+						Name: "my-crg",
+						Reservations: []infrav1.CapacityReservationStatus{
+							{Name: "my-reservation-1"},
+							{Name: "my-reservation-2"},
+						},
+					},
+					{
+						Name:         "old-crg",
+						Reservations: []infrav1.CapacityReservationStatus{{Name: "old-reservation"}},
+					},
+				})
+				s.ResourceGroup().Return("my-rg").AnyTimes()
+				g.CreateResource(gomockinternal.AContext(), &groupSpec, serviceName).Return(group, nil)
+				s.CapacityReservationSpecs().Return([]azure.ResourceSpecGetter{&reservationSpec1})
+				r.CreateResource(gomockinternal.AContext(), &reservationSpec1, reservationsServiceName).Return(reservation1, nil)
+				r.DeleteResource(gomockinternal.AContext(), &CapacityReservationSpec{Name: "my-reservation-2", GroupName: "my-crg", ResourceGroup: "my-rg"}, reservationsServiceName).Return(nil)
+				gomock.InOrder(
+					r.DeleteResource(gomockinternal.AContext(), &CapacityReservationSpec{Name: "old-reservation", GroupName: "old-crg", ResourceGroup: "my-rg"}, reservationsServiceName).Return(nil),
+					g.DeleteResource(gomockinternal.AContext(), &CapacityReservationGroupSpec{Name: "old-crg", ResourceGroup: "my-rg"}, serviceName).Return(nil),
+				)
+				s.SetCapacityReservationGroupsStatus([]infrav1.CapacityReservationGroupStatus{
+					{
+						Name: "my-crg",
+						ID:   "/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/capacityReservationGroups/my-crg",
+						Reservations: []infrav1.CapacityReservationStatus{
+							{Name: "my-reservation-1", VMSize: "Standard_D2s_v3", Zone: "1", Reserved: 3, Used: 2},
+						},
+					},
+				})
+				s.UpdatePutStatus(infrav1.CapacityReservationGroupsReadyCondition, serviceName, nil)
+			},
+		},
+		{
+			name:          "capacity reservation group removed from the spec is kept in the status until its reservations are deleted",
+			expectedError: "operation type DELETE on Azure resource my-rg/old-reservation is not done",
+			expect: func(s *mock_capacityreservationgroups.MockCapacityReservationGroupScopeMockRecorder, g, r *mock_async.MockReconcilerMockRecorder) {
+				notDoneErr := azure.NewOperationNotDoneError(&infrav1.Future{Type: infrav1.DeleteFuture, ResourceGroup: "my-rg", Name: "old-reservation"})
+				previous := []infrav1.CapacityReservationGroupStatus{
+					{
+						Name:         "old-crg",
+						ID:           "/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/capacityReservationGroups/old-crg",
+						Reservations: []infrav1.CapacityReservationStatus{{Name: "old-reservation", Reserved: 1}},
+					},
+				}
+				s.CapacityReservationGroupSpecs().Return([]azure.ResourceSpecGetter{})
+				s.CapacityReservationGroupsStatus().Return(previous)
+				s.ResourceGroup().Return("my-rg").AnyTimes()
+				s.CapacityReservationSpecs().Return(nil)
+				r.DeleteResource(gomockinternal.AContext(), &CapacityReservationSpec{Name: "old-reservation", GroupName: "old-crg", ResourceGroup: "my-rg"}, reservationsServiceName).Return(notDoneErr)
+				s.SetCapacityReservationGroupsStatus(previous)
+				s.UpdatePutStatus(infrav1.CapacityReservationGroupsReadyCondition, serviceName, notDoneErr)
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := mock_capacityreservationgroups.NewMockCapacityReservationGroupScope(mockCtrl)
+			groupsMock := mock_async.NewMockReconciler(mockCtrl)
+			reservationsMock := mock_async.NewMockReconciler(mockCtrl)
+
+			tc.expect(scopeMock.EXPECT(), groupsMock.EXPECT(), reservationsMock.EXPECT())
+
+			s := &Service{
+				Scope:        scopeMock,
+				groups:       groupsMock,
+				reservations: reservationsMock,
+			}
+
+			err := s.Reconcile(context.TODO())
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestDeleteCapacityReservationGroups(t *testing.T) {
+	testcases := []struct {
+		name          string
+		expectedError string
+		expect        func(s *mock_capacityreservationgroups.MockCapacityReservationGroupScopeMockRecorder, g, r *mock_async.MockReconcilerMockRecorder)
+	}{
+		{
+			name:          "noop if no capacity reservation group specs are found",
+			expectedError: "",
+			expect: func(s *mock_capacityreservationgroups.MockCapacityReservationGroupScopeMockRecorder, g, r *mock_async.MockReconcilerMockRecorder) {
+				s.CapacityReservationGroupSpecs().Return([]azure.ResourceSpecGetter{})
+				s.CapacityReservationGroupsStatus().Return(nil)
+			},
+		},
+		{
+			name:          "delete capacity reservations and then the capacity reservation group",
+			expectedError: "",
+			expect: func(s *mock_capacityreservationgroups.MockCapacityReservationGroupScopeMockRecorder, g, r *mock_async.MockReconcilerMockRecorder) {
+				s.CapacityReservationGroupSpecs().Return([]azure.ResourceSpecGetter{&groupSpec})
+				s.CapacityReservationGroupsStatus().Return(nil)
+				s.CapacityReservationSpecs().Return([]azure.ResourceSpecGetter{&reservationSpec1, &reservationSpec2})
+				gomock.InOrder(
+					r.DeleteResource(gomockinternal.AContext(), &reservationSpec1, reservationsServiceName).Return(nil),
+					r.DeleteResource(gomockinternal.AContext(), &reservationSpec2, reservationsServiceName).Return(nil),
+					g.DeleteResource(gomockinternal.AContext(), &groupSpec, serviceName).Return(nil),
+				)
+				s.SetCapacityReservationGroupsStatus(nil)
+				s.UpdateDeleteStatus(infrav1.CapacityReservationGroupsReadyCondition, serviceName, nil)
+			},
+		},
+		{
+			name:          "capacity reservation group is not deleted while a reservation fails to be deleted",
+			expectedError: internalError.Error(),
+			expect: func(s *mock_capacityreservationgroups.MockCapacityReservationGroupScopeMockRecorder, g, r *mock_async.MockReconcilerMockRecorder) {
+				s.CapacityReservationGroupSpecs().Return([]azure.ResourceSpecGetter{&groupSpec})
+				s.CapacityReservationGroupsStatus().Return(nil)
+				s.CapacityReservationSpecs().Return([]azure.ResourceSpecGetter{&reservationSpec1, &reservationSpec2})
+				r.DeleteResource(gomockinternal.AContext(), &reservationSpec1, reservationsServiceName).Return(internalError)
+				r.DeleteResource(gomockinternal.AContext(), &reservationSpec2, reservationsServiceName).Return(nil)
+				s.UpdateDeleteStatus(infrav1.CapacityReservationGroupsReadyCondition, serviceName, internalError)
+			},
+		},
+		{
+			name:          "delete capacity reservation groups removed from the spec that are still being deleted",
+			expectedError: "",
+			expect: func(s *mock_capacityreservationgroups.MockCapacityReservationGroupScopeMockRecorder, g, r *mock_async.MockReconcilerMockRecorder) {
+				s.CapacityReservationGroupSpecs().Return([]azure.ResourceSpecGetter{&groupSpec})
+				s.CapacityReservationGroupsStatus().Return([]infrav1.CapacityReservationGroupStatus{
+					{Name: "my-crg", Reservations: []infrav1.CapacityReservationStatus{{Name: "my-reservation-1"}}},
+					{Name: "old-crg"},
+				})
+				s.ResourceGroup().Return("my-rg").AnyTimes()
+				s.CapacityReservationSpecs().Return([]azure.ResourceSpecGetter{&reservationSpec1})
+				gomock.InOrder(
+					r.DeleteResource(gomockinternal.AContext(), &reservationSpec1, reservationsServiceName).Return(nil),
+					g.DeleteResource(gomockinternal.AContext(), &CapacityReservationGroupSpec{Name: "old-crg", ResourceGroup: "my-rg"}, serviceName).Return(nil),
+					g.DeleteResource(gomockinternal.AContext(), &groupSpec, serviceName).Return(nil),
+				)
+				s.SetCapacityReservationGroupsStatus(nil)
+				s.UpdateDeleteStatus(infrav1.CapacityReservationGroupsReadyCondition, serviceName, nil)
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := mock_capacityreservationgroups.NewMockCapacityReservationGroupScope(mockCtrl)
+			groupsMock := mock_async.NewMockReconciler(mockCtrl)
+			reservationsMock := mock_async.NewMockReconciler(mockCtrl)
+
+			tc.expect(scopeMock.EXPECT(), groupsMock.EXPECT(), reservationsMock.EXPECT())
+
+			s := &Service{
+				Scope:        scopeMock,
+				groups:       groupsMock,
+				reservations: reservationsMock,
+			}
+
+			err := s.Delete(context.TODO())
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestValidateReservation(t *testing.T) {
+	regional := compute.CapacityReservation{
+		Sku: &compute.Sku{Name: to.StringPtr("Standard_D4s_v3"), Capacity: to.Int64Ptr(1)},
+	}
+	reservations := []compute.CapacityReservation{reservation1, reservation2, regional}
+
+	testcases := []struct {
+		name          string
+		vmSize        string
+		zone          string
+		expectedError string
+	}{
+		{
+			name:   "zonal reservation matches VM size and zone",
+			vmSize: "Standard_D2s_v3",
+			zone:   "2",
+		},
+		{
+			name:   "VM size is matched case insensitively",
+			vmSize: "standard_d2s_v3",
+			zone:   "1",
+		},
+		{
+			name:   "regional reservation matches a VM without zone",
+			vmSize: "Standard_D4s_v3",
+		},
+		{
+			name:          "no reservation in zone",
+			vmSize:        "Standard_D2s_v3",
+			zone:          "3",
+			expectedError: "capacity reservation group my-crg-id has no capacity reservation for VM size Standard_D2s_v3 in zone 3",
+		},
+		{
+			name:          "zonal reservation does not match a VM without zone",
+			vmSize:        "Standard_D2s_v3",
+			expectedError: "capacity reservation group my-crg-id has no regional capacity reservation for VM size Standard_D2s_v3",
+		},
+		{
+			name:          "regional reservation does not match a zonal VM",
+			vmSize:        "Standard_D4s_v3",
+			zone:          "1",
+			expectedError: "capacity reservation group my-crg-id has no capacity reservation for VM size Standard_D4s_v3 in zone 1",
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			err := ValidateReservation(reservations, "my-crg-id", tc.vmSize, tc.zone)
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring(tc.expectedError))
+				var reconcileErr azure.ReconcileError
+				g.Expect(errors.As(err, &reconcileErr)).To(BeTrue())
+				g.Expect(reconcileErr.IsTerminal()).To(BeTrue())
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestReservationSpecFromID(t *testing.T) {
+	g := NewWithT(t)
+
+	spec, err := reservationSpecFromID("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/capacityReservationGroups/my-crg/capacityReservations/my-reservation-1")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(spec).To(Equal(&CapacityReservationSpec{Name: "my-reservation-1", GroupName: "my-crg", ResourceGroup: "my-rg"}))
+
+	_, err = reservationSpecFromID("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/capacityReservationGroups/my-crg")
+	g.Expect(err).To(HaveOccurred())
+}
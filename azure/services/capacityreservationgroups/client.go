@@ -0,0 +1,314 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacityreservationgroups
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
+	"github.com/Azure/go-autorest/autorest"
+	azureautorest "github.com/Azure/go-autorest/autorest/azure"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/util/reconciler"
+	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+)
+
+// Client wraps go-sdk.
+type Client interface {
+	ListReservations(ctx context.Context, groupID string) ([]compute.CapacityReservation, error)
+}
+
+// AzureClient contains the Azure go-sdk Client.
+type AzureClient struct {
+	reservations compute.CapacityReservationsClient
+}
+
+var _ Client = &AzureClient{}
+
+// NewClient creates a new capacity reservations client from subscription ID.
+func NewClient(auth azure.Authorizer) *AzureClient {
+	return &AzureClient{
+		reservations: newCapacityReservationsClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer()),
+	}
+}
+
+// newCapacityReservationGroupsClient creates a new capacity reservation groups client from subscription ID.
+func newCapacityReservationGroupsClient(subscriptionID string, baseURI string, authorizer autorest.Authorizer) compute.CapacityReservationGroupsClient {
+	groupsClient := compute.NewCapacityReservationGroupsClientWithBaseURI(baseURI, subscriptionID)
+	azure.SetAutoRestClientDefaults(&groupsClient.Client, authorizer)
+	return groupsClient
+}
+
+// newCapacityReservationsClient creates a new capacity reservations client from subscription ID.
+func newCapacityReservationsClient(subscriptionID string, baseURI string, authorizer autorest.Authorizer) compute.CapacityReservationsClient {
+	reservationsClient := compute.NewCapacityReservationsClientWithBaseURI(baseURI, subscriptionID)
+	azure.SetAutoRestClientDefaults(&reservationsClient.Client, authorizer)
+	return reservationsClient
+}
+
+// ListReservations returns the capacity reservations of the capacity reservation group with the given resource ID.
+func (ac *AzureClient) ListReservations(ctx context.Context, groupID string) ([]compute.CapacityReservation, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "capacityreservationgroups.AzureClient.ListReservations")
+	defer done()
+
+	group, err := azureautorest.ParseResourceID(groupID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse capacity reservation group ID %s", groupID)
+	}
+
+	iter, err := ac.reservations.ListByCapacityReservationGroupComplete(ctx, group.ResourceGroup, group.ResourceName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list capacity reservations of group %s", groupID)
+	}
+
+	var reservations []compute.CapacityReservation
+	for iter.NotDone() {
+		reservations = append(reservations, iter.Value())
+		if err := iter.NextWithContext(ctx); err != nil {
+			return nil, errors.Wrap(err, "failed to iterate capacity reservations")
+		}
+	}
+
+	return reservations, nil
+}
+
+// groupsClient is the async client for capacity reservation groups.
+type groupsClient struct {
+	groups compute.CapacityReservationGroupsClient
+}
+
+// newGroupsClient creates a new capacity reservation groups async client from subscription ID.
+func newGroupsClient(auth azure.Authorizer) *groupsClient {
+	return &groupsClient{
+		groups: newCapacityReservationGroupsClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer()),
+	}
+}
+
+// Get gets a capacity reservation group.
+func (gc *groupsClient) Get(ctx context.Context, spec azure.ResourceSpecGetter) (result interface{}, err error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "capacityreservationgroups.groupsClient.Get")
+	defer done()
+
+	return gc.groups.Get(ctx, spec.ResourceGroupName(), spec.ResourceName(), "")
+}
+
+// CreateOrUpdateAsync creates or updates a capacity reservation group.
+// Capacity reservation groups are created synchronously, so a nil future is always returned.
+func (gc *groupsClient) CreateOrUpdateAsync(ctx context.Context, spec azure.ResourceSpecGetter, parameters interface{}) (result interface{}, future azureautorest.FutureAPI, err error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "capacityreservationgroups.groupsClient.CreateOrUpdateAsync")
+	defer done()
+
+	group, ok := parameters.(compute.CapacityReservationGroup)
+	if !ok {
+		return nil, nil, errors.Errorf("%T is not a compute.CapacityReservationGroup", parameters)
+	}
+
+	result, err = gc.groups.CreateOrUpdate(ctx, spec.ResourceGroupName(), spec.ResourceName(), group)
+	return result, nil, err
+}
+
+// DeleteAsync deletes a capacity reservation group.
+// Capacity reservation groups are deleted synchronously, so a nil future is always returned.
+func (gc *groupsClient) DeleteAsync(ctx context.Context, spec azure.ResourceSpecGetter) (future azureautorest.FutureAPI, err error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "capacityreservationgroups.groupsClient.DeleteAsync")
+	defer done()
+
+	_, err = gc.groups.Delete(ctx, spec.ResourceGroupName(), spec.ResourceName())
+	return nil, err
+}
+
+// IsDone returns true if the long-running operation has completed.
+func (gc *groupsClient) IsDone(ctx context.Context, future azureautorest.FutureAPI) (isDone bool, err error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "capacityreservationgroups.groupsClient.IsDone")
+	defer done()
+
+	isDone, err = future.DoneWithContext(ctx, gc.groups)
+	if err != nil {
+		return false, errors.Wrap(err, "failed checking if the operation was complete")
+	}
+
+	return isDone, nil
+}
+
+// Result fetches the result of a long-running operation future.
+func (gc *groupsClient) Result(ctx context.Context, future azureautorest.FutureAPI, futureType string) (result interface{}, err error) {
+	// Result is a no-op for capacity reservation groups as their operations never return a future.
+	return nil, nil
+}
+
+// reservationsClient is the async client for capacity reservations.
+type reservationsClient struct {
+	reservations compute.CapacityReservationsClient
+}
+
+// newReservationsClient creates a new capacity reservations async client from subscription ID.
+func newReservationsClient(auth azure.Authorizer) *reservationsClient {
+	return &reservationsClient{
+		reservations: newCapacityReservationsClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer()),
+	}
+}
+
+// Get gets a capacity reservation, including its instance view so the allocated VMs are known.
+func (rc *reservationsClient) Get(ctx context.Context, spec azure.ResourceSpecGetter) (result interface{}, err error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "capacityreservationgroups.reservationsClient.Get")
+	defer done()
+
+	return rc.getWithInstanceView(ctx, spec)
+}
+
+// CreateOrUpdateAsync creates or updates a capacity reservation asynchronously.
+// It sends a PUT request to Azure and if accepted without error, the func will return a Future which can be used to track the ongoing
+// progress of the operation.
+func (rc *reservationsClient) CreateOrUpdateAsync(ctx context.Context, spec azure.ResourceSpecGetter, parameters interface{}) (result interface{}, future azureautorest.FutureAPI, err error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "capacityreservationgroups.reservationsClient.CreateOrUpdateAsync")
+	defer done()
+
+	reservation, ok := parameters.(compute.CapacityReservation)
+	if !ok {
+		return nil, nil, errors.Errorf("%T is not a compute.CapacityReservation", parameters)
+	}
+
+	createFuture, err := rc.reservations.CreateOrUpdate(ctx, spec.ResourceGroupName(), spec.OwnerResourceName(), spec.ResourceName(), reservation)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureCallTimeout)
+	defer cancel()
+
+	err = createFuture.WaitForCompletionRef(ctx, rc.reservations.Client)
+	if err != nil {
+		// if an error occurs, return the future.
+		// this means the long-running operation didn't finish in the specified timeout.
+		return nil, &createFuture, err
+	}
+
+	if _, err := createFuture.Result(rc.reservations); err != nil {
+		return nil, nil, err
+	}
+	// if the operation completed, return a nil future
+	result, err = rc.getWithInstanceView(ctx, spec)
+	return result, nil, err
+}
+
+// getWithInstanceView gets a capacity reservation with its instance view. The response of a PUT has no instance view,
+// so the capacity reservation is fetched again after it is created or updated to report its used capacity.
+func (rc *reservationsClient) getWithInstanceView(ctx context.Context, spec azure.ResourceSpecGetter) (compute.CapacityReservation, error) {
+	return rc.reservations.Get(ctx, spec.ResourceGroupName(), spec.OwnerResourceName(), spec.ResourceName(), compute.CapacityReservationInstanceViewTypesInstanceView)
+}
+
+// DeleteAsync deletes a capacity reservation asynchronously. DeleteAsync sends a DELETE
+// request to Azure and if accepted without error, the func will return a Future which can be used to track the ongoing
+// progress of the operation.
+func (rc *reservationsClient) DeleteAsync(ctx context.Context, spec azure.ResourceSpecGetter) (future azureautorest.FutureAPI, err error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "capacityreservationgroups.reservationsClient.DeleteAsync")
+	defer done()
+
+	deleteFuture, err := rc.reservations.Delete(ctx, spec.ResourceGroupName(), spec.OwnerResourceName(), spec.ResourceName())
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureCallTimeout)
+	defer cancel()
+
+	err = deleteFuture.WaitForCompletionRef(ctx, rc.reservations.Client)
+	if err != nil {
+		// if an error occurs, return the future.
+		// this means the long-running operation didn't finish in the specified timeout.
+		return &deleteFuture, err
+	}
+	_, err = deleteFuture.Result(rc.reservations)
+	// if the operation completed, return a nil future.
+	return nil, err
+}
+
+// IsDone returns true if the long-running operation has completed.
+func (rc *reservationsClient) IsDone(ctx context.Context, future azureautorest.FutureAPI) (isDone bool, err error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "capacityreservationgroups.reservationsClient.IsDone")
+	defer done()
+
+	isDone, err = future.DoneWithContext(ctx, rc.reservations)
+	if err != nil {
+		return false, errors.Wrap(err, "failed checking if the operation was complete")
+	}
+
+	return isDone, nil
+}
+
+// Result fetches the result of a long-running operation future.
+func (rc *reservationsClient) Result(ctx context.Context, future azureautorest.FutureAPI, futureType string) (result interface{}, err error) {
+	_, _, done := tele.StartSpanWithLogger(ctx, "capacityreservationgroups.reservationsClient.Result")
+	defer done()
+
+	if future == nil {
+		return nil, errors.Errorf("cannot get result from nil future")
+	}
+
+	switch futureType {
+	case infrav1.PutFuture:
+		// Marshal and Unmarshal the future to put it into the correct future type so we can access the Result function.
+		// Unfortunately the FutureAPI can't be casted directly to CapacityReservationsCreateOrUpdateFuture because it is a azureautorest.Future, which doesn't implement the Result function. See PR #1686 for discussion on alternatives.
+		var createFuture *compute.CapacityReservationsCreateOrUpdateFuture
+		jsonData, err := future.MarshalJSON()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to marshal future")
+		}
+		if err := json.Unmarshal(jsonData, &createFuture); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal future data")
+		}
+		reservation, err := createFuture.Result(rc.reservations)
+		if err != nil {
+			return nil, err
+		}
+		spec, err := reservationSpecFromID(to.String(reservation.ID))
+		if err != nil {
+			return nil, err
+		}
+		return rc.getWithInstanceView(ctx, spec)
+
+	case infrav1.DeleteFuture:
+		// Delete does not return a result capacity reservation
+		return nil, nil
+
+	default:
+		return nil, errors.Errorf("unknown future type %q", futureType)
+	}
+}
+
+// reservationSpecFromID returns a spec identifying the capacity reservation with the given resource ID.
+func reservationSpecFromID(id string) (*CapacityReservationSpec, error) {
+	resource, err := azureautorest.ParseResourceID(id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse capacity reservation ID %s", id)
+	}
+	// The ID ends with capacityReservationGroups/{group}/capacityReservations/{name}.
+	segments := strings.Split(strings.Trim(id, "/"), "/")
+	if len(segments) < 4 || !strings.EqualFold(segments[len(segments)-2], "capacityReservations") {
+		return nil, errors.Errorf("%s is not the ID of a capacity reservation", id)
+	}
+	return &CapacityReservationSpec{
+		Name:          segments[len(segments)-1],
+		GroupName:     segments[len(segments)-3],
+		ResourceGroup: resource.ResourceGroup,
+	}, nil
+}
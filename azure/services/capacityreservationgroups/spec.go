@@ -0,0 +1,137 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacityreservationgroups
+
+import (
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/converters"
+)
+
+// CapacityReservationGroupSpec defines the specification for a capacity reservation group.
+type CapacityReservationGroupSpec struct {
+	Name           string
+	ResourceGroup  string
+	ClusterName    string
+	Location       string
+	Zones          []string
+	AdditionalTags infrav1.Tags
+}
+
+// ResourceName returns the name of the capacity reservation group.
+func (s *CapacityReservationGroupSpec) ResourceName() string {
+	return s.Name
+}
+
+// ResourceGroupName returns the name of the resource group.
+func (s *CapacityReservationGroupSpec) ResourceGroupName() string {
+	return s.ResourceGroup
+}
+
+// OwnerResourceName is a no-op for capacity reservation groups.
+func (s *CapacityReservationGroupSpec) OwnerResourceName() string {
+	return ""
+}
+
+// Parameters returns the parameters for the capacity reservation group.
+func (s *CapacityReservationGroupSpec) Parameters(existing interface{}) (params interface{}, err error) {
+	if existing != nil {
+		if _, ok := existing.(compute.CapacityReservationGroup); !ok {
+			return nil, errors.Errorf("%T is not a compute.CapacityReservationGroup", existing)
+		}
+		// capacity reservation group already exists, its zones can only be set on creation.
+		return nil, nil
+	}
+
+	group := compute.CapacityReservationGroup{
+		Location: to.StringPtr(s.Location),
+		Tags: converters.TagsToMap(infrav1.Build(infrav1.BuildParams{
+			ClusterName: s.ClusterName,
+			Lifecycle:   infrav1.ResourceLifecycleOwned,
+			Name:        to.StringPtr(s.Name),
+			Additional:  s.AdditionalTags,
+		})),
+	}
+	if len(s.Zones) > 0 {
+		group.Zones = to.StringSlicePtr(s.Zones)
+	}
+
+	return group, nil
+}
+
+// CapacityReservationSpec defines the specification for a capacity reservation within a capacity reservation group.
+type CapacityReservationSpec struct {
+	Name           string
+	GroupName      string
+	ResourceGroup  string
+	ClusterName    string
+	Location       string
+	VMSize         string
+	Zone           string
+	Capacity       int64
+	AdditionalTags infrav1.Tags
+}
+
+// ResourceName returns the name of the capacity reservation.
+func (s *CapacityReservationSpec) ResourceName() string {
+	return s.Name
+}
+
+// ResourceGroupName returns the name of the resource group.
+func (s *CapacityReservationSpec) ResourceGroupName() string {
+	return s.ResourceGroup
+}
+
+// OwnerResourceName returns the name of the capacity reservation group the capacity reservation belongs to.
+func (s *CapacityReservationSpec) OwnerResourceName() string {
+	return s.GroupName
+}
+
+// Parameters returns the parameters for the capacity reservation.
+func (s *CapacityReservationSpec) Parameters(existing interface{}) (params interface{}, err error) {
+	if existing != nil {
+		existingReservation, ok := existing.(compute.CapacityReservation)
+		if !ok {
+			return nil, errors.Errorf("%T is not a compute.CapacityReservation", existing)
+		}
+		if existingReservation.Sku != nil && existingReservation.Sku.Capacity != nil && *existingReservation.Sku.Capacity == s.Capacity {
+			// capacity reservation already exists with the expected capacity
+			return nil, nil
+		}
+	}
+
+	reservation := compute.CapacityReservation{
+		Location: to.StringPtr(s.Location),
+		Sku: &compute.Sku{
+			Name:     to.StringPtr(s.VMSize),
+			Capacity: to.Int64Ptr(s.Capacity),
+		},
+		Tags: converters.TagsToMap(infrav1.Build(infrav1.BuildParams{
+			ClusterName: s.ClusterName,
+			Lifecycle:   infrav1.ResourceLifecycleOwned,
+			Name:        to.StringPtr(s.Name),
+			Additional:  s.AdditionalTags,
+		})),
+	}
+	if s.Zone != "" {
+		reservation.Zones = &[]string{s.Zone}
+	}
+
+	return reservation, nil
+}
@@ -18,21 +18,30 @@ package disks
 
 import (
 	"context"
+	"encoding/json"
 
-	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
 	"github.com/Azure/go-autorest/autorest"
 	azureautorest "github.com/Azure/go-autorest/autorest/azure"
 	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
 	"sigs.k8s.io/cluster-api-provider-azure/util/reconciler"
 	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
 )
 
+// client wraps go-sdk.
+type client interface {
+	Get(context.Context, azure.ResourceSpecGetter) (interface{}, error)
+}
+
 // azureClient contains the Azure go-sdk Client.
 type azureClient struct {
 	disks compute.DisksClient
 }
 
+var _ client = (*azureClient)(nil)
+
 // newClient creates a new disk Client from subscription ID.
 func newClient(auth azure.Authorizer) *azureClient {
 	c := NewDisksClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer())
@@ -46,6 +55,45 @@ func NewDisksClient(subscriptionID string, baseURI string, authorizer autorest.A
 	return disksClient
 }
 
+// Get gets the specified disk.
+func (ac *azureClient) Get(ctx context.Context, spec azure.ResourceSpecGetter) (result interface{}, err error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "disks.azureClient.Get")
+	defer done()
+
+	return ac.disks.Get(ctx, spec.ResourceGroupName(), spec.ResourceName())
+}
+
+// CreateOrUpdateAsync creates or updates a disk asynchronously.
+// It sends a PUT request to Azure and if accepted without error, the func will return a Future which can be used to track the ongoing
+// progress of the operation.
+func (ac *azureClient) CreateOrUpdateAsync(ctx context.Context, spec azure.ResourceSpecGetter, parameters interface{}) (result interface{}, future azureautorest.FutureAPI, err error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "disks.azureClient.CreateOrUpdateAsync")
+	defer done()
+
+	disk, ok := parameters.(compute.Disk)
+	if !ok {
+		return nil, nil, errors.Errorf("%T is not a compute.Disk", parameters)
+	}
+
+	createFuture, err := ac.disks.CreateOrUpdate(ctx, spec.ResourceGroupName(), spec.ResourceName(), disk)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureCallTimeout)
+	defer cancel()
+
+	err = createFuture.WaitForCompletionRef(ctx, ac.disks.Client)
+	if err != nil {
+		// if an error occurs, return the future.
+		// this means the long-running operation didn't finish in the specified timeout.
+		return nil, &createFuture, err
+	}
+	result, err = createFuture.Result(ac.disks)
+	// if the operation completed, return a nil future
+	return result, nil, err
+}
+
 // DeleteAsync deletes a route table asynchronously. DeleteAsync sends a DELETE
 // request to Azure and if accepted without error, the func will return a Future which can be used to track the ongoing
 // progress of the operation.
@@ -74,7 +122,34 @@ func (ac *azureClient) DeleteAsync(ctx context.Context, spec azure.ResourceSpecG
 
 // Result fetches the result of a long-running operation future.
 func (ac *azureClient) Result(ctx context.Context, future azureautorest.FutureAPI, futureType string) (result interface{}, err error) {
-	return nil, nil
+	_, _, done := tele.StartSpanWithLogger(ctx, "disks.azureClient.Result")
+	defer done()
+
+	if future == nil {
+		return nil, errors.Errorf("cannot get result from nil future")
+	}
+
+	switch futureType {
+	case infrav1.PutFuture:
+		// Marshal and Unmarshal the future to put it into the correct future type so we can access the Result function.
+		// Unfortunately the FutureAPI can't be casted directly to DisksCreateOrUpdateFuture because it is a azureautorest.Future, which doesn't implement the Result function. See PR #1686 for discussion on alternatives.
+		var createFuture *compute.DisksCreateOrUpdateFuture
+		jsonData, err := future.MarshalJSON()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to marshal future")
+		}
+		if err := json.Unmarshal(jsonData, &createFuture); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal future data")
+		}
+		return createFuture.Result(ac.disks)
+
+	case infrav1.DeleteFuture:
+		// Delete does not return a result disk.
+		return nil, nil
+
+	default:
+		return nil, errors.Errorf("unknown future type %q", futureType)
+	}
 }
 
 // IsDone returns true if the long-running operation has completed.
@@ -19,6 +19,8 @@ package disks
 import (
 	"context"
 
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
+	"github.com/pkg/errors"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async"
@@ -26,19 +28,28 @@ import (
 	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
 )
 
-const serviceName = "disks"
+const (
+	serviceName = "disks"
+	// vmServiceName is the name of the virtual machines service, which keys the long-running operations on the VM.
+	vmServiceName = "virtualmachine"
+)
 
 // DiskScope defines the scope interface for a disk service.
 type DiskScope interface {
 	azure.ClusterDescriber
 	azure.AsyncStatusUpdater
+	Name() string
 	DiskSpecs() []azure.ResourceSpecGetter
+	DataDiskSpecs() []azure.ResourceSpecGetter
+	RemovedDataDisks() []string
+	SetRemovedDataDisks([]string)
 }
 
 // Service provides operations on Azure resources.
 type Service struct {
 	Scope DiskScope
 	async.Reconciler
+	client client
 }
 
 // New creates a new disks service.
@@ -46,7 +57,8 @@ func New(scope DiskScope) *Service {
 	client := newClient(scope)
 	return &Service{
 		Scope:      scope,
-		Reconciler: async.New(scope, nil, client),
+		client:     client,
+		Reconciler: async.New(scope, client, client),
 	}
 }
 
@@ -55,13 +67,85 @@ func (s *Service) Name() string {
 	return serviceName
 }
 
-// Reconcile on disk is currently no-op. OS disks should only be deleted and will create with the VM automatically.
+// Reconcile grows the data disks whose size was increased and deletes the removed data disks once they are detached.
+// OS and data disks are created with the VM.
 func (s *Service) Reconcile(ctx context.Context) error {
-	_, _, done := tele.StartSpanWithLogger(ctx, "disks.Service.Reconcile")
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "disks.Service.Reconcile")
 	defer done()
 
-	// DisksReadyCondition is set in the VM service.
-	return nil
+	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureServiceReconcileTimeout)
+	defer cancel()
+
+	specs := s.Scope.DataDiskSpecs()
+	removed := s.Scope.RemovedDataDisks()
+	if len(specs) == 0 && len(removed) == 0 {
+		return nil
+	}
+
+	// We go through the list of DataDiskSpecs to grow each one, independently of the result of the previous one.
+	// If multiple errors occur, we return the most pressing one.
+	//  Order of precedence (highest -> lowest) is: error that is not an operationNotDoneError (i.e. error updating) -> operationNotDoneError (i.e. updating in progress) -> no error (i.e. updated)
+	var result error
+	for _, diskSpec := range specs {
+		if _, err := s.CreateResource(ctx, diskSpec, serviceName); err != nil {
+			if !azure.IsOperationNotDoneError(err) || result == nil {
+				result = err
+			}
+		}
+	}
+
+	if result == nil && len(removed) > 0 {
+		result = s.deleteRemovedDataDisks(ctx, removed)
+	}
+
+	// DisksReadyCondition is also set in the VM service since the disks are created with the VM.
+	s.Scope.UpdatePatchStatus(infrav1.DisksReadyCondition, serviceName, result)
+	return result
+}
+
+// deleteRemovedDataDisks deletes the data disks that the VM service detached after they were removed from the spec with
+// the Delete removal policy, and forgets the ones that no longer exist. A data disk that is still attached is requeued
+// while the VM update detaching it is in progress, and is otherwise left for the VM service to detach again.
+func (s *Service) deleteRemovedDataDisks(ctx context.Context, names []string) error {
+	var (
+		result  error
+		pending []string
+	)
+	for _, name := range names {
+		diskSpec := &DiskSpec{
+			Name:          name,
+			ResourceGroup: s.Scope.ResourceGroup(),
+		}
+		existing, err := s.client.Get(ctx, diskSpec)
+		if azure.ResourceNotFound(err) {
+			continue
+		}
+		pending = append(pending, name)
+		if err != nil {
+			result = errors.Wrapf(err, "failed to get data disk %s", name)
+			continue
+		}
+		disk, ok := existing.(compute.Disk)
+		if !ok {
+			result = errors.Errorf("%T is not a compute.Disk", existing)
+			continue
+		}
+		if disk.ManagedBy != nil {
+			if result == nil && s.Scope.GetLongRunningOperationState(s.Scope.Name(), vmServiceName) != nil {
+				result = azure.WithTransientError(errors.Errorf("data disk %s is still being detached", name), reconciler.DefaultReconcilerRequeue)
+			}
+			continue
+		}
+		if err := s.DeleteResource(ctx, diskSpec, serviceName); err != nil {
+			if !azure.IsOperationNotDoneError(err) || result == nil {
+				result = err
+			}
+			continue
+		}
+		pending = pending[:len(pending)-1]
+	}
+	s.Scope.SetRemovedDataDisks(pending)
+	return result
 }
 
 // Delete deletes the disk associated with a VM.
@@ -21,7 +21,9 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
 	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/gomega"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
@@ -46,10 +48,141 @@ var (
 		&diskSpec1,
 		&diskSpec2,
 	}
+	dataDiskSpec1 = DataDiskSpec{
+		Name:          "my-vm_disk1",
+		ResourceGroup: "my-group",
+		DiskSizeGB:    128,
+	}
+	fakeDataDiskSpecs = []azure.ResourceSpecGetter{
+		&dataDiskSpec1,
+	}
 
 	internalError = autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 500}, "Internal Server Error")
+	notFoundError = autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not Found")
 )
 
+func TestReconcileDisks(t *testing.T) {
+	testcases := []struct {
+		name          string
+		expectedError string
+		expect        func(s *mock_disks.MockDiskScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder, c *mock_disks.MockclientMockRecorder)
+	}{
+		{
+			name:          "noop if no data disk specs and removed data disks are found",
+			expectedError: "",
+			expect: func(s *mock_disks.MockDiskScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder, c *mock_disks.MockclientMockRecorder) {
+				s.DataDiskSpecs().Return([]azure.ResourceSpecGetter{})
+				s.RemovedDataDisks().Return(nil)
+			},
+		},
+		{
+			name:          "grow the data disks",
+			expectedError: "",
+			expect: func(s *mock_disks.MockDiskScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder, c *mock_disks.MockclientMockRecorder) {
+				s.DataDiskSpecs().Return(fakeDataDiskSpecs)
+				s.RemovedDataDisks().Return(nil)
+				gomock.InOrder(
+					r.CreateResource(gomockinternal.AContext(), &dataDiskSpec1, serviceName).Return(nil, nil),
+					s.UpdatePatchStatus(infrav1.DisksReadyCondition, serviceName, nil),
+				)
+			},
+		},
+		{
+			name:          "error while trying to grow the data disks",
+			expectedError: "#: Internal Server Error: StatusCode=500",
+			expect: func(s *mock_disks.MockDiskScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder, c *mock_disks.MockclientMockRecorder) {
+				s.DataDiskSpecs().Return(fakeDataDiskSpecs)
+				s.RemovedDataDisks().Return([]string{"my-vm_disk2"})
+				gomock.InOrder(
+					r.CreateResource(gomockinternal.AContext(), &dataDiskSpec1, serviceName).Return(nil, internalError),
+					s.UpdatePatchStatus(infrav1.DisksReadyCondition, serviceName, internalError),
+				)
+			},
+		},
+		{
+			name:          "delete the detached removed data disks and forget the deleted ones",
+			expectedError: "operation type  on Azure resource / is not done",
+			expect: func(s *mock_disks.MockDiskScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder, c *mock_disks.MockclientMockRecorder) {
+				s.DataDiskSpecs().Return(fakeDataDiskSpecs)
+				s.RemovedDataDisks().Return([]string{"my-vm_disk2", "my-vm_disk3", "my-vm_disk4"})
+				s.Name().AnyTimes().Return("my-vm")
+				s.ResourceGroup().AnyTimes().Return("my-group")
+				gomock.InOrder(
+					r.CreateResource(gomockinternal.AContext(), &dataDiskSpec1, serviceName).Return(nil, nil),
+					c.Get(gomockinternal.AContext(), &DiskSpec{Name: "my-vm_disk2", ResourceGroup: "my-group"}).Return(compute.Disk{Name: to.StringPtr("my-vm_disk2")}, nil),
+					r.DeleteResource(gomockinternal.AContext(), &DiskSpec{Name: "my-vm_disk2", ResourceGroup: "my-group"}, serviceName).Return(nil),
+					c.Get(gomockinternal.AContext(), &DiskSpec{Name: "my-vm_disk3", ResourceGroup: "my-group"}).Return(nil, notFoundError),
+					c.Get(gomockinternal.AContext(), &DiskSpec{Name: "my-vm_disk4", ResourceGroup: "my-group"}).Return(compute.Disk{Name: to.StringPtr("my-vm_disk4")}, nil),
+					r.DeleteResource(gomockinternal.AContext(), &DiskSpec{Name: "my-vm_disk4", ResourceGroup: "my-group"}, serviceName).Return(azure.NewOperationNotDoneError(&infrav1.Future{})),
+					s.SetRemovedDataDisks([]string{"my-vm_disk4"}),
+					s.UpdatePatchStatus(infrav1.DisksReadyCondition, serviceName, gomockinternal.ErrStrEq("operation type  on Azure resource / is not done")),
+				)
+			},
+		},
+		{
+			name:          "requeue while a removed data disk is being detached",
+			expectedError: "data disk my-vm_disk2 is still being detached. Object will be requeued after 15s",
+			expect: func(s *mock_disks.MockDiskScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder, c *mock_disks.MockclientMockRecorder) {
+				s.DataDiskSpecs().Return(nil)
+				s.RemovedDataDisks().Return([]string{"my-vm_disk2"})
+				s.Name().AnyTimes().Return("my-vm")
+				s.ResourceGroup().AnyTimes().Return("my-group")
+				gomock.InOrder(
+					c.Get(gomockinternal.AContext(), &DiskSpec{Name: "my-vm_disk2", ResourceGroup: "my-group"}).Return(compute.Disk{Name: to.StringPtr("my-vm_disk2"), ManagedBy: to.StringPtr("my-vm-id")}, nil),
+					s.GetLongRunningOperationState("my-vm", vmServiceName).Return(&infrav1.Future{}),
+					s.SetRemovedDataDisks([]string{"my-vm_disk2"}),
+					s.UpdatePatchStatus(infrav1.DisksReadyCondition, serviceName, gomockinternal.ErrStrEq("data disk my-vm_disk2 is still being detached. Object will be requeued after 15s")),
+				)
+			},
+		},
+		{
+			name:          "keep an attached removed data disk for the VM service to detach it again",
+			expectedError: "",
+			expect: func(s *mock_disks.MockDiskScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder, c *mock_disks.MockclientMockRecorder) {
+				s.DataDiskSpecs().Return(nil)
+				s.RemovedDataDisks().Return([]string{"my-vm_disk2"})
+				s.Name().AnyTimes().Return("my-vm")
+				s.ResourceGroup().AnyTimes().Return("my-group")
+				gomock.InOrder(
+					c.Get(gomockinternal.AContext(), &DiskSpec{Name: "my-vm_disk2", ResourceGroup: "my-group"}).Return(compute.Disk{Name: to.StringPtr("my-vm_disk2"), ManagedBy: to.StringPtr("my-vm-id")}, nil),
+					s.GetLongRunningOperationState("my-vm", vmServiceName).Return(nil),
+					s.SetRemovedDataDisks([]string{"my-vm_disk2"}),
+					s.UpdatePatchStatus(infrav1.DisksReadyCondition, serviceName, nil),
+				)
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := mock_disks.NewMockDiskScope(mockCtrl)
+			asyncMock := mock_async.NewMockReconciler(mockCtrl)
+			clientMock := mock_disks.NewMockclient(mockCtrl)
+
+			tc.expect(scopeMock.EXPECT(), asyncMock.EXPECT(), clientMock.EXPECT())
+
+			s := &Service{
+				Scope:      scopeMock,
+				Reconciler: asyncMock,
+				client:     clientMock,
+			}
+
+			err := s.Reconcile(context.TODO())
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
+
 func TestDeleteDisk(t *testing.T) {
 	testcases := []struct {
 		name          string
@@ -179,6 +179,20 @@ func (mr *MockDiskScopeMockRecorder) ClusterName() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClusterName", reflect.TypeOf((*MockDiskScope)(nil).ClusterName))
 }
 
+// DataDiskSpecs mocks base method.
+func (m *MockDiskScope) DataDiskSpecs() []azure.ResourceSpecGetter {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DataDiskSpecs")
+	ret0, _ := ret[0].([]azure.ResourceSpecGetter)
+	return ret0
+}
+
+// DataDiskSpecs indicates an expected call of DataDiskSpecs.
+func (mr *MockDiskScopeMockRecorder) DataDiskSpecs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DataDiskSpecs", reflect.TypeOf((*MockDiskScope)(nil).DataDiskSpecs))
+}
+
 // DeleteLongRunningOperationState mocks base method.
 func (m *MockDiskScope) DeleteLongRunningOperationState(arg0, arg1 string) {
 	m.ctrl.T.Helper()
@@ -261,6 +275,34 @@ func (mr *MockDiskScopeMockRecorder) Location() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Location", reflect.TypeOf((*MockDiskScope)(nil).Location))
 }
 
+// Name mocks base method.
+func (m *MockDiskScope) Name() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Name")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Name indicates an expected call of Name.
+func (mr *MockDiskScopeMockRecorder) Name() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Name", reflect.TypeOf((*MockDiskScope)(nil).Name))
+}
+
+// RemovedDataDisks mocks base method.
+func (m *MockDiskScope) RemovedDataDisks() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemovedDataDisks")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// RemovedDataDisks indicates an expected call of RemovedDataDisks.
+func (mr *MockDiskScopeMockRecorder) RemovedDataDisks() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemovedDataDisks", reflect.TypeOf((*MockDiskScope)(nil).RemovedDataDisks))
+}
+
 // ResourceGroup mocks base method.
 func (m *MockDiskScope) ResourceGroup() string {
 	m.ctrl.T.Helper()
@@ -287,6 +329,18 @@ func (mr *MockDiskScopeMockRecorder) SetLongRunningOperationState(arg0 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLongRunningOperationState", reflect.TypeOf((*MockDiskScope)(nil).SetLongRunningOperationState), arg0)
 }
 
+// SetRemovedDataDisks mocks base method.
+func (m *MockDiskScope) SetRemovedDataDisks(arg0 []string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetRemovedDataDisks", arg0)
+}
+
+// SetRemovedDataDisks indicates an expected call of SetRemovedDataDisks.
+func (mr *MockDiskScopeMockRecorder) SetRemovedDataDisks(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRemovedDataDisks", reflect.TypeOf((*MockDiskScope)(nil).SetRemovedDataDisks), arg0)
+}
+
 // SubscriptionID mocks base method.
 func (m *MockDiskScope) SubscriptionID() string {
 	m.ctrl.T.Helper()
@@ -16,6 +16,15 @@ limitations under the License.
 
 package disks
 
+import (
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/converters"
+)
+
 // DiskSpec defines the specification for a disk.
 type DiskSpec struct {
 	Name          string
@@ -41,3 +50,132 @@ func (s *DiskSpec) OwnerResourceName() string {
 func (s *DiskSpec) Parameters(existing interface{}) (params interface{}, err error) {
 	return nil, nil
 }
+
+// DataDiskSpec defines the specification for a data disk of a virtual machine.
+type DataDiskSpec struct {
+	Name                string
+	ResourceGroup       string
+	Location            string
+	Zone                string
+	ClusterName         string
+	AdditionalTags      infrav1.Tags
+	DiskSizeGB          int32
+	StorageAccountType  string
+	DiskEncryptionSetID string
+	DiskIOPSReadWrite   *int64
+	DiskMBpsReadWrite   *int64
+	MaxShares           *int32
+	// Standalone is true if the disk is created on its own and then attached to the virtual machine,
+	// rather than created along with it.
+	Standalone bool
+}
+
+// IsStandaloneDataDisk returns true if the data disk has properties that can't be set when the disk is created
+// along with the virtual machine, in which case the disk is created on its own and then attached to it.
+func IsStandaloneDataDisk(disk infrav1.DataDisk) bool {
+	return disk.SourceDiskID == nil && (disk.DiskIOPSReadWrite != nil || disk.DiskMBpsReadWrite != nil || disk.MaxShares != nil)
+}
+
+// ResourceName returns the name of the disk.
+func (s *DataDiskSpec) ResourceName() string {
+	return s.Name
+}
+
+// ResourceGroupName returns the name of the resource group.
+func (s *DataDiskSpec) ResourceGroupName() string {
+	return s.ResourceGroup
+}
+
+// OwnerResourceName is a no-op for disks.
+func (s *DataDiskSpec) OwnerResourceName() string {
+	return ""
+}
+
+// Parameters returns the parameters for creating a standalone data disk, or for growing an existing data disk
+// and updating its performance. Other data disks are created along with the virtual machine they are attached to,
+// so no parameters are returned for them if they don't exist yet.
+func (s *DataDiskSpec) Parameters(existing interface{}) (params interface{}, err error) {
+	if existing == nil {
+		if !s.Standalone {
+			return nil, nil
+		}
+		return s.standaloneDisk(), nil
+	}
+
+	disk, ok := existing.(compute.Disk)
+	if !ok {
+		return nil, errors.Errorf("%T is not a compute.Disk", existing)
+	}
+
+	if disk.DiskProperties == nil {
+		return nil, nil
+	}
+
+	changed := false
+	if to.Int32(disk.DiskSizeGB) < s.DiskSizeGB {
+		// Ultra disks can't be expanded while they are attached to a running virtual machine.
+		if disk.Sku != nil && disk.Sku.Name == compute.UltraSSDLRS && disk.DiskState == compute.Attached {
+			return nil, azure.WithTerminalError(errors.Errorf("disk %s does not support live resize, deallocate the VM to grow it to %d GB", s.Name, s.DiskSizeGB))
+		}
+		disk.DiskSizeGB = to.Int32Ptr(s.DiskSizeGB)
+		changed = true
+	}
+
+	if s.DiskIOPSReadWrite != nil && to.Int64(disk.DiskIOPSReadWrite) != *s.DiskIOPSReadWrite {
+		disk.DiskIOPSReadWrite = s.DiskIOPSReadWrite
+		changed = true
+	}
+
+	if s.DiskMBpsReadWrite != nil && to.Int64(disk.DiskMBpsReadWrite) != *s.DiskMBpsReadWrite {
+		disk.DiskMBpsReadWrite = s.DiskMBpsReadWrite
+		changed = true
+	}
+
+	if !changed {
+		// disk is up to date
+		return nil, nil
+	}
+
+	return disk, nil
+}
+
+// standaloneDisk returns the parameters for creating an empty standalone data disk.
+func (s *DataDiskSpec) standaloneDisk() compute.Disk {
+	disk := compute.Disk{
+		Location: to.StringPtr(s.Location),
+		Tags: converters.TagsToMap(infrav1.Build(infrav1.BuildParams{
+			ClusterName: s.ClusterName,
+			Lifecycle:   infrav1.ResourceLifecycleOwned,
+			Name:        to.StringPtr(s.Name),
+			Additional:  s.AdditionalTags,
+		})),
+		DiskProperties: &compute.DiskProperties{
+			CreationData: &compute.CreationData{
+				CreateOption: compute.Empty,
+			},
+			DiskSizeGB:        to.Int32Ptr(s.DiskSizeGB),
+			DiskIOPSReadWrite: s.DiskIOPSReadWrite,
+			DiskMBpsReadWrite: s.DiskMBpsReadWrite,
+			MaxShares:         s.MaxShares,
+		},
+	}
+
+	if s.StorageAccountType != "" {
+		disk.Sku = &compute.DiskSku{
+			Name: compute.DiskStorageAccountTypes(s.StorageAccountType),
+		}
+	}
+
+	if s.DiskEncryptionSetID != "" {
+		disk.Encryption = &compute.Encryption{
+			DiskEncryptionSetID: to.StringPtr(s.DiskEncryptionSetID),
+			Type:                compute.EncryptionTypeEncryptionAtRestWithCustomerKey,
+		}
+	}
+
+	if s.Zone != "" {
+		disk.Zones = &[]string{s.Zone}
+	}
+
+	return disk
+}
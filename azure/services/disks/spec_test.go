@@ -0,0 +1,163 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disks
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/gomega"
+)
+
+func TestDataDiskSpecParameters(t *testing.T) {
+	testcases := []struct {
+		name          string
+		spec          *DataDiskSpec
+		existing      interface{}
+		expect        func(g *WithT, result interface{})
+		expectedError string
+	}{
+		{
+			name:     "returns nil if the disk doesn't exist yet",
+			spec:     &DataDiskSpec{Name: "my-vm_disk1", DiskSizeGB: 128},
+			existing: nil,
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(BeNil())
+			},
+		},
+		{
+			name:     "fails if existing is not a disk",
+			spec:     &DataDiskSpec{Name: "my-vm_disk1", DiskSizeGB: 128},
+			existing: compute.VirtualMachine{},
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(BeNil())
+			},
+			expectedError: "compute.VirtualMachine is not a compute.Disk",
+		},
+		{
+			name:     "returns nil if the disk is already large enough",
+			spec:     &DataDiskSpec{Name: "my-vm_disk1", DiskSizeGB: 128},
+			existing: fakeDisk(compute.PremiumLRS, compute.Attached, 128),
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(BeNil())
+			},
+		},
+		{
+			name:     "grows an attached disk",
+			spec:     &DataDiskSpec{Name: "my-vm_disk1", DiskSizeGB: 256},
+			existing: fakeDisk(compute.PremiumLRS, compute.Attached, 128),
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(Equal(fakeDisk(compute.PremiumLRS, compute.Attached, 256)))
+			},
+		},
+		{
+			name:     "grows an unattached ultra disk",
+			spec:     &DataDiskSpec{Name: "my-vm_disk1", DiskSizeGB: 256},
+			existing: fakeDisk(compute.UltraSSDLRS, compute.Unattached, 128),
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(Equal(fakeDisk(compute.UltraSSDLRS, compute.Unattached, 256)))
+			},
+		},
+		{
+			name:     "fails to grow an attached ultra disk",
+			spec:     &DataDiskSpec{Name: "my-vm_disk1", DiskSizeGB: 256},
+			existing: fakeDisk(compute.UltraSSDLRS, compute.Attached, 128),
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(BeNil())
+			},
+			expectedError: "disk my-vm_disk1 does not support live resize, deallocate the VM to grow it to 256 GB",
+		},
+		{
+			name: "creates a standalone disk if it doesn't exist yet",
+			spec: &DataDiskSpec{
+				Name:                "my-vm_disk1",
+				Location:            "test-location",
+				Zone:                "1",
+				ClusterName:         "my-cluster",
+				DiskSizeGB:          128,
+				StorageAccountType:  string(compute.UltraSSDLRS),
+				DiskEncryptionSetID: "my-des",
+				DiskIOPSReadWrite:   to.Int64Ptr(4000),
+				DiskMBpsReadWrite:   to.Int64Ptr(200),
+				MaxShares:           to.Int32Ptr(2),
+				Standalone:          true,
+			},
+			existing: nil,
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(Equal(compute.Disk{
+					Location: to.StringPtr("test-location"),
+					Tags: map[string]*string{
+						"Name": to.StringPtr("my-vm_disk1"),
+						"sigs.k8s.io_cluster-api-provider-azure_cluster_my-cluster": to.StringPtr("owned"),
+					},
+					Sku:   &compute.DiskSku{Name: compute.UltraSSDLRS},
+					Zones: &[]string{"1"},
+					DiskProperties: &compute.DiskProperties{
+						CreationData:      &compute.CreationData{CreateOption: compute.Empty},
+						DiskSizeGB:        to.Int32Ptr(128),
+						DiskIOPSReadWrite: to.Int64Ptr(4000),
+						DiskMBpsReadWrite: to.Int64Ptr(200),
+						MaxShares:         to.Int32Ptr(2),
+						Encryption: &compute.Encryption{
+							DiskEncryptionSetID: to.StringPtr("my-des"),
+							Type:                compute.EncryptionTypeEncryptionAtRestWithCustomerKey,
+						},
+					},
+				}))
+			},
+		},
+		{
+			name:     "updates the performance of an existing disk",
+			spec:     &DataDiskSpec{Name: "my-vm_disk1", DiskSizeGB: 128, DiskIOPSReadWrite: to.Int64Ptr(8000), DiskMBpsReadWrite: to.Int64Ptr(400)},
+			existing: fakeDisk(compute.UltraSSDLRS, compute.Attached, 128),
+			expect: func(g *WithT, result interface{}) {
+				expected := fakeDisk(compute.UltraSSDLRS, compute.Attached, 128)
+				expected.DiskIOPSReadWrite = to.Int64Ptr(8000)
+				expected.DiskMBpsReadWrite = to.Int64Ptr(400)
+				g.Expect(result).To(Equal(expected))
+			},
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+
+			result, err := tc.spec.Parameters(tc.existing)
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+			tc.expect(g, result)
+		})
+	}
+}
+
+func fakeDisk(sku compute.DiskStorageAccountTypes, state compute.DiskState, sizeGB int32) compute.Disk {
+	return compute.Disk{
+		Name: to.StringPtr("my-vm_disk1"),
+		Sku:  &compute.DiskSku{Name: sku},
+		DiskProperties: &compute.DiskProperties{
+			DiskSizeGB: to.Int32Ptr(sizeGB),
+			DiskState:  state,
+		},
+	}
+}
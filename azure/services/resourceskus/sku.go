@@ -20,8 +20,10 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 // SKU is a thin layer over the Azure resource SKU API to better introspect capabilities.
@@ -39,6 +41,9 @@ const (
 	AvailabilitySets ResourceType = "availabilitySets"
 )
 
+// NvidiaGPU is the name of the extended resource advertised by nodes for their NVIDIA GPUs.
+const NvidiaGPU corev1.ResourceName = "nvidia.com/gpu"
+
 // Supported models an enum of possible boolean values for resource support in the Azure API.
 type Supported string
 
@@ -58,6 +63,8 @@ const (
 	VCPUs = "vCPUs"
 	// MemoryGB identifies the capability for memory Size.
 	MemoryGB = "MemoryGB"
+	// GPUs identifies the capability for the number of GPUs.
+	GPUs = "GPUs"
 	// MinimumVCPUS is the minimum vCPUS allowed.
 	MinimumVCPUS = 2
 	// MinimumMemory is the minimum memory allowed.
@@ -68,8 +75,26 @@ const (
 	MaximumPlatformFaultDomainCount = "MaximumPlatformFaultDomainCount"
 	// UltraSSDAvailable identifies the capability for the support of UltraSSD data disks.
 	UltraSSDAvailable = "UltraSSDAvailable"
+	// PremiumIO identifies the capability for the support of premium storage disks.
+	PremiumIO = "PremiumIO"
+	// MaxDataDiskCount identifies the capability for the maximum number of data disks.
+	MaxDataDiskCount = "MaxDataDiskCount"
+	// CachedDiskBytes identifies the capability for the size of the cache disk in bytes.
+	CachedDiskBytes = "CachedDiskBytes"
+	// MaxResourceVolumeMB identifies the capability for the size of the resource (temp) disk in MiB.
+	MaxResourceVolumeMB = "MaxResourceVolumeMB"
 )
 
+// ephemeralOSDiskPlacementCapabilities maps the ephemeral OS disk placements to the capability
+// exposing the size of the disk they use, and the number of bytes in a unit of that capability.
+var ephemeralOSDiskPlacementCapabilities = map[string]struct {
+	name      string
+	unitBytes int64
+}{
+	"CacheDisk":    {name: CachedDiskBytes, unitBytes: 1},
+	"ResourceDisk": {name: MaxResourceVolumeMB, unitBytes: 1 << 20},
+}
+
 // HasCapability return true for a capability which can be either
 // supported or not. Examples include "EphemeralOSDiskSupported",
 // "UltraSSDAvavailable" "EncryptionAtHostSupported",
@@ -118,6 +143,18 @@ func (s SKU) HasCapabilityWithCapacity(name string, value int64) (bool, error) {
 	return false, nil
 }
 
+// HasEphemeralOSDiskPlacement returns true if the ephemeral OS disk can be placed on the given disk of the VM,
+// that is if that disk exists and is large enough for an OS disk of the given size in GB.
+func (s SKU) HasEphemeralOSDiskPlacement(placement string, osDiskSizeGB int32) (bool, error) {
+	capability, ok := ephemeralOSDiskPlacementCapabilities[placement]
+	if !ok {
+		return false, errors.Errorf("unknown ephemeral OS disk placement %s", placement)
+	}
+
+	osDiskBytes := int64(osDiskSizeGB) << 30
+	return s.HasCapabilityWithCapacity(capability.name, (osDiskBytes+capability.unitBytes-1)/capability.unitBytes)
+}
+
 // GetCapability gets the value assigned to the given capability.
 // Eg. MaximumPlatformFaultDomainCount -> "3" will return "3" for the capability "MaximumPlatformFaultDomainCount".
 func (s SKU) GetCapability(name string) (string, bool) {
@@ -131,6 +168,44 @@ func (s SKU) GetCapability(name string) (string, bool) {
 	return "", false
 }
 
+// ResourceCapacity returns the CPU, memory and GPU capacity of a VM size, as it is reported by the capabilities of the SKU.
+// GPUs are reported as the nvidia.com/gpu resource and only when the VM size has any.
+func (s SKU) ResourceCapacity() (corev1.ResourceList, error) {
+	capacity := corev1.ResourceList{}
+
+	vCPUs, ok := s.GetCapability(VCPUs)
+	if !ok {
+		return nil, errors.Errorf("resource sku is missing the %s capability", VCPUs)
+	}
+	cpu, err := resource.ParseQuantity(vCPUs)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s capability '%s'", VCPUs, vCPUs)
+	}
+	capacity[corev1.ResourceCPU] = cpu
+
+	memoryGB, ok := s.GetCapability(MemoryGB)
+	if !ok {
+		return nil, errors.Errorf("resource sku is missing the %s capability", MemoryGB)
+	}
+	memory, err := resource.ParseQuantity(memoryGB + "Gi")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s capability '%s'", MemoryGB, memoryGB)
+	}
+	capacity[corev1.ResourceMemory] = memory
+
+	if gpus, ok := s.GetCapability(GPUs); ok {
+		gpu, err := resource.ParseQuantity(gpus)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse %s capability '%s'", GPUs, gpus)
+		}
+		if !gpu.IsZero() {
+			capacity[NvidiaGPU] = gpu
+		}
+	}
+
+	return capacity, nil
+}
+
 // HasLocationCapability returns true if the provided resource supports the location capability.
 func (s SKU) HasLocationCapability(capabilityName, location, zone string) bool {
 	if s.LocationInfo == nil {
@@ -165,3 +240,47 @@ func (s SKU) HasLocationCapability(capabilityName, location, zone string) bool {
 	}
 	return false
 }
+
+// IsAvailable returns true if the resource can be deployed in the provided location and zone, taking the
+// restrictions of the subscription into account. An empty zone only checks the availability in the location.
+func (s SKU) IsAvailable(location, zone string) bool {
+	if s.LocationInfo == nil {
+		return false
+	}
+
+	for _, info := range *s.LocationInfo {
+		if info.Location == nil || !strings.EqualFold(*info.Location, location) {
+			continue
+		}
+
+		if s.Restrictions != nil {
+			for _, restriction := range *s.Restrictions {
+				// Can't deploy anything in this subscription in this location.
+				if restriction.Type == compute.Location {
+					return false
+				}
+				if zone == "" || restriction.RestrictionInfo == nil || restriction.RestrictionInfo.Zones == nil {
+					continue
+				}
+				for _, restrictedZone := range *restriction.RestrictionInfo.Zones {
+					if restrictedZone == zone {
+						return false
+					}
+				}
+			}
+		}
+
+		if zone == "" {
+			return true
+		}
+		if info.Zones != nil {
+			for _, availableZone := range *info.Zones {
+				if availableZone == zone {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	return false
+}
@@ -0,0 +1,271 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourceskus
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestSKUIsAvailable(t *testing.T) {
+	testcases := []struct {
+		name     string
+		sku      SKU
+		location string
+		zone     string
+		want     bool
+	}{
+		{
+			name:     "no location info",
+			sku:      SKU{},
+			location: "baz",
+			want:     false,
+		},
+		{
+			name: "available in location",
+			sku: SKU{
+				LocationInfo: &[]compute.ResourceSkuLocationInfo{
+					{Location: to.StringPtr("baz"), Zones: &[]string{"1", "2"}},
+				},
+			},
+			location: "baz",
+			want:     true,
+		},
+		{
+			name: "available in zone",
+			sku: SKU{
+				LocationInfo: &[]compute.ResourceSkuLocationInfo{
+					{Location: to.StringPtr("baz"), Zones: &[]string{"1", "2"}},
+				},
+			},
+			location: "baz",
+			zone:     "2",
+			want:     true,
+		},
+		{
+			name: "not available in zone",
+			sku: SKU{
+				LocationInfo: &[]compute.ResourceSkuLocationInfo{
+					{Location: to.StringPtr("baz"), Zones: &[]string{"1", "2"}},
+				},
+			},
+			location: "baz",
+			zone:     "3",
+			want:     false,
+		},
+		{
+			name: "not available in other location",
+			sku: SKU{
+				LocationInfo: &[]compute.ResourceSkuLocationInfo{
+					{Location: to.StringPtr("baz"), Zones: &[]string{"1", "2"}},
+				},
+			},
+			location: "qux",
+			want:     false,
+		},
+		{
+			name: "restricted in location",
+			sku: SKU{
+				LocationInfo: &[]compute.ResourceSkuLocationInfo{
+					{Location: to.StringPtr("baz"), Zones: &[]string{"1", "2"}},
+				},
+				Restrictions: &[]compute.ResourceSkuRestrictions{
+					{Type: compute.Location},
+				},
+			},
+			location: "baz",
+			want:     false,
+		},
+		{
+			name: "restricted in zone",
+			sku: SKU{
+				LocationInfo: &[]compute.ResourceSkuLocationInfo{
+					{Location: to.StringPtr("baz"), Zones: &[]string{"1", "2"}},
+				},
+				Restrictions: &[]compute.ResourceSkuRestrictions{
+					{
+						Type:            compute.Zone,
+						RestrictionInfo: &compute.ResourceSkuRestrictionInfo{Zones: &[]string{"1"}},
+					},
+				},
+			},
+			location: "baz",
+			zone:     "1",
+			want:     false,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(tc.sku.IsAvailable(tc.location, tc.zone)).To(Equal(tc.want))
+		})
+	}
+}
+
+func TestSKUHasEphemeralOSDiskPlacement(t *testing.T) {
+	sku := SKU{
+		Capabilities: &[]compute.ResourceSkuCapabilities{
+			{Name: to.StringPtr(CachedDiskBytes), Value: to.StringPtr("53687091200")},
+			{Name: to.StringPtr(MaxResourceVolumeMB), Value: to.StringPtr("153600")},
+		},
+	}
+
+	testcases := []struct {
+		name          string
+		placement     string
+		osDiskSizeGB  int32
+		want          bool
+		expectedError string
+	}{
+		{
+			name:         "fits on the cache disk",
+			placement:    "CacheDisk",
+			osDiskSizeGB: 50,
+			want:         true,
+		},
+		{
+			name:         "too large for the cache disk",
+			placement:    "CacheDisk",
+			osDiskSizeGB: 51,
+			want:         false,
+		},
+		{
+			name:         "fits on the resource disk",
+			placement:    "ResourceDisk",
+			osDiskSizeGB: 150,
+			want:         true,
+		},
+		{
+			name:         "too large for the resource disk",
+			placement:    "ResourceDisk",
+			osDiskSizeGB: 151,
+			want:         false,
+		},
+		{
+			name:          "unknown placement",
+			placement:     "Foo",
+			osDiskSizeGB:  30,
+			want:          false,
+			expectedError: "unknown ephemeral OS disk placement Foo",
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			got, err := sku.HasEphemeralOSDiskPlacement(tc.placement, tc.osDiskSizeGB)
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+			g.Expect(got).To(Equal(tc.want))
+		})
+	}
+}
+
+func TestSKUResourceCapacity(t *testing.T) {
+	testcases := []struct {
+		name          string
+		capabilities  []compute.ResourceSkuCapabilities
+		want          corev1.ResourceList
+		expectedError string
+	}{
+		{
+			name: "cpu and memory",
+			capabilities: []compute.ResourceSkuCapabilities{
+				{Name: to.StringPtr(VCPUs), Value: to.StringPtr("4")},
+				{Name: to.StringPtr(MemoryGB), Value: to.StringPtr("16")},
+			},
+			want: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("16Gi"),
+			},
+		},
+		{
+			name: "fractional memory",
+			capabilities: []compute.ResourceSkuCapabilities{
+				{Name: to.StringPtr(VCPUs), Value: to.StringPtr("1")},
+				{Name: to.StringPtr(MemoryGB), Value: to.StringPtr("0.75")},
+			},
+			want: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("768Mi"),
+			},
+		},
+		{
+			name: "gpus",
+			capabilities: []compute.ResourceSkuCapabilities{
+				{Name: to.StringPtr(VCPUs), Value: to.StringPtr("6")},
+				{Name: to.StringPtr(MemoryGB), Value: to.StringPtr("112")},
+				{Name: to.StringPtr(GPUs), Value: to.StringPtr("1")},
+			},
+			want: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("6"),
+				corev1.ResourceMemory: resource.MustParse("112Gi"),
+				NvidiaGPU:             resource.MustParse("1"),
+			},
+		},
+		{
+			name: "no gpus",
+			capabilities: []compute.ResourceSkuCapabilities{
+				{Name: to.StringPtr(VCPUs), Value: to.StringPtr("2")},
+				{Name: to.StringPtr(MemoryGB), Value: to.StringPtr("8")},
+				{Name: to.StringPtr(GPUs), Value: to.StringPtr("0")},
+			},
+			want: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("8Gi"),
+			},
+		},
+		{
+			name: "missing memory",
+			capabilities: []compute.ResourceSkuCapabilities{
+				{Name: to.StringPtr(VCPUs), Value: to.StringPtr("2")},
+			},
+			expectedError: "resource sku is missing the MemoryGB capability",
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			sku := SKU{Capabilities: &tc.capabilities}
+			got, err := sku.ResourceCapacity()
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(tc.expectedError))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(HaveLen(len(tc.want)))
+			for name, quantity := range tc.want {
+				actual, ok := got[name]
+				g.Expect(ok).To(BeTrue(), "missing %s", name)
+				g.Expect(actual.Cmp(quantity)).To(Equal(0), "%s: got %s, want %s", name, actual.String(), quantity.String())
+			}
+		})
+	}
+}
@@ -23,7 +23,7 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
 	"github.com/Azure/go-autorest/autorest"
 	azureautorest "github.com/Azure/go-autorest/autorest/azure"
 	"github.com/Azure/go-autorest/autorest/to"
@@ -39,19 +39,22 @@ import (
 type Client interface {
 	List(context.Context, string) ([]compute.VirtualMachineScaleSet, error)
 	ListInstances(context.Context, string, string) ([]compute.VirtualMachineScaleSetVM, error)
+	ListVirtualMachines(context.Context, string, string) ([]compute.VirtualMachine, error)
+	GetVirtualMachineInstanceView(context.Context, string, string) (compute.VirtualMachineInstanceView, error)
 	Get(context.Context, string, string) (compute.VirtualMachineScaleSet, error)
 	CreateOrUpdateAsync(context.Context, string, string, compute.VirtualMachineScaleSet) (*infrav1.Future, error)
 	UpdateAsync(context.Context, string, string, compute.VirtualMachineScaleSetUpdate) (*infrav1.Future, error)
 	GetResultIfDone(ctx context.Context, future *infrav1.Future) (compute.VirtualMachineScaleSet, error)
-	UpdateInstances(context.Context, string, string, []string) error
+	UpdateInstancesAsync(context.Context, string, string, []string) (*infrav1.Future, error)
 	DeleteAsync(context.Context, string, string) (*infrav1.Future, error)
 }
 
 type (
 	// AzureClient contains the Azure go-sdk Client.
 	AzureClient struct {
-		scalesetvms compute.VirtualMachineScaleSetVMsClient
-		scalesets   compute.VirtualMachineScaleSetsClient
+		scalesetvms     compute.VirtualMachineScaleSetVMsClient
+		scalesets       compute.VirtualMachineScaleSetsClient
+		virtualmachines compute.VirtualMachinesClient
 	}
 
 	genericScaleSetFuture interface {
@@ -67,6 +70,10 @@ type (
 	deleteResultAdapter struct {
 		compute.VirtualMachineScaleSetsDeleteFuture
 	}
+
+	postResultAdapter struct {
+		azureautorest.Future
+	}
 )
 
 var _ Client = &AzureClient{}
@@ -74,8 +81,9 @@ var _ Client = &AzureClient{}
 // NewClient creates a new VMSS client from subscription ID.
 func NewClient(auth azure.Authorizer) *AzureClient {
 	return &AzureClient{
-		scalesetvms: newVirtualMachineScaleSetVMsClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer()),
-		scalesets:   newVirtualMachineScaleSetsClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer()),
+		scalesetvms:     newVirtualMachineScaleSetVMsClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer()),
+		scalesets:       newVirtualMachineScaleSetsClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer()),
+		virtualmachines: newVirtualMachinesClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer()),
 	}
 }
 
@@ -86,6 +94,13 @@ func newVirtualMachineScaleSetVMsClient(subscriptionID string, baseURI string, a
 	return c
 }
 
+// newVirtualMachinesClient creates a new VM client from subscription ID.
+func newVirtualMachinesClient(subscriptionID string, baseURI string, authorizer autorest.Authorizer) compute.VirtualMachinesClient {
+	c := compute.NewVirtualMachinesClientWithBaseURI(baseURI, subscriptionID)
+	azure.SetAutoRestClientDefaults(&c.Client, authorizer)
+	return c
+}
+
 // newVirtualMachineScaleSetsClient creates a new vmss client from subscription ID.
 func newVirtualMachineScaleSetsClient(subscriptionID string, baseURI string, authorizer autorest.Authorizer) compute.VirtualMachineScaleSetsClient {
 	c := compute.NewVirtualMachineScaleSetsClientWithBaseURI(baseURI, subscriptionID)
@@ -93,12 +108,12 @@ func newVirtualMachineScaleSetsClient(subscriptionID string, baseURI string, aut
 	return c
 }
 
-// ListInstances retrieves information about the model views of a virtual machine scale set.
+// ListInstances retrieves information about the model and instance views of a virtual machine scale set.
 func (ac *AzureClient) ListInstances(ctx context.Context, resourceGroupName, vmssName string) ([]compute.VirtualMachineScaleSetVM, error) {
 	ctx, _, done := tele.StartSpanWithLogger(ctx, "scalesets.AzureClient.ListInstances")
 	defer done()
 
-	itr, err := ac.scalesetvms.ListComplete(ctx, resourceGroupName, vmssName, "", "", "")
+	itr, err := ac.scalesetvms.ListComplete(ctx, resourceGroupName, vmssName, "", "", string(compute.InstanceViewTypesInstanceView))
 	if err != nil {
 		return nil, err
 	}
@@ -114,6 +129,36 @@ func (ac *AzureClient) ListInstances(ctx context.Context, resourceGroupName, vms
 	return instances, nil
 }
 
+// ListVirtualMachines retrieves the virtual machines of a virtual machine scale set with the Flexible orchestration mode.
+func (ac *AzureClient) ListVirtualMachines(ctx context.Context, resourceGroupName, vmssID string) ([]compute.VirtualMachine, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "scalesets.AzureClient.ListVirtualMachines")
+	defer done()
+
+	filter := fmt.Sprintf("'virtualMachineScaleSet/id' eq '%s'", vmssID)
+	itr, err := ac.virtualmachines.ListComplete(ctx, resourceGroupName, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var vms []compute.VirtualMachine
+	for ; itr.NotDone(); err = itr.NextWithContext(ctx) {
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate vm scale set virtual machines [%w]", err)
+		}
+		vms = append(vms, itr.Value())
+	}
+	return vms, nil
+}
+
+// GetVirtualMachineInstanceView retrieves the run-time status of a virtual machine of a virtual machine scale set with the
+// Flexible orchestration mode, including its power state.
+func (ac *AzureClient) GetVirtualMachineInstanceView(ctx context.Context, resourceGroupName, vmName string) (compute.VirtualMachineInstanceView, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "scalesets.AzureClient.GetVirtualMachineInstanceView")
+	defer done()
+
+	return ac.virtualmachines.InstanceView(ctx, resourceGroupName, vmName)
+}
+
 // List returns all scale sets in a resource group.
 func (ac *AzureClient) List(ctx context.Context, resourceGroupName string) ([]compute.VirtualMachineScaleSet, error) {
 	ctx, _, done := tele.StartSpanWithLogger(ctx, "scalesets.AzureClient.List")
@@ -241,6 +286,15 @@ func (ac *AzureClient) GetResultIfDone(ctx context.Context, future *infrav1.Futu
 		genericFuture = &deleteResultAdapter{
 			VirtualMachineScaleSetsDeleteFuture: future,
 		}
+	case infrav1.PostFuture:
+		var future azureautorest.Future
+		if err := future.UnmarshalJSON(futureData); err != nil {
+			return compute.VirtualMachineScaleSet{}, errors.Wrap(err, "failed to unmarshal future data")
+		}
+
+		genericFuture = &postResultAdapter{
+			Future: future,
+		}
 	default:
 		return compute.VirtualMachineScaleSet{}, errors.Errorf("unknown future type %q", future.Type)
 	}
@@ -262,9 +316,11 @@ func (ac *AzureClient) GetResultIfDone(ctx context.Context, future *infrav1.Futu
 	return vmss, nil
 }
 
-// UpdateInstances update instances of a VM scale set.
-func (ac *AzureClient) UpdateInstances(ctx context.Context, resourceGroupName, vmssName string, instanceIDs []string) error {
-	ctx, _, done := tele.StartSpanWithLogger(ctx, "scalesets.AzureClient.UpdateInstances")
+// UpdateInstancesAsync applies the latest model of a VM scale set to its instances asynchronously. UpdateInstancesAsync
+// sends a POST request to Azure and if accepted without error, the func will return a Future which can be used to track
+// the ongoing progress of the operation.
+func (ac *AzureClient) UpdateInstancesAsync(ctx context.Context, resourceGroupName, vmssName string, instanceIDs []string) (*infrav1.Future, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "scalesets.AzureClient.UpdateInstancesAsync")
 	defer done()
 
 	params := compute.VirtualMachineScaleSetVMInstanceRequiredIDs{
@@ -272,14 +328,10 @@ func (ac *AzureClient) UpdateInstances(ctx context.Context, resourceGroupName, v
 	}
 	future, err := ac.scalesets.UpdateInstances(ctx, resourceGroupName, vmssName, params)
 	if err != nil {
-		return err
-	}
-	err = future.WaitForCompletionRef(ctx, ac.scalesets.Client)
-	if err != nil {
-		return err
+		return nil, errors.Wrapf(err, "failed updating the instances of vmss named %q", vmssName)
 	}
-	_, err = future.Result(ac.scalesets)
-	return err
+
+	return converters.SDKToFuture(future.FutureAPI, infrav1.PostFuture, updateInstancesServiceName, vmssName, resourceGroupName)
 }
 
 // DeleteAsync is the operation to delete a virtual machine scale set asynchronously. DeleteAsync sends a DELETE
@@ -320,6 +372,13 @@ func (da *deleteResultAdapter) Result(client compute.VirtualMachineScaleSetsClie
 	return compute.VirtualMachineScaleSet{}, err
 }
 
+// Result wraps the result of a POST action so that we can treat it generically. Actions don't return a result, the only
+// thing we care about is if the action was successful. If it wasn't, DoneWithContext will already have returned an
+// error.
+func (pa *postResultAdapter) Result(client compute.VirtualMachineScaleSetsClient) (compute.VirtualMachineScaleSet, error) {
+	return compute.VirtualMachineScaleSet{}, nil
+}
+
 // Result returns the Result so that we can treat it generically.
 func (g *genericScaleSetFutureImpl) Result(client compute.VirtualMachineScaleSetsClient) (compute.VirtualMachineScaleSet, error) {
 	return g.result(client)
@@ -24,7 +24,7 @@ import (
 	context "context"
 	reflect "reflect"
 
-	compute "github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	compute "github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
 	autorest "github.com/Azure/go-autorest/autorest"
 	gomock "github.com/golang/mock/gomock"
 	v1beta1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
@@ -113,6 +113,21 @@ func (mr *MockClientMockRecorder) GetResultIfDone(ctx, future interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetResultIfDone", reflect.TypeOf((*MockClient)(nil).GetResultIfDone), ctx, future)
 }
 
+// GetVirtualMachineInstanceView mocks base method.
+func (m *MockClient) GetVirtualMachineInstanceView(arg0 context.Context, arg1, arg2 string) (compute.VirtualMachineInstanceView, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVirtualMachineInstanceView", arg0, arg1, arg2)
+	ret0, _ := ret[0].(compute.VirtualMachineInstanceView)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVirtualMachineInstanceView indicates an expected call of GetVirtualMachineInstanceView.
+func (mr *MockClientMockRecorder) GetVirtualMachineInstanceView(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVirtualMachineInstanceView", reflect.TypeOf((*MockClient)(nil).GetVirtualMachineInstanceView), arg0, arg1, arg2)
+}
+
 // List mocks base method.
 func (m *MockClient) List(arg0 context.Context, arg1 string) ([]compute.VirtualMachineScaleSet, error) {
 	m.ctrl.T.Helper()
@@ -143,6 +158,21 @@ func (mr *MockClientMockRecorder) ListInstances(arg0, arg1, arg2 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInstances", reflect.TypeOf((*MockClient)(nil).ListInstances), arg0, arg1, arg2)
 }
 
+// ListVirtualMachines mocks base method.
+func (m *MockClient) ListVirtualMachines(arg0 context.Context, arg1, arg2 string) ([]compute.VirtualMachine, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListVirtualMachines", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]compute.VirtualMachine)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListVirtualMachines indicates an expected call of ListVirtualMachines.
+func (mr *MockClientMockRecorder) ListVirtualMachines(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListVirtualMachines", reflect.TypeOf((*MockClient)(nil).ListVirtualMachines), arg0, arg1, arg2)
+}
+
 // UpdateAsync mocks base method.
 func (m *MockClient) UpdateAsync(arg0 context.Context, arg1, arg2 string, arg3 compute.VirtualMachineScaleSetUpdate) (*v1beta1.Future, error) {
 	m.ctrl.T.Helper()
@@ -158,18 +188,19 @@ func (mr *MockClientMockRecorder) UpdateAsync(arg0, arg1, arg2, arg3 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAsync", reflect.TypeOf((*MockClient)(nil).UpdateAsync), arg0, arg1, arg2, arg3)
 }
 
-// UpdateInstances mocks base method.
-func (m *MockClient) UpdateInstances(arg0 context.Context, arg1, arg2 string, arg3 []string) error {
+// UpdateInstancesAsync mocks base method.
+func (m *MockClient) UpdateInstancesAsync(arg0 context.Context, arg1, arg2 string, arg3 []string) (*v1beta1.Future, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateInstances", arg0, arg1, arg2, arg3)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "UpdateInstancesAsync", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*v1beta1.Future)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// UpdateInstances indicates an expected call of UpdateInstances.
-func (mr *MockClientMockRecorder) UpdateInstances(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+// UpdateInstancesAsync indicates an expected call of UpdateInstancesAsync.
+func (mr *MockClientMockRecorder) UpdateInstancesAsync(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateInstances", reflect.TypeOf((*MockClient)(nil).UpdateInstances), arg0, arg1, arg2, arg3)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateInstancesAsync", reflect.TypeOf((*MockClient)(nil).UpdateInstancesAsync), arg0, arg1, arg2, arg3)
 }
 
 // MockgenericScaleSetFuture is a mock of genericScaleSetFuture interface.
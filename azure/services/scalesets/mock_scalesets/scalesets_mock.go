@@ -221,6 +221,21 @@ func (mr *MockScaleSetScopeMockRecorder) GetBootstrapData(arg0 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBootstrapData", reflect.TypeOf((*MockScaleSetScope)(nil).GetBootstrapData), arg0)
 }
 
+// GetBootstrapDataFormat mocks base method.
+func (m *MockScaleSetScope) GetBootstrapDataFormat(arg0 context.Context) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBootstrapDataFormat", arg0)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBootstrapDataFormat indicates an expected call of GetBootstrapDataFormat.
+func (mr *MockScaleSetScopeMockRecorder) GetBootstrapDataFormat(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBootstrapDataFormat", reflect.TypeOf((*MockScaleSetScope)(nil).GetBootstrapDataFormat), arg0)
+}
+
 // GetLongRunningOperationState mocks base method.
 func (m *MockScaleSetScope) GetLongRunningOperationState(arg0, arg1 string) *v1beta1.Future {
 	m.ctrl.T.Helper()
@@ -293,6 +308,20 @@ func (mr *MockScaleSetScopeMockRecorder) MaxSurge() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaxSurge", reflect.TypeOf((*MockScaleSetScope)(nil).MaxSurge))
 }
 
+// NewScaleSetSpec mocks base method.
+func (m *MockScaleSetScope) NewScaleSetSpec() *azure.ScaleSetSpec {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewScaleSetSpec")
+	ret0, _ := ret[0].(*azure.ScaleSetSpec)
+	return ret0
+}
+
+// NewScaleSetSpec indicates an expected call of NewScaleSetSpec.
+func (mr *MockScaleSetScopeMockRecorder) NewScaleSetSpec() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewScaleSetSpec", reflect.TypeOf((*MockScaleSetScope)(nil).NewScaleSetSpec))
+}
+
 // ResourceGroup mocks base method.
 func (m *MockScaleSetScope) ResourceGroup() string {
 	m.ctrl.T.Helper()
@@ -307,6 +336,20 @@ func (mr *MockScaleSetScopeMockRecorder) ResourceGroup() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResourceGroup", reflect.TypeOf((*MockScaleSetScope)(nil).ResourceGroup))
 }
 
+// RetiredScaleSetName mocks base method.
+func (m *MockScaleSetScope) RetiredScaleSetName() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetiredScaleSetName")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// RetiredScaleSetName indicates an expected call of RetiredScaleSetName.
+func (mr *MockScaleSetScopeMockRecorder) RetiredScaleSetName() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetiredScaleSetName", reflect.TypeOf((*MockScaleSetScope)(nil).RetiredScaleSetName))
+}
+
 // SaveVMImageToStatus mocks base method.
 func (m *MockScaleSetScope) SaveVMImageToStatus(arg0 *v1beta1.Image) {
 	m.ctrl.T.Helper()
@@ -345,6 +388,18 @@ func (mr *MockScaleSetScopeMockRecorder) SetAnnotation(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAnnotation", reflect.TypeOf((*MockScaleSetScope)(nil).SetAnnotation), arg0, arg1)
 }
 
+// SetDeploymentVMSSState mocks base method.
+func (m *MockScaleSetScope) SetDeploymentVMSSState(arg0 string, arg1 *azure.VMSS) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetDeploymentVMSSState", arg0, arg1)
+}
+
+// SetDeploymentVMSSState indicates an expected call of SetDeploymentVMSSState.
+func (mr *MockScaleSetScopeMockRecorder) SetDeploymentVMSSState(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDeploymentVMSSState", reflect.TypeOf((*MockScaleSetScope)(nil).SetDeploymentVMSSState), arg0, arg1)
+}
+
 // SetLongRunningOperationState mocks base method.
 func (m *MockScaleSetScope) SetLongRunningOperationState(arg0 *v1beta1.Future) {
 	m.ctrl.T.Helper()
@@ -369,6 +424,18 @@ func (mr *MockScaleSetScopeMockRecorder) SetProviderID(arg0 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetProviderID", reflect.TypeOf((*MockScaleSetScope)(nil).SetProviderID), arg0)
 }
 
+// SetRetiredScaleSetDeleted mocks base method.
+func (m *MockScaleSetScope) SetRetiredScaleSetDeleted() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetRetiredScaleSetDeleted")
+}
+
+// SetRetiredScaleSetDeleted indicates an expected call of SetRetiredScaleSetDeleted.
+func (mr *MockScaleSetScopeMockRecorder) SetRetiredScaleSetDeleted() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRetiredScaleSetDeleted", reflect.TypeOf((*MockScaleSetScope)(nil).SetRetiredScaleSetDeleted))
+}
+
 // SetVMSSState mocks base method.
 func (m *MockScaleSetScope) SetVMSSState(arg0 *azure.VMSS) {
 	m.ctrl.T.Helper()
@@ -381,6 +448,20 @@ func (mr *MockScaleSetScopeMockRecorder) SetVMSSState(arg0 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetVMSSState", reflect.TypeOf((*MockScaleSetScope)(nil).SetVMSSState), arg0)
 }
 
+// StartScaleSetDeployment mocks base method.
+func (m *MockScaleSetScope) StartScaleSetDeployment() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartScaleSetDeployment")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// StartScaleSetDeployment indicates an expected call of StartScaleSetDeployment.
+func (mr *MockScaleSetScopeMockRecorder) StartScaleSetDeployment() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartScaleSetDeployment", reflect.TypeOf((*MockScaleSetScope)(nil).StartScaleSetDeployment))
+}
+
 // SubscriptionID mocks base method.
 func (m *MockScaleSetScope) SubscriptionID() string {
 	m.ctrl.T.Helper()
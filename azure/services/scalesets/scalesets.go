@@ -22,19 +22,23 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/pkg/errors"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/converters"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/capacityreservationgroups"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/resourceskus"
 	"sigs.k8s.io/cluster-api-provider-azure/util/generators"
 	"sigs.k8s.io/cluster-api-provider-azure/util/slice"
 	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
 )
 
-const serviceName = "scalesets"
+const (
+	serviceName                = "scalesets"
+	updateInstancesServiceName = "scalesets-updateinstances"
+)
 
 type (
 	// ScaleSetScope defines the scope interface for a scale sets service.
@@ -42,6 +46,7 @@ type (
 		azure.ClusterDescriber
 		azure.AsyncStatusUpdater
 		GetBootstrapData(context.Context) (string, error)
+		GetBootstrapDataFormat(context.Context) (string, error)
 		GetVMImage(context.Context) (*infrav1.Image, error)
 		SaveVMImageToStatus(*infrav1.Image)
 		MaxSurge() (int, error)
@@ -50,22 +55,29 @@ type (
 		SetAnnotation(string, string)
 		SetProviderID(string)
 		SetVMSSState(*azure.VMSS)
+		StartScaleSetDeployment() bool
+		NewScaleSetSpec() *azure.ScaleSetSpec
+		RetiredScaleSetName() string
+		SetRetiredScaleSetDeleted()
+		SetDeploymentVMSSState(string, *azure.VMSS)
 	}
 
 	// Service provides operations on Azure resources.
 	Service struct {
 		Scope ScaleSetScope
 		Client
-		resourceSKUCache *resourceskus.Cache
+		resourceSKUCache           *resourceskus.Cache
+		capacityReservationsClient capacityreservationgroups.Client
 	}
 )
 
 // New creates a new service.
 func New(scope ScaleSetScope, skuCache *resourceskus.Cache) *Service {
 	return &Service{
-		Client:           NewClient(scope),
-		Scope:            scope,
-		resourceSKUCache: skuCache,
+		Client:                     NewClient(scope),
+		Scope:                      scope,
+		resourceSKUCache:           skuCache,
+		capacityReservationsClient: capacityreservationgroups.NewClient(scope),
 	}
 }
 
@@ -121,7 +133,7 @@ func (s *Service) Reconcile(ctx context.Context) (retErr error) {
 		return errors.Wrapf(err, "failed to get VMSS %s", scaleSetSpec.Name)
 	case err != nil && azure.ResourceNotFound(err):
 		// HTTP(404) resource was not found, so we need to create it with a PUT
-		future, err = s.createVMSS(ctx)
+		future, err = s.createVMSS(ctx, scaleSetSpec)
 		if err != nil {
 			return errors.Wrap(err, "failed to start creating VMSS")
 		}
@@ -129,7 +141,7 @@ func (s *Service) Reconcile(ctx context.Context) (retErr error) {
 		// HTTP(200)
 		// VMSS already exists and may have changes; update it with a PATCH
 		// we do this to avoid overwriting fields in networkProfile modified by cloud-provider
-		future, err = s.patchVMSSIfNeeded(ctx, fetchedVMSS)
+		future, err = s.patchVMSSIfNeeded(ctx, scaleSetSpec, fetchedVMSS)
 		if err != nil {
 			return errors.Wrap(err, "failed to start updating VMSS")
 		}
@@ -146,6 +158,147 @@ func (s *Service) Reconcile(ctx context.Context) (retErr error) {
 
 	// if we get to here, we have completed any long running VMSS operations (creates / updates)
 	s.Scope.DeleteLongRunningOperationState(s.Scope.ScaleSetSpec().Name, serviceName)
+	if err := s.reconcileDeployment(ctx); err != nil {
+		return err
+	}
+
+	return s.updateInstancesInPlace(ctx, scaleSetSpec, fetchedVMSS)
+}
+
+// updateInstancesInPlace applies the latest model of a scale set with the Manual upgrade mode to the instances which
+// only miss the changes applied in place, like extensions or tags, so that they don't need to be replaced. The instances
+// of Flexible scale sets are standard virtual machines which don't follow the model of the scale set.
+func (s *Service) updateInstancesInPlace(ctx context.Context, spec azure.ScaleSetSpec, vmss *azure.VMSS) error {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "scalesets.Service.updateInstancesInPlace")
+	defer done()
+
+	future := s.Scope.GetLongRunningOperationState(spec.Name, updateInstancesServiceName)
+	if future == nil {
+		if spec.OrchestrationMode == infrav1.FlexibleOrchestrationMode || vmss == nil ||
+			(vmss.UpgradePolicy != nil && vmss.UpgradePolicy.Mode != string(compute.UpgradeModeManual)) {
+			return nil
+		}
+
+		var instanceIDs []string
+		for _, instance := range vmss.Instances {
+			// instances protected from scale set actions can't be updated, and evicted instances are updated once started
+			if instance.State != infrav1.Succeeded || instance.Evicted || instance.ProtectFromScaleSetActions {
+				continue
+			}
+			if vmss.NeedsInPlaceUpdate(instance) {
+				instanceIDs = append(instanceIDs, instance.InstanceID)
+			}
+		}
+		if len(instanceIDs) == 0 {
+			return nil
+		}
+
+		log.V(2).Info("updating instances in place", "scale set", spec.Name, "instances", instanceIDs)
+		var err error
+		future, err = s.Client.UpdateInstancesAsync(ctx, s.Scope.ResourceGroup(), spec.Name, instanceIDs)
+		if err != nil {
+			if azure.ResourceConflict(err) {
+				return azure.WithTransientError(err, 30*time.Second)
+			}
+			return errors.Wrapf(err, "failed to start updating the instances of VMSS %s", spec.Name)
+		}
+		s.Scope.SetLongRunningOperationState(future)
+	}
+
+	if _, err := s.GetResultIfDone(ctx, future); err != nil {
+		if azure.IsOperationNotDoneError(err) {
+			return err
+		}
+		s.Scope.DeleteLongRunningOperationState(spec.Name, updateInstancesServiceName)
+		return errors.Wrapf(err, "failed to update the instances of VMSS %s", spec.Name)
+	}
+
+	// the instances are fetched again on the next reconciliation to see whether the model was applied
+	s.Scope.DeleteLongRunningOperationState(spec.Name, updateInstancesServiceName)
+	return nil
+}
+
+// reconcileDeployment creates or updates the new scale set of a blue/green deployment, and deletes the scale set
+// retired by a blue/green deployment once it has no instances left.
+func (s *Service) reconcileDeployment(ctx context.Context) error {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "scalesets.Service.reconcileDeployment")
+	defer done()
+
+	if spec := s.Scope.NewScaleSetSpec(); spec != nil {
+		var (
+			future      = s.Scope.GetLongRunningOperationState(spec.Name, serviceName)
+			fetchedVMSS *azure.VMSS
+			err         error
+		)
+
+		if future == nil {
+			fetchedVMSS, err = s.getVirtualMachineScaleSet(ctx, spec.Name)
+		} else {
+			fetchedVMSS, err = s.getVirtualMachineScaleSetIfDone(ctx, future)
+		}
+
+		switch {
+		case err != nil && !azure.ResourceNotFound(err):
+			return errors.Wrapf(err, "failed to get VMSS %s", spec.Name)
+		case err != nil && azure.ResourceNotFound(err):
+			future, err = s.createVMSS(ctx, *spec)
+			if err != nil {
+				return errors.Wrap(err, "failed to start creating new VMSS")
+			}
+		case err == nil:
+			s.Scope.SetDeploymentVMSSState(spec.Name, fetchedVMSS)
+			future, err = s.patchVMSSIfNeeded(ctx, *spec, fetchedVMSS)
+			if err != nil {
+				return errors.Wrap(err, "failed to start updating new VMSS")
+			}
+		}
+
+		if future != nil {
+			fetchedVMSS, err = s.getVirtualMachineScaleSetIfDone(ctx, future)
+			if err != nil {
+				return errors.Wrapf(err, "failed to get VMSS %s after create or update", spec.Name)
+			}
+			s.Scope.SetDeploymentVMSSState(spec.Name, fetchedVMSS)
+		}
+
+		s.Scope.DeleteLongRunningOperationState(spec.Name, serviceName)
+	}
+
+	if name := s.Scope.RetiredScaleSetName(); name != "" {
+		return s.deleteRetiredVMSS(ctx, name)
+	}
+
+	return nil
+}
+
+// deleteRetiredVMSS deletes the scale set retired by a blue/green deployment once the instances have been drained and
+// deleted along with their AzureMachinePoolMachines.
+func (s *Service) deleteRetiredVMSS(ctx context.Context, vmssName string) error {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "scalesets.Service.deleteRetiredVMSS")
+	defer done()
+
+	if s.Scope.GetLongRunningOperationState(vmssName, serviceName) == nil {
+		fetchedVMSS, err := s.getVirtualMachineScaleSet(ctx, vmssName)
+		switch {
+		case err != nil && azure.ResourceNotFound(err):
+			s.Scope.SetRetiredScaleSetDeleted()
+			return nil
+		case err != nil:
+			return errors.Wrapf(err, "failed to get VMSS %s", vmssName)
+		}
+
+		s.Scope.SetDeploymentVMSSState(vmssName, fetchedVMSS)
+		if len(fetchedVMSS.Instances) > 0 {
+			log.V(4).Info("waiting for the instances of the retired VMSS to be deleted", "scale set", vmssName, "instances", len(fetchedVMSS.Instances))
+			return nil
+		}
+	}
+
+	if err := s.deleteVMSS(ctx, vmssName); err != nil {
+		return err
+	}
+
+	s.Scope.SetRetiredScaleSetDeleted()
 	return nil
 }
 
@@ -155,8 +308,6 @@ func (s *Service) Delete(ctx context.Context) error {
 	ctx, log, done := tele.StartSpanWithLogger(ctx, "scalesets.Service.Delete")
 	defer done()
 
-	var err error
-
 	vmssSpec := s.Scope.ScaleSetSpec()
 
 	defer func() {
@@ -171,8 +322,28 @@ func (s *Service) Delete(ctx context.Context) error {
 		}
 	}()
 
+	// delete the scale sets of an in-progress blue/green deployment along with the active one
+	if spec := s.Scope.NewScaleSetSpec(); spec != nil {
+		if err := s.deleteVMSS(ctx, spec.Name); err != nil {
+			return err
+		}
+	}
+	if name := s.Scope.RetiredScaleSetName(); name != "" {
+		if err := s.deleteVMSS(ctx, name); err != nil {
+			return err
+		}
+	}
+
+	return s.deleteVMSS(ctx, vmssSpec.Name)
+}
+
+// deleteVMSS deletes a scale set asynchronously.
+func (s *Service) deleteVMSS(ctx context.Context, vmssName string) error {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "scalesets.Service.deleteVMSS")
+	defer done()
+
 	// check if there is an ongoing long running operation
-	future := s.Scope.GetLongRunningOperationState(vmssSpec.Name, serviceName)
+	future := s.Scope.GetLongRunningOperationState(vmssName, serviceName)
 	if future != nil {
 		// if the operation is not complete this will return an error
 		_, err := s.GetResultIfDone(ctx, future)
@@ -181,19 +352,19 @@ func (s *Service) Delete(ctx context.Context) error {
 		}
 
 		// ScaleSet has been deleted
-		s.Scope.DeleteLongRunningOperationState(vmssSpec.Name, serviceName)
+		s.Scope.DeleteLongRunningOperationState(vmssName, serviceName)
 		return nil
 	}
 
 	// no long running delete operation is active, so delete the ScaleSet
-	log.V(2).Info("deleting VMSS", "scale set", vmssSpec.Name)
-	future, err = s.Client.DeleteAsync(ctx, s.Scope.ResourceGroup(), vmssSpec.Name)
+	log.V(2).Info("deleting VMSS", "scale set", vmssName)
+	future, err := s.Client.DeleteAsync(ctx, s.Scope.ResourceGroup(), vmssName)
 	if err != nil {
 		if azure.ResourceNotFound(err) {
 			// already deleted
 			return nil
 		}
-		return errors.Wrapf(err, "failed to delete VMSS %s in resource group %s", vmssSpec.Name, s.Scope.ResourceGroup())
+		return errors.Wrapf(err, "failed to delete VMSS %s in resource group %s", vmssName, s.Scope.ResourceGroup())
 	}
 
 	s.Scope.SetLongRunningOperationState(future)
@@ -205,16 +376,14 @@ func (s *Service) Delete(ctx context.Context) error {
 	}
 
 	// future is either nil, or the result of the future is complete
-	s.Scope.DeleteLongRunningOperationState(vmssSpec.Name, serviceName)
+	s.Scope.DeleteLongRunningOperationState(vmssName, serviceName)
 	return nil
 }
 
-func (s *Service) createVMSS(ctx context.Context) (*infrav1.Future, error) {
+func (s *Service) createVMSS(ctx context.Context, spec azure.ScaleSetSpec) (*infrav1.Future, error) {
 	ctx, log, done := tele.StartSpanWithLogger(ctx, "scalesets.Service.createVMSS")
 	defer done()
 
-	spec := s.Scope.ScaleSetSpec()
-
 	vmss, err := s.buildVMSSFromSpec(ctx, spec)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed building VMSS from spec")
@@ -230,12 +399,10 @@ func (s *Service) createVMSS(ctx context.Context) (*infrav1.Future, error) {
 	return future, err
 }
 
-func (s *Service) patchVMSSIfNeeded(ctx context.Context, infraVMSS *azure.VMSS) (*infrav1.Future, error) {
+func (s *Service) patchVMSSIfNeeded(ctx context.Context, spec azure.ScaleSetSpec, infraVMSS *azure.VMSS) (*infrav1.Future, error) {
 	ctx, log, done := tele.StartSpanWithLogger(ctx, "scalesets.Service.patchVMSSIfNeeded")
 	defer done()
 
-	spec := s.Scope.ScaleSetSpec()
-
 	vmss, err := s.buildVMSSFromSpec(ctx, spec)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to generate scale set update parameters for %s", spec.Name)
@@ -251,7 +418,34 @@ func (s *Service) patchVMSSIfNeeded(ctx context.Context, infraVMSS *azure.VMSS)
 		return nil, errors.Wrap(err, "failed to calculate maxSurge")
 	}
 
+	if infraVMSS.AutomaticRepairs != nil && spec.AutomaticRepairs == nil {
+		// automatic repairs need to be disabled explicitly as an absent policy leaves it unchanged
+		patch.AutomaticRepairsPolicy = &compute.AutomaticRepairsPolicy{
+			Enabled: to.BoolPtr(false),
+		}
+	}
+
+	if infraVMSS.UpgradePolicy != nil && infraVMSS.UpgradePolicy.AutomaticOSUpgrade && patch.UpgradePolicy != nil &&
+		(spec.UpgradePolicy == nil || !spec.UpgradePolicy.AutomaticOSUpgrade) {
+		// automatic OS upgrades need to be disabled explicitly as an absent policy leaves them unchanged
+		patch.UpgradePolicy.AutomaticOSUpgradePolicy = &compute.AutomaticOSUpgradePolicy{
+			EnableAutomaticOSUpgrade: to.BoolPtr(false),
+		}
+	}
+
+	// Changes to the tags, the identity, the extensions or the policies are applied without surging or deploying a new
+	// scale set, as they don't require replacing the instances.
+	hasInPlaceChanges := hasInPlaceDifferences(infraVMSS, vmss)
+
 	hasModelChanges := hasModelModifyingDifferences(infraVMSS, vmss)
+	if hasModelChanges && s.Scope.StartScaleSetDeployment() {
+		// the model is deployed with a new scale set instead, so only the capacity of this one is updated
+		log.V(4).Info("model changes are deployed with a new scale set", "scale set", spec.Name)
+		patch = compute.VirtualMachineScaleSetUpdate{Sku: patch.Sku}
+		hasModelChanges = false
+		hasInPlaceChanges = false
+	}
+
 	if maxSurge > 0 && (hasModelChanges || !infraVMSS.HasEnoughLatestModelOrNotMixedModel()) {
 		// surge capacity with the intention of lowering during instance reconciliation
 		surge := spec.Capacity + int64(maxSurge)
@@ -261,7 +455,7 @@ func (s *Service) patchVMSSIfNeeded(ctx context.Context, infraVMSS *azure.VMSS)
 
 	// If there are no model changes and no increase in the replica count, do not update the VMSS.
 	// Decreases in replica count is handled by deleting AzureMachinePoolMachine instances in the MachinePoolScope
-	if *patch.Sku.Capacity <= infraVMSS.Capacity && !hasModelChanges {
+	if *patch.Sku.Capacity <= infraVMSS.Capacity && !hasModelChanges && !hasInPlaceChanges {
 		log.V(4).Info("nothing to update on vmss", "scale set", spec.Name, "newReplicas", *patch.Sku.Capacity, "oldReplicas", infraVMSS.Capacity, "hasChanges", hasModelChanges)
 		return nil, nil
 	}
@@ -285,6 +479,11 @@ func hasModelModifyingDifferences(infraVMSS *azure.VMSS, vmss compute.VirtualMac
 	return infraVMSS.HasModelChanges(*other)
 }
 
+func hasInPlaceDifferences(infraVMSS *azure.VMSS, vmss compute.VirtualMachineScaleSet) bool {
+	other := converters.SDKToVMSS(vmss, []compute.VirtualMachineScaleSetVM{})
+	return infraVMSS.HasInPlaceChanges(*other)
+}
+
 func (s *Service) validateSpec(ctx context.Context) error {
 	ctx, _, done := tele.StartSpanWithLogger(ctx, "scalesets.Service.validateSpec")
 	defer done()
@@ -321,6 +520,17 @@ func (s *Service) validateSpec(ctx context.Context) error {
 		return azure.WithTerminalError(fmt.Errorf("vm size %s does not support ephemeral os. select a different vm size or disable ephemeral os", spec.Size))
 	}
 
+	if spec.OSDisk.DiffDiskSettings != nil && spec.OSDisk.DiffDiskSettings.Placement != nil {
+		placement := string(*spec.OSDisk.DiffDiskSettings.Placement)
+		placementCapability, err := sku.HasEphemeralOSDiskPlacement(placement, to.Int32(spec.OSDisk.DiskSizeGB))
+		if err != nil {
+			return azure.WithTerminalError(errors.Wrap(err, "failed to validate the ephemeral os disk placement"))
+		}
+		if !placementCapability {
+			return azure.WithTerminalError(fmt.Errorf("vm size %s does not have a %s large enough for the ephemeral os disk. select a different vm size, placement or os disk size", spec.Size, placement))
+		}
+	}
+
 	if spec.SecurityProfile != nil && !sku.HasCapability(resourceskus.EncryptionAtHost) {
 		return azure.WithTerminalError(errors.Errorf("encryption at host is not supported for VM type %s", spec.Size))
 	}
@@ -352,6 +562,27 @@ func (s *Service) validateSpec(ctx context.Context) error {
 		}
 	}
 
+	// Checking if the capacity reservation group reserves capacity for the VM type in every selected availability zone
+	if spec.CapacityReservationGroupID != "" {
+		reservations, err := s.capacityReservationsClient.ListReservations(ctx, spec.CapacityReservationGroupID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to list capacity reservations of group %s", spec.CapacityReservationGroupID)
+		}
+
+		zones := spec.FailureDomains
+		if len(zones) == 0 {
+			zones = []string{""}
+		}
+		for _, zone := range zones {
+			if err := capacityreservationgroups.ValidateReservation(reservations, spec.CapacityReservationGroupID, spec.Size, zone); err != nil {
+				// The mismatch can only be found once the reservations are known, so it is reported on the scale set
+				// condition rather than rejected by the webhook.
+				s.Scope.UpdatePutStatus(infrav1.ScaleSetRunningCondition, serviceName, err)
+				return err
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -371,6 +602,9 @@ func (s *Service) buildVMSSFromSpec(ctx context.Context, vmssSpec azure.ScaleSet
 	}
 
 	extensions := s.generateExtensions()
+	if vmssSpec.ApplicationHealth != nil {
+		extensions = append(extensions, getApplicationHealthExtension(vmssSpec))
+	}
 
 	storageProfile, err := s.generateStorageProfile(ctx, vmssSpec, sku)
 	if err != nil {
@@ -398,7 +632,12 @@ func (s *Service) buildVMSSFromSpec(ctx context.Context, vmssSpec azure.ScaleSet
 		}
 	}
 
-	osProfile, err := s.generateOSProfile(ctx, vmssSpec)
+	bootstrapData, err := s.getBootstrapData(ctx, vmssSpec)
+	if err != nil {
+		return compute.VirtualMachineScaleSet{}, err
+	}
+
+	osProfile, err := s.generateOSProfile(vmssSpec, bootstrapData)
 	if err != nil {
 		return compute.VirtualMachineScaleSet{}, err
 	}
@@ -419,14 +658,11 @@ func (s *Service) buildVMSSFromSpec(ctx context.Context, vmssSpec azure.ScaleSet
 			},
 			Overprovision: to.BoolPtr(false),
 			VirtualMachineProfile: &compute.VirtualMachineScaleSetVMProfile{
-				OsProfile:       osProfile,
-				StorageProfile:  storageProfile,
-				SecurityProfile: securityProfile,
-				DiagnosticsProfile: &compute.DiagnosticsProfile{
-					BootDiagnostics: &compute.BootDiagnostics{
-						Enabled: to.BoolPtr(true),
-					},
-				},
+				OsProfile:          osProfile,
+				StorageProfile:     storageProfile,
+				SecurityProfile:    securityProfile,
+				DiagnosticsProfile: converters.GetDiagnosticsProfile(vmssSpec.Diagnostics),
+				UserData:           getUserData(vmssSpec, bootstrapData),
 				NetworkProfile: &compute.VirtualMachineScaleSetNetworkProfile{
 					NetworkInterfaceConfigurations: &[]compute.VirtualMachineScaleSetNetworkConfiguration{
 						{
@@ -442,7 +678,7 @@ func (s *Service) buildVMSSFromSpec(ctx context.Context, vmssSpec azure.ScaleSet
 												ID: to.StringPtr(azure.SubnetID(s.Scope.SubscriptionID(), vmssSpec.VNetResourceGroup, vmssSpec.VNetName, vmssSpec.SubnetName)),
 											},
 											Primary:                         to.BoolPtr(true),
-											PrivateIPAddressVersion:         compute.IPVersionIPv4,
+											PrivateIPAddressVersion:         compute.IPv4,
 											LoadBalancerBackendAddressPools: &backendAddressPools,
 										},
 									},
@@ -452,9 +688,10 @@ func (s *Service) buildVMSSFromSpec(ctx context.Context, vmssSpec azure.ScaleSet
 						},
 					},
 				},
-				Priority:       priority,
-				EvictionPolicy: evictionPolicy,
-				BillingProfile: billingProfile,
+				Priority:            priority,
+				EvictionPolicy:      evictionPolicy,
+				BillingProfile:      billingProfile,
+				CapacityReservation: getCapacityReservation(vmssSpec),
 				ExtensionProfile: &compute.VirtualMachineScaleSetExtensionProfile{
 					Extensions: &extensions,
 				},
@@ -462,6 +699,29 @@ func (s *Service) buildVMSSFromSpec(ctx context.Context, vmssSpec azure.ScaleSet
 		},
 	}
 
+	if vmssSpec.UpgradePolicy != nil {
+		vmss.VirtualMachineScaleSetProperties.UpgradePolicy = getUpgradePolicy(vmssSpec.UpgradePolicy)
+	}
+
+	if mix := vmssSpec.PriorityMixPolicy; mix != nil {
+		// the priority of the model is Spot, and the priority mix policy sets how many instances are regular instead
+		vmss.VirtualMachineScaleSetProperties.PriorityMixPolicy = &compute.PriorityMixPolicy{
+			BaseRegularPriorityCount:           mix.BaseRegularPriorityCount,
+			RegularPriorityPercentageAboveBase: mix.RegularPriorityPercentageAboveBase,
+		}
+		vmss.VirtualMachineScaleSetProperties.VirtualMachineProfile.Priority = compute.Spot
+		vmss.VirtualMachineScaleSetProperties.VirtualMachineProfile.EvictionPolicy = compute.VirtualMachineEvictionPolicyTypes(mix.EvictionPolicy)
+	}
+
+	if vmssSpec.OrchestrationMode == infrav1.FlexibleOrchestrationMode {
+		// Flexible scale sets don't support upgrade policies and overprovisioning, and create the network interfaces of
+		// their virtual machines as standalone resources.
+		vmss.VirtualMachineScaleSetProperties.OrchestrationMode = compute.Flexible
+		vmss.VirtualMachineScaleSetProperties.UpgradePolicy = nil
+		vmss.VirtualMachineScaleSetProperties.Overprovision = nil
+		vmss.VirtualMachineScaleSetProperties.VirtualMachineProfile.NetworkProfile.NetworkAPIVersion = compute.TwoZeroTwoZeroHyphenMinusOneOneHyphenMinusZeroOne
+	}
+
 	// Assign Identity to VMSS
 	if vmssSpec.Identity == infrav1.VMIdentitySystemAssigned {
 		vmss.Identity = &compute.VirtualMachineScaleSetIdentity{
@@ -486,6 +746,14 @@ func (s *Service) buildVMSSFromSpec(ctx context.Context, vmssSpec azure.ScaleSet
 		}
 	}
 
+	if vmssSpec.AutomaticRepairs != nil {
+		vmss.VirtualMachineScaleSetProperties.AutomaticRepairsPolicy = &compute.AutomaticRepairsPolicy{
+			Enabled:      to.BoolPtr(true),
+			GracePeriod:  to.StringPtr(vmssSpec.AutomaticRepairs.GracePeriod),
+			RepairAction: compute.RepairAction(vmssSpec.AutomaticRepairs.RepairAction),
+		}
+	}
+
 	if vmssSpec.TerminateNotificationTimeout != nil {
 		vmss.VirtualMachineScaleSetProperties.VirtualMachineProfile.ScheduledEventsProfile = &compute.ScheduledEventsProfile{
 			TerminateNotificationProfile: &compute.TerminateNotificationProfile{
@@ -517,12 +785,7 @@ func (s *Service) getVirtualMachineScaleSet(ctx context.Context, vmssName string
 		return nil, errors.Wrap(err, "failed to get existing vmss")
 	}
 
-	vmssInstances, err := s.Client.ListInstances(ctx, s.Scope.ResourceGroup(), vmssName)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to list instances")
-	}
-
-	return converters.SDKToVMSS(vmss, vmssInstances), nil
+	return s.getInstances(ctx, s.Scope.ResourceGroup(), vmssName, vmss)
 }
 
 // getVirtualMachineScaleSetIfDone gets a Virtual Machine Scale Set and its instances from Azure if the future is completed.
@@ -535,7 +798,34 @@ func (s *Service) getVirtualMachineScaleSetIfDone(ctx context.Context, future *i
 		return nil, errors.Wrap(err, "failed to get result from future")
 	}
 
-	vmssInstances, err := s.Client.ListInstances(ctx, future.ResourceGroup, future.Name)
+	return s.getInstances(ctx, future.ResourceGroup, future.Name, vmss)
+}
+
+// getInstances lists the instances of a Virtual Machine Scale Set and converts them with the scale set. The instances of
+// Flexible scale sets are standard virtual machines, so they are listed with the virtual machines API.
+func (s *Service) getInstances(ctx context.Context, resourceGroup, vmssName string, vmss compute.VirtualMachineScaleSet) (*azure.VMSS, error) {
+	if vmss.VirtualMachineScaleSetProperties != nil && vmss.OrchestrationMode == compute.Flexible {
+		vms, err := s.Client.ListVirtualMachines(ctx, resourceGroup, to.String(vmss.ID))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to list instances")
+		}
+
+		result := converters.SDKToVMSS(vmss, nil)
+		for _, vm := range vms {
+			if vm.VirtualMachineProperties != nil && vm.Priority == compute.Spot {
+				// whether a Spot virtual machine was evicted is only known from the power state in its instance view
+				instanceView, err := s.Client.GetVirtualMachineInstanceView(ctx, resourceGroup, to.String(vm.Name))
+				if err != nil {
+					return nil, errors.Wrapf(err, "failed to get instance view of %s", to.String(vm.Name))
+				}
+				vm.InstanceView = &instanceView
+			}
+			result.Instances = append(result.Instances, *converters.SDKVMToVMSSVM(vm))
+		}
+		return result, nil
+	}
+
+	vmssInstances, err := s.Client.ListInstances(ctx, resourceGroup, vmssName)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to list instances")
 	}
@@ -561,6 +851,57 @@ func (s *Service) generateExtensions() []compute.VirtualMachineScaleSetExtension
 	return extensions
 }
 
+// getUpgradePolicy returns the upgrade policy of a scale set whose instances are upgraded by the platform.
+func getUpgradePolicy(spec *azure.UpgradePolicySpec) *compute.UpgradePolicy {
+	policy := &compute.UpgradePolicy{
+		Mode: compute.UpgradeMode(spec.Mode),
+	}
+	if spec.RollingUpgrade != nil {
+		policy.RollingUpgradePolicy = &compute.RollingUpgradePolicy{
+			MaxBatchInstancePercent:             spec.RollingUpgrade.MaxBatchInstancePercent,
+			MaxUnhealthyInstancePercent:         spec.RollingUpgrade.MaxUnhealthyInstancePercent,
+			MaxUnhealthyUpgradedInstancePercent: spec.RollingUpgrade.MaxUnhealthyUpgradedInstancePercent,
+		}
+		if spec.RollingUpgrade.PauseTimeBetweenBatches != "" {
+			policy.RollingUpgradePolicy.PauseTimeBetweenBatches = to.StringPtr(spec.RollingUpgrade.PauseTimeBetweenBatches)
+		}
+	}
+	if spec.AutomaticOSUpgrade {
+		policy.AutomaticOSUpgradePolicy = &compute.AutomaticOSUpgradePolicy{
+			EnableAutomaticOSUpgrade: to.BoolPtr(true),
+		}
+	}
+	return policy
+}
+
+// getApplicationHealthExtension returns the Application Health extension which reports the health of the instances
+// by probing the endpoint of the spec.
+func getApplicationHealthExtension(vmssSpec azure.ScaleSetSpec) compute.VirtualMachineScaleSetExtension {
+	name := "ApplicationHealthLinux"
+	if vmssSpec.OSDisk.OSType == azure.WindowsOS {
+		name = "ApplicationHealthWindows"
+	}
+
+	settings := map[string]interface{}{
+		"protocol": vmssSpec.ApplicationHealth.Protocol,
+		"port":     vmssSpec.ApplicationHealth.Port,
+	}
+	if vmssSpec.ApplicationHealth.RequestPath != "" {
+		settings["requestPath"] = vmssSpec.ApplicationHealth.RequestPath
+	}
+
+	return compute.VirtualMachineScaleSetExtension{
+		Name: to.StringPtr(name),
+		VirtualMachineScaleSetExtensionProperties: &compute.VirtualMachineScaleSetExtensionProperties{
+			Publisher:               to.StringPtr("Microsoft.ManagedServices"),
+			Type:                    to.StringPtr(name),
+			TypeHandlerVersion:      to.StringPtr("1.0"),
+			AutoUpgradeMinorVersion: to.BoolPtr(true),
+			Settings:                settings,
+		},
+	}
+}
+
 // generateStorageProfile generates a pointer to a compute.VirtualMachineScaleSetStorageProfile which can utilized for VM creation.
 func (s *Service) generateStorageProfile(ctx context.Context, vmssSpec azure.ScaleSetSpec, sku resourceskus.SKU) (*compute.VirtualMachineScaleSetStorageProfile, error) {
 	ctx, _, done := tele.StartSpanWithLogger(ctx, "scalesets.Service.generateStorageProfile")
@@ -583,6 +924,9 @@ func (s *Service) generateStorageProfile(ctx context.Context, vmssSpec azure.Sca
 		storageProfile.OsDisk.DiffDiskSettings = &compute.DiffDiskSettings{
 			Option: compute.DiffDiskOptions(vmssSpec.OSDisk.DiffDiskSettings.Option),
 		}
+		if vmssSpec.OSDisk.DiffDiskSettings.Placement != nil {
+			storageProfile.OsDisk.DiffDiskSettings.Placement = compute.DiffDiskPlacement(*vmssSpec.OSDisk.DiffDiskSettings.Placement)
+		}
 	}
 
 	if vmssSpec.OSDisk.ManagedDisk != nil {
@@ -633,20 +977,43 @@ func (s *Service) generateStorageProfile(ctx context.Context, vmssSpec azure.Sca
 	return storageProfile, nil
 }
 
-func (s *Service) generateOSProfile(ctx context.Context, vmssSpec azure.ScaleSetSpec) (*compute.VirtualMachineScaleSetOSProfile, error) {
-	sshKey, err := base64.StdEncoding.DecodeString(vmssSpec.SSHKeyData)
+// getBootstrapData returns the bootstrap data of the scale set, once it has checked that it can be passed to the
+// instances.
+func (s *Service) getBootstrapData(ctx context.Context, vmssSpec azure.ScaleSetSpec) (string, error) {
+	bootstrapData, err := s.Scope.GetBootstrapData(ctx)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to decode ssh public key")
+		return "", errors.Wrap(err, "failed to retrieve bootstrap data")
 	}
-	bootstrapData, err := s.Scope.GetBootstrapData(ctx)
+	format, err := s.Scope.GetBootstrapDataFormat(ctx)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to retrieve bootstrap data")
+		return "", errors.Wrap(err, "failed to retrieve bootstrap data format")
+	}
+	if err := azure.ValidateBootstrapData(bootstrapData, format, vmssSpec.BootstrapDataDelivery); err != nil {
+		return "", azure.WithTerminalError(errors.Wrap(err, "invalid bootstrap data"))
+	}
+	return bootstrapData, nil
+}
+
+// getUserData returns the bootstrap data when it is passed to the instances as user data.
+func getUserData(vmssSpec azure.ScaleSetSpec, bootstrapData string) *string {
+	if vmssSpec.BootstrapDataDelivery != infrav1.BootstrapDataDeliveryUserData {
+		return nil
+	}
+	return to.StringPtr(bootstrapData)
+}
+
+func (s *Service) generateOSProfile(vmssSpec azure.ScaleSetSpec, bootstrapData string) (*compute.VirtualMachineScaleSetOSProfile, error) {
+	sshKey, err := base64.StdEncoding.DecodeString(vmssSpec.SSHKeyData)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode ssh public key")
 	}
 
 	osProfile := &compute.VirtualMachineScaleSetOSProfile{
 		ComputerNamePrefix: to.StringPtr(vmssSpec.Name),
 		AdminUsername:      to.StringPtr(azure.DefaultUserName),
-		CustomData:         to.StringPtr(bootstrapData),
+	}
+	if vmssSpec.BootstrapDataDelivery != infrav1.BootstrapDataDeliveryUserData {
+		osProfile.CustomData = to.StringPtr(bootstrapData)
 	}
 
 	switch vmssSpec.OSDisk.OSType {
@@ -746,3 +1113,15 @@ func getSecurityProfile(vmssSpec azure.ScaleSetSpec, sku resourceskus.SKU) (*com
 func (s *Service) IsManaged(ctx context.Context) (bool, error) {
 	return true, nil
 }
+
+// getCapacityReservation returns the capacity reservation profile binding the VMSS instances to their capacity reservation group, if any.
+func getCapacityReservation(vmssSpec azure.ScaleSetSpec) *compute.CapacityReservationProfile {
+	if vmssSpec.CapacityReservationGroupID == "" {
+		return nil
+	}
+	return &compute.CapacityReservationProfile{
+		CapacityReservationGroup: &compute.SubResource{
+			ID: to.StringPtr(vmssSpec.CapacityReservationGroupID),
+		},
+	}
+}
@@ -21,7 +21,7 @@ import (
 	"net/http"
 	"testing"
 
-	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
 	"github.com/Azure/go-autorest/autorest"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/golang/mock/gomock"
@@ -31,6 +31,7 @@ import (
 	"k8s.io/utils/pointer"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/capacityreservationgroups/mock_capacityreservationgroups"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/resourceskus"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/scalesets/mock_scalesets"
 	gomockinternal "sigs.k8s.io/cluster-api-provider-azure/internal/test/matchers/gomock"
@@ -83,6 +84,7 @@ func TestGetExistingVMSS(t *testing.T) {
 						InstanceID: "my-vm-1",
 						Name:       "instance-000001",
 						State:      "Succeeded",
+						Priority:   "Regular",
 					},
 				},
 			},
@@ -117,6 +119,133 @@ func TestGetExistingVMSS(t *testing.T) {
 				}, nil)
 			},
 		},
+		{
+			name:     "get existing flexible vmss",
+			vmssName: "my-vmss",
+			result: &azure.VMSS{
+				ID:       "my-id",
+				Name:     "my-vmss",
+				State:    "Succeeded",
+				Sku:      "Standard_D2",
+				Capacity: int64(1),
+				Instances: []azure.VMSSVM{
+					{
+						ID:               "my-vm-id",
+						InstanceID:       "my-vmss_1a2b3c4d",
+						Name:             "my-vmss1A2B3C",
+						State:            "Succeeded",
+						AvailabilityZone: "2",
+						Priority:         "Regular",
+					},
+				},
+			},
+			expectedError: "",
+			expect: func(s *mock_scalesets.MockScaleSetScopeMockRecorder, m *mock_scalesets.MockClientMockRecorder) {
+				s.ResourceGroup().AnyTimes().Return("my-rg")
+				m.Get(gomockinternal.AContext(), "my-rg", "my-vmss").Return(compute.VirtualMachineScaleSet{
+					ID:   to.StringPtr("my-id"),
+					Name: to.StringPtr("my-vmss"),
+					Sku: &compute.Sku{
+						Capacity: to.Int64Ptr(1),
+						Name:     to.StringPtr("Standard_D2"),
+					},
+					VirtualMachineScaleSetProperties: &compute.VirtualMachineScaleSetProperties{
+						ProvisioningState: to.StringPtr("Succeeded"),
+						OrchestrationMode: compute.Flexible,
+					},
+				}, nil)
+				m.ListVirtualMachines(gomockinternal.AContext(), "my-rg", "my-id").Return([]compute.VirtualMachine{
+					{
+						ID:    to.StringPtr("my-vm-id"),
+						Name:  to.StringPtr("my-vmss_1a2b3c4d"),
+						Zones: &[]string{"2"},
+						VirtualMachineProperties: &compute.VirtualMachineProperties{
+							ProvisioningState: to.StringPtr("Succeeded"),
+							OsProfile: &compute.OSProfile{
+								ComputerName: to.StringPtr("my-vmss1A2B3C"),
+							},
+						},
+					},
+				}, nil)
+			},
+		},
+		{
+			name:     "get existing flexible vmss with spot virtual machines",
+			vmssName: "my-vmss",
+			result: &azure.VMSS{
+				ID:       "my-id",
+				Name:     "my-vmss",
+				State:    "Succeeded",
+				Sku:      "Standard_D2",
+				Capacity: int64(2),
+				Instances: []azure.VMSSVM{
+					{
+						ID:         "my-vm-id-1",
+						InstanceID: "my-vmss_1a2b3c4d",
+						Name:       "my-vmss1A2B3C",
+						State:      "Succeeded",
+						Priority:   "Regular",
+					},
+					{
+						ID:         "my-vm-id-2",
+						InstanceID: "my-vmss_5e6f7a8b",
+						Name:       "my-vmss5E6F7A",
+						State:      "Succeeded",
+						Priority:   "Spot",
+						Evicted:    true,
+					},
+				},
+			},
+			expectedError: "",
+			expect: func(s *mock_scalesets.MockScaleSetScopeMockRecorder, m *mock_scalesets.MockClientMockRecorder) {
+				s.ResourceGroup().AnyTimes().Return("my-rg")
+				m.Get(gomockinternal.AContext(), "my-rg", "my-vmss").Return(compute.VirtualMachineScaleSet{
+					ID:   to.StringPtr("my-id"),
+					Name: to.StringPtr("my-vmss"),
+					Sku: &compute.Sku{
+						Capacity: to.Int64Ptr(2),
+						Name:     to.StringPtr("Standard_D2"),
+					},
+					VirtualMachineScaleSetProperties: &compute.VirtualMachineScaleSetProperties{
+						ProvisioningState: to.StringPtr("Succeeded"),
+						OrchestrationMode: compute.Flexible,
+						PriorityMixPolicy: &compute.PriorityMixPolicy{
+							BaseRegularPriorityCount: to.Int32Ptr(1),
+						},
+					},
+				}, nil)
+				m.ListVirtualMachines(gomockinternal.AContext(), "my-rg", "my-id").Return([]compute.VirtualMachine{
+					{
+						ID:   to.StringPtr("my-vm-id-1"),
+						Name: to.StringPtr("my-vmss_1a2b3c4d"),
+						VirtualMachineProperties: &compute.VirtualMachineProperties{
+							ProvisioningState: to.StringPtr("Succeeded"),
+							Priority:          compute.Regular,
+							OsProfile: &compute.OSProfile{
+								ComputerName: to.StringPtr("my-vmss1A2B3C"),
+							},
+						},
+					},
+					{
+						ID:   to.StringPtr("my-vm-id-2"),
+						Name: to.StringPtr("my-vmss_5e6f7a8b"),
+						VirtualMachineProperties: &compute.VirtualMachineProperties{
+							ProvisioningState: to.StringPtr("Succeeded"),
+							Priority:          compute.Spot,
+							OsProfile: &compute.OSProfile{
+								ComputerName: to.StringPtr("my-vmss5E6F7A"),
+							},
+						},
+					},
+				}, nil)
+				m.GetVirtualMachineInstanceView(gomockinternal.AContext(), "my-rg", "my-vmss_5e6f7a8b").Return(compute.VirtualMachineInstanceView{
+					Statuses: &[]compute.InstanceViewStatus{
+						{Code: to.StringPtr("ProvisioningState/succeeded")},
+						{Code: to.StringPtr("PowerState/deallocated")},
+					},
+				}, nil)
+			},
+		},
 		{
 			name:          "list instances fails",
 			vmssName:      "my-vmss",
@@ -181,6 +310,12 @@ func TestReconcileVMSS(t *testing.T) {
 			ResourceGroup: defaultResourceGroup,
 			Name:          defaultVMSSName,
 		}
+
+		postFuture = &infrav1.Future{
+			Type:          infrav1.PostFuture,
+			ResourceGroup: defaultResourceGroup,
+			Name:          defaultVMSSName,
+		}
 	)
 
 	testcases := []struct {
@@ -214,26 +349,122 @@ func TestReconcileVMSS(t *testing.T) {
 			name:          "should finish creating a vmss when long running operation is done",
 			expectedError: "",
 			expect: func(g *WithT, s *mock_scalesets.MockScaleSetScopeMockRecorder, m *mock_scalesets.MockClientMockRecorder) {
-				defaultSpec := newDefaultVMSSSpec()
+				defaultSpec := withUltraDisk(newDefaultVMSSSpec())
+				s.ScaleSetSpec().Return(defaultSpec).AnyTimes()
+				createdVMSS := newDefaultVMSS("VM_SIZE")
+				instances := newDefaultInstances()
+
+				setupDefaultVMSSInProgressOperationDoneExpectations(s, m, createdVMSS, instances)
+				s.DeleteLongRunningOperationState(defaultSpec.Name, serviceName)
+				s.GetLongRunningOperationState(defaultSpec.Name, updateInstancesServiceName).Return(nil)
+			},
+		},
+		{
+			name:          "should wait for the instances of the retired vmss to be deleted",
+			expectedError: "",
+			expect: func(g *WithT, s *mock_scalesets.MockScaleSetScopeMockRecorder, m *mock_scalesets.MockClientMockRecorder) {
+				defaultSpec := withUltraDisk(newDefaultVMSSSpec())
+				s.ScaleSetSpec().Return(defaultSpec).AnyTimes()
+				createdVMSS := newDefaultVMSS("VM_SIZE")
+				instances := newDefaultInstances()
+
+				setupDefaultVMSSInProgressOperationDoneExpectations(s, m, createdVMSS, instances)
+				s.DeleteLongRunningOperationState(defaultSpec.Name, serviceName)
+				s.GetLongRunningOperationState(defaultSpec.Name, updateInstancesServiceName).Return(nil)
+				s.RetiredScaleSetName().Return("my-vmss-green")
+				s.GetLongRunningOperationState("my-vmss-green", serviceName).Return(nil)
+				m.Get(gomockinternal.AContext(), defaultResourceGroup, "my-vmss-green").Return(newDefaultExistingVMSS("VM_SIZE"), nil)
+				m.ListInstances(gomockinternal.AContext(), defaultResourceGroup, "my-vmss-green").Return(instances, nil)
+				s.SetDeploymentVMSSState("my-vmss-green", gomock.Any())
+			},
+		},
+		{
+			name:          "should delete the retired vmss once its instances are deleted",
+			expectedError: "",
+			expect: func(g *WithT, s *mock_scalesets.MockScaleSetScopeMockRecorder, m *mock_scalesets.MockClientMockRecorder) {
+				defaultSpec := withUltraDisk(newDefaultVMSSSpec())
 				s.ScaleSetSpec().Return(defaultSpec).AnyTimes()
 				createdVMSS := newDefaultVMSS("VM_SIZE")
 				instances := newDefaultInstances()
 
 				setupDefaultVMSSInProgressOperationDoneExpectations(s, m, createdVMSS, instances)
 				s.DeleteLongRunningOperationState(defaultSpec.Name, serviceName)
+				s.GetLongRunningOperationState(defaultSpec.Name, updateInstancesServiceName).Return(nil)
+				s.RetiredScaleSetName().Return("my-vmss-green")
+				s.GetLongRunningOperationState("my-vmss-green", serviceName).Return(nil).Times(2)
+				m.Get(gomockinternal.AContext(), defaultResourceGroup, "my-vmss-green").Return(newDefaultExistingVMSS("VM_SIZE"), nil)
+				m.ListInstances(gomockinternal.AContext(), defaultResourceGroup, "my-vmss-green").Return([]compute.VirtualMachineScaleSetVM{}, nil)
+				s.SetDeploymentVMSSState("my-vmss-green", gomock.Any())
+				m.DeleteAsync(gomockinternal.AContext(), defaultResourceGroup, "my-vmss-green").Return(nil, nil)
+				s.SetLongRunningOperationState(nil)
+				s.DeleteLongRunningOperationState("my-vmss-green", serviceName)
+				s.SetRetiredScaleSetDeleted()
 			},
 		},
 		{
 			name:          "Windows VMSS should not get patched",
 			expectedError: "",
 			expect: func(g *WithT, s *mock_scalesets.MockScaleSetScopeMockRecorder, m *mock_scalesets.MockClientMockRecorder) {
-				defaultSpec := newWindowsVMSSSpec()
+				defaultSpec := withUltraDisk(newWindowsVMSSSpec())
 				s.ScaleSetSpec().Return(defaultSpec).AnyTimes()
 				createdVMSS := newDefaultWindowsVMSS()
 				instances := newDefaultInstances()
 
 				setupDefaultVMSSInProgressOperationDoneExpectations(s, m, createdVMSS, instances)
 				s.DeleteLongRunningOperationState(defaultSpec.Name, serviceName)
+				s.GetLongRunningOperationState(defaultSpec.Name, updateInstancesServiceName).Return(nil)
+			},
+		},
+		{
+			name:          "should start updating the instances which only miss changes applied in place",
+			expectedError: "operation type POST on Azure resource my-rg/my-vmss is not done",
+			expect: func(g *WithT, s *mock_scalesets.MockScaleSetScopeMockRecorder, m *mock_scalesets.MockClientMockRecorder) {
+				defaultSpec := withUltraDisk(newDefaultVMSSSpec())
+				s.ScaleSetSpec().Return(defaultSpec).AnyTimes()
+				createdVMSS := newDefaultVMSS("VM_SIZE")
+				instances := newDefaultInstances()
+				instances[1].LatestModelApplied = to.BoolPtr(false)
+
+				setupDefaultVMSSInProgressOperationDoneExpectations(s, m, createdVMSS, instances)
+				s.DeleteLongRunningOperationState(defaultSpec.Name, serviceName)
+				s.GetLongRunningOperationState(defaultSpec.Name, updateInstancesServiceName).Return(nil)
+				m.UpdateInstancesAsync(gomockinternal.AContext(), defaultResourceGroup, defaultVMSSName, []string{"my-vm-2"}).
+					Return(postFuture, nil)
+				s.SetLongRunningOperationState(postFuture)
+				m.GetResultIfDone(gomockinternal.AContext(), postFuture).Return(compute.VirtualMachineScaleSet{}, azure.NewOperationNotDoneError(postFuture))
+			},
+		},
+		{
+			name:          "should not update the instances of a flexible vmss in place",
+			expectedError: "",
+			expect: func(g *WithT, s *mock_scalesets.MockScaleSetScopeMockRecorder, m *mock_scalesets.MockClientMockRecorder) {
+				defaultSpec := withUltraDisk(newDefaultVMSSSpec())
+				defaultSpec.OrchestrationMode = infrav1.FlexibleOrchestrationMode
+				s.ScaleSetSpec().Return(defaultSpec).AnyTimes()
+				createdVMSS := newDefaultVMSS("VM_SIZE")
+				instances := newDefaultInstances()
+				instances[1].LatestModelApplied = to.BoolPtr(false)
+
+				setupDefaultVMSSInProgressOperationDoneExpectations(s, m, createdVMSS, instances)
+				s.DeleteLongRunningOperationState(defaultSpec.Name, serviceName)
+				s.GetLongRunningOperationState(defaultSpec.Name, updateInstancesServiceName).Return(nil)
+			},
+		},
+		{
+			name:          "should finish updating the instances in place",
+			expectedError: "",
+			expect: func(g *WithT, s *mock_scalesets.MockScaleSetScopeMockRecorder, m *mock_scalesets.MockClientMockRecorder) {
+				defaultSpec := withUltraDisk(newDefaultVMSSSpec())
+				s.ScaleSetSpec().Return(defaultSpec).AnyTimes()
+				createdVMSS := newDefaultVMSS("VM_SIZE")
+				instances := newDefaultInstances()
+				instances[1].LatestModelApplied = to.BoolPtr(false)
+
+				setupDefaultVMSSInProgressOperationDoneExpectations(s, m, createdVMSS, instances)
+				s.DeleteLongRunningOperationState(defaultSpec.Name, serviceName)
+				s.GetLongRunningOperationState(defaultSpec.Name, updateInstancesServiceName).Return(postFuture)
+				m.GetResultIfDone(gomockinternal.AContext(), postFuture).Return(compute.VirtualMachineScaleSet{}, nil)
+				s.DeleteLongRunningOperationState(defaultSpec.Name, updateInstancesServiceName)
 			},
 		},
 		{
@@ -271,13 +502,215 @@ func TestReconcileVMSS(t *testing.T) {
 				setupDefaultVMSSStartCreatingExpectations(s, m)
 				vmss := newDefaultVMSS("VM_SIZE")
 				vmss.VirtualMachineScaleSetProperties.AdditionalCapabilities = &compute.AdditionalCapabilities{UltraSSDEnabled: pointer.Bool(true)}
-				vmss.VirtualMachineScaleSetProperties.VirtualMachineProfile.Priority = compute.VirtualMachinePriorityTypesSpot
+				vmss.VirtualMachineScaleSetProperties.VirtualMachineProfile.Priority = compute.Spot
 				vmss.VirtualMachineScaleSetProperties.VirtualMachineProfile.EvictionPolicy = compute.VirtualMachineEvictionPolicyTypesDeallocate
 				m.CreateOrUpdateAsync(gomockinternal.AContext(), defaultResourceGroup, defaultVMSSName, gomockinternal.DiffEq(vmss)).
 					Return(putFuture, nil)
 				setupCreatingSucceededExpectations(s, m, newDefaultExistingVMSS("VM_SIZE"), putFuture)
 			},
 		},
+		{
+			name:          "should start creating a vmss with application health and automatic repairs",
+			expectedError: "failed to get VMSS my-vmss after create or update: failed to get result from future: operation type PUT on Azure resource my-rg/my-vmss is not done",
+			expect: func(g *WithT, s *mock_scalesets.MockScaleSetScopeMockRecorder, m *mock_scalesets.MockClientMockRecorder) {
+				spec := newDefaultVMSSSpec()
+				spec.ApplicationHealth = &azure.ApplicationHealthSpec{
+					Protocol:    "http",
+					Port:        10248,
+					RequestPath: "/healthz",
+				}
+				spec.AutomaticRepairs = &azure.AutomaticRepairsSpec{
+					GracePeriod:  "PT30M",
+					RepairAction: "Replace",
+				}
+				spec.DataDisks = append(spec.DataDisks, infrav1.DataDisk{
+					NameSuffix: "my_disk_with_ultra_disks",
+					DiskSizeGB: 128,
+					Lun:        to.Int32Ptr(3),
+					ManagedDisk: &infrav1.ManagedDiskParameters{
+						StorageAccountType: "UltraSSD_LRS",
+					},
+				})
+				s.ScaleSetSpec().Return(spec).AnyTimes()
+				setupDefaultVMSSStartCreatingExpectations(s, m)
+				vmss := newDefaultVMSS("VM_SIZE")
+				vmss.VirtualMachineScaleSetProperties.AdditionalCapabilities = &compute.AdditionalCapabilities{UltraSSDEnabled: pointer.Bool(true)}
+				extensions := vmss.VirtualMachineScaleSetProperties.VirtualMachineProfile.ExtensionProfile.Extensions
+				*extensions = append(*extensions, compute.VirtualMachineScaleSetExtension{
+					Name: to.StringPtr("ApplicationHealthLinux"),
+					VirtualMachineScaleSetExtensionProperties: &compute.VirtualMachineScaleSetExtensionProperties{
+						Publisher:               to.StringPtr("Microsoft.ManagedServices"),
+						Type:                    to.StringPtr("ApplicationHealthLinux"),
+						TypeHandlerVersion:      to.StringPtr("1.0"),
+						AutoUpgradeMinorVersion: to.BoolPtr(true),
+						Settings: map[string]interface{}{
+							"protocol":    "http",
+							"port":        int32(10248),
+							"requestPath": "/healthz",
+						},
+					},
+				})
+				vmss.VirtualMachineScaleSetProperties.AutomaticRepairsPolicy = &compute.AutomaticRepairsPolicy{
+					Enabled:      to.BoolPtr(true),
+					GracePeriod:  to.StringPtr("PT30M"),
+					RepairAction: compute.Replace,
+				}
+				m.CreateOrUpdateAsync(gomockinternal.AContext(), defaultResourceGroup, defaultVMSSName, gomockinternal.DiffEq(vmss)).
+					Return(putFuture, nil)
+				setupCreatingSucceededExpectations(s, m, newDefaultExistingVMSS("VM_SIZE"), putFuture)
+			},
+		},
+		{
+			name:          "should start creating a flexible vmss",
+			expectedError: "failed to get VMSS my-vmss after create or update: failed to get result from future: operation type PUT on Azure resource my-rg/my-vmss is not done",
+			expect: func(g *WithT, s *mock_scalesets.MockScaleSetScopeMockRecorder, m *mock_scalesets.MockClientMockRecorder) {
+				spec := newDefaultVMSSSpec()
+				spec.OrchestrationMode = infrav1.FlexibleOrchestrationMode
+				spec.DataDisks = append(spec.DataDisks, infrav1.DataDisk{
+					NameSuffix: "my_disk_with_ultra_disks",
+					DiskSizeGB: 128,
+					Lun:        to.Int32Ptr(3),
+					ManagedDisk: &infrav1.ManagedDiskParameters{
+						StorageAccountType: "UltraSSD_LRS",
+					},
+				})
+				s.ScaleSetSpec().Return(spec).AnyTimes()
+				setupDefaultVMSSStartCreatingExpectations(s, m)
+				vmss := newDefaultVMSS("VM_SIZE")
+				vmss.VirtualMachineScaleSetProperties.AdditionalCapabilities = &compute.AdditionalCapabilities{UltraSSDEnabled: pointer.Bool(true)}
+				vmss.VirtualMachineScaleSetProperties.OrchestrationMode = compute.Flexible
+				vmss.VirtualMachineScaleSetProperties.UpgradePolicy = nil
+				vmss.VirtualMachineScaleSetProperties.Overprovision = nil
+				vmss.VirtualMachineScaleSetProperties.VirtualMachineProfile.NetworkProfile.NetworkAPIVersion = compute.TwoZeroTwoZeroHyphenMinusOneOneHyphenMinusZeroOne
+				m.CreateOrUpdateAsync(gomockinternal.AContext(), defaultResourceGroup, defaultVMSSName, gomockinternal.DiffEq(vmss)).
+					Return(putFuture, nil)
+				setupCreatingSucceededExpectations(s, m, newDefaultExistingVMSS("VM_SIZE"), putFuture)
+			},
+		},
+		{
+			name:          "should start creating a vmss with a rolling upgrade policy and automatic os upgrades",
+			expectedError: "failed to get VMSS my-vmss after create or update: failed to get result from future: operation type PUT on Azure resource my-rg/my-vmss is not done",
+			expect: func(g *WithT, s *mock_scalesets.MockScaleSetScopeMockRecorder, m *mock_scalesets.MockClientMockRecorder) {
+				spec := newDefaultVMSSSpec()
+				spec.UpgradePolicy = &azure.UpgradePolicySpec{
+					Mode: "Rolling",
+					RollingUpgrade: &azure.RollingUpgradePolicySpec{
+						MaxBatchInstancePercent: to.Int32Ptr(30),
+						PauseTimeBetweenBatches: "PT60S",
+					},
+					AutomaticOSUpgrade: true,
+				}
+				spec.DataDisks = append(spec.DataDisks, infrav1.DataDisk{
+					NameSuffix: "my_disk_with_ultra_disks",
+					DiskSizeGB: 128,
+					Lun:        to.Int32Ptr(3),
+					ManagedDisk: &infrav1.ManagedDiskParameters{
+						StorageAccountType: "UltraSSD_LRS",
+					},
+				})
+				s.ScaleSetSpec().Return(spec).AnyTimes()
+				setupDefaultVMSSStartCreatingExpectations(s, m)
+				vmss := newDefaultVMSS("VM_SIZE")
+				vmss.VirtualMachineScaleSetProperties.AdditionalCapabilities = &compute.AdditionalCapabilities{UltraSSDEnabled: pointer.Bool(true)}
+				vmss.VirtualMachineScaleSetProperties.UpgradePolicy = &compute.UpgradePolicy{
+					Mode: compute.UpgradeModeRolling,
+					RollingUpgradePolicy: &compute.RollingUpgradePolicy{
+						MaxBatchInstancePercent: to.Int32Ptr(30),
+						PauseTimeBetweenBatches: to.StringPtr("PT60S"),
+					},
+					AutomaticOSUpgradePolicy: &compute.AutomaticOSUpgradePolicy{
+						EnableAutomaticOSUpgrade: to.BoolPtr(true),
+					},
+				}
+				m.CreateOrUpdateAsync(gomockinternal.AContext(), defaultResourceGroup, defaultVMSSName, gomockinternal.DiffEq(vmss)).
+					Return(putFuture, nil)
+				setupCreatingSucceededExpectations(s, m, newDefaultExistingVMSS("VM_SIZE"), putFuture)
+			},
+		},
+		{
+			name:          "should start creating a vmss with automatic os upgrades of the latest image version",
+			expectedError: "failed to get VMSS my-vmss after create or update: failed to get result from future: operation type PUT on Azure resource my-rg/my-vmss is not done",
+			expect: func(g *WithT, s *mock_scalesets.MockScaleSetScopeMockRecorder, m *mock_scalesets.MockClientMockRecorder) {
+				spec := newDefaultVMSSSpec()
+				spec.UpgradePolicy = &azure.UpgradePolicySpec{
+					Mode:               "Manual",
+					AutomaticOSUpgrade: true,
+				}
+				spec.DataDisks = append(spec.DataDisks, infrav1.DataDisk{
+					NameSuffix: "my_disk_with_ultra_disks",
+					DiskSizeGB: 128,
+					Lun:        to.Int32Ptr(3),
+					ManagedDisk: &infrav1.ManagedDiskParameters{
+						StorageAccountType: "UltraSSD_LRS",
+					},
+				})
+				s.ScaleSetSpec().Return(spec).AnyTimes()
+				setupVMSSExpectationsWithoutVMImage(s)
+				image := &infrav1.Image{
+					Marketplace: &infrav1.AzureMarketplaceImage{
+						Publisher: "fake-publisher",
+						Offer:     "my-offer",
+						SKU:       "sku-id",
+						Version:   "latest",
+					},
+				}
+				s.GetVMImage(gomockinternal.AContext()).Return(image, nil).AnyTimes()
+				s.SaveVMImageToStatus(image)
+				s.GetLongRunningOperationState(defaultVMSSName, serviceName).Return(nil)
+				m.Get(gomockinternal.AContext(), defaultResourceGroup, defaultVMSSName).
+					Return(compute.VirtualMachineScaleSet{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not found"))
+				vmss := newDefaultVMSS("VM_SIZE")
+				vmss.VirtualMachineScaleSetProperties.AdditionalCapabilities = &compute.AdditionalCapabilities{UltraSSDEnabled: pointer.Bool(true)}
+				vmss.VirtualMachineScaleSetProperties.VirtualMachineProfile.StorageProfile.ImageReference.Version = to.StringPtr("latest")
+				vmss.VirtualMachineScaleSetProperties.UpgradePolicy = &compute.UpgradePolicy{
+					Mode: compute.UpgradeModeManual,
+					AutomaticOSUpgradePolicy: &compute.AutomaticOSUpgradePolicy{
+						EnableAutomaticOSUpgrade: to.BoolPtr(true),
+					},
+				}
+				m.CreateOrUpdateAsync(gomockinternal.AContext(), defaultResourceGroup, defaultVMSSName, gomockinternal.DiffEq(vmss)).
+					Return(putFuture, nil)
+				setupCreatingSucceededExpectations(s, m, newDefaultExistingVMSS("VM_SIZE"), putFuture)
+			},
+		},
+		{
+			name:          "should start creating a flexible vmss with a priority mix policy",
+			expectedError: "failed to get VMSS my-vmss after create or update: failed to get result from future: operation type PUT on Azure resource my-rg/my-vmss is not done",
+			expect: func(g *WithT, s *mock_scalesets.MockScaleSetScopeMockRecorder, m *mock_scalesets.MockClientMockRecorder) {
+				spec := newDefaultVMSSSpec()
+				spec.OrchestrationMode = infrav1.FlexibleOrchestrationMode
+				spec.PriorityMixPolicy = &azure.PriorityMixPolicySpec{
+					BaseRegularPriorityCount:           to.Int32Ptr(2),
+					RegularPriorityPercentageAboveBase: to.Int32Ptr(20),
+					EvictionPolicy:                     "Delete",
+				}
+				spec.DataDisks = append(spec.DataDisks, infrav1.DataDisk{
+					NameSuffix: "my_disk_with_ultra_disks",
+					DiskSizeGB: 128,
+					Lun:        to.Int32Ptr(3),
+					ManagedDisk: &infrav1.ManagedDiskParameters{
+						StorageAccountType: "UltraSSD_LRS",
+					},
+				})
+				s.ScaleSetSpec().Return(spec).AnyTimes()
+				setupDefaultVMSSStartCreatingExpectations(s, m)
+				vmss := newDefaultVMSS("VM_SIZE")
+				vmss.VirtualMachineScaleSetProperties.AdditionalCapabilities = &compute.AdditionalCapabilities{UltraSSDEnabled: pointer.Bool(true)}
+				vmss.VirtualMachineScaleSetProperties.OrchestrationMode = compute.Flexible
+				vmss.VirtualMachineScaleSetProperties.UpgradePolicy = nil
+				vmss.VirtualMachineScaleSetProperties.Overprovision = nil
+				vmss.VirtualMachineScaleSetProperties.VirtualMachineProfile.NetworkProfile.NetworkAPIVersion = compute.TwoZeroTwoZeroHyphenMinusOneOneHyphenMinusZeroOne
+				vmss.VirtualMachineScaleSetProperties.PriorityMixPolicy = &compute.PriorityMixPolicy{
+					BaseRegularPriorityCount:           to.Int32Ptr(2),
+					RegularPriorityPercentageAboveBase: to.Int32Ptr(20),
+				}
+				vmss.VirtualMachineScaleSetProperties.VirtualMachineProfile.Priority = compute.Spot
+				vmss.VirtualMachineScaleSetProperties.VirtualMachineProfile.EvictionPolicy = compute.VirtualMachineEvictionPolicyTypesDelete
+				m.CreateOrUpdateAsync(gomockinternal.AContext(), defaultResourceGroup, defaultVMSSName, gomockinternal.DiffEq(vmss)).
+					Return(putFuture, nil)
+				setupCreatingSucceededExpectations(s, m, newDefaultExistingVMSS("VM_SIZE"), putFuture)
+			},
+		},
 		{
 			name:          "should start creating a vmss with spot vm and a maximum price",
 			expectedError: "failed to get VMSS my-vmss after create or update: failed to get result from future: operation type PUT on Azure resource my-rg/my-vmss is not done",
@@ -298,7 +731,7 @@ func TestReconcileVMSS(t *testing.T) {
 				s.ScaleSetSpec().Return(spec).AnyTimes()
 				setupDefaultVMSSStartCreatingExpectations(s, m)
 				vmss := newDefaultVMSS("VM_SIZE")
-				vmss.VirtualMachineScaleSetProperties.VirtualMachineProfile.Priority = compute.VirtualMachinePriorityTypesSpot
+				vmss.VirtualMachineScaleSetProperties.VirtualMachineProfile.Priority = compute.Spot
 				vmss.VirtualMachineScaleSetProperties.VirtualMachineProfile.BillingProfile = &compute.BillingProfile{
 					MaxPrice: to.Float64Ptr(0.001),
 				}
@@ -366,7 +799,7 @@ func TestReconcileVMSS(t *testing.T) {
 				vmss.VirtualMachineScaleSetProperties.AdditionalCapabilities = &compute.AdditionalCapabilities{UltraSSDEnabled: pointer.Bool(true)}
 				vmss.Identity = &compute.VirtualMachineScaleSetIdentity{
 					Type: compute.ResourceIdentityTypeUserAssigned,
-					UserAssignedIdentities: map[string]*compute.VirtualMachineScaleSetIdentityUserAssignedIdentitiesValue{
+					UserAssignedIdentities: map[string]*compute.UserAssignedIdentitiesValue{
 						"/subscriptions/123/resourcegroups/456/providers/Microsoft.ManagedIdentity/userAssignedIdentities/id1": {},
 					},
 				}
@@ -448,6 +881,42 @@ func TestReconcileVMSS(t *testing.T) {
 				m.ListInstances(gomockinternal.AContext(), defaultResourceGroup, defaultVMSSName).Return(instances, nil)
 			},
 		},
+		{
+			name:          "should start deploying a new vmss instead of updating the model of the existing one",
+			expectedError: "failed to get VMSS my-vmss-green after create or update: failed to get result from future: operation type PUT on Azure resource my-rg/my-vmss-green is not done",
+			expect: func(g *WithT, s *mock_scalesets.MockScaleSetScopeMockRecorder, m *mock_scalesets.MockClientMockRecorder) {
+				spec := newDefaultVMSSSpec()
+				s.ScaleSetSpec().Return(spec).AnyTimes()
+
+				setupUpdateVMSSExpectations(s)
+				s.SetProviderID(azure.ProviderIDPrefix + "vmss-id")
+				s.GetLongRunningOperationState(defaultVMSSName, serviceName).Return(nil)
+				s.MaxSurge().Return(0, nil)
+				s.SetVMSSState(gomock.Any())
+				existingVMSS := newDefaultExistingVMSS("VM_SIZE")
+				existingVMSS.Sku.Capacity = to.Int64Ptr(2)
+				m.Get(gomockinternal.AContext(), defaultResourceGroup, defaultVMSSName).Return(existingVMSS, nil)
+				m.ListInstances(gomockinternal.AContext(), defaultResourceGroup, defaultVMSSName).Return(newDefaultInstances(), nil)
+				s.StartScaleSetDeployment().Return(true)
+				s.DeleteLongRunningOperationState(defaultVMSSName, serviceName)
+
+				greenSpec := spec
+				greenSpec.Name = "my-vmss-green"
+				greenFuture := &infrav1.Future{
+					Type:          infrav1.PutFuture,
+					ResourceGroup: defaultResourceGroup,
+					Name:          "my-vmss-green",
+				}
+				s.NewScaleSetSpec().Return(&greenSpec)
+				setupUpdateVMSSExpectations(s)
+				s.GetLongRunningOperationState("my-vmss-green", serviceName).Return(nil)
+				m.Get(gomockinternal.AContext(), defaultResourceGroup, "my-vmss-green").
+					Return(compute.VirtualMachineScaleSet{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not found"))
+				m.CreateOrUpdateAsync(gomockinternal.AContext(), defaultResourceGroup, "my-vmss-green", gomock.Any()).Return(greenFuture, nil)
+				s.SetLongRunningOperationState(greenFuture)
+				m.GetResultIfDone(gomockinternal.AContext(), greenFuture).Return(compute.VirtualMachineScaleSet{}, azure.NewOperationNotDoneError(greenFuture))
+			},
+		},
 		{
 			name:          "less than 2 vCPUs",
 			expectedError: "reconcile error that cannot be recovered occurred: vm size should be bigger or equal to at least 2 vCPUs. Object will not be requeued",
@@ -531,6 +1000,10 @@ func TestReconcileVMSS(t *testing.T) {
 			clientMock := mock_scalesets.NewMockClient(mockCtrl)
 
 			tc.expect(g, scopeMock.EXPECT(), clientMock.EXPECT())
+			// blue/green deployments are only in progress in the test cases expecting them
+			scopeMock.EXPECT().StartScaleSetDeployment().Return(false).AnyTimes()
+			scopeMock.EXPECT().NewScaleSetSpec().Return(nil).AnyTimes()
+			scopeMock.EXPECT().RetiredScaleSetName().Return("").AnyTimes()
 
 			s := &Service{
 				Scope:            scopeMock,
@@ -549,6 +1022,91 @@ func TestReconcileVMSS(t *testing.T) {
 	}
 }
 
+func TestReconcileVMSSCapacityReservationMismatch(t *testing.T) {
+	g := NewWithT(t)
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	scopeMock := mock_scalesets.NewMockScaleSetScope(mockCtrl)
+	reservationsMock := mock_capacityreservationgroups.NewMockClient(mockCtrl)
+
+	groupID := "/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/capacityReservationGroups/my-crg"
+	scopeMock.EXPECT().ScaleSetSpec().Return(azure.ScaleSetSpec{
+		Name:                       defaultVMSSName,
+		Size:                       "VM_SIZE",
+		Capacity:                   2,
+		SSHKeyData:                 "ZmFrZXNzaGtleQo=",
+		CapacityReservationGroupID: groupID,
+	}).AnyTimes()
+	scopeMock.EXPECT().Location().Return("test-location").AnyTimes()
+	reservationsMock.EXPECT().ListReservations(gomockinternal.AContext(), groupID).Return([]compute.CapacityReservation{
+		{Sku: &compute.Sku{Name: to.StringPtr("OTHER_VM_SIZE"), Capacity: to.Int64Ptr(2)}},
+	}, nil)
+	expectedErr := "reconcile error that cannot be recovered occurred: capacity reservation group " + groupID + " has no regional capacity reservation for VM size VM_SIZE. Object will not be requeued"
+	scopeMock.EXPECT().UpdatePutStatus(infrav1.ScaleSetRunningCondition, serviceName, gomock.Any()).Do(func(_ clusterv1.ConditionType, _ string, err error) {
+		g.Expect(err).To(MatchError(expectedErr))
+	})
+
+	s := &Service{
+		Scope:                      scopeMock,
+		resourceSKUCache:           resourceskus.NewStaticCache(getFakeSkus(), "test-location"),
+		capacityReservationsClient: reservationsMock,
+	}
+
+	err := s.Reconcile(context.TODO())
+	g.Expect(err).To(MatchError(expectedErr))
+}
+
+func TestGetBootstrapData(t *testing.T) {
+	testcases := []struct {
+		name          string
+		delivery      infrav1.BootstrapDataDelivery
+		format        string
+		wantUserData  *string
+		expectedError string
+	}{
+		{
+			name:   "cloud-config passed as custom data",
+			format: azure.CloudConfigBootstrapDataFormat,
+		},
+		{
+			name:         "ignition passed as user data",
+			delivery:     infrav1.BootstrapDataDeliveryUserData,
+			format:       azure.IgnitionBootstrapDataFormat,
+			wantUserData: to.StringPtr("ZmFrZS1ib290c3RyYXAtZGF0YQ=="),
+		},
+		{
+			name:          "cloud-config passed as user data",
+			delivery:      infrav1.BootstrapDataDeliveryUserData,
+			format:        azure.CloudConfigBootstrapDataFormat,
+			expectedError: "reconcile error that cannot be recovered occurred: invalid bootstrap data: cloud-config bootstrap data must be passed as custom data. Object will not be requeued",
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := mock_scalesets.NewMockScaleSetScope(mockCtrl)
+			scopeMock.EXPECT().GetBootstrapData(gomockinternal.AContext()).Return("ZmFrZS1ib290c3RyYXAtZGF0YQ==", nil)
+			scopeMock.EXPECT().GetBootstrapDataFormat(gomockinternal.AContext()).Return(tc.format, nil)
+
+			s := &Service{Scope: scopeMock}
+			spec := azure.ScaleSetSpec{Name: defaultVMSSName, BootstrapDataDelivery: tc.delivery}
+			bootstrapData, err := s.getBootstrapData(context.TODO(), spec)
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(tc.expectedError))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(getUserData(spec, bootstrapData)).To(Equal(tc.wantUserData))
+		})
+	}
+}
+
 func TestDeleteVMSS(t *testing.T) {
 	const (
 		resourceGroup = "my-rg"
@@ -595,6 +1153,32 @@ func TestDeleteVMSS(t *testing.T) {
 					Return(compute.VirtualMachineScaleSet{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not found"))
 			},
 		},
+		{
+			name:          "delete the new vmss of an in-progress blue/green deployment with the active vmss",
+			expectedError: "",
+			expect: func(s *mock_scalesets.MockScaleSetScopeMockRecorder, m *mock_scalesets.MockClientMockRecorder) {
+				s.ScaleSetSpec().Return(azure.ScaleSetSpec{
+					Name:     name,
+					Size:     "VM_SIZE",
+					Capacity: 3,
+				}).AnyTimes()
+				s.NewScaleSetSpec().Return(&azure.ScaleSetSpec{
+					Name:     "my-vmss-green",
+					Size:     "VM_SIZE",
+					Capacity: 3,
+				})
+				s.ResourceGroup().AnyTimes().Return(resourceGroup)
+				s.GetLongRunningOperationState("my-vmss-green", serviceName).Return(nil)
+				m.DeleteAsync(gomockinternal.AContext(), resourceGroup, "my-vmss-green").Return(nil, nil)
+				s.SetLongRunningOperationState(nil)
+				s.DeleteLongRunningOperationState("my-vmss-green", serviceName)
+				s.GetLongRunningOperationState(name, serviceName).Return(nil)
+				m.DeleteAsync(gomockinternal.AContext(), resourceGroup, name).
+					Return(nil, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not found"))
+				m.Get(gomockinternal.AContext(), resourceGroup, name).
+					Return(compute.VirtualMachineScaleSet{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not found"))
+			},
+		},
 		{
 			name:          "vmss deletion fails",
 			expectedError: "failed to delete VMSS my-vmss in resource group my-rg: #: Internal Server Error: StatusCode=500",
@@ -627,6 +1211,8 @@ func TestDeleteVMSS(t *testing.T) {
 			clientMock := mock_scalesets.NewMockClient(mockCtrl)
 
 			tc.expect(scopeMock.EXPECT(), clientMock.EXPECT())
+			scopeMock.EXPECT().NewScaleSetSpec().Return(nil).AnyTimes()
+			scopeMock.EXPECT().RetiredScaleSetName().Return("").AnyTimes()
 
 			s := &Service{
 				Scope:  scopeMock,
@@ -898,6 +1484,19 @@ func newDefaultVMSSSpec() azure.ScaleSetSpec {
 	}
 }
 
+// withUltraDisk adds the ultra disk of the scale set returned by newDefaultVMSS for the VM_SIZE size to a spec.
+func withUltraDisk(spec azure.ScaleSetSpec) azure.ScaleSetSpec {
+	spec.DataDisks = append(spec.DataDisks, infrav1.DataDisk{
+		NameSuffix: "my_disk_with_ultra_disks",
+		DiskSizeGB: 128,
+		Lun:        to.Int32Ptr(3),
+		ManagedDisk: &infrav1.ManagedDiskParameters{
+			StorageAccountType: "UltraSSD_LRS",
+		},
+	})
+	return spec
+}
+
 func newWindowsVMSSSpec() azure.ScaleSetSpec {
 	vmss := newDefaultVMSSSpec()
 	vmss.OSDisk.OSType = azure.WindowsOS
@@ -1002,7 +1601,7 @@ func newDefaultVMSS(vmSize string) compute.VirtualMachineScaleSet {
 												ID: to.StringPtr("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Network/virtualNetworks/my-vnet/subnets/my-subnet"),
 											},
 											Primary:                         to.BoolPtr(true),
-											PrivateIPAddressVersion:         compute.IPVersionIPv4,
+											PrivateIPAddressVersion:         compute.IPv4,
 											LoadBalancerBackendAddressPools: &[]compute.SubResource{{ID: to.StringPtr("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Network/loadBalancers/capz-lb/backendAddressPools/backendPool")}},
 										},
 									},
@@ -1113,7 +1712,8 @@ func newDefaultInstances() []compute.VirtualMachineScaleSetVM {
 			InstanceID: to.StringPtr("my-vm-1"),
 			Name:       to.StringPtr("my-vm"),
 			VirtualMachineScaleSetVMProperties: &compute.VirtualMachineScaleSetVMProperties{
-				ProvisioningState: to.StringPtr("Succeeded"),
+				ProvisioningState:  to.StringPtr("Succeeded"),
+				LatestModelApplied: to.BoolPtr(true),
 				OsProfile: &compute.OSProfile{
 					ComputerName: to.StringPtr("instance-000001"),
 				},
@@ -1124,6 +1724,13 @@ func newDefaultInstances() []compute.VirtualMachineScaleSetVM {
 						Sku:       to.StringPtr("sku-id"),
 						Version:   to.StringPtr("1.0"),
 					},
+					OsDisk: &compute.OSDisk{
+						DiskSizeGB: to.Int32Ptr(120),
+						ManagedDisk: &compute.ManagedDiskParameters{
+							StorageAccountType: "Premium_LRS",
+						},
+					},
+					DataDisks: newDefaultInstanceDataDisks(),
 				},
 			},
 		},
@@ -1132,7 +1739,8 @@ func newDefaultInstances() []compute.VirtualMachineScaleSetVM {
 			InstanceID: to.StringPtr("my-vm-2"),
 			Name:       to.StringPtr("my-vm"),
 			VirtualMachineScaleSetVMProperties: &compute.VirtualMachineScaleSetVMProperties{
-				ProvisioningState: to.StringPtr("Succeeded"),
+				ProvisioningState:  to.StringPtr("Succeeded"),
+				LatestModelApplied: to.BoolPtr(true),
 				OsProfile: &compute.OSProfile{
 					ComputerName: to.StringPtr("instance-000002"),
 				},
@@ -1143,12 +1751,41 @@ func newDefaultInstances() []compute.VirtualMachineScaleSetVM {
 						Sku:       to.StringPtr("sku-id"),
 						Version:   to.StringPtr("1.0"),
 					},
+					OsDisk: &compute.OSDisk{
+						DiskSizeGB: to.Int32Ptr(120),
+						ManagedDisk: &compute.ManagedDiskParameters{
+							StorageAccountType: "Premium_LRS",
+						},
+					},
+					DataDisks: newDefaultInstanceDataDisks(),
 				},
 			},
 		},
 	}
 }
 
+// newDefaultInstanceDataDisks returns the data disks of an instance of the scale set returned by newDefaultVMSS for the
+// VM_SIZE size.
+func newDefaultInstanceDataDisks() *[]compute.DataDisk {
+	var dataDisks []compute.DataDisk
+	for _, disk := range *fetchDataDiskBasedOnSize("VM_SIZE") {
+		dataDisk := compute.DataDisk{
+			Lun:          disk.Lun,
+			Name:         disk.Name,
+			CreateOption: disk.CreateOption,
+			DiskSizeGB:   disk.DiskSizeGB,
+		}
+		if disk.ManagedDisk != nil {
+			dataDisk.ManagedDisk = &compute.ManagedDiskParameters{
+				StorageAccountType: disk.ManagedDisk.StorageAccountType,
+				DiskEncryptionSet:  disk.ManagedDisk.DiskEncryptionSet,
+			}
+		}
+		dataDisks = append(dataDisks, dataDisk)
+	}
+	return &dataDisks
+}
+
 func setupDefaultVMSSInProgressOperationDoneExpectations(s *mock_scalesets.MockScaleSetScopeMockRecorder, m *mock_scalesets.MockClientMockRecorder, createdVMSS compute.VirtualMachineScaleSet, instances []compute.VirtualMachineScaleSetVM) {
 	createdVMSS.ID = to.StringPtr("vmss-id")
 	createdVMSS.ProvisioningState = to.StringPtr(string(infrav1.Succeeded))
@@ -1218,6 +1855,7 @@ func setupVMSSExpectationsWithoutVMImage(s *mock_scalesets.MockScaleSetScopeMock
 	s.Location().AnyTimes().Return("test-location")
 	s.ClusterName().Return("my-cluster")
 	s.GetBootstrapData(gomockinternal.AContext()).Return("fake-bootstrap-data", nil)
+	s.GetBootstrapDataFormat(gomockinternal.AContext()).Return(azure.CloudConfigBootstrapDataFormat, nil)
 	s.VMSSExtensionSpecs().Return([]azure.ExtensionSpec{
 		{
 			Name:      "someExtension",
@@ -22,8 +22,9 @@ import (
 	"encoding/json"
 	"time"
 
-	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
 	"github.com/Azure/go-autorest/autorest"
+	azureautorest "github.com/Azure/go-autorest/autorest/azure"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/pkg/errors"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
@@ -37,12 +38,21 @@ type client interface {
 	Get(context.Context, string, string, string) (compute.VirtualMachineScaleSetVM, error)
 	GetResultIfDone(ctx context.Context, future *infrav1.Future) (compute.VirtualMachineScaleSetVM, error)
 	DeleteAsync(context.Context, string, string, string) (*infrav1.Future, error)
+	ActionAsync(context.Context, string, string, string, string) (*infrav1.Future, error)
+	GetVM(context.Context, string, string) (compute.VirtualMachine, error)
+	DeleteVMAsync(context.Context, string, string) (*infrav1.Future, error)
+	VMActionAsync(context.Context, string, string, string) (*infrav1.Future, error)
+	RunCommandAsync(context.Context, string, string, string, compute.RunCommandInput) (*infrav1.Future, error)
+	UpdateProtectionPolicyAsync(context.Context, string, string, string, compute.VirtualMachineScaleSetVMProtectionPolicy) (*infrav1.Future, error)
+	VMRunCommandAsync(context.Context, string, string, compute.RunCommandInput) (*infrav1.Future, error)
+	GetRunCommandResultIfDone(ctx context.Context, future *infrav1.Future) (compute.RunCommandResult, error)
 }
 
 type (
 	// azureClient contains the Azure go-sdk Client.
 	azureClient struct {
-		scalesetvms compute.VirtualMachineScaleSetVMsClient
+		scalesetvms     compute.VirtualMachineScaleSetVMsClient
+		virtualmachines compute.VirtualMachinesClient
 	}
 
 	genericScaleSetVMFuture interface {
@@ -53,14 +63,24 @@ type (
 	deleteFutureAdapter struct {
 		compute.VirtualMachineScaleSetVMsDeleteFuture
 	}
+
+	postFutureAdapter struct {
+		azureautorest.Future
+	}
+
+	updateFutureAdapter struct {
+		compute.VirtualMachineScaleSetVMsUpdateFuture
+	}
 )
 
 var _ client = &azureClient{}
 
 // newClient creates a new VMSS client from subscription ID.
 func newClient(auth azure.Authorizer) *azureClient {
+	subscriptionID, baseURI, authorizer := auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer()
 	return &azureClient{
-		scalesetvms: newVirtualMachineScaleSetVMsClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer()),
+		scalesetvms:     newVirtualMachineScaleSetVMsClient(subscriptionID, baseURI, authorizer),
+		virtualmachines: newVirtualMachinesClient(subscriptionID, baseURI, authorizer),
 	}
 }
 
@@ -73,12 +93,21 @@ func newVirtualMachineScaleSetVMsClient(subscriptionID string, baseURI string, a
 	return c
 }
 
-// Get retrieves the Virtual Machine Scale Set Virtual Machine.
+// newVirtualMachinesClient creates a new VM client from subscription ID.
+func newVirtualMachinesClient(subscriptionID string, baseURI string, authorizer autorest.Authorizer) compute.VirtualMachinesClient {
+	c := compute.NewVirtualMachinesClientWithBaseURI(baseURI, subscriptionID)
+	c.Authorizer = authorizer
+	c.RetryAttempts = 1
+	_ = c.AddToUserAgent(azure.UserAgent()) // intentionally ignore error as it doesn't matter
+	return c
+}
+
+// Get retrieves the Virtual Machine Scale Set Virtual Machine, including its instance view.
 func (ac *azureClient) Get(ctx context.Context, resourceGroupName, vmssName, instanceID string) (compute.VirtualMachineScaleSetVM, error) {
 	ctx, _, done := tele.StartSpanWithLogger(ctx, "scalesetvms.azureClient.Get")
 	defer done()
 
-	return ac.scalesetvms.Get(ctx, resourceGroupName, vmssName, instanceID, "")
+	return ac.scalesetvms.Get(ctx, resourceGroupName, vmssName, instanceID, compute.InstanceViewTypesInstanceView)
 }
 
 // GetResultIfDone fetches the result of a long-running operation future if it is done.
@@ -102,6 +131,24 @@ func (ac *azureClient) GetResultIfDone(ctx context.Context, future *infrav1.Futu
 		genericFuture = &deleteFutureAdapter{
 			VirtualMachineScaleSetVMsDeleteFuture: future,
 		}
+	case infrav1.PutFuture:
+		var future compute.VirtualMachineScaleSetVMsUpdateFuture
+		if err := json.Unmarshal(futureData, &future); err != nil {
+			return compute.VirtualMachineScaleSetVM{}, errors.Wrap(err, "failed to unmarshal future data")
+		}
+
+		genericFuture = &updateFutureAdapter{
+			VirtualMachineScaleSetVMsUpdateFuture: future,
+		}
+	case infrav1.PostFuture:
+		var future azureautorest.Future
+		if err := future.UnmarshalJSON(futureData); err != nil {
+			return compute.VirtualMachineScaleSetVM{}, errors.Wrap(err, "failed to unmarshal future data")
+		}
+
+		genericFuture = &postFutureAdapter{
+			Future: future,
+		}
 	default:
 		return compute.VirtualMachineScaleSetVM{}, errors.Errorf("unknown furture type %q", future.Type)
 	}
@@ -143,9 +190,232 @@ func (ac *azureClient) DeleteAsync(ctx context.Context, resourceGroupName, vmssN
 	return converters.SDKToFuture(&future, infrav1.DeleteFuture, serviceName, instanceID, resourceGroupName)
 }
 
+// ActionAsync runs one of the azure.VMActions on a virtual machine scale set instance asynchronously. ActionAsync sends
+// a POST request to Azure and if accepted without error, the func will return a Future which can be used to track the
+// ongoing progress of the operation.
+//
+// Parameters:
+//   resourceGroupName - the name of the resource group.
+//   vmssName - the name of the VM scale set.
+//   instanceID - the ID of the VM scale set VM.
+//   action - the action to run on the VM scale set VM.
+func (ac *azureClient) ActionAsync(ctx context.Context, resourceGroupName, vmssName, instanceID, action string) (*infrav1.Future, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "scalesetvms.azureClient.ActionAsync")
+	defer done()
+
+	var (
+		future azureautorest.FutureAPI
+		err    error
+	)
+	switch action {
+	case azure.VMActionRestart:
+		var f compute.VirtualMachineScaleSetVMsRestartFuture
+		f, err = ac.scalesetvms.Restart(ctx, resourceGroupName, vmssName, instanceID)
+		future = f.FutureAPI
+	case azure.VMActionRedeploy:
+		var f compute.VirtualMachineScaleSetVMsRedeployFuture
+		f, err = ac.scalesetvms.Redeploy(ctx, resourceGroupName, vmssName, instanceID)
+		future = f.FutureAPI
+	case azure.VMActionReimage:
+		var f compute.VirtualMachineScaleSetVMsReimageFuture
+		f, err = ac.scalesetvms.Reimage(ctx, resourceGroupName, vmssName, instanceID, nil)
+		future = f.FutureAPI
+	case azure.VMActionDeallocate:
+		var f compute.VirtualMachineScaleSetVMsDeallocateFuture
+		f, err = ac.scalesetvms.Deallocate(ctx, resourceGroupName, vmssName, instanceID)
+		future = f.FutureAPI
+	case azure.VMActionStart:
+		var f compute.VirtualMachineScaleSetVMsStartFuture
+		f, err = ac.scalesetvms.Start(ctx, resourceGroupName, vmssName, instanceID)
+		future = f.FutureAPI
+	default:
+		return nil, errors.Errorf("unknown VM action %q", action)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to %s vmss instance %s/%s", action, vmssName, instanceID)
+	}
+
+	return converters.SDKToFuture(future, infrav1.PostFuture, actionServiceName, instanceID, resourceGroupName)
+}
+
+// GetVM retrieves a virtual machine of a Virtual Machine Scale Set with the Flexible orchestration mode, including its
+// instance view.
+func (ac *azureClient) GetVM(ctx context.Context, resourceGroupName, vmName string) (compute.VirtualMachine, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "scalesetvms.azureClient.GetVM")
+	defer done()
+
+	return ac.virtualmachines.Get(ctx, resourceGroupName, vmName, compute.InstanceViewTypesInstanceView)
+}
+
+// DeleteVMAsync is the operation to delete a virtual machine of a Virtual Machine Scale Set with the Flexible
+// orchestration mode asynchronously. The returned Future can be used to track the progress of the operation with
+// GetResultIfDone like the Futures of the scale set instance operations.
+func (ac *azureClient) DeleteVMAsync(ctx context.Context, resourceGroupName, vmName string) (*infrav1.Future, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "scalesetvms.azureClient.DeleteVMAsync")
+	defer done()
+
+	future, err := ac.virtualmachines.Delete(ctx, resourceGroupName, vmName, to.BoolPtr(false))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed deleting vm named %q", vmName)
+	}
+
+	return converters.SDKToFuture(&future, infrav1.DeleteFuture, serviceName, vmName, resourceGroupName)
+}
+
+// VMActionAsync runs one of the azure.VMActions on a virtual machine of a Virtual Machine Scale Set with the Flexible
+// orchestration mode asynchronously.
+func (ac *azureClient) VMActionAsync(ctx context.Context, resourceGroupName, vmName, action string) (*infrav1.Future, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "scalesetvms.azureClient.VMActionAsync")
+	defer done()
+
+	var (
+		future azureautorest.FutureAPI
+		err    error
+	)
+	switch action {
+	case azure.VMActionRestart:
+		var f compute.VirtualMachinesRestartFuture
+		f, err = ac.virtualmachines.Restart(ctx, resourceGroupName, vmName)
+		future = f.FutureAPI
+	case azure.VMActionRedeploy:
+		var f compute.VirtualMachinesRedeployFuture
+		f, err = ac.virtualmachines.Redeploy(ctx, resourceGroupName, vmName)
+		future = f.FutureAPI
+	case azure.VMActionReimage:
+		var f compute.VirtualMachinesReimageFuture
+		f, err = ac.virtualmachines.Reimage(ctx, resourceGroupName, vmName, nil)
+		future = f.FutureAPI
+	case azure.VMActionDeallocate:
+		var f compute.VirtualMachinesDeallocateFuture
+		f, err = ac.virtualmachines.Deallocate(ctx, resourceGroupName, vmName, nil)
+		future = f.FutureAPI
+	case azure.VMActionStart:
+		var f compute.VirtualMachinesStartFuture
+		f, err = ac.virtualmachines.Start(ctx, resourceGroupName, vmName)
+		future = f.FutureAPI
+	default:
+		return nil, errors.Errorf("unknown VM action %q", action)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to %s vm %s", action, vmName)
+	}
+
+	return converters.SDKToFuture(future, infrav1.PostFuture, actionServiceName, vmName, resourceGroupName)
+}
+
+// UpdateProtectionPolicyAsync sets the protection policy of a virtual machine scale set instance asynchronously. The
+// instance is updated with a PUT request of its whole model, so its latest model is fetched first.
+//
+// Parameters:
+//   resourceGroupName - the name of the resource group.
+//   vmssName - the name of the VM scale set.
+//   instanceID - the ID of the VM scale set VM.
+//   policy - the protection policy of the VM scale set VM.
+func (ac *azureClient) UpdateProtectionPolicyAsync(ctx context.Context, resourceGroupName, vmssName, instanceID string, policy compute.VirtualMachineScaleSetVMProtectionPolicy) (*infrav1.Future, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "scalesetvms.azureClient.UpdateProtectionPolicyAsync")
+	defer done()
+
+	instance, err := ac.scalesetvms.Get(ctx, resourceGroupName, vmssName, instanceID, "")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get vmss instance %s/%s", vmssName, instanceID)
+	}
+	if instance.VirtualMachineScaleSetVMProperties == nil {
+		return nil, errors.Errorf("vmss instance %s/%s has no properties", vmssName, instanceID)
+	}
+	instance.ProtectionPolicy = &policy
+
+	future, err := ac.scalesetvms.Update(ctx, resourceGroupName, vmssName, instanceID, instance)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to update the protection policy of vmss instance %s/%s", vmssName, instanceID)
+	}
+
+	return converters.SDKToFuture(&future, infrav1.PutFuture, protectionServiceName, instanceID, resourceGroupName)
+}
+
+// RunCommandAsync runs a command on a virtual machine scale set instance asynchronously. RunCommandAsync sends a POST
+// request to Azure and if accepted without error, the func will return a Future which can be used to track the ongoing
+// progress of the operation.
+//
+// Parameters:
+//   resourceGroupName - the name of the resource group.
+//   vmssName - the name of the VM scale set.
+//   instanceID - the ID of the VM scale set VM.
+//   input - the command to run on the VM scale set VM.
+func (ac *azureClient) RunCommandAsync(ctx context.Context, resourceGroupName, vmssName, instanceID string, input compute.RunCommandInput) (*infrav1.Future, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "scalesetvms.azureClient.RunCommandAsync")
+	defer done()
+
+	future, err := ac.scalesetvms.RunCommand(ctx, resourceGroupName, vmssName, instanceID, input)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to run command on vmss instance %s/%s", vmssName, instanceID)
+	}
+
+	return converters.SDKToFuture(future.FutureAPI, infrav1.PostFuture, scheduledEventsServiceName, instanceID, resourceGroupName)
+}
+
+// VMRunCommandAsync runs a command on a virtual machine of a Virtual Machine Scale Set with the Flexible orchestration
+// mode asynchronously.
+func (ac *azureClient) VMRunCommandAsync(ctx context.Context, resourceGroupName, vmName string, input compute.RunCommandInput) (*infrav1.Future, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "scalesetvms.azureClient.VMRunCommandAsync")
+	defer done()
+
+	future, err := ac.virtualmachines.RunCommand(ctx, resourceGroupName, vmName, input)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to run command on vm %s", vmName)
+	}
+
+	return converters.SDKToFuture(future.FutureAPI, infrav1.PostFuture, scheduledEventsServiceName, vmName, resourceGroupName)
+}
+
+// GetRunCommandResultIfDone fetches the result of a command run with RunCommandAsync or VMRunCommandAsync if it is
+// done, which holds the output of the command.
+func (ac *azureClient) GetRunCommandResultIfDone(ctx context.Context, future *infrav1.Future) (compute.RunCommandResult, error) {
+	ctx, _, spanDone := tele.StartSpanWithLogger(ctx, "scalesetvms.azureClient.GetRunCommandResultIfDone")
+	defer spanDone()
+
+	futureData, err := base64.URLEncoding.DecodeString(future.Data)
+	if err != nil {
+		return compute.RunCommandResult{}, errors.Wrapf(err, "failed to base64 decode future data")
+	}
+
+	// the futures of the scale set instances and virtual machines run commands only differ by the client they use
+	var runCommandFuture compute.VirtualMachineScaleSetVMsRunCommandFuture
+	if err := runCommandFuture.UnmarshalJSON(futureData); err != nil {
+		return compute.RunCommandResult{}, errors.Wrap(err, "failed to unmarshal future data")
+	}
+
+	done, err := runCommandFuture.DoneWithContext(ctx, ac.scalesetvms)
+	if err != nil {
+		return compute.RunCommandResult{}, errors.Wrapf(err, "failed checking if the operation was complete")
+	}
+
+	if !done {
+		return compute.RunCommandResult{}, azure.WithTransientError(azure.NewOperationNotDoneError(future), 15*time.Second)
+	}
+
+	result, err := runCommandFuture.Result(ac.scalesetvms)
+	if err != nil {
+		return result, errors.Wrapf(err, "failed fetching the result of the run command operation")
+	}
+
+	return result, nil
+}
+
 // Result wraps the delete result so that we can treat it generically. The only thing we care about is if the delete
 // was successful. If it wasn't, an error will be returned.
 func (da *deleteFutureAdapter) Result(client compute.VirtualMachineScaleSetVMsClient) (compute.VirtualMachineScaleSetVM, error) {
 	_, err := da.VirtualMachineScaleSetVMsDeleteFuture.Result(client)
 	return compute.VirtualMachineScaleSetVM{}, err
 }
+
+// Result wraps the update result so that we can treat it generically.
+func (ua *updateFutureAdapter) Result(client compute.VirtualMachineScaleSetVMsClient) (compute.VirtualMachineScaleSetVM, error) {
+	return ua.VirtualMachineScaleSetVMsUpdateFuture.Result(client)
+}
+
+// Result wraps the result of a POST action so that we can treat it generically. Actions don't return a result, the only
+// thing we care about is if the action was successful. If it wasn't, DoneWithContext will already have returned an
+// error.
+func (pa *postFutureAdapter) Result(client compute.VirtualMachineScaleSetVMsClient) (compute.VirtualMachineScaleSetVM, error) {
+	return compute.VirtualMachineScaleSetVM{}, nil
+}
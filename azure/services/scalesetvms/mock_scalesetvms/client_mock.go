@@ -24,7 +24,7 @@ import (
 	context "context"
 	reflect "reflect"
 
-	compute "github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	compute "github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
 	autorest "github.com/Azure/go-autorest/autorest"
 	gomock "github.com/golang/mock/gomock"
 	v1beta1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
@@ -53,6 +53,21 @@ func (m *Mockclient) EXPECT() *MockclientMockRecorder {
 	return m.recorder
 }
 
+// ActionAsync mocks base method.
+func (m *Mockclient) ActionAsync(arg0 context.Context, arg1, arg2, arg3, arg4 string) (*v1beta1.Future, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ActionAsync", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(*v1beta1.Future)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ActionAsync indicates an expected call of ActionAsync.
+func (mr *MockclientMockRecorder) ActionAsync(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ActionAsync", reflect.TypeOf((*Mockclient)(nil).ActionAsync), arg0, arg1, arg2, arg3, arg4)
+}
+
 // DeleteAsync mocks base method.
 func (m *Mockclient) DeleteAsync(arg0 context.Context, arg1, arg2, arg3 string) (*v1beta1.Future, error) {
 	m.ctrl.T.Helper()
@@ -68,6 +83,21 @@ func (mr *MockclientMockRecorder) DeleteAsync(arg0, arg1, arg2, arg3 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAsync", reflect.TypeOf((*Mockclient)(nil).DeleteAsync), arg0, arg1, arg2, arg3)
 }
 
+// DeleteVMAsync mocks base method.
+func (m *Mockclient) DeleteVMAsync(arg0 context.Context, arg1, arg2 string) (*v1beta1.Future, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteVMAsync", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*v1beta1.Future)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteVMAsync indicates an expected call of DeleteVMAsync.
+func (mr *MockclientMockRecorder) DeleteVMAsync(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVMAsync", reflect.TypeOf((*Mockclient)(nil).DeleteVMAsync), arg0, arg1, arg2)
+}
+
 // Get mocks base method.
 func (m *Mockclient) Get(arg0 context.Context, arg1, arg2, arg3 string) (compute.VirtualMachineScaleSetVM, error) {
 	m.ctrl.T.Helper()
@@ -98,6 +128,96 @@ func (mr *MockclientMockRecorder) GetResultIfDone(ctx, future interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetResultIfDone", reflect.TypeOf((*Mockclient)(nil).GetResultIfDone), ctx, future)
 }
 
+// GetRunCommandResultIfDone mocks base method.
+func (m *Mockclient) GetRunCommandResultIfDone(ctx context.Context, future *v1beta1.Future) (compute.RunCommandResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRunCommandResultIfDone", ctx, future)
+	ret0, _ := ret[0].(compute.RunCommandResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRunCommandResultIfDone indicates an expected call of GetRunCommandResultIfDone.
+func (mr *MockclientMockRecorder) GetRunCommandResultIfDone(ctx, future interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRunCommandResultIfDone", reflect.TypeOf((*Mockclient)(nil).GetRunCommandResultIfDone), ctx, future)
+}
+
+// GetVM mocks base method.
+func (m *Mockclient) GetVM(arg0 context.Context, arg1, arg2 string) (compute.VirtualMachine, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVM", arg0, arg1, arg2)
+	ret0, _ := ret[0].(compute.VirtualMachine)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVM indicates an expected call of GetVM.
+func (mr *MockclientMockRecorder) GetVM(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVM", reflect.TypeOf((*Mockclient)(nil).GetVM), arg0, arg1, arg2)
+}
+
+// RunCommandAsync mocks base method.
+func (m *Mockclient) RunCommandAsync(arg0 context.Context, arg1, arg2, arg3 string, arg4 compute.RunCommandInput) (*v1beta1.Future, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunCommandAsync", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(*v1beta1.Future)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RunCommandAsync indicates an expected call of RunCommandAsync.
+func (mr *MockclientMockRecorder) RunCommandAsync(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunCommandAsync", reflect.TypeOf((*Mockclient)(nil).RunCommandAsync), arg0, arg1, arg2, arg3, arg4)
+}
+
+// UpdateProtectionPolicyAsync mocks base method.
+func (m *Mockclient) UpdateProtectionPolicyAsync(arg0 context.Context, arg1, arg2, arg3 string, arg4 compute.VirtualMachineScaleSetVMProtectionPolicy) (*v1beta1.Future, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProtectionPolicyAsync", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(*v1beta1.Future)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateProtectionPolicyAsync indicates an expected call of UpdateProtectionPolicyAsync.
+func (mr *MockclientMockRecorder) UpdateProtectionPolicyAsync(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProtectionPolicyAsync", reflect.TypeOf((*Mockclient)(nil).UpdateProtectionPolicyAsync), arg0, arg1, arg2, arg3, arg4)
+}
+
+// VMActionAsync mocks base method.
+func (m *Mockclient) VMActionAsync(arg0 context.Context, arg1, arg2, arg3 string) (*v1beta1.Future, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VMActionAsync", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*v1beta1.Future)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VMActionAsync indicates an expected call of VMActionAsync.
+func (mr *MockclientMockRecorder) VMActionAsync(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VMActionAsync", reflect.TypeOf((*Mockclient)(nil).VMActionAsync), arg0, arg1, arg2, arg3)
+}
+
+// VMRunCommandAsync mocks base method.
+func (m *Mockclient) VMRunCommandAsync(arg0 context.Context, arg1, arg2 string, arg3 compute.RunCommandInput) (*v1beta1.Future, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VMRunCommandAsync", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*v1beta1.Future)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VMRunCommandAsync indicates an expected call of VMRunCommandAsync.
+func (mr *MockclientMockRecorder) VMRunCommandAsync(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VMRunCommandAsync", reflect.TypeOf((*Mockclient)(nil).VMRunCommandAsync), arg0, arg1, arg2, arg3)
+}
+
 // MockgenericScaleSetVMFuture is a mock of genericScaleSetVMFuture interface.
 type MockgenericScaleSetVMFuture struct {
 	ctrl     *gomock.Controller
@@ -67,6 +67,20 @@ func (mr *MockScaleSetVMScopeMockRecorder) AdditionalTags() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdditionalTags", reflect.TypeOf((*MockScaleSetVMScope)(nil).AdditionalTags))
 }
 
+// Annotation mocks base method.
+func (m *MockScaleSetVMScope) Annotation(arg0 string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Annotation", arg0)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Annotation indicates an expected call of Annotation.
+func (mr *MockScaleSetVMScopeMockRecorder) Annotation(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Annotation", reflect.TypeOf((*MockScaleSetVMScope)(nil).Annotation), arg0)
+}
+
 // Authorizer mocks base method.
 func (m *MockScaleSetVMScope) Authorizer() autorest.Authorizer {
 	m.ctrl.T.Helper()
@@ -261,6 +275,46 @@ func (mr *MockScaleSetVMScopeMockRecorder) Location() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Location", reflect.TypeOf((*MockScaleSetVMScope)(nil).Location))
 }
 
+// OSType mocks base method.
+func (m *MockScaleSetVMScope) OSType() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OSType")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// OSType indicates an expected call of OSType.
+func (mr *MockScaleSetVMScopeMockRecorder) OSType() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OSType", reflect.TypeOf((*MockScaleSetVMScope)(nil).OSType))
+}
+
+// OrchestrationMode mocks base method.
+func (m *MockScaleSetVMScope) OrchestrationMode() v1beta1.OrchestrationModeType {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OrchestrationMode")
+	ret0, _ := ret[0].(v1beta1.OrchestrationModeType)
+	return ret0
+}
+
+// OrchestrationMode indicates an expected call of OrchestrationMode.
+func (mr *MockScaleSetVMScopeMockRecorder) OrchestrationMode() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OrchestrationMode", reflect.TypeOf((*MockScaleSetVMScope)(nil).OrchestrationMode))
+}
+
+// RemoveAnnotation mocks base method.
+func (m *MockScaleSetVMScope) RemoveAnnotation(arg0 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RemoveAnnotation", arg0)
+}
+
+// RemoveAnnotation indicates an expected call of RemoveAnnotation.
+func (mr *MockScaleSetVMScopeMockRecorder) RemoveAnnotation(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveAnnotation", reflect.TypeOf((*MockScaleSetVMScope)(nil).RemoveAnnotation), arg0)
+}
+
 // ResourceGroup mocks base method.
 func (m *MockScaleSetVMScope) ResourceGroup() string {
 	m.ctrl.T.Helper()
@@ -18,8 +18,11 @@ package scalesetvms
 
 import (
 	"context"
+	"strings"
 	"time"
 
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/pkg/errors"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
@@ -27,7 +30,60 @@ import (
 	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
 )
 
-const serviceName = "scalesetvms"
+const (
+	serviceName = "scalesetvms"
+	// actionServiceName keys the long-running operations of the actions requested with azure.VMActionAnnotation.
+	actionServiceName = "scalesetvms-action"
+	// scheduledEventsServiceName keys the long-running operations approving the scheduled events of an instance.
+	scheduledEventsServiceName = "scalesetvms-scheduledevents"
+	// protectionServiceName keys the long-running operations updating the protection policy of an instance.
+	protectionServiceName = "scalesetvms-protection"
+)
+
+// approvedScheduledEventOutput is printed by the scripts approving the scheduled events for each approved event, as
+// the run command operation succeeds even if the script fails on some operating systems.
+const approvedScheduledEventOutput = "approved scheduled event"
+
+// approveTerminateEventsScript is the shell script run on Linux instances to approve their pending Terminate and Preempt
+// scheduled events. Scheduled events can only be approved from the instance itself, through the instance metadata
+// service, which lists the events of all the instances of the scale set. The script fails if no event was approved.
+var approveTerminateEventsScript = []string{
+	`url="http://169.254.169.254/metadata/scheduledevents?api-version=2020-07-01"`,
+	`name=$(curl -sf -H Metadata:true "http://169.254.169.254/metadata/instance/compute/name?api-version=2021-02-01&format=text")`,
+	`approved=0`,
+	`for event in $(curl -sf -H Metadata:true "$url" | grep -o '{[^{}]*}' | tr -d ' '); do`,
+	`  case "$event" in *'"EventType":"Terminate"'*|*'"EventType":"Preempt"'*) ;; *) continue ;; esac`,
+	`  case "$event" in *"\"$name\""*) ;; *) continue ;; esac`,
+	`  id=$(echo "$event" | sed 's/.*"EventId":"\([^"]*\)".*/\1/')`,
+	`  if curl -sf -H Metadata:true -X POST -d "{\"StartRequests\":[{\"EventId\":\"$id\"}]}" "$url"; then`,
+	`    echo "approved scheduled event $id"`,
+	`    approved=1`,
+	`  fi`,
+	`done`,
+	`if [ "$approved" -eq 0 ]; then`,
+	`  echo "no terminate scheduled event of $name was approved" >&2`,
+	`  exit 1`,
+	`fi`,
+}
+
+// approveTerminateEventsPowerShellScript is the PowerShell script run on Windows instances to approve their pending
+// Terminate and Preempt scheduled events. The script fails if no event was approved.
+var approveTerminateEventsPowerShellScript = []string{
+	`$ErrorActionPreference = "Stop"`,
+	`$url = "http://169.254.169.254/metadata/scheduledevents?api-version=2020-07-01"`,
+	`$name = Invoke-RestMethod -Headers @{Metadata = "true"} -Uri "http://169.254.169.254/metadata/instance/compute/name?api-version=2021-02-01&format=text"`,
+	`$scheduledEvents = (Invoke-RestMethod -Headers @{Metadata = "true"} -Uri $url).Events | Where-Object { ($_.EventType -eq "Terminate" -or $_.EventType -eq "Preempt") -and $_.Resources -contains $name }`,
+	`$approved = $false`,
+	`foreach ($scheduledEvent in $scheduledEvents) {`,
+	`  $body = @{StartRequests = @(@{EventId = $scheduledEvent.EventId})} | ConvertTo-Json -Depth 3`,
+	`  Invoke-RestMethod -Method Post -Headers @{Metadata = "true"} -ContentType "application/json" -Uri $url -Body $body`,
+	`  Write-Output "approved scheduled event $($scheduledEvent.EventId)"`,
+	`  $approved = $true`,
+	`}`,
+	`if (-not $approved) {`,
+	`  throw "no terminate scheduled event of $name was approved"`,
+	`}`,
+}
 
 type (
 	// ScaleSetVMScope defines the scope interface for a scale sets service.
@@ -36,7 +92,11 @@ type (
 		azure.AsyncStatusUpdater
 		InstanceID() string
 		ScaleSetName() string
+		OrchestrationMode() infrav1.OrchestrationModeType
+		OSType() string
 		SetVMSSVM(vmssvm *azure.VMSSVM)
+		Annotation(string) string
+		RemoveAnnotation(string)
 	}
 
 	// Service provides operations on Azure resources.
@@ -71,7 +131,7 @@ func (s *Service) Reconcile(ctx context.Context) error {
 	)
 
 	// fetch the latest data about the instance -- model mutations are handled by the AzureMachinePoolReconciler
-	instance, err := s.Client.Get(ctx, resourceGroup, vmssName, instanceID)
+	instance, ephemeralOSDisk, err := s.getInstance(ctx, resourceGroup, vmssName, instanceID)
 	if err != nil {
 		if azure.ResourceNotFound(err) {
 			return azure.WithTransientError(errors.New("instance does not exist yet"), 30*time.Second)
@@ -79,10 +139,277 @@ func (s *Service) Reconcile(ctx context.Context) error {
 		return errors.Wrap(err, "failed getting instance")
 	}
 
-	s.Scope.SetVMSSVM(converters.SDKToVMSSVM(instance))
+	s.Scope.SetVMSSVM(instance)
+	if err := s.reconcileProtectionPolicy(ctx, resourceGroup, vmssName, instanceID, instance); err != nil {
+		return err
+	}
+
+	return s.reconcileAction(ctx, ephemeralOSDisk)
+}
+
+// getInstance fetches the instance and reports whether it has an ephemeral OS disk. The instances of Flexible scale
+// sets are standard virtual machines, so they are fetched with the virtual machines API.
+func (s *Service) getInstance(ctx context.Context, resourceGroup, vmssName, instanceID string) (*azure.VMSSVM, bool, error) {
+	if s.Scope.OrchestrationMode() == infrav1.FlexibleOrchestrationMode {
+		vm, err := s.Client.GetVM(ctx, resourceGroup, instanceID)
+		if err != nil {
+			return nil, false, err
+		}
+		ephemeralOSDisk := vm.VirtualMachineProperties != nil && vm.StorageProfile != nil &&
+			vm.StorageProfile.OsDisk != nil && vm.StorageProfile.OsDisk.DiffDiskSettings != nil
+		return converters.SDKVMToVMSSVM(vm), ephemeralOSDisk, nil
+	}
+
+	instance, err := s.Client.Get(ctx, resourceGroup, vmssName, instanceID)
+	if err != nil {
+		return nil, false, err
+	}
+	ephemeralOSDisk := instance.VirtualMachineScaleSetVMProperties != nil && instance.StorageProfile != nil &&
+		instance.StorageProfile.OsDisk != nil && instance.StorageProfile.OsDisk.DiffDiskSettings != nil
+	return converters.SDKToVMSSVM(instance), ephemeralOSDisk, nil
+}
+
+// reconcileProtectionPolicy applies the protection policy requested with azure.InstanceProtectionAnnotation to the
+// instance. The instances of Flexible scale sets don't support protection policies, they are only protected from the
+// scale-in of the machine pool.
+func (s *Service) reconcileProtectionPolicy(ctx context.Context, resourceGroup, vmssName, instanceID string, instance *azure.VMSSVM) error {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "scalesetvms.Service.reconcileProtectionPolicy")
+	defer done()
+
+	if s.Scope.OrchestrationMode() == infrav1.FlexibleOrchestrationMode {
+		return nil
+	}
+
+	future := s.Scope.GetLongRunningOperationState(instanceID, protectionServiceName)
+	if future == nil {
+		policy, err := protectionPolicy(s.Scope.Annotation(azure.InstanceProtectionAnnotation))
+		if err != nil {
+			log.Error(err, "invalid instance protection", "instanceID", instanceID)
+			return nil
+		}
+
+		if to.Bool(policy.ProtectFromScaleIn) == instance.ProtectFromScaleIn &&
+			to.Bool(policy.ProtectFromScaleSetActions) == instance.ProtectFromScaleSetActions {
+			return nil
+		}
+
+		// since the future was nil, there is no ongoing update; start it
+		future, err = s.Client.UpdateProtectionPolicyAsync(ctx, resourceGroup, vmssName, instanceID, policy)
+		if err != nil {
+			return err
+		}
+		s.Scope.SetLongRunningOperationState(future)
+	}
+
+	result, err := s.Client.GetResultIfDone(ctx, future)
+	if err != nil {
+		if azure.IsOperationNotDoneError(err) {
+			return err
+		}
+		s.Scope.DeleteLongRunningOperationState(instanceID, protectionServiceName)
+		return errors.Wrap(err, "failed to update the protection policy of the instance")
+	}
+
+	s.Scope.DeleteLongRunningOperationState(instanceID, protectionServiceName)
+	log.V(2).Info("updated the protection policy of the instance", "instanceID", instanceID)
+	if result.VirtualMachineScaleSetVMProperties != nil {
+		s.Scope.SetVMSSVM(converters.SDKToVMSSVM(result))
+	}
+	return nil
+}
+
+// protectionPolicy returns the protection policy requested with the value of azure.InstanceProtectionAnnotation.
+func protectionPolicy(protection string) (compute.VirtualMachineScaleSetVMProtectionPolicy, error) {
+	switch protection {
+	case "":
+		return compute.VirtualMachineScaleSetVMProtectionPolicy{
+			ProtectFromScaleIn:         to.BoolPtr(false),
+			ProtectFromScaleSetActions: to.BoolPtr(false),
+		}, nil
+	case azure.InstanceProtectionScaleIn:
+		return compute.VirtualMachineScaleSetVMProtectionPolicy{
+			ProtectFromScaleIn:         to.BoolPtr(true),
+			ProtectFromScaleSetActions: to.BoolPtr(false),
+		}, nil
+	case azure.InstanceProtectionScaleSetActions:
+		return compute.VirtualMachineScaleSetVMProtectionPolicy{
+			ProtectFromScaleIn:         to.BoolPtr(true),
+			ProtectFromScaleSetActions: to.BoolPtr(true),
+		}, nil
+	default:
+		return compute.VirtualMachineScaleSetVMProtectionPolicy{}, errors.Errorf("unknown instance protection %q, must be one of %s, %s", protection, azure.InstanceProtectionScaleIn, azure.InstanceProtectionScaleSetActions)
+	}
+}
+
+// reconcileAction runs the action requested with azure.VMActionAnnotation on the instance and reports its progress.
+// The annotation is removed once the action has completed or failed, so a failed action is not retried until it is
+// requested again.
+func (s *Service) reconcileAction(ctx context.Context, ephemeralOSDisk bool) error {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "scalesetvms.Service.reconcileAction")
+	defer done()
+
+	action := s.Scope.Annotation(azure.VMActionAnnotation)
+	if action == "" {
+		return nil
+	}
+
+	err := s.runAction(ctx, ephemeralOSDisk, action)
+	s.Scope.UpdatePatchStatus(infrav1.VMActionCondition, actionServiceName, err)
+	if azure.IsOperationNotDoneError(err) {
+		return err
+	}
+	if err != nil {
+		log.Error(err, "VM action failed", "instanceID", s.Scope.InstanceID(), "action", action)
+	} else {
+		log.V(2).Info("VM action completed", "instanceID", s.Scope.InstanceID(), "action", action)
+	}
+	s.Scope.RemoveAnnotation(azure.VMActionAnnotation)
+	return nil
+}
+
+// runAction starts the action on the instance, or checks on the progress of an action that is already running.
+func (s *Service) runAction(ctx context.Context, ephemeralOSDisk bool, action string) error {
+	var (
+		resourceGroup = s.Scope.ResourceGroup()
+		vmssName      = s.Scope.ScaleSetName()
+		instanceID    = s.Scope.InstanceID()
+	)
+
+	future := s.Scope.GetLongRunningOperationState(instanceID, actionServiceName)
+	if future == nil {
+		if err := validateAction(ephemeralOSDisk, action); err != nil {
+			return err
+		}
+
+		// since the future was nil, there is no ongoing action; start it
+		var err error
+		if s.Scope.OrchestrationMode() == infrav1.FlexibleOrchestrationMode {
+			future, err = s.Client.VMActionAsync(ctx, resourceGroup, instanceID, action)
+		} else {
+			future, err = s.Client.ActionAsync(ctx, resourceGroup, vmssName, instanceID, action)
+		}
+		if err != nil {
+			return err
+		}
+		s.Scope.SetLongRunningOperationState(future)
+	}
+
+	if _, err := s.Client.GetResultIfDone(ctx, future); err != nil {
+		if azure.IsOperationNotDoneError(err) {
+			return err
+		}
+		s.Scope.DeleteLongRunningOperationState(instanceID, actionServiceName)
+		return errors.Wrapf(err, "failed to %s instance", action)
+	}
+
+	s.Scope.DeleteLongRunningOperationState(instanceID, actionServiceName)
 	return nil
 }
 
+// validateAction checks that the action is known and can be run on the instance.
+func validateAction(ephemeralOSDisk bool, action string) error {
+	switch action {
+	case azure.VMActionRestart, azure.VMActionRedeploy, azure.VMActionDeallocate, azure.VMActionStart:
+		return nil
+	case azure.VMActionReimage:
+		if !ephemeralOSDisk {
+			return errors.New("reimage is only supported for instances with an ephemeral OS disk")
+		}
+		return nil
+	default:
+		return errors.Errorf("unknown VM action %q, must be one of %s", action, strings.Join(azure.VMActions, ", "))
+	}
+}
+
+// ApproveTerminateEvents approves the pending Terminate and Preempt scheduled events of the instance, so that it is
+// deleted or evicted right away rather than at the end of the notification timeout. The approval runs as a command on
+// the instance since scheduled events can only be approved from the instance itself.
+func (s *Service) ApproveTerminateEvents(ctx context.Context) error {
+	var (
+		resourceGroup = s.Scope.ResourceGroup()
+		vmssName      = s.Scope.ScaleSetName()
+		instanceID    = s.Scope.InstanceID()
+	)
+
+	ctx, log, done := tele.StartSpanWithLogger(
+		ctx,
+		"scalesetvms.Service.ApproveTerminateEvents",
+		tele.KVP("resourceGroup", resourceGroup),
+		tele.KVP("scaleset", vmssName),
+		tele.KVP("instanceID", instanceID),
+	)
+	defer done()
+
+	err := s.approveTerminateEvents(ctx, resourceGroup, vmssName, instanceID)
+	s.Scope.UpdatePatchStatus(infrav1.ScheduledEventApprovedCondition, scheduledEventsServiceName, err)
+	if err != nil {
+		return err
+	}
+
+	log.V(2).Info("approved the terminate scheduled events of the instance")
+	return nil
+}
+
+// approveTerminateEvents starts the command approving the scheduled events of the instance, or checks on the progress
+// of a command that is already running.
+func (s *Service) approveTerminateEvents(ctx context.Context, resourceGroup, vmssName, instanceID string) error {
+	future := s.Scope.GetLongRunningOperationState(instanceID, scheduledEventsServiceName)
+	if future == nil {
+		input := compute.RunCommandInput{
+			CommandID: to.StringPtr("RunShellScript"),
+			Script:    &approveTerminateEventsScript,
+		}
+		if s.Scope.OSType() == azure.WindowsOS {
+			input = compute.RunCommandInput{
+				CommandID: to.StringPtr("RunPowerShellScript"),
+				Script:    &approveTerminateEventsPowerShellScript,
+			}
+		}
+
+		// since the future was nil, there is no ongoing approval; start it
+		var err error
+		if s.Scope.OrchestrationMode() == infrav1.FlexibleOrchestrationMode {
+			future, err = s.Client.VMRunCommandAsync(ctx, resourceGroup, instanceID, input)
+		} else {
+			future, err = s.Client.RunCommandAsync(ctx, resourceGroup, vmssName, instanceID, input)
+		}
+		if err != nil {
+			return err
+		}
+		s.Scope.SetLongRunningOperationState(future)
+	}
+
+	result, err := s.Client.GetRunCommandResultIfDone(ctx, future)
+	if err != nil {
+		if azure.IsOperationNotDoneError(err) {
+			return err
+		}
+		s.Scope.DeleteLongRunningOperationState(instanceID, scheduledEventsServiceName)
+		return errors.Wrap(err, "failed to approve the scheduled events of the instance")
+	}
+
+	s.Scope.DeleteLongRunningOperationState(instanceID, scheduledEventsServiceName)
+	output := runCommandOutput(result)
+	if !strings.Contains(output, approvedScheduledEventOutput) {
+		return errors.Errorf("no scheduled event of the instance was approved: %s", output)
+	}
+	return nil
+}
+
+// runCommandOutput returns the messages of the statuses of a run command, which hold the output of the script.
+func runCommandOutput(result compute.RunCommandResult) string {
+	if result.Value == nil {
+		return ""
+	}
+	var messages []string
+	for _, status := range *result.Value {
+		if message := strings.TrimSpace(to.String(status.Message)); message != "" {
+			messages = append(messages, message)
+		}
+	}
+	return strings.Join(messages, "\n")
+}
+
 // Delete deletes a scaleset instance asynchronously returning a future which encapsulates the long-running operation.
 func (s *Service) Delete(ctx context.Context) error {
 	var (
@@ -101,9 +428,10 @@ func (s *Service) Delete(ctx context.Context) error {
 	defer done()
 
 	defer func() {
-		if instance, err := s.Client.Get(ctx, resourceGroup, vmssName, instanceID); err == nil && instance.VirtualMachineScaleSetVMProperties != nil {
-			log.V(4).Info("updating vmss vm state", "state", instance.ProvisioningState)
-			s.Scope.SetVMSSVM(converters.SDKToVMSSVM(instance))
+		// instances without properties have no state to update
+		if instance, _, err := s.getInstance(ctx, resourceGroup, vmssName, instanceID); err == nil && instance.State != "" {
+			log.V(4).Info("updating vmss vm state", "state", instance.State)
+			s.Scope.SetVMSSVM(instance)
 		}
 	}()
 
@@ -127,7 +455,12 @@ func (s *Service) Delete(ctx context.Context) error {
 	}
 
 	// since the future was nil, there is no ongoing activity; start deleting the instance
-	future, err := s.Client.DeleteAsync(ctx, resourceGroup, vmssName, instanceID)
+	var err error
+	if s.Scope.OrchestrationMode() == infrav1.FlexibleOrchestrationMode {
+		future, err = s.Client.DeleteVMAsync(ctx, resourceGroup, instanceID)
+	} else {
+		future, err = s.Client.DeleteAsync(ctx, resourceGroup, vmssName, instanceID)
+	}
 	if err != nil {
 		if azure.ResourceNotFound(err) {
 			// already deleted
@@ -22,7 +22,7 @@ import (
 	"testing"
 	"time"
 
-	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
 	"github.com/Azure/go-autorest/autorest"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/golang/mock/gomock"
@@ -108,6 +108,194 @@ func TestService_Reconcile(t *testing.T) {
 				}
 				m.Get(gomock2.AContext(), "rg", "scaleset", "0").Return(vm, nil)
 				s.SetVMSSVM(converters.SDKToVMSSVM(vm))
+				s.GetLongRunningOperationState("0", protectionServiceName).Return(nil)
+				s.Annotation(azure.InstanceProtectionAnnotation).Return("")
+				s.Annotation(azure.VMActionAnnotation).Return("")
+			},
+		},
+		{
+			Name: "should start a requested action",
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.ResourceGroup().Return("rg").AnyTimes()
+				s.InstanceID().Return("0").AnyTimes()
+				s.ScaleSetName().Return("scaleset").AnyTimes()
+				vm := compute.VirtualMachineScaleSetVM{
+					InstanceID: to.StringPtr("0"),
+				}
+				m.Get(gomock2.AContext(), "rg", "scaleset", "0").Return(vm, nil)
+				s.SetVMSSVM(converters.SDKToVMSSVM(vm))
+				s.GetLongRunningOperationState("0", protectionServiceName).Return(nil)
+				s.Annotation(azure.InstanceProtectionAnnotation).Return("")
+				s.Annotation(azure.VMActionAnnotation).Return(azure.VMActionRestart)
+				s.GetLongRunningOperationState("0", actionServiceName).Return(nil)
+				future := &infrav1.Future{
+					Type: infrav1.PostFuture,
+				}
+				m.ActionAsync(gomock2.AContext(), "rg", "scaleset", "0", azure.VMActionRestart).Return(future, nil)
+				s.SetLongRunningOperationState(future)
+				m.GetResultIfDone(gomock2.AContext(), future).Return(compute.VirtualMachineScaleSetVM{}, azure.WithTransientError(azure.NewOperationNotDoneError(future), 15*time.Second))
+				s.UpdatePatchStatus(infrav1.VMActionCondition, actionServiceName, gomock2.ErrStrEq("operation type POST on Azure resource / is not done. Object will be requeued after 15s"))
+			},
+			Err: azure.WithTransientError(azure.NewOperationNotDoneError(&infrav1.Future{
+				Type: infrav1.PostFuture,
+			}), 15*time.Second),
+		},
+		{
+			Name: "should remove the action annotation when the requested action has completed",
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.ResourceGroup().Return("rg").AnyTimes()
+				s.InstanceID().Return("0").AnyTimes()
+				s.ScaleSetName().Return("scaleset").AnyTimes()
+				vm := compute.VirtualMachineScaleSetVM{
+					InstanceID: to.StringPtr("0"),
+				}
+				m.Get(gomock2.AContext(), "rg", "scaleset", "0").Return(vm, nil)
+				s.SetVMSSVM(converters.SDKToVMSSVM(vm))
+				s.GetLongRunningOperationState("0", protectionServiceName).Return(nil)
+				s.Annotation(azure.InstanceProtectionAnnotation).Return("")
+				s.Annotation(azure.VMActionAnnotation).Return(azure.VMActionRedeploy)
+				future := &infrav1.Future{
+					Type: infrav1.PostFuture,
+				}
+				s.GetLongRunningOperationState("0", actionServiceName).Return(future)
+				m.GetResultIfDone(gomock2.AContext(), future).Return(compute.VirtualMachineScaleSetVM{}, nil)
+				s.DeleteLongRunningOperationState("0", actionServiceName)
+				s.UpdatePatchStatus(infrav1.VMActionCondition, actionServiceName, nil)
+				s.RemoveAnnotation(azure.VMActionAnnotation)
+			},
+		},
+		{
+			Name: "should reject reimage of an instance without an ephemeral OS disk",
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.ResourceGroup().Return("rg").AnyTimes()
+				s.InstanceID().Return("0").AnyTimes()
+				s.ScaleSetName().Return("scaleset").AnyTimes()
+				vm := compute.VirtualMachineScaleSetVM{
+					InstanceID: to.StringPtr("0"),
+				}
+				m.Get(gomock2.AContext(), "rg", "scaleset", "0").Return(vm, nil)
+				s.SetVMSSVM(converters.SDKToVMSSVM(vm))
+				s.GetLongRunningOperationState("0", protectionServiceName).Return(nil)
+				s.Annotation(azure.InstanceProtectionAnnotation).Return("")
+				s.Annotation(azure.VMActionAnnotation).Return(azure.VMActionReimage)
+				s.GetLongRunningOperationState("0", actionServiceName).Return(nil)
+				s.UpdatePatchStatus(infrav1.VMActionCondition, actionServiceName, gomock2.ErrStrEq("reimage is only supported for instances with an ephemeral OS disk"))
+				s.RemoveAnnotation(azure.VMActionAnnotation)
+			},
+		},
+		{
+			Name: "should start protecting an instance from scale-in",
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.ResourceGroup().Return("rg")
+				s.InstanceID().Return("0")
+				s.ScaleSetName().Return("scaleset")
+				vm := compute.VirtualMachineScaleSetVM{
+					InstanceID:                         to.StringPtr("0"),
+					VirtualMachineScaleSetVMProperties: &compute.VirtualMachineScaleSetVMProperties{},
+				}
+				m.Get(gomock2.AContext(), "rg", "scaleset", "0").Return(vm, nil)
+				s.SetVMSSVM(converters.SDKToVMSSVM(vm))
+				s.GetLongRunningOperationState("0", protectionServiceName).Return(nil)
+				s.Annotation(azure.InstanceProtectionAnnotation).Return(azure.InstanceProtectionScaleIn)
+				future := &infrav1.Future{
+					Type: infrav1.PutFuture,
+				}
+				m.UpdateProtectionPolicyAsync(gomock2.AContext(), "rg", "scaleset", "0", compute.VirtualMachineScaleSetVMProtectionPolicy{
+					ProtectFromScaleIn:         to.BoolPtr(true),
+					ProtectFromScaleSetActions: to.BoolPtr(false),
+				}).Return(future, nil)
+				s.SetLongRunningOperationState(future)
+				m.GetResultIfDone(gomock2.AContext(), future).Return(compute.VirtualMachineScaleSetVM{}, azure.WithTransientError(azure.NewOperationNotDoneError(future), 15*time.Second))
+			},
+			Err: azure.WithTransientError(azure.NewOperationNotDoneError(&infrav1.Future{
+				Type: infrav1.PutFuture,
+			}), 15*time.Second),
+		},
+		{
+			Name: "should not update the protection policy of an instance which already has it",
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.ResourceGroup().Return("rg")
+				s.InstanceID().Return("0")
+				s.ScaleSetName().Return("scaleset")
+				vm := compute.VirtualMachineScaleSetVM{
+					InstanceID: to.StringPtr("0"),
+					VirtualMachineScaleSetVMProperties: &compute.VirtualMachineScaleSetVMProperties{
+						ProtectionPolicy: &compute.VirtualMachineScaleSetVMProtectionPolicy{
+							ProtectFromScaleIn:         to.BoolPtr(true),
+							ProtectFromScaleSetActions: to.BoolPtr(true),
+						},
+					},
+				}
+				m.Get(gomock2.AContext(), "rg", "scaleset", "0").Return(vm, nil)
+				s.SetVMSSVM(converters.SDKToVMSSVM(vm))
+				s.GetLongRunningOperationState("0", protectionServiceName).Return(nil)
+				s.Annotation(azure.InstanceProtectionAnnotation).Return(azure.InstanceProtectionScaleSetActions)
+				s.Annotation(azure.VMActionAnnotation).Return("")
+			},
+		},
+		{
+			Name: "should finish removing the protection policy of an instance",
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.ResourceGroup().Return("rg")
+				s.InstanceID().Return("0")
+				s.ScaleSetName().Return("scaleset")
+				vm := compute.VirtualMachineScaleSetVM{
+					InstanceID: to.StringPtr("0"),
+				}
+				m.Get(gomock2.AContext(), "rg", "scaleset", "0").Return(vm, nil)
+				s.SetVMSSVM(converters.SDKToVMSSVM(vm))
+				future := &infrav1.Future{
+					Type: infrav1.PutFuture,
+				}
+				s.GetLongRunningOperationState("0", protectionServiceName).Return(future)
+				updated := compute.VirtualMachineScaleSetVM{
+					InstanceID:                         to.StringPtr("0"),
+					VirtualMachineScaleSetVMProperties: &compute.VirtualMachineScaleSetVMProperties{},
+				}
+				m.GetResultIfDone(gomock2.AContext(), future).Return(updated, nil)
+				s.DeleteLongRunningOperationState("0", protectionServiceName)
+				s.SetVMSSVM(converters.SDKToVMSSVM(updated))
+				s.Annotation(azure.VMActionAnnotation).Return("")
+			},
+		},
+		{
+			Name: "should reconcile a virtual machine of a flexible scale set successfully",
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.ResourceGroup().Return("rg")
+				s.InstanceID().Return("scaleset_1a2b3c4d")
+				s.ScaleSetName().Return("scaleset")
+				s.OrchestrationMode().Return(infrav1.FlexibleOrchestrationMode).AnyTimes()
+				vm := compute.VirtualMachine{
+					Name: to.StringPtr("scaleset_1a2b3c4d"),
+				}
+				m.GetVM(gomock2.AContext(), "rg", "scaleset_1a2b3c4d").Return(vm, nil)
+				s.SetVMSSVM(converters.SDKVMToVMSSVM(vm))
+				s.Annotation(azure.VMActionAnnotation).Return("")
+			},
+		},
+		{
+			Name: "should start a requested action on a virtual machine of a flexible scale set",
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.ResourceGroup().Return("rg").AnyTimes()
+				s.InstanceID().Return("scaleset_1a2b3c4d").AnyTimes()
+				s.ScaleSetName().Return("scaleset").AnyTimes()
+				s.OrchestrationMode().Return(infrav1.FlexibleOrchestrationMode).AnyTimes()
+				vm := compute.VirtualMachine{
+					Name: to.StringPtr("scaleset_1a2b3c4d"),
+				}
+				m.GetVM(gomock2.AContext(), "rg", "scaleset_1a2b3c4d").Return(vm, nil)
+				s.SetVMSSVM(converters.SDKVMToVMSSVM(vm))
+				s.Annotation(azure.VMActionAnnotation).Return(azure.VMActionRestart)
+				s.GetLongRunningOperationState("scaleset_1a2b3c4d", actionServiceName).Return(nil)
+				future := &infrav1.Future{
+					Type: infrav1.PostFuture,
+				}
+				m.VMActionAsync(gomock2.AContext(), "rg", "scaleset_1a2b3c4d", azure.VMActionRestart).Return(future, nil)
+				s.SetLongRunningOperationState(future)
+				m.GetResultIfDone(gomock2.AContext(), future).Return(compute.VirtualMachineScaleSetVM{}, nil)
+				s.DeleteLongRunningOperationState("scaleset_1a2b3c4d", actionServiceName)
+				s.UpdatePatchStatus(infrav1.VMActionCondition, actionServiceName, nil)
+				s.RemoveAnnotation(azure.VMActionAnnotation)
 			},
 		},
 		{
@@ -150,6 +338,7 @@ func TestService_Reconcile(t *testing.T) {
 			service := NewService(scopeMock)
 			service.Client = clientMock
 			c.Setup(scopeMock.EXPECT(), clientMock.EXPECT())
+			scopeMock.EXPECT().OrchestrationMode().Return(infrav1.UniformOrchestrationMode).AnyTimes()
 
 			if err := service.Reconcile(context.TODO()); c.Err == nil {
 				g.Expect(err).To(Succeed())
@@ -229,6 +418,24 @@ func TestService_Delete(t *testing.T) {
 			},
 			Err: errors.Wrap(errors.New("boom"), "failed to delete instance scaleset/0"),
 		},
+		{
+			Name: "should start deleting a virtual machine of a flexible scale set",
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.ResourceGroup().Return("rg")
+				s.InstanceID().Return("scaleset_1a2b3c4d")
+				s.ScaleSetName().Return("scaleset")
+				s.OrchestrationMode().Return(infrav1.FlexibleOrchestrationMode).AnyTimes()
+				s.GetLongRunningOperationState("scaleset_1a2b3c4d", serviceName).Return(nil)
+				future := &infrav1.Future{
+					Type: infrav1.DeleteFuture,
+				}
+				m.DeleteVMAsync(gomock2.AContext(), "rg", "scaleset_1a2b3c4d").Return(future, nil)
+				s.SetLongRunningOperationState(future)
+				m.GetResultIfDone(gomock2.AContext(), future).Return(compute.VirtualMachineScaleSetVM{}, nil)
+				s.DeleteLongRunningOperationState("scaleset_1a2b3c4d", serviceName)
+				m.GetVM(gomock2.AContext(), "rg", "scaleset_1a2b3c4d").Return(compute.VirtualMachine{}, autorest404)
+			},
+		},
 		{
 			Name: "should return error when a long running operation is active and getting the result returns an error",
 			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
@@ -263,6 +470,7 @@ func TestService_Delete(t *testing.T) {
 			service := NewService(scopeMock)
 			service.Client = clientMock
 			c.Setup(scopeMock.EXPECT(), clientMock.EXPECT())
+			scopeMock.EXPECT().OrchestrationMode().Return(infrav1.UniformOrchestrationMode).AnyTimes()
 
 			if err := service.Delete(context.TODO()); c.Err == nil {
 				g.Expect(err).To(Succeed())
@@ -276,3 +484,117 @@ func TestService_Delete(t *testing.T) {
 		})
 	}
 }
+
+func TestService_ApproveTerminateEvents(t *testing.T) {
+	cases := []struct {
+		Name  string
+		Setup func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder)
+		Err   error
+	}{
+		{
+			Name: "should start approving the scheduled events of the instance",
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.OrchestrationMode().Return(infrav1.UniformOrchestrationMode)
+				s.OSType().Return(azure.LinuxOS)
+				s.GetLongRunningOperationState("0", scheduledEventsServiceName).Return(nil)
+				future := &infrav1.Future{
+					Type: infrav1.PostFuture,
+				}
+				m.RunCommandAsync(gomock2.AContext(), "rg", "scaleset", "0", compute.RunCommandInput{
+					CommandID: to.StringPtr("RunShellScript"),
+					Script:    &approveTerminateEventsScript,
+				}).Return(future, nil)
+				s.SetLongRunningOperationState(future)
+				m.GetRunCommandResultIfDone(gomock2.AContext(), future).Return(compute.RunCommandResult{}, azure.WithTransientError(azure.NewOperationNotDoneError(future), 15*time.Second))
+				s.UpdatePatchStatus(infrav1.ScheduledEventApprovedCondition, scheduledEventsServiceName, gomock2.ErrStrEq("operation type POST on Azure resource / is not done. Object will be requeued after 15s"))
+			},
+			Err: azure.WithTransientError(azure.NewOperationNotDoneError(&infrav1.Future{
+				Type: infrav1.PostFuture,
+			}), 15*time.Second),
+		},
+		{
+			Name: "should approve the scheduled events of a windows virtual machine of a flexible scale set",
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.OrchestrationMode().Return(infrav1.FlexibleOrchestrationMode)
+				s.OSType().Return(azure.WindowsOS)
+				s.GetLongRunningOperationState("0", scheduledEventsServiceName).Return(nil)
+				future := &infrav1.Future{
+					Type: infrav1.PostFuture,
+				}
+				m.VMRunCommandAsync(gomock2.AContext(), "rg", "0", compute.RunCommandInput{
+					CommandID: to.StringPtr("RunPowerShellScript"),
+					Script:    &approveTerminateEventsPowerShellScript,
+				}).Return(future, nil)
+				s.SetLongRunningOperationState(future)
+				m.GetRunCommandResultIfDone(gomock2.AContext(), future).Return(compute.RunCommandResult{
+					Value: &[]compute.InstanceViewStatus{
+						{Code: to.StringPtr("ComponentStatus/StdOut/succeeded"), Message: to.StringPtr("approved scheduled event 602d9444-d2cd-49c7-8624-8643e7171297")},
+						{Code: to.StringPtr("ComponentStatus/StdErr/succeeded"), Message: to.StringPtr("")},
+					},
+				}, nil)
+				s.DeleteLongRunningOperationState("0", scheduledEventsServiceName)
+				s.UpdatePatchStatus(infrav1.ScheduledEventApprovedCondition, scheduledEventsServiceName, nil)
+			},
+		},
+		{
+			Name: "should report that no scheduled event was approved",
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				future := &infrav1.Future{
+					Type: infrav1.PostFuture,
+				}
+				s.GetLongRunningOperationState("0", scheduledEventsServiceName).Return(future)
+				m.GetRunCommandResultIfDone(gomock2.AContext(), future).Return(compute.RunCommandResult{
+					Value: &[]compute.InstanceViewStatus{
+						{Code: to.StringPtr("ProvisioningState/succeeded"), Message: to.StringPtr("Enable succeeded: \n[stdout]\n\n[stderr]\nno terminate scheduled event of vm_0 was approved\n")},
+					},
+				}, nil)
+				s.DeleteLongRunningOperationState("0", scheduledEventsServiceName)
+				s.UpdatePatchStatus(infrav1.ScheduledEventApprovedCondition, scheduledEventsServiceName, gomock2.ErrStrEq("no scheduled event of the instance was approved: Enable succeeded: \n[stdout]\n\n[stderr]\nno terminate scheduled event of vm_0 was approved"))
+			},
+			Err: errors.New("no scheduled event of the instance was approved: Enable succeeded: \n[stdout]\n\n[stderr]\nno terminate scheduled event of vm_0 was approved"),
+		},
+		{
+			Name: "should report a failed approval",
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				future := &infrav1.Future{
+					Type: infrav1.PostFuture,
+				}
+				s.GetLongRunningOperationState("0", scheduledEventsServiceName).Return(future)
+				m.GetRunCommandResultIfDone(gomock2.AContext(), future).Return(compute.RunCommandResult{}, errors.New("boom"))
+				s.DeleteLongRunningOperationState("0", scheduledEventsServiceName)
+				s.UpdatePatchStatus(infrav1.ScheduledEventApprovedCondition, scheduledEventsServiceName, gomock2.ErrStrEq("failed to approve the scheduled events of the instance: boom"))
+			},
+			Err: errors.Wrap(errors.New("boom"), "failed to approve the scheduled events of the instance"),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			var (
+				g          = NewWithT(t)
+				mockCtrl   = gomock.NewController(t)
+				scopeMock  = mock_scalesetvms.NewMockScaleSetVMScope(mockCtrl)
+				clientMock = mock_scalesetvms.NewMockclient(mockCtrl)
+			)
+			defer mockCtrl.Finish()
+
+			scopeMock.EXPECT().SubscriptionID().Return("subID")
+			scopeMock.EXPECT().BaseURI().Return("https://localhost/")
+			scopeMock.EXPECT().Authorizer().Return(nil)
+			scopeMock.EXPECT().ResourceGroup().Return("rg")
+			scopeMock.EXPECT().ScaleSetName().Return("scaleset")
+			scopeMock.EXPECT().InstanceID().Return("0")
+
+			service := NewService(scopeMock)
+			service.Client = clientMock
+			c.Setup(scopeMock.EXPECT(), clientMock.EXPECT())
+
+			if err := service.ApproveTerminateEvents(context.TODO()); c.Err == nil {
+				g.Expect(err).To(Succeed())
+			} else {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err).To(MatchError(c.Err.Error()))
+			}
+		})
+	}
+}
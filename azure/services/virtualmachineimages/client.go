@@ -0,0 +1,128 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package virtualmachineimages
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+)
+
+// Client wraps go-sdk.
+type Client interface {
+	ListMarketplaceVersions(ctx context.Context, location, publisher, offer, sku string) ([]string, error)
+	ListSharedGalleryVersions(ctx context.Context, subscriptionID, resourceGroup, gallery, image, location string) ([]string, error)
+}
+
+// AzureClient contains the Azure go-sdk Client.
+type AzureClient struct {
+	auth   azure.Authorizer
+	images compute.VirtualMachineImagesClient
+}
+
+var _ Client = &AzureClient{}
+
+// NewClient creates a new virtual machine images client from subscription ID.
+func NewClient(auth azure.Authorizer) *AzureClient {
+	c := compute.NewVirtualMachineImagesClientWithBaseURI(auth.BaseURI(), auth.SubscriptionID())
+	azure.SetAutoRestClientDefaults(&c.Client, auth.Authorizer())
+	return &AzureClient{
+		auth:   auth,
+		images: c,
+	}
+}
+
+// ListMarketplaceVersions returns the versions of a Marketplace image SKU available in a location.
+func (ac *AzureClient) ListMarketplaceVersions(ctx context.Context, location, publisher, offer, sku string) ([]string, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "virtualmachineimages.AzureClient.ListMarketplaceVersions")
+	defer done()
+
+	result, err := ac.images.List(ctx, location, publisher, offer, sku, "", nil, "")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list versions of image %s:%s:%s", publisher, offer, sku)
+	}
+
+	var versions []string
+	if result.Value != nil {
+		for _, image := range *result.Value {
+			if image.Name != nil {
+				versions = append(versions, *image.Name)
+			}
+		}
+	}
+	return versions, nil
+}
+
+// ListSharedGalleryVersions returns the versions of a Shared Image Gallery image that are replicated to a location,
+// and not excluded from latest.
+func (ac *AzureClient) ListSharedGalleryVersions(ctx context.Context, subscriptionID, resourceGroup, gallery, image, location string) ([]string, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "virtualmachineimages.AzureClient.ListSharedGalleryVersions")
+	defer done()
+
+	// The gallery can be in another subscription than the cluster.
+	c := compute.NewGalleryImageVersionsClientWithBaseURI(ac.auth.BaseURI(), subscriptionID)
+	azure.SetAutoRestClientDefaults(&c.Client, ac.auth.Authorizer())
+
+	iter, err := c.ListByGalleryImageComplete(ctx, resourceGroup, gallery, image)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list versions of image %s in gallery %s", image, gallery)
+	}
+
+	var versions []string
+	for iter.NotDone() {
+		if isUsableGalleryImageVersion(iter.Value(), location) {
+			versions = append(versions, to.String(iter.Value().Name))
+		}
+		if err := iter.NextWithContext(ctx); err != nil {
+			return versions, errors.Wrapf(err, "failed to iterate versions of image %s in gallery %s", image, gallery)
+		}
+	}
+	return versions, nil
+}
+
+// isUsableGalleryImageVersion returns true if a gallery image version was successfully provisioned, is replicated to
+// the location and is not excluded from latest.
+func isUsableGalleryImageVersion(version compute.GalleryImageVersion, location string) bool {
+	if version.Name == nil || version.GalleryImageVersionProperties == nil {
+		return false
+	}
+	if version.ProvisioningState != compute.GalleryProvisioningStateSucceeded {
+		return false
+	}
+	profile := version.PublishingProfile
+	if profile == nil {
+		return true
+	}
+	if to.Bool(profile.ExcludeFromLatest) {
+		return false
+	}
+	if profile.TargetRegions == nil {
+		return true
+	}
+	for _, region := range *profile.TargetRegions {
+		// Target regions are display names such as "West US 2".
+		if strings.EqualFold(strings.ReplaceAll(to.String(region.Name), " ", ""), location) {
+			return true
+		}
+	}
+	return false
+}
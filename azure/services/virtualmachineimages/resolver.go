@@ -0,0 +1,263 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package virtualmachineimages
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/util/cache/ttllru"
+	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+)
+
+// versionsCacheTTL is how long the versions of an image are cached before being listed again, which bounds how long
+// it takes for a new version of an image to be picked up.
+const versionsCacheTTL = 10 * time.Minute
+
+var (
+	doOnce        sync.Once
+	versionsCache ttllru.PeekingCacher
+)
+
+// Resolver resolves the 'latest' or version range of images to the exact version of the latest matching image.
+type Resolver struct {
+	client   Client
+	location string
+
+	// cache caches the versions of images across reconciles. It is keyed by the Authorizer HashKey(), as images
+	// available to a subscription may not be available to others.
+	cache   ttllru.PeekingCacher
+	hashKey string
+}
+
+// GetResolver returns a resolver for images in a location, which lists their versions with the given Authorizer.
+func GetResolver(auth azure.Authorizer, location string) (*Resolver, error) {
+	var err error
+	doOnce.Do(func() {
+		versionsCache, err = ttllru.New(1024, versionsCacheTTL)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed creating LRU cache for image versions")
+	}
+
+	return &Resolver{
+		client:   NewClient(auth),
+		location: location,
+		cache:    versionsCache,
+		hashKey:  auth.HashKey(),
+	}, nil
+}
+
+// NeedsResolution returns true if the version of an image is 'latest' or a version range to resolve.
+func NeedsResolution(image *infrav1.Image) bool {
+	if image == nil {
+		return false
+	}
+	if image.Marketplace != nil {
+		return !infrav1.IsExactImageVersion(image.Marketplace.Version)
+	}
+	if image.SharedGallery != nil {
+		return !infrav1.IsExactImageVersion(image.SharedGallery.Version)
+	}
+	return false
+}
+
+// IsVersionRange returns true if the version of an image is a version range, which unlike an exact version or
+// 'latest' can't be used as is to create a VM.
+func IsVersionRange(image *infrav1.Image) bool {
+	return NeedsResolution(image) && imageVersion(image) != infrav1.LatestImageVersion
+}
+
+// IsResolvedFrom returns true if an image is the resolution of an image whose version is 'latest' or a version range,
+// i.e. it is the same image with an exact version matching the version of the image to resolve.
+func IsResolvedFrom(resolved, image *infrav1.Image) bool {
+	if resolved == nil || image == nil {
+		return false
+	}
+	resolvedVersion, constraint := imageVersion(resolved), imageVersion(image)
+	if !infrav1.IsExactImageVersion(resolvedVersion) {
+		return false
+	}
+	if !reflect.DeepEqual(withoutVersion(resolved), withoutVersion(image)) {
+		return false
+	}
+	if constraint == infrav1.LatestImageVersion {
+		return true
+	}
+	versionRange, err := semver.ParseRange(constraint)
+	if err != nil {
+		return false
+	}
+	parsed, err := parseVersion(resolvedVersion)
+	return err == nil && versionRange(parsed)
+}
+
+// IsNewer returns true if the exact version of an image is newer than the one of another resolution of the same image.
+func IsNewer(image, than *infrav1.Image) bool {
+	version, err := parseVersion(imageVersion(image))
+	if err != nil {
+		return false
+	}
+	thanVersion, err := parseVersion(imageVersion(than))
+	if err != nil {
+		return true
+	}
+	return version.GT(thanVersion)
+}
+
+// imageVersion returns the version of a Marketplace or Shared Image Gallery image.
+func imageVersion(image *infrav1.Image) string {
+	switch {
+	case image.Marketplace != nil:
+		return image.Marketplace.Version
+	case image.SharedGallery != nil:
+		return image.SharedGallery.Version
+	}
+	return ""
+}
+
+// withoutVersion returns a copy of an image without its version.
+func withoutVersion(image *infrav1.Image) *infrav1.Image {
+	image = image.DeepCopy()
+	if image.Marketplace != nil {
+		image.Marketplace.Version = ""
+	}
+	if image.SharedGallery != nil {
+		image.SharedGallery.Version = ""
+	}
+	return image
+}
+
+// Resolve returns a copy of an image with its 'latest' or version range resolved to the exact version of the latest
+// matching image. Images that don't need resolution are returned as is.
+func (r *Resolver) Resolve(ctx context.Context, image *infrav1.Image) (*infrav1.Image, error) {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "virtualmachineimages.Resolver.Resolve")
+	defer done()
+
+	if !NeedsResolution(image) {
+		return image, nil
+	}
+
+	resolved := image.DeepCopy()
+	switch {
+	case image.Marketplace != nil:
+		mp := image.Marketplace
+		key := fmt.Sprintf("marketplace/%s/%s/%s", mp.Publisher, mp.Offer, mp.SKU)
+		versions, err := r.versions(ctx, key, func() ([]string, error) {
+			return r.client.ListMarketplaceVersions(ctx, r.location, mp.Publisher, mp.Offer, mp.SKU)
+		})
+		if err != nil {
+			return nil, err
+		}
+		version, err := latestMatchingVersion(versions, mp.Version)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to resolve version of image %s:%s:%s", mp.Publisher, mp.Offer, mp.SKU)
+		}
+		resolved.Marketplace.Version = version
+
+	case image.SharedGallery != nil:
+		sig := image.SharedGallery
+		key := fmt.Sprintf("sharedgallery/%s/%s/%s/%s", sig.SubscriptionID, sig.ResourceGroup, sig.Gallery, sig.Name)
+		versions, err := r.versions(ctx, key, func() ([]string, error) {
+			return r.client.ListSharedGalleryVersions(ctx, sig.SubscriptionID, sig.ResourceGroup, sig.Gallery, sig.Name, r.location)
+		})
+		if err != nil {
+			return nil, err
+		}
+		version, err := latestMatchingVersion(versions, sig.Version)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to resolve version of image %s in gallery %s", sig.Name, sig.Gallery)
+		}
+		resolved.SharedGallery.Version = version
+	}
+
+	log.V(4).Info("resolved image version", "image", resolved)
+	return resolved, nil
+}
+
+// versions returns the cached versions of an image, or lists and caches them.
+func (r *Resolver) versions(ctx context.Context, key string, list func() ([]string, error)) ([]string, error) {
+	if r.cache == nil {
+		return list()
+	}
+
+	key = strings.Join([]string{r.hashKey, r.location, key}, "/")
+	// Peek doesn't extend the expiration of the versions, so that new versions are listed at least every TTL.
+	if versions, _, ok := r.cache.Peek(key); ok {
+		return versions.([]string), nil
+	}
+
+	versions, err := list()
+	if err != nil {
+		return nil, err
+	}
+	r.cache.Add(key, versions)
+	return versions, nil
+}
+
+// latestMatchingVersion returns the latest of the versions matching a constraint, which is either 'latest' or a
+// semantic version range. Versions that are not Major.Minor.Build are ignored.
+func latestMatchingVersion(versions []string, constraint string) (string, error) {
+	matches := func(semver.Version) bool { return true }
+	if constraint != infrav1.LatestImageVersion {
+		versionRange, err := semver.ParseRange(constraint)
+		if err != nil {
+			return "", errors.Wrapf(err, "invalid version range %q", constraint)
+		}
+		matches = versionRange
+	}
+
+	var (
+		latest        string
+		latestVersion semver.Version
+	)
+	for _, v := range versions {
+		parsed, err := parseVersion(v)
+		if err != nil || !matches(parsed) {
+			continue
+		}
+		if latest == "" || parsed.GT(latestVersion) {
+			latest, latestVersion = v, parsed
+		}
+	}
+	if latest == "" {
+		return "", errors.Errorf("no version matching %q found", constraint)
+	}
+	return latest, nil
+}
+
+// parseVersion parses a Major.Minor.Build image version as a semantic version. Unlike semantic versions, image
+// versions may have leading zeros, e.g. 2022.01.05.
+func parseVersion(version string) (semver.Version, error) {
+	parts := strings.Split(version, ".")
+	for i, part := range parts {
+		if trimmed := strings.TrimLeft(part, "0"); trimmed != "" {
+			parts[i] = trimmed
+		} else if part != "" {
+			parts[i] = "0"
+		}
+	}
+	return semver.Parse(strings.Join(parts, "."))
+}
@@ -0,0 +1,259 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package virtualmachineimages
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/virtualmachineimages/mock_virtualmachineimages"
+	gomockinternal "sigs.k8s.io/cluster-api-provider-azure/internal/test/matchers/gomock"
+	"sigs.k8s.io/cluster-api-provider-azure/util/cache/ttllru"
+)
+
+func marketplaceImage(version string) *infrav1.Image {
+	return &infrav1.Image{
+		Marketplace: &infrav1.AzureMarketplaceImage{
+			Publisher: "cncf-upstream",
+			Offer:     "capi",
+			SKU:       "k8s-1dot22dot6-ubuntu-2004",
+			Version:   version,
+		},
+	}
+}
+
+func sharedGalleryImage(version string) *infrav1.Image {
+	return &infrav1.Image{
+		SharedGallery: &infrav1.AzureSharedGalleryImage{
+			SubscriptionID: "123",
+			ResourceGroup:  "my-rg",
+			Gallery:        "my-gallery",
+			Name:           "my-image",
+			Version:        version,
+		},
+	}
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name   string
+		image  *infrav1.Image
+		expect func(m *mock_virtualmachineimages.MockClientMockRecorder)
+		want   *infrav1.Image
+		err    string
+	}{
+		{
+			name:   "exact marketplace version is not resolved",
+			image:  marketplaceImage("2022.01.05"),
+			expect: func(m *mock_virtualmachineimages.MockClientMockRecorder) {},
+			want:   marketplaceImage("2022.01.05"),
+		},
+		{
+			name:   "image ID is not resolved",
+			image:  &infrav1.Image{ID: to.StringPtr("/subscriptions/123/image")},
+			expect: func(m *mock_virtualmachineimages.MockClientMockRecorder) {},
+			want:   &infrav1.Image{ID: to.StringPtr("/subscriptions/123/image")},
+		},
+		{
+			name:  "latest marketplace version is resolved to the latest version",
+			image: marketplaceImage("latest"),
+			expect: func(m *mock_virtualmachineimages.MockClientMockRecorder) {
+				m.ListMarketplaceVersions(gomockinternal.AContext(), "westus2", "cncf-upstream", "capi", "k8s-1dot22dot6-ubuntu-2004").
+					Return([]string{"2021.12.10", "2022.01.05", "2021.02.01", "not-a-version"}, nil)
+			},
+			want: marketplaceImage("2022.01.05"),
+		},
+		{
+			name:  "marketplace version range is resolved to the latest matching version",
+			image: marketplaceImage(">=2021.0.0 <2022.0.0"),
+			expect: func(m *mock_virtualmachineimages.MockClientMockRecorder) {
+				m.ListMarketplaceVersions(gomockinternal.AContext(), "westus2", "cncf-upstream", "capi", "k8s-1dot22dot6-ubuntu-2004").
+					Return([]string{"2021.12.10", "2022.01.05", "2021.02.01"}, nil)
+			},
+			want: marketplaceImage("2021.12.10"),
+		},
+		{
+			name:  "shared gallery wildcard version is resolved to the latest matching version",
+			image: sharedGalleryImage("1.2.x"),
+			expect: func(m *mock_virtualmachineimages.MockClientMockRecorder) {
+				m.ListSharedGalleryVersions(gomockinternal.AContext(), "123", "my-rg", "my-gallery", "my-image", "westus2").
+					Return([]string{"1.2.0", "1.2.3", "1.3.0"}, nil)
+			},
+			want: sharedGalleryImage("1.2.3"),
+		},
+		{
+			name:  "fails if no version matches",
+			image: marketplaceImage(">=2023.0.0"),
+			expect: func(m *mock_virtualmachineimages.MockClientMockRecorder) {
+				m.ListMarketplaceVersions(gomockinternal.AContext(), "westus2", "cncf-upstream", "capi", "k8s-1dot22dot6-ubuntu-2004").
+					Return([]string{"2021.12.10", "2022.01.05"}, nil)
+			},
+			err: `failed to resolve version of image cncf-upstream:capi:k8s-1dot22dot6-ubuntu-2004: no version matching ">=2023.0.0" found`,
+		},
+		{
+			name:  "fails if versions can't be listed",
+			image: sharedGalleryImage("latest"),
+			expect: func(m *mock_virtualmachineimages.MockClientMockRecorder) {
+				m.ListSharedGalleryVersions(gomockinternal.AContext(), "123", "my-rg", "my-gallery", "my-image", "westus2").
+					Return(nil, errors.New("#: Internal Server Error: StatusCode=500"))
+			},
+			err: "#: Internal Server Error: StatusCode=500",
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			clientMock := mock_virtualmachineimages.NewMockClient(mockCtrl)
+			tc.expect(clientMock.EXPECT())
+
+			r := &Resolver{client: clientMock, location: "westus2"}
+			got, err := r.Resolve(context.TODO(), tc.image)
+			if tc.err != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err).To(MatchError(tc.err))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(got).To(Equal(tc.want))
+			}
+		})
+	}
+}
+
+func TestResolveCachesVersions(t *testing.T) {
+	g := NewWithT(t)
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	clientMock := mock_virtualmachineimages.NewMockClient(mockCtrl)
+	clientMock.EXPECT().ListMarketplaceVersions(gomockinternal.AContext(), "westus2", "cncf-upstream", "capi", "k8s-1dot22dot6-ubuntu-2004").
+		Return([]string{"2021.12.10", "2022.01.05"}, nil).Times(1)
+
+	cache, err := ttllru.New(10, time.Minute)
+	g.Expect(err).NotTo(HaveOccurred())
+	r := &Resolver{client: clientMock, location: "westus2", cache: cache, hashKey: "hash"}
+	for i := 0; i < 2; i++ {
+		got, err := r.Resolve(context.TODO(), marketplaceImage("latest"))
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(got).To(Equal(marketplaceImage("2022.01.05")))
+	}
+}
+
+func TestIsResolvedFrom(t *testing.T) {
+	tests := []struct {
+		name     string
+		resolved *infrav1.Image
+		image    *infrav1.Image
+		want     bool
+	}{
+		{
+			name:     "nil resolved image",
+			resolved: nil,
+			image:    marketplaceImage("latest"),
+			want:     false,
+		},
+		{
+			name:     "resolution of latest",
+			resolved: marketplaceImage("2022.01.05"),
+			image:    marketplaceImage("latest"),
+			want:     true,
+		},
+		{
+			name:     "resolution of a matching range",
+			resolved: sharedGalleryImage("1.2.3"),
+			image:    sharedGalleryImage("1.2.x"),
+			want:     true,
+		},
+		{
+			name:     "version outside of the range",
+			resolved: sharedGalleryImage("1.3.0"),
+			image:    sharedGalleryImage("1.2.x"),
+			want:     false,
+		},
+		{
+			name:     "different image",
+			resolved: sharedGalleryImage("1.2.3"),
+			image:    marketplaceImage("latest"),
+			want:     false,
+		},
+		{
+			name:     "unresolved version",
+			resolved: marketplaceImage("latest"),
+			image:    marketplaceImage("latest"),
+			want:     false,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			g.Expect(IsResolvedFrom(tc.resolved, tc.image)).To(Equal(tc.want))
+		})
+	}
+}
+
+func TestIsVersionRange(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(IsVersionRange(marketplaceImage("1.2.x"))).To(BeTrue())
+	g.Expect(IsVersionRange(sharedGalleryImage(">=1.2.0 <1.3.0"))).To(BeTrue())
+	g.Expect(IsVersionRange(marketplaceImage("latest"))).To(BeFalse())
+	g.Expect(IsVersionRange(sharedGalleryImage("1.2.3"))).To(BeFalse())
+	g.Expect(IsVersionRange(nil)).To(BeFalse())
+}
+
+func TestIsNewer(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(IsNewer(marketplaceImage("2022.01.05"), marketplaceImage("2021.12.10"))).To(BeTrue())
+	g.Expect(IsNewer(marketplaceImage("2021.12.10"), marketplaceImage("2022.01.05"))).To(BeFalse())
+	g.Expect(IsNewer(marketplaceImage("2022.01.05"), marketplaceImage("2022.1.5"))).To(BeFalse())
+	g.Expect(IsNewer(marketplaceImage("2022.01.05"), marketplaceImage("latest"))).To(BeTrue())
+}
+
+func TestIsUsableGalleryImageVersion(t *testing.T) {
+	version := func(state compute.GalleryProvisioningState, excludeFromLatest bool, regions ...string) compute.GalleryImageVersion {
+		targetRegions := make([]compute.TargetRegion, len(regions))
+		for i := range regions {
+			targetRegions[i] = compute.TargetRegion{Name: to.StringPtr(regions[i])}
+		}
+		return compute.GalleryImageVersion{
+			Name: to.StringPtr("1.2.3"),
+			GalleryImageVersionProperties: &compute.GalleryImageVersionProperties{
+				ProvisioningState: state,
+				PublishingProfile: &compute.GalleryImageVersionPublishingProfile{
+					ExcludeFromLatest: to.BoolPtr(excludeFromLatest),
+					TargetRegions:     &targetRegions,
+				},
+			},
+		}
+	}
+
+	g := NewWithT(t)
+	g.Expect(isUsableGalleryImageVersion(version(compute.GalleryProvisioningStateSucceeded, false, "East US", "West US 2"), "westus2")).To(BeTrue())
+	g.Expect(isUsableGalleryImageVersion(version(compute.GalleryProvisioningStateSucceeded, false, "East US"), "westus2")).To(BeFalse())
+	g.Expect(isUsableGalleryImageVersion(version(compute.GalleryProvisioningStateSucceeded, true, "West US 2"), "westus2")).To(BeFalse())
+	g.Expect(isUsableGalleryImageVersion(version(compute.GalleryProvisioningStateCreating, false, "West US 2"), "westus2")).To(BeFalse())
+}
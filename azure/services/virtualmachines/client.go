@@ -20,22 +20,37 @@ import (
 	"context"
 	"encoding/json"
 
-	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
 	"github.com/Azure/go-autorest/autorest"
 	azureautorest "github.com/Azure/go-autorest/autorest/azure"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/pkg/errors"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/converters"
 	"sigs.k8s.io/cluster-api-provider-azure/util/reconciler"
 	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
 )
 
+// Client wraps go-sdk.
+type Client interface {
+	Get(context.Context, azure.ResourceSpecGetter) (interface{}, error)
+	GetInstanceView(context.Context, azure.ResourceSpecGetter) (compute.VirtualMachineInstanceView, error)
+	ListAvailableSizes(context.Context, azure.ResourceSpecGetter) ([]string, error)
+	UpdateSizeAsync(context.Context, azure.ResourceSpecGetter, string, string) (*infrav1.Future, error)
+	DeallocateAsync(context.Context, azure.ResourceSpecGetter) (*infrav1.Future, error)
+	StartAsync(context.Context, azure.ResourceSpecGetter) (*infrav1.Future, error)
+	ActionAsync(context.Context, azure.ResourceSpecGetter, string, string) (*infrav1.Future, error)
+	IsDone(context.Context, azureautorest.FutureAPI) (bool, error)
+}
+
 // AzureClient contains the Azure go-sdk Client.
 type AzureClient struct {
 	virtualmachines compute.VirtualMachinesClient
 }
 
+var _ Client = &AzureClient{}
+
 // NewClient creates a new VM client from subscription ID.
 func NewClient(auth azure.Authorizer) *AzureClient {
 	c := newVirtualMachinesClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer())
@@ -64,6 +79,10 @@ func (ac *AzureClient) CreateOrUpdateAsync(ctx context.Context, spec azure.Resou
 	ctx, _, done := tele.StartSpanWithLogger(ctx, "virtualmachines.AzureClient.CreateOrUpdate")
 	defer done()
 
+	if update, ok := parameters.(compute.VirtualMachineUpdate); ok {
+		return ac.updateAsync(ctx, spec, update)
+	}
+
 	vm, ok := parameters.(compute.VirtualMachine)
 	if !ok {
 		return nil, nil, errors.Errorf("%T is not a compute.VirtualMachine", parameters)
@@ -88,6 +107,32 @@ func (ac *AzureClient) CreateOrUpdateAsync(ctx context.Context, spec azure.Resou
 	return result, nil, err
 }
 
+// updateAsync updates an existing virtual machine asynchronously with a PATCH request, so that only the properties
+// set in the update are sent to Azure. The future is tracked like the one of a PUT request, as both results are
+// read as the virtual machine.
+func (ac *AzureClient) updateAsync(ctx context.Context, spec azure.ResourceSpecGetter, update compute.VirtualMachineUpdate) (result interface{}, future azureautorest.FutureAPI, err error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "virtualmachines.AzureClient.updateAsync")
+	defer done()
+
+	updateFuture, err := ac.virtualmachines.Update(ctx, spec.ResourceGroupName(), spec.ResourceName(), update)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureCallTimeout)
+	defer cancel()
+
+	err = updateFuture.WaitForCompletionRef(ctx, ac.virtualmachines.Client)
+	if err != nil {
+		// if an error occurs, return the future.
+		// this means the long-running operation didn't finish in the specified timeout.
+		return nil, &updateFuture, err
+	}
+	result, err = updateFuture.Result(ac.virtualmachines)
+	// if the operation completed, return a nil future
+	return result, nil, err
+}
+
 // DeleteAsync deletes a virtual machine asynchronously. DeleteAsync sends a DELETE
 // request to Azure and if accepted without error, the func will return a Future which can be used to track the ongoing
 // progress of the operation.
@@ -115,6 +160,168 @@ func (ac *AzureClient) DeleteAsync(ctx context.Context, spec azure.ResourceSpecG
 	return nil, err
 }
 
+// GetInstanceView retrieves the run-time status of a virtual machine, including its power state.
+func (ac *AzureClient) GetInstanceView(ctx context.Context, spec azure.ResourceSpecGetter) (compute.VirtualMachineInstanceView, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "virtualmachines.AzureClient.GetInstanceView")
+	defer done()
+
+	return ac.virtualmachines.InstanceView(ctx, spec.ResourceGroupName(), spec.ResourceName())
+}
+
+// ListAvailableSizes returns the names of the sizes a virtual machine can be resized to on the hardware cluster
+// currently hosting it.
+func (ac *AzureClient) ListAvailableSizes(ctx context.Context, spec azure.ResourceSpecGetter) ([]string, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "virtualmachines.AzureClient.ListAvailableSizes")
+	defer done()
+
+	result, err := ac.virtualmachines.ListAvailableSizes(ctx, spec.ResourceGroupName(), spec.ResourceName())
+	if err != nil {
+		return nil, err
+	}
+
+	var sizes []string
+	if result.Value != nil {
+		for _, size := range *result.Value {
+			sizes = append(sizes, to.String(size.Name))
+		}
+	}
+	return sizes, nil
+}
+
+// UpdateSizeAsync changes the size of a virtual machine asynchronously. UpdateSizeAsync sends a PATCH
+// request to Azure and if accepted without error, the func will return a Future keyed by serviceName which can be used
+// to track the ongoing progress of the operation.
+func (ac *AzureClient) UpdateSizeAsync(ctx context.Context, spec azure.ResourceSpecGetter, size, serviceName string) (*infrav1.Future, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "virtualmachines.AzureClient.UpdateSizeAsync")
+	defer done()
+
+	parameters := compute.VirtualMachineUpdate{
+		VirtualMachineProperties: &compute.VirtualMachineProperties{
+			HardwareProfile: &compute.HardwareProfile{
+				VMSize: compute.VirtualMachineSizeTypes(size),
+			},
+		},
+	}
+	future, err := ac.virtualmachines.Update(ctx, spec.ResourceGroupName(), spec.ResourceName(), parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureCallTimeout)
+	defer cancel()
+
+	err = future.WaitForCompletionRef(ctx, ac.virtualmachines.Client)
+	if err != nil {
+		// if an error occurs, return the future.
+		// this means the long-running operation didn't finish in the specified timeout.
+		return converters.SDKToFuture(&future, infrav1.PatchFuture, serviceName, spec.ResourceName(), spec.ResourceGroupName())
+	}
+	_, err = future.Result(ac.virtualmachines)
+	// if the operation completed, return a nil future.
+	return nil, err
+}
+
+// DeallocateAsync shuts down a virtual machine and releases its compute resources asynchronously. DeallocateAsync sends a POST
+// request to Azure and if accepted without error, the func will return a Future which can be used to track the ongoing
+// progress of the operation. The Future is keyed as part of a resize that restarts the virtual machine afterwards.
+func (ac *AzureClient) DeallocateAsync(ctx context.Context, spec azure.ResourceSpecGetter) (*infrav1.Future, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "virtualmachines.AzureClient.DeallocateAsync")
+	defer done()
+
+	future, err := ac.virtualmachines.Deallocate(ctx, spec.ResourceGroupName(), spec.ResourceName(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureCallTimeout)
+	defer cancel()
+
+	err = future.WaitForCompletionRef(ctx, ac.virtualmachines.Client)
+	if err != nil {
+		// if an error occurs, return the future.
+		// this means the long-running operation didn't finish in the specified timeout.
+		return converters.SDKToFuture(&future, infrav1.PostFuture, resizeRestartServiceName, spec.ResourceName(), spec.ResourceGroupName())
+	}
+	_, err = future.Result(ac.virtualmachines)
+	// if the operation completed, return a nil future.
+	return nil, err
+}
+
+// StartAsync starts a virtual machine asynchronously. StartAsync sends a POST
+// request to Azure and if accepted without error, the func will return a Future which can be used to track the ongoing
+// progress of the operation.
+func (ac *AzureClient) StartAsync(ctx context.Context, spec azure.ResourceSpecGetter) (*infrav1.Future, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "virtualmachines.AzureClient.StartAsync")
+	defer done()
+
+	future, err := ac.virtualmachines.Start(ctx, spec.ResourceGroupName(), spec.ResourceName())
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureCallTimeout)
+	defer cancel()
+
+	err = future.WaitForCompletionRef(ctx, ac.virtualmachines.Client)
+	if err != nil {
+		// if an error occurs, return the future.
+		// this means the long-running operation didn't finish in the specified timeout.
+		return converters.SDKToFuture(&future, infrav1.PostFuture, resizeServiceName, spec.ResourceName(), spec.ResourceGroupName())
+	}
+	_, err = future.Result(ac.virtualmachines)
+	// if the operation completed, return a nil future.
+	return nil, err
+}
+
+// ActionAsync runs one of the azure.VMActions on a virtual machine asynchronously. ActionAsync sends a POST
+// request to Azure and if accepted without error, the func will return a Future which can be used to track the ongoing
+// progress of the operation. The future is keyed by serviceName, so that actions started for different purposes are
+// tracked separately.
+func (ac *AzureClient) ActionAsync(ctx context.Context, spec azure.ResourceSpecGetter, action, serviceName string) (*infrav1.Future, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "virtualmachines.AzureClient.ActionAsync")
+	defer done()
+
+	future, err := ac.startAction(ctx, spec.ResourceGroupName(), spec.ResourceName(), action)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureCallTimeout)
+	defer cancel()
+
+	err = future.WaitForCompletionRef(ctx, ac.virtualmachines.Client)
+	if err != nil {
+		// if an error occurs, return the future.
+		// this means the long-running operation didn't finish in the specified timeout.
+		return converters.SDKToFuture(future, infrav1.PostFuture, serviceName, spec.ResourceName(), spec.ResourceGroupName())
+	}
+	// if the operation completed, return a nil future.
+	return nil, nil
+}
+
+// startAction sends the POST request of an action to Azure and returns the future of the operation.
+func (ac *AzureClient) startAction(ctx context.Context, resourceGroupName, vmName, action string) (azureautorest.FutureAPI, error) {
+	switch action {
+	case azure.VMActionRestart:
+		future, err := ac.virtualmachines.Restart(ctx, resourceGroupName, vmName)
+		return future.FutureAPI, err
+	case azure.VMActionRedeploy:
+		future, err := ac.virtualmachines.Redeploy(ctx, resourceGroupName, vmName)
+		return future.FutureAPI, err
+	case azure.VMActionReimage:
+		future, err := ac.virtualmachines.Reimage(ctx, resourceGroupName, vmName, nil)
+		return future.FutureAPI, err
+	case azure.VMActionDeallocate:
+		future, err := ac.virtualmachines.Deallocate(ctx, resourceGroupName, vmName, nil)
+		return future.FutureAPI, err
+	case azure.VMActionStart:
+		future, err := ac.virtualmachines.Start(ctx, resourceGroupName, vmName)
+		return future.FutureAPI, err
+	default:
+		return nil, errors.Errorf("unknown VM action %q", action)
+	}
+}
+
 // IsDone returns true if the long-running operation has completed.
 func (ac *AzureClient) IsDone(ctx context.Context, future azureautorest.FutureAPI) (isDone bool, err error) {
 	ctx, _, done := tele.StartSpanWithLogger(ctx, "virtualmachines.AzureClient.IsDone")
@@ -19,3 +19,157 @@ limitations under the License.
 
 // Package mock_virtualmachines is a generated GoMock package.
 package mock_virtualmachines
+
+import (
+	context "context"
+	reflect "reflect"
+
+	compute "github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
+	azure "github.com/Azure/go-autorest/autorest/azure"
+	gomock "github.com/golang/mock/gomock"
+	v1beta1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	azure0 "sigs.k8s.io/cluster-api-provider-azure/azure"
+)
+
+// MockClient is a mock of Client interface.
+type MockClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockClientMockRecorder
+}
+
+// MockClientMockRecorder is the mock recorder for MockClient.
+type MockClientMockRecorder struct {
+	mock *MockClient
+}
+
+// NewMockClient creates a new mock instance.
+func NewMockClient(ctrl *gomock.Controller) *MockClient {
+	mock := &MockClient{ctrl: ctrl}
+	mock.recorder = &MockClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClient) EXPECT() *MockClientMockRecorder {
+	return m.recorder
+}
+
+// ActionAsync mocks base method.
+func (m *MockClient) ActionAsync(arg0 context.Context, arg1 azure0.ResourceSpecGetter, arg2, arg3 string) (*v1beta1.Future, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ActionAsync", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*v1beta1.Future)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ActionAsync indicates an expected call of ActionAsync.
+func (mr *MockClientMockRecorder) ActionAsync(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ActionAsync", reflect.TypeOf((*MockClient)(nil).ActionAsync), arg0, arg1, arg2, arg3)
+}
+
+// DeallocateAsync mocks base method.
+func (m *MockClient) DeallocateAsync(arg0 context.Context, arg1 azure0.ResourceSpecGetter) (*v1beta1.Future, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeallocateAsync", arg0, arg1)
+	ret0, _ := ret[0].(*v1beta1.Future)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeallocateAsync indicates an expected call of DeallocateAsync.
+func (mr *MockClientMockRecorder) DeallocateAsync(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeallocateAsync", reflect.TypeOf((*MockClient)(nil).DeallocateAsync), arg0, arg1)
+}
+
+// Get mocks base method.
+func (m *MockClient) Get(arg0 context.Context, arg1 azure0.ResourceSpecGetter) (interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0, arg1)
+	ret0, _ := ret[0].(interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockClientMockRecorder) Get(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockClient)(nil).Get), arg0, arg1)
+}
+
+// GetInstanceView mocks base method.
+func (m *MockClient) GetInstanceView(arg0 context.Context, arg1 azure0.ResourceSpecGetter) (compute.VirtualMachineInstanceView, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInstanceView", arg0, arg1)
+	ret0, _ := ret[0].(compute.VirtualMachineInstanceView)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInstanceView indicates an expected call of GetInstanceView.
+func (mr *MockClientMockRecorder) GetInstanceView(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInstanceView", reflect.TypeOf((*MockClient)(nil).GetInstanceView), arg0, arg1)
+}
+
+// IsDone mocks base method.
+func (m *MockClient) IsDone(arg0 context.Context, arg1 azure.FutureAPI) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsDone", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsDone indicates an expected call of IsDone.
+func (mr *MockClientMockRecorder) IsDone(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsDone", reflect.TypeOf((*MockClient)(nil).IsDone), arg0, arg1)
+}
+
+// ListAvailableSizes mocks base method.
+func (m *MockClient) ListAvailableSizes(arg0 context.Context, arg1 azure0.ResourceSpecGetter) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAvailableSizes", arg0, arg1)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAvailableSizes indicates an expected call of ListAvailableSizes.
+func (mr *MockClientMockRecorder) ListAvailableSizes(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAvailableSizes", reflect.TypeOf((*MockClient)(nil).ListAvailableSizes), arg0, arg1)
+}
+
+// StartAsync mocks base method.
+func (m *MockClient) StartAsync(arg0 context.Context, arg1 azure0.ResourceSpecGetter) (*v1beta1.Future, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartAsync", arg0, arg1)
+	ret0, _ := ret[0].(*v1beta1.Future)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartAsync indicates an expected call of StartAsync.
+func (mr *MockClientMockRecorder) StartAsync(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartAsync", reflect.TypeOf((*MockClient)(nil).StartAsync), arg0, arg1)
+}
+
+// UpdateSizeAsync mocks base method.
+func (m *MockClient) UpdateSizeAsync(arg0 context.Context, arg1 azure0.ResourceSpecGetter, arg2, arg3 string) (*v1beta1.Future, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSizeAsync", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*v1beta1.Future)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateSizeAsync indicates an expected call of UpdateSizeAsync.
+func (mr *MockClientMockRecorder) UpdateSizeAsync(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSizeAsync", reflect.TypeOf((*MockClient)(nil).UpdateSizeAsync), arg0, arg1, arg2, arg3)
+}
@@ -54,6 +54,20 @@ func (m *MockVMScope) EXPECT() *MockVMScopeMockRecorder {
 	return m.recorder
 }
 
+// Annotation mocks base method.
+func (m *MockVMScope) Annotation(arg0 string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Annotation", arg0)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Annotation indicates an expected call of Annotation.
+func (mr *MockVMScopeMockRecorder) Annotation(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Annotation", reflect.TypeOf((*MockVMScope)(nil).Annotation), arg0)
+}
+
 // Authorizer mocks base method.
 func (m *MockVMScope) Authorizer() autorest.Authorizer {
 	m.ctrl.T.Helper()
@@ -164,6 +178,46 @@ func (mr *MockVMScopeMockRecorder) HashKey() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HashKey", reflect.TypeOf((*MockVMScope)(nil).HashKey))
 }
 
+// IsHibernated mocks base method.
+func (m *MockVMScope) IsHibernated() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsHibernated")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsHibernated indicates an expected call of IsHibernated.
+func (mr *MockVMScopeMockRecorder) IsHibernated() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsHibernated", reflect.TypeOf((*MockVMScope)(nil).IsHibernated))
+}
+
+// RemoveAnnotation mocks base method.
+func (m *MockVMScope) RemoveAnnotation(arg0 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RemoveAnnotation", arg0)
+}
+
+// RemoveAnnotation indicates an expected call of RemoveAnnotation.
+func (mr *MockVMScopeMockRecorder) RemoveAnnotation(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveAnnotation", reflect.TypeOf((*MockVMScope)(nil).RemoveAnnotation), arg0)
+}
+
+// RemovedDataDisks mocks base method.
+func (m *MockVMScope) RemovedDataDisks() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemovedDataDisks")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// RemovedDataDisks indicates an expected call of RemovedDataDisks.
+func (mr *MockVMScopeMockRecorder) RemovedDataDisks() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemovedDataDisks", reflect.TypeOf((*MockVMScope)(nil).RemovedDataDisks))
+}
+
 // SetAddresses mocks base method.
 func (m *MockVMScope) SetAddresses(arg0 []v1.NodeAddress) {
 	m.ctrl.T.Helper()
@@ -212,6 +266,18 @@ func (mr *MockVMScopeMockRecorder) SetProviderID(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetProviderID", reflect.TypeOf((*MockVMScope)(nil).SetProviderID), arg0)
 }
 
+// SetRemovedDataDisks mocks base method.
+func (m *MockVMScope) SetRemovedDataDisks(arg0 []string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetRemovedDataDisks", arg0)
+}
+
+// SetRemovedDataDisks indicates an expected call of SetRemovedDataDisks.
+func (mr *MockVMScopeMockRecorder) SetRemovedDataDisks(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRemovedDataDisks", reflect.TypeOf((*MockVMScope)(nil).SetRemovedDataDisks), arg0)
+}
+
 // SetVMState mocks base method.
 func (m *MockVMScope) SetVMState(arg0 v1beta1.ProvisioningState) {
 	m.ctrl.T.Helper()
@@ -224,6 +290,20 @@ func (mr *MockVMScopeMockRecorder) SetVMState(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetVMState", reflect.TypeOf((*MockVMScope)(nil).SetVMState), arg0)
 }
 
+// ShouldHibernate mocks base method.
+func (m *MockVMScope) ShouldHibernate() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ShouldHibernate")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// ShouldHibernate indicates an expected call of ShouldHibernate.
+func (mr *MockVMScopeMockRecorder) ShouldHibernate() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShouldHibernate", reflect.TypeOf((*MockVMScope)(nil).ShouldHibernate))
+}
+
 // SubscriptionID mocks base method.
 func (m *MockVMScope) SubscriptionID() string {
 	m.ctrl.T.Helper()
@@ -264,6 +344,18 @@ func (mr *MockVMScopeMockRecorder) UpdateDeleteStatus(arg0, arg1, arg2 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDeleteStatus", reflect.TypeOf((*MockVMScope)(nil).UpdateDeleteStatus), arg0, arg1, arg2)
 }
 
+// UpdateHibernationStatus mocks base method.
+func (m *MockVMScope) UpdateHibernationStatus(arg0 error) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdateHibernationStatus", arg0)
+}
+
+// UpdateHibernationStatus indicates an expected call of UpdateHibernationStatus.
+func (mr *MockVMScopeMockRecorder) UpdateHibernationStatus(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHibernationStatus", reflect.TypeOf((*MockVMScope)(nil).UpdateHibernationStatus), arg0)
+}
+
 // UpdatePatchStatus mocks base method.
 func (m *MockVMScope) UpdatePatchStatus(arg0 v1beta10.ConditionType, arg1 string, arg2 error) {
 	m.ctrl.T.Helper()
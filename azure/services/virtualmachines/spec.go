@@ -19,40 +19,57 @@ package virtualmachines
 import (
 	"encoding/base64"
 	"fmt"
+	"strings"
 
-	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
+	azureautorest "github.com/Azure/go-autorest/autorest/azure"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/pkg/errors"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/converters"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/disks"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/resourceskus"
 	"sigs.k8s.io/cluster-api-provider-azure/util/generators"
 )
 
+// maxLun is the highest LUN a data disk can be attached at.
+const maxLun = 63
+
 // VMSpec defines the specification for a Virtual Machine.
 type VMSpec struct {
-	Name                   string
-	ResourceGroup          string
-	Location               string
-	ClusterName            string
-	Role                   string
-	NICIDs                 []string
-	SSHKeyData             string
-	Size                   string
-	AvailabilitySetID      string
-	Zone                   string
-	Identity               infrav1.VMIdentity
-	OSDisk                 infrav1.OSDisk
-	DataDisks              []infrav1.DataDisk
-	UserAssignedIdentities []infrav1.UserAssignedIdentity
-	SpotVMOptions          *infrav1.SpotVMOptions
-	SecurityProfile        *infrav1.SecurityProfile
-	AdditionalTags         infrav1.Tags
-	SKU                    resourceskus.SKU
-	Image                  *infrav1.Image
-	BootstrapData          string
-	ProviderID             string
+	Name                       string
+	ResourceGroup              string
+	Location                   string
+	ClusterName                string
+	Role                       string
+	NICIDs                     []string
+	SSHKeyData                 string
+	Size                       string
+	EnableInPlaceResize        bool
+	AvailabilitySetID          string
+	Zone                       string
+	Identity                   infrav1.VMIdentity
+	OSDisk                     infrav1.OSDisk
+	DataDisks                  []infrav1.DataDisk
+	DataDiskRemovalPolicy      infrav1.DataDiskRemovalPolicy
+	EnableUltraSSD             *bool
+	Diagnostics                *infrav1.Diagnostics
+	SubscriptionID             string
+	UserAssignedIdentities     []infrav1.UserAssignedIdentity
+	SpotVMOptions              *infrav1.SpotVMOptions
+	SecurityProfile            *infrav1.SecurityProfile
+	AdditionalTags             infrav1.Tags
+	SKU                        resourceskus.SKU
+	Image                      *infrav1.Image
+	BootstrapData              string
+	BootstrapDataFormat        string
+	BootstrapDataDelivery      infrav1.BootstrapDataDelivery
+	ProviderID                 string
+	CapacityReservationGroupID string
+
+	// detachedDataDisks are the names of the data disks detached by the update returned by Parameters.
+	detachedDataDisks []string
 }
 
 // ResourceName returns the name of the virtual machine.
@@ -73,11 +90,12 @@ func (s *VMSpec) OwnerResourceName() string {
 // Parameters returns the parameters for the virtual machine.
 func (s *VMSpec) Parameters(existing interface{}) (params interface{}, err error) {
 	if existing != nil {
-		if _, ok := existing.(compute.VirtualMachine); !ok {
+		existingVM, ok := existing.(compute.VirtualMachine)
+		if !ok {
 			return nil, errors.Errorf("%T is not a compute.VirtualMachine", existing)
 		}
-		// vm already exists
-		return nil, nil
+		// vm already exists, only its data disks can be updated
+		return s.updateDataDisks(existingVM)
 	}
 
 	// VM got deleted outside of capz, do not recreate it as Machines are immutable.
@@ -95,6 +113,10 @@ func (s *VMSpec) Parameters(existing interface{}) (params interface{}, err error
 		return nil, err
 	}
 
+	if err := azure.ValidateBootstrapData(s.BootstrapData, s.BootstrapDataFormat, s.BootstrapDataDelivery); err != nil {
+		return nil, azure.WithTerminalError(errors.Wrap(err, "invalid bootstrap data"))
+	}
+
 	osProfile, err := s.generateOSProfile()
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to generate OS Profile")
@@ -110,6 +132,11 @@ func (s *VMSpec) Parameters(existing interface{}) (params interface{}, err error
 		return nil, errors.Wrap(err, "failed to generate VM identity")
 	}
 
+	additionalCapabilities, err := s.generateAdditionalCapabilities()
+	if err != nil {
+		return nil, err
+	}
+
 	return compute.VirtualMachine{
 		Plan:     converters.ImageToPlan(s.Image),
 		Location: to.StringPtr(s.Location),
@@ -121,7 +148,7 @@ func (s *VMSpec) Parameters(existing interface{}) (params interface{}, err error
 			Additional:  s.AdditionalTags,
 		})),
 		VirtualMachineProperties: &compute.VirtualMachineProperties{
-			AdditionalCapabilities: s.generateAdditionalCapabilities(),
+			AdditionalCapabilities: additionalCapabilities,
 			AvailabilitySet:        s.getAvailabilitySet(),
 			HardwareProfile: &compute.HardwareProfile{
 				VMSize: compute.VirtualMachineSizeTypes(s.Size),
@@ -132,20 +159,38 @@ func (s *VMSpec) Parameters(existing interface{}) (params interface{}, err error
 			NetworkProfile: &compute.NetworkProfile{
 				NetworkInterfaces: s.generateNICRefs(),
 			},
-			Priority:       priority,
-			EvictionPolicy: evictionPolicy,
-			BillingProfile: billingProfile,
-			DiagnosticsProfile: &compute.DiagnosticsProfile{
-				BootDiagnostics: &compute.BootDiagnostics{
-					Enabled: to.BoolPtr(true),
-				},
-			},
+			Priority:            priority,
+			EvictionPolicy:      evictionPolicy,
+			BillingProfile:      billingProfile,
+			CapacityReservation: s.getCapacityReservation(),
+			DiagnosticsProfile:  converters.GetDiagnosticsProfile(s.Diagnostics),
+			UserData:            s.getUserData(),
 		},
 		Identity: identity,
 		Zones:    s.getZones(),
 	}, nil
 }
 
+// getUserData returns the bootstrap data when it is passed to the VM as user data.
+func (s *VMSpec) getUserData() *string {
+	if s.BootstrapDataDelivery != infrav1.BootstrapDataDeliveryUserData {
+		return nil
+	}
+	return to.StringPtr(s.BootstrapData)
+}
+
+// getCapacityReservation returns the capacity reservation profile binding the VM to its capacity reservation group, if any.
+func (s *VMSpec) getCapacityReservation() *compute.CapacityReservationProfile {
+	if s.CapacityReservationGroupID == "" {
+		return nil
+	}
+	return &compute.CapacityReservationProfile{
+		CapacityReservationGroup: &compute.SubResource{
+			ID: to.StringPtr(s.CapacityReservationGroupID),
+		},
+	}
+}
+
 // generateStorageProfile generates a pointer to a compute.StorageProfile which can utilized for VM creation.
 func (s *VMSpec) generateStorageProfile() (*compute.StorageProfile, error) {
 	storageProfile := &compute.StorageProfile{
@@ -185,6 +230,18 @@ func (s *VMSpec) generateStorageProfile() (*compute.StorageProfile, error) {
 		storageProfile.OsDisk.DiffDiskSettings = &compute.DiffDiskSettings{
 			Option: compute.DiffDiskOptions(s.OSDisk.DiffDiskSettings.Option),
 		}
+
+		if s.OSDisk.DiffDiskSettings.Placement != nil {
+			placement := string(*s.OSDisk.DiffDiskSettings.Placement)
+			placementCapability, err := s.SKU.HasEphemeralOSDiskPlacement(placement, to.Int32(s.OSDisk.DiskSizeGB))
+			if err != nil {
+				return nil, azure.WithTerminalError(errors.Wrap(err, "failed to validate the ephemeral os disk placement"))
+			}
+			if !placementCapability {
+				return nil, azure.WithTerminalError(fmt.Errorf("vm size %s does not have a %s large enough for the ephemeral os disk. select a different vm size, placement or os disk size", s.Size, placement))
+			}
+			storageProfile.OsDisk.DiffDiskSettings.Placement = compute.DiffDiskPlacement(placement)
+		}
 	}
 
 	if s.OSDisk.ManagedDisk != nil {
@@ -197,30 +254,24 @@ func (s *VMSpec) generateStorageProfile() (*compute.StorageProfile, error) {
 		}
 	}
 
-	dataDisks := make([]compute.DataDisk, len(s.DataDisks))
-	for i, disk := range s.DataDisks {
-		dataDisks[i] = compute.DataDisk{
-			CreateOption: compute.DiskCreateOptionTypesEmpty,
-			DiskSizeGB:   to.Int32Ptr(disk.DiskSizeGB),
-			Lun:          disk.Lun,
-			Name:         to.StringPtr(azure.GenerateDataDiskName(s.Name, disk.NameSuffix)),
-			Caching:      compute.CachingTypes(disk.CachingType),
+	// Checking if the requested VM size supports the number of data disks
+	if _, ok := s.SKU.GetCapability(resourceskus.MaxDataDiskCount); ok {
+		dataDiskCapability, err := s.SKU.HasCapabilityWithCapacity(resourceskus.MaxDataDiskCount, int64(len(s.DataDisks)))
+		if err != nil {
+			return nil, azure.WithTerminalError(errors.Wrap(err, "failed to validate the max data disk count capability"))
 		}
+		if !dataDiskCapability {
+			return nil, azure.WithTerminalError(fmt.Errorf("vm size %s does not support %d data disks", s.Size, len(s.DataDisks)))
+		}
+	}
 
-		if disk.ManagedDisk != nil {
-			dataDisks[i].ManagedDisk = &compute.ManagedDiskParameters{
-				StorageAccountType: compute.StorageAccountTypes(disk.ManagedDisk.StorageAccountType),
-			}
-
-			if disk.ManagedDisk.DiskEncryptionSet != nil {
-				dataDisks[i].ManagedDisk.DiskEncryptionSet = &compute.DiskEncryptionSetParameters{ID: to.StringPtr(disk.ManagedDisk.DiskEncryptionSet.ID)}
-			}
-
-			// check the support for ultra disks based on location and vm size
-			if disk.ManagedDisk.StorageAccountType == string(compute.StorageAccountTypesUltraSSDLRS) && !s.SKU.HasLocationCapability(resourceskus.UltraSSDAvailable, s.Location, s.Zone) {
-				return nil, azure.WithTerminalError(fmt.Errorf("vm size %s does not support ultra disks in location %s. select a different vm size or disable ultra disks", s.Size, s.Location))
-			}
+	dataDisks := make([]compute.DataDisk, len(s.DataDisks))
+	for i, disk := range s.DataDisks {
+		dataDisk, err := s.generateDataDisk(disk)
+		if err != nil {
+			return nil, err
 		}
+		dataDisks[i] = dataDisk
 	}
 	storageProfile.DataDisks = &dataDisks
 
@@ -234,6 +285,170 @@ func (s *VMSpec) generateStorageProfile() (*compute.StorageProfile, error) {
 	return storageProfile, nil
 }
 
+// generateDataDisk generates a compute.DataDisk for the given data disk. Existing and standalone disks are attached
+// to the VM, while the other disks are created as empty managed disks along with it.
+func (s *VMSpec) generateDataDisk(disk infrav1.DataDisk) (compute.DataDisk, error) {
+	if disk.ManagedDisk != nil {
+		// check the support for ultra disks based on location and vm size
+		if disk.ManagedDisk.StorageAccountType == string(compute.StorageAccountTypesUltraSSDLRS) && !s.SKU.HasLocationCapability(resourceskus.UltraSSDAvailable, s.Location, s.Zone) {
+			return compute.DataDisk{}, azure.WithTerminalError(fmt.Errorf("vm size %s does not support ultra disks in location %s. select a different vm size or disable ultra disks", s.Size, s.Location))
+		}
+
+		// check the support for premium v2 disks based on vm size
+		if disk.ManagedDisk.StorageAccountType == infrav1.StorageAccountTypePremiumV2LRS && !s.SKU.HasCapability(resourceskus.PremiumIO) {
+			return compute.DataDisk{}, azure.WithTerminalError(fmt.Errorf("vm size %s does not support premium storage. select a different vm size or storage account type", s.Size))
+		}
+	}
+
+	name, err := s.dataDiskName(disk)
+	if err != nil {
+		return compute.DataDisk{}, err
+	}
+
+	dataDisk := compute.DataDisk{
+		Lun:     disk.Lun,
+		Name:    to.StringPtr(name),
+		Caching: compute.CachingTypes(disk.CachingType),
+	}
+
+	switch {
+	case disk.SourceDiskID != nil:
+		dataDisk.CreateOption = compute.DiskCreateOptionTypesAttach
+		dataDisk.ManagedDisk = &compute.ManagedDiskParameters{ID: disk.SourceDiskID}
+		return dataDisk, nil
+	case disks.IsStandaloneDataDisk(disk):
+		dataDisk.CreateOption = compute.DiskCreateOptionTypesAttach
+		dataDisk.ManagedDisk = &compute.ManagedDiskParameters{ID: to.StringPtr(azure.DiskID(s.SubscriptionID, s.ResourceGroup, name))}
+		return dataDisk, nil
+	}
+
+	dataDisk.CreateOption = compute.DiskCreateOptionTypesEmpty
+	dataDisk.DiskSizeGB = to.Int32Ptr(disk.DiskSizeGB)
+
+	if disk.ManagedDisk != nil {
+		dataDisk.ManagedDisk = &compute.ManagedDiskParameters{
+			StorageAccountType: compute.StorageAccountTypes(disk.ManagedDisk.StorageAccountType),
+		}
+
+		if disk.ManagedDisk.DiskEncryptionSet != nil {
+			dataDisk.ManagedDisk.DiskEncryptionSet = &compute.DiskEncryptionSetParameters{ID: to.StringPtr(disk.ManagedDisk.DiskEncryptionSet.ID)}
+		}
+	}
+
+	return dataDisk, nil
+}
+
+// dataDiskName returns the name of the managed disk backing the given data disk.
+func (s *VMSpec) dataDiskName(disk infrav1.DataDisk) (string, error) {
+	if disk.SourceDiskID == nil {
+		return azure.GenerateDataDiskName(s.Name, disk.NameSuffix), nil
+	}
+	resource, err := azureautorest.ParseResourceID(*disk.SourceDiskID)
+	if err != nil {
+		return "", azure.WithTerminalError(errors.Wrapf(err, "failed to parse source disk ID %s", *disk.SourceDiskID))
+	}
+	return resource.ResourceName, nil
+}
+
+// updateDataDisks returns a VM update that attaches the data disks added to the spec at their LUN and, if a removal
+// policy is set, detaches the data disks removed from the spec. Only the data disks created by CAPZ for this VM are
+// ever detached. The update only holds the data disks of the storage profile, so that the rest of the VM is left as is.
+// It returns nil if the data disks of the VM are up to date.
+func (s *VMSpec) updateDataDisks(existing compute.VirtualMachine) (interface{}, error) {
+	if existing.VirtualMachineProperties == nil || existing.StorageProfile == nil {
+		return nil, nil
+	}
+
+	wanted := make(map[string]bool, len(s.DataDisks))
+	for _, disk := range s.DataDisks {
+		name, err := s.dataDiskName(disk)
+		if err != nil {
+			return nil, err
+		}
+		wanted[name] = true
+	}
+
+	var current []compute.DataDisk
+	if existing.StorageProfile.DataDisks != nil {
+		current = *existing.StorageProfile.DataDisks
+	}
+
+	prefix := azure.GenerateDataDiskName(s.Name, "")
+	changed := false
+	attached := make(map[string]bool, len(current))
+	usedLuns := make(map[int32]string, len(current))
+	dataDisks := make([]compute.DataDisk, 0, len(current)+len(s.DataDisks))
+	for _, disk := range current {
+		name := to.String(disk.Name)
+		if s.DataDiskRemovalPolicy != "" && !wanted[name] && strings.HasPrefix(name, prefix) && !to.Bool(disk.ToBeDetached) {
+			disk.ToBeDetached = to.BoolPtr(true)
+			s.detachedDataDisks = append(s.detachedDataDisks, name)
+			changed = true
+		}
+		attached[name] = true
+		if disk.Lun != nil {
+			usedLuns[*disk.Lun] = name
+		}
+		dataDisks = append(dataDisks, disk)
+	}
+
+	// Data disks are attached at the LUN set in the spec, and only the ones without a LUN get the lowest free one once
+	// all the LUNs set in the spec are claimed.
+	var withoutLun []compute.DataDisk
+	for _, disk := range s.DataDisks {
+		dataDisk, err := s.generateDataDisk(disk)
+		if err != nil {
+			return nil, err
+		}
+		name := to.String(dataDisk.Name)
+		if attached[name] {
+			continue
+		}
+		if dataDisk.Lun == nil {
+			withoutLun = append(withoutLun, dataDisk)
+			continue
+		}
+		if used, ok := usedLuns[*dataDisk.Lun]; ok {
+			return nil, azure.WithTerminalError(errors.Errorf("cannot attach data disk %s at LUN %d, which is already used by data disk %s", name, *dataDisk.Lun, used))
+		}
+		usedLuns[*dataDisk.Lun] = name
+		dataDisks = append(dataDisks, dataDisk)
+		changed = true
+	}
+	for _, dataDisk := range withoutLun {
+		lun, err := freeLun(usedLuns)
+		if err != nil {
+			return nil, err
+		}
+		dataDisk.Lun = to.Int32Ptr(lun)
+		usedLuns[lun] = to.String(dataDisk.Name)
+		dataDisks = append(dataDisks, dataDisk)
+		changed = true
+	}
+
+	if !changed {
+		return nil, nil
+	}
+
+	return compute.VirtualMachineUpdate{
+		VirtualMachineProperties: &compute.VirtualMachineProperties{
+			StorageProfile: &compute.StorageProfile{
+				DataDisks: &dataDisks,
+			},
+		},
+	}, nil
+}
+
+// freeLun returns the lowest LUN that is not in use.
+func freeLun(usedLuns map[int32]string) (int32, error) {
+	for lun := int32(0); lun <= maxLun; lun++ {
+		if _, ok := usedLuns[lun]; !ok {
+			return lun, nil
+		}
+	}
+	return 0, azure.WithTerminalError(errors.New("no free LUN left to attach the data disk"))
+}
+
 func (s *VMSpec) generateOSProfile() (*compute.OSProfile, error) {
 	sshKey, err := base64.StdEncoding.DecodeString(s.SSHKeyData)
 	if err != nil {
@@ -243,7 +458,9 @@ func (s *VMSpec) generateOSProfile() (*compute.OSProfile, error) {
 	osProfile := &compute.OSProfile{
 		ComputerName:  to.StringPtr(s.Name),
 		AdminUsername: to.StringPtr(azure.DefaultUserName),
-		CustomData:    to.StringPtr(s.BootstrapData),
+	}
+	if s.BootstrapDataDelivery != infrav1.BootstrapDataDeliveryUserData {
+		osProfile.CustomData = to.StringPtr(s.BootstrapData)
 	}
 
 	switch s.OSDisk.OSType {
@@ -304,7 +521,16 @@ func (s *VMSpec) generateNICRefs() *[]compute.NetworkInterfaceReference {
 	return &nicRefs
 }
 
-func (s *VMSpec) generateAdditionalCapabilities() *compute.AdditionalCapabilities {
+func (s *VMSpec) generateAdditionalCapabilities() (*compute.AdditionalCapabilities, error) {
+	if s.EnableUltraSSD != nil {
+		if *s.EnableUltraSSD && !s.SKU.HasLocationCapability(resourceskus.UltraSSDAvailable, s.Location, s.Zone) {
+			return nil, azure.WithTerminalError(fmt.Errorf("vm size %s does not support ultra disks in location %s. select a different vm size or disable ultra disks", s.Size, s.Location))
+		}
+		return &compute.AdditionalCapabilities{
+			UltraSSDEnabled: to.BoolPtr(*s.EnableUltraSSD),
+		}, nil
+	}
+
 	var capabilities *compute.AdditionalCapabilities
 	for _, dataDisk := range s.DataDisks {
 		if dataDisk.ManagedDisk != nil && dataDisk.ManagedDisk.StorageAccountType == string(compute.StorageAccountTypesUltraSSDLRS) {
@@ -314,7 +540,7 @@ func (s *VMSpec) generateAdditionalCapabilities() *compute.AdditionalCapabilitie
 			break
 		}
 	}
-	return capabilities
+	return capabilities, nil
 }
 
 func (s *VMSpec) getAvailabilitySet() *compute.SubResource {
@@ -19,7 +19,7 @@ package virtualmachines
 import (
 	"testing"
 
-	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
 	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-02-01/network"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/google/go-cmp/cmp"
@@ -90,6 +90,10 @@ var (
 				Name:  to.StringPtr(resourceskus.EphemeralOSDisk),
 				Value: to.StringPtr("True"),
 			},
+			{
+				Name:  to.StringPtr(resourceskus.MaxResourceVolumeMB),
+				Value: to.StringPtr("153600"),
+			},
 		},
 	}
 
@@ -191,6 +195,107 @@ func TestParameters(t *testing.T) {
 			},
 			expectedError: "",
 		},
+		{
+			name: "returns nil if the data disks of an existing vm are up to date",
+			spec: &VMSpec{
+				Name:                  "my-vm",
+				DataDisks:             []infrav1.DataDisk{{NameSuffix: "disk1", DiskSizeGB: 64, Lun: to.Int32Ptr(0)}},
+				DataDiskRemovalPolicy: infrav1.DataDiskRemovalPolicyRetain,
+			},
+			existing: existingVMWithDataDisks(
+				compute.DataDisk{Name: to.StringPtr("my-vm_disk1"), Lun: to.Int32Ptr(0)},
+				compute.DataDisk{Name: to.StringPtr("pvc-1234"), Lun: to.Int32Ptr(1)},
+			),
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(BeNil())
+			},
+			expectedError: "",
+		},
+		{
+			name: "attaches data disks added to an existing vm at their lun",
+			spec: &VMSpec{
+				Name: "my-vm",
+				DataDisks: []infrav1.DataDisk{
+					{NameSuffix: "disk1", DiskSizeGB: 64, Lun: to.Int32Ptr(0)},
+					{NameSuffix: "disk2", DiskSizeGB: 128, Lun: to.Int32Ptr(3), CachingType: "ReadWrite"},
+					{NameSuffix: "disk3", DiskSizeGB: 256},
+					{NameSuffix: "disk4", DiskSizeGB: 32, Lun: to.Int32Ptr(2)},
+				},
+			},
+			existing: existingVMWithDataDisks(
+				compute.DataDisk{Name: to.StringPtr("my-vm_disk1"), Lun: to.Int32Ptr(0)},
+				compute.DataDisk{Name: to.StringPtr("pvc-1234"), Lun: to.Int32Ptr(1)},
+			),
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(Equal(compute.VirtualMachineUpdate{
+					VirtualMachineProperties: &compute.VirtualMachineProperties{
+						StorageProfile: &compute.StorageProfile{
+							DataDisks: &[]compute.DataDisk{
+								{Name: to.StringPtr("my-vm_disk1"), Lun: to.Int32Ptr(0)},
+								{Name: to.StringPtr("pvc-1234"), Lun: to.Int32Ptr(1)},
+								{Name: to.StringPtr("my-vm_disk2"), Lun: to.Int32Ptr(3), DiskSizeGB: to.Int32Ptr(128), CreateOption: compute.DiskCreateOptionTypesEmpty, Caching: compute.CachingTypesReadWrite},
+								{Name: to.StringPtr("my-vm_disk4"), Lun: to.Int32Ptr(2), DiskSizeGB: to.Int32Ptr(32), CreateOption: compute.DiskCreateOptionTypesEmpty},
+								{Name: to.StringPtr("my-vm_disk3"), Lun: to.Int32Ptr(4), DiskSizeGB: to.Int32Ptr(256), CreateOption: compute.DiskCreateOptionTypesEmpty},
+							},
+						},
+					},
+				}))
+			},
+			expectedError: "",
+		},
+		{
+			name: "fails if a data disk added to an existing vm uses the lun of an attached disk",
+			spec: &VMSpec{
+				Name: "my-vm",
+				DataDisks: []infrav1.DataDisk{
+					{NameSuffix: "disk1", DiskSizeGB: 64, Lun: to.Int32Ptr(0)},
+					{NameSuffix: "disk2", DiskSizeGB: 128, Lun: to.Int32Ptr(1)},
+				},
+			},
+			existing: existingVMWithDataDisks(
+				compute.DataDisk{Name: to.StringPtr("my-vm_disk1"), Lun: to.Int32Ptr(0)},
+				compute.DataDisk{Name: to.StringPtr("pvc-1234"), Lun: to.Int32Ptr(1)},
+			),
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(BeNil())
+			},
+			expectedError: "reconcile error that cannot be recovered occurred: cannot attach data disk my-vm_disk2 at LUN 1, which is already used by data disk pvc-1234. Object will not be requeued",
+		},
+		{
+			name: "detaches data disks removed from an existing vm if a removal policy is set",
+			spec: &VMSpec{
+				Name:                  "my-vm",
+				DataDisks:             []infrav1.DataDisk{{NameSuffix: "disk2", DiskSizeGB: 64, Lun: to.Int32Ptr(2)}},
+				DataDiskRemovalPolicy: infrav1.DataDiskRemovalPolicyDelete,
+			},
+			existing: existingVMWithDataDisks(
+				compute.DataDisk{Name: to.StringPtr("my-vm_disk1"), Lun: to.Int32Ptr(0)},
+				compute.DataDisk{Name: to.StringPtr("pvc-1234"), Lun: to.Int32Ptr(1)},
+				compute.DataDisk{Name: to.StringPtr("my-vm_disk2"), Lun: to.Int32Ptr(2)},
+			),
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(BeAssignableToTypeOf(compute.VirtualMachineUpdate{}))
+				g.Expect(*result.(compute.VirtualMachineUpdate).StorageProfile.DataDisks).To(Equal([]compute.DataDisk{
+					{Name: to.StringPtr("my-vm_disk1"), Lun: to.Int32Ptr(0), ToBeDetached: to.BoolPtr(true)},
+					{Name: to.StringPtr("pvc-1234"), Lun: to.Int32Ptr(1)},
+					{Name: to.StringPtr("my-vm_disk2"), Lun: to.Int32Ptr(2)},
+				}))
+			},
+			expectedError: "",
+		},
+		{
+			name: "does not detach data disks removed from an existing vm without a removal policy",
+			spec: &VMSpec{
+				Name: "my-vm",
+			},
+			existing: existingVMWithDataDisks(
+				compute.DataDisk{Name: to.StringPtr("my-vm_disk1"), Lun: to.Int32Ptr(0)},
+			),
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(BeNil())
+			},
+			expectedError: "",
+		},
 		{
 			name: "fails if vm deleted out of band, should not recreate",
 			spec: &VMSpec{
@@ -241,7 +346,7 @@ func TestParameters(t *testing.T) {
 			expect: func(g *WithT, result interface{}) {
 				g.Expect(result).To(BeAssignableToTypeOf(compute.VirtualMachine{}))
 				g.Expect(result.(compute.VirtualMachine).Identity.Type).To(Equal(compute.ResourceIdentityTypeUserAssigned))
-				g.Expect(result.(compute.VirtualMachine).Identity.UserAssignedIdentities).To(Equal(map[string]*compute.VirtualMachineIdentityUserAssignedIdentitiesValue{"my-user-id": {}}))
+				g.Expect(result.(compute.VirtualMachine).Identity.UserAssignedIdentities).To(Equal(map[string]*compute.UserAssignedIdentitiesValue{"my-user-id": {}}))
 			},
 			expectedError: "",
 		},
@@ -261,7 +366,7 @@ func TestParameters(t *testing.T) {
 			existing: nil,
 			expect: func(g *WithT, result interface{}) {
 				g.Expect(result).To(BeAssignableToTypeOf(compute.VirtualMachine{}))
-				g.Expect(result.(compute.VirtualMachine).Priority).To(Equal(compute.VirtualMachinePriorityTypesSpot))
+				g.Expect(result.(compute.VirtualMachine).Priority).To(Equal(compute.Spot))
 				g.Expect(result.(compute.VirtualMachine).EvictionPolicy).To(Equal(compute.VirtualMachineEvictionPolicyTypesDeallocate))
 				g.Expect(result.(compute.VirtualMachine).BillingProfile).To(BeNil())
 			},
@@ -379,7 +484,7 @@ func TestParameters(t *testing.T) {
 						StorageAccountType: "Premium_LRS",
 					},
 					DiffDiskSettings: &infrav1.DiffDiskSettings{
-						Option: string(compute.DiffDiskOptionsLocal),
+						Option: string(compute.Local),
 					},
 				},
 				Image: &infrav1.Image{ID: to.StringPtr("fake-image-id")},
@@ -388,10 +493,160 @@ func TestParameters(t *testing.T) {
 			existing: nil,
 			expect: func(g *WithT, result interface{}) {
 				g.Expect(result).To(BeAssignableToTypeOf(compute.VirtualMachine{}))
-				g.Expect(result.(compute.VirtualMachine).StorageProfile.OsDisk.DiffDiskSettings.Option).To(Equal(compute.DiffDiskOptionsLocal))
+				g.Expect(result.(compute.VirtualMachine).StorageProfile.OsDisk.DiffDiskSettings.Option).To(Equal(compute.Local))
+			},
+			expectedError: "",
+		},
+		{
+			name: "can create a vm with EphemeralOSDisk on the resource disk",
+			spec: &VMSpec{
+				Name:       "my-vm",
+				Role:       infrav1.Node,
+				NICIDs:     []string{"my-nic"},
+				SSHKeyData: "fakesshpublickey",
+				Size:       "Standard_D2v3",
+				OSDisk: infrav1.OSDisk{
+					OSType:     "Linux",
+					DiskSizeGB: to.Int32Ptr(128),
+					DiffDiskSettings: &infrav1.DiffDiskSettings{
+						Option:    string(compute.Local),
+						Placement: diffDiskPlacementPtr(infrav1.DiffDiskPlacementResourceDisk),
+					},
+				},
+				Image: &infrav1.Image{ID: to.StringPtr("fake-image-id")},
+				SKU:   validSKUWithEphemeralOS,
+			},
+			existing: nil,
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(BeAssignableToTypeOf(compute.VirtualMachine{}))
+				g.Expect(result.(compute.VirtualMachine).StorageProfile.OsDisk.DiffDiskSettings).To(Equal(&compute.DiffDiskSettings{
+					Option:    compute.Local,
+					Placement: compute.ResourceDisk,
+				}))
+			},
+			expectedError: "",
+		},
+		{
+			name: "cannot create vm with EphemeralOSDisk on a disk that is too small",
+			spec: &VMSpec{
+				Name:       "my-vm",
+				Role:       infrav1.Node,
+				NICIDs:     []string{"my-nic"},
+				SSHKeyData: "fakesshpublickey",
+				Size:       "Standard_D2v3",
+				OSDisk: infrav1.OSDisk{
+					OSType:     "Linux",
+					DiskSizeGB: to.Int32Ptr(256),
+					DiffDiskSettings: &infrav1.DiffDiskSettings{
+						Option:    string(compute.Local),
+						Placement: diffDiskPlacementPtr(infrav1.DiffDiskPlacementResourceDisk),
+					},
+				},
+				Image: &infrav1.Image{ID: to.StringPtr("fake-image-id")},
+				SKU:   validSKUWithEphemeralOS,
+			},
+			existing: nil,
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(BeNil())
+			},
+			expectedError: "reconcile error that cannot be recovered occurred: vm size Standard_D2v3 does not have a ResourceDisk large enough for the ephemeral os disk. select a different vm size, placement or os disk size. Object will not be requeued",
+		},
+		{
+			name: "cannot create vm with EphemeralOSDisk on a disk the vm size doesn't have",
+			spec: &VMSpec{
+				Name:       "my-vm",
+				Role:       infrav1.Node,
+				NICIDs:     []string{"my-nic"},
+				SSHKeyData: "fakesshpublickey",
+				Size:       "Standard_D2v3",
+				OSDisk: infrav1.OSDisk{
+					OSType:     "Linux",
+					DiskSizeGB: to.Int32Ptr(30),
+					DiffDiskSettings: &infrav1.DiffDiskSettings{
+						Option:    string(compute.Local),
+						Placement: diffDiskPlacementPtr(infrav1.DiffDiskPlacementCacheDisk),
+					},
+				},
+				Image: &infrav1.Image{ID: to.StringPtr("fake-image-id")},
+				SKU:   validSKUWithEphemeralOS,
+			},
+			existing: nil,
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(BeNil())
+			},
+			expectedError: "reconcile error that cannot be recovered occurred: vm size Standard_D2v3 does not have a CacheDisk large enough for the ephemeral os disk. select a different vm size, placement or os disk size. Object will not be requeued",
+		},
+		{
+			name: "can create a vm with user-managed boot diagnostics",
+			spec: &VMSpec{
+				Name:       "my-vm",
+				Role:       infrav1.Node,
+				NICIDs:     []string{"my-nic"},
+				SSHKeyData: "fakesshpublickey",
+				Size:       "Standard_D2v3",
+				Image:      &infrav1.Image{ID: to.StringPtr("fake-image-id")},
+				Diagnostics: &infrav1.Diagnostics{
+					Boot: &infrav1.BootDiagnostics{
+						StorageAccountType: infrav1.UserManagedDiagnosticsStorage,
+						UserManaged: &infrav1.UserManagedBootDiagnostics{
+							StorageAccountURI: "https://mystorageaccount.blob.core.windows.net/",
+						},
+					},
+				},
+				SKU: validSKU,
+			},
+			existing: nil,
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(BeAssignableToTypeOf(compute.VirtualMachine{}))
+				g.Expect(result.(compute.VirtualMachine).DiagnosticsProfile.BootDiagnostics).To(Equal(&compute.BootDiagnostics{
+					Enabled:    to.BoolPtr(true),
+					StorageURI: to.StringPtr("https://mystorageaccount.blob.core.windows.net/"),
+				}))
+			},
+			expectedError: "",
+		},
+		{
+			name: "can create a vm with ignition bootstrap data passed as user data",
+			spec: &VMSpec{
+				Name:                  "my-vm",
+				Role:                  infrav1.Node,
+				NICIDs:                []string{"my-nic"},
+				SSHKeyData:            "fakesshpublickey",
+				Size:                  "Standard_D2v3",
+				Image:                 &infrav1.Image{ID: to.StringPtr("fake-image-id")},
+				BootstrapData:         "eyJpZ25pdGlvbiI6e319",
+				BootstrapDataFormat:   azure.IgnitionBootstrapDataFormat,
+				BootstrapDataDelivery: infrav1.BootstrapDataDeliveryUserData,
+				SKU:                   validSKU,
+			},
+			existing: nil,
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(BeAssignableToTypeOf(compute.VirtualMachine{}))
+				g.Expect(result.(compute.VirtualMachine).UserData).To(Equal(to.StringPtr("eyJpZ25pdGlvbiI6e319")))
+				g.Expect(result.(compute.VirtualMachine).OsProfile.CustomData).To(BeNil())
 			},
 			expectedError: "",
 		},
+		{
+			name: "cannot create a vm with cloud-config bootstrap data passed as user data",
+			spec: &VMSpec{
+				Name:                  "my-vm",
+				Role:                  infrav1.Node,
+				NICIDs:                []string{"my-nic"},
+				SSHKeyData:            "fakesshpublickey",
+				Size:                  "Standard_D2v3",
+				Image:                 &infrav1.Image{ID: to.StringPtr("fake-image-id")},
+				BootstrapData:         "I2Nsb3VkLWNvbmZpZw==",
+				BootstrapDataFormat:   azure.CloudConfigBootstrapDataFormat,
+				BootstrapDataDelivery: infrav1.BootstrapDataDeliveryUserData,
+				SKU:                   validSKU,
+			},
+			existing: nil,
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(BeNil())
+			},
+			expectedError: "reconcile error that cannot be recovered occurred: invalid bootstrap data: cloud-config bootstrap data must be passed as custom data. Object will not be requeued",
+		},
 		{
 			name: "creating a vm with encryption at host enabled for unsupported VM type fails",
 			spec: &VMSpec{
@@ -427,7 +682,7 @@ func TestParameters(t *testing.T) {
 						StorageAccountType: "Premium_LRS",
 					},
 					DiffDiskSettings: &infrav1.DiffDiskSettings{
-						Option: string(compute.DiffDiskOptionsLocal),
+						Option: string(compute.Local),
 					},
 				},
 				Image: &infrav1.Image{ID: to.StringPtr("fake-image-id")},
@@ -659,6 +914,170 @@ func TestParameters(t *testing.T) {
 			},
 			expectedError: "reconcile error that cannot be recovered occurred: vm size Standard_D2v3 does not support ultra disks in location test-location. select a different vm size or disable ultra disks. Object will not be requeued",
 		},
+		{
+			name: "can create a vm with existing and standalone data disks attached",
+			spec: &VMSpec{
+				Name:           "my-vm",
+				ResourceGroup:  "my-rg",
+				SubscriptionID: "123",
+				Role:           infrav1.Node,
+				NICIDs:         []string{"my-nic"},
+				SSHKeyData:     "fakesshpublickey",
+				Size:           "Standard_D2v3",
+				Location:       "test-location",
+				Zone:           "1",
+				Image:          &infrav1.Image{ID: to.StringPtr("fake-image-id")},
+				DataDisks: []infrav1.DataDisk{
+					{
+						NameSuffix:   "existing",
+						SourceDiskID: to.StringPtr("/subscriptions/123/resourceGroups/other-rg/providers/Microsoft.Compute/disks/my-existing-disk"),
+						Lun:          to.Int32Ptr(0),
+					},
+					{
+						NameSuffix:        "ultra",
+						DiskSizeGB:        128,
+						Lun:               to.Int32Ptr(1),
+						DiskIOPSReadWrite: to.Int64Ptr(4000),
+						DiskMBpsReadWrite: to.Int64Ptr(200),
+						ManagedDisk: &infrav1.ManagedDiskParameters{
+							StorageAccountType: "UltraSSD_LRS",
+						},
+					},
+				},
+				SKU: validSKUWithUltraSSD,
+			},
+			existing: nil,
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(BeAssignableToTypeOf(compute.VirtualMachine{}))
+				g.Expect(*result.(compute.VirtualMachine).StorageProfile.DataDisks).To(Equal([]compute.DataDisk{
+					{
+						Lun:          to.Int32Ptr(0),
+						Name:         to.StringPtr("my-existing-disk"),
+						CreateOption: compute.DiskCreateOptionTypesAttach,
+						ManagedDisk: &compute.ManagedDiskParameters{
+							ID: to.StringPtr("/subscriptions/123/resourceGroups/other-rg/providers/Microsoft.Compute/disks/my-existing-disk"),
+						},
+					},
+					{
+						Lun:          to.Int32Ptr(1),
+						Name:         to.StringPtr("my-vm_ultra"),
+						CreateOption: compute.DiskCreateOptionTypesAttach,
+						ManagedDisk: &compute.ManagedDiskParameters{
+							ID: to.StringPtr("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/disks/my-vm_ultra"),
+						},
+					},
+				}))
+			},
+			expectedError: "",
+		},
+		{
+			name: "returns nil if an existing data disk is already attached to an existing vm",
+			spec: &VMSpec{
+				Name: "my-vm",
+				DataDisks: []infrav1.DataDisk{
+					{
+						SourceDiskID: to.StringPtr("/subscriptions/123/resourceGroups/other-rg/providers/Microsoft.Compute/disks/my-existing-disk"),
+						Lun:          to.Int32Ptr(0),
+					},
+				},
+				DataDiskRemovalPolicy: infrav1.DataDiskRemovalPolicyDelete,
+			},
+			existing: existingVMWithDataDisks(
+				compute.DataDisk{Name: to.StringPtr("my-existing-disk"), Lun: to.Int32Ptr(0)},
+			),
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(BeNil())
+			},
+			expectedError: "",
+		},
+		{
+			name: "can create a vm with ultra disks explicitly enabled",
+			spec: &VMSpec{
+				Name:           "my-vm",
+				Role:           infrav1.Node,
+				NICIDs:         []string{"my-nic"},
+				SSHKeyData:     "fakesshpublickey",
+				Size:           "Standard_D2v3",
+				Location:       "test-location",
+				Zone:           "1",
+				Image:          &infrav1.Image{ID: to.StringPtr("fake-image-id")},
+				EnableUltraSSD: to.BoolPtr(true),
+				SKU:            validSKUWithUltraSSD,
+			},
+			existing: nil,
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(BeAssignableToTypeOf(compute.VirtualMachine{}))
+				g.Expect(result.(compute.VirtualMachine).AdditionalCapabilities.UltraSSDEnabled).To(Equal(to.BoolPtr(true)))
+			},
+			expectedError: "",
+		},
+		{
+			name: "can create a vm with ultra disks explicitly disabled",
+			spec: &VMSpec{
+				Name:           "my-vm",
+				Role:           infrav1.Node,
+				NICIDs:         []string{"my-nic"},
+				SSHKeyData:     "fakesshpublickey",
+				Size:           "Standard_D2v3",
+				Location:       "test-location",
+				Image:          &infrav1.Image{ID: to.StringPtr("fake-image-id")},
+				EnableUltraSSD: to.BoolPtr(false),
+				SKU:            validSKU,
+			},
+			existing: nil,
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(BeAssignableToTypeOf(compute.VirtualMachine{}))
+				g.Expect(result.(compute.VirtualMachine).AdditionalCapabilities.UltraSSDEnabled).To(Equal(to.BoolPtr(false)))
+			},
+			expectedError: "",
+		},
+		{
+			name: "creating vm with ultra disks explicitly enabled in unsupported location fails",
+			spec: &VMSpec{
+				Name:           "my-vm",
+				Role:           infrav1.Node,
+				NICIDs:         []string{"my-nic"},
+				SSHKeyData:     "fakesshpublickey",
+				Size:           "Standard_D2v3",
+				Location:       "test-location",
+				Image:          &infrav1.Image{ID: to.StringPtr("fake-image-id")},
+				EnableUltraSSD: to.BoolPtr(true),
+				SKU:            validSKU,
+			},
+			existing: nil,
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(BeNil())
+			},
+			expectedError: "reconcile error that cannot be recovered occurred: vm size Standard_D2v3 does not support ultra disks in location test-location. select a different vm size or disable ultra disks. Object will not be requeued",
+		},
+		{
+			name: "creating vm with premium v2 disk for vm size without premium storage fails",
+			spec: &VMSpec{
+				Name:       "my-vm",
+				Role:       infrav1.Node,
+				NICIDs:     []string{"my-nic"},
+				SSHKeyData: "fakesshpublickey",
+				Size:       "Standard_D2v3",
+				Location:   "test-location",
+				Image:      &infrav1.Image{ID: to.StringPtr("fake-image-id")},
+				DataDisks: []infrav1.DataDisk{
+					{
+						NameSuffix: "premiumv2",
+						DiskSizeGB: 128,
+						Lun:        to.Int32Ptr(0),
+						ManagedDisk: &infrav1.ManagedDiskParameters{
+							StorageAccountType: infrav1.StorageAccountTypePremiumV2LRS,
+						},
+					},
+				},
+				SKU: validSKU,
+			},
+			existing: nil,
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(BeNil())
+			},
+			expectedError: "reconcile error that cannot be recovered occurred: vm size Standard_D2v3 does not support premium storage. select a different vm size or storage account type. Object will not be requeued",
+		},
 	}
 	for _, tc := range testcases {
 		tc := tc
@@ -677,3 +1096,17 @@ func TestParameters(t *testing.T) {
 		})
 	}
 }
+
+func existingVMWithDataDisks(dataDisks ...compute.DataDisk) compute.VirtualMachine {
+	return compute.VirtualMachine{
+		VirtualMachineProperties: &compute.VirtualMachineProperties{
+			StorageProfile: &compute.StorageProfile{
+				DataDisks: &dataDisks,
+			},
+		},
+	}
+}
+
+func diffDiskPlacementPtr(placement infrav1.DiffDiskPlacement) *infrav1.DiffDiskPlacement {
+	return &placement
+}
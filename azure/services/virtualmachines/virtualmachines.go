@@ -20,7 +20,7 @@ import (
 	"context"
 	"strings"
 
-	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
 	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-02-01/network"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/pkg/errors"
@@ -29,13 +29,28 @@ import (
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/converters"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/capacityreservationgroups"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/networkinterfaces"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/publicips"
 	"sigs.k8s.io/cluster-api-provider-azure/util/reconciler"
+	"sigs.k8s.io/cluster-api-provider-azure/util/slice"
 	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
 )
 
-const serviceName = "virtualmachine"
+const (
+	serviceName = "virtualmachine"
+	// resizeServiceName keys the long-running operations of an in-place resize, which are tracked separately
+	// from the operations on the virtual machine resource itself.
+	resizeServiceName = "virtualmachine-resize"
+	// resizeRestartServiceName keys the long-running operations of a resize that had to deallocate the virtual
+	// machine, so that only a virtual machine deallocated by the resize itself is started again afterwards.
+	resizeRestartServiceName = "virtualmachine-resize-restart"
+	// actionServiceName keys the long-running operations of the actions requested with azure.VMActionAnnotation.
+	actionServiceName = "virtualmachine-action"
+	// hibernationServiceName keys the long-running operations that deallocate and start the virtual machine when its
+	// cluster is hibernated and resumed.
+	hibernationServiceName = "virtualmachine-hibernation"
+)
 
 // VMScope defines the scope interface for a virtual machines service.
 type VMScope interface {
@@ -43,27 +58,38 @@ type VMScope interface {
 	azure.AsyncStatusUpdater
 	VMSpec() azure.ResourceSpecGetter
 	SetAnnotation(string, string)
+	Annotation(string) string
+	RemoveAnnotation(string)
 	SetProviderID(string)
 	SetAddresses([]corev1.NodeAddress)
 	SetVMState(infrav1.ProvisioningState)
+	RemovedDataDisks() []string
+	SetRemovedDataDisks([]string)
+	ShouldHibernate() bool
+	IsHibernated() bool
+	UpdateHibernationStatus(error)
 }
 
 // Service provides operations on Azure resources.
 type Service struct {
 	Scope VMScope
 	async.Reconciler
-	interfacesGetter async.Getter
-	publicIPsClient  publicips.Client
+	client                     Client
+	interfacesGetter           async.Getter
+	publicIPsClient            publicips.Client
+	capacityReservationsClient capacityreservationgroups.Client
 }
 
 // New creates a new service.
 func New(scope VMScope) *Service {
 	Client := NewClient(scope)
 	return &Service{
-		Scope:            scope,
-		interfacesGetter: networkinterfaces.NewClient(scope),
-		publicIPsClient:  publicips.NewClient(scope),
-		Reconciler:       async.New(scope, Client, Client),
+		Scope:                      scope,
+		client:                     Client,
+		interfacesGetter:           networkinterfaces.NewClient(scope),
+		publicIPsClient:            publicips.NewClient(scope),
+		capacityReservationsClient: capacityreservationgroups.NewClient(scope),
+		Reconciler:                 async.New(scope, Client, Client),
 	}
 }
 
@@ -85,7 +111,13 @@ func (s *Service) Reconcile(ctx context.Context) error {
 		return nil
 	}
 
+	if err := s.validateCapacityReservation(ctx, vmSpec); err != nil {
+		s.Scope.UpdatePutStatus(infrav1.VMRunningCondition, serviceName, err)
+		return err
+	}
+
 	result, err := s.CreateResource(ctx, vmSpec, serviceName)
+	removed := s.recordRemovedDataDisks(vmSpec)
 	s.Scope.UpdatePutStatus(infrav1.VMRunningCondition, serviceName, err)
 	// Set the DiskReady condition here since the disk gets created with the VM.
 	s.Scope.UpdatePutStatus(infrav1.DisksReadyCondition, serviceName, err)
@@ -108,6 +140,20 @@ func (s *Service) Reconcile(ctx context.Context) error {
 		}
 		s.Scope.SetAddresses(addresses)
 		s.Scope.SetVMState(infraVM.State)
+
+		// The disks service runs before this service, so requeue for it to delete the data disks that were just detached.
+		if removed {
+			return azure.WithTransientError(errors.New("requeuing to delete the detached data disks"), reconciler.DefaultReconcilerRequeue)
+		}
+
+		// Resizes and actions are put on hold while the VM is hibernated, so that they don't start it again.
+		if s.Scope.ShouldHibernate() || s.Scope.IsHibernated() {
+			return s.reconcileHibernation(ctx, vmSpec)
+		}
+		if err := s.reconcileSize(ctx, vmSpec); err != nil {
+			return err
+		}
+		return s.reconcileAction(ctx, vmSpec)
 	}
 	return err
 }
@@ -135,6 +181,345 @@ func (s *Service) Delete(ctx context.Context) error {
 	return err
 }
 
+// recordRemovedDataDisks records the data disks detached from the VM with the Delete removal policy, so that the disks
+// service deletes them once they are detached. It returns true if any data disk was detached.
+func (s *Service) recordRemovedDataDisks(spec azure.ResourceSpecGetter) bool {
+	vmSpec, ok := spec.(*VMSpec)
+	if !ok || vmSpec.DataDiskRemovalPolicy != infrav1.DataDiskRemovalPolicyDelete || len(vmSpec.detachedDataDisks) == 0 {
+		return false
+	}
+
+	removed := s.Scope.RemovedDataDisks()
+	for _, name := range vmSpec.detachedDataDisks {
+		if !slice.Contains(removed, name) {
+			removed = append(removed, name)
+		}
+	}
+	s.Scope.SetRemovedDataDisks(removed)
+	return true
+}
+
+// validateCapacityReservation checks that the capacity reservation group of a VM that is yet to be created
+// reserves capacity for the VM's size and zone.
+func (s *Service) validateCapacityReservation(ctx context.Context, spec azure.ResourceSpecGetter) error {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "virtualmachines.Service.validateCapacityReservation")
+	defer done()
+
+	vmSpec, ok := spec.(*VMSpec)
+	if !ok || vmSpec.CapacityReservationGroupID == "" || vmSpec.ProviderID != "" {
+		return nil
+	}
+
+	reservations, err := s.capacityReservationsClient.ListReservations(ctx, vmSpec.CapacityReservationGroupID)
+	if err != nil {
+		return errors.Wrap(err, "failed to list capacity reservations")
+	}
+
+	return capacityreservationgroups.ValidateReservation(reservations, vmSpec.CapacityReservationGroupID, vmSpec.Size, vmSpec.Zone)
+}
+
+// reconcileSize resizes an existing virtual machine in place when in-place resize is enabled and its size differs
+// from the desired size, and reports the progress of the resize.
+func (s *Service) reconcileSize(ctx context.Context, spec azure.ResourceSpecGetter) error {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "virtualmachines.Service.reconcileSize")
+	defer done()
+
+	vmSpec, ok := spec.(*VMSpec)
+	if !ok || !vmSpec.EnableInPlaceResize {
+		return nil
+	}
+
+	err := s.resize(ctx, vmSpec)
+	s.Scope.UpdatePatchStatus(infrav1.VMResizedCondition, resizeServiceName, err)
+	return err
+}
+
+// resize moves the virtual machine one step closer to the desired size. If the new size is available on the hardware
+// cluster hosting the VM, the VM is resized directly and Azure restarts it. Otherwise, the VM is deallocated, resized
+// and started again. A VM that is already deallocated is resized in place and left deallocated. Each step is a
+// long-running operation that is resumed across reconcile loops.
+func (s *Service) resize(ctx context.Context, vmSpec *VMSpec) error {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "virtualmachines.Service.resize")
+	defer done()
+
+	// Check if the previous step of the resize is still in progress. The steps of a resize that deallocated the VM
+	// are tracked under resizeRestartServiceName, which tells whether the VM has to be started again.
+	var restart bool
+	for _, service := range []string{resizeServiceName, resizeRestartServiceName} {
+		future := s.Scope.GetLongRunningOperationState(vmSpec.Name, service)
+		if future == nil {
+			continue
+		}
+		sdkFuture, err := converters.FutureToSDK(*future)
+		if err != nil {
+			// Reset the future data to avoid getting stuck in a bad loop.
+			s.Scope.DeleteLongRunningOperationState(vmSpec.Name, service)
+			return errors.Wrap(err, "could not decode future data, resetting long-running operation state")
+		}
+		isDone, err := s.client.IsDone(ctx, sdkFuture)
+		if err != nil {
+			return errors.Wrap(err, "failed checking if the operation was complete")
+		}
+		if !isDone {
+			log.V(2).Info("VM resize operation is still ongoing", "vm", vmSpec.Name, "type", future.Type)
+			return azure.WithTransientError(azure.NewOperationNotDoneError(future), reconciler.DefaultReconcilerRequeue)
+		}
+		s.Scope.DeleteLongRunningOperationState(vmSpec.Name, service)
+		restart = service == resizeRestartServiceName
+	}
+
+	existing, err := s.client.Get(ctx, vmSpec)
+	if err != nil {
+		return errors.Wrap(err, "failed to get VM")
+	}
+	vm, ok := existing.(compute.VirtualMachine)
+	if !ok {
+		return errors.Errorf("%T is not a compute.VirtualMachine", existing)
+	}
+	var currentSize string
+	if vm.VirtualMachineProperties != nil && vm.HardwareProfile != nil {
+		currentSize = string(vm.HardwareProfile.VMSize)
+	}
+
+	instanceView, err := s.client.GetInstanceView(ctx, vmSpec)
+	if err != nil {
+		return errors.Wrap(err, "failed to get VM instance view")
+	}
+	deallocated := isDeallocated(instanceView)
+
+	if strings.EqualFold(currentSize, vmSpec.Size) {
+		// A VM that was deallocated to be resized is started again once it has the new size.
+		if deallocated && restart {
+			log.V(2).Info("starting VM after resize", "vm", vmSpec.Name, "size", vmSpec.Size)
+			return s.trackResizeOperation(s.client.StartAsync(ctx, vmSpec))
+		}
+		return nil
+	}
+
+	if !vmSpec.SKU.IsAvailable(vmSpec.Location, vmSpec.Zone) {
+		return errors.Errorf("VM size %s is not available in location %s zone %q", vmSpec.Size, vmSpec.Location, vmSpec.Zone)
+	}
+
+	if deallocated {
+		return s.resizeDeallocated(ctx, vmSpec, restart)
+	}
+
+	sizes, err := s.client.ListAvailableSizes(ctx, vmSpec)
+	if err != nil {
+		return errors.Wrap(err, "failed to list available VM sizes")
+	}
+	for _, size := range sizes {
+		if strings.EqualFold(size, vmSpec.Size) {
+			log.V(2).Info("resizing VM", "vm", vmSpec.Name, "from", currentSize, "to", vmSpec.Size)
+			return s.trackResizeOperation(s.client.UpdateSizeAsync(ctx, vmSpec, vmSpec.Size, resizeServiceName))
+		}
+	}
+
+	// The new size is not available on the hardware cluster currently hosting the VM, so the VM has to be
+	// deallocated before it can be resized.
+	log.V(2).Info("deallocating VM to resize it", "vm", vmSpec.Name, "from", currentSize, "to", vmSpec.Size)
+	if err := s.trackResizeOperation(s.client.DeallocateAsync(ctx, vmSpec)); err != nil {
+		return err
+	}
+	return s.resizeDeallocated(ctx, vmSpec, true)
+}
+
+// resizeDeallocated resizes a deallocated virtual machine. The VM is only started again if restart is set, i.e. if
+// the resize deallocated it; a VM deallocated by anything else, such as the deallocate VM action, stays deallocated.
+func (s *Service) resizeDeallocated(ctx context.Context, vmSpec *VMSpec, restart bool) error {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "virtualmachines.Service.resizeDeallocated")
+	defer done()
+
+	service := resizeServiceName
+	if restart {
+		service = resizeRestartServiceName
+	}
+	log.V(2).Info("resizing deallocated VM", "vm", vmSpec.Name, "size", vmSpec.Size)
+	if err := s.trackResizeOperation(s.client.UpdateSizeAsync(ctx, vmSpec, vmSpec.Size, service)); err != nil {
+		return err
+	}
+	if !restart {
+		return nil
+	}
+
+	log.V(2).Info("starting VM after resize", "vm", vmSpec.Name, "size", vmSpec.Size)
+	return s.trackResizeOperation(s.client.StartAsync(ctx, vmSpec))
+}
+
+// trackResizeOperation stores the future of a resize operation that did not complete in time so it can be resumed
+// in the next reconcile loop.
+func (s *Service) trackResizeOperation(future *infrav1.Future, err error) error {
+	if err != nil {
+		return err
+	}
+	if future != nil {
+		s.Scope.SetLongRunningOperationState(future)
+		return azure.WithTransientError(azure.NewOperationNotDoneError(future), reconciler.DefaultReconcilerRequeue)
+	}
+	return nil
+}
+
+// reconcileHibernation deallocates the virtual machine while its cluster is hibernated and starts it again once the
+// cluster is resumed, and reports the progress in the Hibernated condition.
+func (s *Service) reconcileHibernation(ctx context.Context, spec azure.ResourceSpecGetter) error {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "virtualmachines.Service.reconcileHibernation")
+	defer done()
+
+	vmSpec, ok := spec.(*VMSpec)
+	if !ok {
+		return nil
+	}
+
+	err := s.setPowerState(ctx, vmSpec, s.Scope.ShouldHibernate())
+	s.Scope.UpdateHibernationStatus(err)
+	return err
+}
+
+// setPowerState deallocates or starts the virtual machine, unless it is already in the desired power state. Each
+// operation is a long-running operation that is resumed across reconcile loops.
+func (s *Service) setPowerState(ctx context.Context, vmSpec *VMSpec, deallocate bool) error {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "virtualmachines.Service.setPowerState")
+	defer done()
+
+	if future := s.Scope.GetLongRunningOperationState(vmSpec.Name, hibernationServiceName); future != nil {
+		sdkFuture, err := converters.FutureToSDK(*future)
+		if err != nil {
+			// Reset the future data to avoid getting stuck in a bad loop.
+			s.Scope.DeleteLongRunningOperationState(vmSpec.Name, hibernationServiceName)
+			return errors.Wrap(err, "could not decode future data, resetting long-running operation state")
+		}
+		isDone, err := s.client.IsDone(ctx, sdkFuture)
+		if err != nil {
+			s.Scope.DeleteLongRunningOperationState(vmSpec.Name, hibernationServiceName)
+			return errors.Wrap(err, "failed to change the power state of the VM")
+		}
+		if !isDone {
+			log.V(2).Info("VM power state change is still ongoing", "vm", vmSpec.Name)
+			return azure.WithTransientError(azure.NewOperationNotDoneError(future), reconciler.DefaultReconcilerRequeue)
+		}
+		s.Scope.DeleteLongRunningOperationState(vmSpec.Name, hibernationServiceName)
+	}
+
+	instanceView, err := s.client.GetInstanceView(ctx, vmSpec)
+	if err != nil {
+		return errors.Wrap(err, "failed to get VM instance view")
+	}
+	if isDeallocated(instanceView) == deallocate {
+		return nil
+	}
+
+	action := azure.VMActionStart
+	if deallocate {
+		action = azure.VMActionDeallocate
+	}
+	log.V(2).Info("changing VM power state", "vm", vmSpec.Name, "action", action)
+	future, err := s.client.ActionAsync(ctx, vmSpec, action, hibernationServiceName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to %s VM", action)
+	}
+	if future != nil {
+		s.Scope.SetLongRunningOperationState(future)
+		return azure.WithTransientError(azure.NewOperationNotDoneError(future), reconciler.DefaultReconcilerRequeue)
+	}
+	return nil
+}
+
+// reconcileAction runs the action requested with azure.VMActionAnnotation on the virtual machine and reports its
+// progress. The annotation is removed once the action has completed or failed, so a failed action is not retried
+// until it is requested again. Failures are only reported in the VMAction condition so that they don't block the
+// reconciliation of the machine.
+func (s *Service) reconcileAction(ctx context.Context, spec azure.ResourceSpecGetter) error {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "virtualmachines.Service.reconcileAction")
+	defer done()
+
+	action := s.Scope.Annotation(azure.VMActionAnnotation)
+	if action == "" {
+		return nil
+	}
+	vmSpec, ok := spec.(*VMSpec)
+	if !ok {
+		return nil
+	}
+
+	err := s.runAction(ctx, vmSpec, action)
+	s.Scope.UpdatePatchStatus(infrav1.VMActionCondition, actionServiceName, err)
+	if azure.IsOperationNotDoneError(err) {
+		return err
+	}
+	if err != nil {
+		log.Error(err, "VM action failed", "vm", vmSpec.Name, "action", action)
+	} else {
+		log.V(2).Info("VM action completed", "vm", vmSpec.Name, "action", action)
+	}
+	s.Scope.RemoveAnnotation(azure.VMActionAnnotation)
+	return nil
+}
+
+// runAction starts the action on the virtual machine, or checks on the progress of an action that is already running.
+func (s *Service) runAction(ctx context.Context, vmSpec *VMSpec, action string) error {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "virtualmachines.Service.runAction")
+	defer done()
+
+	if future := s.Scope.GetLongRunningOperationState(vmSpec.Name, actionServiceName); future != nil {
+		sdkFuture, err := converters.FutureToSDK(*future)
+		if err != nil {
+			// Reset the future data to avoid getting stuck in a bad loop.
+			s.Scope.DeleteLongRunningOperationState(vmSpec.Name, actionServiceName)
+			return errors.Wrap(err, "could not decode future data, resetting long-running operation state")
+		}
+		isDone, err := s.client.IsDone(ctx, sdkFuture)
+		if isDone || err != nil {
+			s.Scope.DeleteLongRunningOperationState(vmSpec.Name, actionServiceName)
+			return errors.Wrapf(err, "failed to %s VM", action)
+		}
+		log.V(2).Info("VM action is still ongoing", "vm", vmSpec.Name, "action", action)
+		return azure.WithTransientError(azure.NewOperationNotDoneError(future), reconciler.DefaultReconcilerRequeue)
+	}
+
+	if err := validateAction(vmSpec, action); err != nil {
+		return err
+	}
+
+	log.V(2).Info("starting VM action", "vm", vmSpec.Name, "action", action)
+	future, err := s.client.ActionAsync(ctx, vmSpec, action, actionServiceName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to %s VM", action)
+	}
+	if future != nil {
+		s.Scope.SetLongRunningOperationState(future)
+		return azure.WithTransientError(azure.NewOperationNotDoneError(future), reconciler.DefaultReconcilerRequeue)
+	}
+	return nil
+}
+
+// validateAction checks that the action is known and can be run on the virtual machine.
+func validateAction(vmSpec *VMSpec, action string) error {
+	switch action {
+	case azure.VMActionRestart, azure.VMActionRedeploy, azure.VMActionDeallocate, azure.VMActionStart:
+		return nil
+	case azure.VMActionReimage:
+		if vmSpec.OSDisk.DiffDiskSettings == nil {
+			return errors.New("reimage is only supported for VMs with an ephemeral OS disk")
+		}
+		return nil
+	default:
+		return errors.Errorf("unknown VM action %q, must be one of %s", action, strings.Join(azure.VMActions, ", "))
+	}
+}
+
+// isDeallocated returns true if the instance view reports the virtual machine as deallocated.
+func isDeallocated(instanceView compute.VirtualMachineInstanceView) bool {
+	if instanceView.Statuses == nil {
+		return false
+	}
+	for _, status := range *instanceView.Statuses {
+		if strings.EqualFold(to.String(status.Code), "PowerState/deallocated") {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *Service) getAddresses(ctx context.Context, vm compute.VirtualMachine, rgName string) ([]corev1.NodeAddress, error) {
 	ctx, _, done := tele.StartSpanWithLogger(ctx, "virtualmachines.Service.getAddresses")
 	defer done()
@@ -21,17 +21,20 @@ import (
 	"net/http"
 	"testing"
 
-	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
 	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-02-01/network"
 	"github.com/Azure/go-autorest/autorest"
+	azureautorest "github.com/Azure/go-autorest/autorest/azure"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async/mock_async"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/networkinterfaces"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/publicips/mock_publicips"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/resourceskus"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/virtualmachines/mock_virtualmachines"
 	gomockinternal "sigs.k8s.io/cluster-api-provider-azure/internal/test/matchers/gomock"
 )
@@ -52,6 +55,12 @@ var (
 		Image:             &infrav1.Image{ID: to.StringPtr("fake-image-id")},
 		BootstrapData:     "fake data",
 	}
+	fakeVMSpecDetachingDataDisks = VMSpec{
+		Name:                  "test-vm",
+		ResourceGroup:         "test-group",
+		DataDiskRemovalPolicy: infrav1.DataDiskRemovalPolicyDelete,
+		detachedDataDisks:     []string{"test-vm_disk1", "test-vm_disk2"},
+	}
 	fakeExistingVM = compute.VirtualMachine{
 		ID:   to.StringPtr("test-vm-id"),
 		Name: to.StringPtr("test-vm-name"),
@@ -133,6 +142,27 @@ func TestReconcileVM(t *testing.T) {
 				mpip.Get(gomockinternal.AContext(), "test-group", "pip-1").Return(fakePublicIPs, nil)
 				s.SetAddresses(fakeNodeAddresses)
 				s.SetVMState(infrav1.Succeeded)
+				s.ShouldHibernate().Return(false)
+				s.IsHibernated().Return(false)
+				s.Annotation(azure.VMActionAnnotation).Return("")
+			},
+		},
+		{
+			name:          "requeue to delete the data disks detached with the Delete removal policy",
+			expectedError: "requeuing to delete the detached data disks. Object will be requeued after 15s",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, mnic *mock_async.MockGetterMockRecorder, mpip *mock_publicips.MockClientMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
+				s.VMSpec().Return(&fakeVMSpecDetachingDataDisks)
+				r.CreateResource(gomockinternal.AContext(), &fakeVMSpecDetachingDataDisks, serviceName).Return(fakeExistingVM, nil)
+				s.RemovedDataDisks().Return([]string{"test-vm_disk1"})
+				s.SetRemovedDataDisks([]string{"test-vm_disk1", "test-vm_disk2"})
+				s.UpdatePutStatus(infrav1.VMRunningCondition, serviceName, nil)
+				s.UpdatePutStatus(infrav1.DisksReadyCondition, serviceName, nil)
+				s.SetProviderID("azure://test-vm-id")
+				s.SetAnnotation("cluster-api-provider-azure", "true")
+				mnic.Get(gomockinternal.AContext(), &fakeNetworkInterfaceGetterSpec).Return(fakeNetworkInterface, nil)
+				mpip.Get(gomockinternal.AContext(), "test-group", "pip-1").Return(fakePublicIPs, nil)
+				s.SetAddresses(fakeNodeAddresses)
+				s.SetVMState(infrav1.Succeeded)
 			},
 		},
 		{
@@ -279,3 +309,451 @@ func TestDeleteVM(t *testing.T) {
 		})
 	}
 }
+
+func TestReconcileVMSize(t *testing.T) {
+	resizeSpec := fakeVMSpec
+	resizeSpec.EnableInPlaceResize = true
+	resizeSpec.Size = "Standard_D4s_v3"
+	resizeSpec.SKU = resourceskus.SKU{
+		Name: to.StringPtr("Standard_D4s_v3"),
+		LocationInfo: &[]compute.ResourceSkuLocationInfo{
+			{Location: to.StringPtr("test-location")},
+		},
+	}
+	unavailableSpec := resizeSpec
+	unavailableSpec.SKU = resourceskus.SKU{Name: to.StringPtr("Standard_D4s_v3")}
+
+	vmWithSize := func(size string) compute.VirtualMachine {
+		return compute.VirtualMachine{
+			VirtualMachineProperties: &compute.VirtualMachineProperties{
+				HardwareProfile: &compute.HardwareProfile{VMSize: compute.VirtualMachineSizeTypes(size)},
+			},
+		}
+	}
+	runningInstanceView := compute.VirtualMachineInstanceView{
+		Statuses: &[]compute.InstanceViewStatus{{Code: to.StringPtr("PowerState/running")}},
+	}
+	deallocatedInstanceView := compute.VirtualMachineInstanceView{
+		Statuses: &[]compute.InstanceViewStatus{{Code: to.StringPtr("PowerState/deallocated")}},
+	}
+	patchFuture := infrav1.Future{
+		Type:          infrav1.PatchFuture,
+		ServiceName:   resizeServiceName,
+		Name:          "test-vm",
+		ResourceGroup: "test-group",
+		Data:          "eyJtZXRob2QiOiJQQVRDSCIsInBvbGxpbmdNZXRob2QiOiJMb2NhdGlvbiIsImxyb1N0YXRlIjoiSW5Qcm9ncmVzcyJ9",
+	}
+	postFuture := infrav1.Future{
+		Type:          infrav1.PostFuture,
+		ServiceName:   resizeServiceName,
+		Name:          "test-vm",
+		ResourceGroup: "test-group",
+		Data:          "eyJtZXRob2QiOiJQT1NUIiwicG9sbGluZ01ldGhvZCI6IkxvY2F0aW9uIiwibHJvU3RhdGUiOiJJblByb2dyZXNzIn0=",
+	}
+	restartPatchFuture := patchFuture
+	restartPatchFuture.ServiceName = resizeRestartServiceName
+	restartPostFuture := postFuture
+	restartPostFuture.ServiceName = resizeRestartServiceName
+
+	testcases := []struct {
+		name          string
+		spec          *VMSpec
+		expectedError string
+		expect        func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder)
+	}{
+		{
+			name:          "noop if in-place resize is not enabled",
+			spec:          &fakeVMSpec,
+			expectedError: "",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+			},
+		},
+		{
+			name:          "VM already has the desired size",
+			spec:          &resizeSpec,
+			expectedError: "",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", resizeServiceName).Return(nil)
+				s.GetLongRunningOperationState("test-vm", resizeRestartServiceName).Return(nil)
+				m.Get(gomockinternal.AContext(), &resizeSpec).Return(vmWithSize("Standard_D4s_v3"), nil)
+				m.GetInstanceView(gomockinternal.AContext(), &resizeSpec).Return(runningInstanceView, nil)
+				s.UpdatePatchStatus(infrav1.VMResizedCondition, resizeServiceName, nil)
+			},
+		},
+		{
+			name:          "resize VM when the new size is available on the hardware cluster",
+			spec:          &resizeSpec,
+			expectedError: "operation type PATCH on Azure resource test-group/test-vm is not done. Object will be requeued after 15s",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", resizeServiceName).Return(nil)
+				s.GetLongRunningOperationState("test-vm", resizeRestartServiceName).Return(nil)
+				m.Get(gomockinternal.AContext(), &resizeSpec).Return(vmWithSize("Standard_D2s_v3"), nil)
+				m.GetInstanceView(gomockinternal.AContext(), &resizeSpec).Return(runningInstanceView, nil)
+				m.ListAvailableSizes(gomockinternal.AContext(), &resizeSpec).Return([]string{"Standard_D2s_v3", "Standard_D4s_v3"}, nil)
+				m.UpdateSizeAsync(gomockinternal.AContext(), &resizeSpec, "Standard_D4s_v3", resizeServiceName).Return(&patchFuture, nil)
+				s.SetLongRunningOperationState(&patchFuture)
+				s.UpdatePatchStatus(infrav1.VMResizedCondition, resizeServiceName, gomockinternal.ErrStrEq("operation type PATCH on Azure resource test-group/test-vm is not done. Object will be requeued after 15s"))
+			},
+		},
+		{
+			name:          "deallocate VM when the new size is not available on the hardware cluster",
+			spec:          &resizeSpec,
+			expectedError: "operation type POST on Azure resource test-group/test-vm is not done. Object will be requeued after 15s",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", resizeServiceName).Return(nil)
+				s.GetLongRunningOperationState("test-vm", resizeRestartServiceName).Return(nil)
+				m.Get(gomockinternal.AContext(), &resizeSpec).Return(vmWithSize("Standard_D2s_v3"), nil)
+				m.GetInstanceView(gomockinternal.AContext(), &resizeSpec).Return(runningInstanceView, nil)
+				m.ListAvailableSizes(gomockinternal.AContext(), &resizeSpec).Return([]string{"Standard_D2s_v3"}, nil)
+				m.DeallocateAsync(gomockinternal.AContext(), &resizeSpec).Return(&restartPostFuture, nil)
+				s.SetLongRunningOperationState(&restartPostFuture)
+				s.UpdatePatchStatus(infrav1.VMResizedCondition, resizeServiceName, gomockinternal.ErrStrEq("operation type POST on Azure resource test-group/test-vm is not done. Object will be requeued after 15s"))
+			},
+		},
+		{
+			name:          "resize and start VM once the resize deallocated it",
+			spec:          &resizeSpec,
+			expectedError: "",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", resizeServiceName).Return(nil)
+				s.GetLongRunningOperationState("test-vm", resizeRestartServiceName).Return(&restartPostFuture)
+				m.IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(true, nil)
+				s.DeleteLongRunningOperationState("test-vm", resizeRestartServiceName)
+				m.Get(gomockinternal.AContext(), &resizeSpec).Return(vmWithSize("Standard_D2s_v3"), nil)
+				m.GetInstanceView(gomockinternal.AContext(), &resizeSpec).Return(deallocatedInstanceView, nil)
+				m.UpdateSizeAsync(gomockinternal.AContext(), &resizeSpec, "Standard_D4s_v3", resizeRestartServiceName).Return(nil, nil)
+				m.StartAsync(gomockinternal.AContext(), &resizeSpec).Return(nil, nil)
+				s.UpdatePatchStatus(infrav1.VMResizedCondition, resizeServiceName, nil)
+			},
+		},
+		{
+			name:          "start VM deallocated by the resize once its resize is complete",
+			spec:          &resizeSpec,
+			expectedError: "operation type POST on Azure resource test-group/test-vm is not done. Object will be requeued after 15s",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", resizeServiceName).Return(nil)
+				s.GetLongRunningOperationState("test-vm", resizeRestartServiceName).Return(&restartPatchFuture)
+				m.IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(true, nil)
+				s.DeleteLongRunningOperationState("test-vm", resizeRestartServiceName)
+				m.Get(gomockinternal.AContext(), &resizeSpec).Return(vmWithSize("Standard_D4s_v3"), nil)
+				m.GetInstanceView(gomockinternal.AContext(), &resizeSpec).Return(deallocatedInstanceView, nil)
+				m.StartAsync(gomockinternal.AContext(), &resizeSpec).Return(&postFuture, nil)
+				s.SetLongRunningOperationState(&postFuture)
+				s.UpdatePatchStatus(infrav1.VMResizedCondition, resizeServiceName, gomockinternal.ErrStrEq("operation type POST on Azure resource test-group/test-vm is not done. Object will be requeued after 15s"))
+			},
+		},
+		{
+			name:          "resize deallocated VM in place and leave it deallocated",
+			spec:          &resizeSpec,
+			expectedError: "",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", resizeServiceName).Return(nil)
+				s.GetLongRunningOperationState("test-vm", resizeRestartServiceName).Return(nil)
+				m.Get(gomockinternal.AContext(), &resizeSpec).Return(vmWithSize("Standard_D2s_v3"), nil)
+				m.GetInstanceView(gomockinternal.AContext(), &resizeSpec).Return(deallocatedInstanceView, nil)
+				m.UpdateSizeAsync(gomockinternal.AContext(), &resizeSpec, "Standard_D4s_v3", resizeServiceName).Return(nil, nil)
+				s.UpdatePatchStatus(infrav1.VMResizedCondition, resizeServiceName, nil)
+			},
+		},
+		{
+			name:          "leave deallocated VM deallocated once its in-place resize is complete",
+			spec:          &resizeSpec,
+			expectedError: "",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", resizeServiceName).Return(&patchFuture)
+				m.IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(true, nil)
+				s.DeleteLongRunningOperationState("test-vm", resizeServiceName)
+				s.GetLongRunningOperationState("test-vm", resizeRestartServiceName).Return(nil)
+				m.Get(gomockinternal.AContext(), &resizeSpec).Return(vmWithSize("Standard_D4s_v3"), nil)
+				m.GetInstanceView(gomockinternal.AContext(), &resizeSpec).Return(deallocatedInstanceView, nil)
+				s.UpdatePatchStatus(infrav1.VMResizedCondition, resizeServiceName, nil)
+			},
+		},
+		{
+			name:          "resize operation still in progress",
+			spec:          &resizeSpec,
+			expectedError: "operation type PATCH on Azure resource test-group/test-vm is not done. Object will be requeued after 15s",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", resizeServiceName).Return(&patchFuture)
+				m.IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(false, nil)
+				s.UpdatePatchStatus(infrav1.VMResizedCondition, resizeServiceName, gomockinternal.ErrStrEq("operation type PATCH on Azure resource test-group/test-vm is not done. Object will be requeued after 15s"))
+			},
+		},
+		{
+			name:          "new size is not available in the VM location",
+			spec:          &unavailableSpec,
+			expectedError: "VM size Standard_D4s_v3 is not available in location test-location zone \"\"",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", resizeServiceName).Return(nil)
+				s.GetLongRunningOperationState("test-vm", resizeRestartServiceName).Return(nil)
+				m.Get(gomockinternal.AContext(), &unavailableSpec).Return(vmWithSize("Standard_D2s_v3"), nil)
+				m.GetInstanceView(gomockinternal.AContext(), &unavailableSpec).Return(runningInstanceView, nil)
+				s.UpdatePatchStatus(infrav1.VMResizedCondition, resizeServiceName, gomockinternal.ErrStrEq("VM size Standard_D4s_v3 is not available in location test-location zone \"\""))
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := mock_virtualmachines.NewMockVMScope(mockCtrl)
+			clientMock := mock_virtualmachines.NewMockClient(mockCtrl)
+
+			tc.expect(scopeMock.EXPECT(), clientMock.EXPECT())
+
+			s := &Service{
+				Scope:  scopeMock,
+				client: clientMock,
+			}
+
+			err := s.reconcileSize(context.TODO(), tc.spec)
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestReconcileVMAction(t *testing.T) {
+	ephemeralSpec := fakeVMSpec
+	ephemeralSpec.OSDisk = infrav1.OSDisk{
+		DiffDiskSettings: &infrav1.DiffDiskSettings{Option: string(compute.Local)},
+	}
+	postFuture := infrav1.Future{
+		Type:          infrav1.PostFuture,
+		ServiceName:   actionServiceName,
+		Name:          "test-vm",
+		ResourceGroup: "test-group",
+		Data:          "eyJtZXRob2QiOiJQT1NUIiwicG9sbGluZ01ldGhvZCI6IkxvY2F0aW9uIiwibHJvU3RhdGUiOiJJblByb2dyZXNzIn0=",
+	}
+
+	testcases := []struct {
+		name          string
+		spec          *VMSpec
+		expectedError string
+		expect        func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder)
+	}{
+		{
+			name:          "noop if no action is requested",
+			spec:          &fakeVMSpec,
+			expectedError: "",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.Annotation(azure.VMActionAnnotation).Return("")
+			},
+		},
+		{
+			name:          "restart VM",
+			spec:          &fakeVMSpec,
+			expectedError: "",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.Annotation(azure.VMActionAnnotation).Return(azure.VMActionRestart)
+				s.GetLongRunningOperationState("test-vm", actionServiceName).Return(nil)
+				m.ActionAsync(gomockinternal.AContext(), &fakeVMSpec, azure.VMActionRestart, actionServiceName).Return(nil, nil)
+				s.UpdatePatchStatus(infrav1.VMActionCondition, actionServiceName, nil)
+				s.RemoveAnnotation(azure.VMActionAnnotation)
+			},
+		},
+		{
+			name:          "redeploy VM is still in progress",
+			spec:          &fakeVMSpec,
+			expectedError: "operation type POST on Azure resource test-group/test-vm is not done. Object will be requeued after 15s",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.Annotation(azure.VMActionAnnotation).Return(azure.VMActionRedeploy)
+				s.GetLongRunningOperationState("test-vm", actionServiceName).Return(nil)
+				m.ActionAsync(gomockinternal.AContext(), &fakeVMSpec, azure.VMActionRedeploy, actionServiceName).Return(&postFuture, nil)
+				s.SetLongRunningOperationState(&postFuture)
+				s.UpdatePatchStatus(infrav1.VMActionCondition, actionServiceName, gomockinternal.ErrStrEq("operation type POST on Azure resource test-group/test-vm is not done. Object will be requeued after 15s"))
+			},
+		},
+		{
+			name:          "action completes in a later reconcile",
+			spec:          &fakeVMSpec,
+			expectedError: "",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.Annotation(azure.VMActionAnnotation).Return(azure.VMActionDeallocate)
+				s.GetLongRunningOperationState("test-vm", actionServiceName).Return(&postFuture)
+				m.IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(true, nil)
+				s.DeleteLongRunningOperationState("test-vm", actionServiceName)
+				s.UpdatePatchStatus(infrav1.VMActionCondition, actionServiceName, nil)
+				s.RemoveAnnotation(azure.VMActionAnnotation)
+			},
+		},
+		{
+			name:          "action fails in a later reconcile",
+			spec:          &fakeVMSpec,
+			expectedError: "",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.Annotation(azure.VMActionAnnotation).Return(azure.VMActionStart)
+				s.GetLongRunningOperationState("test-vm", actionServiceName).Return(&postFuture)
+				m.IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(true, internalError)
+				s.DeleteLongRunningOperationState("test-vm", actionServiceName)
+				s.UpdatePatchStatus(infrav1.VMActionCondition, actionServiceName, gomockinternal.ErrStrEq("failed to start VM: #: Internal Server Error: StatusCode=500"))
+				s.RemoveAnnotation(azure.VMActionAnnotation)
+			},
+		},
+		{
+			name:          "reimage VM with an ephemeral OS disk",
+			spec:          &ephemeralSpec,
+			expectedError: "",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.Annotation(azure.VMActionAnnotation).Return(azure.VMActionReimage)
+				s.GetLongRunningOperationState("test-vm", actionServiceName).Return(nil)
+				m.ActionAsync(gomockinternal.AContext(), &ephemeralSpec, azure.VMActionReimage, actionServiceName).Return(nil, nil)
+				s.UpdatePatchStatus(infrav1.VMActionCondition, actionServiceName, nil)
+				s.RemoveAnnotation(azure.VMActionAnnotation)
+			},
+		},
+		{
+			name:          "reimage is rejected for VMs without an ephemeral OS disk",
+			spec:          &fakeVMSpec,
+			expectedError: "",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.Annotation(azure.VMActionAnnotation).Return(azure.VMActionReimage)
+				s.GetLongRunningOperationState("test-vm", actionServiceName).Return(nil)
+				s.UpdatePatchStatus(infrav1.VMActionCondition, actionServiceName, gomockinternal.ErrStrEq("reimage is only supported for VMs with an ephemeral OS disk"))
+				s.RemoveAnnotation(azure.VMActionAnnotation)
+			},
+		},
+		{
+			name:          "unknown action is rejected",
+			spec:          &fakeVMSpec,
+			expectedError: "",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.Annotation(azure.VMActionAnnotation).Return("reboot")
+				s.GetLongRunningOperationState("test-vm", actionServiceName).Return(nil)
+				s.UpdatePatchStatus(infrav1.VMActionCondition, actionServiceName, gomockinternal.ErrStrEq("unknown VM action \"reboot\", must be one of restart, redeploy, reimage, deallocate, start"))
+				s.RemoveAnnotation(azure.VMActionAnnotation)
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := mock_virtualmachines.NewMockVMScope(mockCtrl)
+			clientMock := mock_virtualmachines.NewMockClient(mockCtrl)
+
+			tc.expect(scopeMock.EXPECT(), clientMock.EXPECT())
+
+			s := &Service{
+				Scope:  scopeMock,
+				client: clientMock,
+			}
+
+			err := s.reconcileAction(context.TODO(), tc.spec)
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestReconcileVMHibernation(t *testing.T) {
+	runningInstanceView := compute.VirtualMachineInstanceView{
+		Statuses: &[]compute.InstanceViewStatus{{Code: to.StringPtr("PowerState/running")}},
+	}
+	deallocatedInstanceView := compute.VirtualMachineInstanceView{
+		Statuses: &[]compute.InstanceViewStatus{{Code: to.StringPtr("PowerState/deallocated")}},
+	}
+	postFuture := infrav1.Future{
+		Type:          infrav1.PostFuture,
+		ServiceName:   hibernationServiceName,
+		Name:          "test-vm",
+		ResourceGroup: "test-group",
+		Data:          "eyJtZXRob2QiOiJQT1NUIiwicG9sbGluZ01ldGhvZCI6IkxvY2F0aW9uIiwibHJvU3RhdGUiOiJJblByb2dyZXNzIn0=",
+	}
+
+	testcases := []struct {
+		name          string
+		expectedError string
+		expect        func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder)
+	}{
+		{
+			name:          "deallocate running VM to hibernate it",
+			expectedError: "operation type POST on Azure resource test-group/test-vm is not done. Object will be requeued after 15s",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.ShouldHibernate().Return(true)
+				s.GetLongRunningOperationState("test-vm", hibernationServiceName).Return(nil)
+				m.GetInstanceView(gomockinternal.AContext(), &fakeVMSpec).Return(runningInstanceView, nil)
+				m.ActionAsync(gomockinternal.AContext(), &fakeVMSpec, azure.VMActionDeallocate, hibernationServiceName).Return(&postFuture, nil)
+				s.SetLongRunningOperationState(&postFuture)
+				s.UpdateHibernationStatus(gomockinternal.ErrStrEq("operation type POST on Azure resource test-group/test-vm is not done. Object will be requeued after 15s"))
+			},
+		},
+		{
+			name:          "VM is hibernated once deallocated",
+			expectedError: "",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.ShouldHibernate().Return(true)
+				s.GetLongRunningOperationState("test-vm", hibernationServiceName).Return(&postFuture)
+				m.IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(true, nil)
+				s.DeleteLongRunningOperationState("test-vm", hibernationServiceName)
+				m.GetInstanceView(gomockinternal.AContext(), &fakeVMSpec).Return(deallocatedInstanceView, nil)
+				s.UpdateHibernationStatus(nil)
+			},
+		},
+		{
+			name:          "start deallocated VM to resume it",
+			expectedError: "",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.ShouldHibernate().Return(false)
+				s.GetLongRunningOperationState("test-vm", hibernationServiceName).Return(nil)
+				m.GetInstanceView(gomockinternal.AContext(), &fakeVMSpec).Return(deallocatedInstanceView, nil)
+				m.ActionAsync(gomockinternal.AContext(), &fakeVMSpec, azure.VMActionStart, hibernationServiceName).Return(nil, nil)
+				s.UpdateHibernationStatus(nil)
+			},
+		},
+		{
+			name:          "deallocation fails",
+			expectedError: "failed to change the power state of the VM: #: Internal Server Error: StatusCode=500",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.ShouldHibernate().Return(true)
+				s.GetLongRunningOperationState("test-vm", hibernationServiceName).Return(&postFuture)
+				m.IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(true, internalError)
+				s.DeleteLongRunningOperationState("test-vm", hibernationServiceName)
+				s.UpdateHibernationStatus(gomockinternal.ErrStrEq("failed to change the power state of the VM: #: Internal Server Error: StatusCode=500"))
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := mock_virtualmachines.NewMockVMScope(mockCtrl)
+			clientMock := mock_virtualmachines.NewMockClient(mockCtrl)
+
+			tc.expect(scopeMock.EXPECT(), clientMock.EXPECT())
+
+			s := &Service{
+				Scope:  scopeMock,
+				client: clientMock,
+			}
+
+			err := s.reconcileHibernation(context.TODO(), &fakeVMSpec)
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
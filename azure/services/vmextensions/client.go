@@ -18,9 +18,13 @@ package vmextensions
 
 import (
 	"context"
+	"io"
+	"net/http"
 
-	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
 	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
 	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
 )
@@ -30,19 +34,26 @@ type client interface {
 	Get(ctx context.Context, resourceGroupName, vmName, name string) (compute.VirtualMachineExtension, error)
 	CreateOrUpdateAsync(context.Context, string, string, string, compute.VirtualMachineExtension) error
 	Delete(context.Context, string, string, string) error
+	GetSerialConsoleLog(ctx context.Context, resourceGroupName, vmName string) (string, error)
 }
 
 // azureClient contains the Azure go-sdk Client.
 type azureClient struct {
-	vmextensions compute.VirtualMachineExtensionsClient
+	vmextensions    compute.VirtualMachineExtensionsClient
+	virtualmachines compute.VirtualMachinesClient
 }
 
 var _ client = (*azureClient)(nil)
 
+// sasURIExpirationTimeInMinutes is how long the URI returned to download the serial console log stays valid.
+const sasURIExpirationTimeInMinutes = 5
+
 // newClient creates a new VM client from subscription ID.
 func newClient(auth azure.Authorizer) *azureClient {
 	c := newVirtualMachineExtensionsClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer())
-	return &azureClient{c}
+	vmClient := compute.NewVirtualMachinesClientWithBaseURI(auth.BaseURI(), auth.SubscriptionID())
+	azure.SetAutoRestClientDefaults(&vmClient.Client, auth.Authorizer())
+	return &azureClient{vmextensions: c, virtualmachines: vmClient}
 }
 
 // newVirtualMachineExtensionsClient creates a new vm extension client from subscription ID.
@@ -85,3 +96,37 @@ func (ac *azureClient) Delete(ctx context.Context, resourceGroupName, vmName, na
 	_, err = future.Result(ac.vmextensions)
 	return err
 }
+
+// GetSerialConsoleLog downloads the serial console log of the virtual machine from its boot diagnostics.
+func (ac *azureClient) GetSerialConsoleLog(ctx context.Context, resourceGroupName, vmName string) (string, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "vmextensions.AzureClient.GetSerialConsoleLog")
+	defer done()
+
+	result, err := ac.virtualmachines.RetrieveBootDiagnosticsData(ctx, resourceGroupName, vmName, to.Int32Ptr(sasURIExpirationTimeInMinutes))
+	if err != nil {
+		return "", err
+	}
+	if result.SerialConsoleLogBlobURI == nil {
+		return "", errors.Errorf("no serial console log available for VM %s", vmName)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, *result.SerialConsoleLogBlobURI, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("failed to download the serial console log of VM %s: %s", vmName, resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
@@ -24,7 +24,7 @@ import (
 	context "context"
 	reflect "reflect"
 
-	compute "github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	compute "github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -93,3 +93,18 @@ func (mr *MockclientMockRecorder) Get(ctx, resourceGroupName, vmName, name inter
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*Mockclient)(nil).Get), ctx, resourceGroupName, vmName, name)
 }
+
+// GetSerialConsoleLog mocks base method.
+func (m *Mockclient) GetSerialConsoleLog(ctx context.Context, resourceGroupName, vmName string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSerialConsoleLog", ctx, resourceGroupName, vmName)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSerialConsoleLog indicates an expected call of GetSerialConsoleLog.
+func (mr *MockclientMockRecorder) GetSerialConsoleLog(ctx, resourceGroupName, vmName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSerialConsoleLog", reflect.TypeOf((*Mockclient)(nil).GetSerialConsoleLog), ctx, resourceGroupName, vmName)
+}
@@ -249,6 +249,18 @@ func (mr *MockVMExtensionScopeMockRecorder) SetBootstrapConditions(arg0, arg1, a
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBootstrapConditions", reflect.TypeOf((*MockVMExtensionScope)(nil).SetBootstrapConditions), arg0, arg1, arg2)
 }
 
+// SetBootstrapFailureLog mocks base method.
+func (m *MockVMExtensionScope) SetBootstrapFailureLog(arg0 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetBootstrapFailureLog", arg0)
+}
+
+// SetBootstrapFailureLog indicates an expected call of SetBootstrapFailureLog.
+func (mr *MockVMExtensionScopeMockRecorder) SetBootstrapFailureLog(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBootstrapFailureLog", reflect.TypeOf((*MockVMExtensionScope)(nil).SetBootstrapFailureLog), arg0)
+}
+
 // SubscriptionID mocks base method.
 func (m *MockVMExtensionScope) SubscriptionID() string {
 	m.ctrl.T.Helper()
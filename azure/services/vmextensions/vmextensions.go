@@ -18,21 +18,31 @@ package vmextensions
 
 import (
 	"context"
+	"strings"
 
-	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
 	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
 )
 
 const serviceName = "vmextensions"
 
+const (
+	// serialConsoleLogTailLines is the number of lines of the serial console log attached to a failed bootstrap.
+	serialConsoleLogTailLines = 20
+	// maxSerialConsoleLogTailBytes caps the size of the serial console log attached to a failed bootstrap.
+	maxSerialConsoleLogTailBytes = 4096
+)
+
 // VMExtensionScope defines the scope interface for a vm extension service.
 type VMExtensionScope interface {
 	azure.ClusterDescriber
 	VMExtensionSpecs() []azure.ExtensionSpec
 	SetBootstrapConditions(context.Context, string, string) error
+	SetBootstrapFailureLog(string)
 }
 
 // Service provides operations on Azure resources.
@@ -63,6 +73,9 @@ func (s *Service) Reconcile(ctx context.Context) error {
 		if existing, err := s.client.Get(ctx, s.Scope.ResourceGroup(), extensionSpec.VMName, extensionSpec.Name); err == nil {
 			// check the extension status and set the associated conditions.
 			if retErr := s.Scope.SetBootstrapConditions(ctx, to.String(existing.ProvisioningState), extensionSpec.Name); retErr != nil {
+				if infrav1.ProvisioningState(to.String(existing.ProvisioningState)) == infrav1.Failed {
+					s.recordSerialConsoleLog(ctx, extensionSpec.VMName)
+				}
 				return retErr
 			}
 			// if the extension already exists, do not update it.
@@ -96,6 +109,33 @@ func (s *Service) Reconcile(ctx context.Context) error {
 	return nil
 }
 
+// recordSerialConsoleLog attaches the tail of the serial console log of the VM to its failed bootstrap, so that
+// bootstrap errors can be debugged without access to the VM. Failing to get the log doesn't fail the reconciliation.
+func (s *Service) recordSerialConsoleLog(ctx context.Context, vmName string) {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "vmextensions.Service.recordSerialConsoleLog")
+	defer done()
+
+	serialConsoleLog, err := s.client.GetSerialConsoleLog(ctx, s.Scope.ResourceGroup(), vmName)
+	if err != nil {
+		log.V(2).Info("failed to get the serial console log of the VM", "virtual machine", vmName, "error", err.Error())
+		return
+	}
+	s.Scope.SetBootstrapFailureLog(serialConsoleLogTail(serialConsoleLog))
+}
+
+// serialConsoleLogTail returns the last lines of a serial console log.
+func serialConsoleLogTail(serialConsoleLog string) string {
+	lines := strings.Split(strings.TrimRight(strings.ReplaceAll(serialConsoleLog, "\r", ""), "\n"), "\n")
+	if len(lines) > serialConsoleLogTailLines {
+		lines = lines[len(lines)-serialConsoleLogTailLines:]
+	}
+	tail := strings.Join(lines, "\n")
+	if len(tail) > maxSerialConsoleLogTailBytes {
+		tail = tail[len(tail)-maxSerialConsoleLogTailBytes:]
+	}
+	return tail
+}
+
 // Delete is a no-op. Extensions will be deleted as part of VM deletion.
 func (s *Service) Delete(_ context.Context) error {
 	return nil
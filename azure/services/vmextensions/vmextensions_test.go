@@ -18,14 +18,17 @@ package vmextensions
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"strings"
 	"testing"
 
-	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
 	"github.com/Azure/go-autorest/autorest"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/vmextensions/mock_vmextensions"
 	gomockinternal "sigs.k8s.io/cluster-api-provider-azure/internal/test/matchers/gomock"
@@ -55,12 +58,12 @@ func TestReconcileVMExtension(t *testing.T) {
 					VirtualMachineExtensionProperties: &compute.VirtualMachineExtensionProperties{
 						Publisher:         to.StringPtr("some-publisher"),
 						Type:              to.StringPtr("my-extension-1"),
-						ProvisioningState: to.StringPtr(string(compute.ProvisioningStateSucceeded)),
+						ProvisioningState: to.StringPtr("Succeeded"),
 					},
 					ID:   to.StringPtr("fake/id"),
 					Name: to.StringPtr("my-extension-1"),
 				}, nil)
-				s.SetBootstrapConditions(gomockinternal.AContext(), string(compute.ProvisioningStateSucceeded), "my-extension-1")
+				s.SetBootstrapConditions(gomockinternal.AContext(), "Succeeded", "my-extension-1")
 			},
 		},
 		{
@@ -81,12 +84,67 @@ func TestReconcileVMExtension(t *testing.T) {
 					VirtualMachineExtensionProperties: &compute.VirtualMachineExtensionProperties{
 						Publisher:         to.StringPtr("some-publisher"),
 						Type:              to.StringPtr("my-extension-1"),
-						ProvisioningState: to.StringPtr(string(compute.ProvisioningStateFailed)),
+						ProvisioningState: to.StringPtr("Failed"),
 					},
 					ID:   to.StringPtr("fake/id"),
 					Name: to.StringPtr("my-extension-1"),
 				}, nil)
-				s.SetBootstrapConditions(gomockinternal.AContext(), string(compute.ProvisioningStateFailed), "my-extension-1")
+				s.SetBootstrapConditions(gomockinternal.AContext(), "Failed", "my-extension-1")
+			},
+		},
+		{
+			name:          "extension failed to bootstrap the vm",
+			expectedError: "extension state failed",
+			expect: func(s *mock_vmextensions.MockVMExtensionScopeMockRecorder, m *mock_vmextensions.MockclientMockRecorder) {
+				s.VMExtensionSpecs().Return([]azure.ExtensionSpec{
+					{
+						Name:      "my-extension-1",
+						VMName:    "my-vm",
+						Publisher: "some-publisher",
+						Version:   "1.0",
+					},
+				})
+				s.ResourceGroup().AnyTimes().Return("my-rg")
+				s.Location().AnyTimes().Return("test-location")
+				m.Get(gomockinternal.AContext(), "my-rg", "my-vm", "my-extension-1").Return(compute.VirtualMachineExtension{
+					VirtualMachineExtensionProperties: &compute.VirtualMachineExtensionProperties{
+						Publisher:         to.StringPtr("some-publisher"),
+						Type:              to.StringPtr("my-extension-1"),
+						ProvisioningState: to.StringPtr("Failed"),
+					},
+					ID:   to.StringPtr("fake/id"),
+					Name: to.StringPtr("my-extension-1"),
+				}, nil)
+				s.SetBootstrapConditions(gomockinternal.AContext(), "Failed", "my-extension-1").Return(errors.New("extension state failed"))
+				m.GetSerialConsoleLog(gomockinternal.AContext(), "my-rg", "my-vm").Return("[  OK  ] Started cloud-init.\r\nkubeadm join failed\r\n", nil)
+				s.SetBootstrapFailureLog("[  OK  ] Started cloud-init.\nkubeadm join failed")
+			},
+		},
+		{
+			name:          "extension failed to bootstrap the vm and the serial console log is not available",
+			expectedError: "extension state failed",
+			expect: func(s *mock_vmextensions.MockVMExtensionScopeMockRecorder, m *mock_vmextensions.MockclientMockRecorder) {
+				s.VMExtensionSpecs().Return([]azure.ExtensionSpec{
+					{
+						Name:      "my-extension-1",
+						VMName:    "my-vm",
+						Publisher: "some-publisher",
+						Version:   "1.0",
+					},
+				})
+				s.ResourceGroup().AnyTimes().Return("my-rg")
+				s.Location().AnyTimes().Return("test-location")
+				m.Get(gomockinternal.AContext(), "my-rg", "my-vm", "my-extension-1").Return(compute.VirtualMachineExtension{
+					VirtualMachineExtensionProperties: &compute.VirtualMachineExtensionProperties{
+						Publisher:         to.StringPtr("some-publisher"),
+						Type:              to.StringPtr("my-extension-1"),
+						ProvisioningState: to.StringPtr("Failed"),
+					},
+					ID:   to.StringPtr("fake/id"),
+					Name: to.StringPtr("my-extension-1"),
+				}, nil)
+				s.SetBootstrapConditions(gomockinternal.AContext(), "Failed", "my-extension-1").Return(errors.New("extension state failed"))
+				m.GetSerialConsoleLog(gomockinternal.AContext(), "my-rg", "my-vm").Return("", autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 409}, "Conflict"))
 			},
 		},
 		{
@@ -107,12 +165,12 @@ func TestReconcileVMExtension(t *testing.T) {
 					VirtualMachineExtensionProperties: &compute.VirtualMachineExtensionProperties{
 						Publisher:         to.StringPtr("some-publisher"),
 						Type:              to.StringPtr("my-extension-1"),
-						ProvisioningState: to.StringPtr(string(compute.ProvisioningStateCreating)),
+						ProvisioningState: to.StringPtr("Creating"),
 					},
 					ID:   to.StringPtr("fake/id"),
 					Name: to.StringPtr("my-extension-1"),
 				}, nil)
-				s.SetBootstrapConditions(gomockinternal.AContext(), string(compute.ProvisioningStateCreating), "my-extension-1")
+				s.SetBootstrapConditions(gomockinternal.AContext(), "Creating", "my-extension-1")
 			},
 		},
 		{
@@ -222,3 +280,16 @@ func TestReconcileVMExtension(t *testing.T) {
 		})
 	}
 }
+
+func TestSerialConsoleLogTail(t *testing.T) {
+	g := NewWithT(t)
+
+	var lines []string
+	for i := 0; i < 30; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	g.Expect(serialConsoleLogTail(strings.Join(lines, "\r\n") + "\r\n")).To(Equal(strings.Join(lines[10:], "\n")))
+
+	long := strings.Repeat("a", 2*maxSerialConsoleLogTailBytes)
+	g.Expect(serialConsoleLogTail(long)).To(HaveLen(maxSerialConsoleLogTailBytes))
+}
@@ -21,7 +21,7 @@ import (
 	"net/http"
 	"testing"
 
-	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
 	"github.com/Azure/go-autorest/autorest"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/golang/mock/gomock"
@@ -56,11 +56,11 @@ func TestReconcileVMSSExtension(t *testing.T) {
 					VirtualMachineScaleSetExtensionProperties: &compute.VirtualMachineScaleSetExtensionProperties{
 						Publisher:         to.StringPtr("some-publisher"),
 						Type:              to.StringPtr("my-extension-1"),
-						ProvisioningState: to.StringPtr(string(compute.ProvisioningStateSucceeded)),
+						ProvisioningState: to.StringPtr("Succeeded"),
 					},
 					ID: to.StringPtr("some/fake/id"),
 				}, nil)
-				s.SetBootstrapConditions(gomockinternal.AContext(), string(compute.ProvisioningStateSucceeded), "my-extension-1")
+				s.SetBootstrapConditions(gomockinternal.AContext(), "Succeeded", "my-extension-1")
 			},
 		},
 		{
@@ -18,8 +18,11 @@ package azure
 
 import (
 	"reflect"
+	"sort"
+	"strings"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 )
 
@@ -68,8 +71,57 @@ type ScaleSetSpec struct {
 	Identity                     infrav1.VMIdentity
 	UserAssignedIdentities       []infrav1.UserAssignedIdentity
 	SecurityProfile              *infrav1.SecurityProfile
+	Diagnostics                  *infrav1.Diagnostics
+	BootstrapDataDelivery        infrav1.BootstrapDataDelivery
 	SpotVMOptions                *infrav1.SpotVMOptions
 	FailureDomains               []string
+	CapacityReservationGroupID   string
+	ApplicationHealth            *ApplicationHealthSpec
+	AutomaticRepairs             *AutomaticRepairsSpec
+	OrchestrationMode            infrav1.OrchestrationModeType
+	UpgradePolicy                *UpgradePolicySpec
+	PriorityMixPolicy            *PriorityMixPolicySpec
+}
+
+// ApplicationHealthSpec defines the specification for the endpoint probed by the Application Health extension of a
+// Scale Set.
+type ApplicationHealthSpec struct {
+	Protocol    string
+	Port        int32
+	RequestPath string
+}
+
+// AutomaticRepairsSpec defines the specification for the automatic repairs policy of a Scale Set.
+type AutomaticRepairsSpec struct {
+	// GracePeriod is the grace period in ISO 8601 format, e.g. PT30M.
+	GracePeriod  string `json:"gracePeriod,omitempty"`
+	RepairAction string `json:"repairAction,omitempty"`
+}
+
+// UpgradePolicySpec defines the specification for the upgrade policy of a Scale Set when the platform upgrades its
+// instances, i.e. with the Rolling or Automatic mode or automatic OS image upgrades.
+type UpgradePolicySpec struct {
+	Mode               string                    `json:"mode,omitempty"`
+	RollingUpgrade     *RollingUpgradePolicySpec `json:"rollingUpgrade,omitempty"`
+	AutomaticOSUpgrade bool                      `json:"automaticOSUpgrade,omitempty"`
+}
+
+// RollingUpgradePolicySpec defines the specification for the batches in which the platform upgrades the instances of a
+// Scale Set. Unset fields use the defaults of the platform.
+type RollingUpgradePolicySpec struct {
+	MaxBatchInstancePercent             *int32 `json:"maxBatchInstancePercent,omitempty"`
+	MaxUnhealthyInstancePercent         *int32 `json:"maxUnhealthyInstancePercent,omitempty"`
+	MaxUnhealthyUpgradedInstancePercent *int32 `json:"maxUnhealthyUpgradedInstancePercent,omitempty"`
+	// PauseTimeBetweenBatches is the pause time in ISO 8601 format, e.g. PT5M.
+	PauseTimeBetweenBatches string `json:"pauseTimeBetweenBatches,omitempty"`
+}
+
+// PriorityMixPolicySpec defines the specification for the mix of regular and Spot instances of a Scale Set.
+type PriorityMixPolicySpec struct {
+	BaseRegularPriorityCount           *int32
+	RegularPriorityPercentageAboveBase *int32
+	// EvictionPolicy is the eviction policy of the Spot instances, Deallocate or Delete.
+	EvictionPolicy string
 }
 
 // TagsSpec defines the specification for a set of tags.
@@ -82,6 +134,26 @@ type TagsSpec struct {
 	Annotation string
 }
 
+// BootstrapDataBlobSpec defines the specification for the blob the bootstrap data of a machine is uploaded to.
+type BootstrapDataBlobSpec struct {
+	// StorageAccount is the storage account owned by the cluster to create before uploading the bootstrap data.
+	// It is nil when the bootstrap data is uploaded to an existing storage account.
+	StorageAccount ResourceSpecGetter
+	// StorageAccountName is the name of the storage account the bootstrap data is uploaded to.
+	StorageAccountName string
+	// ResourceGroup is the resource group of the storage account.
+	ResourceGroup string
+	// ContainerName is the name of the blob container.
+	ContainerName string
+	// Name is the name of the blob.
+	Name string
+	// Access is how the virtual machine authenticates to download the blob.
+	Access infrav1.BootstrapDataBlobAccess
+	// IdentityID is the resource ID of the user-assigned identity the virtual machine downloads the blob with when
+	// Access is ManagedIdentity.
+	IdentityID string
+}
+
 // ExtensionSpec defines the specification for a VM or VMScaleSet extension.
 type ExtensionSpec struct {
 	Name              string
@@ -94,12 +166,34 @@ type ExtensionSpec struct {
 type (
 	// VMSSVM defines a VM in a virtual machine scale set.
 	VMSSVM struct {
-		ID               string                    `json:"id,omitempty"`
-		InstanceID       string                    `json:"instanceID,omitempty"`
-		Image            infrav1.Image             `json:"image,omitempty"`
-		Name             string                    `json:"name,omitempty"`
-		AvailabilityZone string                    `json:"availabilityZone,omitempty"`
-		State            infrav1.ProvisioningState `json:"vmState,omitempty"`
+		ID                         string                    `json:"id,omitempty"`
+		InstanceID                 string                    `json:"instanceID,omitempty"`
+		Image                      infrav1.Image             `json:"image,omitempty"`
+		Name                       string                    `json:"name,omitempty"`
+		Sku                        string                    `json:"sku,omitempty"`
+		AvailabilityZone           string                    `json:"availabilityZone,omitempty"`
+		State                      infrav1.ProvisioningState `json:"vmState,omitempty"`
+		HealthState                string                    `json:"healthState,omitempty"`
+		Priority                   string                    `json:"priority,omitempty"`
+		Evicted                    bool                      `json:"evicted,omitempty"`
+		ProtectFromScaleIn         bool                      `json:"protectFromScaleIn,omitempty"`
+		ProtectFromScaleSetActions bool                      `json:"protectFromScaleSetActions,omitempty"`
+		LatestModelApplied         bool                      `json:"latestModelApplied,omitempty"`
+		OSDisk                     VMSSDisk                  `json:"osDisk,omitempty"`
+		DataDisks                  []VMSSDisk                `json:"dataDisks,omitempty"`
+		EncryptionAtHost           bool                      `json:"encryptionAtHost,omitempty"`
+	}
+
+	// VMSSDisk defines a managed disk of a VMSS model or of a VM in a virtual machine scale set. Attached data disks were
+	// attached to the VM after its creation, e.g. persistent volumes, rather than created from the model.
+	VMSSDisk struct {
+		Name                string `json:"name,omitempty"`
+		Lun                 *int32 `json:"lun,omitempty"`
+		DiskSizeGB          int32  `json:"diskSizeGB,omitempty"`
+		StorageAccountType  string `json:"storageAccountType,omitempty"`
+		DiskEncryptionSetID string `json:"diskEncryptionSetID,omitempty"`
+		Ephemeral           bool   `json:"ephemeral,omitempty"`
+		Attached            bool   `json:"attached,omitempty"`
 	}
 
 	// VMSS defines a virtual machine scale set.
@@ -114,19 +208,67 @@ type (
 		Identity  infrav1.VMIdentity        `json:"identity,omitempty"`
 		Tags      infrav1.Tags              `json:"tags,omitempty"`
 		Instances []VMSSVM                  `json:"instances,omitempty"`
+
+		OSDisk           VMSSDisk              `json:"osDisk,omitempty"`
+		DataDisks        []VMSSDisk            `json:"dataDisks,omitempty"`
+		Extensions       []string              `json:"extensions,omitempty"`
+		AutomaticRepairs *AutomaticRepairsSpec `json:"automaticRepairs,omitempty"`
+		UpgradePolicy    *UpgradePolicySpec    `json:"upgradePolicy,omitempty"`
 	}
 )
 
-// HasModelChanges returns true if the spec fields which will mutate the Azure VMSS model are different.
+// HasModelChanges returns true if the spec fields of the Azure VMSS model which require replacing the instances, i.e.
+// the image, the size, the zones or the disk layout, are different.
 func (vmss VMSS) HasModelChanges(other VMSS) bool {
 	equal := cmp.Equal(vmss.Image, other.Image) &&
-		cmp.Equal(vmss.Identity, other.Identity) &&
 		cmp.Equal(vmss.Zones, other.Zones) &&
-		cmp.Equal(vmss.Tags, other.Tags) &&
-		cmp.Equal(vmss.Sku, other.Sku)
+		cmp.Equal(vmss.Sku, other.Sku) &&
+		HasDiskLayout(vmss.OSDisk, vmss.DataDisks, other.OSDisk, other.DataDisks)
+	return !equal
+}
+
+// HasInPlaceChanges returns true if the tags, the identity, the extensions, the automatic repairs policy or the upgrade
+// policy of the VMSS are different. Unlike model changes, they don't require replacing the instances.
+func (vmss VMSS) HasInPlaceChanges(other VMSS) bool {
+	sorted := func(names []string) []string {
+		names = append([]string{}, names...)
+		sort.Strings(names)
+		return names
+	}
+	equal := cmp.Equal(vmss.Tags, other.Tags) &&
+		cmp.Equal(vmss.Identity, other.Identity) &&
+		cmp.Equal(sorted(vmss.Extensions), sorted(other.Extensions), cmpopts.EquateEmpty()) &&
+		cmp.Equal(vmss.AutomaticRepairs, other.AutomaticRepairs) &&
+		!vmss.hasUpgradePolicyChanges(other.UpgradePolicy)
 	return !equal
 }
 
+// hasUpgradePolicyChanges returns true if the upgrade policy of the VMSS is different from the desired one. The rolling
+// upgrade settings left unset in the desired policy are ignored, as the platform reports its defaults for them.
+func (vmss VMSS) hasUpgradePolicyChanges(desired *UpgradePolicySpec) bool {
+	actual := vmss.UpgradePolicy
+	if actual == nil || desired == nil {
+		return actual != desired
+	}
+	if actual.Mode != desired.Mode || actual.AutomaticOSUpgrade != desired.AutomaticOSUpgrade {
+		return true
+	}
+	if desired.RollingUpgrade == nil {
+		return false
+	}
+	if actual.RollingUpgrade == nil {
+		return true
+	}
+
+	changed := func(actual, desired *int32) bool {
+		return desired != nil && (actual == nil || *actual != *desired)
+	}
+	return changed(actual.RollingUpgrade.MaxBatchInstancePercent, desired.RollingUpgrade.MaxBatchInstancePercent) ||
+		changed(actual.RollingUpgrade.MaxUnhealthyInstancePercent, desired.RollingUpgrade.MaxUnhealthyInstancePercent) ||
+		changed(actual.RollingUpgrade.MaxUnhealthyUpgradedInstancePercent, desired.RollingUpgrade.MaxUnhealthyUpgradedInstancePercent) ||
+		(desired.RollingUpgrade.PauseTimeBetweenBatches != "" && actual.RollingUpgrade.PauseTimeBetweenBatches != desired.RollingUpgrade.PauseTimeBetweenBatches)
+}
+
 // InstancesByProviderID returns VMSSVMs by ID.
 func (vmss VMSS) InstancesByProviderID() map[string]VMSSVM {
 	instancesByProviderID := make(map[string]VMSSVM, len(vmss.Instances))
@@ -169,10 +311,91 @@ func (vmss VMSS) HasEnoughLatestModelOrNotMixedModel() bool {
 	return counter == vmss.Capacity
 }
 
-// HasLatestModelApplied returns true if the VMSS instance matches the VMSS image reference.
+// HasLatestModelApplied returns true if the VMSS instance matches the VMSS image reference and the disk layout of the
+// VMSS model.
 func (vmss VMSS) HasLatestModelApplied(vm VMSSVM) bool {
-	// if the images match, then the VM is of the same model
-	return reflect.DeepEqual(vm.Image, vmss.Image)
+	// if the images match, then the VM is of the same model, unless its disk layout drifted from the disks of the model
+	return reflect.DeepEqual(vm.Image, vmss.Image) &&
+		HasDiskLayout(vm.OSDisk, vm.DataDisks, vmss.OSDisk, vmss.DataDisks)
+}
+
+// NeedsInPlaceUpdate returns true if the latest VMSS model is not applied to the VMSS instance although the instance has
+// the image, the size and the disk layout of the model, i.e. the instance only misses changes which are applied in
+// place like extensions.
+func (vmss VMSS) NeedsInPlaceUpdate(vm VMSSVM) bool {
+	return !vm.LatestModelApplied &&
+		vmss.HasLatestModelApplied(vm) &&
+		(vm.Sku == "" || vm.Sku == vmss.Sku)
+}
+
+// HasDiskLayout returns true if the disks have the layout of the desired disks: the data disks have the same LUNs, and
+// the disks have the size, the storage account type and the disk encryption set of the desired disks where these are
+// set. Data disks attached after the creation of a VM are not part of its layout, and neither is whether the OS disk is
+// ephemeral, as it can't be changed on an existing scale set.
+func HasDiskLayout(osDisk VMSSDisk, dataDisks []VMSSDisk, desiredOSDisk VMSSDisk, desiredDataDisks []VMSSDisk) bool {
+	if !diskMatches(osDisk, desiredOSDisk) {
+		return false
+	}
+
+	byLun := make(map[int32]VMSSDisk, len(dataDisks))
+	for _, disk := range dataDisks {
+		if disk.Lun != nil && !disk.Attached {
+			byLun[*disk.Lun] = disk
+		}
+	}
+
+	desiredLuns := 0
+	for _, desired := range desiredDataDisks {
+		if desired.Lun == nil {
+			continue
+		}
+		desiredLuns++
+		disk, ok := byLun[*desired.Lun]
+		if !ok || !diskMatches(disk, desired) {
+			return false
+		}
+	}
+	return len(byLun) == desiredLuns
+}
+
+// diskMatches returns true if the disk has the size, the storage account type and the disk encryption set of the
+// desired disk where these are set. The size and the storage account type aren't compared if they aren't reported for
+// the disk.
+func diskMatches(disk, desired VMSSDisk) bool {
+	return (desired.DiskSizeGB == 0 || disk.DiskSizeGB == 0 || disk.DiskSizeGB == desired.DiskSizeGB) &&
+		(desired.StorageAccountType == "" || disk.StorageAccountType == "" || strings.EqualFold(disk.StorageAccountType, desired.StorageAccountType)) &&
+		(desired.DiskEncryptionSetID == "" || strings.EqualFold(disk.DiskEncryptionSetID, desired.DiskEncryptionSetID))
+}
+
+// DiskLayoutFromSpec returns the disks of the layout described by the OS disk and the data disks of a scale set spec.
+func DiskLayoutFromSpec(osDisk infrav1.OSDisk, dataDisks []infrav1.DataDisk) (VMSSDisk, []VMSSDisk) {
+	desiredOSDisk := VMSSDisk{
+		Ephemeral: osDisk.DiffDiskSettings != nil,
+	}
+	if osDisk.DiskSizeGB != nil {
+		desiredOSDisk.DiskSizeGB = *osDisk.DiskSizeGB
+	}
+	if osDisk.ManagedDisk != nil {
+		desiredOSDisk.StorageAccountType = osDisk.ManagedDisk.StorageAccountType
+		if osDisk.ManagedDisk.DiskEncryptionSet != nil {
+			desiredOSDisk.DiskEncryptionSetID = osDisk.ManagedDisk.DiskEncryptionSet.ID
+		}
+	}
+
+	data := make([]VMSSDisk, len(dataDisks))
+	for i, disk := range dataDisks {
+		data[i] = VMSSDisk{
+			Lun:        disk.Lun,
+			DiskSizeGB: disk.DiskSizeGB,
+		}
+		if disk.ManagedDisk != nil {
+			data[i].StorageAccountType = disk.ManagedDisk.StorageAccountType
+			if disk.ManagedDisk.DiskEncryptionSet != nil {
+				data[i].DiskEncryptionSetID = disk.ManagedDisk.DiskEncryptionSet.ID
+			}
+		}
+	}
+	return desiredOSDisk, data
 }
 
 // ManagedClusterSpec contains properties to create a managed cluster.
@@ -80,7 +80,7 @@ func TestVMSS_HasModelChanges(t *testing.T) {
 				r := getDefaultVMSSForModelTesting()
 				return r, l
 			},
-			HasModelChanges: true,
+			HasModelChanges: false,
 		},
 		{
 			Name: "with different Zones",
@@ -134,6 +134,16 @@ func TestVMSS_HasModelChanges(t *testing.T) {
 				r := getDefaultVMSSForModelTesting()
 				return r, l
 			},
+			HasModelChanges: false,
+		},
+		{
+			Name: "with a different data disk size",
+			Factory: func() (VMSS, VMSS) {
+				l := getDefaultVMSSForModelTesting()
+				l.DataDisks = []VMSSDisk{{Lun: to.Int32Ptr(0), DiskSizeGB: 256}}
+				r := getDefaultVMSSForModelTesting()
+				return r, l
+			},
 			HasModelChanges: true,
 		},
 	}
@@ -161,5 +171,313 @@ func getDefaultVMSSForModelTesting() VMSS {
 		Tags: infrav1.Tags{
 			"foo": "baz",
 		},
+		OSDisk:    VMSSDisk{DiskSizeGB: 30, StorageAccountType: "Premium_LRS"},
+		DataDisks: []VMSSDisk{{Lun: to.Int32Ptr(0), DiskSizeGB: 128}},
+	}
+}
+
+func TestVMSS_HasInPlaceChanges(t *testing.T) {
+	cases := []struct {
+		Name       string
+		Factory    func() (VMSS, VMSS)
+		HasChanges bool
+	}{
+		{
+			Name: "two empty VMSS",
+			Factory: func() (VMSS, VMSS) {
+				return VMSS{}, VMSS{Extensions: []string{}}
+			},
+			HasChanges: false,
+		},
+		{
+			Name: "same default VMSS",
+			Factory: func() (VMSS, VMSS) {
+				return getDefaultVMSSForModelTesting(), getDefaultVMSSForModelTesting()
+			},
+			HasChanges: false,
+		},
+		{
+			Name: "with different identity",
+			Factory: func() (VMSS, VMSS) {
+				l := getDefaultVMSSForModelTesting()
+				l.Identity = infrav1.VMIdentityNone
+				return getDefaultVMSSForModelTesting(), l
+			},
+			HasChanges: true,
+		},
+		{
+			Name: "with different Tags",
+			Factory: func() (VMSS, VMSS) {
+				l := getDefaultVMSSForModelTesting()
+				l.Tags = infrav1.Tags{
+					"bin": "baz",
+				}
+				return getDefaultVMSSForModelTesting(), l
+			},
+			HasChanges: true,
+		},
+		{
+			Name: "with a different image",
+			Factory: func() (VMSS, VMSS) {
+				l := getDefaultVMSSForModelTesting()
+				l.Image = infrav1.Image{
+					ID: to.StringPtr("foo"),
+				}
+				return getDefaultVMSSForModelTesting(), l
+			},
+			HasChanges: false,
+		},
+		{
+			Name: "same extensions in a different order",
+			Factory: func() (VMSS, VMSS) {
+				return VMSS{Extensions: []string{"CAPZ.Linux.Bootstrapping", "ApplicationHealthLinux"}},
+					VMSS{Extensions: []string{"ApplicationHealthLinux", "CAPZ.Linux.Bootstrapping"}}
+			},
+			HasChanges: false,
+		},
+		{
+			Name: "with an added extension",
+			Factory: func() (VMSS, VMSS) {
+				return VMSS{Extensions: []string{"CAPZ.Linux.Bootstrapping"}},
+					VMSS{Extensions: []string{"CAPZ.Linux.Bootstrapping", "ApplicationHealthLinux"}}
+			},
+			HasChanges: true,
+		},
+		{
+			Name: "with automatic repairs enabled",
+			Factory: func() (VMSS, VMSS) {
+				return VMSS{}, VMSS{AutomaticRepairs: &AutomaticRepairsSpec{GracePeriod: "PT30M", RepairAction: "Replace"}}
+			},
+			HasChanges: true,
+		},
+		{
+			Name: "with a different repair action",
+			Factory: func() (VMSS, VMSS) {
+				return VMSS{AutomaticRepairs: &AutomaticRepairsSpec{GracePeriod: "PT30M", RepairAction: "Replace"}},
+					VMSS{AutomaticRepairs: &AutomaticRepairsSpec{GracePeriod: "PT30M", RepairAction: "Restart"}}
+			},
+			HasChanges: true,
+		},
+		{
+			Name: "with a rolling upgrade policy",
+			Factory: func() (VMSS, VMSS) {
+				return VMSS{}, VMSS{UpgradePolicy: &UpgradePolicySpec{Mode: "Rolling"}}
+			},
+			HasChanges: true,
+		},
+		{
+			Name: "with automatic OS upgrades enabled",
+			Factory: func() (VMSS, VMSS) {
+				return VMSS{UpgradePolicy: &UpgradePolicySpec{Mode: "Rolling"}},
+					VMSS{UpgradePolicy: &UpgradePolicySpec{Mode: "Rolling", AutomaticOSUpgrade: true}}
+			},
+			HasChanges: true,
+		},
+		{
+			Name: "with the platform defaults of unset rolling upgrade settings",
+			Factory: func() (VMSS, VMSS) {
+				return VMSS{UpgradePolicy: &UpgradePolicySpec{Mode: "Rolling", RollingUpgrade: &RollingUpgradePolicySpec{
+						MaxBatchInstancePercent:     to.Int32Ptr(50),
+						MaxUnhealthyInstancePercent: to.Int32Ptr(20),
+						PauseTimeBetweenBatches:     "PT0S",
+					}}},
+					VMSS{UpgradePolicy: &UpgradePolicySpec{Mode: "Rolling", RollingUpgrade: &RollingUpgradePolicySpec{
+						MaxBatchInstancePercent: to.Int32Ptr(50),
+					}}}
+			},
+			HasChanges: false,
+		},
+		{
+			Name: "with a different rolling upgrade batch size",
+			Factory: func() (VMSS, VMSS) {
+				return VMSS{UpgradePolicy: &UpgradePolicySpec{Mode: "Rolling", RollingUpgrade: &RollingUpgradePolicySpec{
+						MaxBatchInstancePercent: to.Int32Ptr(20),
+					}}},
+					VMSS{UpgradePolicy: &UpgradePolicySpec{Mode: "Rolling", RollingUpgrade: &RollingUpgradePolicySpec{
+						MaxBatchInstancePercent: to.Int32Ptr(50),
+					}}}
+			},
+			HasChanges: true,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			l, r := c.Factory()
+			g := NewWithT(t)
+			g.Expect(l.HasInPlaceChanges(r)).To(Equal(c.HasChanges))
+		})
+	}
+}
+
+func TestVMSS_HasLatestModelApplied(t *testing.T) {
+	vmss := getDefaultVMSSForModelTesting()
+
+	cases := []struct {
+		Name     string
+		Instance VMSSVM
+		Expected bool
+	}{
+		{
+			Name:     "instance with the image and the disk layout of the model",
+			Instance: VMSSVM{Image: vmss.Image, DataDisks: vmss.DataDisks},
+			Expected: true,
+		},
+		{
+			Name:     "instance with a different image",
+			Instance: VMSSVM{Image: infrav1.Image{ID: to.StringPtr("foo")}, DataDisks: vmss.DataDisks},
+			Expected: false,
+		},
+		{
+			Name:     "instance whose disk layout drifted",
+			Instance: VMSSVM{Image: vmss.Image, DataDisks: []VMSSDisk{{Lun: to.Int32Ptr(0), DiskSizeGB: 64}}},
+			Expected: false,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(vmss.HasLatestModelApplied(c.Instance)).To(Equal(c.Expected))
+		})
+	}
+}
+
+func TestVMSS_NeedsInPlaceUpdate(t *testing.T) {
+	vmss := getDefaultVMSSForModelTesting()
+
+	cases := []struct {
+		Name     string
+		Instance VMSSVM
+		Expected bool
+	}{
+		{
+			Name:     "instance with the latest model",
+			Instance: VMSSVM{Image: vmss.Image, Sku: vmss.Sku, DataDisks: vmss.DataDisks, LatestModelApplied: true},
+			Expected: false,
+		},
+		{
+			Name:     "instance only missing changes applied in place",
+			Instance: VMSSVM{Image: vmss.Image, Sku: vmss.Sku, DataDisks: vmss.DataDisks},
+			Expected: true,
+		},
+		{
+			Name:     "instance without a reported size",
+			Instance: VMSSVM{Image: vmss.Image, DataDisks: vmss.DataDisks},
+			Expected: true,
+		},
+		{
+			Name:     "instance with a different image",
+			Instance: VMSSVM{Image: infrav1.Image{ID: to.StringPtr("foo")}, Sku: vmss.Sku},
+			Expected: false,
+		},
+		{
+			Name:     "instance with a different size",
+			Instance: VMSSVM{Image: vmss.Image, Sku: "reallySmallVM"},
+			Expected: false,
+		},
+		{
+			Name:     "instance whose disk layout drifted",
+			Instance: VMSSVM{Image: vmss.Image, Sku: vmss.Sku, DataDisks: []VMSSDisk{{Lun: to.Int32Ptr(0), DiskSizeGB: 64}}},
+			Expected: false,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(vmss.NeedsInPlaceUpdate(c.Instance)).To(Equal(c.Expected))
+		})
+	}
+}
+
+func TestHasDiskLayout(t *testing.T) {
+	desiredOSDisk := VMSSDisk{DiskSizeGB: 30, StorageAccountType: "Premium_LRS"}
+	desiredDataDisks := []VMSSDisk{
+		{Lun: to.Int32Ptr(0), DiskSizeGB: 128},
+		{Lun: to.Int32Ptr(1), DiskSizeGB: 256, StorageAccountType: "Premium_LRS", DiskEncryptionSetID: "my-des"},
+	}
+
+	cases := []struct {
+		Name      string
+		OSDisk    VMSSDisk
+		DataDisks []VMSSDisk
+		Expected  bool
+	}{
+		{
+			Name:   "disks with the desired layout",
+			OSDisk: VMSSDisk{Name: "os", DiskSizeGB: 30, StorageAccountType: "Premium_LRS"},
+			DataDisks: []VMSSDisk{
+				{Name: "data-1", Lun: to.Int32Ptr(1), DiskSizeGB: 256, StorageAccountType: "premium_lrs", DiskEncryptionSetID: "MY-DES"},
+				{Name: "data-0", Lun: to.Int32Ptr(0), DiskSizeGB: 128, StorageAccountType: "Standard_LRS"},
+			},
+			Expected: true,
+		},
+		{
+			Name:   "disks with an attached persistent volume",
+			OSDisk: VMSSDisk{Name: "os", DiskSizeGB: 30, StorageAccountType: "Premium_LRS"},
+			DataDisks: []VMSSDisk{
+				{Name: "data-0", Lun: to.Int32Ptr(0), DiskSizeGB: 128},
+				{Name: "data-1", Lun: to.Int32Ptr(1), DiskSizeGB: 256, StorageAccountType: "Premium_LRS", DiskEncryptionSetID: "my-des"},
+				{Name: "pv", Lun: to.Int32Ptr(2), DiskSizeGB: 10, Attached: true},
+			},
+			Expected: true,
+		},
+		{
+			Name:   "disks without a reported OS disk size",
+			OSDisk: VMSSDisk{Name: "os", Ephemeral: true},
+			DataDisks: []VMSSDisk{
+				{Name: "data-0", Lun: to.Int32Ptr(0), DiskSizeGB: 128},
+				{Name: "data-1", Lun: to.Int32Ptr(1), DiskSizeGB: 256, StorageAccountType: "Premium_LRS", DiskEncryptionSetID: "my-des"},
+			},
+			Expected: true,
+		},
+		{
+			Name:   "disks with a different OS disk size",
+			OSDisk: VMSSDisk{Name: "os", DiskSizeGB: 64},
+			DataDisks: []VMSSDisk{
+				{Name: "data-0", Lun: to.Int32Ptr(0), DiskSizeGB: 128},
+				{Name: "data-1", Lun: to.Int32Ptr(1), DiskSizeGB: 256, StorageAccountType: "Premium_LRS", DiskEncryptionSetID: "my-des"},
+			},
+			Expected: false,
+		},
+		{
+			Name:   "disks missing a data disk",
+			OSDisk: VMSSDisk{Name: "os", DiskSizeGB: 30},
+			DataDisks: []VMSSDisk{
+				{Name: "data-0", Lun: to.Int32Ptr(0), DiskSizeGB: 128},
+			},
+			Expected: false,
+		},
+		{
+			Name:   "disks with an extra data disk",
+			OSDisk: VMSSDisk{Name: "os", DiskSizeGB: 30},
+			DataDisks: []VMSSDisk{
+				{Name: "data-0", Lun: to.Int32Ptr(0), DiskSizeGB: 128},
+				{Name: "data-1", Lun: to.Int32Ptr(1), DiskSizeGB: 256, StorageAccountType: "Premium_LRS", DiskEncryptionSetID: "my-des"},
+				{Name: "data-2", Lun: to.Int32Ptr(2), DiskSizeGB: 128},
+			},
+			Expected: false,
+		},
+		{
+			Name:   "disks with a different disk encryption set",
+			OSDisk: VMSSDisk{Name: "os", DiskSizeGB: 30},
+			DataDisks: []VMSSDisk{
+				{Name: "data-0", Lun: to.Int32Ptr(0), DiskSizeGB: 128},
+				{Name: "data-1", Lun: to.Int32Ptr(1), DiskSizeGB: 256, StorageAccountType: "Premium_LRS"},
+			},
+			Expected: false,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(HasDiskLayout(c.OSDisk, c.DataDisks, desiredOSDisk, desiredDataDisks)).To(Equal(c.Expected))
+		})
 	}
 }
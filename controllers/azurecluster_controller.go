@@ -28,6 +28,7 @@ import (
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/scope"
+	infrav1exp "sigs.k8s.io/cluster-api-provider-azure/exp/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/pkg/coalescing"
 	"sigs.k8s.io/cluster-api-provider-azure/util/reconciler"
 	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
@@ -257,9 +258,65 @@ func (acr *AzureClusterReconciler) reconcileNormal(ctx context.Context, clusterS
 	azureCluster.Status.Ready = true
 	conditions.MarkTrue(azureCluster, infrav1.NetworkInfrastructureReadyCondition)
 
+	// Report the progress of the hibernation or resumption of the machines of the cluster.
+	inProgress, err := updateHibernationStatus(ctx, acr.Client, azureCluster, clusterScope.ClusterName())
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to update the hibernation status of the cluster")
+	}
+	if inProgress {
+		return reconcile.Result{RequeueAfter: reconciler.DefaultReconcilerRequeue}, nil
+	}
+
 	return reconcile.Result{}, nil
 }
 
+// updateHibernationStatus reports the progress of the hibernation or resumption of the AzureMachines and
+// AzureMachinePools of the cluster in its Hibernated condition, and returns true while it is in progress.
+func updateHibernationStatus(ctx context.Context, c client.Client, azureCluster *infrav1.AzureCluster, clusterName string) (bool, error) {
+	machines := &infrav1.AzureMachineList{}
+	if err := c.List(ctx, machines, client.InNamespace(azureCluster.Namespace), client.MatchingLabels{clusterv1.ClusterLabelName: clusterName}); err != nil {
+		return false, errors.Wrap(err, "failed to list AzureMachines")
+	}
+	machinePools := &infrav1exp.AzureMachinePoolList{}
+	if err := c.List(ctx, machinePools, client.InNamespace(azureCluster.Namespace), client.MatchingLabels{clusterv1.ClusterLabelName: clusterName}); err != nil {
+		return false, errors.Wrap(err, "failed to list AzureMachinePools")
+	}
+
+	objs := make([]conditions.Getter, 0, len(machines.Items)+len(machinePools.Items))
+	for i := range machines.Items {
+		objs = append(objs, &machines.Items[i])
+	}
+	for i := range machinePools.Items {
+		objs = append(objs, &machinePools.Items[i])
+	}
+	var hibernated, pending int
+	for _, obj := range objs {
+		switch {
+		case conditions.IsTrue(obj, infrav1.HibernatedCondition):
+			hibernated++
+		case conditions.Has(obj, infrav1.HibernatedCondition):
+			pending++
+		}
+	}
+
+	switch {
+	case azureCluster.Spec.Hibernate && hibernated == len(objs):
+		conditions.MarkTrue(azureCluster, infrav1.HibernatedCondition)
+		return false, nil
+	case azureCluster.Spec.Hibernate:
+		conditions.MarkFalse(azureCluster, infrav1.HibernatedCondition, infrav1.HibernatingReason, clusterv1.ConditionSeverityInfo,
+			"%d of %d machines and machine pools hibernated", hibernated, len(objs))
+		return true, nil
+	case hibernated+pending == 0:
+		conditions.Delete(azureCluster, infrav1.HibernatedCondition)
+		return false, nil
+	default:
+		conditions.MarkFalse(azureCluster, infrav1.HibernatedCondition, infrav1.ResumingReason, clusterv1.ConditionSeverityInfo,
+			"%d of %d machines and machine pools still hibernated", hibernated+pending, len(objs))
+		return true, nil
+	}
+}
+
 func (acr *AzureClusterReconciler) reconcileDelete(ctx context.Context, clusterScope *scope.ClusterScope) (reconcile.Result, error) {
 	ctx, log, done := tele.StartSpanWithLogger(ctx, "controllers.AzureClusterReconciler.reconcileDelete")
 	defer done()
@@ -23,6 +23,8 @@ import (
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/scope"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/bastionhosts"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/bootstrapdata"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/capacityreservationgroups"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/groups"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/loadbalancers"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/natgateways"
@@ -68,6 +70,8 @@ func newAzureClusterService(scope *scope.ClusterScope) (*azureClusterService, er
 			loadbalancers.New(scope),
 			privatedns.New(scope),
 			bastionhosts.New(scope),
+			capacityreservationgroups.New(scope),
+			bootstrapdata.NewStorageAccountService(scope),
 			tags.New(scope),
 		},
 		skuCache: skuCache,
@@ -284,11 +284,25 @@ func (amr *AzureMachineReconciler) reconcileNormal(ctx context.Context, machineS
 		return reconcile.Result{}, errors.Wrap(err, "failed to init machine scope cache")
 	}
 
+	// Deallocate the VM while the cluster is hibernated, and keep MachineHealthChecks from remediating the machine
+	// until its VM has been resumed.
+	hibernate, err := ShouldHibernate(ctx, amr.Client, clusterScope.AzureCluster, clusterScope.ClusterName(), machineScope.AzureMachine, machineScope.IsControlPlane())
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to check if the machine should be hibernated")
+	}
+	machineScope.SetHibernate(hibernate)
+	if err := setSkipRemediation(ctx, amr.Client, machineScope.Machine, hibernate || machineScope.IsHibernated()); err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to update the remediation annotation of the machine")
+	}
+
 	ams, err := amr.createAzureMachineService(machineScope)
 	if err != nil {
 		return reconcile.Result{}, errors.Wrap(err, "failed to create azure machine service")
 	}
 
+	// Report the outcome of a requested VM action once the machine service has completed it.
+	defer RecordVMActionEvent(amr.Recorder, machineScope.AzureMachine, machineScope.Annotation(azure.VMActionAnnotation))
+
 	if err := ams.Reconcile(ctx); err != nil {
 		// This means that a VM was created and managed by this controller, but is not present anymore.
 		// In this case, we mark it as failed and leave it to MHC for remediation
@@ -305,6 +319,11 @@ func (amr *AzureMachineReconciler) reconcileNormal(ctx context.Context, machineS
 		if errors.As(err, &reconcileError) {
 			if reconcileError.IsTerminal() {
 				amr.Recorder.Eventf(machineScope.AzureMachine, corev1.EventTypeWarning, "ReconcileError", errors.Wrapf(err, "failed to reconcile AzureMachine").Error())
+				// Surface the serial console log of the VM when the bootstrap extension failed.
+				if message := conditions.GetMessage(machineScope.AzureMachine, infrav1.BootstrapSucceededCondition); message != "" &&
+					conditions.GetReason(machineScope.AzureMachine, infrav1.BootstrapSucceededCondition) == infrav1.BootstrapFailedReason {
+					amr.Recorder.Eventf(machineScope.AzureMachine, corev1.EventTypeWarning, infrav1.BootstrapFailedReason, "%s", message)
+				}
 				log.Error(err, "failed to reconcile AzureMachine", "name", machineScope.Name())
 				machineScope.SetFailureReason(capierrors.CreateMachineError)
 				machineScope.SetFailureMessage(err)
@@ -328,6 +347,13 @@ func (amr *AzureMachineReconciler) reconcileNormal(ctx context.Context, machineS
 
 	machineScope.SetReady()
 
+	// Control plane machines wait for the worker machines to be hibernated before they are hibernated themselves, and
+	// worker machines wait for the control plane machines to be resumed before they are resumed themselves.
+	if hibernate != clusterScope.AzureCluster.Spec.Hibernate {
+		log.V(2).Info("waiting for the other machines of the cluster to be hibernated or resumed")
+		return reconcile.Result{RequeueAfter: reconciler.DefaultReconcilerRequeue}, nil
+	}
+
 	return reconcile.Result{}, nil
 }
 
@@ -23,6 +23,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/scope"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/availabilitysets"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/bootstrapdata"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/disks"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/inboundnatrules"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/networkinterfaces"
@@ -51,6 +52,11 @@ func newAzureMachineService(machineScope *scope.MachineScope) (*azureMachineServ
 		return nil, errors.Wrap(err, "failed creating a NewCache")
 	}
 
+	bootstrapDataSvc, err := bootstrapdata.New(machineScope)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed creating the bootstrap data service")
+	}
+
 	return &azureMachineService{
 		scope: machineScope,
 		services: []azure.ServiceReconciler{
@@ -59,6 +65,7 @@ func newAzureMachineService(machineScope *scope.MachineScope) (*azureMachineServ
 			networkinterfaces.New(machineScope, cache),
 			availabilitysets.New(machineScope, cache),
 			disks.New(machineScope),
+			bootstrapDataSvc,
 			virtualmachines.New(machineScope),
 			roleassignments.New(machineScope),
 			vmextensions.New(machineScope),
@@ -0,0 +1,182 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/scope"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/resourceskus"
+	"sigs.k8s.io/cluster-api-provider-azure/util/reconciler"
+	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/predicates"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// AzureMachineTemplateReconciler reconciles the capacity of AzureMachineTemplate objects, which is used by the
+// cluster-autoscaler to scale node groups from zero.
+type AzureMachineTemplateReconciler struct {
+	client.Client
+	Recorder         record.EventRecorder
+	ReconcileTimeout time.Duration
+	WatchFilterValue string
+}
+
+// SetupWithManager initializes this controller with a manager.
+func (r *AzureMachineTemplateReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, options controller.Options) error {
+	_, log, done := tele.StartSpanWithLogger(ctx,
+		"controllers.AzureMachineTemplateReconciler.SetupWithManager",
+	)
+	defer done()
+
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(options).
+		For(&infrav1.AzureMachineTemplate{}).
+		WithEventFilter(predicates.ResourceNotPausedAndHasFilterLabel(log, r.WatchFilterValue)).
+		Complete(r)
+}
+
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=azuremachinetemplates;azuremachinetemplates/status,verbs=get;list;watch;update;patch
+
+// Reconcile reconciles the capacity of Azure machine templates.
+func (r *AzureMachineTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultedLoopTimeout(r.ReconcileTimeout))
+	defer cancel()
+
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "controllers.AzureMachineTemplateReconciler.Reconcile",
+		tele.KVP("namespace", req.Namespace),
+		tele.KVP("name", req.Name),
+		tele.KVP("kind", "AzureMachineTemplate"),
+	)
+	defer done()
+
+	// Fetch the AzureMachineTemplate instance
+	azureMachineTemplate := &infrav1.AzureMachineTemplate{}
+	err := r.Get(ctx, req.NamespacedName, azureMachineTemplate)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Info("object was not found")
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	// Fetch the Cluster.
+	cluster, err := util.GetOwnerCluster(ctx, r.Client, azureMachineTemplate.ObjectMeta)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if cluster == nil {
+		log.Info("Cluster Controller has not yet set OwnerRef")
+		return reconcile.Result{}, nil
+	}
+
+	log = log.WithValues("cluster", cluster.Name)
+
+	// Return early if the object or Cluster is paused.
+	if annotations.IsPaused(cluster, azureMachineTemplate) {
+		log.Info("AzureMachineTemplate or linked Cluster is marked as paused. Won't reconcile")
+		return ctrl.Result{}, nil
+	}
+
+	// only look at azure clusters
+	if cluster.Spec.InfrastructureRef.Kind != "AzureCluster" {
+		log.WithValues("kind", cluster.Spec.InfrastructureRef.Kind).Info("infra ref was not an AzureCluster")
+		return ctrl.Result{}, nil
+	}
+
+	// fetch the corresponding azure cluster
+	azureCluster := &infrav1.AzureCluster{}
+	azureClusterName := types.NamespacedName{
+		Namespace: req.Namespace,
+		Name:      cluster.Spec.InfrastructureRef.Name,
+	}
+
+	if err := r.Get(ctx, azureClusterName, azureCluster); err != nil {
+		log.Error(err, "failed to fetch AzureCluster")
+		return reconcile.Result{}, err
+	}
+
+	// Create the scope.
+	clusterScope, err := scope.NewClusterScope(ctx, scope.ClusterScopeParams{
+		Client:       r.Client,
+		Cluster:      cluster,
+		AzureCluster: azureCluster,
+	})
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to create scope")
+	}
+
+	skuCache, err := resourceskus.GetCache(clusterScope, clusterScope.Location())
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to create a NewCache")
+	}
+
+	patchHelper, err := patch.NewHelper(azureMachineTemplate, r.Client)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to init patch helper")
+	}
+
+	if err := r.reconcileCapacity(ctx, azureMachineTemplate, skuCache); err != nil {
+		r.Recorder.Eventf(azureMachineTemplate, corev1.EventTypeWarning, "CapacityReconcileFailed", err.Error())
+		return reconcile.Result{}, err
+	}
+
+	if err := patchHelper.Patch(ctx, azureMachineTemplate); err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to patch AzureMachineTemplate")
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// reconcileCapacity sets the capacity annotations and status of the AzureMachineTemplate from its VM size.
+func (r *AzureMachineTemplateReconciler) reconcileCapacity(ctx context.Context, azureMachineTemplate *infrav1.AzureMachineTemplate, skuCache *resourceskus.Cache) error {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "controllers.AzureMachineTemplateReconciler.reconcileCapacity")
+	defer done()
+
+	vmSize := azureMachineTemplate.Spec.Template.Spec.VMSize
+	sku, err := skuCache.Get(ctx, vmSize, resourceskus.VirtualMachines)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get SKU %s", vmSize)
+	}
+
+	vmCapacity, err := sku.ResourceCapacity()
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the capacity of SKU %s", vmSize)
+	}
+
+	capacity, err := SetAutoscalerCapacity(azureMachineTemplate, vmCapacity)
+	if err != nil {
+		return err
+	}
+	azureMachineTemplate.Status.Capacity = capacity
+
+	return nil
+}
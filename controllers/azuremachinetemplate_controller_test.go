@@ -0,0 +1,156 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/resourceskus"
+)
+
+func TestAzureMachineTemplateReconciler_reconcileCapacity(t *testing.T) {
+	skuCache := resourceskus.NewStaticCache([]compute.ResourceSku{
+		{
+			Name: to.StringPtr("Standard_D4s_v3"),
+			Capabilities: &[]compute.ResourceSkuCapabilities{
+				{Name: to.StringPtr(resourceskus.VCPUs), Value: to.StringPtr("4")},
+				{Name: to.StringPtr(resourceskus.MemoryGB), Value: to.StringPtr("16")},
+			},
+		},
+		{
+			Name: to.StringPtr("Standard_NC6s_v3"),
+			Capabilities: &[]compute.ResourceSkuCapabilities{
+				{Name: to.StringPtr(resourceskus.VCPUs), Value: to.StringPtr("6")},
+				{Name: to.StringPtr(resourceskus.MemoryGB), Value: to.StringPtr("112")},
+				{Name: to.StringPtr(resourceskus.GPUs), Value: to.StringPtr("1")},
+			},
+		},
+	}, "")
+
+	cases := map[string]struct {
+		vmSize              string
+		annotations         map[string]string
+		expectedCapacity    corev1.ResourceList
+		expectedAnnotations map[string]string
+		expectedError       string
+	}{
+		"sets the capacity of the vm size": {
+			vmSize: "Standard_D4s_v3",
+			expectedCapacity: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("16Gi"),
+				corev1.ResourcePods:   resource.MustParse("110"),
+			},
+			expectedAnnotations: map[string]string{
+				azure.AutoscalerCPUCapacityAnnotation:     "4",
+				azure.AutoscalerMemoryCapacityAnnotation:  "16Gi",
+				azure.AutoscalerMaxPodsCapacityAnnotation: "110",
+			},
+		},
+		"sets the gpus of the vm size": {
+			vmSize: "Standard_NC6s_v3",
+			expectedCapacity: corev1.ResourceList{
+				corev1.ResourceCPU:     resource.MustParse("6"),
+				corev1.ResourceMemory:  resource.MustParse("112Gi"),
+				corev1.ResourcePods:    resource.MustParse("110"),
+				resourceskus.NvidiaGPU: resource.MustParse("1"),
+			},
+			expectedAnnotations: map[string]string{
+				azure.AutoscalerCPUCapacityAnnotation:      "6",
+				azure.AutoscalerMemoryCapacityAnnotation:   "112Gi",
+				azure.AutoscalerMaxPodsCapacityAnnotation:  "110",
+				azure.AutoscalerGPUCountCapacityAnnotation: "1",
+				azure.AutoscalerGPUTypeCapacityAnnotation:  "nvidia.com/gpu",
+			},
+		},
+		"keeps the max pods set by the user and removes the gpus of the previous vm size": {
+			vmSize: "Standard_D4s_v3",
+			annotations: map[string]string{
+				azure.AutoscalerCPUCapacityAnnotation:      "6",
+				azure.AutoscalerMemoryCapacityAnnotation:   "112Gi",
+				azure.AutoscalerMaxPodsCapacityAnnotation:  "30",
+				azure.AutoscalerGPUCountCapacityAnnotation: "1",
+				azure.AutoscalerGPUTypeCapacityAnnotation:  "nvidia.com/gpu",
+				"foo": "bar",
+			},
+			expectedCapacity: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("16Gi"),
+				corev1.ResourcePods:   resource.MustParse("30"),
+			},
+			expectedAnnotations: map[string]string{
+				azure.AutoscalerCPUCapacityAnnotation:     "4",
+				azure.AutoscalerMemoryCapacityAnnotation:  "16Gi",
+				azure.AutoscalerMaxPodsCapacityAnnotation: "30",
+				"foo": "bar",
+			},
+		},
+		"invalid max pods": {
+			vmSize: "Standard_D4s_v3",
+			annotations: map[string]string{
+				azure.AutoscalerMaxPodsCapacityAnnotation: "many",
+			},
+			expectedError: "failed to parse annotation capacity.cluster-autoscaler.kubernetes.io/maxPods: quantities must match the regular expression '^([+-]?[0-9.]+)([eEinumkKMGTP]*[-+]?[0-9]*)$'",
+		},
+		"unknown vm size": {
+			vmSize:        "Standard_Foo",
+			expectedError: "failed to get SKU Standard_Foo",
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			azureMachineTemplate := &infrav1.AzureMachineTemplate{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "my-template",
+					Annotations: tc.annotations,
+				},
+				Spec: infrav1.AzureMachineTemplateSpec{
+					Template: infrav1.AzureMachineTemplateResource{
+						Spec: infrav1.AzureMachineSpec{
+							VMSize: tc.vmSize,
+						},
+					},
+				},
+			}
+
+			r := &AzureMachineTemplateReconciler{}
+			err := r.reconcileCapacity(context.Background(), azureMachineTemplate, skuCache)
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring(tc.expectedError))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(equality.Semantic.DeepEqual(azureMachineTemplate.Status.Capacity, tc.expectedCapacity)).To(BeTrue(), "unexpected capacity %v", azureMachineTemplate.Status.Capacity)
+			g.Expect(azureMachineTemplate.Annotations).To(Equal(tc.expectedAnnotations))
+		})
+	}
+}
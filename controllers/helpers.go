@@ -26,15 +26,18 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/scope"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/groups"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/resourceskus"
 	infrav1exp "sigs.k8s.io/cluster-api-provider-azure/exp/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/pkg/coalescing"
 	"sigs.k8s.io/cluster-api-provider-azure/util/reconciler"
@@ -42,6 +45,9 @@ import (
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	capiv1exp "sigs.k8s.io/cluster-api/exp/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/patch"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
@@ -50,6 +56,10 @@ import (
 )
 
 const (
+	// hibernationSkipRemediationValue is the value of the skip-remediation annotations added to machines while their
+	// cluster is hibernated, so that annotations added by users are left alone when the cluster is resumed.
+	hibernationSkipRemediationValue = "hibernated"
+
 	spIdentityWarning = "You are using Service Principal authentication for Cloud Provider Azure which is less secure than Managed Identity. " +
 		"Your Service Principal credentials will be written to a file on the disk of each VM in order to be accessible by Cloud Provider. " +
 		"To learn more, see https://capz.sigs.k8s.io/topics/identities-use-cases.html#azure-host-identity "
@@ -597,3 +607,129 @@ func GetClusterIdentityFromRef(ctx context.Context, c client.Client, azureCluste
 	}
 	return nil, nil
 }
+
+// RecordVMActionEvent records an event with the outcome of the action requested on obj with azure.VMActionAnnotation,
+// once the action has completed or failed and the annotation has been removed.
+func RecordVMActionEvent(recorder record.EventRecorder, obj conditions.Getter, action string) {
+	if action == "" || obj.GetAnnotations()[azure.VMActionAnnotation] != "" {
+		return
+	}
+
+	if conditions.IsTrue(obj, infrav1.VMActionCondition) {
+		recorder.Eventf(obj, corev1.EventTypeNormal, "VMActionSucceeded", "VM action %q completed", action)
+		return
+	}
+	recorder.Eventf(obj, corev1.EventTypeWarning, "VMActionFailed", "VM action %q failed: %s", action, conditions.GetMessage(obj, infrav1.VMActionCondition))
+}
+
+// ShouldHibernate returns true if the VMs of a machine or machine pool should be hibernated. While the cluster is
+// hibernated, the control plane machines are hibernated last so that the worker machines can still be drained. While
+// the cluster is resumed, the worker machines are resumed once all the control plane machines have been resumed.
+func ShouldHibernate(ctx context.Context, c client.Client, azureCluster *infrav1.AzureCluster, clusterName string, obj conditions.Getter, controlPlane bool) (bool, error) {
+	switch {
+	case azureCluster.Spec.Hibernate && controlPlane:
+		return workersHibernated(ctx, c, azureCluster.Namespace, clusterName)
+	case azureCluster.Spec.Hibernate:
+		return true, nil
+	case !controlPlane && conditions.Has(obj, infrav1.HibernatedCondition):
+		return controlPlaneHibernated(ctx, c, azureCluster.Namespace, clusterName)
+	default:
+		return false, nil
+	}
+}
+
+// workersHibernated returns true if all the worker AzureMachines and AzureMachinePools of the cluster are hibernated.
+func workersHibernated(ctx context.Context, c client.Client, namespace, clusterName string) (bool, error) {
+	machines := &infrav1.AzureMachineList{}
+	if err := c.List(ctx, machines, client.InNamespace(namespace), client.MatchingLabels{clusterv1.ClusterLabelName: clusterName}); err != nil {
+		return false, errors.Wrap(err, "failed to list AzureMachines")
+	}
+	for i := range machines.Items {
+		if _, ok := machines.Items[i].Labels[clusterv1.MachineControlPlaneLabelName]; !ok && !conditions.IsTrue(&machines.Items[i], infrav1.HibernatedCondition) {
+			return false, nil
+		}
+	}
+
+	machinePools := &infrav1exp.AzureMachinePoolList{}
+	if err := c.List(ctx, machinePools, client.InNamespace(namespace), client.MatchingLabels{clusterv1.ClusterLabelName: clusterName}); err != nil {
+		return false, errors.Wrap(err, "failed to list AzureMachinePools")
+	}
+	for i := range machinePools.Items {
+		if !conditions.IsTrue(&machinePools.Items[i], infrav1.HibernatedCondition) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// controlPlaneHibernated returns true if any control plane AzureMachine of the cluster is still hibernated.
+func controlPlaneHibernated(ctx context.Context, c client.Client, namespace, clusterName string) (bool, error) {
+	machines := &infrav1.AzureMachineList{}
+	if err := c.List(ctx, machines,
+		client.InNamespace(namespace),
+		client.MatchingLabels{clusterv1.ClusterLabelName: clusterName},
+		client.HasLabels{clusterv1.MachineControlPlaneLabelName},
+	); err != nil {
+		return false, errors.Wrap(err, "failed to list control plane AzureMachines")
+	}
+	for i := range machines.Items {
+		if conditions.Has(&machines.Items[i], infrav1.HibernatedCondition) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// setSkipRemediation adds the annotation that keeps MachineHealthChecks from remediating a machine while its VM is
+// hibernated, and removes it once the VM has been resumed.
+func setSkipRemediation(ctx context.Context, c client.Client, machine *clusterv1.Machine, skip bool) error {
+	value, ok := machine.Annotations[clusterv1.MachineSkipRemediationAnnotation]
+	if skip == ok || (ok && value != hibernationSkipRemediationValue) {
+		return nil
+	}
+
+	helper, err := patch.NewHelper(machine, c)
+	if err != nil {
+		return errors.Wrap(err, "failed to init patch helper")
+	}
+	if skip {
+		annotations.AddAnnotations(machine, map[string]string{clusterv1.MachineSkipRemediationAnnotation: hibernationSkipRemediationValue})
+	} else {
+		delete(machine.Annotations, clusterv1.MachineSkipRemediationAnnotation)
+	}
+	return helper.Patch(ctx, machine)
+}
+
+// SetAutoscalerCapacity sets the cluster-autoscaler capacity annotations of obj from the capacity of its VM size, and
+// returns that capacity completed with the maximum number of pods of its nodes. The maximum number of pods defaults to
+// the kubelet default and is taken from the maxPods annotation when it has been set.
+func SetAutoscalerCapacity(obj metav1.Object, vmCapacity corev1.ResourceList) (corev1.ResourceList, error) {
+	objAnnotations := obj.GetAnnotations()
+	if objAnnotations == nil {
+		objAnnotations = map[string]string{}
+	}
+
+	capacity := vmCapacity.DeepCopy()
+	capacity[corev1.ResourcePods] = *resource.NewQuantity(azure.DefaultMaxPods, resource.DecimalSI)
+	if maxPods, ok := objAnnotations[azure.AutoscalerMaxPodsCapacityAnnotation]; ok {
+		pods, err := resource.ParseQuantity(maxPods)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse annotation %s", azure.AutoscalerMaxPodsCapacityAnnotation)
+		}
+		capacity[corev1.ResourcePods] = pods
+	}
+
+	objAnnotations[azure.AutoscalerCPUCapacityAnnotation] = capacity.Cpu().String()
+	objAnnotations[azure.AutoscalerMemoryCapacityAnnotation] = capacity.Memory().String()
+	objAnnotations[azure.AutoscalerMaxPodsCapacityAnnotation] = capacity.Pods().String()
+	if _, ok := capacity[resourceskus.NvidiaGPU]; ok {
+		objAnnotations[azure.AutoscalerGPUCountCapacityAnnotation] = capacity.Name(resourceskus.NvidiaGPU, resource.DecimalSI).String()
+		objAnnotations[azure.AutoscalerGPUTypeCapacityAnnotation] = string(resourceskus.NvidiaGPU)
+	} else {
+		delete(objAnnotations, azure.AutoscalerGPUCountCapacityAnnotation)
+		delete(objAnnotations, azure.AutoscalerGPUTypeCapacityAnnotation)
+	}
+	obj.SetAnnotations(objAnnotations)
+
+	return capacity, nil
+}
@@ -34,10 +34,15 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/scope"
+	infrav1exp "sigs.k8s.io/cluster-api-provider-azure/exp/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/internal/test/mock_log"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -679,3 +684,280 @@ const (
     "cloudProviderBackoffJitter": 1.2000000000000002
 }`
 )
+
+func TestRecordVMActionEvent(t *testing.T) {
+	tests := []struct {
+		name        string
+		action      string
+		annotations map[string]string
+		conditions  clusterv1.Conditions
+		want        []string
+	}{
+		{
+			name:   "no action requested",
+			action: "",
+			want:   nil,
+		},
+		{
+			name:        "action still in progress",
+			action:      azure.VMActionRestart,
+			annotations: map[string]string{azure.VMActionAnnotation: azure.VMActionRestart},
+			conditions: clusterv1.Conditions{
+				*conditions.FalseCondition(infrav1.VMActionCondition, infrav1.UpdatingReason, clusterv1.ConditionSeverityInfo, "virtualmachine-action updating"),
+			},
+			want: nil,
+		},
+		{
+			name:       "action completed",
+			action:     azure.VMActionRestart,
+			conditions: clusterv1.Conditions{*conditions.TrueCondition(infrav1.VMActionCondition)},
+			want:       []string{"Normal VMActionSucceeded VM action \"restart\" completed"},
+		},
+		{
+			name:   "action failed",
+			action: azure.VMActionReimage,
+			conditions: clusterv1.Conditions{
+				*conditions.FalseCondition(infrav1.VMActionCondition, infrav1.FailedReason, clusterv1.ConditionSeverityError, "boom"),
+			},
+			want: []string{"Warning VMActionFailed VM action \"reimage\" failed: boom"},
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			recorder := record.NewFakeRecorder(10)
+			azureMachine := &infrav1.AzureMachine{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations},
+				Status:     infrav1.AzureMachineStatus{Conditions: tc.conditions},
+			}
+
+			RecordVMActionEvent(recorder, azureMachine, tc.action)
+			close(recorder.Events)
+
+			var got []string
+			for event := range recorder.Events {
+				got = append(got, event)
+			}
+			g.Expect(got).To(Equal(tc.want))
+		})
+	}
+}
+
+func hibernationTestObjects(workersHibernated, controlPlaneHibernated bool) []client.Object {
+	labels := map[string]string{clusterv1.ClusterLabelName: "my-cluster"}
+	controlPlaneLabels := map[string]string{clusterv1.ClusterLabelName: "my-cluster", clusterv1.MachineControlPlaneLabelName: ""}
+	var workerConditions, controlPlaneConditions clusterv1.Conditions
+	if workersHibernated {
+		workerConditions = clusterv1.Conditions{*conditions.TrueCondition(infrav1.HibernatedCondition)}
+	}
+	if controlPlaneHibernated {
+		controlPlaneConditions = clusterv1.Conditions{*conditions.TrueCondition(infrav1.HibernatedCondition)}
+	}
+	return []client.Object{
+		&infrav1.AzureMachine{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "default", Labels: labels},
+			Status:     infrav1.AzureMachineStatus{Conditions: workerConditions},
+		},
+		&infrav1.AzureMachine{
+			ObjectMeta: metav1.ObjectMeta{Name: "control-plane", Namespace: "default", Labels: controlPlaneLabels},
+			Status:     infrav1.AzureMachineStatus{Conditions: controlPlaneConditions},
+		},
+		&infrav1exp.AzureMachinePool{
+			ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: "default", Labels: labels},
+			Status:     infrav1exp.AzureMachinePoolStatus{Conditions: workerConditions},
+		},
+	}
+}
+
+func TestShouldHibernate(t *testing.T) {
+	hibernated := clusterv1.Conditions{*conditions.TrueCondition(infrav1.HibernatedCondition)}
+	tests := []struct {
+		name                   string
+		hibernate              bool
+		controlPlane           bool
+		conditions             clusterv1.Conditions
+		workersHibernated      bool
+		controlPlaneHibernated bool
+		want                   bool
+	}{
+		{
+			name: "cluster is not hibernated",
+			want: false,
+		},
+		{
+			name:      "worker is hibernated with the cluster",
+			hibernate: true,
+			want:      true,
+		},
+		{
+			name:         "control plane waits for the workers to be hibernated",
+			hibernate:    true,
+			controlPlane: true,
+			want:         false,
+		},
+		{
+			name:              "control plane is hibernated after the workers",
+			hibernate:         true,
+			controlPlane:      true,
+			workersHibernated: true,
+			want:              true,
+		},
+		{
+			name:                   "worker waits for the control plane to be resumed",
+			conditions:             hibernated,
+			workersHibernated:      true,
+			controlPlaneHibernated: true,
+			want:                   true,
+		},
+		{
+			name:              "worker is resumed after the control plane",
+			conditions:        hibernated,
+			workersHibernated: true,
+			want:              false,
+		},
+		{
+			name:                   "control plane is resumed first",
+			controlPlane:           true,
+			conditions:             hibernated,
+			workersHibernated:      true,
+			controlPlaneHibernated: true,
+			want:                   false,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			scheme := runtime.NewScheme()
+			g.Expect(infrav1.AddToScheme(scheme)).To(Succeed())
+			g.Expect(infrav1exp.AddToScheme(scheme)).To(Succeed())
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(hibernationTestObjects(tc.workersHibernated, tc.controlPlaneHibernated)...).Build()
+			azureCluster := &infrav1.AzureCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-azure-cluster", Namespace: "default"},
+				Spec:       infrav1.AzureClusterSpec{Hibernate: tc.hibernate},
+			}
+			azureMachine := &infrav1.AzureMachine{
+				Status: infrav1.AzureMachineStatus{Conditions: tc.conditions},
+			}
+
+			got, err := ShouldHibernate(context.TODO(), c, azureCluster, "my-cluster", azureMachine, tc.controlPlane)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tc.want))
+		})
+	}
+}
+
+func TestSetSkipRemediation(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		skip        bool
+		want        map[string]string
+	}{
+		{
+			name: "adds the annotation while hibernated",
+			skip: true,
+			want: map[string]string{clusterv1.MachineSkipRemediationAnnotation: hibernationSkipRemediationValue},
+		},
+		{
+			name:        "removes the annotation once resumed",
+			annotations: map[string]string{clusterv1.MachineSkipRemediationAnnotation: hibernationSkipRemediationValue},
+			skip:        false,
+			want:        map[string]string{},
+		},
+		{
+			name:        "leaves an annotation added by a user",
+			annotations: map[string]string{clusterv1.MachineSkipRemediationAnnotation: ""},
+			skip:        false,
+			want:        map[string]string{clusterv1.MachineSkipRemediationAnnotation: ""},
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			scheme := runtime.NewScheme()
+			g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+			machine := &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-machine", Namespace: "default", Annotations: tc.annotations},
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(machine).Build()
+
+			g.Expect(setSkipRemediation(context.TODO(), c, machine, tc.skip)).To(Succeed())
+
+			got := &clusterv1.Machine{}
+			g.Expect(c.Get(context.TODO(), client.ObjectKeyFromObject(machine), got)).To(Succeed())
+			if len(tc.want) == 0 {
+				g.Expect(got.Annotations).To(BeEmpty())
+			} else {
+				g.Expect(got.Annotations).To(Equal(tc.want))
+			}
+		})
+	}
+}
+
+func TestUpdateHibernationStatus(t *testing.T) {
+	tests := []struct {
+		name                   string
+		hibernate              bool
+		workersHibernated      bool
+		controlPlaneHibernated bool
+		wantInProgress         bool
+		wantReason             string
+		wantStatus             corev1.ConditionStatus
+	}{
+		{
+			name:       "cluster is not hibernated",
+			wantStatus: "",
+		},
+		{
+			name:              "cluster is hibernating",
+			hibernate:         true,
+			workersHibernated: true,
+			wantInProgress:    true,
+			wantReason:        infrav1.HibernatingReason,
+			wantStatus:        corev1.ConditionFalse,
+		},
+		{
+			name:                   "cluster is hibernated",
+			hibernate:              true,
+			workersHibernated:      true,
+			controlPlaneHibernated: true,
+			wantStatus:             corev1.ConditionTrue,
+		},
+		{
+			name:              "cluster is resuming",
+			workersHibernated: true,
+			wantInProgress:    true,
+			wantReason:        infrav1.ResumingReason,
+			wantStatus:        corev1.ConditionFalse,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			scheme := runtime.NewScheme()
+			g.Expect(infrav1.AddToScheme(scheme)).To(Succeed())
+			g.Expect(infrav1exp.AddToScheme(scheme)).To(Succeed())
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(hibernationTestObjects(tc.workersHibernated, tc.controlPlaneHibernated)...).Build()
+			azureCluster := &infrav1.AzureCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-azure-cluster", Namespace: "default"},
+				Spec:       infrav1.AzureClusterSpec{Hibernate: tc.hibernate},
+			}
+
+			inProgress, err := updateHibernationStatus(context.TODO(), c, azureCluster, "my-cluster")
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(inProgress).To(Equal(tc.wantInProgress))
+			condition := conditions.Get(azureCluster, infrav1.HibernatedCondition)
+			if tc.wantStatus == "" {
+				g.Expect(condition).To(BeNil())
+				return
+			}
+			g.Expect(condition).NotTo(BeNil())
+			g.Expect(condition.Status).To(Equal(tc.wantStatus))
+			g.Expect(condition.Reason).To(Equal(tc.wantReason))
+		})
+	}
+}
@@ -47,6 +47,31 @@ func (src *AzureMachinePool) ConvertTo(dstRaw conversion.Hub) error {
 	}
 
 	dst.Spec.Template.SubnetName = restored.Spec.Template.SubnetName
+	dst.Spec.Template.CapacityReservationGroupID = restored.Spec.Template.CapacityReservationGroupID
+	dst.Spec.Template.Diagnostics = restored.Spec.Template.Diagnostics
+	dst.Spec.Template.BootstrapDataDelivery = restored.Spec.Template.BootstrapDataDelivery
+	restoreDataDisks(dst.Spec.Template.DataDisks, restored.Spec.Template.DataDisks)
+	restoreImage(dst.Spec.Template.Image, restored.Spec.Template.Image)
+	dst.Spec.ImageVersionUpdatePolicy = restored.Spec.ImageVersionUpdatePolicy
+	dst.Spec.ApplicationHealth = restored.Spec.ApplicationHealth
+	dst.Spec.OrchestrationMode = restored.Spec.OrchestrationMode
+	dst.Spec.UpgradePolicy = restored.Spec.UpgradePolicy
+	dst.Spec.PriorityMixPolicy = restored.Spec.PriorityMixPolicy
+	dst.Spec.Strategy.BlueGreen = restored.Spec.Strategy.BlueGreen
+	dst.Status.BlueGreen = restored.Status.BlueGreen
+	dst.Status.RolloutResumeTime = restored.Status.RolloutResumeTime
+	dst.Status.Capacity = restored.Status.Capacity
+	for i, instance := range dst.Status.Instances {
+		if instance != nil && i < len(restored.Status.Instances) && restored.Status.Instances[i] != nil {
+			instance.Priority = restored.Status.Instances[i].Priority
+			instance.Evicted = restored.Status.Instances[i].Evicted
+			instance.HealthState = restored.Status.Instances[i].HealthState
+		}
+	}
+
+	if dst.Spec.Template.OSDisk.DiffDiskSettings != nil && restored.Spec.Template.OSDisk.DiffDiskSettings != nil {
+		dst.Spec.Template.OSDisk.DiffDiskSettings.Placement = restored.Spec.Template.OSDisk.DiffDiskSettings.Placement
+	}
 
 	dst.Spec.Strategy.Type = restored.Spec.Strategy.Type
 	if restored.Spec.Strategy.RollingUpdate != nil {
@@ -56,6 +81,9 @@ func (src *AzureMachinePool) ConvertTo(dstRaw conversion.Hub) error {
 		}
 
 		dst.Spec.Strategy.RollingUpdate.DeletePolicy = restored.Spec.Strategy.RollingUpdate.DeletePolicy
+		dst.Spec.Strategy.RollingUpdate.BalanceZones = restored.Spec.Strategy.RollingUpdate.BalanceZones
+		dst.Spec.Strategy.RollingUpdate.DrainBlockedTimeout = restored.Spec.Strategy.RollingUpdate.DrainBlockedTimeout
+		dst.Spec.Strategy.RollingUpdate.NodeReadyTimeout = restored.Spec.Strategy.RollingUpdate.NodeReadyTimeout
 	}
 
 	if restored.Spec.NodeDrainTimeout != nil {
@@ -104,6 +132,10 @@ func Convert_v1beta1_AzureMachinePoolSpec_To_v1alpha3_AzureMachinePoolSpec(in *e
 	return autoConvert_v1beta1_AzureMachinePoolSpec_To_v1alpha3_AzureMachinePoolSpec(in, out, s)
 }
 
+func Convert_v1beta1_AzureMachinePoolInstanceStatus_To_v1alpha3_AzureMachinePoolInstanceStatus(in *expv1beta1.AzureMachinePoolInstanceStatus, out *AzureMachinePoolInstanceStatus, s convert.Scope) error {
+	return autoConvert_v1beta1_AzureMachinePoolInstanceStatus_To_v1alpha3_AzureMachinePoolInstanceStatus(in, out, s)
+}
+
 func Convert_v1beta1_AzureMachinePoolStatus_To_v1alpha3_AzureMachinePoolStatus(in *expv1beta1.AzureMachinePoolStatus, out *AzureMachinePoolStatus, s convert.Scope) error {
 	if len(in.LongRunningOperationStates) > 0 {
 		if out.LongRunningOperationState == nil {
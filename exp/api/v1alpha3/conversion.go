@@ -34,6 +34,16 @@ func Convert_v1beta1_OSDisk_To_v1alpha3_OSDisk(in *v1beta1.OSDisk, out *v1alpha3
 	return v1alpha3.Convert_v1beta1_OSDisk_To_v1alpha3_OSDisk(in, out, s)
 }
 
+// Convert_v1alpha3_DataDisk_To_v1beta1_DataDisk is a conversion function.
+func Convert_v1alpha3_DataDisk_To_v1beta1_DataDisk(in *v1alpha3.DataDisk, out *v1beta1.DataDisk, s conversion.Scope) error {
+	return v1alpha3.Convert_v1alpha3_DataDisk_To_v1beta1_DataDisk(in, out, s)
+}
+
+// Convert_v1beta1_DataDisk_To_v1alpha3_DataDisk is a conversion function.
+func Convert_v1beta1_DataDisk_To_v1alpha3_DataDisk(in *v1beta1.DataDisk, out *v1alpha3.DataDisk, s conversion.Scope) error {
+	return v1alpha3.Convert_v1beta1_DataDisk_To_v1alpha3_DataDisk(in, out, s)
+}
+
 // Convert_v1alpha3_Image_To_v1beta1_Image is a conversion function.
 func Convert_v1alpha3_Image_To_v1beta1_Image(in *v1alpha3.Image, out *v1beta1.Image, s conversion.Scope) error {
 	return v1alpha3.Convert_v1alpha3_Image_To_v1beta1_Image(in, out, s)
@@ -53,3 +63,25 @@ func Convert_v1alpha3_APIEndpoint_To_v1beta1_APIEndpoint(in *clusterapiapiv1alph
 func Convert_v1beta1_APIEndpoint_To_v1alpha3_APIEndpoint(in *clusterapiapiv1beta1.APIEndpoint, out *clusterapiapiv1alpha3.APIEndpoint, s conversion.Scope) error {
 	return clusterapiapiv1alpha3.Convert_v1beta1_APIEndpoint_To_v1alpha3_APIEndpoint(in, out, s)
 }
+
+// restoreDataDisks restores the fields of the data disks that don't exist in this version.
+func restoreDataDisks(dst, restored []v1beta1.DataDisk) {
+	if len(dst) != len(restored) {
+		return
+	}
+	for i := range dst {
+		dst[i].SourceDiskID = restored[i].SourceDiskID
+		dst[i].DiskIOPSReadWrite = restored[i].DiskIOPSReadWrite
+		dst[i].DiskMBpsReadWrite = restored[i].DiskMBpsReadWrite
+		dst[i].MaxShares = restored[i].MaxShares
+	}
+}
+
+// restoreImage restores the fields of the image that don't exist in this version.
+func restoreImage(dst, restored *v1beta1.Image) {
+	if dst == nil || restored == nil {
+		return
+	}
+	dst.CommunityGallery = restored.CommunityGallery
+	dst.DirectSharedGallery = restored.DirectSharedGallery
+}
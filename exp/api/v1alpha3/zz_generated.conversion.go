@@ -66,11 +66,6 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1beta1.AzureMachinePoolInstanceStatus)(nil), (*AzureMachinePoolInstanceStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1beta1_AzureMachinePoolInstanceStatus_To_v1alpha3_AzureMachinePoolInstanceStatus(a.(*v1beta1.AzureMachinePoolInstanceStatus), b.(*AzureMachinePoolInstanceStatus), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*AzureMachinePoolList)(nil), (*v1beta1.AzureMachinePoolList)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha3_AzureMachinePoolList_To_v1beta1_AzureMachinePoolList(a.(*AzureMachinePoolList), b.(*v1beta1.AzureMachinePoolList), scope)
 	}); err != nil {
@@ -221,6 +216,11 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddConversionFunc((*clusterapiproviderazureapiv1alpha3.DataDisk)(nil), (*clusterapiproviderazureapiv1beta1.DataDisk)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_DataDisk_To_v1beta1_DataDisk(a.(*clusterapiproviderazureapiv1alpha3.DataDisk), b.(*clusterapiproviderazureapiv1beta1.DataDisk), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddConversionFunc((*clusterapiproviderazureapiv1alpha3.Image)(nil), (*clusterapiproviderazureapiv1beta1.Image)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha3_Image_To_v1beta1_Image(a.(*clusterapiproviderazureapiv1alpha3.Image), b.(*clusterapiproviderazureapiv1beta1.Image), scope)
 	}); err != nil {
@@ -236,6 +236,11 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddConversionFunc((*v1beta1.AzureMachinePoolInstanceStatus)(nil), (*AzureMachinePoolInstanceStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_AzureMachinePoolInstanceStatus_To_v1alpha3_AzureMachinePoolInstanceStatus(a.(*v1beta1.AzureMachinePoolInstanceStatus), b.(*AzureMachinePoolInstanceStatus), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddConversionFunc((*v1beta1.AzureMachinePoolMachineTemplate)(nil), (*AzureMachinePoolMachineTemplate)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta1_AzureMachinePoolMachineTemplate_To_v1alpha3_AzureMachinePoolMachineTemplate(a.(*v1beta1.AzureMachinePoolMachineTemplate), b.(*AzureMachinePoolMachineTemplate), scope)
 	}); err != nil {
@@ -271,6 +276,11 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddConversionFunc((*clusterapiproviderazureapiv1beta1.DataDisk)(nil), (*clusterapiproviderazureapiv1alpha3.DataDisk)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_DataDisk_To_v1alpha3_DataDisk(a.(*clusterapiproviderazureapiv1beta1.DataDisk), b.(*clusterapiproviderazureapiv1alpha3.DataDisk), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddConversionFunc((*clusterapiproviderazureapiv1beta1.Image)(nil), (*clusterapiproviderazureapiv1alpha3.Image)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta1_Image_To_v1alpha3_Image(a.(*clusterapiproviderazureapiv1beta1.Image), b.(*clusterapiproviderazureapiv1alpha3.Image), scope)
 	}); err != nil {
@@ -360,14 +370,12 @@ func autoConvert_v1beta1_AzureMachinePoolInstanceStatus_To_v1alpha3_AzureMachine
 	out.InstanceID = in.InstanceID
 	out.InstanceName = in.InstanceName
 	out.LatestModelApplied = in.LatestModelApplied
+	// WARNING: in.Priority requires manual conversion: does not exist in peer-type
+	// WARNING: in.Evicted requires manual conversion: does not exist in peer-type
+	// WARNING: in.HealthState requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1beta1_AzureMachinePoolInstanceStatus_To_v1alpha3_AzureMachinePoolInstanceStatus is an autogenerated conversion function.
-func Convert_v1beta1_AzureMachinePoolInstanceStatus_To_v1alpha3_AzureMachinePoolInstanceStatus(in *v1beta1.AzureMachinePoolInstanceStatus, out *AzureMachinePoolInstanceStatus, s conversion.Scope) error {
-	return autoConvert_v1beta1_AzureMachinePoolInstanceStatus_To_v1alpha3_AzureMachinePoolInstanceStatus(in, out, s)
-}
-
 func autoConvert_v1alpha3_AzureMachinePoolList_To_v1beta1_AzureMachinePoolList(in *AzureMachinePoolList, out *v1beta1.AzureMachinePoolList, s conversion.Scope) error {
 	out.ListMeta = in.ListMeta
 	if in.Items != nil {
@@ -424,7 +432,17 @@ func autoConvert_v1alpha3_AzureMachinePoolMachineTemplate_To_v1beta1_AzureMachin
 	if err := Convert_v1alpha3_OSDisk_To_v1beta1_OSDisk(&in.OSDisk, &out.OSDisk, s); err != nil {
 		return err
 	}
-	out.DataDisks = *(*[]clusterapiproviderazureapiv1beta1.DataDisk)(unsafe.Pointer(&in.DataDisks))
+	if in.DataDisks != nil {
+		in, out := &in.DataDisks, &out.DataDisks
+		*out = make([]clusterapiproviderazureapiv1beta1.DataDisk, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha3_DataDisk_To_v1beta1_DataDisk(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.DataDisks = nil
+	}
 	out.SSHPublicKey = in.SSHPublicKey
 	out.AcceleratedNetworking = (*bool)(unsafe.Pointer(in.AcceleratedNetworking))
 	out.TerminateNotificationTimeout = (*int)(unsafe.Pointer(in.TerminateNotificationTimeout))
@@ -452,13 +470,26 @@ func autoConvert_v1beta1_AzureMachinePoolMachineTemplate_To_v1alpha3_AzureMachin
 	if err := Convert_v1beta1_OSDisk_To_v1alpha3_OSDisk(&in.OSDisk, &out.OSDisk, s); err != nil {
 		return err
 	}
-	out.DataDisks = *(*[]clusterapiproviderazureapiv1alpha3.DataDisk)(unsafe.Pointer(&in.DataDisks))
+	if in.DataDisks != nil {
+		in, out := &in.DataDisks, &out.DataDisks
+		*out = make([]clusterapiproviderazureapiv1alpha3.DataDisk, len(*in))
+		for i := range *in {
+			if err := Convert_v1beta1_DataDisk_To_v1alpha3_DataDisk(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.DataDisks = nil
+	}
 	out.SSHPublicKey = in.SSHPublicKey
 	out.AcceleratedNetworking = (*bool)(unsafe.Pointer(in.AcceleratedNetworking))
 	out.TerminateNotificationTimeout = (*int)(unsafe.Pointer(in.TerminateNotificationTimeout))
 	out.SecurityProfile = (*clusterapiproviderazureapiv1alpha3.SecurityProfile)(unsafe.Pointer(in.SecurityProfile))
+	// WARNING: in.Diagnostics requires manual conversion: does not exist in peer-type
+	// WARNING: in.BootstrapDataDelivery requires manual conversion: does not exist in peer-type
 	out.SpotVMOptions = (*clusterapiproviderazureapiv1alpha3.SpotVMOptions)(unsafe.Pointer(in.SpotVMOptions))
 	// WARNING: in.SubnetName requires manual conversion: does not exist in peer-type
+	// WARNING: in.CapacityReservationGroupID requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -494,13 +525,34 @@ func autoConvert_v1beta1_AzureMachinePoolSpec_To_v1alpha3_AzureMachinePoolSpec(i
 	out.RoleAssignmentName = in.RoleAssignmentName
 	// WARNING: in.Strategy requires manual conversion: does not exist in peer-type
 	// WARNING: in.NodeDrainTimeout requires manual conversion: does not exist in peer-type
+	// WARNING: in.ImageVersionUpdatePolicy requires manual conversion: does not exist in peer-type
+	// WARNING: in.ApplicationHealth requires manual conversion: does not exist in peer-type
+	// WARNING: in.OrchestrationMode requires manual conversion: does not exist in peer-type
+	// WARNING: in.UpgradePolicy requires manual conversion: does not exist in peer-type
+	// WARNING: in.PriorityMixPolicy requires manual conversion: does not exist in peer-type
 	return nil
 }
 
 func autoConvert_v1alpha3_AzureMachinePoolStatus_To_v1beta1_AzureMachinePoolStatus(in *AzureMachinePoolStatus, out *v1beta1.AzureMachinePoolStatus, s conversion.Scope) error {
 	out.Ready = in.Ready
 	out.Replicas = in.Replicas
-	out.Instances = *(*[]*v1beta1.AzureMachinePoolInstanceStatus)(unsafe.Pointer(&in.Instances))
+	if in.Instances != nil {
+		in, out := &in.Instances, &out.Instances
+		*out = make([]*v1beta1.AzureMachinePoolInstanceStatus, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(v1beta1.AzureMachinePoolInstanceStatus)
+				if err := Convert_v1alpha3_AzureMachinePoolInstanceStatus_To_v1beta1_AzureMachinePoolInstanceStatus(*in, *out, s); err != nil {
+					return err
+				}
+			} else {
+				(*out)[i] = nil
+			}
+		}
+	} else {
+		out.Instances = nil
+	}
 	out.Version = in.Version
 	out.ProvisioningState = (*clusterapiproviderazureapiv1beta1.ProvisioningState)(unsafe.Pointer(in.ProvisioningState))
 	out.FailureReason = (*errors.MachineStatusError)(unsafe.Pointer(in.FailureReason))
@@ -513,7 +565,23 @@ func autoConvert_v1alpha3_AzureMachinePoolStatus_To_v1beta1_AzureMachinePoolStat
 func autoConvert_v1beta1_AzureMachinePoolStatus_To_v1alpha3_AzureMachinePoolStatus(in *v1beta1.AzureMachinePoolStatus, out *AzureMachinePoolStatus, s conversion.Scope) error {
 	out.Ready = in.Ready
 	out.Replicas = in.Replicas
-	out.Instances = *(*[]*AzureMachinePoolInstanceStatus)(unsafe.Pointer(&in.Instances))
+	if in.Instances != nil {
+		in, out := &in.Instances, &out.Instances
+		*out = make([]*AzureMachinePoolInstanceStatus, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(AzureMachinePoolInstanceStatus)
+				if err := Convert_v1beta1_AzureMachinePoolInstanceStatus_To_v1alpha3_AzureMachinePoolInstanceStatus(*in, *out, s); err != nil {
+					return err
+				}
+			} else {
+				(*out)[i] = nil
+			}
+		}
+	} else {
+		out.Instances = nil
+	}
 	// WARNING: in.Image requires manual conversion: does not exist in peer-type
 	out.Version = in.Version
 	out.ProvisioningState = (*clusterapiproviderazureapiv1alpha3.VMState)(unsafe.Pointer(in.ProvisioningState))
@@ -521,6 +589,9 @@ func autoConvert_v1beta1_AzureMachinePoolStatus_To_v1alpha3_AzureMachinePoolStat
 	out.FailureMessage = (*string)(unsafe.Pointer(in.FailureMessage))
 	out.Conditions = *(*apiv1alpha3.Conditions)(unsafe.Pointer(&in.Conditions))
 	// WARNING: in.LongRunningOperationStates requires manual conversion: does not exist in peer-type
+	// WARNING: in.BlueGreen requires manual conversion: does not exist in peer-type
+	// WARNING: in.RolloutResumeTime requires manual conversion: does not exist in peer-type
+	// WARNING: in.Capacity requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -17,20 +17,70 @@ limitations under the License.
 package v1alpha4
 
 import (
+	apiconversion "k8s.io/apimachinery/pkg/conversion"
 	expv1beta1 "sigs.k8s.io/cluster-api-provider-azure/exp/api/v1beta1"
+	utilconversion "sigs.k8s.io/cluster-api/util/conversion"
 	"sigs.k8s.io/controller-runtime/pkg/conversion"
 )
 
 // ConvertTo converts this AzureMachinePool to the Hub version (v1beta1).
 func (src *AzureMachinePool) ConvertTo(dstRaw conversion.Hub) error {
 	dst := dstRaw.(*expv1beta1.AzureMachinePool)
-	return Convert_v1alpha4_AzureMachinePool_To_v1beta1_AzureMachinePool(src, dst, nil)
+	if err := Convert_v1alpha4_AzureMachinePool_To_v1beta1_AzureMachinePool(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Restore missing fields from annotations
+	restored := &expv1beta1.AzureMachinePool{}
+	if ok, err := utilconversion.UnmarshalData(src, restored); err != nil || !ok {
+		return err
+	}
+
+	dst.Spec.Template.CapacityReservationGroupID = restored.Spec.Template.CapacityReservationGroupID
+	dst.Spec.Template.Diagnostics = restored.Spec.Template.Diagnostics
+	dst.Spec.Template.BootstrapDataDelivery = restored.Spec.Template.BootstrapDataDelivery
+	restoreDataDisks(dst.Spec.Template.DataDisks, restored.Spec.Template.DataDisks)
+	restoreImage(dst.Spec.Template.Image, restored.Spec.Template.Image)
+	dst.Spec.ImageVersionUpdatePolicy = restored.Spec.ImageVersionUpdatePolicy
+	dst.Spec.ApplicationHealth = restored.Spec.ApplicationHealth
+	dst.Spec.OrchestrationMode = restored.Spec.OrchestrationMode
+	dst.Spec.UpgradePolicy = restored.Spec.UpgradePolicy
+	dst.Spec.PriorityMixPolicy = restored.Spec.PriorityMixPolicy
+	dst.Spec.Strategy.BlueGreen = restored.Spec.Strategy.BlueGreen
+	dst.Status.BlueGreen = restored.Status.BlueGreen
+	dst.Status.RolloutResumeTime = restored.Status.RolloutResumeTime
+	dst.Status.Capacity = restored.Status.Capacity
+	for i, instance := range dst.Status.Instances {
+		if instance != nil && i < len(restored.Status.Instances) && restored.Status.Instances[i] != nil {
+			instance.Priority = restored.Status.Instances[i].Priority
+			instance.Evicted = restored.Status.Instances[i].Evicted
+			instance.HealthState = restored.Status.Instances[i].HealthState
+		}
+	}
+	restoreImage(dst.Status.Image, restored.Status.Image)
+
+	if dst.Spec.Template.OSDisk.DiffDiskSettings != nil && restored.Spec.Template.OSDisk.DiffDiskSettings != nil {
+		dst.Spec.Template.OSDisk.DiffDiskSettings.Placement = restored.Spec.Template.OSDisk.DiffDiskSettings.Placement
+	}
+
+	if dst.Spec.Strategy.RollingUpdate != nil && restored.Spec.Strategy.RollingUpdate != nil {
+		dst.Spec.Strategy.RollingUpdate.BalanceZones = restored.Spec.Strategy.RollingUpdate.BalanceZones
+		dst.Spec.Strategy.RollingUpdate.DrainBlockedTimeout = restored.Spec.Strategy.RollingUpdate.DrainBlockedTimeout
+		dst.Spec.Strategy.RollingUpdate.NodeReadyTimeout = restored.Spec.Strategy.RollingUpdate.NodeReadyTimeout
+	}
+
+	return nil
 }
 
 // ConvertFrom converts from the Hub version (v1beta1) to this version.
 func (dst *AzureMachinePool) ConvertFrom(srcRaw conversion.Hub) error {
 	src := srcRaw.(*expv1beta1.AzureMachinePool)
-	return Convert_v1beta1_AzureMachinePool_To_v1alpha4_AzureMachinePool(src, dst, nil)
+	if err := Convert_v1beta1_AzureMachinePool_To_v1alpha4_AzureMachinePool(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Preserve Hub data on down-conversion.
+	return utilconversion.MarshalData(src, dst)
 }
 
 // ConvertTo converts this AzureMachinePool to the Hub version (v1beta1).
@@ -44,3 +94,33 @@ func (dst *AzureMachinePoolList) ConvertFrom(srcRaw conversion.Hub) error {
 	src := srcRaw.(*expv1beta1.AzureMachinePoolList)
 	return Convert_v1beta1_AzureMachinePoolList_To_v1alpha4_AzureMachinePoolList(src, dst, nil)
 }
+
+// Convert_v1beta1_AzureMachinePoolMachineTemplate_To_v1alpha4_AzureMachinePoolMachineTemplate converts from the Hub version (v1beta1) of the AzureMachinePoolMachineTemplate to this version.
+func Convert_v1beta1_AzureMachinePoolMachineTemplate_To_v1alpha4_AzureMachinePoolMachineTemplate(in *expv1beta1.AzureMachinePoolMachineTemplate, out *AzureMachinePoolMachineTemplate, s apiconversion.Scope) error {
+	return autoConvert_v1beta1_AzureMachinePoolMachineTemplate_To_v1alpha4_AzureMachinePoolMachineTemplate(in, out, s)
+}
+
+// Convert_v1beta1_AzureMachinePoolSpec_To_v1alpha4_AzureMachinePoolSpec converts from the Hub version (v1beta1) of the AzureMachinePoolSpec to this version.
+func Convert_v1beta1_AzureMachinePoolSpec_To_v1alpha4_AzureMachinePoolSpec(in *expv1beta1.AzureMachinePoolSpec, out *AzureMachinePoolSpec, s apiconversion.Scope) error {
+	return autoConvert_v1beta1_AzureMachinePoolSpec_To_v1alpha4_AzureMachinePoolSpec(in, out, s)
+}
+
+// Convert_v1beta1_AzureMachinePoolDeploymentStrategy_To_v1alpha4_AzureMachinePoolDeploymentStrategy converts from the Hub version (v1beta1) of the AzureMachinePoolDeploymentStrategy to this version.
+func Convert_v1beta1_AzureMachinePoolDeploymentStrategy_To_v1alpha4_AzureMachinePoolDeploymentStrategy(in *expv1beta1.AzureMachinePoolDeploymentStrategy, out *AzureMachinePoolDeploymentStrategy, s apiconversion.Scope) error {
+	return autoConvert_v1beta1_AzureMachinePoolDeploymentStrategy_To_v1alpha4_AzureMachinePoolDeploymentStrategy(in, out, s)
+}
+
+// Convert_v1beta1_AzureMachinePoolInstanceStatus_To_v1alpha4_AzureMachinePoolInstanceStatus converts from the Hub version (v1beta1) of the AzureMachinePoolInstanceStatus to this version.
+func Convert_v1beta1_AzureMachinePoolInstanceStatus_To_v1alpha4_AzureMachinePoolInstanceStatus(in *expv1beta1.AzureMachinePoolInstanceStatus, out *AzureMachinePoolInstanceStatus, s apiconversion.Scope) error {
+	return autoConvert_v1beta1_AzureMachinePoolInstanceStatus_To_v1alpha4_AzureMachinePoolInstanceStatus(in, out, s)
+}
+
+// Convert_v1beta1_AzureMachinePoolStatus_To_v1alpha4_AzureMachinePoolStatus converts from the Hub version (v1beta1) of the AzureMachinePoolStatus to this version.
+func Convert_v1beta1_AzureMachinePoolStatus_To_v1alpha4_AzureMachinePoolStatus(in *expv1beta1.AzureMachinePoolStatus, out *AzureMachinePoolStatus, s apiconversion.Scope) error {
+	return autoConvert_v1beta1_AzureMachinePoolStatus_To_v1alpha4_AzureMachinePoolStatus(in, out, s)
+}
+
+// Convert_v1beta1_MachineRollingUpdateDeployment_To_v1alpha4_MachineRollingUpdateDeployment converts from the Hub version (v1beta1) of the MachineRollingUpdateDeployment to this version.
+func Convert_v1beta1_MachineRollingUpdateDeployment_To_v1alpha4_MachineRollingUpdateDeployment(in *expv1beta1.MachineRollingUpdateDeployment, out *MachineRollingUpdateDeployment, s apiconversion.Scope) error {
+	return autoConvert_v1beta1_MachineRollingUpdateDeployment_To_v1alpha4_MachineRollingUpdateDeployment(in, out, s)
+}
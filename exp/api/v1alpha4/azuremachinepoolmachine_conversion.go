@@ -17,20 +17,42 @@ limitations under the License.
 package v1alpha4
 
 import (
+	apiconversion "k8s.io/apimachinery/pkg/conversion"
 	expv1beta1 "sigs.k8s.io/cluster-api-provider-azure/exp/api/v1beta1"
+	utilconversion "sigs.k8s.io/cluster-api/util/conversion"
 	"sigs.k8s.io/controller-runtime/pkg/conversion"
 )
 
 // ConvertTo converts this AzureMachinePoolMachine to the Hub version (v1beta1).
 func (src *AzureMachinePoolMachine) ConvertTo(dstRaw conversion.Hub) error {
 	dst := dstRaw.(*expv1beta1.AzureMachinePoolMachine)
-	return Convert_v1alpha4_AzureMachinePoolMachine_To_v1beta1_AzureMachinePoolMachine(src, dst, nil)
+	if err := Convert_v1alpha4_AzureMachinePoolMachine_To_v1beta1_AzureMachinePoolMachine(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Restore missing fields from annotations
+	restored := &expv1beta1.AzureMachinePoolMachine{}
+	if ok, err := utilconversion.UnmarshalData(src, restored); err != nil || !ok {
+		return err
+	}
+
+	dst.Status.HealthState = restored.Status.HealthState
+	dst.Status.AvailabilityZone = restored.Status.AvailabilityZone
+	dst.Status.ProtectionPolicy = restored.Status.ProtectionPolicy
+	dst.Status.Disks = restored.Status.Disks
+
+	return nil
 }
 
 // ConvertFrom converts from the Hub version (v1beta1) to this version.
 func (dst *AzureMachinePoolMachine) ConvertFrom(srcRaw conversion.Hub) error {
 	src := srcRaw.(*expv1beta1.AzureMachinePoolMachine)
-	return Convert_v1beta1_AzureMachinePoolMachine_To_v1alpha4_AzureMachinePoolMachine(src, dst, nil)
+	if err := Convert_v1beta1_AzureMachinePoolMachine_To_v1alpha4_AzureMachinePoolMachine(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Preserve Hub data on down-conversion.
+	return utilconversion.MarshalData(src, dst)
 }
 
 // ConvertTo converts this AzureMachinePoolMachineList to the Hub version (v1beta1).
@@ -44,3 +66,8 @@ func (dst *AzureMachinePoolMachineList) ConvertFrom(srcRaw conversion.Hub) error
 	src := srcRaw.(*expv1beta1.AzureMachinePoolMachineList)
 	return Convert_v1beta1_AzureMachinePoolMachineList_To_v1alpha4_AzureMachinePoolMachineList(src, dst, nil)
 }
+
+// Convert_v1beta1_AzureMachinePoolMachineStatus_To_v1alpha4_AzureMachinePoolMachineStatus converts from the Hub version (v1beta1) of the AzureMachinePoolMachineStatus to this version.
+func Convert_v1beta1_AzureMachinePoolMachineStatus_To_v1alpha4_AzureMachinePoolMachineStatus(in *expv1beta1.AzureMachinePoolMachineStatus, out *AzureMachinePoolMachineStatus, s apiconversion.Scope) error {
+	return autoConvert_v1beta1_AzureMachinePoolMachineStatus_To_v1alpha4_AzureMachinePoolMachineStatus(in, out, s)
+}
@@ -18,8 +18,10 @@ package v1beta1
 
 import (
 	"encoding/base64"
+	"time"
 
 	"golang.org/x/crypto/ssh"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/uuid"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	utilSSH "sigs.k8s.io/cluster-api-provider-azure/util/ssh"
@@ -38,6 +40,20 @@ func (amp *AzureMachinePool) SetDefaultSSHPublicKey() error {
 	return nil
 }
 
+// SetDefaultImage sets the image of an AzureMachinePool which doesn't specify one to the default image for all
+// Kubernetes versions of its OS type, if the default image resolver has one.
+func (amp *AzureMachinePool) SetDefaultImage() error {
+	if amp.Spec.Template.Image != nil {
+		return nil
+	}
+	image, err := infrav1.GetDefaultImageForAllVersions(amp.Spec.Template.OSDisk.OSType)
+	if err != nil {
+		return err
+	}
+	amp.Spec.Template.Image = image
+	return nil
+}
+
 // SetIdentityDefaults sets the defaults for VMSS Identity.
 func (amp *AzureMachinePool) SetIdentityDefaults() {
 	if amp.Spec.Identity == infrav1.VMIdentitySystemAssigned {
@@ -46,3 +62,41 @@ func (amp *AzureMachinePool) SetIdentityDefaults() {
 		}
 	}
 }
+
+// SetApplicationHealthDefaults sets the defaults for the Application Health extension.
+func (amp *AzureMachinePool) SetApplicationHealthDefaults() {
+	health := amp.Spec.ApplicationHealth
+	if health == nil {
+		return
+	}
+	if health.Protocol == "" {
+		health.Protocol = HTTPApplicationHealthProtocol
+	}
+	if health.Port == 0 {
+		health.Port = DefaultApplicationHealthPort
+	}
+	if health.RequestPath == "" && health.Protocol != TCPApplicationHealthProtocol {
+		health.RequestPath = DefaultApplicationHealthRequestPath
+	}
+	if repairs := health.AutomaticRepairs; repairs != nil {
+		if repairs.GracePeriod == nil {
+			repairs.GracePeriod = &metav1.Duration{Duration: 30 * time.Minute}
+		}
+		if repairs.RepairAction == "" {
+			repairs.RepairAction = ReplaceAutomaticRepairAction
+		}
+	}
+}
+
+// SetStrategyDefaults sets the defaults for the blue/green deployment strategy.
+func (amp *AzureMachinePool) SetStrategyDefaults() {
+	if amp.Spec.Strategy.Type != BlueGreenAzureMachinePoolDeploymentStrategyType {
+		return
+	}
+	if amp.Spec.Strategy.BlueGreen == nil {
+		amp.Spec.Strategy.BlueGreen = &MachineBlueGreenDeployment{}
+	}
+	if amp.Spec.Strategy.BlueGreen.ReadyTimeout == nil {
+		amp.Spec.Strategy.BlueGreen.ReadyTimeout = &metav1.Duration{Duration: 30 * time.Minute}
+	}
+}
@@ -18,9 +18,11 @@ package v1beta1
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 )
 
@@ -76,6 +78,79 @@ func TestAzureMachinePool_SetIdentityDefaults(t *testing.T) {
 	g.Expect(notSystemAssignedTest.machinePool.Spec.RoleAssignmentName).To(BeEmpty())
 }
 
+func TestAzureMachinePool_SetApplicationHealthDefaults(t *testing.T) {
+	g := NewWithT(t)
+
+	httpTest := &AzureMachinePool{Spec: AzureMachinePoolSpec{
+		ApplicationHealth: &ApplicationHealth{
+			AutomaticRepairs: &AutomaticRepairs{},
+		},
+	}}
+	tcpTest := &AzureMachinePool{Spec: AzureMachinePoolSpec{
+		ApplicationHealth: &ApplicationHealth{
+			Protocol: TCPApplicationHealthProtocol,
+			Port:     8080,
+		},
+	}}
+	noApplicationHealthTest := &AzureMachinePool{}
+
+	httpTest.SetApplicationHealthDefaults()
+	g.Expect(httpTest.Spec.ApplicationHealth).To(Equal(&ApplicationHealth{
+		Protocol:    HTTPApplicationHealthProtocol,
+		Port:        DefaultApplicationHealthPort,
+		RequestPath: DefaultApplicationHealthRequestPath,
+		AutomaticRepairs: &AutomaticRepairs{
+			GracePeriod:  &metav1.Duration{Duration: 30 * time.Minute},
+			RepairAction: ReplaceAutomaticRepairAction,
+		},
+	}))
+
+	tcpTest.SetApplicationHealthDefaults()
+	g.Expect(tcpTest.Spec.ApplicationHealth).To(Equal(&ApplicationHealth{
+		Protocol: TCPApplicationHealthProtocol,
+		Port:     8080,
+	}))
+
+	noApplicationHealthTest.SetApplicationHealthDefaults()
+	g.Expect(noApplicationHealthTest.Spec.ApplicationHealth).To(BeNil())
+}
+
+func TestAzureMachinePool_SetStrategyDefaults(t *testing.T) {
+	g := NewWithT(t)
+
+	blueGreenTest := &AzureMachinePool{Spec: AzureMachinePoolSpec{
+		Strategy: AzureMachinePoolDeploymentStrategy{
+			Type: BlueGreenAzureMachinePoolDeploymentStrategyType,
+		},
+	}}
+	customTimeoutTest := &AzureMachinePool{Spec: AzureMachinePoolSpec{
+		Strategy: AzureMachinePoolDeploymentStrategy{
+			Type: BlueGreenAzureMachinePoolDeploymentStrategyType,
+			BlueGreen: &MachineBlueGreenDeployment{
+				ReadyTimeout: &metav1.Duration{Duration: 10 * time.Minute},
+			},
+		},
+	}}
+	rollingUpdateTest := &AzureMachinePool{Spec: AzureMachinePoolSpec{
+		Strategy: AzureMachinePoolDeploymentStrategy{
+			Type: RollingUpdateAzureMachinePoolDeploymentStrategyType,
+		},
+	}}
+
+	blueGreenTest.SetStrategyDefaults()
+	g.Expect(blueGreenTest.Spec.Strategy.BlueGreen).To(Equal(&MachineBlueGreenDeployment{
+		ReadyTimeout: &metav1.Duration{Duration: 30 * time.Minute},
+	}))
+
+	customTimeoutTest.SetStrategyDefaults()
+	g.Expect(customTimeoutTest.Spec.Strategy.BlueGreen).To(Equal(&MachineBlueGreenDeployment{
+		ReadyTimeout: &metav1.Duration{Duration: 10 * time.Minute},
+	}))
+
+	rollingUpdateTest.SetStrategyDefaults()
+	g.Expect(rollingUpdateTest.Spec.Strategy.BlueGreen).To(BeNil())
+}
+
 func createMachinePoolWithSSHPublicKey(sshPublicKey string) *AzureMachinePool {
 	return hardcodedAzureMachinePoolWithSSHKey(sshPublicKey)
 }
@@ -76,7 +76,7 @@ func TestAzureMachinePool_Validate(t *testing.T) {
 			},
 			Expect: func(g *gomega.GomegaWithT, actual error) {
 				g.Expect(actual).To(gomega.HaveOccurred())
-				g.Expect(actual.Error()).To(gomega.ContainSubstring("You must supply a ID, Marketplace or SharedGallery image details"))
+				g.Expect(actual.Error()).To(gomega.ContainSubstring("You must supply a ID, Marketplace, SharedGallery, CommunityGallery or DirectSharedGallery image details"))
 			},
 		},
 		{
@@ -134,7 +134,7 @@ func TestAzureMachinePool_Validate(t *testing.T) {
 			t.Parallel()
 			g := gomega.NewGomegaWithT(t)
 			amp := c.Factory(g)
-			actualErr := amp.Validate(nil)
+			actualErr := amp.Validate(nil, nil)
 			c.Expect(g, actualErr)
 		})
 	}
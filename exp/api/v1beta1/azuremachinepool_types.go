@@ -17,6 +17,7 @@ limitations under the License.
 package v1beta1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
@@ -28,17 +29,85 @@ const (
 	// MachinePoolNameLabel indicates the AzureMachinePool name the AzureMachinePoolMachine belongs.
 	MachinePoolNameLabel = "azuremachinepool.infrastructure.cluster.x-k8s.io/machine-pool"
 
+	// ScaleSetNameLabel indicates the name of the scale set the AzureMachinePoolMachine belongs to. It is only set on
+	// the AzureMachinePoolMachines of scale sets deployed by the BlueGreen deployment strategy, the others belong to
+	// the default scale set of the AzureMachinePool.
+	ScaleSetNameLabel = "azuremachinepool.infrastructure.cluster.x-k8s.io/scale-set"
+
+	// ResumeRolloutAnnotation resumes a rolling update paused by the AzureMachinePool controller when set on the
+	// AzureMachinePool. The machines which paused the rolling update are only considered again once the
+	// DrainBlockedTimeout or NodeReadyTimeout has passed since it was resumed. The annotation is removed once the rolling
+	// update is resumed.
+	ResumeRolloutAnnotation = "azuremachinepool.infrastructure.cluster.x-k8s.io/resume-rollout"
+
 	// RollingUpdateAzureMachinePoolDeploymentStrategyType replaces AzureMachinePoolMachines with older models with
 	// AzureMachinePoolMachines based on the latest model.
 	// i.e. gradually scale down the old AzureMachinePoolMachines and scale up the new ones.
 	RollingUpdateAzureMachinePoolDeploymentStrategyType AzureMachinePoolDeploymentStrategyType = "RollingUpdate"
 
+	// BlueGreenAzureMachinePoolDeploymentStrategyType replaces the scale set of the AzureMachinePool with a new scale
+	// set based on the latest model.
+	// i.e. create the new scale set, wait for all of its AzureMachinePoolMachines to be ready, then drain and delete
+	// the old scale set.
+	BlueGreenAzureMachinePoolDeploymentStrategyType AzureMachinePoolDeploymentStrategyType = "BlueGreen"
+
 	// OldestDeletePolicyType will delete machines with the oldest creation date first.
 	OldestDeletePolicyType AzureMachinePoolDeletePolicyType = "Oldest"
 	// NewestDeletePolicyType will delete machines with the newest creation date first.
 	NewestDeletePolicyType AzureMachinePoolDeletePolicyType = "Newest"
 	// RandomDeletePolicyType will delete machines in random order.
 	RandomDeletePolicyType AzureMachinePoolDeletePolicyType = "Random"
+
+	// PinnedImageVersionUpdatePolicy keeps the image version resolved when the spec image is set until it changes.
+	PinnedImageVersionUpdatePolicy ImageVersionUpdatePolicy = "Pinned"
+	// RollingImageVersionUpdatePolicy updates the model of the machine pool to newer image versions matching the spec
+	// image as they are published, which rolls out the AzureMachinePoolMachines according to the deployment strategy.
+	RollingImageVersionUpdatePolicy ImageVersionUpdatePolicy = "Rolling"
+
+	// HTTPApplicationHealthProtocol probes the instances with HTTP requests.
+	HTTPApplicationHealthProtocol ApplicationHealthProtocol = "http"
+	// HTTPSApplicationHealthProtocol probes the instances with HTTPS requests.
+	HTTPSApplicationHealthProtocol ApplicationHealthProtocol = "https"
+	// TCPApplicationHealthProtocol probes the instances by opening TCP connections.
+	TCPApplicationHealthProtocol ApplicationHealthProtocol = "tcp"
+
+	// DefaultApplicationHealthPort is the port of the kubelet healthz endpoint.
+	DefaultApplicationHealthPort = 10248
+	// DefaultApplicationHealthRequestPath is the path of the kubelet healthz endpoint.
+	DefaultApplicationHealthRequestPath = "/healthz"
+
+	// ReplaceAutomaticRepairAction deletes unhealthy instances and creates new ones.
+	ReplaceAutomaticRepairAction AutomaticRepairAction = "Replace"
+	// RestartAutomaticRepairAction restarts unhealthy instances.
+	RestartAutomaticRepairAction AutomaticRepairAction = "Restart"
+	// ReimageAutomaticRepairAction reimages unhealthy instances.
+	ReimageAutomaticRepairAction AutomaticRepairAction = "Reimage"
+
+	// HealthyInstanceHealthState is the health state of instances whose endpoint is healthy.
+	HealthyInstanceHealthState InstanceHealthState = "Healthy"
+	// UnhealthyInstanceHealthState is the health state of instances whose endpoint is unhealthy.
+	UnhealthyInstanceHealthState InstanceHealthState = "Unhealthy"
+	// InitializingInstanceHealthState is the health state of instances whose endpoint hasn't been healthy yet.
+	InitializingInstanceHealthState InstanceHealthState = "Initializing"
+	// UnknownInstanceHealthState is the health state of instances whose health can't be determined.
+	UnknownInstanceHealthState InstanceHealthState = "Unknown"
+
+	// ManualUpgradeMode leaves the instances on their model until they are replaced by the deployment strategy.
+	ManualUpgradeMode UpgradeMode = "Manual"
+	// RollingUpgradeMode lets the platform upgrade the instances to the latest model in batches.
+	RollingUpgradeMode UpgradeMode = "Rolling"
+	// AutomaticUpgradeMode lets the platform upgrade all the instances to the latest model at the same time.
+	AutomaticUpgradeMode UpgradeMode = "Automatic"
+
+	// DeallocateSpotEvictionPolicy deallocates evicted Spot instances, which keeps their disks.
+	DeallocateSpotEvictionPolicy SpotEvictionPolicy = "Deallocate"
+	// DeleteSpotEvictionPolicy deletes evicted Spot instances and their disks.
+	DeleteSpotEvictionPolicy SpotEvictionPolicy = "Delete"
+
+	// RegularInstancePriority is the priority of regular instances.
+	RegularInstancePriority InstancePriority = "Regular"
+	// SpotInstancePriority is the priority of Spot instances, which can be evicted.
+	SpotInstancePriority InstancePriority = "Spot"
 )
 
 type (
@@ -80,6 +149,17 @@ type (
 		// +optional
 		SecurityProfile *infrav1.SecurityProfile `json:"securityProfile,omitempty"`
 
+		// Diagnostics specifies the diagnostic settings for the Virtual Machine Scale Set instances.
+		// If omitted, boot diagnostics are enabled with a storage account managed by Azure.
+		// +optional
+		Diagnostics *infrav1.Diagnostics `json:"diagnostics,omitempty"`
+
+		// BootstrapDataDelivery specifies whether the bootstrap data is passed to the Virtual Machine Scale Set instances
+		// as custom data or as user data. Ignition bootstrap data can be passed either way, while cloud-init bootstrap
+		// data must be passed as custom data. Blob is not supported for machine pools. Defaults to CustomData.
+		// +optional
+		BootstrapDataDelivery infrav1.BootstrapDataDelivery `json:"bootstrapDataDelivery,omitempty"`
+
 		// SpotVMOptions allows the ability to specify the Machine should use a Spot VM
 		// +optional
 		SpotVMOptions *infrav1.SpotVMOptions `json:"spotVMOptions,omitempty"`
@@ -87,6 +167,11 @@ type (
 		// SubnetName selects the Subnet where the VMSS will be placed
 		// +optional
 		SubnetName string `json:"subnetName,omitempty"`
+
+		// CapacityReservationGroupID is the Azure resource ID of an on-demand capacity reservation group to allocate
+		// the Virtual Machine Scale Set instances from. The group must contain a capacity reservation for the VM size and zone of the machine pool.
+		// +optional
+		CapacityReservationGroupID *string `json:"capacityReservationGroupID,omitempty"`
 	}
 
 	// AzureMachinePoolSpec defines the desired state of AzureMachinePool.
@@ -143,6 +228,178 @@ type (
 		// NOTE: NodeDrainTimeout is different from `kubectl drain --timeout`
 		// +optional
 		NodeDrainTimeout *metav1.Duration `json:"nodeDrainTimeout,omitempty"`
+
+		// ImageVersionUpdatePolicy sets how the machine pool handles new versions of an image whose version is
+		// 'latest' or a version range. With Pinned, the version resolved when the image is set is recorded in the
+		// status and kept, so all the instances use the same version. With Rolling, the machine pool is updated to
+		// newer matching versions as they are published, and its machines are replaced according to the strategy.
+		// +kubebuilder:validation:Enum=Pinned;Rolling
+		// +kubebuilder:default=Pinned
+		// +optional
+		ImageVersionUpdatePolicy ImageVersionUpdatePolicy `json:"imageVersionUpdatePolicy,omitempty"`
+
+		// ApplicationHealth enables the Application Health extension on the Virtual Machine Scale Set instances, which
+		// probes an endpoint of the instances to report their health. By default, the kubelet healthz endpoint is
+		// probed. Instances which report they aren't healthy are not considered ready by the deployment strategy.
+		// +optional
+		ApplicationHealth *ApplicationHealth `json:"applicationHealth,omitempty"`
+
+		// OrchestrationMode is the orchestration mode of the Virtual Machine Scale Set. Uniform scale sets manage
+		// identical instances through the scale set VM API, while Flexible scale sets manage standard virtual machines
+		// which can be spread across zones and fault domains. The orchestration mode can't be changed once set.
+		// +kubebuilder:validation:Enum=Flexible;Uniform
+		// +kubebuilder:default=Uniform
+		// +optional
+		OrchestrationMode infrav1.OrchestrationModeType `json:"orchestrationMode,omitempty"`
+
+		// UpgradePolicy sets how the instances of the Virtual Machine Scale Set are upgraded to its latest model, and
+		// enables automatic OS image upgrades. By default, the instances are replaced by the deployment strategy.
+		// With the Rolling or Automatic mode, or automatic OS image upgrades, the platform upgrades the instances itself
+		// and the deployment strategy only deletes machines to scale the machine pool down.
+		// +optional
+		UpgradePolicy *UpgradePolicy `json:"upgradePolicy,omitempty"`
+
+		// PriorityMixPolicy mixes regular and Spot instances in the Virtual Machine Scale Set: a base number of
+		// regular instances, and a percentage of Spot instances above the base. It requires the Flexible orchestration
+		// mode and can't be changed once set. The maximum price of the Spot instances is set by
+		// Template.SpotVMOptions.
+		// +optional
+		PriorityMixPolicy *PriorityMixPolicy `json:"priorityMixPolicy,omitempty"`
+	}
+
+	// ImageVersionUpdatePolicy defines how an AzureMachinePool handles new versions of its image.
+	ImageVersionUpdatePolicy string
+
+	// ApplicationHealthProtocol is the protocol used to probe the health of instances.
+	ApplicationHealthProtocol string
+
+	// AutomaticRepairAction is the action taken to repair unhealthy instances.
+	AutomaticRepairAction string
+
+	// InstanceHealthState is the health of an instance reported by the Application Health extension.
+	InstanceHealthState string
+
+	// ApplicationHealth defines the endpoint probed by the Application Health extension.
+	ApplicationHealth struct {
+		// Protocol is the protocol used to probe the endpoint, one of http, https or tcp.
+		// +kubebuilder:validation:Enum=http;https;tcp
+		// +kubebuilder:default=http
+		// +optional
+		Protocol ApplicationHealthProtocol `json:"protocol,omitempty"`
+
+		// Port is the port of the endpoint. Defaults to 10248, the port of the kubelet healthz endpoint.
+		// +kubebuilder:validation:Minimum=1
+		// +kubebuilder:validation:Maximum=65535
+		// +kubebuilder:default=10248
+		// +optional
+		Port int32 `json:"port,omitempty"`
+
+		// RequestPath is the path of the endpoint probed with http or https, which must be empty with tcp.
+		// Defaults to /healthz with http and https.
+		// +optional
+		RequestPath string `json:"requestPath,omitempty"`
+
+		// AutomaticRepairs enables the automatic repair of the instances which are unhealthy for longer than the
+		// grace period.
+		// +optional
+		AutomaticRepairs *AutomaticRepairs `json:"automaticRepairs,omitempty"`
+	}
+
+	// AutomaticRepairs defines how unhealthy instances are repaired.
+	AutomaticRepairs struct {
+		// GracePeriod is the time for which repairs are suspended after the state of an instance changes, e.g. after
+		// it is created, to let it become healthy. It must be between 10 and 90 minutes. Defaults to 30 minutes.
+		// +optional
+		GracePeriod *metav1.Duration `json:"gracePeriod,omitempty"`
+
+		// RepairAction is the action taken to repair unhealthy instances, one of Replace, Restart or Reimage.
+		// Reimage is only supported for instances with an ephemeral OS disk.
+		// +kubebuilder:validation:Enum=Replace;Restart;Reimage
+		// +kubebuilder:default=Replace
+		// +optional
+		RepairAction AutomaticRepairAction `json:"repairAction,omitempty"`
+	}
+
+	// UpgradeMode is the mode in which the instances of a Virtual Machine Scale Set are upgraded to its latest model.
+	UpgradeMode string
+
+	// UpgradePolicy defines how the instances of the Virtual Machine Scale Set are upgraded.
+	UpgradePolicy struct {
+		// Mode is the upgrade mode, one of Manual, Rolling or Automatic. With Manual, the instances are replaced by the
+		// deployment strategy. With Rolling, the platform upgrades the instances in batches as configured by
+		// RollingUpgrade. With Automatic, the platform upgrades all the instances at the same time.
+		// +kubebuilder:validation:Enum=Manual;Rolling;Automatic
+		// +kubebuilder:default=Manual
+		// +optional
+		Mode UpgradeMode `json:"mode,omitempty"`
+
+		// RollingUpgrade configures the batches in which the instances are upgraded with the Rolling mode and by
+		// automatic OS image upgrades.
+		// +optional
+		RollingUpgrade *RollingUpgradePolicy `json:"rollingUpgrade,omitempty"`
+
+		// AutomaticOSUpgrade enables automatic OS image upgrades, which upgrade the instances in batches to the newer
+		// versions of their image as they are published. It requires ApplicationHealth.
+		// +optional
+		AutomaticOSUpgrade bool `json:"automaticOSUpgrade,omitempty"`
+	}
+
+	// SpotEvictionPolicy is the action taken on Spot instances when they are evicted.
+	SpotEvictionPolicy string
+
+	// InstancePriority is the priority of an instance of a Virtual Machine Scale Set.
+	InstancePriority string
+
+	// PriorityMixPolicy defines the mix of regular and Spot instances of the Virtual Machine Scale Set.
+	PriorityMixPolicy struct {
+		// BaseRegularPriorityCount is the number of regular instances created before any Spot instance is created.
+		// Defaults to 0.
+		// +kubebuilder:validation:Minimum=0
+		// +optional
+		BaseRegularPriorityCount *int32 `json:"baseRegularPriorityCount,omitempty"`
+
+		// SpotPercentageAboveBase is the percentage of the instances above BaseRegularPriorityCount which are Spot
+		// instances, between 0 and 100. Defaults to 50.
+		// +kubebuilder:validation:Minimum=0
+		// +kubebuilder:validation:Maximum=100
+		// +optional
+		SpotPercentageAboveBase *int32 `json:"spotPercentageAboveBase,omitempty"`
+
+		// EvictionPolicy is the action taken on evicted Spot instances, one of Deallocate or Delete. Deallocated
+		// instances are reported as evicted in the status of the AzureMachinePool until they are started again or
+		// deleted, and still count towards the replicas of the machine pool.
+		// +kubebuilder:validation:Enum=Deallocate;Delete
+		// +kubebuilder:default=Deallocate
+		// +optional
+		EvictionPolicy SpotEvictionPolicy `json:"evictionPolicy,omitempty"`
+	}
+
+	// RollingUpgradePolicy defines the batches in which the platform upgrades the instances of a Virtual Machine Scale
+	// Set. Unset fields use the defaults of the platform.
+	RollingUpgradePolicy struct {
+		// MaxBatchInstancePercent is the maximum percentage of the instances upgraded in one batch.
+		// +kubebuilder:validation:Minimum=5
+		// +kubebuilder:validation:Maximum=100
+		// +optional
+		MaxBatchInstancePercent *int32 `json:"maxBatchInstancePercent,omitempty"`
+
+		// MaxUnhealthyInstancePercent is the maximum percentage of the instances which can be unhealthy at any time,
+		// whether because they are being upgraded or for any other reason. The upgrade is aborted above it.
+		// +kubebuilder:validation:Minimum=5
+		// +kubebuilder:validation:Maximum=100
+		// +optional
+		MaxUnhealthyInstancePercent *int32 `json:"maxUnhealthyInstancePercent,omitempty"`
+
+		// MaxUnhealthyUpgradedInstancePercent is the maximum percentage of the upgraded instances which can be
+		// unhealthy. The upgrade is aborted above it.
+		// +kubebuilder:validation:Minimum=0
+		// +kubebuilder:validation:Maximum=100
+		// +optional
+		MaxUnhealthyUpgradedInstancePercent *int32 `json:"maxUnhealthyUpgradedInstancePercent,omitempty"`
+
+		// PauseTimeBetweenBatches is the time to wait between two batches, at most one hour.
+		// +optional
+		PauseTimeBetweenBatches *metav1.Duration `json:"pauseTimeBetweenBatches,omitempty"`
 	}
 
 	// AzureMachinePoolDeploymentStrategyType is the type of deployment strategy employed to rollout a new version of
@@ -151,9 +408,9 @@ type (
 
 	// AzureMachinePoolDeploymentStrategy describes how to replace existing machines with new ones.
 	AzureMachinePoolDeploymentStrategy struct {
-		// Type of deployment. Currently the only supported strategy is RollingUpdate
+		// Type of deployment, either RollingUpdate or BlueGreen.
 		// +optional
-		// +kubebuilder:validation:Enum=RollingUpdate
+		// +kubebuilder:validation:Enum=RollingUpdate;BlueGreen
 		// +optional
 		// +kubebuilder:default=RollingUpdate
 		Type AzureMachinePoolDeploymentStrategyType `json:"type,omitempty"`
@@ -162,6 +419,11 @@ type (
 		// MachineDeploymentStrategyType = RollingUpdate.
 		// +optional
 		RollingUpdate *MachineRollingUpdateDeployment `json:"rollingUpdate,omitempty"`
+
+		// Blue/green deployment config params. Present only if
+		// MachineDeploymentStrategyType = BlueGreen.
+		// +optional
+		BlueGreen *MachineBlueGreenDeployment `json:"blueGreen,omitempty"`
 	}
 
 	// AzureMachinePoolDeletePolicyType is the type of DeletePolicy employed to select machines to be deleted during an
@@ -210,6 +472,61 @@ type (
 		// +kubebuilder:validation:Enum=Random;Newest;Oldest
 		// +kubebuilder:default:=Oldest
 		DeletePolicy AzureMachinePoolDeletePolicyType `json:"deletePolicy,omitempty"`
+
+		// BalanceZones selects the machines to delete so that the remaining machines stay evenly spread across the
+		// availability zones of the scale set. The machines to delete are taken from the zone with the most machines
+		// first, and the DeletePolicy decides between the machines of a zone.
+		// +optional
+		BalanceZones bool `json:"balanceZones,omitempty"`
+
+		// DrainBlockedTimeout pauses the rolling update when draining the node of a machine being deleted, e.g. because
+		// of a PodDisruptionBudget, hasn't succeeded for longer than this. The rolling update resumes once the drain
+		// succeeds or the machine is deleted. If not set, the rolling update isn't paused for blocked drains.
+		// +optional
+		DrainBlockedTimeout *metav1.Duration `json:"drainBlockedTimeout,omitempty"`
+
+		// NodeReadyTimeout pauses the rolling update when the node of a machine running the latest model doesn't become
+		// ready within this time after the machine is created. The rolling update resumes once the node becomes ready
+		// or the machine is deleted. If not set, the rolling update isn't paused for nodes which aren't ready.
+		// +optional
+		NodeReadyTimeout *metav1.Duration `json:"nodeReadyTimeout,omitempty"`
+	}
+
+	// MachineBlueGreenDeployment is used to control the desired behavior of blue/green deployments.
+	MachineBlueGreenDeployment struct {
+		// ReadyTimeout is the time the AzureMachinePoolMachines of a new scale set have to become ready before the
+		// deployment is rolled back, i.e. the new scale set is deleted and the old one is kept. The deployment is also
+		// rolled back as soon as one of them fails to provision or is reported unhealthy.
+		// Defaults to 30 minutes.
+		// +optional
+		ReadyTimeout *metav1.Duration `json:"readyTimeout,omitempty"`
+	}
+
+	// BlueGreenDeploymentStatus describes the scale sets of an AzureMachinePool using the BlueGreen deployment
+	// strategy.
+	BlueGreenDeploymentStatus struct {
+		// ActiveScaleSetName is the name of the scale set whose AzureMachinePoolMachines serve the machine pool.
+		// +optional
+		ActiveScaleSetName string `json:"activeScaleSetName,omitempty"`
+
+		// NewScaleSetName is the name of the scale set being created with the latest model while a deployment is in
+		// progress.
+		// +optional
+		NewScaleSetName string `json:"newScaleSetName,omitempty"`
+
+		// RetiredScaleSetName is the name of the scale set being drained and deleted, i.e. the previously active scale
+		// set once a deployment completed, or the new scale set once a deployment was rolled back.
+		// +optional
+		RetiredScaleSetName string `json:"retiredScaleSetName,omitempty"`
+
+		// StartTime is the time the in-progress deployment started.
+		// +optional
+		StartTime *metav1.Time `json:"startTime,omitempty"`
+
+		// RolledBackGeneration is the generation of the AzureMachinePool whose deployment was last rolled back. No new
+		// deployment is started until the AzureMachinePool changes.
+		// +optional
+		RolledBackGeneration int64 `json:"rolledBackGeneration,omitempty"`
 	}
 
 	// AzureMachinePoolStatus defines the observed state of AzureMachinePool.
@@ -227,7 +544,8 @@ type (
 		Instances []*AzureMachinePoolInstanceStatus `json:"instances,omitempty"`
 
 		// Image is the current image used in the AzureMachinePool. When the spec image is nil, this image is populated
-		// with the details of the defaulted Azure Marketplace "capi" offer.
+		// with the details of the defaulted Azure Marketplace "capi" offer. When the version of the spec image is
+		// 'latest' or a version range, it is populated with the resolved version.
 		// +optional
 		Image *infrav1.Image `json:"image,omitempty"`
 
@@ -285,6 +603,19 @@ type (
 		// next reconciliation loop.
 		// +optional
 		LongRunningOperationStates infrav1.Futures `json:"longRunningOperationStates,omitempty"`
+
+		// BlueGreen describes the scale sets of the AzureMachinePool when it uses the BlueGreen deployment strategy.
+		// +optional
+		BlueGreen *BlueGreenDeploymentStatus `json:"blueGreen,omitempty"`
+
+		// RolloutResumeTime is the last time a paused rolling update was resumed with the ResumeRolloutAnnotation.
+		// +optional
+		RolloutResumeTime *metav1.Time `json:"rolloutResumeTime,omitempty"`
+
+		// Capacity is the CPU, memory and GPU capacity of the VM size of the machine pool, and the default maximum
+		// number of pods of its nodes. It is used by the cluster-autoscaler to scale the machine pool from zero.
+		// +optional
+		Capacity corev1.ResourceList `json:"capacity,omitempty"`
 	}
 
 	// AzureMachinePoolInstanceStatus provides status information for each instance in the VMSS.
@@ -313,6 +644,19 @@ type (
 		// the image version the VM is running. If the instance is not running the latest model, it means the instance
 		// may not be running the version of Kubernetes the Machine Pool has specified and needs to be updated.
 		LatestModelApplied bool `json:"latestModelApplied"`
+
+		// Priority is the priority of the instance, Regular or Spot.
+		// +optional
+		Priority InstancePriority `json:"priority,omitempty"`
+
+		// Evicted is true when the instance is a Spot instance which was evicted and deallocated.
+		// +optional
+		Evicted bool `json:"evicted,omitempty"`
+
+		// HealthState is the health of the instance reported by the Application Health extension, when it is enabled
+		// on the AzureMachinePool and running on the instance.
+		// +optional
+		HealthState InstanceHealthState `json:"healthState,omitempty"`
 	}
 
 	// +kubebuilder:object:root=true
@@ -366,6 +710,24 @@ func (amp *AzureMachinePool) SetFutures(futures infrav1.Futures) {
 	amp.Status.LongRunningOperationStates = futures
 }
 
+// GetOrchestrationMode returns the orchestration mode of the Virtual Machine Scale Set, which defaults to Uniform.
+func (amp *AzureMachinePool) GetOrchestrationMode() infrav1.OrchestrationModeType {
+	if amp.Spec.OrchestrationMode == "" {
+		return infrav1.UniformOrchestrationMode
+	}
+	return amp.Spec.OrchestrationMode
+}
+
+// HasPlatformManagedUpgrades returns true if the platform upgrades the instances of the Virtual Machine Scale Set
+// itself, per its upgrade policy, rather than the deployment strategy replacing them.
+func (amp *AzureMachinePool) HasPlatformManagedUpgrades() bool {
+	policy := amp.Spec.UpgradePolicy
+	if policy == nil {
+		return false
+	}
+	return (policy.Mode != "" && policy.Mode != ManualUpgradeMode) || policy.AutomaticOSUpgrade
+}
+
 func init() {
 	SchemeBuilder.Register(&AzureMachinePool{}, &AzureMachinePoolList{})
 }
@@ -17,21 +17,29 @@ limitations under the License.
 package v1beta1
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"reflect"
+	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	webhookutils "sigs.k8s.io/cluster-api-provider-azure/util/webhook"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
 
 // SetupWebhookWithManager sets up and registers the webhook with the manager.
 func (amp *AzureMachinePool) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	// the validating webhook reads the AzureCluster of the machine pool, so it is registered with a client
+	mgr.GetWebhookServer().Register("/validate-infrastructure-cluster-x-k8s-io-v1beta1-azuremachinepool",
+		webhookutils.NewValidatingWebhook(amp, mgr.GetClient()))
+
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(amp).
 		Complete()
@@ -46,30 +54,39 @@ func (amp *AzureMachinePool) Default() {
 	if err := amp.SetDefaultSSHPublicKey(); err != nil {
 		ctrl.Log.WithName("AzureMachinePoolLogger").Error(err, "SetDefaultSshPublicKey failed")
 	}
+	// The image is only defaulted when the AzureMachinePool is created, i.e. before the API server sets its creation
+	// timestamp, so that existing machine pools don't roll out a new image.
+	if amp.CreationTimestamp.IsZero() {
+		if err := amp.SetDefaultImage(); err != nil {
+			ctrl.Log.WithName("AzureMachinePoolLogger").Error(err, "SetDefaultImage failed")
+		}
+	}
 	amp.SetIdentityDefaults()
+	amp.SetApplicationHealthDefaults()
+	amp.SetStrategyDefaults()
 }
 
 // +kubebuilder:webhook:verbs=create;update,path=/validate-infrastructure-cluster-x-k8s-io-v1beta1-azuremachinepool,mutating=false,failurePolicy=fail,groups=infrastructure.cluster.x-k8s.io,resources=azuremachinepools,versions=v1beta1,name=validation.azuremachinepool.infrastructure.cluster.x-k8s.io,sideEffects=None,admissionReviewVersions=v1;v1beta1
 
-var _ webhook.Validator = &AzureMachinePool{}
+var _ webhookutils.Validator = &AzureMachinePool{}
 
-// ValidateCreate implements webhook.Validator so a webhook will be registered for the type.
-func (amp *AzureMachinePool) ValidateCreate() error {
-	return amp.Validate(nil)
+// ValidateCreate implements webhookutils.Validator so a webhook will be registered for the type.
+func (amp *AzureMachinePool) ValidateCreate(client client.Client) error {
+	return amp.Validate(nil, client)
 }
 
-// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
-func (amp *AzureMachinePool) ValidateUpdate(old runtime.Object) error {
-	return amp.Validate(old)
+// ValidateUpdate implements webhookutils.Validator so a webhook will be registered for the type.
+func (amp *AzureMachinePool) ValidateUpdate(old runtime.Object, client client.Client) error {
+	return amp.Validate(old, client)
 }
 
-// ValidateDelete implements webhook.Validator so a webhook will be registered for the type.
-func (amp *AzureMachinePool) ValidateDelete() error {
+// ValidateDelete implements webhookutils.Validator so a webhook will be registered for the type.
+func (amp *AzureMachinePool) ValidateDelete(client client.Client) error {
 	return nil
 }
 
 // Validate the Azure Machine Pool and return an aggregate error.
-func (amp *AzureMachinePool) Validate(old runtime.Object) error {
+func (amp *AzureMachinePool) Validate(old runtime.Object, client client.Client) error {
 	validators := []func() error{
 		amp.ValidateImage,
 		amp.ValidateTerminateNotificationTimeout,
@@ -77,6 +94,14 @@ func (amp *AzureMachinePool) Validate(old runtime.Object) error {
 		amp.ValidateUserAssignedIdentity,
 		amp.ValidateStrategy(),
 		amp.ValidateSystemAssignedIdentity(old),
+		amp.ValidateCapacityReservationGroupID,
+		amp.ValidateCapacityReservation(client),
+		amp.ValidateDiagnostics,
+		amp.ValidateBootstrapDataDelivery,
+		amp.ValidateApplicationHealth,
+		amp.ValidateOrchestrationMode(old),
+		amp.ValidateUpgradePolicy,
+		amp.ValidatePriorityMixPolicy(old),
 	}
 
 	var errs []error
@@ -141,6 +166,195 @@ func (amp *AzureMachinePool) ValidateUserAssignedIdentity() error {
 	return nil
 }
 
+// ValidateCapacityReservationGroupID validates the capacity reservation group ID of the template.
+func (amp *AzureMachinePool) ValidateCapacityReservationGroupID() error {
+	fldPath := field.NewPath("template", "capacityReservationGroupID")
+	if errs := infrav1.ValidateCapacityReservationGroupID(amp.Spec.Template.CapacityReservationGroupID, fldPath); len(errs) > 0 {
+		return kerrors.NewAggregate(errs.ToAggregate().Errors())
+	}
+
+	return nil
+}
+
+// ValidateCapacityReservation validates that the capacity reservation group of the template reserves capacity for its
+// VM size, when the group is one of the capacity reservation groups of the AzureCluster. The zones of the machine pool
+// are only known to its MachinePool, so they are validated at reconcile time.
+func (amp *AzureMachinePool) ValidateCapacityReservation(cli client.Client) func() error {
+	return func() error {
+		if amp.Spec.Template.CapacityReservationGroupID == nil {
+			return nil
+		}
+
+		cluster, err := infrav1.GetOwnerAzureCluster(context.Background(), cli, amp)
+		if err != nil {
+			return err
+		}
+
+		fldPath := field.NewPath("template", "capacityReservationGroupID")
+		if errs := infrav1.ValidateCapacityReservation(amp.Spec.Template.CapacityReservationGroupID, cluster, amp.Spec.Template.VMSize, nil, fldPath); len(errs) > 0 {
+			return kerrors.NewAggregate(errs.ToAggregate().Errors())
+		}
+
+		return nil
+	}
+}
+
+// ValidateDiagnostics validates the diagnostic settings of the template.
+func (amp *AzureMachinePool) ValidateDiagnostics() error {
+	fldPath := field.NewPath("template", "diagnostics")
+	if errs := infrav1.ValidateDiagnostics(amp.Spec.Template.Diagnostics, fldPath); len(errs) > 0 {
+		return kerrors.NewAggregate(errs.ToAggregate().Errors())
+	}
+
+	return nil
+}
+
+// ValidateBootstrapDataDelivery validates how the bootstrap data is passed to the instances of the template.
+func (amp *AzureMachinePool) ValidateBootstrapDataDelivery() error {
+	fldPath := field.NewPath("template", "bootstrapDataDelivery")
+	if errs := infrav1.ValidateBootstrapDataDelivery(amp.Spec.Template.BootstrapDataDelivery, amp.Spec.Template.OSDisk.OSType, fldPath); len(errs) > 0 {
+		return kerrors.NewAggregate(errs.ToAggregate().Errors())
+	}
+
+	// Instances created by scaling out need the bootstrap data long after the blob of a machine would be deleted.
+	if amp.Spec.Template.BootstrapDataDelivery == infrav1.BootstrapDataDeliveryBlob {
+		return field.NotSupported(fldPath, amp.Spec.Template.BootstrapDataDelivery, []string{string(infrav1.BootstrapDataDeliveryCustomData), string(infrav1.BootstrapDataDeliveryUserData)})
+	}
+
+	return nil
+}
+
+// ValidateApplicationHealth validates the Application Health extension settings.
+func (amp *AzureMachinePool) ValidateApplicationHealth() error {
+	health := amp.Spec.ApplicationHealth
+	if health == nil {
+		return nil
+	}
+
+	fldPath := field.NewPath("applicationHealth")
+	var allErrs field.ErrorList
+	if health.Protocol == TCPApplicationHealthProtocol && health.RequestPath != "" {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("requestPath"), health.RequestPath, "requestPath must be empty with the tcp protocol"))
+	}
+	if repairs := health.AutomaticRepairs; repairs != nil && repairs.GracePeriod != nil {
+		if gracePeriod := repairs.GracePeriod.Duration; gracePeriod < 10*time.Minute || gracePeriod > 90*time.Minute {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("automaticRepairs", "gracePeriod"), gracePeriod.String(), "gracePeriod must be between 10 and 90 minutes"))
+		} else if gracePeriod%time.Minute != 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("automaticRepairs", "gracePeriod"), gracePeriod.String(), "gracePeriod must be a whole number of minutes"))
+		}
+	}
+	if repairs := health.AutomaticRepairs; repairs != nil && repairs.RepairAction == ReimageAutomaticRepairAction && amp.Spec.Template.OSDisk.DiffDiskSettings == nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("automaticRepairs", "repairAction"), repairs.RepairAction, "Reimage is only supported for instances with an ephemeral OS disk"))
+	}
+	if len(allErrs) > 0 {
+		return kerrors.NewAggregate(allErrs.ToAggregate().Errors())
+	}
+
+	return nil
+}
+
+// ValidateOrchestrationMode validates that the orchestration mode isn't changed, as the orchestration mode of a Virtual
+// Machine Scale Set is immutable.
+func (amp *AzureMachinePool) ValidateOrchestrationMode(old runtime.Object) func() error {
+	return func() error {
+		if old == nil {
+			return nil
+		}
+		oldMachinePool, ok := old.(*AzureMachinePool)
+		if !ok {
+			return fmt.Errorf("unexpected type for old azure machine pool object. Expected: %q, Got: %q",
+				"AzureMachinePool", reflect.TypeOf(old))
+		}
+
+		if amp.GetOrchestrationMode() != oldMachinePool.GetOrchestrationMode() {
+			return field.Forbidden(field.NewPath("orchestrationMode"), "orchestrationMode is immutable")
+		}
+
+		return nil
+	}
+}
+
+// ValidateUpgradePolicy validates the upgrade policy of the Virtual Machine Scale Set.
+func (amp *AzureMachinePool) ValidateUpgradePolicy() error {
+	policy := amp.Spec.UpgradePolicy
+	if policy == nil {
+		return nil
+	}
+
+	fldPath := field.NewPath("upgradePolicy")
+	var allErrs field.ErrorList
+	if amp.HasPlatformManagedUpgrades() {
+		if amp.GetOrchestrationMode() == infrav1.FlexibleOrchestrationMode {
+			allErrs = append(allErrs, field.Forbidden(fldPath, "upgrade policies are not supported with the Flexible orchestration mode"))
+		}
+		if amp.Spec.Strategy.Type == BlueGreenAzureMachinePoolDeploymentStrategyType {
+			allErrs = append(allErrs, field.Forbidden(fldPath, "instances upgraded by the platform are not supported with the blue/green strategy"))
+		}
+	}
+	if policy.Mode == RollingUpgradeMode && amp.Spec.ApplicationHealth == nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("mode"), policy.Mode, "the Rolling mode requires applicationHealth"))
+	}
+	if policy.AutomaticOSUpgrade && amp.Spec.ApplicationHealth == nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("automaticOSUpgrade"), policy.AutomaticOSUpgrade, "automatic OS upgrades require applicationHealth"))
+	}
+	if policy.AutomaticOSUpgrade && !isLatestPlatformImage(amp.Spec.Template.Image) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("automaticOSUpgrade"), policy.AutomaticOSUpgrade, "automatic OS upgrades require a marketplace or shared gallery image with the latest version"))
+	}
+	if rolling := policy.RollingUpgrade; rolling != nil {
+		if policy.Mode != RollingUpgradeMode && !policy.AutomaticOSUpgrade {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("rollingUpgrade"), "rollingUpgrade is only used with the Rolling mode or automatic OS upgrades"))
+		}
+		if pause := rolling.PauseTimeBetweenBatches; pause != nil && (pause.Duration < 0 || pause.Duration > time.Hour) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("rollingUpgrade", "pauseTimeBetweenBatches"), pause.Duration.String(), "pauseTimeBetweenBatches must be between 0 and 1 hour"))
+		}
+	}
+	if len(allErrs) > 0 {
+		return kerrors.NewAggregate(allErrs.ToAggregate().Errors())
+	}
+
+	return nil
+}
+
+// isLatestPlatformImage returns true if an image is a Marketplace or Shared Gallery image with the 'latest' version,
+// which the platform can upgrade to the newer versions published.
+func isLatestPlatformImage(image *infrav1.Image) bool {
+	switch {
+	case image == nil:
+		return false
+	case image.Marketplace != nil:
+		return image.Marketplace.Version == infrav1.LatestImageVersion
+	case image.SharedGallery != nil:
+		return image.SharedGallery.Version == infrav1.LatestImageVersion
+	}
+	return false
+}
+
+// ValidatePriorityMixPolicy validates that the priority mix policy is only used with the Flexible orchestration mode,
+// and that it isn't changed, as the priority mix policy can't be updated on an existing Virtual Machine Scale Set.
+func (amp *AzureMachinePool) ValidatePriorityMixPolicy(old runtime.Object) func() error {
+	return func() error {
+		fldPath := field.NewPath("priorityMixPolicy")
+		if amp.Spec.PriorityMixPolicy != nil && amp.GetOrchestrationMode() != infrav1.FlexibleOrchestrationMode {
+			return field.Forbidden(fldPath, "priorityMixPolicy requires the Flexible orchestration mode")
+		}
+
+		if old == nil {
+			return nil
+		}
+		oldMachinePool, ok := old.(*AzureMachinePool)
+		if !ok {
+			return fmt.Errorf("unexpected type for old azure machine pool object. Expected: %q, Got: %q",
+				"AzureMachinePool", reflect.TypeOf(old))
+		}
+
+		if !reflect.DeepEqual(amp.Spec.PriorityMixPolicy, oldMachinePool.Spec.PriorityMixPolicy) {
+			return field.Forbidden(fldPath, "priorityMixPolicy is immutable")
+		}
+
+		return nil
+	}
+}
+
 // ValidateStrategy validates the strategy.
 func (amp *AzureMachinePool) ValidateStrategy() func() error {
 	return func() error {
@@ -152,6 +366,22 @@ func (amp *AzureMachinePool) ValidateStrategy() func() error {
 				maxUnavailable.Type == intstr.Int && maxUnavailable.IntVal == 0 {
 				return errors.New("rolling update strategy MaxUnavailable must not be 0 if MaxSurge is 0")
 			}
+			if timeout := rollingUpdateStrategy.DrainBlockedTimeout; timeout != nil && timeout.Duration <= 0 {
+				return errors.New("rolling update strategy DrainBlockedTimeout must be greater than 0")
+			}
+			if timeout := rollingUpdateStrategy.NodeReadyTimeout; timeout != nil && timeout.Duration <= 0 {
+				return errors.New("rolling update strategy NodeReadyTimeout must be greater than 0")
+			}
+		}
+
+		if amp.Spec.Strategy.Type == BlueGreenAzureMachinePoolDeploymentStrategyType {
+			if amp.Spec.Identity == infrav1.VMIdentitySystemAssigned {
+				// the role assignment of the system-assigned identity can't be moved to the identity of a new scale set
+				return errors.New("blue/green strategy is not supported with a system-assigned identity")
+			}
+			if blueGreen := amp.Spec.Strategy.BlueGreen; blueGreen != nil && blueGreen.ReadyTimeout != nil && blueGreen.ReadyTimeout.Duration <= 0 {
+				return errors.New("blue/green strategy ReadyTimeout must be greater than 0")
+			}
 		}
 
 		return nil
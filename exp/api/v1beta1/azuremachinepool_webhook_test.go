@@ -21,14 +21,20 @@ import (
 	"crypto/rsa"
 	"encoding/base64"
 	"testing"
+	"time"
 
 	"github.com/Azure/go-autorest/autorest/to"
 	guuid "github.com/google/uuid"
 	. "github.com/onsi/gomega"
 	"golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/uuid"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 var (
@@ -135,10 +141,331 @@ func TestAzureMachinePool_ValidateCreate(t *testing.T) {
 			}),
 			wantErr: false,
 		},
+		{
+			name: "azuremachinepool with valid rollout pause timeouts",
+			amp: createMachinePoolWithStrategy(AzureMachinePoolDeploymentStrategy{
+				Type: RollingUpdateAzureMachinePoolDeploymentStrategyType,
+				RollingUpdate: &MachineRollingUpdateDeployment{
+					MaxSurge:            &one,
+					DrainBlockedTimeout: &metav1.Duration{Duration: 10 * time.Minute},
+					NodeReadyTimeout:    &metav1.Duration{Duration: 15 * time.Minute},
+				},
+			}),
+			wantErr: false,
+		},
+		{
+			name: "azuremachinepool with invalid DrainBlockedTimeout",
+			amp: createMachinePoolWithStrategy(AzureMachinePoolDeploymentStrategy{
+				Type: RollingUpdateAzureMachinePoolDeploymentStrategyType,
+				RollingUpdate: &MachineRollingUpdateDeployment{
+					MaxSurge:            &one,
+					DrainBlockedTimeout: &metav1.Duration{Duration: -1 * time.Minute},
+				},
+			}),
+			wantErr: true,
+		},
+		{
+			name: "azuremachinepool with invalid NodeReadyTimeout",
+			amp: createMachinePoolWithStrategy(AzureMachinePoolDeploymentStrategy{
+				Type: RollingUpdateAzureMachinePoolDeploymentStrategyType,
+				RollingUpdate: &MachineRollingUpdateDeployment{
+					MaxSurge:         &one,
+					NodeReadyTimeout: &metav1.Duration{Duration: 0},
+				},
+			}),
+			wantErr: true,
+		},
+		{
+			name: "azuremachinepool with valid blue/green deployment configuration",
+			amp: createMachinePoolWithStrategy(AzureMachinePoolDeploymentStrategy{
+				Type: BlueGreenAzureMachinePoolDeploymentStrategyType,
+				BlueGreen: &MachineBlueGreenDeployment{
+					ReadyTimeout: &metav1.Duration{Duration: 10 * time.Minute},
+				},
+			}),
+			wantErr: false,
+		},
+		{
+			name: "azuremachinepool with invalid ReadyTimeout blue/green deployment configuration",
+			amp: createMachinePoolWithStrategy(AzureMachinePoolDeploymentStrategy{
+				Type: BlueGreenAzureMachinePoolDeploymentStrategyType,
+				BlueGreen: &MachineBlueGreenDeployment{
+					ReadyTimeout: &metav1.Duration{Duration: 0},
+				},
+			}),
+			wantErr: true,
+		},
+		{
+			name: "azuremachinepool with blue/green deployment and system-assigned identity",
+			amp: func() *AzureMachinePool {
+				amp := createMachinePoolWithStrategy(AzureMachinePoolDeploymentStrategy{
+					Type: BlueGreenAzureMachinePoolDeploymentStrategyType,
+				})
+				amp.Spec.Identity = infrav1.VMIdentitySystemAssigned
+				amp.Spec.RoleAssignmentName = "c6e3443d-bc11-4335-8819-ab6637b10586"
+				return amp
+			}(),
+			wantErr: true,
+		},
+		{
+			name:    "azuremachinepool with valid capacity reservation group ID",
+			amp:     createMachinePoolWithCapacityReservationGroupID("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/capacityReservationGroups/my-crg"),
+			wantErr: false,
+		},
+		{
+			name:    "azuremachinepool with invalid capacity reservation group ID",
+			amp:     createMachinePoolWithCapacityReservationGroupID("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/availabilitySets/my-as"),
+			wantErr: true,
+		},
+		{
+			name:    "azuremachinepool with bootstrap data passed as user data",
+			amp:     createMachinePoolWithBootstrapDataDelivery(infrav1.BootstrapDataDeliveryUserData),
+			wantErr: false,
+		},
+		{
+			name:    "azuremachinepool with bootstrap data delivered from blob storage",
+			amp:     createMachinePoolWithBootstrapDataDelivery(infrav1.BootstrapDataDeliveryBlob),
+			wantErr: true,
+		},
+		{
+			name: "azuremachinepool with application health and automatic repairs",
+			amp: createMachinePoolWithApplicationHealth(ApplicationHealth{
+				Protocol:         HTTPApplicationHealthProtocol,
+				Port:             DefaultApplicationHealthPort,
+				RequestPath:      DefaultApplicationHealthRequestPath,
+				AutomaticRepairs: &AutomaticRepairs{GracePeriod: &metav1.Duration{Duration: 30 * time.Minute}},
+			}),
+			wantErr: false,
+		},
+		{
+			name: "azuremachinepool with application health tcp protocol and request path",
+			amp: createMachinePoolWithApplicationHealth(ApplicationHealth{
+				Protocol:    TCPApplicationHealthProtocol,
+				Port:        DefaultApplicationHealthPort,
+				RequestPath: DefaultApplicationHealthRequestPath,
+			}),
+			wantErr: true,
+		},
+		{
+			name: "azuremachinepool with automatic repairs grace period too short",
+			amp: createMachinePoolWithApplicationHealth(ApplicationHealth{
+				Protocol:         TCPApplicationHealthProtocol,
+				Port:             DefaultApplicationHealthPort,
+				AutomaticRepairs: &AutomaticRepairs{GracePeriod: &metav1.Duration{Duration: 5 * time.Minute}},
+			}),
+			wantErr: true,
+		},
+		{
+			name: "azuremachinepool with automatic repairs grace period not in whole minutes",
+			amp: createMachinePoolWithApplicationHealth(ApplicationHealth{
+				Protocol:         TCPApplicationHealthProtocol,
+				Port:             DefaultApplicationHealthPort,
+				AutomaticRepairs: &AutomaticRepairs{GracePeriod: &metav1.Duration{Duration: 30*time.Minute + 30*time.Second}},
+			}),
+			wantErr: true,
+		},
+		{
+			name: "azuremachinepool with reimage repair action without ephemeral OS disk",
+			amp: createMachinePoolWithApplicationHealth(ApplicationHealth{
+				Protocol:         TCPApplicationHealthProtocol,
+				Port:             DefaultApplicationHealthPort,
+				AutomaticRepairs: &AutomaticRepairs{RepairAction: ReimageAutomaticRepairAction},
+			}),
+			wantErr: true,
+		},
+		{
+			name: "azuremachinepool with rolling upgrade policy",
+			amp: createMachinePoolWithUpgradePolicy(UpgradePolicy{
+				Mode: RollingUpgradeMode,
+				RollingUpgrade: &RollingUpgradePolicy{
+					MaxBatchInstancePercent: to.Int32Ptr(50),
+					PauseTimeBetweenBatches: &metav1.Duration{Duration: 5 * time.Minute},
+				},
+			}, true),
+			wantErr: false,
+		},
+		{
+			name:    "azuremachinepool with automatic OS upgrades",
+			amp:     createMachinePoolWithUpgradePolicy(UpgradePolicy{Mode: ManualUpgradeMode, AutomaticOSUpgrade: true}, true),
+			wantErr: false,
+		},
+		{
+			name: "azuremachinepool with automatic OS upgrades of the latest shared gallery image",
+			amp: func() *AzureMachinePool {
+				amp := createMachinePoolWithUpgradePolicy(UpgradePolicy{AutomaticOSUpgrade: true}, true)
+				amp.Spec.Template.Image = &infrav1.Image{
+					SharedGallery: &infrav1.AzureSharedGalleryImage{
+						SubscriptionID: "SUB123",
+						ResourceGroup:  "RG123",
+						Gallery:        "GALLERY1",
+						Name:           "IMAGE1",
+						Version:        infrav1.LatestImageVersion,
+					},
+				}
+				return amp
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "azuremachinepool with automatic OS upgrades of an exact image version",
+			amp: func() *AzureMachinePool {
+				amp := createMachinePoolWithUpgradePolicy(UpgradePolicy{AutomaticOSUpgrade: true}, true)
+				amp.Spec.Template.Image.Marketplace.Version = "1.0.0"
+				return amp
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "azuremachinepool with automatic OS upgrades of an image version range",
+			amp: func() *AzureMachinePool {
+				amp := createMachinePoolWithUpgradePolicy(UpgradePolicy{AutomaticOSUpgrade: true}, true)
+				amp.Spec.Template.Image.Marketplace.Version = "1.0.x"
+				return amp
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "azuremachinepool with automatic OS upgrades of an image ID",
+			amp: func() *AzureMachinePool {
+				amp := createMachinePoolWithUpgradePolicy(UpgradePolicy{AutomaticOSUpgrade: true}, true)
+				amp.Spec.Template.Image = &infrav1.Image{ID: to.StringPtr("image-id")}
+				return amp
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "azuremachinepool with automatic OS upgrades without an image",
+			amp: func() *AzureMachinePool {
+				amp := createMachinePoolWithUpgradePolicy(UpgradePolicy{AutomaticOSUpgrade: true}, true)
+				amp.Spec.Template.Image = nil
+				return amp
+			}(),
+			wantErr: true,
+		},
+		{
+			name:    "azuremachinepool with rolling upgrade policy without application health",
+			amp:     createMachinePoolWithUpgradePolicy(UpgradePolicy{Mode: RollingUpgradeMode}, false),
+			wantErr: true,
+		},
+		{
+			name:    "azuremachinepool with automatic OS upgrades without application health",
+			amp:     createMachinePoolWithUpgradePolicy(UpgradePolicy{AutomaticOSUpgrade: true}, false),
+			wantErr: true,
+		},
+		{
+			name: "azuremachinepool with rolling upgrade settings in manual mode",
+			amp: createMachinePoolWithUpgradePolicy(UpgradePolicy{
+				Mode:           ManualUpgradeMode,
+				RollingUpgrade: &RollingUpgradePolicy{MaxBatchInstancePercent: to.Int32Ptr(50)},
+			}, true),
+			wantErr: true,
+		},
+		{
+			name: "azuremachinepool with rolling upgrade pause time too long",
+			amp: createMachinePoolWithUpgradePolicy(UpgradePolicy{
+				Mode:           RollingUpgradeMode,
+				RollingUpgrade: &RollingUpgradePolicy{PauseTimeBetweenBatches: &metav1.Duration{Duration: 2 * time.Hour}},
+			}, true),
+			wantErr: true,
+		},
+		{
+			name: "azuremachinepool with automatic upgrade mode and blue/green strategy",
+			amp: func() *AzureMachinePool {
+				amp := createMachinePoolWithUpgradePolicy(UpgradePolicy{Mode: AutomaticUpgradeMode}, false)
+				amp.Spec.Strategy.Type = BlueGreenAzureMachinePoolDeploymentStrategyType
+				return amp
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "azuremachinepool with automatic upgrade mode and flexible orchestration mode",
+			amp: func() *AzureMachinePool {
+				amp := createMachinePoolWithUpgradePolicy(UpgradePolicy{Mode: AutomaticUpgradeMode}, false)
+				amp.Spec.OrchestrationMode = infrav1.FlexibleOrchestrationMode
+				return amp
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "azuremachinepool with priority mix policy and flexible orchestration mode",
+			amp: createMachinePoolWithPriorityMixPolicy(infrav1.FlexibleOrchestrationMode, &PriorityMixPolicy{
+				BaseRegularPriorityCount: to.Int32Ptr(2),
+				SpotPercentageAboveBase:  to.Int32Ptr(80),
+				EvictionPolicy:           DeleteSpotEvictionPolicy,
+			}),
+			wantErr: false,
+		},
+		{
+			name:    "azuremachinepool with priority mix policy and uniform orchestration mode",
+			amp:     createMachinePoolWithPriorityMixPolicy(infrav1.UniformOrchestrationMode, &PriorityMixPolicy{}),
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.amp.ValidateCreate(nil)
+			if tc.wantErr {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestAzureMachinePool_ValidateCreateCapacityReservation(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(infrav1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-cluster", Namespace: "default"},
+			Spec: clusterv1.ClusterSpec{
+				InfrastructureRef: &corev1.ObjectReference{Kind: "AzureCluster", Name: "my-azure-cluster"},
+			},
+		},
+		&infrav1.AzureCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-azure-cluster", Namespace: "default"},
+			Spec: infrav1.AzureClusterSpec{
+				ResourceGroup: "my-rg",
+				CapacityReservationGroups: []infrav1.CapacityReservationGroup{
+					{
+						Name:  "my-crg",
+						Zones: []string{"1"},
+						Reservations: []infrav1.CapacityReservation{
+							{Name: "d2-1", VMSize: "Standard_D2s_v3", Zone: "1", Capacity: 2},
+						},
+					},
+				},
+			},
+		},
+	).Build()
+
+	tests := []struct {
+		name    string
+		vmSize  string
+		wantErr bool
+	}{
+		{
+			name:    "azuremachinepool with a VM size reserved by the capacity reservation group of its AzureCluster",
+			vmSize:  "Standard_D2s_v3",
+			wantErr: false,
+		},
+		{
+			name:    "azuremachinepool with a VM size not reserved by the capacity reservation group of its AzureCluster",
+			vmSize:  "Standard_D4s_v3",
+			wantErr: true,
+		},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			err := tc.amp.ValidateCreate()
+			amp := createMachinePoolWithCapacityReservationGroupID("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/capacityReservationGroups/my-crg")
+			amp.Namespace = "default"
+			amp.Labels = map[string]string{clusterv1.ClusterLabelName: "my-cluster"}
+			amp.Spec.Template.VMSize = tc.vmSize
+			err := amp.ValidateCreate(cli)
 			if tc.wantErr {
 				g.Expect(err).To(HaveOccurred())
 			} else {
@@ -210,10 +537,40 @@ func TestAzureMachinePool_ValidateUpdate(t *testing.T) {
 			}),
 			wantErr: false,
 		},
+		{
+			name:    "azuremachinepool with defaulted orchestration mode",
+			oldAMP:  createMachinePoolWithOrchestrationMode(""),
+			amp:     createMachinePoolWithOrchestrationMode(infrav1.UniformOrchestrationMode),
+			wantErr: false,
+		},
+		{
+			name:    "azuremachinepool with orchestration mode changed",
+			oldAMP:  createMachinePoolWithOrchestrationMode(infrav1.UniformOrchestrationMode),
+			amp:     createMachinePoolWithOrchestrationMode(infrav1.FlexibleOrchestrationMode),
+			wantErr: true,
+		},
+		{
+			name:    "azuremachinepool with priority mix policy unchanged",
+			oldAMP:  createMachinePoolWithPriorityMixPolicy(infrav1.FlexibleOrchestrationMode, &PriorityMixPolicy{SpotPercentageAboveBase: to.Int32Ptr(50)}),
+			amp:     createMachinePoolWithPriorityMixPolicy(infrav1.FlexibleOrchestrationMode, &PriorityMixPolicy{SpotPercentageAboveBase: to.Int32Ptr(50)}),
+			wantErr: false,
+		},
+		{
+			name:    "azuremachinepool with priority mix policy changed",
+			oldAMP:  createMachinePoolWithPriorityMixPolicy(infrav1.FlexibleOrchestrationMode, &PriorityMixPolicy{SpotPercentageAboveBase: to.Int32Ptr(50)}),
+			amp:     createMachinePoolWithPriorityMixPolicy(infrav1.FlexibleOrchestrationMode, &PriorityMixPolicy{SpotPercentageAboveBase: to.Int32Ptr(80)}),
+			wantErr: true,
+		},
+		{
+			name:    "azuremachinepool with priority mix policy added",
+			oldAMP:  createMachinePoolWithPriorityMixPolicy(infrav1.FlexibleOrchestrationMode, nil),
+			amp:     createMachinePoolWithPriorityMixPolicy(infrav1.FlexibleOrchestrationMode, &PriorityMixPolicy{}),
+			wantErr: true,
+		},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			err := tc.amp.ValidateUpdate(tc.oldAMP)
+			err := tc.amp.ValidateUpdate(tc.oldAMP, nil)
 			if tc.wantErr {
 				g.Expect(err).To(HaveOccurred())
 			} else {
@@ -260,6 +617,74 @@ func TestAzureMachinePool_Default(t *testing.T) {
 	g.Expect(publicKeyNotExistTest.amp.Spec.Template.SSHPublicKey).NotTo(BeEmpty())
 }
 
+type fakeDefaultImageResolver map[string]*infrav1.Image
+
+func (r fakeDefaultImageResolver) GetDefaultImage(k8sVersion, osType, flavor string) (*infrav1.Image, error) {
+	if k8sVersion != "" || flavor != "" {
+		return nil, nil
+	}
+	if osType == "" {
+		osType = "Linux"
+	}
+	return r[osType].DeepCopy(), nil
+}
+
+func TestAzureMachinePool_DefaultImage(t *testing.T) {
+	linuxImage := &infrav1.Image{ID: to.StringPtr("/subscriptions/123/resourceGroups/images/providers/Microsoft.Compute/images/linux")}
+	windowsImage := &infrav1.Image{ID: to.StringPtr("/subscriptions/123/resourceGroups/images/providers/Microsoft.Compute/images/windows")}
+	customImage := &infrav1.Image{ID: to.StringPtr("/subscriptions/123/resourceGroups/images/providers/Microsoft.Compute/images/custom")}
+
+	tests := []struct {
+		name     string
+		resolver infrav1.DefaultImageResolver
+		amp      *AzureMachinePool
+		want     *infrav1.Image
+	}{
+		{
+			name: "no default image resolver",
+			amp:  &AzureMachinePool{},
+			want: nil,
+		},
+		{
+			name:     "new machine pool without image",
+			resolver: fakeDefaultImageResolver{"Linux": linuxImage, "Windows": windowsImage},
+			amp:      &AzureMachinePool{},
+			want:     linuxImage,
+		},
+		{
+			name:     "new Windows machine pool without image",
+			resolver: fakeDefaultImageResolver{"Linux": linuxImage, "Windows": windowsImage},
+			amp: &AzureMachinePool{Spec: AzureMachinePoolSpec{Template: AzureMachinePoolMachineTemplate{
+				OSDisk: infrav1.OSDisk{OSType: "Windows"},
+			}}},
+			want: windowsImage,
+		},
+		{
+			name:     "new machine pool with image",
+			resolver: fakeDefaultImageResolver{"Linux": linuxImage},
+			amp:      &AzureMachinePool{Spec: AzureMachinePoolSpec{Template: AzureMachinePoolMachineTemplate{Image: customImage}}},
+			want:     customImage,
+		},
+		{
+			name:     "existing machine pool without image",
+			resolver: fakeDefaultImageResolver{"Linux": linuxImage},
+			amp:      &AzureMachinePool{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.Now()}},
+			want:     nil,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			infrav1.SetDefaultImageResolver(tc.resolver)
+			defer infrav1.SetDefaultImageResolver(nil)
+
+			tc.amp.Default()
+			g.Expect(tc.amp.Spec.Template.Image).To(Equal(tc.want))
+		})
+	}
+}
+
 func createMachinePoolWithtMarketPlaceImage(publisher, offer, sku, version string, terminateNotificationTimeout *int) *AzureMachinePool {
 	image := infrav1.Image{
 		Marketplace: &infrav1.AzureMarketplaceImage{
@@ -361,3 +786,74 @@ func createMachinePoolWithStrategy(strategy AzureMachinePoolDeploymentStrategy)
 		},
 	}
 }
+
+func createMachinePoolWithBootstrapDataDelivery(delivery infrav1.BootstrapDataDelivery) *AzureMachinePool {
+	return &AzureMachinePool{
+		Spec: AzureMachinePoolSpec{
+			Template: AzureMachinePoolMachineTemplate{
+				BootstrapDataDelivery: delivery,
+			},
+		},
+	}
+}
+
+func createMachinePoolWithOrchestrationMode(mode infrav1.OrchestrationModeType) *AzureMachinePool {
+	return &AzureMachinePool{
+		Spec: AzureMachinePoolSpec{
+			OrchestrationMode: mode,
+		},
+	}
+}
+
+func createMachinePoolWithApplicationHealth(health ApplicationHealth) *AzureMachinePool {
+	return &AzureMachinePool{
+		Spec: AzureMachinePoolSpec{
+			ApplicationHealth: &health,
+		},
+	}
+}
+
+func createMachinePoolWithUpgradePolicy(policy UpgradePolicy, applicationHealth bool) *AzureMachinePool {
+	amp := &AzureMachinePool{
+		Spec: AzureMachinePoolSpec{
+			Template: AzureMachinePoolMachineTemplate{
+				Image: &infrav1.Image{
+					Marketplace: &infrav1.AzureMarketplaceImage{
+						Publisher: "PUB1234",
+						Offer:     "OFFER1234",
+						SKU:       "SKU1234",
+						Version:   infrav1.LatestImageVersion,
+					},
+				},
+			},
+			UpgradePolicy: &policy,
+		},
+	}
+	if applicationHealth {
+		amp.Spec.ApplicationHealth = &ApplicationHealth{
+			Protocol:    HTTPApplicationHealthProtocol,
+			Port:        DefaultApplicationHealthPort,
+			RequestPath: DefaultApplicationHealthRequestPath,
+		}
+	}
+	return amp
+}
+
+func createMachinePoolWithPriorityMixPolicy(mode infrav1.OrchestrationModeType, policy *PriorityMixPolicy) *AzureMachinePool {
+	return &AzureMachinePool{
+		Spec: AzureMachinePoolSpec{
+			OrchestrationMode: mode,
+			PriorityMixPolicy: policy,
+		},
+	}
+}
+
+func createMachinePoolWithCapacityReservationGroupID(id string) *AzureMachinePool {
+	return &AzureMachinePool{
+		Spec: AzureMachinePoolSpec{
+			Template: AzureMachinePoolMachineTemplate{
+				CapacityReservationGroupID: &id,
+			},
+		},
+	}
+}
@@ -90,11 +90,77 @@ type (
 		// LatestModelApplied indicates the instance is running the most up-to-date VMSS model. A VMSS model describes
 		// the image version the VM is running. If the instance is not running the latest model, it means the instance
 		// may not be running the version of Kubernetes the Machine Pool has specified and needs to be updated.
+		// An instance whose disk layout drifted from the disks of the Machine Pool is not running the latest model either.
 		LatestModelApplied bool `json:"latestModelApplied"`
 
 		// Ready is true when the provider resource is ready.
 		// +optional
 		Ready bool `json:"ready"`
+
+		// HealthState is the health of the instance reported by the Application Health extension, when it is enabled
+		// on the AzureMachinePool and running on the instance.
+		// +optional
+		HealthState InstanceHealthState `json:"healthState,omitempty"`
+
+		// AvailabilityZone is the availability zone of the Azure virtual machine instance, if any.
+		// +optional
+		AvailabilityZone string `json:"availabilityZone,omitempty"`
+
+		// ProtectionPolicy is the protection policy applied to the Azure virtual machine instance, requested with the
+		// azure.cluster.x-k8s.io/instance-protection annotation.
+		// +optional
+		ProtectionPolicy *InstanceProtectionPolicy `json:"protectionPolicy,omitempty"`
+
+		// Disks lists the managed disks of the Azure virtual machine instance, starting with its OS disk.
+		// +optional
+		Disks []InstanceDisk `json:"disks,omitempty"`
+	}
+
+	// InstanceDisk describes a managed disk of an instance of a Virtual Machine Scale Set.
+	InstanceDisk struct {
+		// Name is the name of the disk.
+		Name string `json:"name"`
+
+		// Lun is the logical unit number of a data disk. It is unset for the OS disk.
+		// +optional
+		Lun *int32 `json:"lun,omitempty"`
+
+		// DiskSizeGB is the size of the disk in GB.
+		// +optional
+		DiskSizeGB int32 `json:"diskSizeGB,omitempty"`
+
+		// StorageAccountType is the storage account type of the disk, e.g. Premium_LRS.
+		// +optional
+		StorageAccountType string `json:"storageAccountType,omitempty"`
+
+		// Ephemeral indicates the OS disk is an ephemeral disk on the local storage of the instance.
+		// +optional
+		Ephemeral bool `json:"ephemeral,omitempty"`
+
+		// Attached indicates the data disk was attached to the instance after its creation, e.g. a persistent volume,
+		// rather than created from the VMSS model.
+		// +optional
+		Attached bool `json:"attached,omitempty"`
+
+		// DiskEncryptionSetID is the ID of the disk encryption set whose customer-managed key encrypts the disk.
+		// +optional
+		DiskEncryptionSetID string `json:"diskEncryptionSetID,omitempty"`
+
+		// EncryptionAtHost indicates the disk is also encrypted on the host of the instance.
+		// +optional
+		EncryptionAtHost bool `json:"encryptionAtHost,omitempty"`
+	}
+
+	// InstanceProtectionPolicy is the protection policy of an instance of a Virtual Machine Scale Set.
+	InstanceProtectionPolicy struct {
+		// ProtectFromScaleIn indicates the instance is not deleted when the scale set is scaled in.
+		// +optional
+		ProtectFromScaleIn bool `json:"protectFromScaleIn,omitempty"`
+
+		// ProtectFromScaleSetActions indicates the instance is not affected by the actions of the scale set, such as
+		// scale-in or upgrades to the latest model.
+		// +optional
+		ProtectFromScaleSetActions bool `json:"protectFromScaleSetActions,omitempty"`
 	}
 
 	// +kubebuilder:object:root=true
@@ -103,6 +169,7 @@ type (
 	// +kubebuilder:printcolumn:name="Version",type="string",JSONPath=".status.version",description="Kubernetes version"
 	// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.ready",description="Flag indicating infrastructure is successfully provisioned"
 	// +kubebuilder:printcolumn:name="State",type="string",JSONPath=".status.provisioningState",description="Azure VMSS VM provisioning state"
+	// +kubebuilder:printcolumn:name="Health",type="string",priority=1,JSONPath=".status.healthState",description="Health of the Azure VMSS VM reported by the Application Health extension"
 	// +kubebuilder:printcolumn:name="Cluster",type="string",priority=1,JSONPath=".metadata.labels.cluster\\.x-k8s\\.io/cluster-name",description="Cluster to which this AzureMachinePoolMachine belongs"
 	// +kubebuilder:printcolumn:name="VMSS VM ID",type="string",priority=1,JSONPath=".spec.providerID",description="Azure VMSS VM ID"
 	// +kubebuilder:storageversion
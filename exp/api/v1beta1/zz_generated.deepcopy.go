@@ -108,6 +108,46 @@ func (in *AddonProfile) DeepCopy() *AddonProfile {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationHealth) DeepCopyInto(out *ApplicationHealth) {
+	*out = *in
+	if in.AutomaticRepairs != nil {
+		in, out := &in.AutomaticRepairs, &out.AutomaticRepairs
+		*out = new(AutomaticRepairs)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationHealth.
+func (in *ApplicationHealth) DeepCopy() *ApplicationHealth {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationHealth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutomaticRepairs) DeepCopyInto(out *AutomaticRepairs) {
+	*out = *in
+	if in.GracePeriod != nil {
+		in, out := &in.GracePeriod, &out.GracePeriod
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutomaticRepairs.
+func (in *AutomaticRepairs) DeepCopy() *AutomaticRepairs {
+	if in == nil {
+		return nil
+	}
+	out := new(AutomaticRepairs)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AzureMachinePool) DeepCopyInto(out *AzureMachinePool) {
 	*out = *in
@@ -143,6 +183,11 @@ func (in *AzureMachinePoolDeploymentStrategy) DeepCopyInto(out *AzureMachinePool
 		*out = new(MachineRollingUpdateDeployment)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.BlueGreen != nil {
+		in, out := &in.BlueGreen, &out.BlueGreen
+		*out = new(MachineBlueGreenDeployment)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureMachinePoolDeploymentStrategy.
@@ -316,6 +361,18 @@ func (in *AzureMachinePoolMachineStatus) DeepCopyInto(out *AzureMachinePoolMachi
 		*out = make(apiv1beta1.Futures, len(*in))
 		copy(*out, *in)
 	}
+	if in.ProtectionPolicy != nil {
+		in, out := &in.ProtectionPolicy, &out.ProtectionPolicy
+		*out = new(InstanceProtectionPolicy)
+		**out = **in
+	}
+	if in.Disks != nil {
+		in, out := &in.Disks, &out.Disks
+		*out = make([]InstanceDisk, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureMachinePoolMachineStatus.
@@ -359,11 +416,21 @@ func (in *AzureMachinePoolMachineTemplate) DeepCopyInto(out *AzureMachinePoolMac
 		*out = new(apiv1beta1.SecurityProfile)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Diagnostics != nil {
+		in, out := &in.Diagnostics, &out.Diagnostics
+		*out = new(apiv1beta1.Diagnostics)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.SpotVMOptions != nil {
 		in, out := &in.SpotVMOptions, &out.SpotVMOptions
 		*out = new(apiv1beta1.SpotVMOptions)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.CapacityReservationGroupID != nil {
+		in, out := &in.CapacityReservationGroupID, &out.CapacityReservationGroupID
+		*out = new(string)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureMachinePoolMachineTemplate.
@@ -403,6 +470,21 @@ func (in *AzureMachinePoolSpec) DeepCopyInto(out *AzureMachinePoolSpec) {
 		*out = new(v1.Duration)
 		**out = **in
 	}
+	if in.ApplicationHealth != nil {
+		in, out := &in.ApplicationHealth, &out.ApplicationHealth
+		*out = new(ApplicationHealth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UpgradePolicy != nil {
+		in, out := &in.UpgradePolicy, &out.UpgradePolicy
+		*out = new(UpgradePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PriorityMixPolicy != nil {
+		in, out := &in.PriorityMixPolicy, &out.PriorityMixPolicy
+		*out = new(PriorityMixPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureMachinePoolSpec.
@@ -461,6 +543,23 @@ func (in *AzureMachinePoolStatus) DeepCopyInto(out *AzureMachinePoolStatus) {
 		*out = make(apiv1beta1.Futures, len(*in))
 		copy(*out, *in)
 	}
+	if in.BlueGreen != nil {
+		in, out := &in.BlueGreen, &out.BlueGreen
+		*out = new(BlueGreenDeploymentStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RolloutResumeTime != nil {
+		in, out := &in.RolloutResumeTime, &out.RolloutResumeTime
+		*out = new(v1.Time)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureMachinePoolStatus.
@@ -888,6 +987,61 @@ func (in *AzureManagedMachinePoolStatus) DeepCopy() *AzureManagedMachinePoolStat
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlueGreenDeploymentStatus) DeepCopyInto(out *BlueGreenDeploymentStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = new(v1.Time)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlueGreenDeploymentStatus.
+func (in *BlueGreenDeploymentStatus) DeepCopy() *BlueGreenDeploymentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BlueGreenDeploymentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceDisk) DeepCopyInto(out *InstanceDisk) {
+	*out = *in
+	if in.Lun != nil {
+		in, out := &in.Lun, &out.Lun
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceDisk.
+func (in *InstanceDisk) DeepCopy() *InstanceDisk {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceDisk)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceProtectionPolicy) DeepCopyInto(out *InstanceProtectionPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceProtectionPolicy.
+func (in *InstanceProtectionPolicy) DeepCopy() *InstanceProtectionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceProtectionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LoadBalancerProfile) DeepCopyInto(out *LoadBalancerProfile) {
 	*out = *in
@@ -928,6 +1082,26 @@ func (in *LoadBalancerProfile) DeepCopy() *LoadBalancerProfile {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachineBlueGreenDeployment) DeepCopyInto(out *MachineBlueGreenDeployment) {
+	*out = *in
+	if in.ReadyTimeout != nil {
+		in, out := &in.ReadyTimeout, &out.ReadyTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineBlueGreenDeployment.
+func (in *MachineBlueGreenDeployment) DeepCopy() *MachineBlueGreenDeployment {
+	if in == nil {
+		return nil
+	}
+	out := new(MachineBlueGreenDeployment)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MachineRollingUpdateDeployment) DeepCopyInto(out *MachineRollingUpdateDeployment) {
 	*out = *in
@@ -941,6 +1115,16 @@ func (in *MachineRollingUpdateDeployment) DeepCopyInto(out *MachineRollingUpdate
 		*out = new(intstr.IntOrString)
 		**out = **in
 	}
+	if in.DrainBlockedTimeout != nil {
+		in, out := &in.DrainBlockedTimeout, &out.DrainBlockedTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.NodeReadyTimeout != nil {
+		in, out := &in.NodeReadyTimeout, &out.NodeReadyTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineRollingUpdateDeployment.
@@ -1009,6 +1193,66 @@ func (in *ManagedMachinePoolScaling) DeepCopy() *ManagedMachinePoolScaling {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PriorityMixPolicy) DeepCopyInto(out *PriorityMixPolicy) {
+	*out = *in
+	if in.BaseRegularPriorityCount != nil {
+		in, out := &in.BaseRegularPriorityCount, &out.BaseRegularPriorityCount
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SpotPercentageAboveBase != nil {
+		in, out := &in.SpotPercentageAboveBase, &out.SpotPercentageAboveBase
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PriorityMixPolicy.
+func (in *PriorityMixPolicy) DeepCopy() *PriorityMixPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(PriorityMixPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RollingUpgradePolicy) DeepCopyInto(out *RollingUpgradePolicy) {
+	*out = *in
+	if in.MaxBatchInstancePercent != nil {
+		in, out := &in.MaxBatchInstancePercent, &out.MaxBatchInstancePercent
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxUnhealthyInstancePercent != nil {
+		in, out := &in.MaxUnhealthyInstancePercent, &out.MaxUnhealthyInstancePercent
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxUnhealthyUpgradedInstancePercent != nil {
+		in, out := &in.MaxUnhealthyUpgradedInstancePercent, &out.MaxUnhealthyUpgradedInstancePercent
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PauseTimeBetweenBatches != nil {
+		in, out := &in.PauseTimeBetweenBatches, &out.PauseTimeBetweenBatches
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RollingUpgradePolicy.
+func (in *RollingUpgradePolicy) DeepCopy() *RollingUpgradePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RollingUpgradePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SKU) DeepCopyInto(out *SKU) {
 	*out = *in
@@ -1057,3 +1301,23 @@ func (in Taints) DeepCopy() Taints {
 	in.DeepCopyInto(out)
 	return *out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradePolicy) DeepCopyInto(out *UpgradePolicy) {
+	*out = *in
+	if in.RollingUpgrade != nil {
+		in, out := &in.RollingUpgrade, &out.RollingUpgrade
+		*out = new(RollingUpgradePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradePolicy.
+func (in *UpgradePolicy) DeepCopy() *UpgradePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -282,6 +282,13 @@ func (ampr *AzureMachinePoolReconciler) reconcileNormal(ctx context.Context, mac
 		return reconcile.Result{}, nil
 	}
 
+	// Scale the scale set to zero while the cluster is hibernated.
+	hibernate, err := infracontroller.ShouldHibernate(ctx, ampr.Client, clusterScope.AzureCluster, clusterScope.ClusterName(), machinePoolScope.AzureMachinePool, false)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to check if the machine pool should be hibernated")
+	}
+	machinePoolScope.SetHibernate(hibernate)
+
 	ams, err := ampr.createAzureMachinePoolService(machinePoolScope)
 	if err != nil {
 		return reconcile.Result{}, errors.Wrap(err, "failed creating a newAzureMachinePoolService")
@@ -322,7 +329,8 @@ func (ampr *AzureMachinePoolReconciler) reconcileNormal(ctx context.Context, mac
 		return reconcile.Result{}, errors.Wrap(err, "Scale set deleted, retry creating in next reconcile")
 	}
 
-	if machinePoolScope.NeedsRequeue() {
+	// A hibernated machine pool waits for the control plane machines to be resumed before it is resumed itself.
+	if machinePoolScope.NeedsRequeue() || (hibernate && !clusterScope.AzureCluster.Spec.Hibernate) {
 		return reconcile.Result{
 			RequeueAfter: 30 * time.Second,
 		}, nil
@@ -26,6 +26,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/roleassignments"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/scalesets"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/vmssextensions"
+	infracontroller "sigs.k8s.io/cluster-api-provider-azure/controllers"
 	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
 )
 
@@ -69,6 +70,35 @@ func (s *azureMachinePoolService) Reconcile(ctx context.Context) error {
 		}
 	}
 
+	if err := s.reconcileCapacity(ctx); err != nil {
+		return errors.Wrap(err, "failed to reconcile AzureMachinePool capacity")
+	}
+
+	return nil
+}
+
+// reconcileCapacity sets the capacity annotations and status of the AzureMachinePool from its VM size.
+func (s *azureMachinePoolService) reconcileCapacity(ctx context.Context) error {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "controllers.azureMachinePoolService.reconcileCapacity")
+	defer done()
+
+	vmSize := s.scope.AzureMachinePool.Spec.Template.VMSize
+	sku, err := s.skuCache.Get(ctx, vmSize, resourceskus.VirtualMachines)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get SKU %s", vmSize)
+	}
+
+	vmCapacity, err := sku.ResourceCapacity()
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the capacity of SKU %s", vmSize)
+	}
+
+	capacity, err := infracontroller.SetAutoscalerCapacity(s.scope.AzureMachinePool, vmCapacity)
+	if err != nil {
+		return err
+	}
+	s.scope.SetCapacity(capacity)
+
 	return nil
 }
 
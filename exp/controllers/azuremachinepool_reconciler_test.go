@@ -21,9 +21,13 @@ import (
 	"errors"
 	"testing"
 
-	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/mock_azure"
@@ -84,6 +88,7 @@ func TestAzureMachinePoolServiceReconcile(t *testing.T) {
 					AzureMachinePool: &infrav1exp.AzureMachinePool{
 						Spec: infrav1exp.AzureMachinePoolSpec{
 							Template: infrav1exp.AzureMachinePoolMachineTemplate{
+								VMSize:     "Standard_D2s_v3",
 								SubnetName: "test-subnet",
 							},
 						},
@@ -94,7 +99,15 @@ func TestAzureMachinePoolServiceReconcile(t *testing.T) {
 					svcTwoMock,
 					svcThreeMock,
 				},
-				skuCache: resourceskus.NewStaticCache([]compute.ResourceSku{}, ""),
+				skuCache: resourceskus.NewStaticCache([]compute.ResourceSku{
+					{
+						Name: to.StringPtr("Standard_D2s_v3"),
+						Capabilities: &[]compute.ResourceSkuCapabilities{
+							{Name: to.StringPtr(resourceskus.VCPUs), Value: to.StringPtr("2")},
+							{Name: to.StringPtr(resourceskus.MemoryGB), Value: to.StringPtr("8")},
+						},
+					},
+				}, ""),
 			}
 
 			err := s.Reconcile(context.TODO())
@@ -103,6 +116,12 @@ func TestAzureMachinePoolServiceReconcile(t *testing.T) {
 				g.Expect(err).To(MatchError(tc.expectedError))
 			} else {
 				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(equality.Semantic.DeepEqual(s.scope.AzureMachinePool.Status.Capacity, corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("2"),
+					corev1.ResourceMemory: resource.MustParse("8Gi"),
+					corev1.ResourcePods:   resource.MustParse("110"),
+				})).To(BeTrue())
+				g.Expect(s.scope.AzureMachinePool.Annotations).To(HaveKeyWithValue(azure.AutoscalerMemoryCapacityAnnotation, "8Gi"))
 			}
 		})
 	}
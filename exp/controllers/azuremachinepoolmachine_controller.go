@@ -35,9 +35,11 @@ import (
 	"sigs.k8s.io/cluster-api-provider-azure/pkg/coalescing"
 	"sigs.k8s.io/cluster-api-provider-azure/util/reconciler"
 	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	capierrors "sigs.k8s.io/cluster-api/errors"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/predicates"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -47,6 +49,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
+// terminateEventPollInterval is how often the node of a ready machine is checked for a pending Terminate or Preempt
+// scheduled event. It is shorter than the 30 seconds notice of a Spot eviction.
+const terminateEventPollInterval = 20 * time.Second
+
 type (
 	azureMachinePoolMachineReconcilerFactory func(*scope.MachinePoolMachineScope) azure.Reconciler
 
@@ -252,6 +258,9 @@ func (ampmr *AzureMachinePoolMachineController) reconcileNormal(ctx context.Cont
 		return reconcile.Result{}, nil
 	}
 
+	// Report the outcome of a requested VM action once the scale set VM service has completed it.
+	defer infracontroller.RecordVMActionEvent(ampmr.Recorder, machineScope.AzureMachinePoolMachine, machineScope.Annotation(azure.VMActionAnnotation))
+
 	ampms := ampmr.reconcilerFactory(machineScope)
 	if err := ampms.Reconcile(ctx); err != nil {
 		// Handle transient and terminal errors
@@ -295,6 +304,11 @@ func (ampmr *AzureMachinePoolMachineController) reconcileNormal(ctx context.Cont
 		}, nil
 	}
 
+	if machineScope.ReceivesTerminateEvents() {
+		// the scheduled events of the instance are only known from its node, which is not watched
+		return reconcile.Result{RequeueAfter: terminateEventPollInterval}, nil
+	}
+
 	return reconcile.Result{}, nil
 }
 
@@ -359,9 +373,48 @@ func (r *azureMachinePoolMachineReconciler) Reconcile(ctx context.Context) error
 		return errors.Wrap(err, "failed to update vmss vm status")
 	}
 
+	if err := r.reconcileTerminateEvents(ctx); err != nil {
+		return errors.Wrap(err, "failed to handle the scheduled events of the vmss vm")
+	}
+
 	return nil
 }
 
+// reconcileTerminateEvents drains the node of an instance with a pending Terminate or Preempt scheduled event before
+// approving the event, so that the instance is deleted or evicted gracefully.
+func (r *azureMachinePoolMachineReconciler) reconcileTerminateEvents(ctx context.Context) error {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "controllers.azureMachinePoolMachineReconciler.reconcileTerminateEvents")
+	defer done()
+
+	machine := r.Scope.AzureMachinePoolMachine
+	if !r.Scope.TerminateEventScheduled() {
+		if conditions.Has(machine, infrav1.ScheduledEventApprovedCondition) {
+			// the instance survived the event, e.g. an evicted Spot VM that was started again
+			if err := r.Scope.Uncordon(ctx); err != nil {
+				return err
+			}
+			conditions.Delete(machine, infrav1.ScheduledEventApprovedCondition)
+			conditions.Delete(machine, clusterv1.DrainingSucceededCondition)
+		}
+		return nil
+	}
+
+	if conditions.IsTrue(machine, infrav1.ScheduledEventApprovedCondition) {
+		return nil
+	}
+
+	if !conditions.Has(machine, infrav1.ScheduledEventApprovedCondition) {
+		log.Info("Draining the node of the instance before approving its terminate scheduled event")
+		conditions.MarkFalse(machine, infrav1.ScheduledEventApprovedCondition, infrav1.ScheduledEventDrainingReason, clusterv1.ConditionSeverityInfo, "Draining the node before approving the scheduled event")
+	}
+
+	if err := r.Scope.CordonAndDrain(ctx); err != nil {
+		return errors.Wrap(err, "failed to cordon and drain the scalesetVM")
+	}
+
+	return r.scalesetVMsService.ApproveTerminateEvents(ctx)
+}
+
 // Delete will attempt to drain and delete the Azure VMSS VM.
 func (r *azureMachinePoolMachineReconciler) Delete(ctx context.Context) error {
 	ctx, log, done := tele.StartSpanWithLogger(ctx, "controllers.azureMachinePoolMachineReconciler.Delete")
@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/Azure/go-autorest/autorest/azure/auth"
+	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
@@ -58,6 +59,22 @@ func TestAzureMachinePoolMachineReconciler_Reconcile(t *testing.T) {
 				g.Expect(err).NotTo(HaveOccurred())
 			},
 		},
+		{
+			Name: "should poll the scheduled events of a ready machine of a pool with terminate notifications",
+			Setup: func(cb *fake.ClientBuilder, reconciler *mock_azure.MockReconcilerMockRecorder) {
+				cluster, azCluster, mp, amp, ampm := getAReadyMachinePoolMachineCluster()
+				amp.Spec.Template.TerminateNotificationTimeout = to.IntPtr(5)
+				succeeded := infrav1.Succeeded
+				ampm.Status.Ready = true
+				ampm.Status.ProvisioningState = &succeeded
+				reconciler.Reconcile(gomock2.AContext()).Return(nil)
+				cb.WithObjects(cluster, azCluster, mp, amp, ampm)
+			},
+			Verify: func(g *WithT, result ctrl.Result, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(result.RequeueAfter).To(Equal(terminateEventPollInterval))
+			},
+		},
 		{
 			Name: "should successfully delete",
 			Setup: func(cb *fake.ClientBuilder, reconciler *mock_azure.MockReconcilerMockRecorder) {
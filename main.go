@@ -39,6 +39,7 @@ import (
 	infrav1alpha3 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
 	infrav1alpha4 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha4"
 	infrav1beta1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
 	"sigs.k8s.io/cluster-api-provider-azure/controllers"
 	infrav1alpha3exp "sigs.k8s.io/cluster-api-provider-azure/exp/api/v1alpha3"
 	infrav1alpha4exp "sigs.k8s.io/cluster-api-provider-azure/exp/api/v1alpha4"
@@ -115,6 +116,7 @@ var (
 	webhookPort                        int
 	reconcileTimeout                   time.Duration
 	enableTracing                      bool
+	defaultImagesConfig                string
 )
 
 // InitFlags initializes all command-line flags.
@@ -241,6 +243,12 @@ func InitFlags(fs *pflag.FlagSet) {
 		"Enable tracing to the opentelemetry-collector service in the same namespace.",
 	)
 
+	fs.StringVar(&defaultImagesConfig,
+		"default-images-config",
+		"",
+		"Path to a YAML file mapping Kubernetes versions and OS types to the images used by machines which don't specify one. If unspecified, or if no image matches, the reference images are used.",
+	)
+
 	feature.MutableGates.AddFlag(fs)
 }
 
@@ -255,6 +263,16 @@ func main() {
 		setupLog.Info("Watching cluster-api objects only in namespace for reconciliation", "namespace", watchNamespace)
 	}
 
+	if defaultImagesConfig != "" {
+		config, err := azure.LoadDefaultImagesConfig(defaultImagesConfig)
+		if err != nil {
+			setupLog.Error(err, "unable to load default images config")
+			os.Exit(1)
+		}
+		infrav1beta1.SetDefaultImageResolver(config)
+		setupLog.Info("Using default images config", "default-images-config", defaultImagesConfig)
+	}
+
 	if profilerAddress != "" {
 		setupLog.Info("Profiler listening for requests", "profiler-address", profilerAddress)
 		go func() {
@@ -359,6 +377,16 @@ func registerControllers(ctx context.Context, mgr manager.Manager) {
 		os.Exit(1)
 	}
 
+	if err := (&controllers.AzureMachineTemplateReconciler{
+		Client:           mgr.GetClient(),
+		Recorder:         mgr.GetEventRecorderFor("azuremachinetemplate-reconciler"),
+		ReconcileTimeout: reconcileTimeout,
+		WatchFilterValue: watchFilterValue,
+	}).SetupWithManager(ctx, mgr, controller.Options{MaxConcurrentReconciles: azureMachineConcurrency}); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "AzureMachineTemplate")
+		os.Exit(1)
+	}
+
 	if err := (&controllers.AzureJSONMachineReconciler{
 		Client:           mgr.GetClient(),
 		Recorder:         mgr.GetEventRecorderFor("azurejsonmachine-reconciler"),